@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/labstack/echo/v4"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/analytics"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/booking"
 	businessadmin "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/business_admin"
@@ -9,10 +10,16 @@ import (
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/checkup"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/customer"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/item"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/member"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/place"
-	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/upload"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/pricing"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/realtime"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/review"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/upload"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/webhook"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // Routes struct for routing endpoint
@@ -28,11 +35,17 @@ type Routes struct {
 	businessadminHandler     *businessadmin.Handler
 	customerHandler          *customer.Handler
 	uploadHandler            *upload.Handler
-	reviewHandler			 *review.Handler
+	reviewHandler            *review.Handler
+	pricingHandler           *pricing.Handler
+	analyticsHandler         *analytics.Handler
+	notificationHandler      *notification.Handler
+	realtimeHandler          *realtime.Handler
+	webhookHandler           *webhook.Handler
+	memberHandler            *member.Handler
 }
 
 // NewRoutes for creating Routes instance
-func NewRoutes(router *echo.Echo, checkUpHandler *checkup.Handler, itemHandler *item.Handler, placeHandler *place.Handler, authHandler *auth.Handler, businessadminauthHandler *businessadminauth.Handler, authMiddleware middleware.AuthMiddleware, bookingHandler *booking.Handler, businessadminHandler *businessadmin.Handler, customerHandler *customer.Handler, uploadHandler *upload.Handler, reviewHandler *review.Handler) *Routes {
+func NewRoutes(router *echo.Echo, checkUpHandler *checkup.Handler, itemHandler *item.Handler, placeHandler *place.Handler, authHandler *auth.Handler, businessadminauthHandler *businessadminauth.Handler, authMiddleware middleware.AuthMiddleware, bookingHandler *booking.Handler, businessadminHandler *businessadmin.Handler, customerHandler *customer.Handler, uploadHandler *upload.Handler, reviewHandler *review.Handler, pricingHandler *pricing.Handler, analyticsHandler *analytics.Handler, notificationHandler *notification.Handler, realtimeHandler *realtime.Handler, webhookHandler *webhook.Handler, memberHandler *member.Handler) *Routes {
 	return &Routes{
 		Router:                   router,
 		checkUPHandler:           checkUpHandler,
@@ -46,6 +59,12 @@ func NewRoutes(router *echo.Echo, checkUpHandler *checkup.Handler, itemHandler *
 		customerHandler:          customerHandler,
 		uploadHandler:            uploadHandler,
 		reviewHandler:            reviewHandler,
+		pricingHandler:           pricingHandler,
+		analyticsHandler:         analyticsHandler,
+		notificationHandler:      notificationHandler,
+		realtimeHandler:          realtimeHandler,
+		webhookHandler:           webhookHandler,
+		memberHandler:            memberHandler,
 	}
 }
 
@@ -59,8 +78,8 @@ func (r *Routes) Init() {
 	{
 		// Place module
 		placeRoutes := v1.Group("/place")
-		placeRoutes.GET("", r.placeHandler.GetPlacesListWithPagination)
-		placeRoutes.GET("/:placeID", r.placeHandler.GetDetail)
+		placeRoutes.GET("", r.placeHandler.GetPlacesListWithPagination, r.authMiddleware.OptionalAuthMiddleware())
+		placeRoutes.GET("/:placeID", r.placeHandler.GetDetail, r.authMiddleware.OptionalAuthMiddleware())
 		{
 			// Catalog module
 			catalogRoutes := placeRoutes.Group("/:placeID/catalog")
@@ -71,34 +90,85 @@ func (r *Routes) Init() {
 			placeRoutes.GET("/:placeID/review", r.placeHandler.GetListReviewAndRatingWithPagination)
 		}
 
-		// Business Admin Module
+		// Business Admin Module, every endpoint is guarded by the permission of the role at the place
+		readPlace := r.authMiddleware.RequirePermission(util.PermissionReadPlace)
+		managePlace := r.authMiddleware.RequirePermission(util.PermissionManagePlace)
+		readBooking := r.authMiddleware.RequirePermission(util.PermissionReadBooking)
+		manageBooking := r.authMiddleware.RequirePermission(util.PermissionManageBooking)
+		readCatalog := r.authMiddleware.RequirePermission(util.PermissionReadCatalog)
+		manageCatalog := r.authMiddleware.RequirePermission(util.PermissionManageCatalog)
+		replyReview := r.authMiddleware.RequirePermission(util.PermissionReplyReview)
+		readFinance := r.authMiddleware.RequirePermission(util.PermissionReadFinance)
+		manageFinance := r.authMiddleware.RequirePermission(util.PermissionManageFinance)
+		manageWebhook := r.authMiddleware.RequirePermission(util.PermissionManageWebhook)
+		manageStaff := r.authMiddleware.RequirePermission(util.PermissionManageStaff)
+
 		businessAdminRoutes := v1.Group("/business-admin", r.authMiddleware.AuthMiddleware())
-		businessAdminRoutes.GET("/balance", r.businessadminHandler.GetBalanceDetail)
+		businessAdminRoutes.GET("/balance", r.businessadminHandler.GetBalanceDetail, readFinance)
 		{
-			businessAdminRoutes.POST("/disbursement", r.businessadminHandler.CreateDisbursement)
+			businessAdminRoutes.POST("/disbursement", r.businessadminHandler.CreateDisbursement, manageFinance)
+			businessAdminRoutes.GET("/payout-schedule", r.businessadminHandler.GetPayoutSchedule, readFinance)
+			businessAdminRoutes.PUT("/payout-schedule", r.businessadminHandler.UpdatePayoutSchedule, manageFinance)
+			businessAdminRoutes.GET("/payout-details", r.businessadminHandler.GetPayoutDetails, readFinance)
+			businessAdminRoutes.PUT("/payout-details", r.businessadminHandler.UpdatePayoutDetails, manageFinance)
+			businessAdminRoutes.GET("/reports", r.businessadminHandler.GetReport, readFinance)
+			businessAdminRoutes.GET("/reports/statement", r.businessadminHandler.GetMonthlyStatement, readFinance)
 
 			// Booking Module
 			bookingRoutes := businessAdminRoutes.Group("/booking")
-			bookingRoutes.GET("", r.bookingHandler.GetListCustomerBookingWithPagination)
-			bookingRoutes.GET("/:bookingID", r.bookingHandler.GetDetail)
-			bookingRoutes.PATCH("/:bookingID/confirmation", r.bookingHandler.UpdateBookingStatus)
+			bookingRoutes.GET("", r.bookingHandler.GetListCustomerBookingWithPagination, readBooking)
+			bookingRoutes.GET("/:bookingID", r.bookingHandler.GetDetail, readBooking)
+			bookingRoutes.PATCH("/:bookingID/confirmation", r.bookingHandler.UpdateBookingStatus, manageBooking)
+			bookingRoutes.POST("/check-in", r.bookingHandler.CheckIn, manageBooking)
+			bookingRoutes.PATCH("/:bookingID/check-in", r.bookingHandler.CheckInByBookingID, manageBooking)
+			bookingRoutes.PATCH("/:bookingID/no-show", r.bookingHandler.MarkNoShow, manageBooking)
 
 			// List Items Module
 			businessProfileRoutes := businessAdminRoutes.Group("/business-profile")
-			businessProfileRoutes.PUT("", r.businessadminHandler.PutEditProfile)
+			businessProfileRoutes.PUT("", r.businessadminHandler.PutEditProfile, managePlace)
 
 			listItemsRoutes := businessProfileRoutes.Group("/list-items")
-			listItemsRoutes.GET("", r.itemHandler.GetListItemAdminWithPagination)
-			listItemsRoutes.POST("", r.itemHandler.CreateItem)
-			listItemsRoutes.DELETE("/:itemID", r.itemHandler.DeleteItemAdminByID)
-			listItemsRoutes.PUT("/:itemID", r.itemHandler.UpdateItem)
+			listItemsRoutes.GET("", r.itemHandler.GetListItemAdminWithPagination, readCatalog)
+			listItemsRoutes.POST("", r.itemHandler.CreateItem, manageCatalog)
+			listItemsRoutes.DELETE("/:itemID", r.itemHandler.DeleteItemAdminByID, manageCatalog)
+			listItemsRoutes.PUT("/:itemID", r.itemHandler.UpdateItem, manageCatalog)
 
 			transactionHistoryRoutes := businessAdminRoutes.Group("/transaction-history")
-			transactionHistoryRoutes.GET("", r.businessadminHandler.GetListTransactionsHistoryWithPagination)
-			transactionHistoryRoutes.GET("/:bookingID", r.businessadminHandler.GetTransactionHistoryDetail)
+			transactionHistoryRoutes.GET("", r.businessadminHandler.GetListTransactionsHistoryWithPagination, readFinance)
+			transactionHistoryRoutes.GET("/:bookingID", r.businessadminHandler.GetTransactionHistoryDetail, readFinance)
+
+			businessProfileRoutes.GET("/detail", r.businessadminHandler.GetPlaceDetail, readPlace)
+			businessProfileRoutes.GET("/review", r.businessadminHandler.GetListReviewAndRatingWithPagination, readPlace)
+			businessProfileRoutes.POST("/review/:reviewID/reply", r.reviewHandler.ReplyReview, replyReview)
+
+			pricingRulesRoutes := businessProfileRoutes.Group("/pricing-rules")
+			pricingRulesRoutes.GET("", r.pricingHandler.GetRules, readPlace)
+			pricingRulesRoutes.POST("", r.pricingHandler.CreateRule, managePlace)
+			pricingRulesRoutes.DELETE("/:ruleID", r.pricingHandler.DeleteRule, managePlace)
+			businessProfileRoutes.PUT("/deposit", r.pricingHandler.UpdateDeposit, managePlace)
+			businessProfileRoutes.GET("/no-show-policy", r.bookingHandler.GetNoShowPolicy, readPlace)
+			businessProfileRoutes.PUT("/no-show-policy", r.bookingHandler.UpdateNoShowPolicy, managePlace)
 
-			businessProfileRoutes.GET("/detail", r.businessadminHandler.GetPlaceDetail)
-			businessProfileRoutes.GET("/review", r.businessadminHandler.GetListReviewAndRatingWithPagination)
+			analyticsRoutes := businessAdminRoutes.Group("/analytics", readFinance)
+			analyticsRoutes.GET("/revenue", r.analyticsHandler.GetRevenue)
+			analyticsRoutes.GET("/bookings", r.analyticsHandler.GetBookingStatusCounts)
+			analyticsRoutes.GET("/occupancy", r.analyticsHandler.GetOccupancy)
+			analyticsRoutes.GET("/items", r.analyticsHandler.GetTopItems)
+			analyticsRoutes.GET("/summary", r.analyticsHandler.GetSummary)
+			analyticsRoutes.GET("/ratings", r.analyticsHandler.GetRatingTrend)
+
+			webhookRoutes := businessAdminRoutes.Group("/webhooks", manageWebhook)
+			webhookRoutes.GET("", r.webhookHandler.GetWebhooks)
+			webhookRoutes.POST("", r.webhookHandler.CreateWebhook)
+			webhookRoutes.DELETE("/:webhookID", r.webhookHandler.DeleteWebhook)
+			webhookRoutes.GET("/:webhookID/deliveries", r.webhookHandler.GetDeliveriesWithPagination)
+			webhookRoutes.POST("/:webhookID/deliveries/:deliveryID/redeliver", r.webhookHandler.Redeliver)
+
+			// Staff module
+			staffRoutes := businessAdminRoutes.Group("/staff", manageStaff)
+			staffRoutes.GET("", r.memberHandler.GetMembers)
+			staffRoutes.POST("/invitations", r.memberHandler.Invite)
+			staffRoutes.DELETE("/:memberID", r.memberHandler.RemoveMember)
 		}
 
 		// Auth module
@@ -107,9 +177,15 @@ func (r *Routes) Init() {
 			authRoutes.POST("/check-phone-number", r.authHandler.CheckPhoneNumber)
 			authRoutes.POST("/verify-otp", r.authHandler.VerifyOTP)
 			authRoutes.POST("/register", r.authHandler.Register, r.authMiddleware.AuthMiddleware())
+			authRoutes.POST("/refresh", r.authHandler.RefreshToken)
+			authRoutes.POST("/logout", r.authHandler.Logout, r.authMiddleware.AuthMiddleware())
 
 			authRoutes.POST("/business-admin/register", r.businessadminauthHandler.RegisterBusinessAdmin)
 			authRoutes.POST("/business-admin/login", r.businessadminauthHandler.Login)
+			authRoutes.PUT("/business-admin/password", r.businessadminauthHandler.ChangePassword, r.authMiddleware.AuthMiddleware())
+			authRoutes.POST("/business-admin/forgot-password", r.businessadminauthHandler.ForgotPassword)
+			authRoutes.POST("/business-admin/reset-password", r.businessadminauthHandler.ResetPassword)
+			authRoutes.POST("/business-admin/staff/accept", r.memberHandler.AcceptInvitation)
 		}
 
 		// Booking module
@@ -121,16 +197,54 @@ func (r *Routes) Init() {
 			bookingRoutes.GET("/ongoing", r.bookingHandler.GetMyBookingsOngoing)
 			bookingRoutes.GET("/previous", r.bookingHandler.GetMyBookingsPreviousWithPagination)
 			bookingRoutes.GET("/detail/:bookingID", r.bookingHandler.GetDetailBookingSaya)
+			bookingRoutes.POST("/detail/:bookingID/remaining-invoice", r.bookingHandler.CreateRemainingInvoice)
+			bookingRoutes.GET("/detail/:bookingID/ticket", r.bookingHandler.GetTicket)
+			bookingRoutes.GET("/detail/:bookingID/ticket/pdf", r.bookingHandler.GetTicketPDF)
 			bookingRoutes.POST("/review/:bookingID", r.reviewHandler.InsertBookingReview)
 		}
 
+		// Review module
+		reviewRoutes := v1.Group("/review", r.authMiddleware.AuthMiddleware())
+		{
+			reviewRoutes.PUT("/:reviewID", r.reviewHandler.UpdateReview)
+			reviewRoutes.POST("/:reviewID/report", r.reviewHandler.ReportReview)
+		}
+
+		// Moderation module
+		moderationRoutes := v1.Group("/moderation", r.authMiddleware.AuthMiddleware())
+		{
+			moderationRoutes.GET("/reviews", r.reviewHandler.GetReportedReviewsWithPagination)
+			moderationRoutes.PATCH("/reviews/:reviewID/hide", r.reviewHandler.HideReview)
+			moderationRoutes.PATCH("/reviews/:reviewID/restore", r.reviewHandler.RestoreReview)
+		}
+
 		// User group module
 		userRoutes := v1.Group("/user", r.authMiddleware.AuthMiddleware())
 		{
 			// Customer module
 			userRoutes.GET("", r.customerHandler.RetrieveCustomerProfile)
+
+			// Notification module
+			userRoutes.PUT("/notification-preference", r.notificationHandler.UpdatePreference)
+
+			// Favorite module
+			userRoutes.GET("/favorites", r.placeHandler.GetFavoritesWithPagination)
+			userRoutes.POST("/favorites/:placeID", r.placeHandler.AddFavorite)
+			userRoutes.DELETE("/favorites/:placeID", r.placeHandler.RemoveFavorite)
 		}
 
+		// Notification module
+		notificationRoutes := v1.Group("/notifications", r.authMiddleware.AuthMiddleware())
+		{
+			notificationRoutes.GET("", r.notificationHandler.GetListWithPagination)
+			notificationRoutes.GET("/unread-count", r.notificationHandler.GetUnreadCount)
+			notificationRoutes.PATCH("/read-all", r.notificationHandler.MarkAllAsRead)
+			notificationRoutes.PATCH("/:notificationID/read", r.notificationHandler.MarkAsRead)
+		}
+
+		// Realtime module
+		v1.GET("/events", r.realtimeHandler.Stream, r.authMiddleware.AuthMiddleware())
+
 		// callback
 		callbackRoutes := v1.Group("/callback")
 		{
@@ -1,22 +1,34 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/cloudinary"
 	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
 
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/analytics"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth"
+	authprovider "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth_provider"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/booking"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/customer"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/item"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/member"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/place"
-	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/upload"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/pricing"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/realtime"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/reconciliation"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/review"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/upload"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/webhook"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"github.com/xendit/xendit-go/client"
@@ -25,6 +37,7 @@ import (
 	businessadminauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/business_admin_auth"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/checkup"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // Server struct to for the server dependency
@@ -53,6 +66,7 @@ var (
 	placeHandler *place.Handler
 
 	firebaseAuthRepo firebaseauth.Repo
+	authProvider     authprovider.Provider
 	authMiddleware   middleware.AuthMiddleware
 	authRepo         auth.Repo
 	authService      auth.Service
@@ -62,6 +76,29 @@ var (
 	businessadminauthService businessadminauth.Service
 	businessadminauthHandler *businessadminauth.Handler
 
+	pricingRepo    pricing.Repo
+	pricingService pricing.Service
+	pricingHandler *pricing.Handler
+
+	notificationRepo    notification.Repo
+	notificationService notification.Service
+	notificationHandler *notification.Handler
+	notificationJob     *notification.Job
+	notifier            notification.Notifier
+
+	realtimeRepo    realtime.Repo
+	realtimeService realtime.Service
+	realtimeHandler *realtime.Handler
+
+	webhookRepo    webhook.Repo
+	webhookService webhook.Service
+	webhookHandler *webhook.Handler
+	webhookJob     *webhook.Job
+
+	analyticsRepo    analytics.Repo
+	analyticsService analytics.Service
+	analyticsHandler *analytics.Handler
+
 	bookingRepo    booking.Repo
 	bookingService booking.Service
 	bookingHandler *booking.Handler
@@ -80,9 +117,18 @@ var (
 	uploadService  upload.Service
 	uploadHandler  *upload.Handler
 
-	reviewRepo 		review.Repo
-	reviewService  	review.Service
-	reviewHandler  	*review.Handler
+	reviewRepo    review.Repo
+	reviewService review.Service
+	reviewHandler *review.Handler
+
+	memberRepo    member.Repo
+	memberService member.Service
+	memberHandler *member.Handler
+
+	reconciliationService reconciliation.Service
+	reconciliationJob     *reconciliation.Job
+
+	payoutJob *businessadmin.PayoutJob
 )
 
 // Init all dependency
@@ -92,6 +138,10 @@ func (s Server) Init() {
 
 	cloudinaryRepo = cloudinary.NewRepo(os.Getenv("CLOUDINARY_CLOUD_NAME"), os.Getenv("CLOUDINARY_API_KEY"), os.Getenv("CLOUDINARY_API_SECRET"))
 
+	// Auth provider
+	firebaseAuthRepo = firebaseauth.NewRepo(os.Getenv("IDENTITY_TOOLKIT_URL"), os.Getenv("SECURE_TOKEN_URL"), os.Getenv("FIREBASE_API_KEY"))
+	authProvider = newAuthProvider(db, firebaseAuthRepo)
+
 	// Init internal module
 	// Check up module
 	checkUpRepo = checkup.NewRepo(db)
@@ -110,7 +160,7 @@ func (s Server) Init() {
 
 	// BusinessAdminAuth module
 	businessadminauthRepo = businessadminauth.NewRepo(db)
-	businessadminauthService = businessadminauth.NewService(businessadminauthRepo, os.Getenv("FIREBASE_API_KEY"), os.Getenv("IDENTITY_TOOLKIT_URL"))
+	businessadminauthService = businessadminauth.NewService(businessadminauthRepo, authProvider, emailChannel(), passwordResetProvider(firebaseAuthRepo))
 	businessadminauthHandler = businessadminauth.NewHandler(businessadminauthService)
 
 	// Check up module
@@ -121,23 +171,48 @@ func (s Server) Init() {
 	// Auth module
 	userRepo = user.NewRepo(db)
 	authRepo = auth.NewRepo(db)
-	firebaseAuthRepo = firebaseauth.NewRepo(os.Getenv("IDENTITY_TOOLKIT_URL"), os.Getenv("SECURE_TOKEN_URL"), os.Getenv("FIREBASE_API_KEY"))
-	authMiddleware = middleware.NewAuthMiddleware(firebaseAuthRepo, userRepo)
-	authService = auth.NewService(authRepo, firebaseAuthRepo)
+	authMiddleware = middleware.NewAuthMiddleware(tokenVerifier(authProvider), authRepo, userRepo)
+	authService = auth.NewService(authRepo, authProvider)
 	authHandler = auth.NewHandler(authService)
 
 	// Xendit service
 	xenCli := client.New(os.Getenv("XENDIT_TOKEN"))
 	xenditService := xendit.NewXenditClient(xenCli)
 
+	// Pricing module
+	pricingRepo = pricing.NewRepo(db)
+	pricingService = pricing.NewService(pricingRepo)
+	pricingHandler = pricing.NewHandler(pricingService)
+
+	// Notification module
+	notificationRepo = notification.NewRepo(db)
+	notificationService = notification.NewService(notificationRepo, notificationChannels(notificationRepo)...)
+	notificationHandler = notification.NewHandler(notificationService)
+
+	// Realtime module
+	realtimeRepo = realtime.NewRepo(db)
+	realtimeService = realtime.NewService(realtimeRepo, realtime.NewBroker())
+	realtimeHandler = realtime.NewHandler(realtimeService)
+
+	// Webhook module
+	webhookRepo = webhook.NewRepo(db)
+	webhookService = webhook.NewService(webhookRepo)
+	webhookHandler = webhook.NewHandler(webhookService)
+	notifier = notification.Notifiers{notificationService, realtimeService, webhookService}
+
+	// Analytics module
+	analyticsRepo = analytics.NewRepo(db)
+	analyticsService = analytics.NewService(analyticsRepo)
+	analyticsHandler = analytics.NewHandler(analyticsService)
+
 	// Booking Module
 	bookingRepo = booking.NewRepo(db)
-	bookingService = booking.NewService(bookingRepo, xenditService)
+	bookingService = booking.NewService(bookingRepo, xenditService, pricingService, notifier, ticketSecret())
 	bookingHandler = booking.NewHandler(bookingService)
 
 	// BusinessAdmin module
 	businessadminRepo = businessadmin.NewRepo(db)
-	businessadminService = businessadmin.NewService(businessadminRepo, xenditService, placeService)
+	businessadminService = businessadmin.NewService(businessadminRepo, xenditService, placeService, notifier)
 	businessadminHandler = businessadmin.NewHandler(businessadminService)
 
 	// Customer Module
@@ -151,14 +226,183 @@ func (s Server) Init() {
 
 	// Review module
 	reviewRepo = review.NewRepo(db)
-	reviewService = review.NewService(reviewRepo)
+	reviewService = review.NewService(reviewRepo, notifier, cloudinaryRepo)
 	reviewHandler = review.NewHandler(reviewService)
 
+	// Member module
+	memberRepo = member.NewRepo(db)
+	memberService = member.NewService(memberRepo, emailChannel())
+	memberHandler = member.NewHandler(memberService)
+
+	// Reconciliation module
+	reconciliationService = reconciliation.NewService(bookingService, businessadminService, xenditService)
+
 	// Start routing
-	r := NewRoutes(s.Router, checkupHandler, itemHandler, placeHandler, authHandler, businessadminauthHandler, authMiddleware, bookingHandler, businessadminHandler, customerHandler, uploadHandler, reviewHandler)
+	r := NewRoutes(s.Router, checkupHandler, itemHandler, placeHandler, authHandler, businessadminauthHandler, authMiddleware, bookingHandler, businessadminHandler, customerHandler, uploadHandler, reviewHandler, pricingHandler, analyticsHandler, notificationHandler, realtimeHandler, webhookHandler, memberHandler)
 	r.Init()
 }
 
+// notificationChannels return the in-app channel and every external channel that is configured
+func notificationChannels(repo notification.Repo) []notification.Channel {
+	channels := []notification.Channel{notification.NewInAppChannel(repo)}
+
+	if email := emailChannel(); email != nil {
+		channels = append(channels, email)
+	}
+
+	if os.Getenv("FCM_SERVER_KEY") != "" {
+		channels = append(channels, notification.NewPushChannel(util.FCMSendURL, os.Getenv("FCM_SERVER_KEY")))
+	}
+
+	if sms := smsChannel(); sms != nil {
+		channels = append(channels, sms)
+	}
+
+	return channels
+}
+
+// passwordResetProvider return firebase when it is the auth provider, the reset email is sent by firebase
+// because its password can only be replaced by the signed in user or by the reset code it sent
+func passwordResetProvider(firebaseAuthRepo firebaseauth.Repo) firebaseauth.Repo {
+	if os.Getenv("AUTH_PROVIDER") == util.AuthProviderLocal {
+		return nil
+	}
+
+	return firebaseAuthRepo
+}
+
+// newAuthProvider return the self-hosted provider when AUTH_PROVIDER is local, otherwise firebase is used
+func newAuthProvider(db *sqlx.DB, firebaseAuthRepo firebaseauth.Repo) authprovider.Provider {
+	if os.Getenv("AUTH_PROVIDER") != util.AuthProviderLocal {
+		return firebaseAuthRepo
+	}
+
+	// a short secret could be brute-forced to forge a session token of any user
+	secret := os.Getenv("AUTH_TOKEN_SECRET")
+	if len(secret) < util.MinimumAuthTokenSecretLength {
+		logrus.Fatalf("AUTH_TOKEN_SECRET is required by local auth provider and should be at least %d characters", util.MinimumAuthTokenSecretLength)
+	}
+
+	// OTP code is written to the log without sms gateway, it is only allowed for local development
+	sms := smsChannel()
+	if sms == nil && os.Getenv("ENV") != "local" {
+		logrus.Fatal("SMS_GATEWAY_URL is required by local auth provider unless ENV is local")
+	}
+
+	return authprovider.NewLocalProvider(authprovider.NewRepo(db), secret, sms)
+}
+
+// ticketSecret return the secret signing booking e-ticket, an empty or short secret would let anyone forge a ticket
+func ticketSecret() string {
+	secret := os.Getenv("TICKET_SECRET")
+	if len(secret) < util.MinimumTicketSecretLength {
+		logrus.Fatalf("TICKET_SECRET is required and should be at least %d characters", util.MinimumTicketSecretLength)
+	}
+
+	return secret
+}
+
+// tokenVerifier return the provider itself when it is the local provider, the verifier which verify firebase ID token
+// locally with google public keys, or the firebase repo which look up the token to identity toolkit when
+// FIREBASE_PROJECT_ID is not configured
+func tokenVerifier(provider authprovider.Provider) firebaseauth.TokenVerifier {
+	if os.Getenv("AUTH_PROVIDER") == util.AuthProviderLocal {
+		return provider
+	}
+
+	projectID := os.Getenv("FIREBASE_PROJECT_ID")
+	if projectID == "" {
+		logrus.Warn("FIREBASE_PROJECT_ID is not set, ID token is verified by identity toolkit")
+		return provider
+	}
+
+	certsURL := os.Getenv("FIREBASE_CERTS_URL")
+	if certsURL == "" {
+		certsURL = firebaseauth.GoogleCertsURL
+	}
+
+	return firebaseauth.NewTokenVerifier(projectID, firebaseauth.NewGoogleKeySource(certsURL, &http.Client{Timeout: 10 * time.Second}))
+}
+
+// smsChannel return the sms channel, or nil when sms gateway is not configured
+func smsChannel() notification.Channel {
+	if os.Getenv("SMS_GATEWAY_URL") == "" {
+		return nil
+	}
+
+	return notification.NewSMSChannel(os.Getenv("SMS_GATEWAY_URL"), os.Getenv("SMS_GATEWAY_TOKEN"))
+}
+
+// emailChannel return the email channel, or nil when SMTP is not configured
+func emailChannel() notification.Channel {
+	if os.Getenv("SMTP_HOST") == "" {
+		return nil
+	}
+
+	return notification.NewEmailChannel(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"))
+}
+
+// StartJobs to start all background job
+func (s Server) StartJobs() {
+	interval, err := strconv.Atoi(os.Getenv("RECONCILIATION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = util.ReconciliationInterval
+	}
+
+	reconciliationJob = reconciliation.NewJob(reconciliationService, time.Duration(interval)*time.Minute)
+	reconciliationJob.Start()
+
+	interval, err = strconv.Atoi(os.Getenv("PAYOUT_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = util.PayoutInterval
+	}
+
+	payoutJob = businessadmin.NewPayoutJob(businessadminService, time.Duration(interval)*time.Minute)
+	payoutJob.Start()
+
+	interval, err = strconv.Atoi(os.Getenv("NOTIFICATION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = util.NotificationInterval
+	}
+
+	notificationJob = notification.NewJob(notificationService, time.Duration(interval)*time.Minute)
+	notificationJob.Start()
+
+	interval, err = strconv.Atoi(os.Getenv("WEBHOOK_INTERVAL"))
+	if err != nil || interval <= 0 {
+		interval = util.WebhookInterval
+	}
+
+	webhookJob = webhook.NewJob(webhookService, time.Duration(interval)*time.Minute)
+	webhookJob.Start()
+}
+
+// Reconcile to run the reconciliation once and print the discrepancy report
+func (s Server) Reconcile() {
+	report, err := reconciliationService.Reconcile()
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logrus.Error(err)
+	}
+}
+
+// BackfillPlaceStats to rebuild the denormalized place stats once
+func (s Server) BackfillPlaceStats() {
+	updated, err := placeService.BackfillStats()
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	logrus.Infof("place stats of %d places is rebuilt", updated)
+}
+
 // RunServer to run the server
 func (s Server) RunServer(port string) {
 	if err := s.Router.Start(":" + port); err != http.ErrServerClosed {
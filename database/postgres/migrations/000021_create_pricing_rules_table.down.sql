@@ -0,0 +1,4 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS booking_price;
+
+DROP TABLE IF EXISTS pricing_rules;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "pricing_rules" (
+    "id" SERIAL PRIMARY KEY,
+    "place_id" INT NOT NULL,
+    "name" VARCHAR(64) NOT NULL,
+    "type" INT NOT NULL,
+    "price" FLOAT NOT NULL,
+    "start_time" TIME,
+    "end_time" TIME,
+    "date" DATE,
+    "created_at" TIMESTAMP DEFAULT now(),
+    "updated_at" TIMESTAMP DEFAULT now(),
+    foreign key (place_id) references places(id)
+);
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS booking_price FLOAT;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS booking_invoices;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS payment_type,
+    DROP COLUMN IF EXISTS split_count;
+
+ALTER TABLE places
+    DROP COLUMN IF EXISTS deposit_percentage;
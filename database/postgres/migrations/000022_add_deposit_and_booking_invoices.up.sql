@@ -0,0 +1,30 @@
+ALTER TABLE places
+    ADD COLUMN IF NOT EXISTS deposit_percentage INT NOT NULL DEFAULT 0;
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS payment_type INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS split_count INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS "booking_invoices" (
+    "id" SERIAL PRIMARY KEY,
+    "booking_id" INT NOT NULL,
+    "xendit_id" VARCHAR(128) NOT NULL UNIQUE,
+    "invoice_url" VARCHAR(128) NOT NULL,
+    "type" INT NOT NULL,
+    "amount" FLOAT NOT NULL,
+    "platform_fee" FLOAT NOT NULL DEFAULT 0,
+    "status" INT NOT NULL,
+    "expired_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT now(),
+    "updated_at" TIMESTAMP DEFAULT now(),
+    foreign key (booking_id) references bookings(id)
+);
+
+-- keep invoices created before this migration reachable from the callback
+INSERT INTO booking_invoices (booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, expired_at)
+SELECT b.id, b.xendit_id, COALESCE(b.invoices_url, ''), 0,
+       COALESCE(b.booking_price, p.booking_price, 0) + b.total_price + 3000, 3000,
+       CASE WHEN b.status IN (2, 3) THEN 1 WHEN b.status = 4 THEN 2 ELSE 0 END,
+       b.payment_expired_at
+FROM bookings b, places p
+WHERE b.place_id = p.id AND b.xendit_id IS NOT NULL AND b.xendit_id <> '';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS disbursements_place_id_payout_period_attempt_idx;
+
+ALTER TABLE disbursements
+    DROP COLUMN IF EXISTS attempt,
+    DROP COLUMN IF EXISTS payout_period;
+
+ALTER TABLE business_owners
+    DROP COLUMN IF EXISTS payout_minimum_balance,
+    DROP COLUMN IF EXISTS payout_schedule;
@@ -0,0 +1,11 @@
+ALTER TABLE business_owners
+    ADD COLUMN IF NOT EXISTS payout_schedule INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS payout_minimum_balance FLOAT NOT NULL DEFAULT 0;
+
+ALTER TABLE disbursements
+    ADD COLUMN IF NOT EXISTS payout_period VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS attempt INT NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS disbursements_place_id_payout_period_attempt_idx
+    ON disbursements (place_id, payout_period, attempt)
+    WHERE payout_period IS NOT NULL;
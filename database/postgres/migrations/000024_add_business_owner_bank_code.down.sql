@@ -0,0 +1,2 @@
+ALTER TABLE business_owners
+    DROP COLUMN IF EXISTS bank_code;
@@ -0,0 +1,2 @@
+ALTER TABLE business_owners
+    ADD COLUMN IF NOT EXISTS bank_code VARCHAR(16) NOT NULL DEFAULT 'BCA';
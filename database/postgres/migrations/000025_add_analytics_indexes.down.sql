@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS bookings_place_id_date_idx;
+DROP INDEX IF EXISTS booking_items_booking_id_idx;
+DROP INDEX IF EXISTS reviews_place_id_created_at_idx;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS bookings_place_id_date_idx ON bookings (place_id, date);
+CREATE INDEX IF NOT EXISTS booking_items_booking_id_idx ON booking_items (booking_id);
+CREATE INDEX IF NOT EXISTS reviews_place_id_created_at_idx ON reviews (place_id, created_at);
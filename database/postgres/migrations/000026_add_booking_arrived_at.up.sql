@@ -0,0 +1,2 @@
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS arrived_at timestamp;
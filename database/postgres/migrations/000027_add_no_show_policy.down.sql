@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS bookings_user_id_status_idx;
+
+ALTER TABLE places
+    DROP COLUMN IF EXISTS no_show_policy,
+    DROP COLUMN IF EXISTS no_show_threshold;
@@ -0,0 +1,5 @@
+ALTER TABLE places
+    ADD COLUMN IF NOT EXISTS no_show_policy    INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS no_show_threshold INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS bookings_user_id_status_idx ON bookings (user_id, status);
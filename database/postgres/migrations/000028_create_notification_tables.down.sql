@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "in_app_notifications";
+
+DROP TABLE IF EXISTS "notification_outbox";
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS device_token;
@@ -0,0 +1,34 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(2) NOT NULL DEFAULT 'id',
+    ADD COLUMN IF NOT EXISTS device_token TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS "notification_outbox" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INT NOT NULL,
+    "event" VARCHAR(64) NOT NULL,
+    "channel" VARCHAR(16) NOT NULL,
+    "recipient" VARCHAR(255) NOT NULL,
+    "subject" VARCHAR(255) NOT NULL,
+    "body" TEXT NOT NULL,
+    "status" INT NOT NULL DEFAULT 0,
+    "attempt" INT NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP NOT NULL DEFAULT now(),
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP DEFAULT now(),
+    "updated_at" TIMESTAMP DEFAULT now(),
+    foreign key (user_id) references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_status_next_attempt_at_idx ON notification_outbox (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS "in_app_notifications" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INT NOT NULL,
+    "event" VARCHAR(64) NOT NULL,
+    "title" VARCHAR(255) NOT NULL,
+    "body" TEXT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT now(),
+    foreign key (user_id) references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS in_app_notifications_user_id_idx ON in_app_notifications (user_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS notifications_user_id_unread_idx;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS read_at;
+
+ALTER INDEX IF EXISTS notifications_user_id_idx RENAME TO in_app_notifications_user_id_idx;
+ALTER TABLE IF EXISTS notifications RENAME TO in_app_notifications;
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS in_app_notifications RENAME TO notifications;
+ALTER INDEX IF EXISTS in_app_notifications_user_id_idx RENAME TO notifications_user_id_idx;
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS read_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS "webhooks" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INT NOT NULL,
+    "url" TEXT NOT NULL,
+    "secret" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMP DEFAULT now(),
+    "updated_at" TIMESTAMP DEFAULT now(),
+    foreign key (user_id) references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" SERIAL PRIMARY KEY,
+    "webhook_id" INT NOT NULL,
+    "event" VARCHAR(64) NOT NULL,
+    "payload" TEXT NOT NULL,
+    "status" INT NOT NULL DEFAULT 0,
+    "attempt" INT NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP NOT NULL DEFAULT now(),
+    "response_status" INT NOT NULL DEFAULT 0,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "delivered_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT now(),
+    "updated_at" TIMESTAMP DEFAULT now(),
+    foreign key (webhook_id) references webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_at_idx ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "review_reports";
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS reply,
+    DROP COLUMN IF EXISTS replied_at,
+    DROP COLUMN IF EXISTS is_hidden,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS moderated_at;
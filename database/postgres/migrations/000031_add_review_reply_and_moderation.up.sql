@@ -0,0 +1,22 @@
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS reply TEXT,
+    ADD COLUMN IF NOT EXISTS replied_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS is_hidden BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS moderated_by INT,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS "review_reports" (
+    "id" SERIAL PRIMARY KEY,
+    "review_id" INT NOT NULL,
+    "user_id" INT NOT NULL,
+    "reason" TEXT NOT NULL,
+    "status" INT NOT NULL DEFAULT 0,
+    "resolved_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT now(),
+    "updated_at" TIMESTAMP DEFAULT now(),
+    UNIQUE (review_id, user_id),
+    foreign key (review_id) references reviews(id) ON DELETE CASCADE,
+    foreign key (user_id) references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS review_reports_status_idx ON review_reports (status, review_id);
@@ -0,0 +1,5 @@
+DELETE FROM reviews r
+    USING reviews duplicate
+    WHERE r.booking_id = duplicate.booking_id AND r.id > duplicate.id;
+
+ALTER TABLE reviews ADD CONSTRAINT reviews_booking_id_key UNIQUE (booking_id);
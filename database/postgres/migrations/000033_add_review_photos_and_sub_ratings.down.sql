@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "review_photos";
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS cleanliness_rating,
+    DROP COLUMN IF EXISTS service_rating,
+    DROP COLUMN IF EXISTS value_rating;
@@ -0,0 +1,14 @@
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS cleanliness_rating INT,
+    ADD COLUMN IF NOT EXISTS service_rating INT,
+    ADD COLUMN IF NOT EXISTS value_rating INT;
+
+CREATE TABLE IF NOT EXISTS "review_photos" (
+    "id" SERIAL PRIMARY KEY,
+    "review_id" INT NOT NULL,
+    "url" TEXT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT now(),
+    foreign key (review_id) references reviews(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS review_photos_review_id_idx ON review_photos (review_id);
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS "place_stats" (
+    "place_id" INT PRIMARY KEY,
+    "review_count" INT NOT NULL DEFAULT 0,
+    "rating_sum" INT NOT NULL DEFAULT 0,
+    "cleanliness_sum" INT NOT NULL DEFAULT 0,
+    "cleanliness_count" INT NOT NULL DEFAULT 0,
+    "service_sum" INT NOT NULL DEFAULT 0,
+    "service_count" INT NOT NULL DEFAULT 0,
+    "value_sum" INT NOT NULL DEFAULT 0,
+    "value_count" INT NOT NULL DEFAULT 0,
+    "booking_count" INT NOT NULL DEFAULT 0,
+    "updated_at" TIMESTAMP DEFAULT now(),
+    foreign key (place_id) references places(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS place_stats_booking_count_idx ON place_stats (booking_count DESC);
+
+INSERT INTO place_stats (place_id, review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count, booking_count)
+SELECT p.id, COALESCE(r.review_count, 0), COALESCE(r.rating_sum, 0), COALESCE(r.cleanliness_sum, 0), COALESCE(r.cleanliness_count, 0),
+    COALESCE(r.service_sum, 0), COALESCE(r.service_count, 0), COALESCE(r.value_sum, 0), COALESCE(r.value_count, 0), COALESCE(b.booking_count, 0)
+FROM places p
+LEFT JOIN (
+    SELECT place_id, COUNT(id) AS review_count, SUM(rating) AS rating_sum,
+        SUM(cleanliness_rating) AS cleanliness_sum, COUNT(cleanliness_rating) AS cleanliness_count,
+        SUM(service_rating) AS service_sum, COUNT(service_rating) AS service_count,
+        SUM(value_rating) AS value_sum, COUNT(value_rating) AS value_count
+    FROM reviews
+    WHERE NOT is_hidden
+    GROUP BY place_id
+) r ON r.place_id = p.id
+LEFT JOIN (
+    SELECT place_id, COUNT(id) AS booking_count
+    FROM bookings
+    WHERE status = 3 OR status = 5
+    GROUP BY place_id
+) b ON b.place_id = p.id
+ON CONFLICT (place_id) DO NOTHING;
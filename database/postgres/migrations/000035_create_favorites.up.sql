@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "favorites" (
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INT NOT NULL,
+    "place_id" INT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT now(),
+    foreign key (user_id) references users(id) ON DELETE CASCADE,
+    foreign key (place_id) references places(id) ON DELETE CASCADE,
+    UNIQUE (user_id, place_id)
+);
+
+CREATE INDEX IF NOT EXISTS favorites_user_id_created_at_idx ON favorites (user_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "place_member_invitations";
+DROP TABLE IF EXISTS "place_members";
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS "place_members" (
+    "id" SERIAL PRIMARY KEY,
+    "place_id" INT NOT NULL,
+    "user_id" INT NOT NULL UNIQUE,
+    "role" VARCHAR(16) NOT NULL,
+    "created_at" TIMESTAMP DEFAULT now(),
+    foreign key (place_id) references places(id) ON DELETE CASCADE,
+    foreign key (user_id) references users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS place_members_place_id_idx ON place_members (place_id);
+
+CREATE TABLE IF NOT EXISTS "place_member_invitations" (
+    "id" SERIAL PRIMARY KEY,
+    "place_id" INT NOT NULL,
+    "email" VARCHAR(64) NOT NULL,
+    "role" VARCHAR(16) NOT NULL,
+    "token" VARCHAR(64) NOT NULL UNIQUE,
+    "expires_at" TIMESTAMP NOT NULL,
+    "accepted_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT now(),
+    foreign key (place_id) references places(id) ON DELETE CASCADE,
+    UNIQUE (place_id, email)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE otp_codes
+    ADD COLUMN IF NOT EXISTS attempts int NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+    "token_hash" VARCHAR(64) PRIMARY KEY,
+    "created_at" TIMESTAMP DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS revoked_tokens_expires_at_idx;
+ALTER TABLE revoked_tokens DROP COLUMN IF EXISTS expires_at;
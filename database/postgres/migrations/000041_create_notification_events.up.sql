@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "notification_events" (
+    "id" SERIAL PRIMARY KEY,
+    "type" VARCHAR(64) NOT NULL,
+    "booking_id" INT,
+    "user_id" INT,
+    "amount" FLOAT NOT NULL DEFAULT 0,
+    "queued_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notification_events_pending_idx ON notification_events (id) WHERE queued_at IS NULL;
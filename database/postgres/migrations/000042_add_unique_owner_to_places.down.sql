@@ -0,0 +1 @@
+ALTER TABLE places DROP CONSTRAINT IF EXISTS places_user_id_key;
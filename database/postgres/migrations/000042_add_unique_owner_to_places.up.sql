@@ -0,0 +1 @@
+ALTER TABLE places ADD CONSTRAINT places_user_id_key UNIQUE (user_id);
@@ -0,0 +1,68 @@
+package analytics
+
+// Request consist of date range and grouping of analytics request
+type Request struct {
+	UserID    int    `json:"user_id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Interval  string `json:"interval"`
+	Limit     int    `json:"limit"`
+}
+
+// Filter is the validated request used by repo
+type Filter struct {
+	PlaceID   int
+	StartDate string
+	EndDate   string
+	Interval  string
+	Limit     int
+}
+
+// RevenuePoint consist revenue of successful bookings in a period
+type RevenuePoint struct {
+	Period       string  `json:"period"`
+	Revenue      float64 `json:"revenue"`
+	BookingCount int     `json:"booking_count" db:"booking_count"`
+}
+
+// StatusCount consist number of bookings with the same status
+type StatusCount struct {
+	Status int `json:"status"`
+	Count  int `json:"count"`
+}
+
+// SlotOccupancy consist booked capacity of a time slot against place capacity
+type SlotOccupancy struct {
+	Day            int     `json:"day"`
+	StartTime      string  `json:"start_time" db:"start_time"`
+	EndTime        string  `json:"end_time" db:"end_time"`
+	BookedCapacity int     `json:"booked_capacity" db:"booked_capacity"`
+	TotalCapacity  int     `json:"total_capacity" db:"total_capacity"`
+	OccupancyRate  float64 `json:"occupancy_rate" db:"-"`
+}
+
+// TopItem consist sales of an item
+type TopItem struct {
+	ItemID  int     `json:"item_id" db:"item_id"`
+	Name    string  `json:"name"`
+	Qty     int     `json:"qty"`
+	Revenue float64 `json:"revenue"`
+}
+
+// Summary consist booking aggregates of a place
+type Summary struct {
+	TotalBookings      int     `json:"total_bookings" db:"total_bookings"`
+	SuccessfulBookings int     `json:"successful_bookings" db:"successful_bookings"`
+	FailedBookings     int     `json:"failed_bookings" db:"failed_bookings"`
+	NoShowBookings     int     `json:"no_show_bookings" db:"no_show_bookings"`
+	AveragePartySize   float64 `json:"average_party_size" db:"average_party_size"`
+	FailedRate         float64 `json:"failed_rate" db:"-"`
+	NoShowRate         float64 `json:"no_show_rate" db:"-"`
+}
+
+// RatingPoint consist average rating of reviews in a period
+type RatingPoint struct {
+	Period        string  `json:"period"`
+	AverageRating float64 `json:"average_rating" db:"average_rating"`
+	ReviewCount   int     `json:"review_count" db:"review_count"`
+}
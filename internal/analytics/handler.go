@@ -0,0 +1,131 @@
+package analytics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Handler for defining handler struct
+type Handler struct {
+	service Service
+}
+
+// NewHandler for initialize handler struct
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetRevenue is a handler for getting revenue over time of business admin place
+func (h *Handler) GetRevenue(c echo.Context) error {
+	params, err := parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	revenue, err := h.service.GetRevenue(*params)
+	return respond(c, revenue, err)
+}
+
+// GetBookingStatusCounts is a handler for getting booking counts by status of business admin place
+func (h *Handler) GetBookingStatusCounts(c echo.Context) error {
+	params, err := parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	statusCounts, err := h.service.GetBookingStatusCounts(*params)
+	return respond(c, statusCounts, err)
+}
+
+// GetOccupancy is a handler for getting occupancy rate per time slot of business admin place
+func (h *Handler) GetOccupancy(c echo.Context) error {
+	params, err := parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	occupancy, err := h.service.GetOccupancy(*params)
+	return respond(c, occupancy, err)
+}
+
+// GetTopItems is a handler for getting top selling items of business admin place
+func (h *Handler) GetTopItems(c echo.Context) error {
+	params, err := parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	items, err := h.service.GetTopItems(*params)
+	return respond(c, items, err)
+}
+
+// GetSummary is a handler for getting average party size, failed and no-show rate of business admin place
+func (h *Handler) GetSummary(c echo.Context) error {
+	params, err := parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := h.service.GetSummary(*params)
+	return respond(c, summary, err)
+}
+
+// GetRatingTrend is a handler for getting rating trend of business admin place
+func (h *Handler) GetRatingTrend(c echo.Context) error {
+	params, err := parseRequest(c)
+	if err != nil {
+		return err
+	}
+
+	ratings, err := h.service.GetRatingTrend(*params)
+	return respond(c, ratings, err)
+}
+
+// parseRequest read the business admin and date range query params shared by all analytics endpoint
+func parseRequest(c echo.Context) (*Request, error) {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return nil, util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	params := Request{
+		UserID:    user.ID,
+		StartDate: c.QueryParam("start_date"),
+		EndDate:   c.QueryParam("end_date"),
+		Interval:  c.QueryParam("interval"),
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		params.Limit, err = strconv.Atoi(limit)
+		if err != nil || params.Limit <= 0 {
+			return nil, util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "limit should be positive integer")
+		}
+	}
+
+	return &params, nil
+}
+
+func respond(c echo.Context, data interface{}, err error) error {
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    data,
+	})
+}
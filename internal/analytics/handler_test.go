@@ -0,0 +1,227 @@
+package analytics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetRevenue(params Request) (*[]RevenuePoint, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]RevenuePoint), args.Error(1)
+}
+
+func (m *MockService) GetBookingStatusCounts(params Request) (*[]StatusCount, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]StatusCount), args.Error(1)
+}
+
+func (m *MockService) GetOccupancy(params Request) (*[]SlotOccupancy, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]SlotOccupancy), args.Error(1)
+}
+
+func (m *MockService) GetTopItems(params Request) (*[]TopItem, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]TopItem), args.Error(1)
+}
+
+func (m *MockService) GetSummary(params Request) (*Summary, error) {
+	args := m.Called(params)
+	return args.Get(0).(*Summary), args.Error(1)
+}
+
+func (m *MockService) GetRatingTrend(params Request) (*[]RatingPoint, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]RatingPoint), args.Error(1)
+}
+
+func newBusinessAdminContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	userModel := user.Model{
+		ID:     1,
+		Status: util.StatusBusinessAdmin,
+	}
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userFromDatabase", &userModel)
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_GetRevenue(t *testing.T) {
+	url := "/api/v1/business-admin/analytics/revenue?start_date=2022-01-01&end_date=2022-12-31&interval=month"
+	params := Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31", Interval: "month"}
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("GetRevenue", params).Return(&[]RevenuePoint{{Period: "2022-01-01", Revenue: 10000, BookingCount: 1}}, nil)
+
+		if assert.NoError(t, h.GetRevenue(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"booking_count":1`)
+		}
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetRevenue(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("GetRevenue", params).Return(&[]RevenuePoint{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.GetRevenue(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("GetRevenue", params).Return(&[]RevenuePoint{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetRevenue(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetBookingStatusCounts(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/analytics/bookings?start_date=2022-01-01&end_date=2022-12-31", nil)
+	rec := httptest.NewRecorder()
+	ctx := newBusinessAdminContext(e, req, rec, "password")
+
+	mockService := new(MockService)
+	h := NewHandler(mockService)
+	mockService.On("GetBookingStatusCounts", Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&[]StatusCount{{Status: 2, Count: 3}}, nil)
+
+	if assert.NoError(t, h.GetBookingStatusCounts(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestHandler_GetOccupancy(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/analytics/occupancy?start_date=2022-01-01&end_date=2022-12-31", nil)
+	rec := httptest.NewRecorder()
+	ctx := newBusinessAdminContext(e, req, rec, "password")
+
+	mockService := new(MockService)
+	h := NewHandler(mockService)
+	mockService.On("GetOccupancy", Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&[]SlotOccupancy{{Day: 1, OccupancyRate: 0.5}}, nil)
+
+	if assert.NoError(t, h.GetOccupancy(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"occupancy_rate":0.5`)
+	}
+}
+
+func TestHandler_GetTopItems(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/analytics/items?start_date=2022-01-01&end_date=2022-12-31&limit=5", nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("GetTopItems", Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31", Limit: 5}).Return(&[]TopItem{{ItemID: 1, Name: "Kopi", Qty: 2}}, nil)
+
+		if assert.NoError(t, h.GetTopItems(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed invalid limit", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/analytics/items?start_date=2022-01-01&end_date=2022-12-31&limit=abc", nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetTopItems(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_GetSummary(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/analytics/summary?start_date=2022-01-01&end_date=2022-12-31", nil)
+	rec := httptest.NewRecorder()
+	ctx := newBusinessAdminContext(e, req, rec, "password")
+
+	mockService := new(MockService)
+	h := NewHandler(mockService)
+	mockService.On("GetSummary", Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&Summary{TotalBookings: 4, FailedBookings: 1, FailedRate: 0.25}, nil)
+
+	if assert.NoError(t, h.GetSummary(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"failed_rate":0.25`)
+	}
+}
+
+func TestHandler_GetRatingTrend(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/analytics/ratings?start_date=2022-01-01&end_date=2022-12-31&interval=week", nil)
+	rec := httptest.NewRecorder()
+	ctx := newBusinessAdminContext(e, req, rec, "password")
+
+	mockService := new(MockService)
+	h := NewHandler(mockService)
+	mockService.On("GetRatingTrend", Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31", Interval: "week"}).Return(&[]RatingPoint{{Period: "2022-01-03", AverageRating: 4}}, nil)
+
+	if assert.NoError(t, h.GetRatingTrend(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
@@ -0,0 +1,165 @@
+package analytics
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// NewRepo used to initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	GetPlaceIDByUserID(userID int) (int, error)
+	GetRevenue(filter Filter) (*[]RevenuePoint, error)
+	GetBookingStatusCounts(filter Filter) (*[]StatusCount, error)
+	GetOccupancy(filter Filter) (*[]SlotOccupancy, error)
+	GetTopItems(filter Filter) (*[]TopItem, error)
+	GetSummary(filter Filter) (*Summary, error)
+	GetRatingTrend(filter Filter) (*[]RatingPoint, error)
+}
+
+func (r repo) GetPlaceIDByUserID(userID int) (int, error) {
+	var placeID int
+
+	query := "SELECT id FROM places WHERE user_id = $1"
+	err := r.db.Get(&placeID, query, userID)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return placeID, nil
+}
+
+func (r repo) GetRevenue(filter Filter) (*[]RevenuePoint, error) {
+	result := make([]RevenuePoint, 0)
+
+	query := `SELECT to_char(date_trunc($4, b.date), 'YYYY-MM-DD') AS period,
+				SUM(b.total_price + COALESCE(b.booking_price, p.booking_price, 0)) AS revenue,
+				COUNT(b.id) AS booking_count
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			WHERE b.place_id = $1 AND b.date BETWEEN $2 AND $3 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
+			GROUP BY 1
+			ORDER BY 1`
+
+	err := r.db.Select(&result, query, filter.PlaceID, filter.StartDate, filter.EndDate, filter.Interval)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetBookingStatusCounts(filter Filter) (*[]StatusCount, error) {
+	result := make([]StatusCount, 0)
+
+	query := `SELECT status, COUNT(id) AS count
+			FROM bookings
+			WHERE place_id = $1 AND date BETWEEN $2 AND $3
+			GROUP BY status
+			ORDER BY status`
+
+	err := r.db.Select(&result, query, filter.PlaceID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetOccupancy(filter Filter) (*[]SlotOccupancy, error) {
+	result := make([]SlotOccupancy, 0)
+
+	// total capacity is the place capacity multiplied by the number of days the time slot is open in the range
+	query := `SELECT ts.day,
+				to_char(ts.start_time, 'HH24:MI:SS') AS start_time,
+				to_char(ts.end_time, 'HH24:MI:SS') AS end_time,
+				COALESCE(SUM(b.capacity), 0) AS booked_capacity,
+				p.capacity * (
+					SELECT COUNT(*) FROM generate_series($2::date, $3::date, interval '1 day') AS d
+					WHERE EXTRACT(DOW FROM d) = ts.day
+				) AS total_capacity
+			FROM time_slots ts
+			INNER JOIN places p ON ts.place_id = p.id
+			LEFT JOIN bookings b ON b.place_id = ts.place_id AND b.date BETWEEN $2 AND $3
+				AND EXTRACT(DOW FROM b.date) = ts.day
+				AND b.start_time <= ts.start_time AND b.end_time >= ts.end_time
+				AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
+			WHERE ts.place_id = $1
+			GROUP BY ts.id, ts.day, ts.start_time, ts.end_time, p.capacity
+			ORDER BY ts.day, ts.start_time`
+
+	err := r.db.Select(&result, query, filter.PlaceID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetTopItems(filter Filter) (*[]TopItem, error) {
+	result := make([]TopItem, 0)
+
+	query := `SELECT i.id AS item_id, i.name, SUM(bi.qty) AS qty, SUM(bi.total_price) AS revenue
+			FROM booking_items bi
+			INNER JOIN bookings b ON bi.booking_id = b.id
+			INNER JOIN items i ON bi.item_id = i.id
+			WHERE b.place_id = $1 AND b.date BETWEEN $2 AND $3 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
+			GROUP BY i.id, i.name
+			ORDER BY qty DESC, i.id
+			LIMIT $4`
+
+	err := r.db.Select(&result, query, filter.PlaceID, filter.StartDate, filter.EndDate, filter.Limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetSummary(filter Filter) (*Summary, error) {
+	var result Summary
+
+	query := `SELECT COUNT(id) AS total_bookings,
+				COUNT(id) FILTER (WHERE status = 2 OR status = 3 OR status = 5 OR status = 6) AS successful_bookings,
+				COUNT(id) FILTER (WHERE status = 4) AS failed_bookings,
+				COUNT(id) FILTER (WHERE status = 6) AS no_show_bookings,
+				COALESCE(AVG(capacity) FILTER (WHERE status = 2 OR status = 3 OR status = 5 OR status = 6), 0) AS average_party_size
+			FROM bookings
+			WHERE place_id = $1 AND date BETWEEN $2 AND $3`
+
+	err := r.db.Get(&result, query, filter.PlaceID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetRatingTrend(filter Filter) (*[]RatingPoint, error) {
+	result := make([]RatingPoint, 0)
+
+	query := `SELECT to_char(date_trunc($4, created_at), 'YYYY-MM-DD') AS period,
+				AVG(rating) AS average_rating,
+				COUNT(id) AS review_count
+			FROM reviews
+			WHERE place_id = $1 AND created_at >= $2::date AND created_at < $3::date + 1
+			GROUP BY 1
+			ORDER BY 1`
+
+	err := r.db.Select(&result, query, filter.PlaceID, filter.StartDate, filter.EndDate, filter.Interval)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
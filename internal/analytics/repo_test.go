@@ -0,0 +1,211 @@
+package analytics
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+var filter = Filter{PlaceID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31", Interval: "month", Limit: 10}
+
+func TestRepo_GetPlaceIDByUserID(t *testing.T) {
+	query := "SELECT id FROM places WHERE user_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(2))
+
+		placeID, err := repoMock.GetPlaceIDByUserID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, placeID)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetPlaceIDByUserID(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetRevenue(t *testing.T) {
+	query := "SELECT to_char(date_trunc($4, b.date), 'YYYY-MM-DD') AS period"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"period", "revenue", "booking_count"}).
+			AddRow("2022-01-01", 150000.0, 3).
+			AddRow("2022-02-01", 50000.0, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-01-01", "2022-12-31", "month").WillReturnRows(rows)
+
+		revenue, err := repoMock.GetRevenue(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, &[]RevenuePoint{
+			{Period: "2022-01-01", Revenue: 150000, BookingCount: 3},
+			{Period: "2022-02-01", Revenue: 50000, BookingCount: 1},
+		}, revenue)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetRevenue(filter)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetBookingStatusCounts(t *testing.T) {
+	query := "SELECT status, COUNT(id) AS count FROM bookings"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"status", "count"}).AddRow(2, 5).AddRow(4, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-01-01", "2022-12-31").WillReturnRows(rows)
+
+		statusCounts, err := repoMock.GetBookingStatusCounts(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, &[]StatusCount{{Status: 2, Count: 5}, {Status: 4, Count: 1}}, statusCounts)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetBookingStatusCounts(filter)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetOccupancy(t *testing.T) {
+	query := "FROM time_slots ts"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"day", "start_time", "end_time", "booked_capacity", "total_capacity"}).
+			AddRow(1, "10:00:00", "11:00:00", 20, 520)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-01-01", "2022-12-31").WillReturnRows(rows)
+
+		occupancy, err := repoMock.GetOccupancy(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, &[]SlotOccupancy{{Day: 1, StartTime: "10:00:00", EndTime: "11:00:00", BookedCapacity: 20, TotalCapacity: 520}}, occupancy)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetOccupancy(filter)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetTopItems(t *testing.T) {
+	query := "FROM booking_items bi"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"item_id", "name", "qty", "revenue"}).AddRow(3, "Nasi Goreng", 12, 240000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-01-01", "2022-12-31", 10).WillReturnRows(rows)
+
+		items, err := repoMock.GetTopItems(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, &[]TopItem{{ItemID: 3, Name: "Nasi Goreng", Qty: 12, Revenue: 240000}}, items)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetTopItems(filter)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetSummary(t *testing.T) {
+	query := "SELECT COUNT(id) AS total_bookings"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"total_bookings", "successful_bookings", "failed_bookings", "no_show_bookings", "average_party_size"}).AddRow(10, 8, 2, 1, 3.5)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-01-01", "2022-12-31").WillReturnRows(rows)
+
+		summary, err := repoMock.GetSummary(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, &Summary{TotalBookings: 10, SuccessfulBookings: 8, FailedBookings: 2, NoShowBookings: 1, AveragePartySize: 3.5}, summary)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetSummary(filter)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetRatingTrend(t *testing.T) {
+	query := "SELECT to_char(date_trunc($4, created_at), 'YYYY-MM-DD') AS period"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"period", "average_rating", "review_count"}).AddRow("2022-01-01", 4.5, 2)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-01-01", "2022-12-31", "month").WillReturnRows(rows)
+
+		ratings, err := repoMock.GetRatingTrend(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, &[]RatingPoint{{Period: "2022-01-01", AverageRating: 4.5, ReviewCount: 2}}, ratings)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetRatingTrend(filter)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	GetRevenue(params Request) (*[]RevenuePoint, error)
+	GetBookingStatusCounts(params Request) (*[]StatusCount, error)
+	GetOccupancy(params Request) (*[]SlotOccupancy, error)
+	GetTopItems(params Request) (*[]TopItem, error)
+	GetSummary(params Request) (*Summary, error)
+	GetRatingTrend(params Request) (*[]RatingPoint, error)
+}
+
+type service struct {
+	repo Repo
+}
+
+// NewService for initialize service
+func NewService(repo Repo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s service) GetRevenue(params Request) (*[]RevenuePoint, error) {
+	filter, err := s.buildFilter(params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetRevenue(*filter)
+}
+
+func (s service) GetBookingStatusCounts(params Request) (*[]StatusCount, error) {
+	filter, err := s.buildFilter(params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetBookingStatusCounts(*filter)
+}
+
+func (s service) GetOccupancy(params Request) (*[]SlotOccupancy, error) {
+	filter, err := s.buildFilter(params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	occupancy, err := s.repo.GetOccupancy(*filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, slot := range *occupancy {
+		if slot.TotalCapacity > 0 {
+			(*occupancy)[i].OccupancyRate = float64(slot.BookedCapacity) / float64(slot.TotalCapacity)
+		}
+	}
+
+	return occupancy, nil
+}
+
+func (s service) GetTopItems(params Request) (*[]TopItem, error) {
+	if params.Limit == 0 {
+		params.Limit = util.DefaultAnalyticsTopItems
+	}
+
+	filter, err := s.buildFilter(params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTopItems(*filter)
+}
+
+func (s service) GetSummary(params Request) (*Summary, error) {
+	filter, err := s.buildFilter(params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.GetSummary(*filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary.TotalBookings > 0 {
+		summary.FailedRate = float64(summary.FailedBookings) / float64(summary.TotalBookings)
+		summary.NoShowRate = float64(summary.NoShowBookings) / float64(summary.TotalBookings)
+	}
+
+	return summary, nil
+}
+
+func (s service) GetRatingTrend(params Request) (*[]RatingPoint, error) {
+	filter, err := s.buildFilter(params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetRatingTrend(*filter)
+}
+
+// buildFilter validate the analytics request and resolve the place of the business admin
+func (s service) buildFilter(params Request, withInterval bool) (*Filter, error) {
+	var errorList []string
+
+	if params.UserID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	startDate, errStartDate := time.Parse(util.DateLayout, params.StartDate)
+	if errStartDate != nil {
+		errorList = append(errorList, "start_date should be in YYYY-MM-DD format")
+	}
+
+	endDate, errEndDate := time.Parse(util.DateLayout, params.EndDate)
+	if errEndDate != nil {
+		errorList = append(errorList, "end_date should be in YYYY-MM-DD format")
+	}
+
+	if errStartDate == nil && errEndDate == nil {
+		if endDate.Before(startDate) {
+			errorList = append(errorList, "end_date should not be before start_date")
+		} else if endDate.Sub(startDate).Hours()/24 >= util.MaximumReportRange {
+			errorList = append(errorList, fmt.Sprintf("date range should be at most %d days", util.MaximumReportRange))
+		}
+	}
+
+	if withInterval {
+		if params.Interval == "" {
+			params.Interval = util.AnalyticsIntervalDay
+		}
+
+		if params.Interval != util.AnalyticsIntervalDay && params.Interval != util.AnalyticsIntervalWeek && params.Interval != util.AnalyticsIntervalMonth {
+			errorList = append(errorList, fmt.Sprintf("interval should be %s, %s or %s", util.AnalyticsIntervalDay, util.AnalyticsIntervalWeek, util.AnalyticsIntervalMonth))
+		}
+	}
+
+	if params.Limit < 0 || params.Limit > util.MaximumAnalyticsTopItems {
+		errorList = append(errorList, fmt.Sprintf("limit should be between 1 and %d", util.MaximumAnalyticsTopItems))
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	placeID, err := s.repo.GetPlaceIDByUserID(params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Filter{
+		PlaceID:   placeID,
+		StartDate: params.StartDate,
+		EndDate:   params.EndDate,
+		Interval:  params.Interval,
+		Limit:     params.Limit,
+	}, nil
+}
@@ -0,0 +1,211 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetPlaceIDByUserID(userID int) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) GetRevenue(filter Filter) (*[]RevenuePoint, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*[]RevenuePoint), args.Error(1)
+}
+
+func (m *MockRepository) GetBookingStatusCounts(filter Filter) (*[]StatusCount, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*[]StatusCount), args.Error(1)
+}
+
+func (m *MockRepository) GetOccupancy(filter Filter) (*[]SlotOccupancy, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*[]SlotOccupancy), args.Error(1)
+}
+
+func (m *MockRepository) GetTopItems(filter Filter) (*[]TopItem, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*[]TopItem), args.Error(1)
+}
+
+func (m *MockRepository) GetSummary(filter Filter) (*Summary, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*Summary), args.Error(1)
+}
+
+func (m *MockRepository) GetRatingTrend(filter Filter) (*[]RatingPoint, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*[]RatingPoint), args.Error(1)
+}
+
+var request = Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31"}
+
+func TestService_GetRevenue(t *testing.T) {
+	t.Run("success with default interval", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		revenue := []RevenuePoint{{Period: "2022-01-01", Revenue: 10000, BookingCount: 1}}
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetRevenue", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31", Interval: "day"}).Return(&revenue, nil)
+
+		result, err := s.GetRevenue(request)
+		assert.NoError(t, err)
+		assert.Equal(t, &revenue, result)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		_, err := s.GetRevenue(Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2023-06-01", Interval: "year"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "date range should be at most 366 days")
+		assert.Contains(t, err.Error(), "interval should be day, week or month")
+	})
+
+	t.Run("failed end date before start date", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		_, err := s.GetRevenue(Request{UserID: 1, StartDate: "2022-02-01", EndDate: "2022-01-01"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get place", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(0, ErrInternalServerError)
+
+		_, err := s.GetRevenue(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetBookingStatusCounts(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		statusCounts := []StatusCount{{Status: 2, Count: 3}}
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetBookingStatusCounts", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&statusCounts, nil)
+
+		result, err := s.GetBookingStatusCounts(request)
+		assert.NoError(t, err)
+		assert.Equal(t, &statusCounts, result)
+	})
+
+	t.Run("failed invalid date", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		_, err := s.GetBookingStatusCounts(Request{UserID: 1, StartDate: "01-01-2022", EndDate: "2022-12-31"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetOccupancy(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		occupancy := []SlotOccupancy{
+			{Day: 1, StartTime: "10:00:00", EndTime: "11:00:00", BookedCapacity: 13, TotalCapacity: 52},
+			{Day: 2, StartTime: "10:00:00", EndTime: "11:00:00", BookedCapacity: 0, TotalCapacity: 0},
+		}
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetOccupancy", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&occupancy, nil)
+
+		result, err := s.GetOccupancy(request)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.25, (*result)[0].OccupancyRate)
+		assert.Equal(t, 0.0, (*result)[1].OccupancyRate)
+	})
+
+	t.Run("failed repo error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetOccupancy", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&[]SlotOccupancy{}, ErrInternalServerError)
+
+		_, err := s.GetOccupancy(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetTopItems(t *testing.T) {
+	t.Run("success with default limit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		items := []TopItem{{ItemID: 1, Name: "Kopi", Qty: 4, Revenue: 40000}}
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetTopItems", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31", Limit: 10}).Return(&items, nil)
+
+		result, err := s.GetTopItems(request)
+		assert.NoError(t, err)
+		assert.Equal(t, &items, result)
+	})
+
+	t.Run("failed limit too large", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		_, err := s.GetTopItems(Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31", Limit: 1000})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetSummary(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetSummary", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31"}).
+			Return(&Summary{TotalBookings: 10, SuccessfulBookings: 8, FailedBookings: 2, NoShowBookings: 1, AveragePartySize: 3}, nil)
+
+		result, err := s.GetSummary(request)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.2, result.FailedRate)
+		assert.Equal(t, 0.1, result.NoShowRate)
+	})
+
+	t.Run("failed repo error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetSummary", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31"}).Return(&Summary{}, ErrInternalServerError)
+
+		_, err := s.GetSummary(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetRatingTrend(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		s := NewService(mockRepo)
+
+		ratings := []RatingPoint{{Period: "2022-01-03", AverageRating: 4, ReviewCount: 2}}
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetRatingTrend", Filter{PlaceID: 2, StartDate: "2022-01-01", EndDate: "2022-12-31", Interval: "week"}).Return(&ratings, nil)
+
+		result, err := s.GetRatingTrend(Request{UserID: 1, StartDate: "2022-01-01", EndDate: "2022-12-31", Interval: "week"})
+		assert.NoError(t, err)
+		assert.Equal(t, &ratings, result)
+	})
+}
@@ -54,6 +54,11 @@ type RegisterRequest struct {
 	FullName string `json:"full_name"`
 }
 
+// RefreshTokenRequest represent request body for refreshing token and logging out
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // CheckPhoneNumberResponse response of check phone number endpoint
 type CheckPhoneNumberResponse struct {
 	SessionInfo string `json:"session_info"`
@@ -68,3 +73,11 @@ type VerifyOTPResult struct {
 	IsNewUser    bool   `json:"is_new_user"`
 	PhoneNumber  string `json:"phone_number"`
 }
+
+// RefreshTokenResult Result of refresh token endpoint
+type RefreshTokenResult struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    string `json:"expires_in"`
+	LocalID      string `json:"local_id"`
+}
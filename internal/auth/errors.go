@@ -5,6 +5,8 @@ import "github.com/pkg/errors"
 var (
 	// ErrInputValidation is returned when the input is invalid
 	ErrInputValidation = errors.New("input validation error")
+	// ErrUnauthorized is returned when the refresh token is invalid or revoked
+	ErrUnauthorized = errors.New("unauthorized")
 	// ErrInternalServer is returned when the server encounters an internal error
 	ErrInternalServer = errors.New("internal server error")
 )
@@ -120,3 +120,51 @@ func (h *Handler) Register(c echo.Context) error {
 		Data:    customer,
 	})
 }
+
+// RefreshToken for handling RefreshToken endpoint
+func (h *Handler) RefreshToken(c echo.Context) error {
+	var req RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, ErrInternalServer, err.Error())
+	}
+
+	resp, err := h.service.RefreshToken(req.RefreshToken)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidation:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrUnauthorized:
+			return util.ErrorWrapWithContext(c, http.StatusUnauthorized, err)
+		}
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    resp,
+	})
+}
+
+// Logout for handling Logout endpoint, used by both customer and business admin
+func (h *Handler) Logout(c echo.Context) error {
+	var req RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, ErrInternalServer, err.Error())
+	}
+
+	if err := h.service.Logout(middleware.ParseLocalID(c), middleware.ParseToken(c), req.RefreshToken); err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidation:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrUnauthorized:
+			return util.ErrorWrapWithContext(c, http.StatusUnauthorized, err)
+		}
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
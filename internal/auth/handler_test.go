@@ -56,6 +56,19 @@ func (m *MockService) GetCustomerByPhoneNumber(phoneNumber string) (*Customer, e
 	return args.Get(0).(*Customer), args.Error(1)
 }
 
+func (m *MockService) RefreshToken(refreshToken string) (*RefreshTokenResult, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RefreshTokenResult), args.Error(1)
+}
+
+func (m *MockService) Logout(localID, accessToken, refreshToken string) error {
+	args := m.Called(localID, accessToken, refreshToken)
+	return args.Error(0)
+}
+
 func TestHandler_CheckPhoneNumber(t *testing.T) {
 	t.Setenv("BASE_URL", "localhost:8080")
 	e := echo.New()
@@ -671,3 +684,135 @@ func TestHandler_Register(t *testing.T) {
 		assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
 	})
 }
+
+func TestHandler_RefreshToken(t *testing.T) {
+	e := echo.New()
+
+	resp := RefreshTokenResult{
+		AccessToken:  "test access token",
+		RefreshToken: "test new refresh token",
+		ExpiresIn:    "3600",
+		LocalID:      "test local id",
+	}
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		payload, _ := json.Marshal(map[string]string{
+			"refresh_token": "test refresh token",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		expectedResponseJSON, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    resp,
+		})
+
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("RefreshToken", "test refresh token").Return(&resp, nil)
+
+		ctx, rec := newContext()
+		assert.NoError(t, mockHandler.RefreshToken(ctx))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
+	})
+
+	errorCases := map[string]struct {
+		err            error
+		expectedStatus int
+	}{
+		"input validation error":    {errors.Wrap(ErrInputValidation, "test error"), http.StatusBadRequest},
+		"revoked refresh token":     {errors.Wrap(ErrUnauthorized, "test error"), http.StatusUnauthorized},
+		"internal error on service": {errors.Wrap(ErrInternalServer, "test error"), http.StatusInternalServerError},
+	}
+
+	for name, tc := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockService)
+			mockHandler := NewHandler(mockService)
+			mockService.On("RefreshToken", "test refresh token").Return(nil, tc.err)
+
+			ctx, rec := newContext()
+			util.ErrorHandler(mockHandler.RefreshToken(ctx), ctx)
+			mockService.AssertExpectations(t)
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+
+	t.Run("error binding request", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"refresh_token": 1}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		util.ErrorHandler(mockHandler.RefreshToken(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_Logout(t *testing.T) {
+	e := echo.New()
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		payload, _ := json.Marshal(map[string]string{
+			"refresh_token": "test refresh token",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("token", "test access token")
+		ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{Users: []firebaseauth.User{{LocalID: "test local id"}}})
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("Logout", "test local id", "test access token", "test refresh token").Return(nil)
+
+		ctx, rec := newContext()
+		assert.NoError(t, mockHandler.Logout(ctx))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("input validation error", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("Logout", "test local id", "test access token", "test refresh token").Return(errors.Wrap(ErrInputValidation, "test error"))
+
+		ctx, rec := newContext()
+		util.ErrorHandler(mockHandler.Logout(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("refresh token of another user", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("Logout", "test local id", "test access token", "test refresh token").Return(errors.Wrap(ErrUnauthorized, "test error"))
+
+		ctx, rec := newContext()
+		util.ErrorHandler(mockHandler.Logout(ctx), ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("internal error on service", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("Logout", "test local id", "test access token", "test refresh token").Return(errors.Wrap(ErrInternalServer, "test error"))
+
+		ctx, rec := newContext()
+		util.ErrorHandler(mockHandler.Logout(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
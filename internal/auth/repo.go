@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"github.com/golang-jwt/jwt"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"time"
 )
 
 // NewRepo PostgreSQL for auth module
@@ -23,6 +27,9 @@ type Repo interface {
 	CheckPhoneNumber(phoneNumber string) (bool, error)
 	CreateCustomer(customer Customer) (*Customer, error)
 	GetCustomerByPhoneNumber(phoneNumber string) (*Customer, error)
+	RevokeTokens(tokens ...string) error
+	RevokeToken(token string) (bool, error)
+	IsSessionRevoked(token, localID string, authTime time.Time) (bool, error)
 }
 
 func (r repo) CheckPhoneNumber(phoneNumber string) (bool, error) {
@@ -141,3 +148,79 @@ func (r repo) GetCustomerByPhoneNumber(phoneNumber string) (*Customer, error) {
 		Status:      status,
 	}, nil
 }
+
+// revokeTokenQuery save the hash of a token, a token which is already revoked is not inserted again
+const revokeTokenQuery = "INSERT INTO revoked_tokens (token_hash, expires_at) VALUES ($1, $2) ON CONFLICT (token_hash) DO NOTHING"
+
+// RevokeTokens save the hash of the tokens so they are rejected afterwards, the token itself is never stored,
+// revoked tokens which would have expired anyway are pruned in the same transaction
+func (r repo) RevokeTokens(tokens ...string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM revoked_tokens WHERE expires_at < $1", time.Now().UTC()); err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	for _, token := range tokens {
+		if _, err = tx.Exec(revokeTokenQuery, hashToken(token), tokenExpiry(token)); err != nil {
+			return errors.Wrap(ErrInternalServer, err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return nil
+}
+
+// RevokeToken save the hash of the token, false is returned when it is already revoked so a rotated refresh token
+// which is used twice is detected even when both requests are made concurrently
+func (r repo) RevokeToken(token string) (bool, error) {
+	result, err := r.db.Exec(revokeTokenQuery, hashToken(token), tokenExpiry(token))
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// IsSessionRevoked return true when the token is revoked by logging out or the user has invalidated
+// every session signed in before the password is changed, sessions_valid_after is saved in UTC
+func (r repo) IsSessionRevoked(token, localID string, authTime time.Time) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_hash=$1)
+		OR EXISTS(SELECT 1 FROM users WHERE firebase_local_id=$2 AND sessions_valid_after > $3)`
+	err := r.db.Get(&revoked, query, hashToken(token), localID, authTime.UTC())
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return revoked, nil
+}
+
+// tokenExpiry return the expiry of a JWT in UTC, the signature is not verified here because the service only revoke
+// a token verified by the provider, nil is returned for opaque token such as firebase refresh token which never expire
+func tokenExpiry(token string) *time.Time {
+	var claims jwt.StandardClaims
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, &claims); err != nil || claims.ExpiresAt == 0 {
+		return nil
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0).UTC()
+	return &expiresAt
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -355,3 +356,219 @@ func TestRepo_GetCustomerByPhoneNumber(t *testing.T) {
 		assert.Nil(t, customer)
 	})
 }
+
+func TestRepo_RevokeTokens(t *testing.T) {
+	pruneQuery := "DELETE FROM revoked_tokens WHERE expires_at < $1"
+	query := "INSERT INTO revoked_tokens (token_hash, expires_at) VALUES ($1, $2) ON CONFLICT (token_hash) DO NOTHING"
+	expiresAt := time.Unix(1650003600, 0).UTC()
+	accessToken := newTestJWT(expiresAt)
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(pruneQuery)).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken(accessToken), &expiresAt).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken("refresh token"), nil).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repoMock.RevokeTokens(accessToken, "refresh token")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to begin transaction", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+
+		err = repoMock.RevokeTokens("access token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+
+	t.Run("failed to prune", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(pruneQuery)).WithArgs(sqlmock.AnyArg()).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.RevokeTokens("access token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to insert", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(pruneQuery)).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken("access token"), nil).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.RevokeTokens("access token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to commit", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(pruneQuery)).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken("access token"), nil).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(sql.ErrConnDone)
+
+		err = repoMock.RevokeTokens("access token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_RevokeToken(t *testing.T) {
+	query := "INSERT INTO revoked_tokens (token_hash, expires_at) VALUES ($1, $2) ON CONFLICT (token_hash) DO NOTHING"
+	expiresAt := time.Unix(1652592000, 0).UTC()
+	refreshToken := newTestJWT(expiresAt)
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken(refreshToken), &expiresAt).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		revoked, err := repoMock.RevokeToken(refreshToken)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("already revoked", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken(refreshToken), &expiresAt).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		revoked, err := repoMock.RevokeToken(refreshToken)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(hashToken(refreshToken), &expiresAt).WillReturnError(sql.ErrConnDone)
+
+		revoked, err := repoMock.RevokeToken(refreshToken)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.False(t, revoked)
+	})
+}
+
+func TestRepo_IsSessionRevoked(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	repoMock := NewRepo(sqlxDB)
+	query := "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_hash=$1)"
+	authTime := time.Unix(1650000000, 0).In(time.FixedZone("WIB", 7*60*60))
+
+	t.Run("revoked", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(hashToken("token"), "local id", authTime.UTC()).
+			WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+
+		revoked, err := repoMock.IsSessionRevoked("token", "local id", authTime)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("not revoked", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(hashToken("token"), "local id", authTime.UTC()).
+			WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(false))
+
+		revoked, err := repoMock.IsSessionRevoked("token", "local id", authTime)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(hashToken("token"), "local id", authTime.UTC()).WillReturnError(sql.ErrConnDone)
+
+		revoked, err := repoMock.IsSessionRevoked("token", "local id", authTime)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.False(t, revoked)
+	})
+}
+
+func TestTokenExpiry(t *testing.T) {
+	expiresAt := time.Unix(1650003600, 0).UTC()
+
+	assert.Equal(t, &expiresAt, tokenExpiry(newTestJWT(expiresAt)))
+	assert.Nil(t, tokenExpiry("opaque refresh token"))
+}
+
+func newTestJWT(expiresAt time.Time) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{ExpiresAt: expiresAt.Unix()}).SignedString([]byte("secret"))
+	return token
+}
+
+func TestHashToken(t *testing.T) {
+	assert.Len(t, hashToken("token"), 64)
+	assert.Equal(t, hashToken("token"), hashToken("token"))
+	assert.NotEqual(t, hashToken("token"), hashToken("other token"))
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"fmt"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth_provider"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 	"strings"
@@ -10,15 +11,15 @@ import (
 )
 
 type service struct {
-	repo             Repo
-	firebaseAuthRepo firebaseauth.Repo
+	repo         Repo
+	authProvider authprovider.Provider
 }
 
 // NewService for initialize service
-func NewService(repo Repo, firebaseAuthRepo firebaseauth.Repo) Service {
+func NewService(repo Repo, authProvider authprovider.Provider) Service {
 	return &service{
-		repo:             repo,
-		firebaseAuthRepo: firebaseAuthRepo,
+		repo:         repo,
+		authProvider: authProvider,
 	}
 }
 
@@ -29,6 +30,8 @@ type Service interface {
 	VerifyOTP(sessionInfo, otp string) (*VerifyOTPResult, error)
 	Register(customer Customer) (*Customer, error)
 	GetCustomerByPhoneNumber(phoneNumber string) (*Customer, error)
+	RefreshToken(refreshToken string) (*RefreshTokenResult, error)
+	Logout(localID, accessToken, refreshToken string) error
 }
 
 func (s service) CheckPhoneNumber(phoneNumber string) (bool, error) {
@@ -71,7 +74,7 @@ func (s service) SendOTP(phoneNumber, recaptchaToken string) (string, error) {
 		RecaptchaToken: recaptchaToken,
 	}
 
-	resp, err := s.firebaseAuthRepo.SendOTP(firebaseParams)
+	resp, err := s.authProvider.SendOTP(firebaseParams)
 	if err != nil {
 		if errors.Cause(err) == firebaseauth.ErrInputValidation {
 			return "", errors.Wrap(ErrInputValidation, err.Error())
@@ -102,7 +105,7 @@ func (s service) VerifyOTP(sessionInfo, otp string) (*VerifyOTPResult, error) {
 		Code:        otp,
 	}
 
-	resp, err := s.firebaseAuthRepo.VerifyOTP(firebaseParams)
+	resp, err := s.authProvider.VerifyOTP(firebaseParams)
 	if err != nil {
 		if errors.Cause(err) == firebaseauth.ErrInputValidation {
 			return nil, errors.Wrap(ErrInputValidation, err.Error())
@@ -161,3 +164,79 @@ func (s service) GetCustomerByPhoneNumber(phoneNumber string) (*Customer, error)
 
 	return s.repo.GetCustomerByPhoneNumber(phoneNumber)
 }
+
+// RefreshToken exchange the refresh token for a new access token, the old refresh token is revoked when
+// the provider rotate it so it can not be used twice, a reused refresh token is rejected
+func (s service) RefreshToken(refreshToken string) (*RefreshTokenResult, error) {
+	if refreshToken == "" {
+		return nil, errors.Wrap(ErrInputValidation, "refresh token is required")
+	}
+
+	resp, err := s.authProvider.RefreshToken(refreshToken)
+	if err != nil {
+		if errors.Cause(err) == firebaseauth.ErrInputValidation {
+			return nil, errors.Wrap(ErrUnauthorized, err.Error())
+		}
+		return nil, err
+	}
+
+	revoked, err := s.repo.IsSessionRevoked(refreshToken, resp.UserID, resp.AuthTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, errors.Wrap(ErrUnauthorized, "refresh token has been revoked")
+	}
+
+	if resp.RefreshToken != refreshToken {
+		rotated, err := s.repo.RevokeToken(refreshToken)
+		if err != nil {
+			return nil, err
+		}
+
+		// another request has already exchanged the refresh token
+		if !rotated {
+			return nil, errors.Wrap(ErrUnauthorized, "refresh token has been used")
+		}
+	}
+
+	return &RefreshTokenResult{
+		AccessToken:  resp.IDToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
+		LocalID:      resp.UserID,
+	}, nil
+}
+
+// Logout revoke the access and refresh token of the session, the refresh token is verified by the provider
+// and rejected when it does not belong to the signed in user so only a real token with its expiry is stored
+func (s service) Logout(localID, accessToken, refreshToken string) error {
+	var errorList []string
+
+	if accessToken == "" {
+		errorList = append(errorList, "access token is required")
+	}
+
+	if refreshToken == "" {
+		errorList = append(errorList, "refresh token is required")
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidation, strings.Join(errorList, ","))
+	}
+
+	resp, err := s.authProvider.RefreshToken(refreshToken)
+	if err != nil {
+		if errors.Cause(err) == firebaseauth.ErrInputValidation {
+			return errors.Wrap(ErrUnauthorized, err.Error())
+		}
+		return err
+	}
+
+	if resp.UserID != localID {
+		return errors.Wrap(ErrUnauthorized, "refresh token does not belong to the user")
+	}
+
+	return s.repo.RevokeTokens(accessToken, refreshToken)
+}
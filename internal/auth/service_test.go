@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
 	"testing"
+	"time"
 )
 
 type MockRepository struct {
@@ -27,6 +28,21 @@ func (m *MockRepository) GetCustomerByPhoneNumber(phoneNumber string) (*Customer
 	return args.Get(0).(*Customer), args.Error(1)
 }
 
+func (m *MockRepository) RevokeTokens(tokens ...string) error {
+	args := m.Called(tokens)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RevokeToken(token string) (bool, error) {
+	args := m.Called(token)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) IsSessionRevoked(token, localID string, authTime time.Time) (bool, error) {
+	args := m.Called(token, localID, authTime)
+	return args.Bool(0), args.Error(1)
+}
+
 type FirebaseMockRepository struct {
 	mock.Mock
 }
@@ -41,6 +57,24 @@ func (f *FirebaseMockRepository) VerifyOTP(params firebaseauth.VerifyOTPParams)
 	return args.Get(0).(*firebaseauth.VerifyOTPResult), args.Error(1)
 }
 
+func (f *FirebaseMockRepository) SignInWithPassword(params firebaseauth.SignInWithPasswordParams) (*firebaseauth.SignInWithPasswordResult, error) {
+	args := f.Called(params)
+	return args.Get(0).(*firebaseauth.SignInWithPasswordResult), args.Error(1)
+}
+
+func (f *FirebaseMockRepository) RefreshToken(refreshToken string) (*firebaseauth.RefreshTokenResult, error) {
+	args := f.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*firebaseauth.RefreshTokenResult), args.Error(1)
+}
+
+func (f *FirebaseMockRepository) UpdatePassword(params firebaseauth.UpdatePasswordParams) error {
+	args := f.Called(params)
+	return args.Error(0)
+}
+
 func (f *FirebaseMockRepository) GetUserDataFromToken(token string) (*firebaseauth.UserDataFromToken, error) {
 	args := f.Called(token)
 	return args.Get(0).(*firebaseauth.UserDataFromToken), args.Error(1)
@@ -318,3 +352,204 @@ func TestService_GetCustomerByPhoneNumber(t *testing.T) {
 		assert.Equal(t, &customer, actual)
 	})
 }
+
+func TestService_RefreshToken(t *testing.T) {
+	refreshResult := firebaseauth.RefreshTokenResult{
+		IDToken:      "new access token",
+		RefreshToken: "new refresh token",
+		ExpiresIn:    "3600",
+		UserID:       "local id",
+		AuthTime:     time.Unix(1650000000, 0),
+	}
+
+	t.Run("success and old refresh token is revoked", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		mockRepo.On("IsSessionRevoked", "refresh token", "local id", refreshResult.AuthTime).Return(false, nil)
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("RevokeToken", "refresh token").Return(true, nil)
+
+		result, err := mockService.RefreshToken("refresh token")
+		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+		assert.Equal(t, &RefreshTokenResult{
+			AccessToken:  "new access token",
+			RefreshToken: "new refresh token",
+			ExpiresIn:    "3600",
+			LocalID:      "local id",
+		}, result)
+	})
+
+	t.Run("success and refresh token is not rotated", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		sameRefreshResult := refreshResult
+		sameRefreshResult.RefreshToken = "refresh token"
+		mockRepo.On("IsSessionRevoked", "refresh token", "local id", refreshResult.AuthTime).Return(false, nil)
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&sameRefreshResult, nil)
+
+		result, err := mockService.RefreshToken("refresh token")
+		mockRepo.AssertNotCalled(t, "RevokeToken", mock.Anything)
+		assert.NoError(t, err)
+		assert.Equal(t, "refresh token", result.RefreshToken)
+	})
+
+	t.Run("empty refresh token", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(FirebaseMockRepository))
+
+		result, err := mockService.RefreshToken("")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+
+	t.Run("revoked refresh token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("IsSessionRevoked", "refresh token", "local id", refreshResult.AuthTime).Return(true, nil)
+
+		result, err := mockService.RefreshToken("refresh token")
+		mockRepo.AssertNotCalled(t, "RevokeToken", mock.Anything)
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid refresh token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(nil, errors.Wrap(firebaseauth.ErrInputValidation, "invalid refresh token"))
+
+		result, err := mockService.RefreshToken("refresh token")
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed to check revocation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("IsSessionRevoked", "refresh token", "local id", refreshResult.AuthTime).Return(false, errors.Wrap(ErrInternalServer, "test error"))
+
+		result, err := mockService.RefreshToken("refresh token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed to revoke old refresh token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		mockRepo.On("IsSessionRevoked", "refresh token", "local id", refreshResult.AuthTime).Return(false, nil)
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("RevokeToken", "refresh token").Return(false, errors.Wrap(ErrInternalServer, "test error"))
+
+		result, err := mockService.RefreshToken("refresh token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+
+	t.Run("reused refresh token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		mockRepo.On("IsSessionRevoked", "refresh token", "local id", refreshResult.AuthTime).Return(false, nil)
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("RevokeToken", "refresh token").Return(false, nil)
+
+		result, err := mockService.RefreshToken("refresh token")
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+		assert.Contains(t, err.Error(), "refresh token has been used")
+		assert.Nil(t, result)
+	})
+}
+
+func TestService_Logout(t *testing.T) {
+	refreshResult := firebaseauth.RefreshTokenResult{
+		IDToken:      "new access token",
+		RefreshToken: "refresh token",
+		ExpiresIn:    "3600",
+		UserID:       "local id",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("RevokeTokens", []string{"access token", "refresh token"}).Return(nil)
+
+		err := mockService.Logout("local id", "access token", "refresh token")
+		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty tokens", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(FirebaseMockRepository))
+
+		err := mockService.Logout("local id", "", "")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "refresh token is required")
+	})
+
+	t.Run("invalid refresh token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(nil, errors.Wrap(firebaseauth.ErrInputValidation, "INVALID_REFRESH_TOKEN"))
+
+		err := mockService.Logout("local id", "access token", "refresh token")
+		mockRepo.AssertNotCalled(t, "RevokeTokens", mock.Anything)
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+	})
+
+	t.Run("failed to verify refresh token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(nil, errors.Wrap(firebaseauth.ErrInternalServer, "test error"))
+
+		err := mockService.Logout("local id", "access token", "refresh token")
+		mockRepo.AssertNotCalled(t, "RevokeTokens", mock.Anything)
+		assert.Equal(t, firebaseauth.ErrInternalServer, errors.Cause(err))
+	})
+
+	t.Run("refresh token of another user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+
+		err := mockService.Logout("other local id", "access token", "refresh token")
+		mockRepo.AssertNotCalled(t, "RevokeTokens", mock.Anything)
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+		assert.Contains(t, err.Error(), "refresh token does not belong to the user")
+	})
+
+	t.Run("failed to revoke tokens", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		firebaseMockRepo := new(FirebaseMockRepository)
+		mockService := NewService(mockRepo, firebaseMockRepo)
+
+		firebaseMockRepo.On("RefreshToken", "refresh token").Return(&refreshResult, nil)
+		mockRepo.On("RevokeTokens", []string{"access token", "refresh token"}).Return(errors.Wrap(ErrInternalServer, "test error"))
+
+		err := mockService.Logout("local id", "access token", "refresh token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
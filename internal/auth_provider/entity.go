@@ -0,0 +1,46 @@
+package authprovider
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	tokenIssuer = "majapahit-service"
+
+	tokenTypeAccess     = "access"
+	tokenTypeRefresh    = "refresh"
+	tokenTypeOTPSession = "otp_session"
+
+	signInProviderPhone    = "phone"
+	signInProviderPassword = "password"
+)
+
+// Credential represent the user used for signing in
+type Credential struct {
+	ID          int    `db:"id"`
+	LocalID     string `db:"local_id"`
+	PhoneNumber string `db:"phone_number"`
+	Email       string `db:"email"`
+	Password    string `db:"password"`
+}
+
+// OTPCode represent otp_codes table on database
+type OTPCode struct {
+	ID          int       `db:"id"`
+	OTP         int       `db:"otp"`
+	ExpiredDate time.Time `db:"expired_date"`
+	PhoneNumber string    `db:"phone_number"`
+	Attempts    int       `db:"attempts"`
+}
+
+// tokenClaims is the claims of access, refresh and otp session token issued by local provider
+type tokenClaims struct {
+	jwt.StandardClaims
+	TokenType      string `json:"token_type"`
+	AuthTime       int64  `json:"auth_time,omitempty"`
+	SignInProvider string `json:"sign_in_provider,omitempty"`
+	PhoneNumber    string `json:"phone_number,omitempty"`
+	Email          string `json:"email,omitempty"`
+}
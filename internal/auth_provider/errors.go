@@ -0,0 +1,20 @@
+package authprovider
+
+import (
+	"github.com/pkg/errors"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+)
+
+// errors of the providers are shared with firebase, so the caller handle every provider the same way
+var (
+	// ErrInternalServer for unknown error from provider
+	ErrInternalServer = firebaseauth.ErrInternalServer
+
+	// ErrInputValidation for invalid otp, credential or token
+	ErrInputValidation = firebaseauth.ErrInputValidation
+)
+
+var (
+	errTokenExpired = errors.New("token expired")
+	errTokenInvalid = errors.New("token invalid")
+)
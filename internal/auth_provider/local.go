@@ -0,0 +1,359 @@
+package authprovider
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type localProvider struct {
+	repo   Repo
+	secret []byte
+	sms    notification.Channel
+	now    func() time.Time
+}
+
+// NewLocalProvider for initialize the self-hosted provider which issue its own token signed with secret,
+// OTP code is only written to the log when sms is nil, which is only allowed on local environment
+func NewLocalProvider(repo Repo, secret string, sms notification.Channel) Provider {
+	return &localProvider{
+		repo:   repo,
+		secret: []byte(secret),
+		sms:    sms,
+		now:    time.Now,
+	}
+}
+
+// SendOTP save a new OTP code and send it to the phone number, the session info is a signed token of the code ID
+func (p *localProvider) SendOTP(params firebaseauth.SendOTPParams) (*firebaseauth.SendOTPResult, error) {
+	if params.PhoneNumber == "" || !util.PhoneNumberRegex.MatchString(params.PhoneNumber) {
+		return nil, errors.Wrap(ErrInputValidation, "phone number is invalid")
+	}
+
+	otp, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	expiredDate := p.now().Add(util.OTPExpiryMinutes * time.Minute)
+	otpID, err := p.repo.CreateOTP(params.PhoneNumber, int(otp.Int64()), expiredDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionInfo, err := p.sign(tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        strconv.Itoa(otpID),
+			Subject:   params.PhoneNumber,
+			ExpiresAt: expiredDate.Unix(),
+		},
+		TokenType: tokenTypeOTPSession,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("Kode OTP kamu adalah %06d, berlaku selama %d menit. Jangan berikan kode ini kepada siapa pun.", otp.Int64(), util.OTPExpiryMinutes)
+	if p.sms == nil {
+		logrus.Infof("[otp for %s] %s", params.PhoneNumber, body)
+		return &firebaseauth.SendOTPResult{SessionInfo: sessionInfo}, nil
+	}
+
+	err = p.sms.Send(notification.Message{
+		Channel:   util.NotificationChannelSMS,
+		Recipient: params.PhoneNumber,
+		Subject:   "Majapahit",
+		Body:      body,
+	})
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &firebaseauth.SendOTPResult{SessionInfo: sessionInfo}, nil
+}
+
+// VerifyOTP check the code of the session, a code can only be used once and is discarded after too many wrong attempts
+func (p *localProvider) VerifyOTP(params firebaseauth.VerifyOTPParams) (*firebaseauth.VerifyOTPResult, error) {
+	var claims tokenClaims
+	if err := p.parse(params.SessionInfo, tokenTypeOTPSession, &claims); err != nil {
+		if errors.Cause(err) == errTokenExpired {
+			return nil, errors.Wrap(ErrInputValidation, "session expired")
+		}
+
+		return nil, errors.Wrap(ErrInputValidation, "invalid session")
+	}
+
+	otpID, err := strconv.Atoi(claims.Id)
+	if err != nil {
+		return nil, errors.Wrap(ErrInputValidation, "invalid session")
+	}
+
+	otpCode, err := p.repo.UseOTPAttempt(otpID, util.OTPMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	if otpCode == nil {
+		return nil, errors.Wrap(ErrInputValidation, "session expired or too many attempts. try again later")
+	}
+
+	if otpCode.PhoneNumber != claims.Subject || !p.now().Before(otpCode.ExpiredDate) {
+		return nil, errors.Wrap(ErrInputValidation, "session expired")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(fmt.Sprintf("%06d", otpCode.OTP)), []byte(params.Code)) != 1 {
+		if otpCode.Attempts >= util.OTPMaxAttempts {
+			if _, err = p.repo.RedeemOTP(otpID); err != nil {
+				return nil, err
+			}
+
+			return nil, errors.Wrap(ErrInputValidation, "too many attempts. try again later")
+		}
+
+		return nil, errors.Wrap(ErrInputValidation, "invalid OTP code")
+	}
+
+	// the code is only accepted by the request that delete it when it is verified concurrently
+	redeemed, err := p.repo.RedeemOTP(otpID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !redeemed {
+		return nil, errors.Wrap(ErrInputValidation, "session expired")
+	}
+
+	credential, err := p.repo.GetCredentialByPhoneNumber(otpCode.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	isNewUser := credential == nil
+	if isNewUser {
+		credential = &Credential{PhoneNumber: otpCode.PhoneNumber}
+	}
+
+	localID, err := p.localID(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := p.issueTokens(localID, p.now(), signInProviderPhone, credential.PhoneNumber, credential.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &firebaseauth.VerifyOTPResult{
+		IDToken:      accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    strconv.Itoa(util.AccessTokenExpiryMinutes * 60),
+		LocalID:      localID,
+		IsNewUser:    isNewUser,
+		PhoneNumber:  credential.PhoneNumber,
+	}, nil
+}
+
+// SignInWithPassword check the password of business admin with the bcrypt hash on database
+func (p *localProvider) SignInWithPassword(params firebaseauth.SignInWithPasswordParams) (*firebaseauth.SignInWithPasswordResult, error) {
+	credential, err := p.repo.GetCredentialByEmail(params.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if credential == nil || bcrypt.CompareHashAndPassword([]byte(credential.Password), []byte(params.Password)) != nil {
+		return nil, errors.Wrap(ErrInputValidation, "wrong email/password")
+	}
+
+	localID, err := p.localID(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := p.issueTokens(localID, p.now(), signInProviderPassword, credential.PhoneNumber, credential.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &firebaseauth.SignInWithPasswordResult{
+		IDToken:      accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    strconv.Itoa(util.AccessTokenExpiryMinutes * 60),
+		LocalID:      localID,
+		Email:        credential.Email,
+	}, nil
+}
+
+// RefreshToken issue a new access and refresh token for the user of the refresh token, the sign in time is kept
+func (p *localProvider) RefreshToken(refreshToken string) (*firebaseauth.RefreshTokenResult, error) {
+	var claims tokenClaims
+	if err := p.parse(refreshToken, tokenTypeRefresh, &claims); err != nil {
+		return nil, errors.Wrap(ErrInputValidation, "invalid refresh token")
+	}
+
+	authTime := time.Unix(claims.AuthTime, 0)
+	accessToken, newRefreshToken, err := p.issueTokens(claims.Subject, authTime, claims.SignInProvider, claims.PhoneNumber, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &firebaseauth.RefreshTokenResult{
+		IDToken:      accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    strconv.Itoa(util.AccessTokenExpiryMinutes * 60),
+		UserID:       claims.Subject,
+		AuthTime:     authTime,
+	}, nil
+}
+
+// UpdatePassword does nothing, the password of local provider is the bcrypt hash on users table
+// which is updated by the caller
+func (p *localProvider) UpdatePassword(params firebaseauth.UpdatePasswordParams) error {
+	return nil
+}
+
+// GetUserDataFromToken verify the access token and return the user in the same shape as firebase
+func (p *localProvider) GetUserDataFromToken(token string) (*firebaseauth.UserDataFromToken, error) {
+	var claims tokenClaims
+	if err := p.parse(token, tokenTypeAccess, &claims); err != nil {
+		if errors.Cause(err) == errTokenExpired {
+			return nil, errors.Wrap(ErrInputValidation, "token expired")
+		}
+
+		return nil, errors.Wrap(ErrInputValidation, "token invalid")
+	}
+
+	return &firebaseauth.UserDataFromToken{
+		Kind:     tokenIssuer,
+		AuthTime: time.Unix(claims.AuthTime, 0),
+		Users: []firebaseauth.User{
+			{
+				LocalID: claims.Subject,
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID:  claims.SignInProvider,
+						RawID:       claims.Subject,
+						PhoneNumber: claims.PhoneNumber,
+						Email:       claims.Email,
+					},
+				},
+				PhoneNumber: claims.PhoneNumber,
+				Email:       claims.Email,
+			},
+		},
+	}, nil
+}
+
+// localID return the local ID of registered user or a new one for user who is not registered yet
+func (p *localProvider) localID(credential *Credential) (string, error) {
+	if credential.LocalID != "" {
+		return credential.LocalID, nil
+	}
+
+	localID, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	if credential.ID == 0 {
+		return localID, nil
+	}
+
+	return p.repo.EnsureLocalID(credential.ID, localID)
+}
+
+func (p *localProvider) issueTokens(localID string, authTime time.Time, signInProvider, phoneNumber, email string) (string, string, error) {
+	now := p.now()
+	tokens := make([]string, 0, 2)
+
+	for _, token := range []struct {
+		tokenType string
+		expiresAt time.Time
+	}{
+		{tokenTypeAccess, now.Add(util.AccessTokenExpiryMinutes * time.Minute)},
+		{tokenTypeRefresh, now.AddDate(0, 0, util.RefreshTokenExpiryDays)},
+	} {
+		tokenID, err := randomHex(16)
+		if err != nil {
+			return "", "", err
+		}
+
+		signed, err := p.sign(tokenClaims{
+			StandardClaims: jwt.StandardClaims{
+				Id:        tokenID,
+				Subject:   localID,
+				IssuedAt:  now.Unix(),
+				ExpiresAt: token.expiresAt.Unix(),
+			},
+			TokenType:      token.tokenType,
+			AuthTime:       authTime.Unix(),
+			SignInProvider: signInProvider,
+			PhoneNumber:    phoneNumber,
+			Email:          email,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		tokens = append(tokens, signed)
+	}
+
+	return tokens[0], tokens[1], nil
+}
+
+func (p *localProvider) sign(claims tokenClaims) (string, error) {
+	claims.Issuer = tokenIssuer
+	claims.Audience = tokenIssuer
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(p.secret)
+	if err != nil {
+		return "", errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return signed, nil
+}
+
+// parse verify the signature, expiry, issuer and type of the token
+func (p *localProvider) parse(token, tokenType string, claims *tokenClaims) error {
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+
+		return p.secret, nil
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return errTokenExpired
+		}
+
+		return errTokenInvalid
+	}
+
+	if !claims.VerifyIssuer(tokenIssuer, true) || !claims.VerifyAudience(tokenIssuer, true) || claims.TokenType != tokenType || claims.Subject == "" {
+		return errTokenInvalid
+	}
+
+	return nil
+}
+
+func randomHex(length int) (string, error) {
+	buffer := make([]byte, length)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,425 @@
+package authprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testSecret      = "test-secret"
+	testPhoneNumber = "+6281111111111"
+	testEmail       = "test@gmail.com"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateOTP(phoneNumber string, otp int, expiredDate time.Time) (int, error) {
+	args := m.Called(phoneNumber, otp, expiredDate)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) UseOTPAttempt(id int, maxAttempts int) (*OTPCode, error) {
+	args := m.Called(id, maxAttempts)
+	return args.Get(0).(*OTPCode), args.Error(1)
+}
+
+func (m *MockRepository) RedeemOTP(id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetCredentialByPhoneNumber(phoneNumber string) (*Credential, error) {
+	args := m.Called(phoneNumber)
+	return args.Get(0).(*Credential), args.Error(1)
+}
+
+func (m *MockRepository) GetCredentialByEmail(email string) (*Credential, error) {
+	args := m.Called(email)
+	return args.Get(0).(*Credential), args.Error(1)
+}
+
+func (m *MockRepository) EnsureLocalID(userID int, localID string) (string, error) {
+	args := m.Called(userID, localID)
+	return args.String(0), args.Error(1)
+}
+
+type MockChannel struct {
+	mock.Mock
+}
+
+func (m *MockChannel) Name() string {
+	return util.NotificationChannelSMS
+}
+
+func (m *MockChannel) Recipient(recipient notification.Recipient) string {
+	return recipient.PhoneNumber
+}
+
+func (m *MockChannel) Send(message notification.Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func newTestProvider(repo Repo, sms notification.Channel, now time.Time) *localProvider {
+	provider := NewLocalProvider(repo, testSecret, sms).(*localProvider)
+	provider.now = func() time.Time { return now }
+	return provider
+}
+
+func TestLocalProvider_SendOTP(t *testing.T) {
+	now := time.Now()
+	expiredDate := now.Add(util.OTPExpiryMinutes * time.Minute)
+
+	t.Run("success without sms gateway", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("CreateOTP", testPhoneNumber, mock.AnythingOfType("int"), expiredDate).Return(1, nil)
+
+		res, err := provider.SendOTP(firebaseauth.SendOTPParams{PhoneNumber: testPhoneNumber})
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, err)
+
+		var claims tokenClaims
+		assert.Nil(t, provider.parse(res.SessionInfo, tokenTypeOTPSession, &claims))
+		assert.Equal(t, "1", claims.Id)
+		assert.Equal(t, testPhoneNumber, claims.Subject)
+	})
+
+	t.Run("success with sms gateway", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockSMS := new(MockChannel)
+		provider := newTestProvider(mockRepo, mockSMS, now)
+		mockRepo.On("CreateOTP", testPhoneNumber, mock.AnythingOfType("int"), expiredDate).Return(1, nil)
+		mockSMS.On("Send", mock.MatchedBy(func(message notification.Message) bool {
+			return message.Channel == util.NotificationChannelSMS && message.Recipient == testPhoneNumber
+		})).Return(nil)
+
+		res, err := provider.SendOTP(firebaseauth.SendOTPParams{PhoneNumber: testPhoneNumber})
+		mockSMS.AssertExpectations(t)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, res.SessionInfo)
+	})
+
+	t.Run("invalid phone number", func(t *testing.T) {
+		provider := newTestProvider(new(MockRepository), nil, now)
+
+		res, err := provider.SendOTP(firebaseauth.SendOTPParams{PhoneNumber: "abc"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed to save otp", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("CreateOTP", testPhoneNumber, mock.AnythingOfType("int"), expiredDate).Return(0, errors.Wrap(ErrInternalServer, "test error"))
+
+		res, err := provider.SendOTP(firebaseauth.SendOTPParams{PhoneNumber: testPhoneNumber})
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed to send sms", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockSMS := new(MockChannel)
+		provider := newTestProvider(mockRepo, mockSMS, now)
+		mockRepo.On("CreateOTP", testPhoneNumber, mock.AnythingOfType("int"), expiredDate).Return(1, nil)
+		mockSMS.On("Send", mock.Anything).Return(errors.New("test error"))
+
+		res, err := provider.SendOTP(firebaseauth.SendOTPParams{PhoneNumber: testPhoneNumber})
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestLocalProvider_VerifyOTP(t *testing.T) {
+	now := time.Now()
+	otpCode := OTPCode{ID: 1, OTP: 12345, ExpiredDate: now.Add(time.Minute), PhoneNumber: testPhoneNumber}
+
+	newSession := func(provider *localProvider, expiresAt time.Time) string {
+		sessionInfo, _ := provider.sign(tokenClaims{
+			StandardClaims: jwt.StandardClaims{Id: "1", Subject: testPhoneNumber, ExpiresAt: expiresAt.Unix()},
+			TokenType:      tokenTypeOTPSession,
+		})
+		return sessionInfo
+	}
+
+	t.Run("success registered user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return(&otpCode, nil)
+		mockRepo.On("RedeemOTP", 1).Return(true, nil)
+		mockRepo.On("GetCredentialByPhoneNumber", testPhoneNumber).Return(&Credential{ID: 1, LocalID: "local-id", PhoneNumber: testPhoneNumber}, nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "012345"})
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", res.LocalID)
+		assert.False(t, res.IsNewUser)
+		assert.Equal(t, "3600", res.ExpiresIn)
+
+		userData, err := provider.GetUserDataFromToken(res.IDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", userData.Users[0].LocalID)
+		assert.Equal(t, signInProviderPhone, userData.Users[0].ProviderUserInfo[0].ProviderID)
+		assert.Equal(t, testPhoneNumber, userData.Users[0].PhoneNumber)
+	})
+
+	t.Run("success new user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return(&otpCode, nil)
+		mockRepo.On("RedeemOTP", 1).Return(true, nil)
+		mockRepo.On("GetCredentialByPhoneNumber", testPhoneNumber).Return((*Credential)(nil), nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "012345"})
+		mockRepo.AssertNotCalled(t, "EnsureLocalID", mock.Anything, mock.Anything)
+		assert.Nil(t, err)
+		assert.True(t, res.IsNewUser)
+		assert.Len(t, res.LocalID, 32)
+		assert.Equal(t, testPhoneNumber, res.PhoneNumber)
+	})
+
+	t.Run("success registered user without local id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return(&otpCode, nil)
+		mockRepo.On("RedeemOTP", 1).Return(true, nil)
+		mockRepo.On("GetCredentialByPhoneNumber", testPhoneNumber).Return(&Credential{ID: 1, PhoneNumber: testPhoneNumber}, nil)
+		mockRepo.On("EnsureLocalID", 1, mock.AnythingOfType("string")).Return("local-id", nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "012345"})
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", res.LocalID)
+		assert.False(t, res.IsNewUser)
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return(&otpCode, nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "111111"})
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "RedeemOTP", mock.Anything)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "invalid OTP code")
+		assert.Nil(t, res)
+	})
+
+	t.Run("wrong code on last attempt", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		attemptedOTPCode := otpCode
+		attemptedOTPCode.Attempts = util.OTPMaxAttempts
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return(&attemptedOTPCode, nil)
+		mockRepo.On("RedeemOTP", 1).Return(true, nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "111111"})
+		mockRepo.AssertExpectations(t)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "too many attempts")
+		assert.Nil(t, res)
+	})
+
+	t.Run("otp already used or no attempt left", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return((*OTPCode)(nil), nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "012345"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "too many attempts")
+		assert.Nil(t, res)
+	})
+
+	t.Run("otp redeemed by concurrent request", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return(&otpCode, nil)
+		mockRepo.On("RedeemOTP", 1).Return(false, nil)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "012345"})
+		mockRepo.AssertNotCalled(t, "GetCredentialByPhoneNumber", mock.Anything)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("session expired", func(t *testing.T) {
+		provider := newTestProvider(new(MockRepository), nil, now)
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, now.Add(-time.Minute)), Code: "012345"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "session expired")
+		assert.Nil(t, res)
+	})
+
+	t.Run("invalid session", func(t *testing.T) {
+		provider := newTestProvider(new(MockRepository), nil, now)
+		otherProvider := newTestProvider(new(MockRepository), nil, now)
+		otherProvider.secret = []byte("other-secret")
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(otherProvider, otpCode.ExpiredDate), Code: "012345"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "invalid session")
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed to get otp", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("UseOTPAttempt", 1, util.OTPMaxAttempts).Return((*OTPCode)(nil), errors.Wrap(ErrInternalServer, "test error"))
+
+		res, err := provider.VerifyOTP(firebaseauth.VerifyOTPParams{SessionInfo: newSession(provider, otpCode.ExpiredDate), Code: "012345"})
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestLocalProvider_SignInWithPassword(t *testing.T) {
+	now := time.Now()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	credential := Credential{ID: 1, LocalID: "local-id", Email: testEmail, Password: string(hash)}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("GetCredentialByEmail", testEmail).Return(&credential, nil)
+
+		res, err := provider.SignInWithPassword(firebaseauth.SignInWithPasswordParams{Email: testEmail, Password: "password"})
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", res.LocalID)
+		assert.Equal(t, testEmail, res.Email)
+
+		userData, err := provider.GetUserDataFromToken(res.IDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, signInProviderPassword, userData.Users[0].ProviderUserInfo[0].ProviderID)
+		assert.Equal(t, testEmail, userData.Users[0].Email)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("GetCredentialByEmail", testEmail).Return(&credential, nil)
+
+		res, err := provider.SignInWithPassword(firebaseauth.SignInWithPasswordParams{Email: testEmail, Password: "wrong"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("email not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("GetCredentialByEmail", testEmail).Return((*Credential)(nil), nil)
+
+		res, err := provider.SignInWithPassword(firebaseauth.SignInWithPasswordParams{Email: testEmail, Password: "password"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed to get credential", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		provider := newTestProvider(mockRepo, nil, now)
+		mockRepo.On("GetCredentialByEmail", testEmail).Return((*Credential)(nil), errors.Wrap(ErrInternalServer, "test error"))
+
+		res, err := provider.SignInWithPassword(firebaseauth.SignInWithPasswordParams{Email: testEmail, Password: "password"})
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestLocalProvider_RefreshToken(t *testing.T) {
+	now := time.Now()
+	provider := newTestProvider(new(MockRepository), nil, now)
+	authTime := now.Add(-time.Hour)
+	accessToken, refreshToken, err := provider.issueTokens("local-id", authTime, signInProviderPassword, "", testEmail)
+	assert.Nil(t, err)
+
+	t.Run("success and sign in time is kept", func(t *testing.T) {
+		res, err := provider.RefreshToken(refreshToken)
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", res.UserID)
+		assert.NotEqual(t, refreshToken, res.RefreshToken)
+		assert.Equal(t, authTime.Unix(), res.AuthTime.Unix())
+
+		userData, err := provider.GetUserDataFromToken(res.IDToken)
+		assert.Nil(t, err)
+		assert.Equal(t, authTime.Unix(), userData.AuthTime.Unix())
+		assert.Equal(t, signInProviderPassword, userData.Users[0].ProviderUserInfo[0].ProviderID)
+		assert.Equal(t, testEmail, userData.Users[0].Email)
+	})
+
+	t.Run("access token is not accepted", func(t *testing.T) {
+		res, err := provider.RefreshToken(accessToken)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiredProvider := newTestProvider(new(MockRepository), nil, now.AddDate(0, 0, -2*util.RefreshTokenExpiryDays))
+		_, expiredToken, _ := expiredProvider.issueTokens("local-id", now, signInProviderPassword, "", testEmail)
+
+		res, err := provider.RefreshToken(expiredToken)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestLocalProvider_GetUserDataFromToken(t *testing.T) {
+	now := time.Now()
+	provider := newTestProvider(new(MockRepository), nil, now)
+	accessToken, refreshToken, err := provider.issueTokens("local-id", now, signInProviderPhone, testPhoneNumber, "")
+	assert.Nil(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		res, err := provider.GetUserDataFromToken(accessToken)
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", res.Users[0].LocalID)
+	})
+
+	t.Run("refresh token is not accepted", func(t *testing.T) {
+		res, err := provider.GetUserDataFromToken(refreshToken)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("signed by other secret", func(t *testing.T) {
+		otherProvider := newTestProvider(new(MockRepository), nil, now)
+		otherProvider.secret = []byte("other-secret")
+
+		res, err := otherProvider.GetUserDataFromToken(accessToken)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiredProvider := newTestProvider(new(MockRepository), nil, now.Add(-2*util.AccessTokenExpiryMinutes*time.Minute))
+		expiredToken, _, _ := expiredProvider.issueTokens("local-id", now, signInProviderPhone, testPhoneNumber, "")
+
+		res, err := provider.GetUserDataFromToken(expiredToken)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "token expired")
+		assert.Nil(t, res)
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		res, err := provider.GetUserDataFromToken("test token")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestLocalProvider_UpdatePassword(t *testing.T) {
+	provider := newTestProvider(new(MockRepository), nil, time.Now())
+	assert.Nil(t, provider.UpdatePassword(firebaseauth.UpdatePasswordParams{LocalID: "local-id", Password: "password"}))
+}
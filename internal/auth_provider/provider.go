@@ -0,0 +1,16 @@
+package authprovider
+
+import firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+
+// Provider is the identity provider used for OTP, password sign in, password update, token refresh and token verification,
+// it is implemented by firebase and the self-hosted local provider
+type Provider interface {
+	SendOTP(params firebaseauth.SendOTPParams) (*firebaseauth.SendOTPResult, error)
+	VerifyOTP(params firebaseauth.VerifyOTPParams) (*firebaseauth.VerifyOTPResult, error)
+	SignInWithPassword(params firebaseauth.SignInWithPasswordParams) (*firebaseauth.SignInWithPasswordResult, error)
+	RefreshToken(refreshToken string) (*firebaseauth.RefreshTokenResult, error)
+	UpdatePassword(params firebaseauth.UpdatePasswordParams) error
+	GetUserDataFromToken(token string) (*firebaseauth.UserDataFromToken, error)
+}
+
+var _ Provider = firebaseauth.NewRepo("", "", "")
@@ -0,0 +1,129 @@
+package authprovider
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	CreateOTP(phoneNumber string, otp int, expiredDate time.Time) (int, error)
+	UseOTPAttempt(id int, maxAttempts int) (*OTPCode, error)
+	RedeemOTP(id int) (bool, error)
+	GetCredentialByPhoneNumber(phoneNumber string) (*Credential, error)
+	GetCredentialByEmail(email string) (*Credential, error)
+	EnsureLocalID(userID int, localID string) (string, error)
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// NewRepo for initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+const credentialQuery = `
+	SELECT id, COALESCE(firebase_local_id, '') AS local_id, phone_number, COALESCE(email, '') AS email, COALESCE(password, '') AS password
+	FROM users`
+
+func (r repo) CreateOTP(phoneNumber string, otp int, expiredDate time.Time) (int, error) {
+	var id int
+
+	query := "INSERT INTO otp_codes (otp, expired_date, phone_number) VALUES ($1, $2, $3) RETURNING id"
+	err := r.db.Get(&id, query, otp, expiredDate, phoneNumber)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return id, nil
+}
+
+// UseOTPAttempt count an attempt before the code is compared so concurrent guesses can not exceed the maximum attempts,
+// nil is returned when the code is already used, never exist or has no attempt left
+func (r repo) UseOTPAttempt(id int, maxAttempts int) (*OTPCode, error) {
+	var otpCode OTPCode
+
+	query := `UPDATE otp_codes SET attempts = attempts + 1, updated_at = now() WHERE id = $1 AND attempts < $2
+			RETURNING id, otp, expired_date, phone_number, attempts`
+	err := r.db.Get(&otpCode, query, id, maxAttempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &otpCode, nil
+}
+
+// RedeemOTP delete the otp code, false is returned when it is already deleted so a code can only be redeemed once
+func (r repo) RedeemOTP(id int) (bool, error) {
+	var deletedID int
+
+	query := "DELETE FROM otp_codes WHERE id = $1 RETURNING id"
+	err := r.db.Get(&deletedID, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return true, nil
+}
+
+// GetCredentialByPhoneNumber return the customer with the phone number, nil when it is not registered yet
+func (r repo) GetCredentialByPhoneNumber(phoneNumber string) (*Credential, error) {
+	return r.getCredential(credentialQuery+" WHERE phone_number = $1 AND status = $2", phoneNumber, util.StatusCustomer)
+}
+
+// GetCredentialByEmail return the business admin with the email, nil when it is not found
+func (r repo) GetCredentialByEmail(email string) (*Credential, error) {
+	return r.getCredential(credentialQuery+" WHERE email = $1 AND status = $2", email, util.StatusBusinessAdmin)
+}
+
+func (r repo) getCredential(query string, args ...interface{}) (*Credential, error) {
+	var credential Credential
+
+	err := r.db.Get(&credential, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &credential, nil
+}
+
+// EnsureLocalID set the local ID of the user when it is not set yet and return the local ID used by the user
+func (r repo) EnsureLocalID(userID int, localID string) (string, error) {
+	var usedLocalID string
+
+	query := `
+		UPDATE users SET firebase_local_id = COALESCE(firebase_local_id, $2), updated_at = now()
+		WHERE id = $1
+		RETURNING firebase_local_id`
+	err := r.db.Get(&usedLocalID, query, userID, localID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.Wrap(ErrInternalServer, fmt.Sprintf("user with id %d is not found", userID))
+		}
+
+		return "", errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return usedLocalID, nil
+}
@@ -0,0 +1,221 @@
+package authprovider
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+func TestRepo_CreateOTP(t *testing.T) {
+	query := "INSERT INTO otp_codes (otp, expired_date, phone_number) VALUES ($1, $2, $3) RETURNING id"
+	expiredDate := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(123456, expiredDate, "+6281111111111").
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+
+		id, err := repoMock.CreateOTP("+6281111111111", 123456, expiredDate)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, id)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(123456, expiredDate, "+6281111111111").
+			WillReturnError(sql.ErrConnDone)
+
+		id, err := repoMock.CreateOTP("+6281111111111", 123456, expiredDate)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Equal(t, 0, id)
+	})
+}
+
+func TestRepo_UseOTPAttempt(t *testing.T) {
+	query := `UPDATE otp_codes SET attempts = attempts + 1, updated_at = now() WHERE id = $1 AND attempts < $2
+			RETURNING id, otp, expired_date, phone_number, attempts`
+	columns := []string{"id", "otp", "expired_date", "phone_number", "attempts"}
+	expiredDate := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.OTPMaxAttempts).
+			WillReturnRows(mock.NewRows(columns).AddRow(1, 123456, expiredDate, "+6281111111111", 2))
+
+		result, err := repoMock.UseOTPAttempt(1, util.OTPMaxAttempts)
+		assert.Nil(t, err)
+		assert.Equal(t, &OTPCode{ID: 1, OTP: 123456, ExpiredDate: expiredDate, PhoneNumber: "+6281111111111", Attempts: 2}, result)
+	})
+
+	t.Run("not found or no attempt left", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.OTPMaxAttempts).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.UseOTPAttempt(1, util.OTPMaxAttempts)
+		assert.Nil(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.OTPMaxAttempts).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.UseOTPAttempt(1, util.OTPMaxAttempts)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+}
+
+func TestRepo_RedeemOTP(t *testing.T) {
+	query := "DELETE FROM otp_codes WHERE id = $1 RETURNING id"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+
+		redeemed, err := repoMock.RedeemOTP(1)
+		assert.Nil(t, err)
+		assert.True(t, redeemed)
+	})
+
+	t.Run("already redeemed", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		redeemed, err := repoMock.RedeemOTP(1)
+		assert.Nil(t, err)
+		assert.False(t, redeemed)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		_, err := repoMock.RedeemOTP(1)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetCredential(t *testing.T) {
+	columns := []string{"id", "local_id", "phone_number", "email", "password"}
+	credential := Credential{ID: 1, LocalID: "local-id", PhoneNumber: "+6281111111111", Email: "test@gmail.com", Password: "hash"}
+
+	t.Run("by phone number success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(credentialQuery+" WHERE phone_number = $1 AND status = $2")).
+			WithArgs("+6281111111111", util.StatusCustomer).
+			WillReturnRows(mock.NewRows(columns).AddRow(1, "local-id", "+6281111111111", "test@gmail.com", "hash"))
+
+		result, err := repoMock.GetCredentialByPhoneNumber("+6281111111111")
+		assert.Nil(t, err)
+		assert.Equal(t, &credential, result)
+	})
+
+	t.Run("by email success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(credentialQuery+" WHERE email = $1 AND status = $2")).
+			WithArgs("test@gmail.com", util.StatusBusinessAdmin).
+			WillReturnRows(mock.NewRows(columns).AddRow(1, "local-id", "+6281111111111", "test@gmail.com", "hash"))
+
+		result, err := repoMock.GetCredentialByEmail("test@gmail.com")
+		assert.Nil(t, err)
+		assert.Equal(t, &credential, result)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(credentialQuery)).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetCredentialByEmail("test@gmail.com")
+		assert.Nil(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(credentialQuery)).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetCredentialByPhoneNumber("+6281111111111")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+}
+
+func TestRepo_EnsureLocalID(t *testing.T) {
+	query := "UPDATE users SET firebase_local_id = COALESCE(firebase_local_id, $2), updated_at = now()"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "new-local-id").
+			WillReturnRows(mock.NewRows([]string{"firebase_local_id"}).AddRow("existing-local-id"))
+
+		localID, err := repoMock.EnsureLocalID(1, "new-local-id")
+		assert.Nil(t, err)
+		assert.Equal(t, "existing-local-id", localID)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "new-local-id").WillReturnError(sql.ErrNoRows)
+
+		localID, err := repoMock.EnsureLocalID(1, "new-local-id")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Equal(t, "", localID)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "new-local-id").WillReturnError(sql.ErrConnDone)
+
+		localID, err := repoMock.EnsureLocalID(1, "new-local-id")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Equal(t, "", localID)
+	})
+}
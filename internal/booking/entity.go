@@ -63,8 +63,9 @@ type TimeSlotAPIResponse struct {
 
 // AvailableTimeResponse for response after check available time
 type AvailableTimeResponse struct {
-	Time  string `json:"time"`
-	Total int    `json:"total"`
+	Time  string  `json:"time"`
+	Total int     `json:"total"`
+	Price float64 `json:"price"`
 }
 
 // AvailableDateResponse for response after check available date
@@ -123,14 +124,17 @@ type CreateBookingItemsResponse struct {
 
 // CreateBookingParams for inserting to booking table
 type CreateBookingParams struct {
-	UserID     int       `json:"user_id" db:"user_id"`
-	PlaceID    int       `json:"place_id" db:"place_id"`
-	Date       time.Time `json:"date"`
-	StartTime  time.Time `json:"start_time" db:"start_time"`
-	EndTime    time.Time `json:"end_time" db:"end_time"`
-	Capacity   int       `json:"capacity"`
-	Status     int       `json:"status"`
-	TotalPrice float64   `json:"total_price" db:"total_price"`
+	UserID       int       `json:"user_id" db:"user_id"`
+	PlaceID      int       `json:"place_id" db:"place_id"`
+	Date         time.Time `json:"date"`
+	StartTime    time.Time `json:"start_time" db:"start_time"`
+	EndTime      time.Time `json:"end_time" db:"end_time"`
+	Capacity     int       `json:"capacity"`
+	Status       int       `json:"status"`
+	TotalPrice   float64   `json:"total_price" db:"total_price"`
+	BookingPrice float64   `json:"booking_price" db:"booking_price"`
+	PaymentType  int       `json:"payment_type" db:"payment_type"`
+	SplitCount   int       `json:"split_count" db:"split_count"`
 }
 
 // CreateBookingResponse struct for the response after inserting booking data to db
@@ -155,6 +159,8 @@ type CreateBookingServiceRequest struct {
 	UserID              int       `json:"user_id"`
 	CustomerName        string    `json:"customer_name"`
 	CustomerPhoneNumber string    `json:"customer_phone_number"`
+	PaymentType         int       `json:"payment_type"`
+	SplitCount          int       `json:"split_count"`
 }
 
 // Item object on create booking request
@@ -173,11 +179,13 @@ type CreateBookingServiceResponse struct {
 
 // CreateBookingRequestBody for API request body
 type CreateBookingRequestBody struct {
-	Items     []Item `json:"items"`
-	Date      string `json:"date"`
-	StartTime string `json:"start_time"`
-	EndTime   string `json:"end_time"`
-	Count     int    `json:"count"`
+	Items       []Item `json:"items"`
+	Date        string `json:"date"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	Count       int    `json:"count"`
+	PaymentType int    `json:"payment_type"`
+	SplitCount  int    `json:"split_count"`
 }
 
 // Booking contains customer booking information
@@ -220,16 +228,20 @@ type Detail struct {
 	EndTime             time.Time    `json:"end_time" db:"end_time"`
 	Capacity            int          `json:"capacity"`
 	Status              int          `json:"status"`
+	PaymentType         int          `json:"payment_type" db:"payment_type"`
+	SplitCount          int          `json:"split_count" db:"split_count"`
 	CreatedAt           string       `json:"created_at" db:"created_at"`
 	TotalPrice          float64      `json:"total_price"`
 	TotalPriceTicket    float64      `json:"total_price_ticket"`
 	TotalPriceItem      float64      `json:"total_price_item" db:"total_price"`
+	NoShowCount         int          `json:"customer_no_show_count" db:"no_show_count"`
 	Items               []ItemDetail `json:"items"`
 }
 
 // TicketPriceWrapper will consist ticket price related to place
 type TicketPriceWrapper struct {
 	Price float64 `db:"booking_price"`
+	Fixed bool    `db:"fixed"`
 }
 
 // ItemsWrapper will wrap information related about item
@@ -278,4 +290,83 @@ type DetailBookingSaya struct {
 	Image       string    `json:"image"`
 	Items       []Item    `json:"items"`
 	ExpiredAt   time.Time `json:"expired_at" db:"payment_expired_at"`
+	Invoices    []Invoice `json:"invoices"`
+}
+
+// Invoice is a xendit invoice that pay a part or the whole booking
+type Invoice struct {
+	ID          int       `json:"id"`
+	BookingID   int       `json:"booking_id" db:"booking_id"`
+	XenditID    string    `json:"-" db:"xendit_id"`
+	InvoiceURL  string    `json:"invoice_url" db:"invoice_url"`
+	Type        int       `json:"type"`
+	Amount      float64   `json:"amount"`
+	PlatformFee float64   `json:"-" db:"platform_fee"`
+	Status      int       `json:"status"`
+	ExpiredAt   time.Time `json:"expired_at" db:"expired_at"`
+}
+
+// Ticket is a signed e-ticket shown by customer as qr code at check-in
+type Ticket struct {
+	BookingID int    `json:"booking_id"`
+	Token     string `json:"token"`
+	QRCode    string `json:"qr_code"`
+}
+
+// TicketFile is a printable e-ticket and receipt of a booking
+type TicketFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// CheckInRequest represent request body for booking check-in
+type CheckInRequest struct {
+	Token string `json:"token"`
+}
+
+// Ownership is the customer who made the booking and the business admin who own the place of the booking
+type Ownership struct {
+	BookingID  int        `db:"id"`
+	CustomerID int        `db:"customer_id"`
+	OwnerID    int        `db:"owner_id"`
+	Status     int        `db:"status"`
+	ArrivedAt  *time.Time `db:"arrived_at"`
+}
+
+// CheckInInformation is booking information needed to verify a check-in
+type CheckInInformation struct {
+	ID           int        `db:"id"`
+	OwnerID      int        `db:"user_id"`
+	CustomerName string     `db:"name"`
+	Date         time.Time  `db:"date"`
+	StartTime    string     `db:"start_time"`
+	EndTime      string     `db:"end_time"`
+	Capacity     int        `db:"capacity"`
+	Status       int        `db:"status"`
+	ArrivedAt    *time.Time `db:"arrived_at"`
+}
+
+// CheckInResponse is the checked in booking
+type CheckInResponse struct {
+	BookingID    int       `json:"booking_id"`
+	CustomerName string    `json:"customer_name"`
+	Date         string    `json:"date"`
+	StartTime    string    `json:"start_time"`
+	EndTime      string    `json:"end_time"`
+	Capacity     int       `json:"capacity"`
+	ArrivedAt    time.Time `json:"arrived_at"`
+}
+
+// NoShowPolicy is how a place treat customer with too many no-shows
+type NoShowPolicy struct {
+	Policy    int `json:"policy" db:"no_show_policy"`
+	Threshold int `json:"threshold" db:"no_show_threshold"`
+}
+
+// CustomerNoShowPolicy is the no-show policy of a place with no-show count of the customer
+type CustomerNoShowPolicy struct {
+	Policy      int `db:"no_show_policy"`
+	Threshold   int `db:"no_show_threshold"`
+	NoShowCount int `db:"no_show_count"`
 }
@@ -11,4 +11,7 @@ var (
 
 	// ErrNotFound is used if resource not found
 	ErrNotFound = errors.New("not found")
+
+	// ErrForbidden is used if the booking is not belong to the user
+	ErrForbidden = errors.New("forbidden")
 )
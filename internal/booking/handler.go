@@ -8,6 +8,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
@@ -254,12 +255,19 @@ func (h Handler) CreateBooking(c echo.Context) error {
 		UserID:              userFromDatabase.ID,
 		CustomerName:        userFromDatabase.Name,
 		CustomerPhoneNumber: userFromDatabase.PhoneNumber,
+		PaymentType:         req.PaymentType,
+		SplitCount:          req.SplitCount,
 	}
 
 	resp, err := h.service.CreateBooking(serviceRequest)
 	if err != nil {
-		if errors.Cause(err) == ErrInputValidationError {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
 			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
 		}
 
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
@@ -327,6 +335,11 @@ func (h Handler) GetTimeSlots(c echo.Context) error {
 func (h *Handler) GetDetail(c echo.Context) error {
 	errorList := []string{}
 
+	userModel, err := parseUser(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
 	bookingIDString := c.Param("bookingID")
 	bookingID, err := strconv.Atoi(bookingIDString)
 	if err != nil {
@@ -337,10 +350,15 @@ func (h *Handler) GetDetail(c echo.Context) error {
 		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
 	}
 
-	bookingDetail, err := h.service.GetDetail(bookingID)
+	bookingDetail, err := h.service.GetDetail(userModel.ID, bookingID)
 	if err != nil {
-		if errors.Cause(err) == ErrInputValidationError {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
 			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
 		}
 
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
@@ -357,6 +375,11 @@ func (h *Handler) GetDetail(c echo.Context) error {
 func (h *Handler) UpdateBookingStatus(c echo.Context) error {
 	errorList := []string{}
 
+	userModel, err := parseUser(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
 	bookingIDString := c.Param("bookingID")
 	bookingID, err := strconv.Atoi(bookingIDString)
 	if err != nil {
@@ -372,11 +395,16 @@ func (h *Handler) UpdateBookingStatus(c echo.Context) error {
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, ErrInternalServerError, err.Error())
 	}
 
-	err = h.service.UpdateBookingStatus(bookingID, req.Status)
+	err = h.service.UpdateBookingStatus(userModel.ID, bookingID, req.Status)
 
 	if err != nil {
-		if errors.Cause(err) == ErrInputValidationError {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
 			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
 		}
 
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
@@ -480,17 +508,26 @@ func (h Handler) XenditInvoicesCallback(c echo.Context) error {
 
 // GetDetailBookingSaya used for handling request to get detail booking saya
 func (h *Handler) GetDetailBookingSaya(c echo.Context) error {
+	userModel, err := parseUser(c, util.StatusCustomer)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
 	bookingIDString := c.Param("bookingID")
-	fmt.Println("bookingID:", bookingIDString)
 	bookingID, err := strconv.Atoi(bookingIDString)
 	if err != nil {
 		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "bookingID must be number")
 	}
 
-	detailBookingSaya, err := h.service.GetDetailBookingSaya(bookingID)
+	detailBookingSaya, err := h.service.GetDetailBookingSaya(userModel.ID, bookingID)
 	if err != nil {
-		if errors.Cause(err) == ErrInputValidationError {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
 			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
 		}
 
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
@@ -502,3 +539,275 @@ func (h *Handler) GetDetailBookingSaya(c echo.Context) error {
 		Data:    detailBookingSaya,
 	})
 }
+
+// CreateRemainingInvoice used for handling request to pay the rest of deposit booking
+func (h *Handler) CreateRemainingInvoice(c echo.Context) error {
+	userModel, err := parseUser(c, util.StatusCustomer)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("bookingID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "bookingID must be number")
+	}
+
+	invoice, err := h.service.CreateRemainingInvoice(userModel.ID, bookingID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    invoice,
+	})
+}
+
+// GetTicket used for handling request to get signed e-ticket qr code of a booking
+func (h *Handler) GetTicket(c echo.Context) error {
+	userModel, err := parseUser(c, util.StatusCustomer)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("bookingID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "bookingID must be number")
+	}
+
+	ticket, err := h.service.GetTicket(userModel.ID, bookingID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    ticket,
+	})
+}
+
+// GetTicketPDF used for handling request to download e-ticket and receipt of a booking
+func (h *Handler) GetTicketPDF(c echo.Context) error {
+	userModel, err := parseUser(c, util.StatusCustomer)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("bookingID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "bookingID must be number")
+	}
+
+	file, err := h.service.GetTicketPDF(userModel.ID, bookingID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	return c.Blob(http.StatusOK, file.ContentType, file.Content)
+}
+
+// CheckIn used for handling business admin request to verify e-ticket and mark the booking as arrived
+func (h *Handler) CheckIn(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	var req CheckInRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	checkIn, err := h.service.CheckIn(user.ID, req.Token)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    checkIn,
+	})
+}
+
+// CheckInByBookingID used for handling business admin request to mark the booking as arrived without e-ticket
+func (h *Handler) CheckInByBookingID(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("bookingID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "bookingID must be number")
+	}
+
+	checkIn, err := h.service.CheckInByBookingID(user.ID, bookingID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    checkIn,
+	})
+}
+
+// MarkNoShow used for handling business admin request to mark the booking as no-show
+func (h *Handler) MarkNoShow(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("bookingID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "bookingID must be number")
+	}
+
+	err = h.service.MarkNoShow(user.ID, bookingID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// GetNoShowPolicy used for handling request to get no-show policy of business admin place
+func (h *Handler) GetNoShowPolicy(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	policy, err := h.service.GetNoShowPolicy(user.ID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    policy,
+	})
+}
+
+// UpdateNoShowPolicy used for handling request to update no-show policy of business admin place
+func (h *Handler) UpdateNoShowPolicy(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	var req NoShowPolicy
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	err = h.service.UpdateNoShowPolicy(user.ID, req)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    req,
+	})
+}
+
+// parseUser return the registered user of the given status who make the request
+func parseUser(c echo.Context, status int) (*user.Model, error) {
+	_, userModel, err := middleware.ParseUserData(c, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if userModel == nil {
+		return nil, errors.Wrap(middleware.ErrForbidden, "user is not registered")
+	}
+
+	return userModel, nil
+}
@@ -52,14 +52,14 @@ func (m *MockService) GetTimeSlots(placeID int, selectedDate time.Time) (*[]Time
 	return args.Get(0).(*[]TimeSlot), args.Error(1)
 }
 
-func (m *MockService) GetDetail(bookingID int) (*Detail, error) {
-	args := m.Called(bookingID)
+func (m *MockService) GetDetail(userID int, bookingID int) (*Detail, error) {
+	args := m.Called(userID, bookingID)
 	bookingDetail := args.Get(0).(*Detail)
 	return bookingDetail, args.Error(1)
 }
 
-func (m *MockService) UpdateBookingStatus(bookingID int, newStatus int) error {
-	args := m.Called(bookingID, newStatus)
+func (m *MockService) UpdateBookingStatus(userID int, bookingID int, newStatus int) error {
+	args := m.Called(userID, bookingID, newStatus)
 	return args.Error(0)
 }
 func (m *MockService) GetMyBookingsOngoing(localID string) (*[]Booking, error) {
@@ -80,12 +80,59 @@ func (m *MockService) XenditInvoicesCallback(callback XenditInvoicesCallback) er
 	return args.Error(0)
 }
 
-func (m *MockService) GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error) {
-	args := m.Called(bookingID)
+func (m *MockService) GetDetailBookingSaya(userID int, bookingID int) (*DetailBookingSaya, error) {
+	args := m.Called(userID, bookingID)
 	detailBookingSaya := args.Get(0).(*DetailBookingSaya)
 	return detailBookingSaya, args.Error(1)
 }
 
+func (m *MockService) CreateRemainingInvoice(userID int, bookingID int) (*Invoice, error) {
+	args := m.Called(userID, bookingID)
+	invoice := args.Get(0).(*Invoice)
+	return invoice, args.Error(1)
+}
+
+func (m *MockService) GetPendingInvoices() (*[]Invoice, error) {
+	args := m.Called()
+	invoices := args.Get(0).(*[]Invoice)
+	return invoices, args.Error(1)
+}
+
+func (m *MockService) GetTicket(userID int, bookingID int) (*Ticket, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*Ticket), args.Error(1)
+}
+
+func (m *MockService) GetTicketPDF(userID int, bookingID int) (*TicketFile, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*TicketFile), args.Error(1)
+}
+
+func (m *MockService) CheckIn(userID int, token string) (*CheckInResponse, error) {
+	args := m.Called(userID, token)
+	return args.Get(0).(*CheckInResponse), args.Error(1)
+}
+
+func (m *MockService) CheckInByBookingID(userID int, bookingID int) (*CheckInResponse, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*CheckInResponse), args.Error(1)
+}
+
+func (m *MockService) MarkNoShow(userID int, bookingID int) error {
+	args := m.Called(userID, bookingID)
+	return args.Error(0)
+}
+
+func (m *MockService) GetNoShowPolicy(userID int) (*NoShowPolicy, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*NoShowPolicy), args.Error(1)
+}
+
+func (m *MockService) UpdateNoShowPolicy(userID int, policy NoShowPolicy) error {
+	args := m.Called(userID, policy)
+	return args.Error(0)
+}
+
 func TestHandler_GetListCustomerBookingWithPaginationSuccess(t *testing.T) {
 	// Setup echo
 	e := echo.New()
@@ -2325,6 +2372,16 @@ func TestHandler_GetTimeSlot(t *testing.T) {
 	})
 }
 
+// setUser set the registered user with id 1 who make the request
+func setUser(c echo.Context, providerID string, status int) {
+	c.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: providerID}}},
+		},
+	})
+	c.Set("userFromDatabase", &user.Model{ID: 1, Status: status})
+}
+
 func TestHandler_GetDetailSuccess(t *testing.T) {
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -2333,6 +2390,7 @@ func TestHandler_GetDetailSuccess(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("1")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	mockService := new(MockService)
 	h := NewHandler(mockService)
@@ -2376,7 +2434,7 @@ func TestHandler_GetDetailSuccess(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("GetDetail", bookingID).Return(&bookingDetail, nil)
+	mockService.On("GetDetail", 1, bookingID).Return(&bookingDetail, nil)
 
 	// Test Fields
 	if assert.NoError(t, h.GetDetail(c)) {
@@ -2393,6 +2451,7 @@ func TestHandler_GetDetailWithInternalServerError(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("10")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2411,7 +2470,7 @@ func TestHandler_GetDetailWithInternalServerError(t *testing.T) {
 
 	// Excpectation
 	var bookingDetail Detail
-	mockService.On("GetDetail", bookingID).Return(&bookingDetail, errorFromService)
+	mockService.On("GetDetail", 1, bookingID).Return(&bookingDetail, errorFromService)
 
 	// Tes
 	util.ErrorHandler(h.GetDetail(c), c)
@@ -2427,6 +2486,7 @@ func TestBooking_GetBookingDetailWithBookingIDBelowOne(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("0")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2446,7 +2506,7 @@ func TestBooking_GetBookingDetailWithBookingIDBelowOne(t *testing.T) {
 
 	// Excpectation
 	var bookingDetail Detail
-	mockService.On("GetDetail", bookingID).Return(&bookingDetail, errorFromService)
+	mockService.On("GetDetail", 1, bookingID).Return(&bookingDetail, errorFromService)
 
 	// Test
 	util.ErrorHandler(h.GetDetail(c), c)
@@ -2463,6 +2523,7 @@ func TestHandler_GetDetailWithBookingIDString(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("satu")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2500,6 +2561,7 @@ func TestHandler_UpdateBookingStatusSuccess(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID/confirmation")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("1")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2509,7 +2571,7 @@ func TestHandler_UpdateBookingStatusSuccess(t *testing.T) {
 	newStatus := 2
 
 	// Expectation
-	mockService.On("UpdateBookingStatus", bookingID, newStatus).Return(nil)
+	mockService.On("UpdateBookingStatus", 1, bookingID, newStatus).Return(nil)
 
 	expectedResponse := util.APIResponse{
 		Status:  http.StatusOK,
@@ -2540,6 +2602,7 @@ func TestHandler_UpdateBookingStatusWithBookingIDString(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID/confirmation")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("satu")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2577,6 +2640,7 @@ func TestHandler_UpdateBookingStatusBindingError(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID/confirmation")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("1")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2611,6 +2675,7 @@ func TestHandler_UpdateBookingStatusWithBookingIDBelowOne(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID/confirmation")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("0")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2628,7 +2693,7 @@ func TestHandler_UpdateBookingStatusWithBookingIDBelowOne(t *testing.T) {
 		Errors:  errList,
 	}
 
-	mockService.On("UpdateBookingStatus", bookingID, newStatus).Return(errorFromService)
+	mockService.On("UpdateBookingStatus", 1, bookingID, newStatus).Return(errorFromService)
 
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
@@ -2655,6 +2720,7 @@ func TestHandler_UpdateBookingStatusWithInternalServerError(t *testing.T) {
 	c.SetPath("/api/v1/business-admin/booking/:bookingID/confirmation")
 	c.SetParamNames("bookingID")
 	c.SetParamValues("10")
+	setUser(c, "password", util.StatusBusinessAdmin)
 
 	// Setup service
 	mockService := new(MockService)
@@ -2672,7 +2738,7 @@ func TestHandler_UpdateBookingStatusWithInternalServerError(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("UpdateBookingStatus", bookingID, newStatus).Return(errorFromService)
+	mockService.On("UpdateBookingStatus", 1, bookingID, newStatus).Return(errorFromService)
 
 	// Tes
 	util.ErrorHandler(h.UpdateBookingStatus(c), c)
@@ -3264,6 +3330,7 @@ func TestHandler_GetDetailBookingSayaSuccess(t *testing.T) {
 	ctx.SetPath("/booking/detail/:bookingID")
 	ctx.SetParamNames("bookingID")
 	ctx.SetParamValues("1")
+	setUser(ctx, "phone", util.StatusCustomer)
 
 	mockService := new(MockService)
 	h := NewHandler(mockService)
@@ -3325,7 +3392,7 @@ func TestHandler_GetDetailBookingSayaSuccess(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("GetDetailBookingSaya", bookingID).Return(&detailBookingSaya, nil)
+	mockService.On("GetDetailBookingSaya", 1, bookingID).Return(&detailBookingSaya, nil)
 
 	// Tes
 	if assert.NoError(t, h.GetDetailBookingSaya(ctx)) {
@@ -3345,6 +3412,7 @@ func TestHandler_GetDetailBookingSayaBookingIDError(t *testing.T) {
 	ctx.SetPath("/booking/detail/:bookingID")
 	ctx.SetParamNames("bookingID")
 	ctx.SetParamValues("test")
+	setUser(ctx, "phone", util.StatusCustomer)
 
 	mockService := new(MockService)
 	h := NewHandler(mockService)
@@ -3379,6 +3447,7 @@ func TestHandler_GetDetailBookingSayaValidationError(t *testing.T) {
 	ctx.SetPath("/booking/detail/:bookingID")
 	ctx.SetParamNames("bookingID")
 	ctx.SetParamValues("0")
+	setUser(ctx, "phone", util.StatusCustomer)
 	bookingID := 0
 
 	mockService := new(MockService)
@@ -3399,7 +3468,7 @@ func TestHandler_GetDetailBookingSayaValidationError(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	var detailBookingSaya DetailBookingSaya
-	mockService.On("GetDetailBookingSaya", bookingID).Return(&detailBookingSaya, errorFromService)
+	mockService.On("GetDetailBookingSaya", 1, bookingID).Return(&detailBookingSaya, errorFromService)
 	util.ErrorHandler(h.GetDetailBookingSaya(ctx), ctx)
 
 	// Tes
@@ -3418,6 +3487,7 @@ func TestHandler_GetDetailBookingSayaValidationInternalSeverError(t *testing.T)
 	ctx.SetPath("/booking/detail/:bookingID")
 	ctx.SetParamNames("bookingID")
 	ctx.SetParamValues("1")
+	setUser(ctx, "phone", util.StatusCustomer)
 	bookingID := 1
 
 	mockService := new(MockService)
@@ -3436,10 +3506,465 @@ func TestHandler_GetDetailBookingSayaValidationInternalSeverError(t *testing.T)
 
 	// Excpectation
 	var detailBookingSaya DetailBookingSaya
-	mockService.On("GetDetailBookingSaya", bookingID).Return(&detailBookingSaya, internalServerError)
+	mockService.On("GetDetailBookingSaya", 1, bookingID).Return(&detailBookingSaya, internalServerError)
 	util.ErrorHandler(h.GetDetailBookingSaya(ctx), ctx)
 
 	// Tes
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
 }
+
+func TestHandler_CreateRemainingInvoice(t *testing.T) {
+	newContext := func(bookingID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+			Users: []firebaseauth.User{
+				{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "phone"}}},
+			},
+		})
+		ctx.Set("userFromDatabase", &user.Model{ID: 1, Status: util.StatusCustomer})
+		ctx.SetPath("/booking/detail/:bookingID/remaining-invoice")
+		ctx.SetParamNames("bookingID")
+		ctx.SetParamValues(bookingID)
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		invoice := Invoice{ID: 2, BookingID: 1, InvoiceURL: "remaining url", Type: util.InvoiceTypeRemaining, Amount: 70000}
+		expectedResponse, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusCreated,
+			Message: "success",
+			Data:    invoice,
+		})
+
+		mockService.On("CreateRemainingInvoice", 1, 1).Return(&invoice, nil)
+
+		if assert.NoError(t, h.CreateRemainingInvoice(ctx)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, string(expectedResponse), strings.TrimSuffix(rec.Body.String(), "\n"))
+		}
+	})
+
+	t.Run("failed booking id is not number", func(t *testing.T) {
+		ctx, rec := newContext("abc")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.CreateRemainingInvoice(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateRemainingInvoice", 1, 1).Return(&Invoice{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.CreateRemainingInvoice(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateRemainingInvoice", 1, 1).Return(&Invoice{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.CreateRemainingInvoice(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetTicket(t *testing.T) {
+	newContext := func(bookingID string, path string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+			Users: []firebaseauth.User{
+				{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "phone"}}},
+			},
+		})
+		ctx.Set("userFromDatabase", &user.Model{ID: 1, Status: util.StatusCustomer})
+		ctx.SetPath(path)
+		ctx.SetParamNames("bookingID")
+		ctx.SetParamValues(bookingID)
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("1", "/booking/detail/:bookingID/ticket")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		ticket := Ticket{BookingID: 1, Token: "1.signature", QRCode: "data:image/png;base64,"}
+		expectedResponse, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    ticket,
+		})
+
+		mockService.On("GetTicket", 1, 1).Return(&ticket, nil)
+
+		if assert.NoError(t, h.GetTicket(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, string(expectedResponse), strings.TrimSuffix(rec.Body.String(), "\n"))
+		}
+	})
+
+	t.Run("failed booking not paid", func(t *testing.T) {
+		ctx, rec := newContext("1", "/booking/detail/:bookingID/ticket")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetTicket", 1, 1).Return(&Ticket{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.GetTicket(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("success pdf", func(t *testing.T) {
+		ctx, rec := newContext("1", "/booking/detail/:bookingID/ticket/pdf")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		file := TicketFile{FileName: "e-ticket-1.pdf", ContentType: util.ApplicationPDF, Content: []byte("%PDF")}
+		mockService.On("GetTicketPDF", 1, 1).Return(&file, nil)
+
+		if assert.NoError(t, h.GetTicketPDF(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, `attachment; filename="e-ticket-1.pdf"`, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, "%PDF", rec.Body.String())
+		}
+	})
+
+	t.Run("failed pdf booking id is not number", func(t *testing.T) {
+		ctx, rec := newContext("abc", "/booking/detail/:bookingID/ticket/pdf")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetTicketPDF(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_CheckIn(t *testing.T) {
+	newContext := func(body string, providerID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/business-admin/booking/check-in", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+			Users: []firebaseauth.User{
+				{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: providerID}}},
+			},
+		})
+		ctx.Set("userFromDatabase", &user.Model{ID: 2, Status: util.StatusBusinessAdmin})
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext(`{"token":"1.signature"}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CheckIn", 2, "1.signature").Return(&CheckInResponse{BookingID: 1, CustomerName: "Tom"}, nil)
+
+		if assert.NoError(t, h.CheckIn(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"customer_name":"Tom"`)
+		}
+	})
+
+	t.Run("failed forbidden user", func(t *testing.T) {
+		ctx, rec := newContext(`{"token":"1.signature"}`, "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.CheckIn(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed booking of another place", func(t *testing.T) {
+		ctx, rec := newContext(`{"token":"1.signature"}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CheckIn", 2, "1.signature").Return(&CheckInResponse{}, errors.Wrap(ErrForbidden, "test error"))
+
+		util.ErrorHandler(h.CheckIn(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed invalid token", func(t *testing.T) {
+		ctx, rec := newContext(`{"token":"invalid"}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CheckIn", 2, "invalid").Return(&CheckInResponse{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.CheckIn(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed booking not found", func(t *testing.T) {
+		ctx, rec := newContext(`{"token":"9.signature"}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CheckIn", 2, "9.signature").Return(&CheckInResponse{}, errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.CheckIn(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_MarkNoShow(t *testing.T) {
+	newContext := func(bookingID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+			Users: []firebaseauth.User{
+				{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "password"}}},
+			},
+		})
+		ctx.Set("userFromDatabase", &user.Model{ID: 2, Status: util.StatusBusinessAdmin})
+		ctx.SetPath("/business-admin/booking/:bookingID/no-show")
+		ctx.SetParamNames("bookingID")
+		ctx.SetParamValues(bookingID)
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("MarkNoShow", 2, 1).Return(nil)
+
+		if assert.NoError(t, h.MarkNoShow(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed still in grace period", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("MarkNoShow", 2, 1).Return(errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.MarkNoShow(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed booking of another place", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("MarkNoShow", 2, 1).Return(errors.Wrap(ErrForbidden, "test error"))
+
+		util.ErrorHandler(h.MarkNoShow(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed booking id is not number", func(t *testing.T) {
+		ctx, rec := newContext("abc")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.MarkNoShow(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("success manual check-in", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("CheckInByBookingID", 2, 1).Return(&CheckInResponse{BookingID: 1}, nil)
+
+		if assert.NoError(t, h.CheckInByBookingID(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed manual check-in not found", func(t *testing.T) {
+		ctx, rec := newContext("1")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("CheckInByBookingID", 2, 1).Return(&CheckInResponse{}, errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.CheckInByBookingID(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_NoShowPolicy(t *testing.T) {
+	newContext := func(method string, body string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(method, "/api/v1/business-admin/business-profile/no-show-policy", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+			Users: []firebaseauth.User{
+				{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "password"}}},
+			},
+		})
+		ctx.Set("userFromDatabase", &user.Model{ID: 2, Status: util.StatusBusinessAdmin})
+		return ctx, rec
+	}
+
+	t.Run("success get", func(t *testing.T) {
+		ctx, rec := newContext(http.MethodGet, "")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("GetNoShowPolicy", 2).Return(&NoShowPolicy{Policy: util.NoShowPolicyBlock, Threshold: 3}, nil)
+
+		if assert.NoError(t, h.GetNoShowPolicy(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"threshold":3`)
+		}
+	})
+
+	t.Run("failed get not found", func(t *testing.T) {
+		ctx, rec := newContext(http.MethodGet, "")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("GetNoShowPolicy", 2).Return(&NoShowPolicy{}, errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.GetNoShowPolicy(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("success update", func(t *testing.T) {
+		ctx, rec := newContext(http.MethodPut, `{"policy":2,"threshold":2}`)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("UpdateNoShowPolicy", 2, NoShowPolicy{Policy: util.NoShowPolicyPrepayment, Threshold: 2}).Return(nil)
+
+		if assert.NoError(t, h.UpdateNoShowPolicy(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed update input validation", func(t *testing.T) {
+		ctx, rec := newContext(http.MethodPut, `{"policy":9,"threshold":2}`)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		mockService.On("UpdateNoShowPolicy", 2, NoShowPolicy{Policy: 9, Threshold: 2}).Return(errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.UpdateNoShowPolicy(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_BookingOwnership(t *testing.T) {
+	handlers := map[string]struct {
+		providerID string
+		status     int
+		body       string
+		expect     func(m *MockService, err error)
+		handle     func(h *Handler, c echo.Context) error
+	}{
+		"GetDetail": {"password", util.StatusBusinessAdmin, "", func(m *MockService, err error) {
+			m.On("GetDetail", 1, 1).Return(&Detail{}, err)
+		}, (*Handler).GetDetail},
+		"UpdateBookingStatus": {"password", util.StatusBusinessAdmin, `{"status":3}`, func(m *MockService, err error) {
+			m.On("UpdateBookingStatus", 1, 1, util.BookingSelesai).Return(err)
+		}, (*Handler).UpdateBookingStatus},
+		"GetDetailBookingSaya": {"phone", util.StatusCustomer, "", func(m *MockService, err error) {
+			m.On("GetDetailBookingSaya", 1, 1).Return(&DetailBookingSaya{}, err)
+		}, (*Handler).GetDetailBookingSaya},
+		"CreateRemainingInvoice": {"phone", util.StatusCustomer, "", func(m *MockService, err error) {
+			m.On("CreateRemainingInvoice", 1, 1).Return(&Invoice{}, err)
+		}, (*Handler).CreateRemainingInvoice},
+		"GetTicket": {"phone", util.StatusCustomer, "", func(m *MockService, err error) {
+			m.On("GetTicket", 1, 1).Return(&Ticket{}, err)
+		}, (*Handler).GetTicket},
+		"GetTicketPDF": {"phone", util.StatusCustomer, "", func(m *MockService, err error) {
+			m.On("GetTicketPDF", 1, 1).Return(&TicketFile{}, err)
+		}, (*Handler).GetTicketPDF},
+	}
+
+	results := map[string]struct {
+		err          error
+		expectedCode int
+	}{
+		"booking of another tenant": {errors.Wrap(ErrForbidden, "test error"), http.StatusForbidden},
+		"booking not found":         {errors.Wrap(ErrNotFound, "test error"), http.StatusNotFound},
+	}
+
+	for name, handler := range handlers {
+		for resultName, result := range results {
+			t.Run(name+" "+resultName, func(t *testing.T) {
+				e := echo.New()
+				req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(handler.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetParamNames("bookingID")
+				c.SetParamValues("1")
+				setUser(c, handler.providerID, handler.status)
+
+				mockService := new(MockService)
+				h := NewHandler(mockService)
+				handler.expect(mockService, result.err)
+
+				util.ErrorHandler(handler.handle(h, c), c)
+				assert.Equal(t, result.expectedCode, rec.Code)
+			})
+		}
+
+		t.Run(name+" user of the other role", func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("bookingID")
+			c.SetParamValues("1")
+			if handler.status == util.StatusCustomer {
+				setUser(c, "password", util.StatusBusinessAdmin)
+			} else {
+				setUser(c, "phone", util.StatusCustomer)
+			}
+
+			mockService := new(MockService)
+			h := NewHandler(mockService)
+
+			util.ErrorHandler(handler.handle(h, c), c)
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			mockService.AssertNotCalled(t, name)
+		})
+	}
+}
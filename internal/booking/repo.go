@@ -8,6 +8,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
@@ -35,31 +36,31 @@ type Repo interface {
 	GetDetail(int) (*Detail, error)
 	GetItemWrapper(int) (*ItemsWrapper, error)
 	GetTicketPriceWrapper(int) (*TicketPriceWrapper, error)
-	UpdateBookingStatus(int, int) error
+	UpdateBookingStatus(bookingID int, newStatus int, eventType string) error
 	GetMyBookingsOngoing(localID string) (*[]Booking, error)
 	GetMyBookingsPreviousWithPagination(localID string, params BookingsListRequest) (*List, error)
 	InsertXenditInformation(params XenditInformation) (bool, error)
-	UpdateBookingStatusByXenditID(string, int) error
 	GetPlaceBookingPrice(placeID int) (float64, error)
 	GetInvoicesFromBooking(ID int) (bool, error)
 	AddExpiredPayment(ID int, expiredAt time.Time) error
-	IncrementBusinessAdminBalance(float64, int) error
 	GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error)
 	GetItemByBookingID(bookingID int) (*[]Item, error)
-}
-
-func (r repo) IncrementBusinessAdminBalance(balance float64, placeID int) error {
-	query := "UPDATE business_owners as bo SET balance = balance + $1 " +
-		"FROM places as p " +
-		"WHERE p.user_id = bo.user_id " +
-		"AND p.id = $2"
-
-	_, err := r.db.Exec(query, balance, placeID)
-	if err != nil {
-		return errors.Wrap(ErrInternalServerError, err.Error())
-	}
-
-	return nil
+	InsertInvoice(invoice Invoice) error
+	GetInvoiceByXenditID(xenditID string) (*Invoice, error)
+	GetInvoicesByBookingID(bookingID int) (*[]Invoice, error)
+	SettleInvoice(ID int, status int, credit float64, placeID int) (bool, error)
+	CountUnpaidRequiredInvoices(bookingID int) (int, error)
+	CountPendingRequiredInvoices(bookingID int) (int, error)
+	FailUnpaidBooking(bookingID int, placeID int) (bool, error)
+	GetPendingInvoices() (*[]Invoice, error)
+	GetCheckInInformation(bookingID int) (*CheckInInformation, error)
+	GetBookingOwnership(bookingID int) (*Ownership, error)
+	CheckInBooking(bookingID int) (*time.Time, error)
+	CompleteBooking(bookingID int) error
+	MarkNoShow(bookingID int) error
+	GetNoShowPolicy(userID int) (*NoShowPolicy, error)
+	UpdateNoShowPolicy(userID int, policy NoShowPolicy) error
+	GetCustomerNoShowPolicy(placeID int, userID int) (*CustomerNoShowPolicy, error)
 }
 
 func (r repo) AddExpiredPayment(ID int, expiredAt time.Time) error {
@@ -196,16 +197,33 @@ func (r repo) CreateBookingItems(items []CreateBookingItemsParams) (*CreateBooki
 	return &CreateBookingItemsResponse{TotalPrice: totalPrice}, nil
 }
 
+// CreateBooking insert the booking and record its created event in a transaction
 func (r repo) CreateBooking(booking CreateBookingParams) (*CreateBookingResponse, error) {
 	var bookingID CreateBookingResponse
 
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
 	query := `INSERT INTO 
-					bookings (user_id, place_id, date, start_time, end_time, capacity, status, total_price)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+					bookings (user_id, place_id, date, start_time, end_time, capacity, status, total_price, booking_price, payment_type, split_count)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 				RETURNING id
 				`
 
-	err := r.db.QueryRow(query, booking.UserID, booking.PlaceID, booking.Date, booking.StartTime, booking.EndTime, booking.Capacity, booking.Status, booking.TotalPrice).Scan(&bookingID.ID)
+	err = tx.QueryRow(query, booking.UserID, booking.PlaceID, booking.Date, booking.StartTime, booking.EndTime, booking.Capacity, booking.Status, booking.TotalPrice, booking.BookingPrice, booking.PaymentType, booking.SplitCount).Scan(&bookingID.ID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	err = notification.InsertEvent(tx, notification.Event{Type: util.NotificationBookingCreated, BookingID: bookingID.ID})
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
@@ -304,7 +322,8 @@ func (r repo) GetPlaceBookingPrice(placeID int) (float64, error) {
 func (r *repo) GetDetail(bookingID int) (*Detail, error) {
 	var bookingDetail Detail
 
-	query := `SELECT b.id, u.name, u.phone_number, u.image, b.place_id, b.date, b.start_time, b.end_time, b.capacity, b.status, b.total_price, b.created_at
+	query := `SELECT b.id, u.name, u.phone_number, u.image, b.place_id, b.date, b.start_time, b.end_time, b.capacity, b.status, b.payment_type, b.split_count, b.total_price, b.created_at,
+			  (SELECT COUNT(id) FROM bookings WHERE user_id = b.user_id AND status = 6) AS no_show_count
 			  FROM bookings b, users u
 			  WHERE b.id = $1 AND b.user_id = u.id`
 	err := r.db.Get(&bookingDetail, query, bookingID)
@@ -332,7 +351,7 @@ func (r *repo) GetItemWrapper(bookingID int) (*ItemsWrapper, error) {
 func (r *repo) GetTicketPriceWrapper(bookingID int) (*TicketPriceWrapper, error) {
 	var ticketPrice TicketPriceWrapper
 
-	query := "SELECT booking_price FROM places INNER JOIN bookings ON bookings.place_id = places.id WHERE bookings.id= $1"
+	query := "SELECT COALESCE(bookings.booking_price, places.booking_price, 0) AS booking_price, bookings.booking_price IS NOT NULL AS fixed FROM places INNER JOIN bookings ON bookings.place_id = places.id WHERE bookings.id= $1"
 	err := r.db.Get(&ticketPrice, query, bookingID)
 
 	if err != nil {
@@ -342,25 +361,31 @@ func (r *repo) GetTicketPriceWrapper(bookingID int) (*TicketPriceWrapper, error)
 	return &ticketPrice, nil
 }
 
-func (r *repo) UpdateBookingStatus(bookingID int, newStatus int) error {
-	query := "UPDATE bookings SET status = $2 WHERE id= $1"
-	_, err := r.db.Exec(query, bookingID, newStatus)
+// UpdateBookingStatus change the status of the booking, the event is recorded in the same transaction when eventType is given
+func (r *repo) UpdateBookingStatus(bookingID int, newStatus int, eventType string) error {
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return errors.Wrap(ErrInternalServerError, err.Error())
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-func (r *repo) UpdateBookingStatusByXenditID(xenditID string, newStatus int) error {
-	query := "UPDATE bookings SET status = $2 WHERE xendit_id= $1"
-	_, err := r.db.Exec(query, xenditID, newStatus)
+	query := "UPDATE bookings SET status = $2 WHERE id= $1"
+	_, err = tx.Exec(query, bookingID, newStatus)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.Wrap(ErrNotFound, fmt.Sprintf("data with xendit_id = %s is not found", xenditID))
-		}
 		return errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
+	if eventType != "" {
+		err = notification.InsertEvent(tx, notification.Event{Type: eventType, BookingID: bookingID})
+		if err != nil {
+			return errors.Wrap(ErrInternalServerError, err.Error())
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
 	return nil
 }
 
@@ -369,7 +394,7 @@ func (r *repo) GetMyBookingsOngoing(localID string) (*[]Booking, error) {
 	bookingList = make([]Booking, 0)
 
 	query := `
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -393,7 +418,7 @@ func (r repo) GetMyBookingsPreviousWithPagination(localID string, params Booking
 	myBookingsPrevious.Bookings = make([]Booking, 0)
 
 	query := `
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -445,7 +470,7 @@ func (r repo) GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error) {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	query = `SELECT p.booking_price FROM places p, bookings b WHERE b.id = $1 AND b.place_id = p.id`
+	query = `SELECT COALESCE(b.booking_price, p.booking_price, 0) FROM places p, bookings b WHERE b.id = $1 AND b.place_id = p.id`
 
 	err = r.db.Get(&placeBookingPrice, query, bookingID)
 	if err != nil {
@@ -459,7 +484,7 @@ func (r repo) GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error) {
 func (r repo) GetItemByBookingID(bookingID int) (*[]Item, error) {
 	var items []Item
 	items = make([]Item, 0)
-	var placeID int
+	var bookingPrice float64
 
 	query := `
 	SELECT i.id, i.name, i.price, bi.qty, bi.total_price
@@ -471,14 +496,9 @@ func (r repo) GetItemByBookingID(bookingID int) (*[]Item, error) {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	query = `SELECT p.id FROM places p, bookings b WHERE p.id = b.place_id AND b.id = $1`
-
-	err = r.db.Get(&placeID, query, bookingID)
-	if err != nil {
-		return nil, errors.Wrap(ErrInternalServerError, err.Error())
-	}
+	query = `SELECT COALESCE(b.booking_price, p.booking_price, 0) FROM places p, bookings b WHERE p.id = b.place_id AND b.id = $1`
 
-	bookingPrice, err := r.GetPlaceBookingPrice(placeID)
+	err = r.db.Get(&bookingPrice, query, bookingID)
 	if err != nil {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
@@ -504,3 +524,345 @@ func (r repo) GetItemByBookingID(bookingID int) (*[]Item, error) {
 
 	return &items, nil
 }
+
+func (r repo) InsertInvoice(invoice Invoice) error {
+	query := `INSERT INTO booking_invoices (booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, expired_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.Exec(query, invoice.BookingID, invoice.XenditID, invoice.InvoiceURL, invoice.Type, invoice.Amount, invoice.PlatformFee, invoice.Status, invoice.ExpiredAt)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetInvoiceByXenditID(xenditID string) (*Invoice, error) {
+	var invoice Invoice
+
+	query := `SELECT id, booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, COALESCE(expired_at, created_at) AS expired_at
+			FROM booking_invoices WHERE xendit_id = $1`
+	err := r.db.Get(&invoice, query, xenditID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("invoice with xendit_id = %s is not found", xenditID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &invoice, nil
+}
+
+func (r repo) GetInvoicesByBookingID(bookingID int) (*[]Invoice, error) {
+	invoices := make([]Invoice, 0)
+
+	query := `SELECT id, booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, COALESCE(expired_at, created_at) AS expired_at
+			FROM booking_invoices WHERE booking_id = $1 ORDER BY id`
+	err := r.db.Select(&invoices, query, bookingID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &invoices, nil
+}
+
+func (r repo) GetPendingInvoices() (*[]Invoice, error) {
+	invoices := make([]Invoice, 0)
+
+	query := `SELECT id, booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, COALESCE(expired_at, created_at) AS expired_at
+			FROM booking_invoices WHERE status = $1 ORDER BY id`
+	err := r.db.Select(&invoices, query, util.InvoicePending)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &invoices, nil
+}
+
+// SettleInvoice change the status of pending invoice and credit the owner of the place in a transaction,
+// false is returned when the invoice is already settled so the same payment is never credited twice
+func (r repo) SettleInvoice(ID int, status int, credit float64, placeID int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var settledID int
+	query := "UPDATE booking_invoices SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING id"
+	err = tx.Get(&settledID, query, status, ID, util.InvoicePending)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if credit > 0 {
+		query = "UPDATE business_owners as bo SET balance = balance + $1 " +
+			"FROM places as p " +
+			"WHERE p.user_id = bo.user_id " +
+			"AND p.id = $2"
+
+		_, err = tx.Exec(query, credit, placeID)
+		if err != nil {
+			return false, errors.Wrap(ErrInternalServerError, err.Error())
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return true, nil
+}
+
+func (r repo) CountUnpaidRequiredInvoices(bookingID int) (int, error) {
+	var count int
+
+	query := "SELECT COUNT(id) FROM booking_invoices WHERE booking_id = $1 AND type <> $2 AND status <> $3"
+	err := r.db.Get(&count, query, bookingID, util.InvoiceTypeRemaining, util.InvoicePaid)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+func (r repo) CountPendingRequiredInvoices(bookingID int) (int, error) {
+	var count int
+
+	query := "SELECT COUNT(id) FROM booking_invoices WHERE booking_id = $1 AND type <> $2 AND status = $3"
+	err := r.db.Get(&count, query, bookingID, util.InvoiceTypeRemaining, util.InvoicePending)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+// FailUnpaidBooking fail the booking that is still waiting for payment, its paid invoices are marked as refunded
+// and their credit is taken back from the owner balance in a single transaction
+func (r repo) FailUnpaidBooking(bookingID int, placeID int) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var failedID int
+	query := "UPDATE bookings SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING id"
+	err = tx.Get(&failedID, query, util.BookingGagal, bookingID, util.BookingBelumMembayar)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	var refunded float64
+	query = `WITH refunded AS (
+				UPDATE booking_invoices SET status = $1, updated_at = NOW()
+				WHERE booking_id = $2 AND status = $3
+				RETURNING amount - platform_fee AS credit
+			)
+			SELECT COALESCE(SUM(credit), 0) FROM refunded`
+	err = tx.Get(&refunded, query, util.InvoiceRefunded, bookingID, util.InvoicePaid)
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if refunded > 0 {
+		query = "UPDATE business_owners as bo SET balance = balance - $1 " +
+			"FROM places as p " +
+			"WHERE p.user_id = bo.user_id " +
+			"AND p.id = $2"
+
+		_, err = tx.Exec(query, refunded, placeID)
+		if err != nil {
+			return false, errors.Wrap(ErrInternalServerError, err.Error())
+		}
+	}
+
+	err = notification.InsertEvent(tx, notification.Event{Type: util.NotificationBookingExpired, BookingID: bookingID})
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return true, nil
+}
+
+func (r repo) GetCheckInInformation(bookingID int) (*CheckInInformation, error) {
+	var information CheckInInformation
+
+	query := `SELECT b.id, p.user_id, u.name, b.date, b.start_time, b.end_time, b.capacity, b.status, b.arrived_at
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			INNER JOIN users u ON b.user_id = u.id
+			WHERE b.id = $1`
+	err := r.db.Get(&information, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id = %d not found", bookingID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &information, nil
+}
+
+func (r repo) GetBookingOwnership(bookingID int) (*Ownership, error) {
+	var ownership Ownership
+
+	query := `SELECT b.id, b.user_id AS customer_id, p.user_id AS owner_id, b.status, b.arrived_at
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			WHERE b.id = $1`
+	err := r.db.Get(&ownership, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id = %d not found", bookingID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &ownership, nil
+}
+
+// CheckInBooking record the arrival of a paid booking, the booking stays ongoing until the business admin complete it
+// so the customer can not review it before the visit ends
+func (r repo) CheckInBooking(bookingID int) (*time.Time, error) {
+	var arrivedAt time.Time
+
+	// only the first check-in is recorded when the same ticket is scanned concurrently or the booking is marked as no-show
+	query := "UPDATE bookings SET arrived_at = NOW() WHERE id = $1 AND status = $2 AND arrived_at IS NULL RETURNING arrived_at"
+	err := r.db.Get(&arrivedAt, query, bookingID, util.BookingBerhasil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrInputValidationError, "booking is already checked in or its status is changed")
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &arrivedAt, nil
+}
+
+// CompleteBooking set the checked in booking as completed and count it in the place stats, completed booking is not counted twice
+func (r repo) CompleteBooking(bookingID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var placeID int
+	query := "UPDATE bookings SET status = $2 WHERE id = $1 AND status = $3 AND arrived_at IS NOT NULL RETURNING place_id"
+	err = tx.Get(&placeID, query, bookingID, util.BookingSelesai, util.BookingBerhasil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Wrap(ErrInputValidationError, "booking is not checked in or its status is changed")
+		}
+
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	err = incrementPlaceBookingCount(tx, placeID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// incrementPlaceBookingCount count the completed booking for popularity of the place
+func incrementPlaceBookingCount(tx *sqlx.Tx, placeID int) error {
+	query := `INSERT INTO place_stats (place_id, booking_count) VALUES ($1, 1)
+			ON CONFLICT (place_id) DO UPDATE SET booking_count = place_stats.booking_count + 1, updated_at = NOW()`
+
+	_, err := tx.Exec(query, placeID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) MarkNoShow(bookingID int) error {
+	query := "UPDATE bookings SET status = $2 WHERE id = $1 AND status = $3 AND arrived_at IS NULL"
+	result, err := r.db.Exec(query, bookingID, util.BookingTidakHadir, util.BookingBerhasil)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrInputValidationError, "booking is already checked in or its status is changed")
+	}
+
+	return nil
+}
+
+func (r repo) GetNoShowPolicy(userID int) (*NoShowPolicy, error) {
+	var policy NoShowPolicy
+
+	query := "SELECT no_show_policy, no_show_threshold FROM places WHERE user_id = $1"
+	err := r.db.Get(&policy, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, "place not found")
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &policy, nil
+}
+
+func (r repo) UpdateNoShowPolicy(userID int, policy NoShowPolicy) error {
+	query := "UPDATE places SET no_show_policy = $1, no_show_threshold = $2 WHERE user_id = $3"
+	_, err := r.db.Exec(query, policy.Policy, policy.Threshold, userID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetCustomerNoShowPolicy(placeID int, userID int) (*CustomerNoShowPolicy, error) {
+	var policy CustomerNoShowPolicy
+
+	query := `SELECT p.no_show_policy, p.no_show_threshold,
+				(SELECT COUNT(id) FROM bookings WHERE user_id = $2 AND status = $3) AS no_show_count
+			FROM places p WHERE p.id = $1`
+	err := r.db.Get(&policy, query, placeID, userID, util.BookingTidakHadir)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("place with id = %d not found", placeID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &policy, nil
+}
@@ -496,6 +496,8 @@ func TestRepo_CheckedItem(t *testing.T) {
 	})
 }
 
+const insertEventQuery = "INSERT INTO notification_events (type, booking_id, user_id, amount)"
+
 func TestRepo_CreateBooking(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
@@ -517,22 +519,30 @@ func TestRepo_CreateBooking(t *testing.T) {
 			StartTime:  startTime,
 			EndTime:    endTime,
 			Capacity:   10,
-			Status:     1,
-			TotalPrice: 10000,
+			Status:       1,
+			TotalPrice:   10000,
+			BookingPrice: 20000,
+			PaymentType:  util.PaymentTypeSplit,
+			SplitCount:   2,
 		}
 
 		rows := mock.NewRows([]string{"id"}).AddRow("1")
+		mock.ExpectBegin()
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO 
-					bookings (user_id, place_id, date, start_time, end_time, capacity, status, total_price)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+					bookings (user_id, place_id, date, start_time, end_time, capacity, status, total_price, booking_price, payment_type, split_count)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 				RETURNING id`)).
-			WithArgs(booking.UserID, booking.PlaceID, booking.Date, booking.StartTime, booking.EndTime, booking.Capacity, booking.Status, booking.TotalPrice).
+			WithArgs(booking.UserID, booking.PlaceID, booking.Date, booking.StartTime, booking.EndTime, booking.Capacity, booking.Status, booking.TotalPrice, booking.BookingPrice, booking.PaymentType, booking.SplitCount).
 			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(insertEventQuery)).WithArgs(util.NotificationBookingCreated, 1, 0, float64(0)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		res, err := repo.CreateBooking(booking)
 		assert.NotNil(t, res)
 		assert.Nil(t, err)
 		assert.Equal(t, 1, res.ID)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("failed", func(t *testing.T) {
@@ -559,12 +569,14 @@ func TestRepo_CreateBooking(t *testing.T) {
 			TotalPrice: 10000,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO 
-					bookings (user_id, place_id, date, start_time, end_time, capacity, status, total_price)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+					bookings (user_id, place_id, date, start_time, end_time, capacity, status, total_price, booking_price, payment_type, split_count)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 				RETURNING id`)).
-			WithArgs(booking.UserID, booking.PlaceID, booking.Date, booking.StartTime, booking.EndTime, booking.Capacity, booking.Status, booking.TotalPrice).
+			WithArgs(booking.UserID, booking.PlaceID, booking.Date, booking.StartTime, booking.EndTime, booking.Capacity, booking.Status, booking.TotalPrice, booking.BookingPrice, booking.PaymentType, booking.SplitCount).
 			WillReturnError(ErrInternalServerError)
+		mock.ExpectRollback()
 
 		res, err := repo.CreateBooking(booking)
 		assert.Nil(t, res)
@@ -729,8 +741,11 @@ func TestRepo_GetDetailSuccess(t *testing.T) {
 		EndTime:        time.Now(),
 		Capacity:       10,
 		Status:         1,
+		PaymentType:    util.PaymentTypeDeposit,
+		SplitCount:     1,
 		TotalPriceItem: 100000.0,
 		CreatedAt:      createdAtRow,
+		NoShowCount:    2,
 	}
 
 	// Mock DB
@@ -744,7 +759,7 @@ func TestRepo_GetDetailSuccess(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 	rows := mock.
-		NewRows([]string{"id", "name", "date", "start_time", "end_time", "capacity", "status", "total_price", "created_at"}).
+		NewRows([]string{"id", "name", "date", "start_time", "end_time", "capacity", "status", "payment_type", "split_count", "total_price", "created_at", "no_show_count"}).
 		AddRow(
 			bookingDetailExpected.ID,
 			bookingDetailExpected.CustomerName,
@@ -753,11 +768,15 @@ func TestRepo_GetDetailSuccess(t *testing.T) {
 			bookingDetailExpected.EndTime,
 			bookingDetailExpected.Capacity,
 			bookingDetailExpected.Status,
+			bookingDetailExpected.PaymentType,
+			bookingDetailExpected.SplitCount,
 			bookingDetailExpected.TotalPriceItem,
 			bookingDetailExpected.CreatedAt,
+			bookingDetailExpected.NoShowCount,
 		)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, u.name, u.phone_number, u.image, b.place_id, b.date, b.start_time, b.end_time, b.capacity, b.status, b.total_price, b.created_at
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, u.name, u.phone_number, u.image, b.place_id, b.date, b.start_time, b.end_time, b.capacity, b.status, b.payment_type, b.split_count, b.total_price, b.created_at,
+									   (SELECT COUNT(id) FROM bookings WHERE user_id = b.user_id AND status = 6) AS no_show_count
 									   FROM bookings b, users u
 									   WHERE b.id = $1 AND b.user_id = u.id`)).
 		WithArgs(bookingID).
@@ -784,7 +803,7 @@ func TestRepo_GetDetailInternalServerError(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, u.name, u.phone_number, u.image, b.date, b.start_time, b.end_time, b.capacity, b.status, b.total_price, b.created_at
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT b.id, u.name, u.phone_number, u.image, b.date, b.start_time, b.end_time, b.capacity, b.status, b.payment_type, b.split_count, b.total_price, b.created_at
 		FROM bookings b, users u
 		WHERE b.id = $1 AND b.user_id = u.id`)).
 		WithArgs(bookingID).
@@ -879,6 +898,7 @@ func TestRepo_GetTicketPriceWrapperSuccess(t *testing.T) {
 	bookingID := 1
 	ticketPriceWrapperExpected := &TicketPriceWrapper{
 		Price: 10000,
+		Fixed: true,
 	}
 
 	// Mock DB
@@ -892,12 +912,13 @@ func TestRepo_GetTicketPriceWrapperSuccess(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 	rows := mock.
-		NewRows([]string{"booking_price"}).
+		NewRows([]string{"booking_price", "fixed"}).
 		AddRow(
 			ticketPriceWrapperExpected.Price,
+			ticketPriceWrapperExpected.Fixed,
 		)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT booking_price FROM places INNER JOIN bookings ON bookings.place_id = places.id WHERE bookings.id= $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(bookings.booking_price, places.booking_price, 0) AS booking_price, bookings.booking_price IS NOT NULL AS fixed FROM places INNER JOIN bookings ON bookings.place_id = places.id WHERE bookings.id= $1")).
 		WithArgs(bookingID).
 		WillReturnRows(rows)
 
@@ -922,7 +943,7 @@ func TestRepo_GetTicketPriceWrapperInternalServerError(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT booking_price FROM places INNER JOIN bookings ON bookings.place_id = places.id WHERE bookings.id= $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(bookings.booking_price, places.booking_price, 0) AS booking_price, bookings.booking_price IS NOT NULL AS fixed FROM places INNER JOIN bookings ON bookings.place_id = places.id WHERE bookings.id= $1")).
 		WithArgs(bookingID).
 		WillReturnError(sql.ErrTxDone)
 
@@ -947,12 +968,70 @@ func TestRepo_UpdateBookingStatusSuccess(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE bookings SET status = $2 WHERE id= $1")).
+		WithArgs(bookingID, newStatus).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repoMock.UpdateBookingStatus(bookingID, newStatus, "")
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_UpdateBookingStatusSuccessWithEvent(t *testing.T) {
+	bookingID := 1
+	newStatus := util.BookingBerhasil
+
+	// Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	// Expectation
+	repoMock := NewRepo(sqlxDB)
+
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE bookings SET status = $2 WHERE id= $1")).
 		WithArgs(bookingID, newStatus).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertEventQuery)).WithArgs(util.NotificationBookingPaid, bookingID, 0, float64(0)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = repoMock.UpdateBookingStatus(bookingID, newStatus)
+	err = repoMock.UpdateBookingStatus(bookingID, newStatus, util.NotificationBookingPaid)
 	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRepo_UpdateBookingStatusFailedInsertEvent(t *testing.T) {
+	bookingID := 1
+	newStatus := util.BookingBerhasil
+
+	// Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	// Expectation
+	repoMock := NewRepo(sqlxDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE bookings SET status = $2 WHERE id= $1")).
+		WithArgs(bookingID, newStatus).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertEventQuery)).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = repoMock.UpdateBookingStatus(bookingID, newStatus, util.NotificationBookingPaid)
+	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	assert.Nil(t, mock.ExpectationsWereMet())
 }
 
 func TestRepo_UpdateBookingStatusInternalServerError(t *testing.T) {
@@ -970,11 +1049,13 @@ func TestRepo_UpdateBookingStatusInternalServerError(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE bookings SET status = $2 WHERE id= $1")).
 		WithArgs(bookingID, newStatus).
 		WillReturnError(sql.ErrTxDone)
+	mock.ExpectRollback()
 
-	err = repoMock.UpdateBookingStatus(bookingID, newStatus)
+	err = repoMock.UpdateBookingStatus(bookingID, newStatus, "")
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 }
 
@@ -1046,7 +1127,7 @@ func TestRepo_GetMyBookingsOngoingSuccess(t *testing.T) {
 		)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1081,7 +1162,7 @@ func TestRepo_GetMyBookingsOngoingEmpty(t *testing.T) {
 		NewRows([]string{"id", "place_id", "place_name", "place_image", "date", "start_time", "end_time", "status", "total_price", "payment_expired_at"})
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1113,7 +1194,7 @@ func TestRepo_GetMyBookingsOngoingInternalServerError(t *testing.T) {
 	repoMock := NewRepo(sqlxDB)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1202,7 +1283,7 @@ func TestRepo_GetMyBookingsPreviousWithPaginationSuccess(t *testing.T) {
 			myBookingsPreviousExpected.Bookings[1].ExpiredAt,
 		)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1252,7 +1333,7 @@ func TestRepo_GetMyBookingsPreviousWithPaginationEmpty(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1293,7 +1374,7 @@ func TestRepo_GetMyBookingsPreviousWithPaginationEmptyWhenCount(t *testing.T) {
 	rows := mock.
 		NewRows([]string{"id", "place_id", "place_name", "place_image", "date", "start_time", "end_time", "status", "total_price", "payment_expired_at"})
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1336,7 +1417,7 @@ func TestRepo_GetMyBookingsPreviousWithPaginationError(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1372,7 +1453,7 @@ func TestRepo_GetMyBookingsPreviousWithPaginationErrorWhenCount(t *testing.T) {
 	rows := mock.
 		NewRows([]string{"id", "place_id", "place_name", "place_image", "date", "start_time", "end_time", "status", "total_price", "payment_expired_at"})
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, places.booking_price + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
+		SELECT bookings.id, bookings.place_id, places.name as place_name, places.image as place_image, bookings.date, bookings.start_time, bookings.end_time, bookings.status, COALESCE(bookings.booking_price, places.booking_price, 0) + bookings.total_price + 3000 as total_price, COALESCE(bookings.payment_expired_at, CURRENT_TIMESTAMP) AS payment_expired_at
 		FROM users 
 			JOIN bookings ON users.id = bookings.user_id 	
 			JOIN places ON bookings.place_id = places.id 
@@ -1396,7 +1477,21 @@ func TestRepo_GetMyBookingsPreviousWithPaginationErrorWhenCount(t *testing.T) {
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 }
 
-func TestRepo_UpdateBookingStatusByXenditID(t *testing.T) {
+func TestRepo_InsertInvoice(t *testing.T) {
+	query := `INSERT INTO booking_invoices (booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, expired_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	invoice := Invoice{
+		BookingID:   1,
+		XenditID:    "xendit-1",
+		InvoiceURL:  "http://invoice",
+		Type:        util.InvoiceTypeDeposit,
+		Amount:      33000,
+		PlatformFee: 3000,
+		Status:      util.InvoicePending,
+		ExpiredAt:   time.Date(2022, 5, 7, 10, 0, 0, 0, time.UTC),
+	}
+
 	t.Run("success", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
@@ -1408,10 +1503,11 @@ func TestRepo_UpdateBookingStatusByXenditID(t *testing.T) {
 		// Expectation
 		repoMock := NewRepo(sqlxDB)
 
-		query := "UPDATE bookings SET status = $2 WHERE xendit_id= $1"
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("1", 1).WillReturnResult(driver.ResultNoRows)
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(invoice.BookingID, invoice.XenditID, invoice.InvoiceURL, invoice.Type, invoice.Amount, invoice.PlatformFee, invoice.Status, invoice.ExpiredAt).
+			WillReturnResult(driver.ResultNoRows)
 
-		err = repoMock.UpdateBookingStatusByXenditID("1", 1)
+		err = repoMock.InsertInvoice(invoice)
 		assert.Nil(t, err)
 	})
 
@@ -1426,15 +1522,32 @@ func TestRepo_UpdateBookingStatusByXenditID(t *testing.T) {
 		// Expectation
 		repoMock := NewRepo(sqlxDB)
 
-		query := "UPDATE bookings SET status = $2 WHERE xendit_id= $1"
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("1", 1).WillReturnError(ErrInternalServerError)
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(invoice.BookingID, invoice.XenditID, invoice.InvoiceURL, invoice.Type, invoice.Amount, invoice.PlatformFee, invoice.Status, invoice.ExpiredAt).
+			WillReturnError(ErrInternalServerError)
 
-		err = repoMock.UpdateBookingStatusByXenditID("1", 1)
-		assert.NotNil(t, err)
+		err = repoMock.InsertInvoice(invoice)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
+}
 
-	t.Run("failed no rows", func(t *testing.T) {
+func TestRepo_GetInvoiceByXenditID(t *testing.T) {
+	query := `SELECT id, booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, COALESCE(expired_at, created_at) AS expired_at
+			FROM booking_invoices WHERE xendit_id = $1`
+
+	expected := Invoice{
+		ID:          1,
+		BookingID:   1,
+		XenditID:    "xendit-1",
+		InvoiceURL:  "http://invoice",
+		Type:        util.InvoiceTypeFull,
+		Amount:      23000,
+		PlatformFee: 3000,
+		Status:      util.InvoicePending,
+		ExpiredAt:   time.Date(2022, 5, 7, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("success", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -1445,249 +1558,665 @@ func TestRepo_UpdateBookingStatusByXenditID(t *testing.T) {
 		// Expectation
 		repoMock := NewRepo(sqlxDB)
 
-		query := "UPDATE bookings SET status = $2 WHERE xendit_id= $1"
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("1", 1).WillReturnError(sql.ErrNoRows)
+		rows := mock.NewRows([]string{"id", "booking_id", "xendit_id", "invoice_url", "type", "amount", "platform_fee", "status", "expired_at"}).
+			AddRow(expected.ID, expected.BookingID, expected.XenditID, expected.InvoiceURL, expected.Type, expected.Amount, expected.PlatformFee, expected.Status, expected.ExpiredAt)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("xendit-1").WillReturnRows(rows)
 
-		err = repoMock.UpdateBookingStatusByXenditID("1", 1)
-		assert.NotNil(t, err)
+		invoice, err := repoMock.GetInvoiceByXenditID("xendit-1")
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, invoice)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("xendit-1").WillReturnError(sql.ErrNoRows)
+
+		invoice, err := repoMock.GetInvoiceByXenditID("xendit-1")
+		assert.Nil(t, invoice)
 		assert.Equal(t, ErrNotFound, errors.Cause(err))
 	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("xendit-1").WillReturnError(ErrInternalServerError)
+
+		invoice, err := repoMock.GetInvoiceByXenditID("xendit-1")
+		assert.Nil(t, invoice)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
 }
 
-func TestRepo_InsertXenditInformation(t *testing.T) {
+func TestRepo_GetInvoicesByBookingID(t *testing.T) {
+	query := `SELECT id, booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, COALESCE(expired_at, created_at) AS expired_at
+			FROM booking_invoices WHERE booking_id = $1 ORDER BY id`
+
 	t.Run("success", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
 		// Expectation
-		params := XenditInformation{
-			XenditID:    "1",
-			InvoicesURL: "test.com",
-			BookingID:   1,
-		}
 		repoMock := NewRepo(sqlxDB)
 
-		query := "UPDATE bookings SET xendit_id = $1, invoices_url = $2 WHERE id = $3"
+		expiredAt := time.Date(2022, 5, 7, 10, 0, 0, 0, time.UTC)
+		expected := []Invoice{
+			{ID: 1, BookingID: 1, XenditID: "xendit-1", InvoiceURL: "http://invoice/1", Type: util.InvoiceTypeSplit, Amount: 13000, PlatformFee: 3000, Status: util.InvoicePaid, ExpiredAt: expiredAt},
+			{ID: 2, BookingID: 1, XenditID: "xendit-2", InvoiceURL: "http://invoice/2", Type: util.InvoiceTypeSplit, Amount: 10000, Status: util.InvoicePending, ExpiredAt: expiredAt},
+		}
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(params.XenditID, params.InvoicesURL, params.BookingID).
-			WillReturnResult(driver.ResultNoRows)
+		rows := mock.NewRows([]string{"id", "booking_id", "xendit_id", "invoice_url", "type", "amount", "platform_fee", "status", "expired_at"})
+		for _, i := range expected {
+			rows.AddRow(i.ID, i.BookingID, i.XenditID, i.InvoiceURL, i.Type, i.Amount, i.PlatformFee, i.Status, i.ExpiredAt)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
 
-		isOk, err := repoMock.InsertXenditInformation(params)
+		invoices, err := repoMock.GetInvoicesByBookingID(1)
 		assert.Nil(t, err)
-		assert.True(t, isOk)
+		assert.Equal(t, &expected, invoices)
 	})
 
-	t.Run("error internal server", func(t *testing.T) {
+	t.Run("failed internal server error", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
 		// Expectation
-		params := XenditInformation{
-			XenditID:    "1",
-			InvoicesURL: "test.com",
-			BookingID:   1,
-		}
 		repoMock := NewRepo(sqlxDB)
 
-		query := "UPDATE bookings SET xendit_id = $1, invoices_url = $2 WHERE id = $3"
-
-		mock.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(params.XenditID, params.InvoicesURL, params.BookingID).
-			WillReturnError(ErrInternalServerError)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(ErrInternalServerError)
 
-		isOk, err := repoMock.InsertXenditInformation(params)
-		assert.NotNil(t, err)
-		assert.False(t, isOk)
+		invoices, err := repoMock.GetInvoicesByBookingID(1)
+		assert.Nil(t, invoices)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 }
 
-func TestRepo_GetPlaceBookingPrice(t *testing.T) {
+func TestRepo_GetPendingInvoices(t *testing.T) {
+	query := `SELECT id, booking_id, xendit_id, invoice_url, type, amount, platform_fee, status, COALESCE(expired_at, created_at) AS expired_at
+			FROM booking_invoices WHERE status = $1 ORDER BY id`
+
 	t.Run("success", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		newRepo := NewRepo(sqlxDB)
 
-		query := `SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.NewRows([]string{"booking_price"})
-		rows.AddRow(10000.0)
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnRows(rows)
+		expiredAt := time.Date(2022, 5, 7, 10, 0, 0, 0, time.UTC)
+		expected := []Invoice{
+			{ID: 2, BookingID: 1, XenditID: "xendit-2", InvoiceURL: "http://invoice/2", Type: util.InvoiceTypeFull, Amount: 23000, PlatformFee: 3000, Status: util.InvoicePending, ExpiredAt: expiredAt},
+		}
 
-		resp, err := newRepo.GetPlaceBookingPrice(1)
+		rows := mock.NewRows([]string{"id", "booking_id", "xendit_id", "invoice_url", "type", "amount", "platform_fee", "status", "expired_at"})
+		for _, i := range expected {
+			rows.AddRow(i.ID, i.BookingID, i.XenditID, i.InvoiceURL, i.Type, i.Amount, i.PlatformFee, i.Status, i.ExpiredAt)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.InvoicePending).WillReturnRows(rows)
+
+		invoices, err := repoMock.GetPendingInvoices()
 		assert.Nil(t, err)
-		assert.Equal(t, 10000.0, resp)
+		assert.Equal(t, &expected, invoices)
 	})
 
-	t.Run("failed no sql rows", func(t *testing.T) {
+	t.Run("failed internal server error", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		newRepo := NewRepo(sqlxDB)
 
-		query := `SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.InvoicePending).WillReturnError(sql.ErrTxDone)
 
-		rows := mock.NewRows([]string{"booking_price"})
-		rows.AddRow(10000.0)
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnError(sql.ErrNoRows)
+		invoices, err := repoMock.GetPendingInvoices()
+		assert.Nil(t, invoices)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
 
-		resp, err := newRepo.GetPlaceBookingPrice(1)
-		assert.Equal(t, ErrNotFound, errors.Cause(err))
-		assert.Equal(t, 0.0, resp)
+func TestRepo_SettleInvoice(t *testing.T) {
+	settleQuery := "UPDATE booking_invoices SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING id"
+	creditQuery := "UPDATE business_owners as bo SET balance = balance + $1 FROM places as p WHERE p.user_id = bo.user_id AND p.id = $2"
+
+	t.Run("success paid invoice credit the owner", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.InvoicePaid, 1, util.InvoicePending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(creditQuery)).WithArgs(17000.0, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		settled, err := repoMock.SettleInvoice(1, util.InvoicePaid, 17000.0, 2)
+		assert.Nil(t, err)
+		assert.True(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("failed internal server error", func(t *testing.T) {
+	t.Run("success expired invoice is not credited", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		newRepo := NewRepo(sqlxDB)
 
-		query := `SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.NewRows([]string{"booking_price"})
-		rows.AddRow(10000.0)
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnError(ErrInternalServerError)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.InvoiceExpired, 1, util.InvoicePending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
-		resp, err := newRepo.GetPlaceBookingPrice(1)
-		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
-		assert.Equal(t, 0.0, resp)
+		settled, err := repoMock.SettleInvoice(1, util.InvoiceExpired, 0, 2)
+		assert.Nil(t, err)
+		assert.True(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
-}
 
-func TestRepo_AddExpiredPayment(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+	t.Run("invoice is already settled", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
 		repoMock := NewRepo(sqlxDB)
 
-		timeNow := time.Now()
-		query := "UPDATE bookings SET payment_expired_at = $1  WHERE id = $2"
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(timeNow, 1).WillReturnResult(driver.ResultNoRows)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.InvoicePaid, 1, util.InvoicePending).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
 
-		err = repoMock.AddExpiredPayment(1, timeNow)
+		settled, err := repoMock.SettleInvoice(1, util.InvoicePaid, 17000.0, 2)
 		assert.Nil(t, err)
+		assert.False(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("failed", func(t *testing.T) {
+	t.Run("failed credit balance", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
 		repoMock := NewRepo(sqlxDB)
 
-		timeNow := time.Now()
-		query := "UPDATE bookings SET payment_expired_at = $1  WHERE id = $2"
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(timeNow, 1).WillReturnError(ErrInternalServerError)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.InvoicePaid, 1, util.InvoicePending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(creditQuery)).WithArgs(17000.0, 2).WillReturnError(ErrInternalServerError)
+		mock.ExpectRollback()
 
-		err = repoMock.AddExpiredPayment(1, timeNow)
-		assert.NotNil(t, err)
+		settled, err := repoMock.SettleInvoice(1, util.InvoicePaid, 17000.0, 2)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.False(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 }
 
-func TestRepo_GetInvoicesFromBooking(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestRepo_FailUnpaidBooking(t *testing.T) {
+	failQuery := "UPDATE bookings SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING id"
+	refundQuery := "UPDATE booking_invoices SET status = $1, updated_at = NOW()"
+	debitQuery := "UPDATE business_owners as bo SET balance = balance - $1 FROM places as p WHERE p.user_id = bo.user_id AND p.id = $2"
+
+	t.Run("success paid invoices are refunded", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		repoMock := NewRepo(sqlxDB)
-
-		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
 
-		rows := mock.NewRows([]string{"xendit_id"})
-		rows.AddRow("test xendit id")
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnRows(rows)
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
 
-		isExist, err := repoMock.GetInvoicesFromBooking(1)
-		assert.True(t, isExist)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(failQuery)).WithArgs(util.BookingGagal, 10, util.BookingBelumMembayar).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectQuery(regexp.QuoteMeta(refundQuery)).WithArgs(util.InvoiceRefunded, 10, util.InvoicePaid).
+			WillReturnRows(mock.NewRows([]string{"sum"}).AddRow(7000.0))
+		mock.ExpectExec(regexp.QuoteMeta(debitQuery)).WithArgs(7000.0, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(insertEventQuery)).WithArgs(util.NotificationBookingExpired, 10, 0, float64(0)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		failed, err := repoMock.FailUnpaidBooking(10, 2)
 		assert.Nil(t, err)
+		assert.True(t, failed)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("no error but xendit id not found", func(t *testing.T) {
+	t.Run("success nothing paid is not debited", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		repoMock := NewRepo(sqlxDB)
 
-		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.NewRows([]string{"xendit_id"})
-		rows.AddRow("")
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnRows(rows)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(failQuery)).WithArgs(util.BookingGagal, 10, util.BookingBelumMembayar).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectQuery(regexp.QuoteMeta(refundQuery)).WithArgs(util.InvoiceRefunded, 10, util.InvoicePaid).
+			WillReturnRows(mock.NewRows([]string{"sum"}).AddRow(0.0))
+		mock.ExpectExec(regexp.QuoteMeta(insertEventQuery)).WithArgs(util.NotificationBookingExpired, 10, 0, float64(0)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
-		isExist, err := repoMock.GetInvoicesFromBooking(1)
-		assert.False(t, isExist)
+		failed, err := repoMock.FailUnpaidBooking(10, 2)
 		assert.Nil(t, err)
+		assert.True(t, failed)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("failed internal server error", func(t *testing.T) {
+	t.Run("booking is no longer waiting for payment", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		repoMock := NewRepo(sqlxDB)
 
-		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.NewRows([]string{"xendit_id"})
-		rows.AddRow("test xendit id")
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnError(ErrInternalServerError)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(failQuery)).WithArgs(util.BookingGagal, 10, util.BookingBelumMembayar).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
 
-		isExist, err := repoMock.GetInvoicesFromBooking(1)
-		assert.False(t, isExist)
-		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
-		assert.NotNil(t, err)
+		failed, err := repoMock.FailUnpaidBooking(10, 2)
+		assert.Nil(t, err)
+		assert.False(t, failed)
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("err no rows", func(t *testing.T) {
+	t.Run("failed debit balance", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-		repoMock := NewRepo(sqlxDB)
 
-		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.NewRows([]string{"xendit_id"})
-		rows.AddRow("test xendit id")
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(1).
-			WillReturnError(sql.ErrNoRows)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(failQuery)).WithArgs(util.BookingGagal, 10, util.BookingBelumMembayar).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectQuery(regexp.QuoteMeta(refundQuery)).WithArgs(util.InvoiceRefunded, 10, util.InvoicePaid).
+			WillReturnRows(mock.NewRows([]string{"sum"}).AddRow(7000.0))
+		mock.ExpectExec(regexp.QuoteMeta(debitQuery)).WithArgs(7000.0, 2).WillReturnError(ErrInternalServerError)
+		mock.ExpectRollback()
+
+		failed, err := repoMock.FailUnpaidBooking(10, 2)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.False(t, failed)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepo_CountPendingRequiredInvoices(t *testing.T) {
+	query := "SELECT COUNT(id) FROM booking_invoices WHERE booking_id = $1 AND type <> $2 AND status = $3"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		rows := mock.NewRows([]string{"count"}).AddRow(1)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.InvoiceTypeRemaining, util.InvoicePending).WillReturnRows(rows)
+
+		count, err := repoMock.CountPendingRequiredInvoices(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.InvoiceTypeRemaining, util.InvoicePending).WillReturnError(ErrInternalServerError)
+
+		_, err = repoMock.CountPendingRequiredInvoices(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_CountUnpaidRequiredInvoices(t *testing.T) {
+	query := "SELECT COUNT(id) FROM booking_invoices WHERE booking_id = $1 AND type <> $2 AND status <> $3"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		rows := mock.NewRows([]string{"count"}).AddRow(2)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.InvoiceTypeRemaining, util.InvoicePaid).WillReturnRows(rows)
+
+		count, err := repoMock.CountUnpaidRequiredInvoices(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.InvoiceTypeRemaining, util.InvoicePaid).WillReturnError(ErrInternalServerError)
+
+		_, err = repoMock.CountUnpaidRequiredInvoices(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_InsertXenditInformation(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		params := XenditInformation{
+			XenditID:    "1",
+			InvoicesURL: "test.com",
+			BookingID:   1,
+		}
+		repoMock := NewRepo(sqlxDB)
+
+		query := "UPDATE bookings SET xendit_id = $1, invoices_url = $2 WHERE id = $3"
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(params.XenditID, params.InvoicesURL, params.BookingID).
+			WillReturnResult(driver.ResultNoRows)
+
+		isOk, err := repoMock.InsertXenditInformation(params)
+		assert.Nil(t, err)
+		assert.True(t, isOk)
+	})
+
+	t.Run("error internal server", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+		// Expectation
+		params := XenditInformation{
+			XenditID:    "1",
+			InvoicesURL: "test.com",
+			BookingID:   1,
+		}
+		repoMock := NewRepo(sqlxDB)
+
+		query := "UPDATE bookings SET xendit_id = $1, invoices_url = $2 WHERE id = $3"
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(params.XenditID, params.InvoicesURL, params.BookingID).
+			WillReturnError(ErrInternalServerError)
+
+		isOk, err := repoMock.InsertXenditInformation(params)
+		assert.NotNil(t, err)
+		assert.False(t, isOk)
+	})
+}
+
+func TestRepo_GetPlaceBookingPrice(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		newRepo := NewRepo(sqlxDB)
+
+		query := `SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`
+
+		rows := mock.NewRows([]string{"booking_price"})
+		rows.AddRow(10000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		resp, err := newRepo.GetPlaceBookingPrice(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 10000.0, resp)
+	})
+
+	t.Run("failed no sql rows", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		newRepo := NewRepo(sqlxDB)
+
+		query := `SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`
+
+		rows := mock.NewRows([]string{"booking_price"})
+		rows.AddRow(10000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnError(sql.ErrNoRows)
+
+		resp, err := newRepo.GetPlaceBookingPrice(1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		assert.Equal(t, 0.0, resp)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		newRepo := NewRepo(sqlxDB)
+
+		query := `SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`
+
+		rows := mock.NewRows([]string{"booking_price"})
+		rows.AddRow(10000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnError(ErrInternalServerError)
+
+		resp, err := newRepo.GetPlaceBookingPrice(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Equal(t, 0.0, resp)
+	})
+}
+
+func TestRepo_AddExpiredPayment(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		timeNow := time.Now()
+		query := "UPDATE bookings SET payment_expired_at = $1  WHERE id = $2"
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(timeNow, 1).WillReturnResult(driver.ResultNoRows)
+
+		err = repoMock.AddExpiredPayment(1, timeNow)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		timeNow := time.Now()
+		query := "UPDATE bookings SET payment_expired_at = $1  WHERE id = $2"
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(timeNow, 1).WillReturnError(ErrInternalServerError)
+
+		err = repoMock.AddExpiredPayment(1, timeNow)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetInvoicesFromBooking(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+
+		rows := mock.NewRows([]string{"xendit_id"})
+		rows.AddRow("test xendit id")
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		isExist, err := repoMock.GetInvoicesFromBooking(1)
+		assert.True(t, isExist)
+		assert.Nil(t, err)
+	})
+
+	t.Run("no error but xendit id not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+
+		rows := mock.NewRows([]string{"xendit_id"})
+		rows.AddRow("")
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnRows(rows)
+
+		isExist, err := repoMock.GetInvoicesFromBooking(1)
+		assert.False(t, isExist)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+
+		rows := mock.NewRows([]string{"xendit_id"})
+		rows.AddRow("test xendit id")
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnError(ErrInternalServerError)
+
+		isExist, err := repoMock.GetInvoicesFromBooking(1)
+		assert.False(t, isExist)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("err no rows", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		query := "SELECT COALESCE (xendit_id, '') as xendit_id FROM bookings WHERE id = $1"
+
+		rows := mock.NewRows([]string{"xendit_id"})
+		rows.AddRow("test xendit id")
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(1).
+			WillReturnError(sql.ErrNoRows)
 
 		isExist, err := repoMock.GetInvoicesFromBooking(1)
 		assert.False(t, isExist)
@@ -1744,7 +2273,7 @@ func TestRepo_GetDetailBookingSayaSuccess(t *testing.T) {
 		WillReturnRows(rows)
 
 	rows = mock.NewRows([]string{"booking_price"}).AddRow(10000)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT p.booking_price FROM places p, bookings b WHERE b.id = $1 AND b.place_id = p.id`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(b.booking_price, p.booking_price, 0) FROM places p, bookings b WHERE b.id = $1 AND b.place_id = p.id`)).
 		WithArgs(bookingID).
 		WillReturnRows(rows)
 
@@ -1799,7 +2328,7 @@ func TestRepo_GetItemByBookingIDSuccess(t *testing.T) {
 		},
 	}
 
-	bookingID, placeID := 1, 1
+	bookingID := 1
 
 	// Mock DB
 	mockDB, mock, err := sqlmock.New()
@@ -1832,15 +2361,9 @@ func TestRepo_GetItemByBookingIDSuccess(t *testing.T) {
 		WithArgs(bookingID).
 		WillReturnRows(rows)
 
-	rows = mock.NewRows([]string{"id"}).AddRow(placeID)
-	mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT p.id FROM places p, bookings b WHERE p.id = b.place_id AND b.id = $1`)).
-		WithArgs(bookingID).
-		WillReturnRows(rows)
-
 	rows = mock.NewRows([]string{"booking_price"}).AddRow(10000)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE (booking_price, 0) FROM places WHERE id  = $1`)).
-		WithArgs(placeID).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(b.booking_price, p.booking_price, 0) FROM places p, bookings b WHERE p.id = b.place_id AND b.id = $1`)).
+		WithArgs(bookingID).
 		WillReturnRows(rows)
 
 	tempList := []Item{
@@ -1894,8 +2417,8 @@ func TestRepo_GetItemByBookingIDItemError(t *testing.T) {
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 }
 
-func TestRepo_GetItemByBookingIDPlaceIDError(t *testing.T) {
-	bookingID, placeID := 1, 1
+func TestRepo_GetItemByBookingIDBookingPriceError(t *testing.T) {
+	bookingID := 1
 
 	// Mock DB
 	mockDB, mock, err := sqlmock.New()
@@ -1916,10 +2439,8 @@ func TestRepo_GetItemByBookingIDPlaceIDError(t *testing.T) {
 		WithArgs(bookingID).
 		WillReturnRows(rows)
 
-	rows = mock.NewRows([]string{"id"})
-	rows.AddRow(10000)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT p.id FROM places p, bookings b WHERE p.id = b.place_id AND b.id = $1`)).
-		WithArgs(placeID).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(b.booking_price, p.booking_price, 0) FROM places p, bookings b WHERE p.id = b.place_id AND b.id = $1`)).
+		WithArgs(bookingID).
 		WillReturnError(ErrInternalServerError)
 
 	// Test
@@ -1927,3 +2448,382 @@ func TestRepo_GetItemByBookingIDPlaceIDError(t *testing.T) {
 	assert.Nil(t, listItemResult)
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 }
+
+func TestRepo_GetCheckInInformation(t *testing.T) {
+	query := `SELECT b.id, p.user_id, u.name, b.date, b.start_time, b.end_time, b.capacity, b.status, b.arrived_at
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			INNER JOIN users u ON b.user_id = u.id
+			WHERE b.id = $1`
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		date := time.Date(2022, 4, 2, 0, 0, 0, 0, time.UTC)
+		rows := mock.NewRows([]string{"id", "user_id", "name", "date", "start_time", "end_time", "capacity", "status", "arrived_at"}).
+			AddRow(1, 2, "Tom", date, "10:00:00", "12:00:00", 4, util.BookingBerhasil, nil)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
+
+		information, err := repoMock.GetCheckInInformation(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &CheckInInformation{
+			ID:           1,
+			OwnerID:      2,
+			CustomerName: "Tom",
+			Date:         date,
+			StartTime:    "10:00:00",
+			EndTime:      "12:00:00",
+			Capacity:     4,
+			Status:       util.BookingBerhasil,
+		}, information)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetCheckInInformation(1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetCheckInInformation(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_CheckInBooking(t *testing.T) {
+	query := "UPDATE bookings SET arrived_at = NOW() WHERE id = $1 AND status = $2 AND arrived_at IS NULL RETURNING arrived_at"
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		arrivedAt := time.Date(2022, 4, 2, 9, 45, 0, 0, time.UTC)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingBerhasil).WillReturnRows(mock.NewRows([]string{"arrived_at"}).AddRow(arrivedAt))
+
+		result, err := repoMock.CheckInBooking(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &arrivedAt, result)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed already checked in or no-show", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingBerhasil).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.CheckInBooking(1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingBerhasil).WillReturnError(sql.ErrConnDone)
+
+		_, err := repoMock.CheckInBooking(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_CompleteBooking(t *testing.T) {
+	query := "UPDATE bookings SET status = $2 WHERE id = $1 AND status = $3 AND arrived_at IS NOT NULL RETURNING place_id"
+	statsQuery := "INSERT INTO place_stats (place_id, booking_count) VALUES ($1, 1)"
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingSelesai, util.BookingBerhasil).WillReturnRows(mock.NewRows([]string{"place_id"}).AddRow(3))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.CompleteBooking(1))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed already completed or not checked in", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingSelesai, util.BookingBerhasil).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repoMock.CompleteBooking(1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingSelesai, util.BookingBerhasil).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err := repoMock.CompleteBooking(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed update place stats", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.BookingSelesai, util.BookingBerhasil).WillReturnRows(mock.NewRows([]string{"place_id"}).AddRow(3))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err := repoMock.CompleteBooking(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_MarkNoShow(t *testing.T) {
+	query := "UPDATE bookings SET status = $2 WHERE id = $1 AND status = $3 AND arrived_at IS NULL"
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, util.BookingTidakHadir, util.BookingBerhasil).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.MarkNoShow(1)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed status is changed", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, util.BookingTidakHadir, util.BookingBerhasil).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repoMock.MarkNoShow(1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, util.BookingTidakHadir, util.BookingBerhasil).WillReturnError(sql.ErrTxDone)
+
+		err := repoMock.MarkNoShow(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetNoShowPolicy(t *testing.T) {
+	query := "SELECT no_show_policy, no_show_threshold FROM places WHERE user_id = $1"
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"no_show_policy", "no_show_threshold"}).AddRow(util.NoShowPolicyBlock, 3)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnRows(rows)
+
+		policy, err := repoMock.GetNoShowPolicy(2)
+		assert.Nil(t, err)
+		assert.Equal(t, &NoShowPolicy{Policy: util.NoShowPolicyBlock, Threshold: 3}, policy)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetNoShowPolicy(2)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetNoShowPolicy(2)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdateNoShowPolicy(t *testing.T) {
+	query := "UPDATE places SET no_show_policy = $1, no_show_threshold = $2 WHERE user_id = $3"
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.NoShowPolicyPrepayment, 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.UpdateNoShowPolicy(1, NoShowPolicy{Policy: util.NoShowPolicyPrepayment, Threshold: 2})
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.NoShowPolicyPrepayment, 2, 1).WillReturnError(sql.ErrTxDone)
+
+		err := repoMock.UpdateNoShowPolicy(1, NoShowPolicy{Policy: util.NoShowPolicyPrepayment, Threshold: 2})
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetCustomerNoShowPolicy(t *testing.T) {
+	query := `SELECT p.no_show_policy, p.no_show_threshold,
+				(SELECT COUNT(id) FROM bookings WHERE user_id = $2 AND status = $3) AS no_show_count
+			FROM places p WHERE p.id = $1`
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"no_show_policy", "no_show_threshold", "no_show_count"}).AddRow(util.NoShowPolicyBlock, 3, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2, util.BookingTidakHadir).WillReturnRows(rows)
+
+		policy, err := repoMock.GetCustomerNoShowPolicy(1, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, &CustomerNoShowPolicy{Policy: util.NoShowPolicyBlock, Threshold: 3, NoShowCount: 1}, policy)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2, util.BookingTidakHadir).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetCustomerNoShowPolicy(1, 2)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetBookingOwnership(t *testing.T) {
+	query := `SELECT b.id, b.user_id AS customer_id, p.user_id AS owner_id, b.status, b.arrived_at
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			WHERE b.id = $1`
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		return NewRepo(sqlxDB), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"id", "customer_id", "owner_id", "status", "arrived_at"}).AddRow(1, 2, 3, util.BookingBerhasil, nil)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
+
+		ownership, err := repoMock.GetBookingOwnership(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &Ownership{BookingID: 1, CustomerID: 2, OwnerID: 3, Status: util.BookingBerhasil}, ownership)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		ownership, err := repoMock.GetBookingOwnership(1)
+		assert.Nil(t, ownership)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		ownership, err := repoMock.GetBookingOwnership(1)
+		assert.Nil(t, ownership)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -1,13 +1,18 @@
 package booking
 
 import (
+	"encoding/base64"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/skip2/go-qrcode"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/pricing"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
@@ -19,24 +24,39 @@ type Service interface {
 	GetAvailableDate(params GetAvailableDateParams) (*[]AvailableDateResponse, error)
 	CreateBooking(params CreateBookingServiceRequest) (*CreateBookingServiceResponse, error)
 	GetTimeSlots(placeID int, selectedDate time.Time) (*[]TimeSlot, error)
-	GetDetail(bookingID int) (*Detail, error)
-	UpdateBookingStatus(bookingID int, newStatus int) error
+	GetDetail(userID int, bookingID int) (*Detail, error)
+	UpdateBookingStatus(userID int, bookingID int, newStatus int) error
 	GetMyBookingsOngoing(localID string) (*[]Booking, error)
 	GetMyBookingsPreviousWithPagination(localID string, params BookingsListRequest) (*List, *util.Pagination, error)
 	XenditInvoicesCallback(callback XenditInvoicesCallback) error
-	GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error)
+	GetDetailBookingSaya(userID int, bookingID int) (*DetailBookingSaya, error)
+	CreateRemainingInvoice(userID int, bookingID int) (*Invoice, error)
+	GetPendingInvoices() (*[]Invoice, error)
+	GetTicket(userID int, bookingID int) (*Ticket, error)
+	GetTicketPDF(userID int, bookingID int) (*TicketFile, error)
+	CheckIn(userID int, token string) (*CheckInResponse, error)
+	CheckInByBookingID(userID int, bookingID int) (*CheckInResponse, error)
+	MarkNoShow(userID int, bookingID int) error
+	GetNoShowPolicy(userID int) (*NoShowPolicy, error)
+	UpdateNoShowPolicy(userID int, policy NoShowPolicy) error
 }
 
 type service struct {
-	repo   Repo
-	xendit xendit.Service
+	repo         Repo
+	xendit       xendit.Service
+	pricing      pricing.Service
+	notification notification.Notifier
+	ticketSecret string
 }
 
 // NewService for initialize service
-func NewService(repo Repo, xendit xendit.Service) Service {
+func NewService(repo Repo, xendit xendit.Service, pricing pricing.Service, notification notification.Notifier, ticketSecret string) Service {
 	return &service{
-		repo:   repo,
-		xendit: xendit,
+		repo:         repo,
+		xendit:       xendit,
+		pricing:      pricing,
+		notification: notification,
+		ticketSecret: ticketSecret,
 	}
 }
 
@@ -105,10 +125,37 @@ func (s service) CreateBooking(params CreateBookingServiceRequest) (*CreateBooki
 		errorList = append(errorList, "count should be positive integer")
 	}
 
+	switch params.PaymentType {
+	case util.PaymentTypeFull, util.PaymentTypeDeposit:
+		params.SplitCount = 1
+	case util.PaymentTypeSplit:
+		if params.SplitCount < 2 || params.SplitCount > params.Count {
+			errorList = append(errorList, "split_count should be between 2 and count")
+		}
+	default:
+		errorList = append(errorList, "payment_type should be 0 - 2")
+	}
+
 	if len(errorList) > 0 {
 		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
 	}
 
+	noShowPolicy, err := s.repo.GetCustomerNoShowPolicy(params.PlaceID, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if noShowPolicy.Threshold > 0 && noShowPolicy.NoShowCount >= noShowPolicy.Threshold {
+		switch noShowPolicy.Policy {
+		case util.NoShowPolicyBlock:
+			return nil, errors.Wrap(ErrForbidden, fmt.Sprintf("booking is not allowed after %d no-shows", noShowPolicy.NoShowCount))
+		case util.NoShowPolicyPrepayment:
+			if params.PaymentType != util.PaymentTypeFull {
+				return nil, errors.Wrap(ErrInputValidationError, fmt.Sprintf("full payment is required after %d no-shows", noShowPolicy.NoShowCount))
+			}
+		}
+	}
+
 	var items []CheckedItemParams
 	for _, item := range params.Items {
 		items = append(items, CheckedItemParams{
@@ -152,9 +199,11 @@ func (s service) CreateBooking(params CreateBookingServiceRequest) (*CreateBooki
 	}
 
 	isExist := false
+	var bookingPrice float64
 	for _, i := range *availableTime {
 		if i.Time == params.EndTime.Format(util.TimeLayout) {
 			isExist = true
+			bookingPrice = i.Price
 		}
 	}
 
@@ -164,14 +213,17 @@ func (s service) CreateBooking(params CreateBookingServiceRequest) (*CreateBooki
 
 	// Create booking
 	bookingParams := CreateBookingParams{
-		UserID:     params.UserID,
-		PlaceID:    params.PlaceID,
-		Date:       params.Date,
-		StartTime:  params.StartTime,
-		EndTime:    params.EndTime,
-		Capacity:   params.Count,
-		Status:     util.BookingMenungguKonfirmasi,
-		TotalPrice: 0,
+		UserID:       params.UserID,
+		PlaceID:      params.PlaceID,
+		Date:         params.Date,
+		StartTime:    params.StartTime,
+		EndTime:      params.EndTime,
+		Capacity:     params.Count,
+		Status:       util.BookingMenungguKonfirmasi,
+		TotalPrice:   0,
+		BookingPrice: bookingPrice,
+		PaymentType:  params.PaymentType,
+		SplitCount:   params.SplitCount,
 	}
 
 	// create booking instance
@@ -209,6 +261,8 @@ func (s service) CreateBooking(params CreateBookingServiceRequest) (*CreateBooki
 		}
 	}
 
+	s.notification.Notify(notification.Event{Type: util.NotificationBookingCreated, BookingID: bookingID.ID})
+
 	return &CreateBookingServiceResponse{BookingID: bookingID.ID}, nil
 }
 
@@ -347,6 +401,25 @@ func (s service) GetAvailableTime(params GetAvailableTimeParams) (*[]AvailableTi
 	availableTime := s.checkAvailableSchedule(dividedBooking, params.StartTime, place.Capacity, params.BookedSlot, *timeSlot, false)
 
 	availableTimesFormatted := s.formatAvailableTimeData(availableTime, params.SelectedDate)
+	if len(availableTimesFormatted) == 0 {
+		return &availableTimesFormatted, nil
+	}
+
+	ruleSet, err := s.pricing.GetRuleSet(params.PlaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range availableTimesFormatted {
+		endTime, _ := time.Parse(util.TimeLayout, availableTimesFormatted[i].Time)
+		quote := ruleSet.Calculate(pricing.QuoteParams{
+			Date:      params.SelectedDate,
+			StartTime: params.StartTime,
+			EndTime:   endTime,
+			Count:     params.BookedSlot,
+		})
+		availableTimesFormatted[i].Price = quote.Total
+	}
 
 	return &availableTimesFormatted, nil
 }
@@ -492,7 +565,16 @@ func (s service) difference(s1 []CheckedItemParams, s2 []CheckedItemParams) []Ch
 	return diff
 }
 
-func (s *service) GetDetail(bookingID int) (*Detail, error) {
+// GetDetail return the booking detail for the business admin who own the place of the booking
+func (s *service) GetDetail(userID int, bookingID int) (*Detail, error) {
+	if _, err := s.authorizeBooking(util.StatusBusinessAdmin, userID, bookingID); err != nil {
+		return nil, err
+	}
+
+	return s.getDetail(bookingID)
+}
+
+func (s *service) getDetail(bookingID int) (*Detail, error) {
 	errorList := []string{}
 
 	if bookingID <= 0 {
@@ -519,6 +601,22 @@ func (s *service) GetDetail(bookingID int) (*Detail, error) {
 	}
 
 	totalPriceTicket := ticketPriceWrapper.Price
+	if !ticketPriceWrapper.Fixed {
+		// booking made before the price is stored, calculate it with current rules
+		ruleSet, err := s.pricing.GetRuleSet(bookingDetail.PlaceID)
+		if err != nil {
+			return nil, err
+		}
+
+		quote := ruleSet.Calculate(pricing.QuoteParams{
+			Date:      bookingDetail.Date,
+			StartTime: bookingDetail.StartTime,
+			EndTime:   bookingDetail.EndTime,
+			Count:     bookingDetail.Capacity,
+		})
+		totalPriceTicket = quote.Total
+	}
+
 	totalPrice := totalPriceTicket + bookingDetail.TotalPriceItem
 
 	bookingDetail.TotalPriceTicket = totalPriceTicket
@@ -532,7 +630,25 @@ func (s *service) GetDetail(bookingID int) (*Detail, error) {
 	return bookingDetail, nil
 }
 
-func (s *service) UpdateBookingStatus(bookingID int, newStatus int) error {
+// bookingStatusTransitions is the status a business admin can change a booking to from its current status,
+// payment is only settled by the invoice callback and no-show is only marked through MarkNoShow
+var bookingStatusTransitions = map[int][]int{
+	util.BookingMenungguKonfirmasi: {util.BookingBelumMembayar, util.BookingGagal},
+	util.BookingBelumMembayar:      {util.BookingGagal},
+	util.BookingBerhasil:           {util.BookingSelesai},
+}
+
+func canChangeBookingStatus(currentStatus int, newStatus int) bool {
+	for _, status := range bookingStatusTransitions[currentStatus] {
+		if status == newStatus {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *service) UpdateBookingStatus(userID int, bookingID int, newStatus int) error {
 	errorList := []string{}
 
 	if bookingID <= 0 {
@@ -548,22 +664,26 @@ func (s *service) UpdateBookingStatus(bookingID int, newStatus int) error {
 		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
 	}
 
+	ownership, err := s.authorizeBooking(util.StatusBusinessAdmin, userID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	if !canChangeBookingStatus(ownership.Status, newStatus) {
+		return errors.Wrap(ErrInputValidationError, fmt.Sprintf("booking status can not be changed from %d to %d", ownership.Status, newStatus))
+	}
+
+	if newStatus == util.BookingSelesai && ownership.ArrivedAt == nil {
+		return errors.Wrap(ErrInputValidationError, "booking must be checked in before it is completed")
+	}
+
 	switch newStatus {
 	case util.BookingBelumMembayar:
-		bookingInformation, err := s.GetDetail(bookingID)
+		bookingInformation, err := s.getDetail(bookingID)
 		if err != nil {
 			return err
 		}
 
-		var xenditItems []xendit.Item
-		for _, i := range bookingInformation.Items {
-			xenditItems = append(xenditItems, xendit.Item{
-				Name:  i.Name,
-				Price: i.Price,
-				Qty:   i.Qty,
-			})
-		}
-
 		// Check Invoices Information
 		isExist, err := s.repo.GetInvoicesFromBooking(bookingID)
 		if err != nil {
@@ -571,45 +691,34 @@ func (s *service) UpdateBookingStatus(bookingID int, newStatus int) error {
 		}
 
 		if !isExist {
-			// Create xendit invoices
-			invoiceParams := xendit.CreateInvoiceParams{
-				PlaceID:             bookingInformation.PlaceID,
-				Items:               xenditItems,
-				Description:         fmt.Sprintf("Order from %s", bookingInformation.CustomerName),
-				CustomerName:        bookingInformation.CustomerName,
-				CustomerPhoneNumber: bookingInformation.CustomerPhoneNumber,
-				BookingFee:          bookingInformation.TotalPriceTicket,
-			}
-
-			invoice, err := s.xendit.CreateInvoice(invoiceParams)
-			if err != nil {
-				return err
-			}
-
-			xenditInformationParams := XenditInformation{
-				XenditID:    invoice.ID,
-				InvoicesURL: invoice.InvoiceURL,
-				BookingID:   bookingID,
-			}
-			_, err = s.repo.InsertXenditInformation(xenditInformationParams)
-			if err != nil {
-				return err
-			}
-
-			loc, _ := time.LoadLocation("Asia/Bangkok")
-
-			xenditExpiredDateInLocalTime := invoice.ExpiryDate.In(loc)
-			err = s.repo.AddExpiredPayment(bookingID, xenditExpiredDateInLocalTime)
+			err = s.createInvoices(bookingInformation)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	err := s.repo.UpdateBookingStatus(bookingID, newStatus)
+	var eventType string
+	switch newStatus {
+	case util.BookingBelumMembayar:
+		eventType = util.NotificationBookingConfirmed
+	case util.BookingGagal:
+		eventType = util.NotificationBookingCancelled
+	}
+
+	if newStatus == util.BookingSelesai {
+		err = s.repo.CompleteBooking(bookingID)
+	} else {
+		err = s.repo.UpdateBookingStatus(bookingID, newStatus, eventType)
+	}
 	if err != nil {
 		return err
 	}
+
+	if eventType != "" {
+		s.notification.Notify(notification.Event{Type: eventType, BookingID: bookingID})
+	}
+
 	return nil
 }
 
@@ -621,12 +730,12 @@ func (s *service) XenditInvoicesCallback(callback XenditInvoicesCallback) error
 		errorList = append(errorList, "external id is not valid")
 	}
 
-	var status int
+	var invoiceStatus int
 	switch callback.Status {
 	case util.XenditStatusPaid:
-		status = util.BookingBerhasil
+		invoiceStatus = util.InvoicePaid
 	case util.XenditStatusExpired:
-		status = util.BookingGagal
+		invoiceStatus = util.InvoiceExpired
 	default:
 		errorList = append(errorList, fmt.Sprintf("callback status %s is unknown", callback.Status))
 	}
@@ -635,16 +744,68 @@ func (s *service) XenditInvoicesCallback(callback XenditInvoicesCallback) error
 		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
 	}
 
-	err = s.repo.UpdateBookingStatusByXenditID(callback.ID, status)
+	invoice, err := s.repo.GetInvoiceByXenditID(callback.ID)
+	if err != nil {
+		return err
+	}
+
+	// xendit may send the same callback more than once, only the first one settle the invoice
+	if invoice.Status != util.InvoicePending {
+		return nil
+	}
+
+	var credit float64
+	if invoiceStatus == util.InvoicePaid {
+		credit = callback.Amount - invoice.PlatformFee
+	}
+
+	settled, err := s.repo.SettleInvoice(invoice.ID, invoiceStatus, credit, placeID)
 	if err != nil {
 		return err
 	}
 
-	if callback.Status == util.XenditStatusPaid {
-		err = s.repo.IncrementBusinessAdminBalance(callback.Amount-util.XenditPlatformFee, placeID)
+	if !settled {
+		return nil
+	}
+
+	// remaining invoice can be requested again when expired, the booking is already secured by deposit
+	if invoice.Type == util.InvoiceTypeRemaining {
+		return nil
+	}
+
+	unpaid, err := s.repo.CountUnpaidRequiredInvoices(invoice.BookingID)
+	if err != nil {
+		return err
+	}
+
+	if unpaid == 0 {
+		err = s.repo.UpdateBookingStatus(invoice.BookingID, util.BookingBerhasil, util.NotificationBookingPaid)
 		if err != nil {
 			return err
 		}
+
+		s.notification.Notify(notification.Event{Type: util.NotificationBookingPaid, BookingID: invoice.BookingID})
+		return nil
+	}
+
+	// split booking is only failed once no other participant can still pay
+	pending, err := s.repo.CountPendingRequiredInvoices(invoice.BookingID)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		return nil
+	}
+
+	// participants who already paid are refunded, their credit is taken back from the owner
+	failed, err := s.repo.FailUnpaidBooking(invoice.BookingID, placeID)
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		s.notification.Notify(notification.Event{Type: util.NotificationBookingExpired, BookingID: invoice.BookingID})
 	}
 
 	return nil
@@ -733,18 +894,29 @@ func (s service) updateStatusWhenChecking(ID int, date, startTime string, status
 
 		dateTimeBooking := timeBooking.AddDate(dateBooking.Year(), int(dateBooking.Month())-1, dateBooking.Day()-1)
 		if dateTimeBooking.Before(time.Now()) {
-			err := s.UpdateBookingStatus(ID, util.BookingGagal)
+			err := s.repo.UpdateBookingStatus(ID, util.BookingGagal, util.NotificationBookingExpired)
 			if err != nil {
 				return false, err
 			}
 
+			s.notification.Notify(notification.Event{Type: util.NotificationBookingExpired, BookingID: ID})
+
 			return true, nil
 		}
 	}
 	return false, nil
 }
 
-func (s service) GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error) {
+// GetDetailBookingSaya return the booking detail for the customer who made the booking
+func (s service) GetDetailBookingSaya(userID int, bookingID int) (*DetailBookingSaya, error) {
+	if _, err := s.authorizeBooking(util.StatusCustomer, userID, bookingID); err != nil {
+		return nil, err
+	}
+
+	return s.getDetailBookingSaya(bookingID)
+}
+
+func (s service) getDetailBookingSaya(bookingID int) (*DetailBookingSaya, error) {
 	if bookingID <= 0 {
 		return nil, errors.Wrap(ErrInputValidationError, "Booking ID should be positive")
 	}
@@ -760,5 +932,474 @@ func (s service) GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error)
 	}
 
 	detailBookingSaya.Items = *items
+
+	invoices, err := s.repo.GetInvoicesByBookingID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	detailBookingSaya.Invoices = *invoices
 	return detailBookingSaya, nil
 }
+
+func (s service) CreateRemainingInvoice(userID int, bookingID int) (*Invoice, error) {
+	if _, err := s.authorizeBooking(util.StatusCustomer, userID, bookingID); err != nil {
+		return nil, err
+	}
+
+	bookingInformation, err := s.getDetail(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bookingInformation.PaymentType != util.PaymentTypeDeposit || bookingInformation.Status != util.BookingBerhasil {
+		return nil, errors.Wrap(ErrInputValidationError, "booking does not have remaining payment")
+	}
+
+	invoices, err := s.repo.GetInvoicesByBookingID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	paidAmount := 0.0
+	for _, invoice := range *invoices {
+		if invoice.Type == util.InvoiceTypeRemaining && invoice.Status != util.InvoiceExpired {
+			return nil, errors.Wrap(ErrInputValidationError, "remaining invoice already exists")
+		}
+
+		if invoice.Status == util.InvoicePaid {
+			paidAmount += invoice.Amount - invoice.PlatformFee
+		}
+	}
+
+	remainingAmount := bookingInformation.TotalPrice - paidAmount
+	if remainingAmount <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "booking does not have remaining payment")
+	}
+
+	invoice, err := s.createInvoice(bookingInformation, invoicePlan{
+		Type:        util.InvoiceTypeRemaining,
+		Name:        "Remaining payment",
+		Amount:      remainingAmount,
+		PlatformFee: 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (s service) GetPendingInvoices() (*[]Invoice, error) {
+	return s.repo.GetPendingInvoices()
+}
+
+// invoicePlan describe a single invoice that should be created for a booking
+type invoicePlan struct {
+	Type        int
+	Name        string
+	Amount      float64
+	PlatformFee float64
+}
+
+func (s service) planInvoices(bookingInformation *Detail) ([]invoicePlan, error) {
+	switch bookingInformation.PaymentType {
+	case util.PaymentTypeDeposit:
+		ruleSet, err := s.pricing.GetRuleSet(bookingInformation.PlaceID)
+		if err != nil {
+			return nil, err
+		}
+
+		percentage := ruleSet.DepositPercentage
+		if percentage <= 0 || percentage >= util.MaximumDepositPercentage {
+			break
+		}
+
+		return []invoicePlan{
+			{
+				Type:        util.InvoiceTypeDeposit,
+				Name:        fmt.Sprintf("Deposit %d%%", percentage),
+				Amount:      math.Round(bookingInformation.TotalPrice * float64(percentage) / 100),
+				PlatformFee: util.XenditPlatformFee,
+			},
+		}, nil
+	case util.PaymentTypeSplit:
+		if bookingInformation.SplitCount < 2 {
+			break
+		}
+
+		// first participant also pay the rounding rest and the platform fee
+		share := math.Floor(bookingInformation.TotalPrice / float64(bookingInformation.SplitCount))
+		plans := make([]invoicePlan, 0, bookingInformation.SplitCount)
+		for i := 0; i < bookingInformation.SplitCount; i++ {
+			plan := invoicePlan{
+				Type:   util.InvoiceTypeSplit,
+				Name:   fmt.Sprintf("Split payment %d/%d", i+1, bookingInformation.SplitCount),
+				Amount: share,
+			}
+
+			if i == 0 {
+				plan.Amount = bookingInformation.TotalPrice - share*float64(bookingInformation.SplitCount-1)
+				plan.PlatformFee = util.XenditPlatformFee
+			}
+
+			plans = append(plans, plan)
+		}
+
+		return plans, nil
+	}
+
+	return []invoicePlan{
+		{
+			Type:        util.InvoiceTypeFull,
+			Amount:      bookingInformation.TotalPrice,
+			PlatformFee: util.XenditPlatformFee,
+		},
+	}, nil
+}
+
+func (s service) createInvoices(bookingInformation *Detail) error {
+	plans, err := s.planInvoices(bookingInformation)
+	if err != nil {
+		return err
+	}
+
+	for index, plan := range plans {
+		invoice, err := s.createInvoice(bookingInformation, plan)
+		if err != nil {
+			return err
+		}
+
+		if index > 0 {
+			continue
+		}
+
+		// first invoice is shown as the booking payment link
+		xenditInformationParams := XenditInformation{
+			XenditID:    invoice.XenditID,
+			InvoicesURL: invoice.InvoiceURL,
+			BookingID:   bookingInformation.ID,
+		}
+		_, err = s.repo.InsertXenditInformation(xenditInformationParams)
+		if err != nil {
+			return err
+		}
+
+		err = s.repo.AddExpiredPayment(bookingInformation.ID, invoice.ExpiredAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s service) createInvoice(bookingInformation *Detail, plan invoicePlan) (*Invoice, error) {
+	invoiceParams := xendit.CreateInvoiceParams{
+		PlaceID:             bookingInformation.PlaceID,
+		Description:         fmt.Sprintf("Order from %s", bookingInformation.CustomerName),
+		CustomerName:        bookingInformation.CustomerName,
+		CustomerPhoneNumber: bookingInformation.CustomerPhoneNumber,
+		WithoutPlatformFee:  plan.PlatformFee == 0,
+	}
+
+	if plan.Type == util.InvoiceTypeFull {
+		for _, i := range bookingInformation.Items {
+			invoiceParams.Items = append(invoiceParams.Items, xendit.Item{
+				Name:  i.Name,
+				Price: i.Price,
+				Qty:   i.Qty,
+			})
+		}
+		invoiceParams.BookingFee = bookingInformation.TotalPriceTicket
+	} else {
+		invoiceParams.Description = fmt.Sprintf("%s for order from %s", plan.Name, bookingInformation.CustomerName)
+		invoiceParams.Items = []xendit.Item{
+			{
+				Name:  plan.Name,
+				Price: plan.Amount,
+				Qty:   1,
+			},
+		}
+	}
+
+	xenditInvoice, err := s.xendit.CreateInvoice(invoiceParams)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+
+	invoice := Invoice{
+		BookingID:   bookingInformation.ID,
+		XenditID:    xenditInvoice.ID,
+		InvoiceURL:  xenditInvoice.InvoiceURL,
+		Type:        plan.Type,
+		Amount:      plan.Amount + plan.PlatformFee,
+		PlatformFee: plan.PlatformFee,
+		Status:      util.InvoicePending,
+		ExpiredAt:   xenditInvoice.ExpiryDate.In(loc),
+	}
+
+	err = s.repo.InsertInvoice(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+func (s service) GetTicket(userID int, bookingID int) (*Ticket, error) {
+	if _, err := s.authorizeBooking(util.StatusCustomer, userID, bookingID); err != nil {
+		return nil, err
+	}
+
+	detailBookingSaya, err := s.repo.GetDetailBookingSaya(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if detailBookingSaya.Status != util.BookingBerhasil {
+		return nil, errors.Wrap(ErrInputValidationError, "ticket is only available for paid booking")
+	}
+
+	token := signTicket(s.ticketSecret, bookingID)
+	qrCode, err := qrcode.Encode(token, qrcode.Medium, util.TicketQRCodeSize)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &Ticket{
+		BookingID: bookingID,
+		Token:     token,
+		QRCode:    "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode),
+	}, nil
+}
+
+func (s service) GetTicketPDF(userID int, bookingID int) (*TicketFile, error) {
+	detailBookingSaya, err := s.GetDetailBookingSaya(userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if detailBookingSaya.Status != util.BookingBerhasil {
+		return nil, errors.Wrap(ErrInputValidationError, "ticket is only available for paid booking")
+	}
+
+	qrCode, err := qrcode.Encode(signTicket(s.ticketSecret, bookingID), qrcode.Medium, util.TicketQRCodeSize)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	content, err := encodeTicketPDF(*detailBookingSaya, qrCode)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &TicketFile{
+		FileName:    fmt.Sprintf("e-ticket-%d.pdf", bookingID),
+		ContentType: util.ApplicationPDF,
+		Content:     content,
+	}, nil
+}
+
+func (s service) CheckIn(userID int, token string) (*CheckInResponse, error) {
+	if userID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "userID must be above 0")
+	}
+
+	bookingID, err := parseTicket(s.ticketSecret, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CheckInByBookingID(userID, bookingID)
+}
+
+func (s service) CheckInByBookingID(userID int, bookingID int) (*CheckInResponse, error) {
+	information, startTime, endTime, err := s.getOwnedBooking(userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var errorList []string
+
+	if information.ArrivedAt != nil {
+		errorList = append(errorList, "booking is already checked in")
+	}
+
+	switch information.Status {
+	case util.BookingBerhasil:
+	case util.BookingTidakHadir:
+		errorList = append(errorList, "booking is already marked as no-show")
+	default:
+		errorList = append(errorList, "booking is not paid")
+	}
+
+	now := time.Now().In(startTime.Location())
+	date := information.Date.Format(util.DateLayout)
+
+	if date != now.Format(util.DateLayout) {
+		errorList = append(errorList, fmt.Sprintf("booking is for %s", date))
+	} else if now.Before(startTime.Add(-util.CheckInEarlyMinutes*time.Minute)) || now.After(*endTime) {
+		errorList = append(errorList, fmt.Sprintf("check-in is only available from %d minutes before %s until %s", util.CheckInEarlyMinutes, information.StartTime, information.EndTime))
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	arrivedAt, err := s.repo.CheckInBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckInResponse{
+		BookingID:    bookingID,
+		CustomerName: information.CustomerName,
+		Date:         date,
+		StartTime:    information.StartTime,
+		EndTime:      information.EndTime,
+		Capacity:     information.Capacity,
+		ArrivedAt:    *arrivedAt,
+	}, nil
+}
+
+func (s service) MarkNoShow(userID int, bookingID int) error {
+	information, startTime, _, err := s.getOwnedBooking(userID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	var errorList []string
+
+	if information.ArrivedAt != nil {
+		errorList = append(errorList, "booking is already checked in")
+	}
+
+	if information.Status != util.BookingBerhasil {
+		errorList = append(errorList, "only paid booking can be marked as no-show")
+	}
+
+	if time.Now().Before(startTime.Add(util.NoShowGracePeriodMinutes * time.Minute)) {
+		errorList = append(errorList, fmt.Sprintf("booking can be marked as no-show %d minutes after %s", util.NoShowGracePeriodMinutes, information.StartTime))
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	return s.repo.MarkNoShow(bookingID)
+}
+
+// authorizeBooking make sure the booking is made by the customer, or is for the place of the business admin
+func (s service) authorizeBooking(status int, userID int, bookingID int) (*Ownership, error) {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	if bookingID <= 0 {
+		errorList = append(errorList, "bookingID must be above 0")
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	ownership, err := s.repo.GetBookingOwnership(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case util.StatusCustomer:
+		if ownership.CustomerID != userID {
+			return nil, errors.Wrap(ErrForbidden, "booking is not yours")
+		}
+	case util.StatusBusinessAdmin:
+		if ownership.OwnerID != userID {
+			return nil, errors.Wrap(ErrForbidden, "booking is not for your place")
+		}
+	default:
+		return nil, errors.Wrap(ErrForbidden, "unknown user status")
+	}
+
+	return ownership, nil
+}
+
+// getOwnedBooking return check-in information of booking in the business admin place with its start and end time
+func (s service) getOwnedBooking(userID int, bookingID int) (*CheckInInformation, *time.Time, *time.Time, error) {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	if bookingID <= 0 {
+		errorList = append(errorList, "bookingID must be above 0")
+	}
+
+	if len(errorList) > 0 {
+		return nil, nil, nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	information, err := s.repo.GetCheckInInformation(bookingID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if information.OwnerID != userID {
+		return nil, nil, nil, errors.Wrap(ErrForbidden, "booking is not for your place")
+	}
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	date := information.Date.Format(util.DateLayout)
+
+	startTime, err := time.ParseInLocation(util.DateLayout+" "+util.TimeLayout, date+" "+information.StartTime, loc)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	endTime, err := time.ParseInLocation(util.DateLayout+" "+util.TimeLayout, date+" "+information.EndTime, loc)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return information, &startTime, &endTime, nil
+}
+
+func (s service) GetNoShowPolicy(userID int) (*NoShowPolicy, error) {
+	if userID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "userID must be above 0")
+	}
+
+	return s.repo.GetNoShowPolicy(userID)
+}
+
+func (s service) UpdateNoShowPolicy(userID int, policy NoShowPolicy) error {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	if policy.Policy != util.NoShowPolicyNone && policy.Policy != util.NoShowPolicyBlock && policy.Policy != util.NoShowPolicyPrepayment {
+		errorList = append(errorList, "policy should be 0 - 2")
+	}
+
+	if policy.Threshold < 0 {
+		errorList = append(errorList, "threshold must not be negative")
+	}
+
+	if policy.Policy != util.NoShowPolicyNone && policy.Threshold == 0 {
+		errorList = append(errorList, "threshold is required for the policy")
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	return s.repo.UpdateNoShowPolicy(userID, policy)
+}
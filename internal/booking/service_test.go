@@ -8,7 +8,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/tkuchiki/faketime"
 	xendit2 "github.com/xendit/xendit-go"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/pricing"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
@@ -87,11 +90,87 @@ func (m *MockRepository) UpdateTotalPrice(params UpdateTotalPriceParams) (bool,
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRepository) UpdateBookingStatusByXenditID(xenditID string, status int) error {
-	args := m.Called(xenditID, status)
+func (m *MockRepository) InsertInvoice(invoice Invoice) error {
+	args := m.Called(invoice)
 	return args.Error(0)
 }
 
+func (m *MockRepository) GetInvoiceByXenditID(xenditID string) (*Invoice, error) {
+	args := m.Called(xenditID)
+	return args.Get(0).(*Invoice), args.Error(1)
+}
+
+func (m *MockRepository) GetInvoicesByBookingID(bookingID int) (*[]Invoice, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*[]Invoice), args.Error(1)
+}
+
+func (m *MockRepository) SettleInvoice(ID int, status int, credit float64, placeID int) (bool, error) {
+	args := m.Called(ID, status, credit, placeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CountUnpaidRequiredInvoices(bookingID int) (int, error) {
+	args := m.Called(bookingID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) CountPendingRequiredInvoices(bookingID int) (int, error) {
+	args := m.Called(bookingID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) FailUnpaidBooking(bookingID int, placeID int) (bool, error) {
+	args := m.Called(bookingID, placeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetPendingInvoices() (*[]Invoice, error) {
+	args := m.Called()
+	invoices := args.Get(0).(*[]Invoice)
+	return invoices, args.Error(1)
+}
+
+func (m *MockRepository) GetCheckInInformation(bookingID int) (*CheckInInformation, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*CheckInInformation), args.Error(1)
+}
+
+func (m *MockRepository) GetBookingOwnership(bookingID int) (*Ownership, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*Ownership), args.Error(1)
+}
+
+func (m *MockRepository) CheckInBooking(bookingID int) (*time.Time, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockRepository) CompleteBooking(bookingID int) error {
+	args := m.Called(bookingID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkNoShow(bookingID int) error {
+	args := m.Called(bookingID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetNoShowPolicy(userID int) (*NoShowPolicy, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*NoShowPolicy), args.Error(1)
+}
+
+func (m *MockRepository) UpdateNoShowPolicy(userID int, policy NoShowPolicy) error {
+	args := m.Called(userID, policy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetCustomerNoShowPolicy(placeID int, userID int) (*CustomerNoShowPolicy, error) {
+	args := m.Called(placeID, userID)
+	return args.Get(0).(*CustomerNoShowPolicy), args.Error(1)
+}
+
 func (m *MockRepository) GetInvoicesFromBooking(ID int) (bool, error) {
 	args := m.Called(ID)
 	return args.Bool(0), args.Error(1)
@@ -102,6 +181,35 @@ func (m *MockRepository) AddExpiredPayment(ID int, expiredAt time.Time) error {
 	return args.Error(0)
 }
 
+type MockPricingService struct {
+	mock.Mock
+}
+
+func (m *MockPricingService) GetRuleSet(placeID int) (*pricing.RuleSet, error) {
+	args := m.Called(placeID)
+	return args.Get(0).(*pricing.RuleSet), args.Error(1)
+}
+
+func (m *MockPricingService) GetRulesByUserID(userID int) (*pricing.RuleSet, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*pricing.RuleSet), args.Error(1)
+}
+
+func (m *MockPricingService) CreateRule(userID int, params pricing.CreateRuleRequest) (*pricing.Rule, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).(*pricing.Rule), args.Error(1)
+}
+
+func (m *MockPricingService) DeleteRule(userID int, ruleID int) error {
+	args := m.Called(userID, ruleID)
+	return args.Error(0)
+}
+
+func (m *MockPricingService) UpdateDepositPercentage(userID int, percentage int) error {
+	args := m.Called(userID, percentage)
+	return args.Error(0)
+}
+
 type MockXenditService struct {
 	mock.Mock
 }
@@ -126,8 +234,28 @@ func (x *MockXenditService) GetDisbursement(ID string) (*xendit2.Disbursement, e
 	return args.Get(0).(*xendit2.Disbursement), args.Error(1)
 }
 
-func (m *MockRepository) UpdateBookingStatus(bookingID int, newStatus int) error {
-	args := m.Called(bookingID, newStatus)
+func (x *MockXenditService) GetDisbursementsByExternalID(externalID string) ([]xendit2.Disbursement, error) {
+	args := x.Called(externalID)
+	return args.Get(0).([]xendit2.Disbursement), args.Error(1)
+}
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+// newMockNotificationService accept every notification, assert on a new MockNotificationService to check the event
+func newMockNotificationService() *MockNotificationService {
+	mockNotification := new(MockNotificationService)
+	mockNotification.On("Notify", mock.Anything).Maybe()
+	return mockNotification
+}
+
+func (m *MockNotificationService) Notify(event notification.Event) {
+	m.Called(event)
+}
+
+func (m *MockRepository) UpdateBookingStatus(bookingID int, newStatus int, eventType string) error {
+	args := m.Called(bookingID, newStatus, eventType)
 	return args.Error(0)
 }
 
@@ -141,11 +269,6 @@ func (m *MockRepository) GetPlaceBookingPrice(placeID int) (float64, error) {
 	return args.Get(0).(float64), args.Error(1)
 }
 
-func (m *MockRepository) IncrementBusinessAdminBalance(balance float64, placeID int) error {
-	args := m.Called(balance, placeID)
-	return args.Error(0)
-}
-
 func (m *MockRepository) GetDetailBookingSaya(bookingID int) (*DetailBookingSaya, error) {
 	args := m.Called(bookingID)
 	ret := args.Get(0).(DetailBookingSaya)
@@ -209,11 +332,11 @@ func TestService_GetListCustomerBookingWithPaginationSuccess(t *testing.T) {
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Expectation
 	mockRepo.On("GetListCustomerBookingWithPagination", params).Return(listCustomerBookingOutput, nil)
-	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal).Return(nil)
+	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingExpired).Return(nil)
 
 	// Test
 	listCustomerBookingResult, _, err := mockService.GetListCustomerBookingWithPagination(params)
@@ -277,7 +400,7 @@ func TestService_GetListCustomerBookingWithPaginationSuccessWithDefaultParam(t *
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	paramsDefault := ListRequest{
 		Limit:  10,
@@ -289,7 +412,7 @@ func TestService_GetListCustomerBookingWithPaginationSuccessWithDefaultParam(t *
 
 	// Expectation
 	mockRepo.On("GetListCustomerBookingWithPagination", paramsDefault).Return(listCustomerBookingReturned, nil)
-	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal).Return(nil)
+	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingExpired).Return(nil)
 
 	// Test
 	listCustomerBookingReturn, _, err := mockService.GetListCustomerBookingWithPagination(params)
@@ -338,7 +461,7 @@ func TestService_GetListCustomerBookingWithPaginationFailedUpdateStatus(t *testi
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	paramsDefault := ListRequest{
 		Limit:  10,
@@ -350,7 +473,7 @@ func TestService_GetListCustomerBookingWithPaginationFailedUpdateStatus(t *testi
 
 	// Expectation
 	mockRepo.On("GetListCustomerBookingWithPagination", paramsDefault).Return(listCustomerBookingReturned, nil)
-	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal).Return(errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingExpired).Return(errors.Wrap(ErrInternalServerError, "test error"))
 
 	// Test
 	listCustomerBookingResult, _, err := mockService.GetListCustomerBookingWithPagination(params)
@@ -372,7 +495,7 @@ func TestService_GetListCustomerBookingWithPaginationFailedLimitExceedMaxLimit(t
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Test
 	listCustomerBookingResult, _, err := mockService.GetListCustomerBookingWithPagination(params)
@@ -395,7 +518,7 @@ func TestService_GetListCustomerBookingWithPaginationError(t *testing.T) {
 	// Mock DB
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetListCustomerBookingWithPagination", params).Return(listCustomerBooking, ErrInternalServerError)
 
@@ -419,7 +542,7 @@ func TestService_GetListCustomerBookingWithPaginationFailedURLIsEmpty(t *testing
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Test
 	listCustomerBookingResult, _, err := mockService.GetListCustomerBookingWithPagination(params)
@@ -444,6 +567,7 @@ func TestService_GetDetailSuccess(t *testing.T) {
 
 	ticketPriceWrapper := TicketPriceWrapper{
 		Price: 10000,
+		Fixed: true,
 	}
 
 	itemsWrapper := ItemsWrapper{
@@ -465,13 +589,14 @@ func TestService_GetDetailSuccess(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetDetail", bookingID).Return(bookingDetail, nil)
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
-	bookingDetailResult, err := mockService.GetDetail(bookingID)
+	bookingDetailResult, err := mockService.GetDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	totalTicketPrice := ticketPriceWrapper.Price
@@ -496,17 +621,80 @@ func TestService_GetDetailSuccess(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestService_GetDetailSuccessCalculateTicketPrice(t *testing.T) {
+	bookingID := 1
+	date, _ := time.Parse(util.DateLayout, "2022-05-07")
+	startTime, _ := time.Parse(util.TimeLayout, "18:00:00")
+	endTime, _ := time.Parse(util.TimeLayout, "20:00:00")
+	bookingDetail := Detail{
+		ID:             1,
+		PlaceID:        1,
+		Date:           date,
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Capacity:       4,
+		TotalPriceItem: 50000.0,
+	}
+
+	ruleSet := pricing.RuleSet{
+		PlaceID:   1,
+		BasePrice: 10000,
+		Rules: []pricing.Rule{
+			{Name: "weekend", Type: util.PricingRuleWeekend, Price: 15000},
+			{Name: "peak hour", Type: util.PricingRulePeakHour, Price: 5000, StartTime: "19:00:00", EndTime: "21:00:00"},
+		},
+	}
+
+	mockRepo := new(MockRepository)
+	mockPricingService := new(MockPricingService)
+	mockService := NewService(mockRepo, new(MockXenditService), mockPricingService, newMockNotificationService(), "secret")
+
+	mockRepo.On("GetDetail", bookingID).Return(bookingDetail, nil)
+	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 10000, Fixed: false}, nil)
+	mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{Items: []ItemDetail{}}, nil)
+	mockPricingService.On("GetRuleSet", 1).Return(&ruleSet, nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	bookingDetailResult, err := mockService.GetDetail(1, bookingID)
+	mockRepo.AssertExpectations(t)
+	mockPricingService.AssertExpectations(t)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20000.0, bookingDetailResult.TotalPriceTicket)
+	assert.Equal(t, 70000.0, bookingDetailResult.TotalPrice)
+}
+
+func TestService_GetDetailFailedGetRuleSet(t *testing.T) {
+	bookingID := 1
+
+	mockRepo := new(MockRepository)
+	mockPricingService := new(MockPricingService)
+	mockService := NewService(mockRepo, new(MockXenditService), mockPricingService, newMockNotificationService(), "secret")
+
+	mockRepo.On("GetDetail", bookingID).Return(Detail{ID: 1, PlaceID: 1}, nil)
+	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 10000, Fixed: false}, nil)
+	mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{Items: []ItemDetail{}}, nil)
+	mockPricingService.On("GetRuleSet", 1).Return(&pricing.RuleSet{}, pricing.ErrInternalServerError)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	bookingDetailResult, err := mockService.GetDetail(1, bookingID)
+
+	assert.Nil(t, bookingDetailResult)
+	assert.Equal(t, pricing.ErrInternalServerError, errors.Cause(err))
+}
+
 func TestService_GetDetailFailedCalledGetDetail(t *testing.T) {
 	bookingID := 1
 	var bookingDetail Detail
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetDetail", bookingID).Return(bookingDetail, ErrInternalServerError)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
-	bookingDetailResult, err := mockService.GetDetail(bookingID)
+	bookingDetailResult, err := mockService.GetDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -520,12 +708,13 @@ func TestService_GetDetailFailedCalledGetTicketPriceWrapper(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetDetail", bookingID).Return(bookingDetail, nil)
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, ErrInternalServerError)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
-	bookingDetailResult, err := mockService.GetDetail(bookingID)
+	bookingDetailResult, err := mockService.GetDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -540,13 +729,14 @@ func TestService_GetDetailFailedCalledGetItemWrapper(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetDetail", bookingID).Return(bookingDetail, nil)
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, ErrInternalServerError)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
-	bookingDetailResult, err := mockService.GetDetail(bookingID)
+	bookingDetailResult, err := mockService.GetDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -560,10 +750,12 @@ func TestService_GetDetailWrongInput(t *testing.T) {
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	bookingDetail, err := mockService.GetDetail(bookingID)
+	bookingDetail, err := mockService.GetDetail(1, bookingID)
 
 	assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 	assert.Nil(t, bookingDetail)
@@ -571,18 +763,87 @@ func TestService_GetDetailWrongInput(t *testing.T) {
 
 func TestService_UpdateBookingStatusSuccess(t *testing.T) {
 	bookingID := 1
-	newStatus := 2
+	newStatus := util.BookingGagal
+
+	// Init mock repo and mock service
+	mockRepo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+	mockRepo.On("UpdateBookingStatus", bookingID, newStatus, util.NotificationBookingCancelled).Return(nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	// Test
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
+
+	assert.Nil(t, err)
+}
+
+func TestService_UpdateBookingStatusCompleted(t *testing.T) {
+	bookingID := 1
+	arrivedAt := time.Now()
 
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
-	mockRepo.On("UpdateBookingStatus", bookingID, newStatus).Return(nil)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+	mockRepo.On("CompleteBooking", bookingID).Return(nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingBerhasil, ArrivedAt: &arrivedAt}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, util.BookingSelesai)
 
 	assert.Nil(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateBookingStatus", bookingID, util.BookingSelesai, mock.Anything)
+}
+
+func TestService_UpdateBookingStatusFailedCalledCompleteBooking(t *testing.T) {
+	bookingID := 1
+	arrivedAt := time.Now()
+
+	// Init mock repo and mock service
+	mockRepo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+	mockRepo.On("CompleteBooking", bookingID).Return(ErrInternalServerError)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingBerhasil, ArrivedAt: &arrivedAt}, nil)
+
+	// Test
+	err := mockService.UpdateBookingStatus(1, bookingID, util.BookingSelesai)
+
+	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+}
+
+func TestService_UpdateBookingStatusNotAllowedTransition(t *testing.T) {
+	arrivedAt := time.Now()
+	tests := []struct {
+		name      string
+		ownership Ownership
+		newStatus int
+		message   string
+	}{
+		{"paid by business admin", Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingBelumMembayar}, util.BookingBerhasil, "booking status can not be changed from 1 to 2"},
+		{"completed before confirmed", Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingMenungguKonfirmasi}, util.BookingSelesai, "booking status can not be changed from 0 to 3"},
+		{"no-show without MarkNoShow", Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingBerhasil}, util.BookingTidakHadir, "booking status can not be changed from 2 to 6"},
+		{"cancelled after paid", Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingBerhasil, ArrivedAt: &arrivedAt}, util.BookingGagal, "booking status can not be changed from 2 to 4"},
+		{"reopened after completed", Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingSelesai, ArrivedAt: &arrivedAt}, util.BookingBerhasil, "booking status can not be changed from 3 to 2"},
+		{"completed without check-in", Ownership{CustomerID: 1, OwnerID: 1, Status: util.BookingBerhasil}, util.BookingSelesai, "booking must be checked in before it is completed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+			ownership := test.ownership
+			mockRepo.On("GetBookingOwnership", 1).Return(&ownership, nil)
+
+			err := mockService.UpdateBookingStatus(1, 1, test.newStatus)
+			assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+			assert.Contains(t, err.Error(), test.message)
+			mockRepo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything, mock.Anything)
+			mockRepo.AssertNotCalled(t, "CompleteBooking", mock.Anything)
+		})
+	}
 }
 
 func TestService_UpdateBookingStatusWithBookingIDBelowOne(t *testing.T) {
@@ -592,10 +853,12 @@ func TestService_UpdateBookingStatusWithBookingIDBelowOne(t *testing.T) {
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 }
@@ -607,27 +870,30 @@ func TestService_UpdateBookingStatusWithNewStatusBelowZero(t *testing.T) {
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 }
 
 func TestService_UpdateBookingStatusFailedCalledUpdateBookingStatus(t *testing.T) {
 	bookingID := 1
-	newStatus := 2
+	newStatus := util.BookingGagal
 
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
-	mockRepo.On("UpdateBookingStatus", bookingID, newStatus).Return(ErrInternalServerError)
+	mockRepo.On("UpdateBookingStatus", bookingID, newStatus, util.NotificationBookingCancelled).Return(ErrInternalServerError)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 
@@ -636,7 +902,7 @@ func TestService_UpdateBookingStatusFailedCalledUpdateBookingStatus(t *testing.T
 func TestService_ChangeStatusToBookingBelumMembayarFailedGetInvoices(t *testing.T) {
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	bookingID := 1
 	newStatus := util.BookingBelumMembayar
@@ -667,6 +933,7 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedGetInvoices(t *testing.
 
 	ticketPriceWrapper := TicketPriceWrapper{
 		Price: 10000,
+		Fixed: true,
 	}
 
 	itemsWrapper := ItemsWrapper{
@@ -684,9 +951,10 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedGetInvoices(t *testing.
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, nil)
 	mockRepo.On("GetInvoicesFromBooking", 1).Return(false, errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Error(t, err, "test error")
 }
@@ -694,7 +962,7 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedGetInvoices(t *testing.
 func TestService_ChangeStatusToBookingBelumMembayarSuccess(t *testing.T) {
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	bookingID := 1
 	newStatus := util.BookingBelumMembayar
@@ -756,6 +1024,7 @@ func TestService_ChangeStatusToBookingBelumMembayarSuccess(t *testing.T) {
 
 	ticketPriceWrapper := TicketPriceWrapper{
 		Price: 20000,
+		Fixed: true,
 	}
 
 	itemsWrapper := ItemsWrapper{
@@ -774,12 +1043,23 @@ func TestService_ChangeStatusToBookingBelumMembayarSuccess(t *testing.T) {
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, nil)
 	xenditService.On("CreateInvoice", invoiceParams).Return(&xenditInvoiceReturned, nil)
+	mockRepo.On("InsertInvoice", Invoice{
+		BookingID:   bookingID,
+		XenditID:    xenditInvoiceReturned.ID,
+		InvoiceURL:  xenditInvoiceReturned.InvoiceURL,
+		Type:        util.InvoiceTypeFull,
+		Amount:      23000,
+		PlatformFee: util.XenditPlatformFee,
+		Status:      util.InvoicePending,
+		ExpiredAt:   now,
+	}).Return(nil)
 	mockRepo.On("InsertXenditInformation", xenditInformationParams).Return(true, nil)
 	mockRepo.On("AddExpiredPayment", 1, now).Return(nil)
-	mockRepo.On("UpdateBookingStatus", 1, util.BookingBelumMembayar).Return(nil)
+	mockRepo.On("UpdateBookingStatus", 1, util.BookingBelumMembayar, util.NotificationBookingConfirmed).Return(nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Nil(t, err)
 }
@@ -787,7 +1067,7 @@ func TestService_ChangeStatusToBookingBelumMembayarSuccess(t *testing.T) {
 func TestService_ChangeStatusToBookingBelumMembayarFailedAddExpiredPayment(t *testing.T) {
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	bookingID := 1
 	newStatus := util.BookingBelumMembayar
@@ -849,6 +1129,7 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedAddExpiredPayment(t *te
 
 	ticketPriceWrapper := TicketPriceWrapper{
 		Price: 20000,
+		Fixed: true,
 	}
 
 	itemsWrapper := ItemsWrapper{
@@ -867,11 +1148,22 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedAddExpiredPayment(t *te
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, nil)
 	xenditService.On("CreateInvoice", invoiceParams).Return(&xenditInvoiceReturned, nil)
+	mockRepo.On("InsertInvoice", Invoice{
+		BookingID:   bookingID,
+		XenditID:    xenditInvoiceReturned.ID,
+		InvoiceURL:  xenditInvoiceReturned.InvoiceURL,
+		Type:        util.InvoiceTypeFull,
+		Amount:      23000,
+		PlatformFee: util.XenditPlatformFee,
+		Status:      util.InvoicePending,
+		ExpiredAt:   now,
+	}).Return(nil)
 	mockRepo.On("InsertXenditInformation", xenditInformationParams).Return(true, nil)
 	mockRepo.On("AddExpiredPayment", 1, now).Return(errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Error(t, err, "test error")
 }
@@ -879,7 +1171,7 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedAddExpiredPayment(t *te
 func TestService_ChangeStatusToBookingBelumMembayarFailedInsertXenditInfo(t *testing.T) {
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	bookingID := 1
 	newStatus := util.BookingBelumMembayar
@@ -935,6 +1227,7 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedInsertXenditInfo(t *tes
 
 	ticketPriceWrapper := TicketPriceWrapper{
 		Price: 20000,
+		Fixed: true,
 	}
 
 	itemsWrapper := ItemsWrapper{
@@ -959,10 +1252,21 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedInsertXenditInfo(t *tes
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, nil)
 	xenditService.On("CreateInvoice", invoiceParams).Return(&xenditInvoiceReturned, nil)
+	mockRepo.On("InsertInvoice", Invoice{
+		BookingID:   bookingID,
+		XenditID:    xenditInvoiceReturned.ID,
+		InvoiceURL:  xenditInvoiceReturned.InvoiceURL,
+		Type:        util.InvoiceTypeFull,
+		Amount:      23000,
+		PlatformFee: util.XenditPlatformFee,
+		Status:      util.InvoicePending,
+		ExpiredAt:   now,
+	}).Return(nil)
 	mockRepo.On("InsertXenditInformation", xenditInformationParams).Return(false, errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Error(t, err, "test error")
 }
@@ -970,7 +1274,7 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedInsertXenditInfo(t *tes
 func TestService_ChangeStatusToBookingBelumMembayarCreateInvoices(t *testing.T) {
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	bookingID := 1
 	newStatus := util.BookingBelumMembayar
@@ -1026,6 +1330,7 @@ func TestService_ChangeStatusToBookingBelumMembayarCreateInvoices(t *testing.T)
 
 	ticketPriceWrapper := TicketPriceWrapper{
 		Price: 20000,
+		Fixed: true,
 	}
 
 	itemsWrapper := ItemsWrapper{
@@ -1044,9 +1349,10 @@ func TestService_ChangeStatusToBookingBelumMembayarCreateInvoices(t *testing.T)
 	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(ticketPriceWrapper, nil)
 	mockRepo.On("GetItemWrapper", bookingID).Return(itemsWrapper, nil)
 	xenditService.On("CreateInvoice", invoiceParams).Return(&xenditInvoiceReturned, errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Error(t, err, "test error")
 }
@@ -1054,7 +1360,7 @@ func TestService_ChangeStatusToBookingBelumMembayarCreateInvoices(t *testing.T)
 func TestService_ChangeStatusToBookingBelumMembayarFailedGetDetail(t *testing.T) {
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	bookingID := 1
 	newStatus := util.BookingBelumMembayar
@@ -1083,9 +1389,10 @@ func TestService_ChangeStatusToBookingBelumMembayarFailedGetDetail(t *testing.T)
 		},
 	}
 	mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	err := mockService.UpdateBookingStatus(bookingID, newStatus)
+	err := mockService.UpdateBookingStatus(1, bookingID, newStatus)
 
 	assert.Error(t, err, "test error")
 }
@@ -1106,7 +1413,7 @@ func TestService_GetMyBookingsOngoingSuccess(t *testing.T) {
 			EndTime:    time.Now(),
 			Status:     0,
 			TotalPrice: 10000,
-			ExpiredAt: time.Now(),
+			ExpiredAt:  time.Now(),
 		},
 		{
 			ID:         2,
@@ -1118,16 +1425,16 @@ func TestService_GetMyBookingsOngoingSuccess(t *testing.T) {
 			EndTime:    EndTime,
 			Status:     0,
 			TotalPrice: 20000,
-			ExpiredAt: time.Now(),
+			ExpiredAt:  time.Now(),
 		},
 	}
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetMyBookingsOngoing", localID).Return(myBookingsOngoing, nil)
-	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal).Return(nil)
+	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingExpired).Return(nil)
 
 	myBookingsOngoingResult, err := mockService.GetMyBookingsOngoing(localID)
 	mockRepo.AssertExpectations(t)
@@ -1153,7 +1460,7 @@ func TestService_GetMyBookingsOngoingFailedUpdateStatus(t *testing.T) {
 			EndTime:    time.Now(),
 			Status:     0,
 			TotalPrice: 10000,
-			ExpiredAt: time.Now(),
+			ExpiredAt:  time.Now(),
 		},
 		{
 			ID:         2,
@@ -1165,16 +1472,16 @@ func TestService_GetMyBookingsOngoingFailedUpdateStatus(t *testing.T) {
 			EndTime:    EndTime,
 			Status:     0,
 			TotalPrice: 20000,
-			ExpiredAt: time.Now(),
+			ExpiredAt:  time.Now(),
 		},
 	}
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetMyBookingsOngoing", localID).Return(myBookingsOngoing, nil)
-	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal).Return(errors.Wrap(ErrInternalServerError, "testerror"))
+	mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingExpired).Return(errors.Wrap(ErrInternalServerError, "testerror"))
 
 	myBookingsOngoingResult, err := mockService.GetMyBookingsOngoing(localID)
 	mockRepo.AssertExpectations(t)
@@ -1190,7 +1497,7 @@ func TestService_GetMyBookingsOngoingWrongInput(t *testing.T) {
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Test
 	myBookingsOngoing, err := mockService.GetMyBookingsOngoing(localID)
@@ -1205,7 +1512,7 @@ func TestService_GetMyBookingsOngoingFailedCalledGetDetail(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	mockRepo.On("GetMyBookingsOngoing", localID).Return(myBookingsOngoing, ErrInternalServerError)
 
@@ -1230,7 +1537,7 @@ func TestService_GetMyBookingsPreviousWithPaginationSuccess(t *testing.T) {
 				EndTime:    time.Now(),
 				Status:     0,
 				TotalPrice: 10000,
-				ExpiredAt: time.Now(),
+				ExpiredAt:  time.Now(),
 			},
 			{
 				ID:         2,
@@ -1242,7 +1549,7 @@ func TestService_GetMyBookingsPreviousWithPaginationSuccess(t *testing.T) {
 				EndTime:    time.Now(),
 				Status:     0,
 				TotalPrice: 20000,
-				ExpiredAt: time.Now(),
+				ExpiredAt:  time.Now(),
 			},
 		},
 		TotalCount: 2,
@@ -1257,7 +1564,7 @@ func TestService_GetMyBookingsPreviousWithPaginationSuccess(t *testing.T) {
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Expectation
 	mockRepo.On("GetMyBookingsPreviousWithPagination", localID, params).Return(myBookingsPrevious, nil)
@@ -1285,7 +1592,7 @@ func TestService_GetMyBookingsPreviousWithPaginationSuccessWithDefaultParam(t *t
 				EndTime:    time.Now(),
 				Status:     0,
 				TotalPrice: 10000,
-				ExpiredAt: time.Now(),
+				ExpiredAt:  time.Now(),
 			},
 			{
 				ID:         2,
@@ -1297,7 +1604,7 @@ func TestService_GetMyBookingsPreviousWithPaginationSuccessWithDefaultParam(t *t
 				EndTime:    time.Now(),
 				Status:     0,
 				TotalPrice: 20000,
-				ExpiredAt: time.Now(),
+				ExpiredAt:  time.Now(),
 			},
 		},
 		TotalCount: 2,
@@ -1312,7 +1619,7 @@ func TestService_GetMyBookingsPreviousWithPaginationSuccessWithDefaultParam(t *t
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	paramsDefault := BookingsListRequest{
 		Limit: 10,
@@ -1344,7 +1651,7 @@ func TestService_GetMyBookingsPreviousWithPaginationFailedLimitExceedMaxLimit(t
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Test
 	myBookingsPreviousResult, _, err := mockService.GetMyBookingsPreviousWithPagination(localID, params)
@@ -1367,7 +1674,7 @@ func TestService_GetMyBookingsPreviousWithPaginationFailedCalledGetPlacesListWit
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, xenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Expectation
 	mockRepo.On("GetMyBookingsPreviousWithPagination", localID, params).Return(myBookingsPrevious, ErrInternalServerError)
@@ -1583,7 +1890,8 @@ func TestService_formatAvailableTime(t *testing.T) {
 func TestService_GetAvailableTime(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockXenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, mockXenditService)
+	mockPricingService := new(MockPricingService)
+	mockService := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 	t.Run("success", func(t *testing.T) {
 		selectedDate, _ := time.Parse(util.DateLayout, "2022-03-29")
@@ -1679,19 +1987,71 @@ func TestService_GetAvailableTime(t *testing.T) {
 			OpenHour: startTime,
 			Capacity: placeCapacity,
 		}, nil)
+		mockPricingService.On("GetRuleSet", params.PlaceID).Return(&pricing.RuleSet{
+			PlaceID:   params.PlaceID,
+			BasePrice: 10000,
+			Rules: []pricing.Rule{
+				{Name: "per person", Type: util.PricingRulePerPerson, Price: 1000},
+			},
+		}, nil)
 
 		output, err := mockService.GetAvailableTime(params)
 		mockRepo.AssertExpectations(t)
+		mockPricingService.AssertExpectations(t)
 
 		assert.Nil(t, err)
 		assert.NotNil(t, output)
 		assert.Equal(t, len(expectedOutput), len(*output))
+		for _, availableTime := range *output {
+			assert.Equal(t, 20000.0, availableTime.Price)
+		}
+	})
+
+	t.Run("failed get pricing rules", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPricingService := new(MockPricingService)
+		mockService := NewService(mockRepo, new(MockXenditService), mockPricingService, newMockNotificationService(), "secret")
+
+		selectedDate, _ := time.Parse(util.DateLayout, "2022-03-29")
+		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
+		endTime, _ := time.Parse(util.TimeLayout, "09:00:00")
+
+		params := GetAvailableTimeParams{
+			PlaceID:      1,
+			SelectedDate: selectedDate,
+			StartTime:    startTime,
+			BookedSlot:   1,
+		}
+
+		midnight := time.Date(selectedDate.Year(), selectedDate.Month(), selectedDate.Day(), 0, 0, 0, 0, selectedDate.Location())
+		midnight = midnight.Add(time.Duration(1*24) * time.Hour)
+
+		mockRepo.On("GetBookingData", GetBookingDataParams{
+			PlaceID:   params.PlaceID,
+			StartDate: params.SelectedDate,
+			EndDate:   midnight,
+			StartTime: params.StartTime,
+		}).Return(&[]DataForCheckAvailableSchedule{}, nil)
+		mockRepo.On("GetTimeSlotsData", params.PlaceID, []time.Time{selectedDate}).Return(&[]TimeSlot{
+			{ID: 1, StartTime: startTime, EndTime: endTime, Day: int(selectedDate.Weekday())},
+		}, nil)
+		mockRepo.On("GetPlaceCapacity", params.PlaceID).Return(&PlaceOpenHourAndCapacity{
+			OpenHour: startTime,
+			Capacity: 10,
+		}, nil)
+		mockPricingService.On("GetRuleSet", params.PlaceID).Return(&pricing.RuleSet{}, pricing.ErrInternalServerError)
+
+		output, err := mockService.GetAvailableTime(params)
+		mockPricingService.AssertExpectations(t)
+
+		assert.Nil(t, output)
+		assert.Equal(t, pricing.ErrInternalServerError, errors.Cause(err))
 	})
 
 	t.Run("input validation error", func(t *testing.T) {
 		mockXenditService := new(MockXenditService)
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -1714,7 +2074,7 @@ func TestService_GetAvailableTime(t *testing.T) {
 func TestService_GetAvailableTimeGetBookingDataFailedInternalServerError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockXenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, mockXenditService)
+	mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	selectedDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 	startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -1754,7 +2114,7 @@ func TestService_GetAvailableTimeGetBookingDataFailedInternalServerError(t *test
 func TestService_GetAvailableTimeGetTimeSlotFailedInternalServerError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockXenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, mockXenditService)
+	mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	selectedDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 	startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -1799,7 +2159,7 @@ func TestService_GetAvailableTimeGetTimeSlotFailedInternalServerError(t *testing
 func TestService_GetAvailableTimeGetPlaceCapacityFailedInternalServerError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockXenditService := new(MockXenditService)
-	mockService := NewService(mockRepo, mockXenditService)
+	mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	selectedDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 	startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -1846,7 +2206,7 @@ func TestService_GetAvailableDate(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -1937,7 +2297,7 @@ func TestService_GetAvailableDate(t *testing.T) {
 	t.Run("success with default value of interval", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -2026,7 +2386,7 @@ func TestService_GetAvailableDate(t *testing.T) {
 	t.Run("failed get booking data", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -2079,7 +2439,7 @@ func TestService_GetAvailableDate(t *testing.T) {
 	t.Run("failed get time slot data", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -2168,7 +2528,7 @@ func TestService_GetAvailableDate(t *testing.T) {
 	t.Run("failed get place capacity", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -2260,7 +2620,7 @@ func TestService_GetAvailableDate(t *testing.T) {
 	t.Run("input validation error", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
-		mockService := NewService(mockRepo, mockXenditService)
+		mockService := NewService(mockRepo, mockXenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		startDate, _ := time.Parse(util.DateLayout, "2022-03-29")
 
@@ -2285,7 +2645,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", 1).Return(&pricing.RuleSet{PlaceID: 1, BasePrice: 25000}, nil)
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2328,14 +2690,16 @@ func TestService_CreateBooking(t *testing.T) {
 		}
 
 		bookingParams := CreateBookingParams{
-			UserID:     input.UserID,
-			PlaceID:    input.PlaceID,
-			Date:       input.Date,
-			StartTime:  input.StartTime,
-			EndTime:    input.EndTime,
-			Capacity:   input.Count,
-			Status:     util.BookingMenungguKonfirmasi,
-			TotalPrice: 0,
+			UserID:       input.UserID,
+			PlaceID:      input.PlaceID,
+			Date:         input.Date,
+			StartTime:    input.StartTime,
+			EndTime:      input.EndTime,
+			Capacity:     input.Count,
+			Status:       util.BookingMenungguKonfirmasi,
+			TotalPrice:   0,
+			BookingPrice: 25000,
+			SplitCount:   1,
 		}
 
 		bookingItemParams := []CreateBookingItemsParams{
@@ -2400,9 +2764,12 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("CreateBookingItems", bookingItemParams).Return(&CreateBookingItemsResponse{TotalPrice: 40000}, nil)
 		mockRepo.On("UpdateTotalPrice", updateTotalPrice).Return(true, nil)
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		mockXenditService.AssertExpectations(t)
+		mockPricingService.AssertExpectations(t)
 
 		assert.Nil(t, err)
 		assert.NotNil(t, resp)
@@ -2413,7 +2780,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2444,6 +2813,8 @@ func TestService_CreateBooking(t *testing.T) {
 			CustomerPhoneNumber: "081291264758",
 		}
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		assert.NotNil(t, err)
 		assert.Nil(t, resp)
@@ -2454,7 +2825,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2505,6 +2878,8 @@ func TestService_CreateBooking(t *testing.T) {
 
 		mockRepo.On("CheckedItem", items).Return(&itemsOutput, false, errors.Wrap(ErrInputValidationError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		assert.NotNil(t, err)
 		assert.Nil(t, resp)
@@ -2515,7 +2890,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2566,6 +2943,8 @@ func TestService_CreateBooking(t *testing.T) {
 
 		mockRepo.On("CheckedItem", items).Return(&itemsOutput, false, errors.Wrap(ErrInternalServerError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		assert.NotNil(t, err)
@@ -2577,7 +2956,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2628,6 +3009,7 @@ func TestService_CreateBooking(t *testing.T) {
 			Capacity:   input.Count,
 			Status:     util.BookingMenungguKonfirmasi,
 			TotalPrice: 0,
+			SplitCount: 1,
 		}
 
 		midnight := time.Date(input.Date.Year(), input.Date.Month(), input.Date.Day(), 0, 0, 0, 0, input.Date.Location())
@@ -2670,6 +3052,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("GetPlaceCapacity", input.PlaceID).Return(&placeIDAndCapacity, nil)
 		mockRepo.On("CreateBooking", bookingParams).Return(&CreateBookingResponse{ID: 1}, errors.Wrap(ErrInternalServerError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		assert.NotNil(t, err)
@@ -2681,7 +3065,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2732,6 +3118,7 @@ func TestService_CreateBooking(t *testing.T) {
 			Capacity:   input.Count,
 			Status:     util.BookingMenungguKonfirmasi,
 			TotalPrice: 0,
+			SplitCount: 1,
 		}
 
 		bookingItemParams := []CreateBookingItemsParams{
@@ -2790,6 +3177,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("CreateBooking", bookingParams).Return(&CreateBookingResponse{ID: 1}, nil)
 		mockRepo.On("CreateBookingItems", bookingItemParams).Return(&CreateBookingItemsResponse{TotalPrice: 40000}, errors.Wrap(ErrInternalServerError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		assert.NotNil(t, err)
@@ -2801,7 +3190,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2852,6 +3243,7 @@ func TestService_CreateBooking(t *testing.T) {
 			Capacity:   input.Count,
 			Status:     util.BookingMenungguKonfirmasi,
 			TotalPrice: 0,
+			SplitCount: 1,
 		}
 
 		bookingItemParams := []CreateBookingItemsParams{
@@ -2916,6 +3308,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("CreateBookingItems", bookingItemParams).Return(&CreateBookingItemsResponse{TotalPrice: 40000}, nil)
 		mockRepo.On("UpdateTotalPrice", updateTotalPrice).Return(false, errors.Wrap(ErrInternalServerError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		assert.NotNil(t, err)
@@ -2927,7 +3321,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -2954,6 +3350,7 @@ func TestService_CreateBooking(t *testing.T) {
 			Capacity:   input.Count,
 			Status:     util.BookingMenungguKonfirmasi,
 			TotalPrice: 0,
+			SplitCount: 1,
 		}
 
 		midnight := time.Date(input.Date.Year(), input.Date.Month(), input.Date.Day(), 0, 0, 0, 0, input.Date.Location())
@@ -2995,6 +3392,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("GetPlaceCapacity", input.PlaceID).Return(&placeIDAndCapacity, nil)
 		mockRepo.On("CreateBooking", bookingParams).Return(&CreateBookingResponse{ID: 1}, nil)
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		mockXenditService.AssertExpectations(t)
@@ -3008,7 +3407,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -3073,6 +3474,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("CheckedItem", items).Return(&items, true, nil)
 		mockRepo.On("GetBookingData", repoParams).Return(&getBookingData, errors.Wrap(ErrInternalServerError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		mockXenditService.AssertExpectations(t)
@@ -3086,7 +3489,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -3151,6 +3556,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("CheckedItem", items).Return(&items, true, nil)
 		mockRepo.On("GetBookingData", repoParams).Return(&getBookingData, errors.Wrap(ErrInternalServerError, "test error"))
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 		mockXenditService.AssertExpectations(t)
@@ -3164,7 +3571,9 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXenditService := new(MockXenditService)
 
-		service := NewService(mockRepo, mockXenditService)
+		mockPricingService := new(MockPricingService)
+		mockPricingService.On("GetRuleSet", mock.Anything).Return(&pricing.RuleSet{}, nil).Maybe()
+		service := NewService(mockRepo, mockXenditService, mockPricingService, newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2022-02-02")
 		startTime, _ := time.Parse(util.TimeLayout, "08:00:00")
@@ -3245,6 +3654,8 @@ func TestService_CreateBooking(t *testing.T) {
 		mockRepo.On("GetTimeSlotsData", input.PlaceID, []time.Time{input.Date}).Return(&timeSlotsData, nil)
 		mockRepo.On("GetPlaceCapacity", input.PlaceID).Return(&placeIDAndCapacity, nil)
 
+		mockRepo.On("GetCustomerNoShowPolicy", input.PlaceID, input.UserID).Return(&CustomerNoShowPolicy{}, nil).Maybe()
+
 		resp, err := service.CreateBooking(input)
 		mockRepo.AssertExpectations(t)
 
@@ -3258,7 +3669,7 @@ func TestService_GetTimeSlots(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2020-01-01")
 		dateSlice := []time.Time{date}
@@ -3286,7 +3697,7 @@ func TestService_GetTimeSlots(t *testing.T) {
 	t.Run("success when date is today", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		date := time.Now()
 		dateSlice := []time.Time{date}
@@ -3331,7 +3742,7 @@ func TestService_GetTimeSlots(t *testing.T) {
 	t.Run("failed input validation error", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2020-01-01")
 		dateSlice := []time.Time{date}
@@ -3358,7 +3769,7 @@ func TestService_GetTimeSlots(t *testing.T) {
 	t.Run("failed internal server error", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		date, _ := time.Parse(util.DateLayout, "2020-01-01")
 		dateSlice := []time.Time{date}
@@ -3385,11 +3796,21 @@ func TestService_GetTimeSlots(t *testing.T) {
 }
 
 func TestService_UpdateBookingStatusByXendit(t *testing.T) {
+	fullInvoice := Invoice{
+		ID:          5,
+		BookingID:   10,
+		XenditID:    "1",
+		Type:        util.InvoiceTypeFull,
+		Amount:      20000.0,
+		PlatformFee: util.XenditPlatformFee,
+		Status:      util.InvoicePending,
+	}
+
 	t.Run("success", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
 
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		params := XenditInvoicesCallback{
 			ID:         "1",
@@ -3398,135 +3819,375 @@ func TestService_UpdateBookingStatusByXendit(t *testing.T) {
 			Amount:     20000.0,
 		}
 
-		repo.On("UpdateBookingStatusByXenditID", "1", 2).
-			Return(nil)
-		repo.On("IncrementBusinessAdminBalance", 17000.0, 1).Return(nil)
+		repo.On("GetInvoiceByXenditID", "1").Return(&fullInvoice, nil)
+		repo.On("SettleInvoice", 5, util.InvoicePaid, 17000.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(0, nil)
+		repo.On("UpdateBookingStatus", 10, util.BookingBerhasil, util.NotificationBookingPaid).Return(nil)
 
 		err := service.XenditInvoicesCallback(params)
+		repo.AssertExpectations(t)
 		assert.Nil(t, err)
 	})
 
-	t.Run("failed repo increment balance", func(t *testing.T) {
+	t.Run("success split invoice still waiting other participant", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
 
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		params := XenditInvoicesCallback{
-			ID:         "1",
+			ID:         "2",
 			ExternalID: "1",
 			Status:     "PAID",
-			Amount:     20000.0,
+			Amount:     10000.0,
 		}
 
-		repo.On("UpdateBookingStatusByXenditID", "1", 2).
-			Return(nil)
-		repo.On("IncrementBusinessAdminBalance", 17000.0, 1).Return(errors.Wrap(ErrInternalServerError, "test error"))
+		splitInvoice := Invoice{ID: 6, BookingID: 10, XenditID: "2", Type: util.InvoiceTypeSplit, Amount: 10000.0, Status: util.InvoicePending}
+
+		repo.On("GetInvoiceByXenditID", "2").Return(&splitInvoice, nil)
+		repo.On("SettleInvoice", 6, util.InvoicePaid, 10000.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil)
+		repo.On("CountPendingRequiredInvoices", 10).Return(1, nil)
 
 		err := service.XenditInvoicesCallback(params)
-		assert.NotNil(t, err)
-		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything, mock.Anything)
+		assert.Nil(t, err)
 	})
 
-	t.Run("failed external id not valid", func(t *testing.T) {
+	t.Run("success remaining invoice paid", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "3",
+			ExternalID: "1",
+			Status:     "PAID",
+			Amount:     14000.0,
+		}
+
+		remainingInvoice := Invoice{ID: 7, BookingID: 10, XenditID: "3", Type: util.InvoiceTypeRemaining, Amount: 14000.0, Status: util.InvoicePending}
+
+		repo.On("GetInvoiceByXenditID", "3").Return(&remainingInvoice, nil)
+		repo.On("SettleInvoice", 7, util.InvoicePaid, 14000.0, 1).Return(true, nil)
+
+		err := service.XenditInvoicesCallback(params)
+		repo.AssertExpectations(t)
+		assert.Nil(t, err)
+	})
+
+	t.Run("success duplicate callback", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
 
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		params := XenditInvoicesCallback{
 			ID:         "1",
-			ExternalID: "tes",
+			ExternalID: "1",
 			Status:     "PAID",
 			Amount:     20000.0,
 		}
 
+		paidInvoice := fullInvoice
+		paidInvoice.Status = util.InvoicePaid
+
+		repo.On("GetInvoiceByXenditID", "1").Return(&paidInvoice, nil)
+
 		err := service.XenditInvoicesCallback(params)
-		assert.NotNil(t, err)
-		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		repo.AssertNotCalled(t, "SettleInvoice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Nil(t, err)
 	})
 
-	t.Run("failed from repo", func(t *testing.T) {
+	t.Run("failed settle invoice", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
 
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		params := XenditInvoicesCallback{
 			ID:         "1",
 			ExternalID: "1",
 			Status:     "PAID",
+			Amount:     20000.0,
 		}
 
-		repo.On("UpdateBookingStatusByXenditID", "1", 2).
-			Return(ErrInternalServerError)
+		repo.On("GetInvoiceByXenditID", "1").Return(&fullInvoice, nil)
+		repo.On("SettleInvoice", 5, util.InvoicePaid, 17000.0, 1).Return(false, errors.Wrap(ErrInternalServerError, "test error"))
 
 		err := service.XenditInvoicesCallback(params)
 		assert.NotNil(t, err)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 
-	t.Run("success booking expired", func(t *testing.T) {
+	t.Run("failed count unpaid invoices", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
 
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		params := XenditInvoicesCallback{
 			ID:         "1",
 			ExternalID: "1",
-			Status:     "EXPIRED",
+			Status:     "PAID",
+			Amount:     20000.0,
 		}
 
-		repo.On("UpdateBookingStatusByXenditID", "1", 4).
-			Return(ErrInternalServerError)
+		repo.On("GetInvoiceByXenditID", "1").Return(&fullInvoice, nil)
+		repo.On("SettleInvoice", 5, util.InvoicePaid, 17000.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
 
 		err := service.XenditInvoicesCallback(params)
-		assert.NotNil(t, err)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 
-	t.Run("validation error unknown status", func(t *testing.T) {
+	t.Run("failed external id not valid", func(t *testing.T) {
 		repo := new(MockRepository)
 		xenditService := new(MockXenditService)
 
-		service := NewService(repo, xenditService)
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 		params := XenditInvoicesCallback{
 			ID:         "1",
-			ExternalID: "1",
-			Status:     "UNKNOWN",
+			ExternalID: "tes",
+			Status:     "PAID",
+			Amount:     20000.0,
 		}
 
 		err := service.XenditInvoicesCallback(params)
 		assert.NotNil(t, err)
 		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 	})
-}
 
-func TestService_GetDetailBookingSayaSuccess(t *testing.T) {
-	listItemExpected := []Item{
-		{
-			ID:         0,
-			Name:       "Harga Booking",
-			Price:      1000,
-			Qty:        1,
-			TotalPrice: 1000,
-		},
-		{
-			ID:         999,
-			Name:       "Platform Fee",
-			Price:      3000,
-			Qty:        1,
-			TotalPrice: 3000,
-		},
-		{
-			ID:         1,
-			Name:       "test name 1",
-			Price:      1000,
-			Qty:        1,
-			TotalPrice: 1000,
-		},
+	t.Run("failed invoice not found", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "1",
+			ExternalID: "1",
+			Status:     "PAID",
+		}
+
+		repo.On("GetInvoiceByXenditID", "1").Return(&Invoice{}, errors.Wrap(ErrNotFound, "test error"))
+
+		err := service.XenditInvoicesCallback(params)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("success invoice settled by concurrent callback", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "1",
+			ExternalID: "1",
+			Status:     "PAID",
+			Amount:     20000.0,
+		}
+
+		repo.On("GetInvoiceByXenditID", "1").Return(&fullInvoice, nil)
+		repo.On("SettleInvoice", 5, util.InvoicePaid, 17000.0, 1).Return(false, nil)
+
+		err := service.XenditInvoicesCallback(params)
+		repo.AssertNotCalled(t, "CountUnpaidRequiredInvoices", mock.Anything)
+		repo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything, mock.Anything)
+		assert.Nil(t, err)
+	})
+
+	t.Run("success booking expired", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "1",
+			ExternalID: "1",
+			Status:     "EXPIRED",
+		}
+
+		repo.On("GetInvoiceByXenditID", "1").Return(&fullInvoice, nil)
+		repo.On("SettleInvoice", 5, util.InvoiceExpired, 0.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil)
+		repo.On("CountPendingRequiredInvoices", 10).Return(0, nil)
+		repo.On("FailUnpaidBooking", 10, 1).Return(true, nil)
+
+		err := service.XenditInvoicesCallback(params)
+		repo.AssertExpectations(t)
+		assert.Nil(t, err)
+	})
+
+	t.Run("success split invoice expired while other participant can still pay", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "2",
+			ExternalID: "1",
+			Status:     "EXPIRED",
+		}
+
+		splitInvoice := Invoice{ID: 6, BookingID: 10, XenditID: "2", Type: util.InvoiceTypeSplit, Amount: 10000.0, Status: util.InvoicePending}
+
+		repo.On("GetInvoiceByXenditID", "2").Return(&splitInvoice, nil)
+		repo.On("SettleInvoice", 6, util.InvoiceExpired, 0.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(2, nil)
+		repo.On("CountPendingRequiredInvoices", 10).Return(1, nil)
+
+		err := service.XenditInvoicesCallback(params)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "FailUnpaidBooking", mock.Anything, mock.Anything)
+		assert.Nil(t, err)
+	})
+
+	t.Run("success split invoice paid after other participant expired", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "2",
+			ExternalID: "1",
+			Status:     "PAID",
+			Amount:     10000.0,
+		}
+
+		splitInvoice := Invoice{ID: 6, BookingID: 10, XenditID: "2", Type: util.InvoiceTypeSplit, Amount: 10000.0, Status: util.InvoicePending}
+
+		repo.On("GetInvoiceByXenditID", "2").Return(&splitInvoice, nil)
+		repo.On("SettleInvoice", 6, util.InvoicePaid, 10000.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil)
+		repo.On("CountPendingRequiredInvoices", 10).Return(0, nil)
+		repo.On("FailUnpaidBooking", 10, 1).Return(true, nil)
+
+		err := service.XenditInvoicesCallback(params)
+		repo.AssertExpectations(t)
+		assert.Nil(t, err)
+	})
+
+	t.Run("success split booking failed refund participant who already paid", func(t *testing.T) {
+		repo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		service := NewService(repo, new(MockXenditService), new(MockPricingService), mockNotification, "secret")
+
+		paidInvoice := Invoice{ID: 6, BookingID: 10, XenditID: "2", Type: util.InvoiceTypeSplit, Amount: 10000.0, Status: util.InvoicePending}
+		expiredInvoice := Invoice{ID: 7, BookingID: 10, XenditID: "3", Type: util.InvoiceTypeSplit, Amount: 10000.0, Status: util.InvoicePending}
+
+		// participant A pay while participant B can still pay
+		repo.On("GetInvoiceByXenditID", "2").Return(&paidInvoice, nil)
+		repo.On("SettleInvoice", 6, util.InvoicePaid, 10000.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil).Once()
+		repo.On("CountPendingRequiredInvoices", 10).Return(1, nil).Once()
+
+		err := service.XenditInvoicesCallback(XenditInvoicesCallback{ID: "2", ExternalID: "1", Status: "PAID", Amount: 10000.0})
+		assert.Nil(t, err)
+		repo.AssertNotCalled(t, "FailUnpaidBooking", mock.Anything, mock.Anything)
+
+		// the invoice of participant B expire, the payment of participant A is refunded
+		repo.On("GetInvoiceByXenditID", "3").Return(&expiredInvoice, nil)
+		repo.On("SettleInvoice", 7, util.InvoiceExpired, 0.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil).Once()
+		repo.On("CountPendingRequiredInvoices", 10).Return(0, nil).Once()
+		repo.On("FailUnpaidBooking", 10, 1).Return(true, nil)
+		mockNotification.On("Notify", notification.Event{Type: util.NotificationBookingExpired, BookingID: 10})
+
+		err = service.XenditInvoicesCallback(XenditInvoicesCallback{ID: "3", ExternalID: "1", Status: "EXPIRED"})
+		assert.Nil(t, err)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything, mock.Anything)
+		mockNotification.AssertExpectations(t)
+	})
+
+	t.Run("success booking already failed is not notified again", func(t *testing.T) {
+		repo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		service := NewService(repo, new(MockXenditService), new(MockPricingService), mockNotification, "secret")
+
+		repo.On("GetInvoiceByXenditID", "1").Return(&fullInvoice, nil)
+		repo.On("SettleInvoice", 5, util.InvoiceExpired, 0.0, 1).Return(true, nil)
+		repo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil)
+		repo.On("CountPendingRequiredInvoices", 10).Return(0, nil)
+		repo.On("FailUnpaidBooking", 10, 1).Return(false, nil)
+
+		err := service.XenditInvoicesCallback(XenditInvoicesCallback{ID: "1", ExternalID: "1", Status: "EXPIRED"})
+		assert.Nil(t, err)
+		mockNotification.AssertNotCalled(t, "Notify", mock.Anything)
+	})
+
+	t.Run("success remaining invoice expired keep booking", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "3",
+			ExternalID: "1",
+			Status:     "EXPIRED",
+		}
+
+		remainingInvoice := Invoice{ID: 7, BookingID: 10, XenditID: "3", Type: util.InvoiceTypeRemaining, Status: util.InvoicePending}
+
+		repo.On("GetInvoiceByXenditID", "3").Return(&remainingInvoice, nil)
+		repo.On("SettleInvoice", 7, util.InvoiceExpired, 0.0, 1).Return(true, nil)
+
+		err := service.XenditInvoicesCallback(params)
+		repo.AssertNotCalled(t, "UpdateBookingStatus", mock.Anything, mock.Anything, mock.Anything)
+		assert.Nil(t, err)
+	})
+
+	t.Run("validation error unknown status", func(t *testing.T) {
+		repo := new(MockRepository)
+		xenditService := new(MockXenditService)
+
+		service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		params := XenditInvoicesCallback{
+			ID:         "1",
+			ExternalID: "1",
+			Status:     "UNKNOWN",
+		}
+
+		err := service.XenditInvoicesCallback(params)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetDetailBookingSayaSuccess(t *testing.T) {
+	listItemExpected := []Item{
+		{
+			ID:         0,
+			Name:       "Harga Booking",
+			Price:      1000,
+			Qty:        1,
+			TotalPrice: 1000,
+		},
+		{
+			ID:         999,
+			Name:       "Platform Fee",
+			Price:      3000,
+			Qty:        1,
+			TotalPrice: 3000,
+		},
+		{
+			ID:         1,
+			Name:       "test name 1",
+			Price:      1000,
+			Qty:        1,
+			TotalPrice: 1000,
+		},
 		{
 			ID:         2,
 			Name:       "test name 2",
@@ -3548,6 +4209,17 @@ func TestService_GetDetailBookingSayaSuccess(t *testing.T) {
 		Image:       "test image",
 	}
 
+	invoicesExpected := []Invoice{
+		{
+			ID:         1,
+			BookingID:  1,
+			InvoiceURL: "test invoices url",
+			Type:       util.InvoiceTypeFull,
+			Amount:     10000,
+			Status:     util.InvoicePending,
+		},
+	}
+
 	detailBookingSayaMergedExpected := DetailBookingSaya{
 		ID:          0,
 		Status:      0,
@@ -3559,19 +4231,22 @@ func TestService_GetDetailBookingSayaSuccess(t *testing.T) {
 		InvoicesURL: "test invoices url",
 		Image:       "test image",
 		Items:       listItemExpected,
+		Invoices:    invoicesExpected,
 	}
 
 	bookingID := 1
 
 	repo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	service := NewService(repo, xenditService)
+	service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	repo.On("GetDetailBookingSaya", bookingID).Return(detailBookingSayaExpected, nil)
 	repo.On("GetItemByBookingID", bookingID).Return(listItemExpected, nil)
+	repo.On("GetInvoicesByBookingID", bookingID).Return(&invoicesExpected, nil)
+	repo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	detailBookingSayaResult, err := service.GetDetailBookingSaya(bookingID)
+	detailBookingSayaResult, err := service.GetDetailBookingSaya(1, bookingID)
 	repo.AssertExpectations(t)
 
 	assert.Equal(t, &detailBookingSayaMergedExpected, detailBookingSayaResult)
@@ -3585,12 +4260,13 @@ func TestService_GetDetailBookingSayaError(t *testing.T) {
 
 	repo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	service := NewService(repo, xenditService)
+	service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	repo.On("GetDetailBookingSaya", bookingID).Return(detailBookingSaya, ErrInternalServerError)
+	repo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	detailBookingSayaResult, err := service.GetDetailBookingSaya(bookingID)
+	detailBookingSayaResult, err := service.GetDetailBookingSaya(1, bookingID)
 	repo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -3616,13 +4292,34 @@ func TestService_GetDetailBookingSayaItemsError(t *testing.T) {
 
 	repo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	service := NewService(repo, xenditService)
+	service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	repo.On("GetDetailBookingSaya", bookingID).Return(detailBookingSaya, nil)
 	repo.On("GetItemByBookingID", bookingID).Return(items, ErrInternalServerError)
+	repo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	// Test
+	detailBookingSayaResult, err := service.GetDetailBookingSaya(1, bookingID)
+	repo.AssertExpectations(t)
+
+	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	assert.Nil(t, detailBookingSayaResult)
+}
+
+func TestService_GetDetailBookingSayaInvoicesError(t *testing.T) {
+	bookingID := 1
+
+	repo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+	repo.On("GetDetailBookingSaya", bookingID).Return(DetailBookingSaya{}, nil)
+	repo.On("GetItemByBookingID", bookingID).Return([]Item{}, nil)
+	repo.On("GetInvoicesByBookingID", bookingID).Return(&[]Invoice{}, ErrInternalServerError)
+	repo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
 
 	// Test
-	detailBookingSayaResult, err := service.GetDetailBookingSaya(bookingID)
+	detailBookingSayaResult, err := service.GetDetailBookingSaya(1, bookingID)
 	repo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -3634,13 +4331,817 @@ func TestService_GetDetailBookingSayaBookingIDError(t *testing.T) {
 
 	repo := new(MockRepository)
 	xenditService := new(MockXenditService)
-	service := NewService(repo, xenditService)
+	service := NewService(repo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
 
 	// Test
-	detailBookingSayaResult, err := service.GetDetailBookingSaya(bookingID)
+	detailBookingSayaResult, err := service.GetDetailBookingSaya(1, bookingID)
 	repo.AssertExpectations(t)
 
 	assert.NotNil(t, err)
 	assert.Nil(t, detailBookingSayaResult)
 	assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 }
+
+func TestService_ChangeStatusToBookingBelumMembayarDeposit(t *testing.T) {
+	mockRepo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	mockPricingService := new(MockPricingService)
+	mockService := NewService(mockRepo, xenditService, mockPricingService, newMockNotificationService(), "secret")
+
+	bookingID := 1
+
+	bookingDetailOutput := Detail{
+		ID:                  1,
+		CustomerName:        "test",
+		CustomerPhoneNumber: "test",
+		PlaceID:             1,
+		PaymentType:         util.PaymentTypeDeposit,
+		SplitCount:          1,
+		TotalPriceItem:      80000,
+	}
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	now := time.Now().In(loc)
+	xenditInvoiceReturned := xendit2.Invoice{
+		ID:         "deposit id",
+		InvoiceURL: "deposit url",
+		ExpiryDate: &now,
+	}
+
+	invoiceParams := xendit.CreateInvoiceParams{
+		PlaceID:             1,
+		Items:               []xendit.Item{{Name: "Deposit 30%", Price: 30000, Qty: 1}},
+		Description:         "Deposit 30% for order from test",
+		CustomerName:        "test",
+		CustomerPhoneNumber: "test",
+	}
+
+	mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, nil)
+	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+	mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+	mockRepo.On("GetInvoicesFromBooking", bookingID).Return(false, nil)
+	mockPricingService.On("GetRuleSet", 1).Return(&pricing.RuleSet{PlaceID: 1, DepositPercentage: 30}, nil)
+	xenditService.On("CreateInvoice", invoiceParams).Return(&xenditInvoiceReturned, nil)
+	mockRepo.On("InsertInvoice", Invoice{
+		BookingID:   bookingID,
+		XenditID:    "deposit id",
+		InvoiceURL:  "deposit url",
+		Type:        util.InvoiceTypeDeposit,
+		Amount:      33000,
+		PlatformFee: util.XenditPlatformFee,
+		Status:      util.InvoicePending,
+		ExpiredAt:   now,
+	}).Return(nil)
+	mockRepo.On("InsertXenditInformation", XenditInformation{XenditID: "deposit id", InvoicesURL: "deposit url", BookingID: bookingID}).Return(true, nil)
+	mockRepo.On("AddExpiredPayment", bookingID, now).Return(nil)
+	mockRepo.On("UpdateBookingStatus", bookingID, util.BookingBelumMembayar, util.NotificationBookingConfirmed).Return(nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	err := mockService.UpdateBookingStatus(1, bookingID, util.BookingBelumMembayar)
+	mockRepo.AssertExpectations(t)
+	xenditService.AssertExpectations(t)
+
+	assert.Nil(t, err)
+}
+
+func TestService_ChangeStatusToBookingBelumMembayarDepositFailedGetRuleSet(t *testing.T) {
+	mockRepo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	mockPricingService := new(MockPricingService)
+	mockService := NewService(mockRepo, xenditService, mockPricingService, newMockNotificationService(), "secret")
+
+	bookingID := 1
+
+	mockRepo.On("GetDetail", bookingID).Return(Detail{ID: 1, PlaceID: 1, PaymentType: util.PaymentTypeDeposit}, nil)
+	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+	mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+	mockRepo.On("GetInvoicesFromBooking", bookingID).Return(false, nil)
+	mockPricingService.On("GetRuleSet", 1).Return(&pricing.RuleSet{}, errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	err := mockService.UpdateBookingStatus(1, bookingID, util.BookingBelumMembayar)
+
+	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+}
+
+func TestService_ChangeStatusToBookingBelumMembayarSplit(t *testing.T) {
+	mockRepo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+	bookingID := 1
+
+	bookingDetailOutput := Detail{
+		ID:                  1,
+		CustomerName:        "test",
+		CustomerPhoneNumber: "test",
+		PlaceID:             1,
+		PaymentType:         util.PaymentTypeSplit,
+		SplitCount:          3,
+		TotalPriceItem:      80000,
+	}
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	now := time.Now().In(loc)
+
+	mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, nil)
+	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+	mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+	mockRepo.On("GetInvoicesFromBooking", bookingID).Return(false, nil)
+
+	// 100000 split by 3, the first participant pay the rounding rest
+	expectedShares := []float64{33334, 33333, 33333}
+	for i, share := range expectedShares {
+		xenditID := fmt.Sprintf("split %d", i+1)
+		name := fmt.Sprintf("Split payment %d/3", i+1)
+
+		xenditService.On("CreateInvoice", xendit.CreateInvoiceParams{
+			PlaceID:             1,
+			Items:               []xendit.Item{{Name: name, Price: share, Qty: 1}},
+			Description:         fmt.Sprintf("%s for order from test", name),
+			CustomerName:        "test",
+			CustomerPhoneNumber: "test",
+			WithoutPlatformFee:  i > 0,
+		}).Return(&xendit2.Invoice{ID: xenditID, InvoiceURL: xenditID, ExpiryDate: &now}, nil)
+
+		platformFee := 0.0
+		if i == 0 {
+			platformFee = util.XenditPlatformFee
+		}
+
+		mockRepo.On("InsertInvoice", Invoice{
+			BookingID:   bookingID,
+			XenditID:    xenditID,
+			InvoiceURL:  xenditID,
+			Type:        util.InvoiceTypeSplit,
+			Amount:      share + platformFee,
+			PlatformFee: platformFee,
+			Status:      util.InvoicePending,
+			ExpiredAt:   now,
+		}).Return(nil)
+	}
+
+	mockRepo.On("InsertXenditInformation", XenditInformation{XenditID: "split 1", InvoicesURL: "split 1", BookingID: bookingID}).Return(true, nil)
+	mockRepo.On("AddExpiredPayment", bookingID, now).Return(nil)
+	mockRepo.On("UpdateBookingStatus", bookingID, util.BookingBelumMembayar, util.NotificationBookingConfirmed).Return(nil)
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	err := mockService.UpdateBookingStatus(1, bookingID, util.BookingBelumMembayar)
+	mockRepo.AssertExpectations(t)
+	xenditService.AssertExpectations(t)
+
+	assert.Nil(t, err)
+}
+
+func TestService_ChangeStatusToBookingBelumMembayarFailedInsertInvoice(t *testing.T) {
+	mockRepo := new(MockRepository)
+	xenditService := new(MockXenditService)
+	mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+	bookingID := 1
+
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	now := time.Now().In(loc)
+
+	mockRepo.On("GetDetail", bookingID).Return(Detail{ID: 1, PlaceID: 1}, nil)
+	mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+	mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+	mockRepo.On("GetInvoicesFromBooking", bookingID).Return(false, nil)
+	xenditService.On("CreateInvoice", mock.Anything).Return(&xendit2.Invoice{ID: "test id", ExpiryDate: &now}, nil)
+	mockRepo.On("InsertInvoice", mock.Anything).Return(errors.Wrap(ErrInternalServerError, "test error"))
+	mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+	err := mockService.UpdateBookingStatus(1, bookingID, util.BookingBelumMembayar)
+
+	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+}
+
+func TestService_CreateRemainingInvoice(t *testing.T) {
+	bookingID := 1
+
+	bookingDetailOutput := Detail{
+		ID:                  1,
+		CustomerName:        "test",
+		CustomerPhoneNumber: "test",
+		PlaceID:             1,
+		Status:              util.BookingBerhasil,
+		PaymentType:         util.PaymentTypeDeposit,
+		SplitCount:          1,
+		TotalPriceItem:      80000,
+	}
+
+	paidDeposit := Invoice{ID: 1, BookingID: 1, Type: util.InvoiceTypeDeposit, Amount: 33000, PlatformFee: util.XenditPlatformFee, Status: util.InvoicePaid}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		xenditService := new(MockXenditService)
+		mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		loc, _ := time.LoadLocation("Asia/Bangkok")
+		now := time.Now().In(loc)
+
+		expiredRemaining := Invoice{ID: 2, BookingID: 1, Type: util.InvoiceTypeRemaining, Amount: 70000, Status: util.InvoiceExpired}
+
+		mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, nil)
+		mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+		mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+		mockRepo.On("GetInvoicesByBookingID", bookingID).Return(&[]Invoice{paidDeposit, expiredRemaining}, nil)
+		xenditService.On("CreateInvoice", xendit.CreateInvoiceParams{
+			PlaceID:             1,
+			Items:               []xendit.Item{{Name: "Remaining payment", Price: 70000, Qty: 1}},
+			Description:         "Remaining payment for order from test",
+			CustomerName:        "test",
+			CustomerPhoneNumber: "test",
+			WithoutPlatformFee:  true,
+		}).Return(&xendit2.Invoice{ID: "remaining id", InvoiceURL: "remaining url", ExpiryDate: &now}, nil)
+
+		expectedInvoice := Invoice{
+			BookingID:  bookingID,
+			XenditID:   "remaining id",
+			InvoiceURL: "remaining url",
+			Type:       util.InvoiceTypeRemaining,
+			Amount:     70000,
+			Status:     util.InvoicePending,
+			ExpiredAt:  now,
+		}
+		mockRepo.On("InsertInvoice", expectedInvoice).Return(nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		invoice, err := mockService.CreateRemainingInvoice(1, bookingID)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &expectedInvoice, invoice)
+	})
+
+	t.Run("failed booking id not valid", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		invoice, err := mockService.CreateRemainingInvoice(1, 0)
+
+		assert.Nil(t, invoice)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed booking is not deposit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		fullBooking := bookingDetailOutput
+		fullBooking.PaymentType = util.PaymentTypeFull
+
+		mockRepo.On("GetDetail", bookingID).Return(fullBooking, nil)
+		mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+		mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		invoice, err := mockService.CreateRemainingInvoice(1, bookingID)
+
+		assert.Nil(t, invoice)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed remaining invoice already exists", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		pendingRemaining := Invoice{ID: 2, BookingID: 1, Type: util.InvoiceTypeRemaining, Amount: 70000, Status: util.InvoicePending}
+
+		mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, nil)
+		mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+		mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+		mockRepo.On("GetInvoicesByBookingID", bookingID).Return(&[]Invoice{paidDeposit, pendingRemaining}, nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		invoice, err := mockService.CreateRemainingInvoice(1, bookingID)
+
+		assert.Nil(t, invoice)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get invoices", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, nil)
+		mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+		mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+		mockRepo.On("GetInvoicesByBookingID", bookingID).Return(&[]Invoice{}, errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		invoice, err := mockService.CreateRemainingInvoice(1, bookingID)
+
+		assert.Nil(t, invoice)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed create xendit invoice", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		xenditService := new(MockXenditService)
+		mockService := NewService(mockRepo, xenditService, new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetDetail", bookingID).Return(bookingDetailOutput, nil)
+		mockRepo.On("GetTicketPriceWrapper", bookingID).Return(TicketPriceWrapper{Price: 20000, Fixed: true}, nil)
+		mockRepo.On("GetItemWrapper", bookingID).Return(ItemsWrapper{}, nil)
+		mockRepo.On("GetInvoicesByBookingID", bookingID).Return(&[]Invoice{paidDeposit}, nil)
+		xenditService.On("CreateInvoice", mock.Anything).Return(&xendit2.Invoice{}, errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		invoice, err := mockService.CreateRemainingInvoice(1, bookingID)
+
+		assert.Nil(t, invoice)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetPendingInvoices(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+	expected := []Invoice{{ID: 1, BookingID: 1, XenditID: "xendit-1", Status: util.InvoicePending}}
+	mockRepo.On("GetPendingInvoices").Return(&expected, nil)
+
+	invoices, err := mockService.GetPendingInvoices()
+	mockRepo.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &expected, invoices)
+}
+
+func TestService_GetTicket(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetDetailBookingSaya", 1).Return(DetailBookingSaya{ID: 1, Status: util.BookingBerhasil}, nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		ticket, err := service.GetTicket(1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, ticket.BookingID)
+		assert.Equal(t, signTicket("secret", 1), ticket.Token)
+		assert.Contains(t, ticket.QRCode, "data:image/png;base64,")
+
+		bookingID, err := parseTicket("secret", ticket.Token)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, bookingID)
+	})
+
+	t.Run("failed booking not paid", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetDetailBookingSaya", 1).Return(DetailBookingSaya{ID: 1, Status: util.BookingBelumMembayar}, nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		_, err := service.GetTicket(1, 1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed invalid booking id", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		_, err := service.GetTicket(1, 0)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetTicketPDF(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		items := []Item{
+			{ID: 0, Name: "Harga Booking", Price: 10000, Qty: 1, TotalPrice: 10000},
+			{ID: 999, Name: "Platform Fee", Price: 3000, Qty: 1, TotalPrice: 3000},
+		}
+		invoices := []Invoice{{ID: 1, BookingID: 1, Amount: 13000, Status: util.InvoicePaid}}
+		mockRepo.On("GetDetailBookingSaya", 1).Return(DetailBookingSaya{ID: 1, Status: util.BookingBerhasil, PlaceName: "Kafe", Date: "2022-04-02T00:00:00Z", TotalPrice: 13000}, nil)
+		mockRepo.On("GetItemByBookingID", 1).Return(items, nil)
+		mockRepo.On("GetInvoicesByBookingID", 1).Return(&invoices, nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		file, err := service.GetTicketPDF(1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "e-ticket-1.pdf", file.FileName)
+		assert.Equal(t, util.ApplicationPDF, file.ContentType)
+		assert.Equal(t, "%PDF", string(file.Content[:4]))
+	})
+
+	t.Run("failed booking not paid", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetDetailBookingSaya", 1).Return(DetailBookingSaya{ID: 1, Status: util.BookingGagal}, nil)
+		mockRepo.On("GetItemByBookingID", 1).Return([]Item{}, nil)
+		mockRepo.On("GetInvoicesByBookingID", 1).Return(&[]Invoice{}, nil)
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		_, err := service.GetTicketPDF(1, 1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_CheckIn(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	token := signTicket("secret", 1)
+	information := CheckInInformation{
+		ID:           1,
+		OwnerID:      2,
+		CustomerName: "Tom",
+		Date:         time.Date(2022, 4, 2, 0, 0, 0, 0, time.UTC),
+		StartTime:    "10:00:00",
+		EndTime:      "12:00:00",
+		Capacity:     4,
+		Status:       util.BookingBerhasil,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 2, 9, 45, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		arrivedAt := time.Now()
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+		mockRepo.On("CheckInBooking", 1).Return(&arrivedAt, nil)
+
+		checkIn, err := service.CheckIn(2, token)
+		assert.Nil(t, err)
+		assert.Equal(t, &CheckInResponse{
+			BookingID:    1,
+			CustomerName: "Tom",
+			Date:         "2022-04-02",
+			StartTime:    "10:00:00",
+			EndTime:      "12:00:00",
+			Capacity:     4,
+			ArrivedAt:    arrivedAt,
+		}, checkIn)
+	})
+
+	t.Run("failed invalid signature", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		_, err := service.CheckIn(2, signTicket("another secret", 1))
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+
+		_, err = service.CheckIn(2, "invalid")
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed booking of another place", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+
+		_, err := service.CheckIn(3, token)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+	})
+
+	t.Run("failed too early", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 2, 9, 0, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+
+		_, err := service.CheckIn(2, token)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed wrong date and already checked in", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 3, 10, 0, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		arrivedAt := time.Date(2022, 4, 2, 10, 0, 0, 0, loc)
+		checkedIn := information
+		checkedIn.ArrivedAt = &arrivedAt
+		mockRepo.On("GetCheckInInformation", 1).Return(&checkedIn, nil)
+
+		_, err := service.CheckIn(2, token)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "booking is already checked in")
+		assert.Contains(t, err.Error(), "booking is for 2022-04-02")
+	})
+}
+
+func TestService_CheckInByBookingID(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	information := CheckInInformation{
+		ID:        1,
+		OwnerID:   2,
+		Date:      time.Date(2022, 4, 2, 0, 0, 0, 0, time.UTC),
+		StartTime: "10:00:00",
+		EndTime:   "12:00:00",
+		Status:    util.BookingTidakHadir,
+	}
+
+	t.Run("failed already marked as no-show", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 2, 10, 30, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+
+		_, err := service.CheckInByBookingID(2, 1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "booking is already marked as no-show")
+	})
+
+	t.Run("failed invalid booking id", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		_, err := service.CheckInByBookingID(2, 0)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_MarkNoShow(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Bangkok")
+	information := CheckInInformation{
+		ID:        1,
+		OwnerID:   2,
+		Date:      time.Date(2022, 4, 2, 0, 0, 0, 0, time.UTC),
+		StartTime: "10:00:00",
+		EndTime:   "12:00:00",
+		Status:    util.BookingBerhasil,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 2, 10, 20, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+		mockRepo.On("MarkNoShow", 1).Return(nil)
+
+		err := service.MarkNoShow(2, 1)
+		assert.Nil(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed still in grace period", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 2, 10, 10, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+
+		err := service.MarkNoShow(2, 1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed already checked in", func(t *testing.T) {
+		f := faketime.NewFaketime(2022, 4, 2, 10, 20, 0, 0, loc)
+		defer f.Undo()
+		f.Do()
+
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		arrivedAt := time.Date(2022, 4, 2, 9, 50, 0, 0, loc)
+		checkedIn := information
+		checkedIn.ArrivedAt = &arrivedAt
+		checkedIn.Status = util.BookingSelesai
+		mockRepo.On("GetCheckInInformation", 1).Return(&checkedIn, nil)
+
+		err := service.MarkNoShow(2, 1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed booking of another place", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCheckInInformation", 1).Return(&information, nil)
+
+		err := service.MarkNoShow(3, 1)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+	})
+}
+
+func TestService_NoShowPolicy(t *testing.T) {
+	t.Run("success get", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetNoShowPolicy", 2).Return(&NoShowPolicy{Policy: util.NoShowPolicyBlock, Threshold: 3}, nil)
+
+		policy, err := service.GetNoShowPolicy(2)
+		assert.Nil(t, err)
+		assert.Equal(t, &NoShowPolicy{Policy: util.NoShowPolicyBlock, Threshold: 3}, policy)
+	})
+
+	t.Run("success update", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		policy := NoShowPolicy{Policy: util.NoShowPolicyPrepayment, Threshold: 2}
+		mockRepo.On("UpdateNoShowPolicy", 2, policy).Return(nil)
+
+		err := service.UpdateNoShowPolicy(2, policy)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed update invalid policy", func(t *testing.T) {
+		service := NewService(new(MockRepository), new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		err := service.UpdateNoShowPolicy(2, NoShowPolicy{Policy: 5, Threshold: -1})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+
+		err = service.UpdateNoShowPolicy(2, NoShowPolicy{Policy: util.NoShowPolicyBlock})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_CreateBookingNoShowPolicy(t *testing.T) {
+	input := CreateBookingServiceRequest{
+		Count:       2,
+		PlaceID:     1,
+		UserID:      1,
+		PaymentType: util.PaymentTypeDeposit,
+	}
+
+	t.Run("failed blocked customer", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCustomerNoShowPolicy", 1, 1).Return(&CustomerNoShowPolicy{Policy: util.NoShowPolicyBlock, Threshold: 2, NoShowCount: 2}, nil)
+
+		_, err := service.CreateBooking(input)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+	})
+
+	t.Run("failed prepayment required", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCustomerNoShowPolicy", 1, 1).Return(&CustomerNoShowPolicy{Policy: util.NoShowPolicyPrepayment, Threshold: 2, NoShowCount: 3}, nil)
+
+		_, err := service.CreateBooking(input)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "full payment is required after 3 no-shows")
+	})
+
+	t.Run("failed place not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+
+		mockRepo.On("GetCustomerNoShowPolicy", 1, 1).Return(&CustomerNoShowPolicy{}, ErrNotFound)
+
+		_, err := service.CreateBooking(input)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestService_BookingNotification(t *testing.T) {
+	t.Run("cancelled by business admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), mockNotification, "secret")
+
+		mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingCancelled).Return(nil)
+		mockNotification.On("Notify", notification.Event{Type: util.NotificationBookingCancelled, BookingID: 1})
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		err := mockService.UpdateBookingStatus(1, 1, util.BookingGagal)
+		assert.Nil(t, err)
+		mockNotification.AssertExpectations(t)
+	})
+
+	t.Run("paid", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), mockNotification, "secret")
+
+		invoice := Invoice{ID: 5, BookingID: 10, XenditID: "1", Type: util.InvoiceTypeFull, PlatformFee: util.XenditPlatformFee, Status: util.InvoicePending}
+		mockRepo.On("GetInvoiceByXenditID", "1").Return(&invoice, nil)
+		mockRepo.On("SettleInvoice", 5, util.InvoicePaid, 17000.0, 1).Return(true, nil)
+		mockRepo.On("CountUnpaidRequiredInvoices", 10).Return(0, nil)
+		mockRepo.On("UpdateBookingStatus", 10, util.BookingBerhasil, util.NotificationBookingPaid).Return(nil)
+		mockNotification.On("Notify", notification.Event{Type: util.NotificationBookingPaid, BookingID: 10})
+
+		err := mockService.XenditInvoicesCallback(XenditInvoicesCallback{ID: "1", ExternalID: "1", Status: util.XenditStatusPaid, Amount: 20000.0})
+		assert.Nil(t, err)
+		mockNotification.AssertExpectations(t)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), mockNotification, "secret")
+
+		invoice := Invoice{ID: 5, BookingID: 10, XenditID: "1", Type: util.InvoiceTypeFull, Status: util.InvoicePending}
+		mockRepo.On("GetInvoiceByXenditID", "1").Return(&invoice, nil)
+		mockRepo.On("SettleInvoice", 5, util.InvoiceExpired, 0.0, 1).Return(true, nil)
+		mockRepo.On("CountUnpaidRequiredInvoices", 10).Return(1, nil)
+		mockRepo.On("CountPendingRequiredInvoices", 10).Return(0, nil)
+		mockRepo.On("FailUnpaidBooking", 10, 1).Return(true, nil)
+		mockNotification.On("Notify", notification.Event{Type: util.NotificationBookingExpired, BookingID: 10})
+
+		err := mockService.XenditInvoicesCallback(XenditInvoicesCallback{ID: "1", ExternalID: "1", Status: util.XenditStatusExpired})
+		assert.Nil(t, err)
+		mockNotification.AssertExpectations(t)
+	})
+
+	t.Run("not sent when update failed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), mockNotification, "secret")
+
+		mockRepo.On("UpdateBookingStatus", 1, util.BookingGagal, util.NotificationBookingCancelled).Return(errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetBookingOwnership", mock.Anything).Return(&Ownership{CustomerID: 1, OwnerID: 1}, nil)
+
+		err := mockService.UpdateBookingStatus(1, 1, util.BookingGagal)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		mockNotification.AssertNotCalled(t, "Notify", mock.Anything)
+	})
+}
+
+func TestService_BookingOwnership(t *testing.T) {
+	calls := map[string]struct {
+		status int
+		call   func(s Service, userID int) error
+	}{
+		"GetDetail": {util.StatusBusinessAdmin, func(s Service, userID int) error {
+			_, err := s.GetDetail(userID, 1)
+			return err
+		}},
+		"UpdateBookingStatus": {util.StatusBusinessAdmin, func(s Service, userID int) error {
+			return s.UpdateBookingStatus(userID, 1, util.BookingSelesai)
+		}},
+		"GetDetailBookingSaya": {util.StatusCustomer, func(s Service, userID int) error {
+			_, err := s.GetDetailBookingSaya(userID, 1)
+			return err
+		}},
+		"CreateRemainingInvoice": {util.StatusCustomer, func(s Service, userID int) error {
+			_, err := s.CreateRemainingInvoice(userID, 1)
+			return err
+		}},
+		"GetTicket": {util.StatusCustomer, func(s Service, userID int) error {
+			_, err := s.GetTicket(userID, 1)
+			return err
+		}},
+		"GetTicketPDF": {util.StatusCustomer, func(s Service, userID int) error {
+			_, err := s.GetTicketPDF(userID, 1)
+			return err
+		}},
+	}
+
+	// user 1 is the customer of the booking and user 2 is the owner of the place
+	ownership := &Ownership{BookingID: 1, CustomerID: 1, OwnerID: 2}
+
+	for name, call := range calls {
+		t.Run(name+" booking of another tenant", func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+			mockRepo.On("GetBookingOwnership", 1).Return(ownership, nil)
+
+			err := call.call(mockService, 3)
+			assert.Equal(t, ErrForbidden, errors.Cause(err))
+		})
+
+		t.Run(name+" booking with role of the other side", func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+			mockRepo.On("GetBookingOwnership", 1).Return(ownership, nil)
+
+			// the customer must not pass as the business admin and vice versa
+			userID := ownership.OwnerID
+			if call.status == util.StatusBusinessAdmin {
+				userID = ownership.CustomerID
+			}
+
+			err := call.call(mockService, userID)
+			assert.Equal(t, ErrForbidden, errors.Cause(err))
+		})
+
+		t.Run(name+" booking not found", func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockService := NewService(mockRepo, new(MockXenditService), new(MockPricingService), newMockNotificationService(), "secret")
+			mockRepo.On("GetBookingOwnership", 1).Return(&Ownership{}, errors.Wrap(ErrNotFound, "test error"))
+
+			err := call.call(mockService, 1)
+			assert.Equal(t, ErrNotFound, errors.Cause(err))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package booking
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// signTicket create booking token in form of <bookingID>.<signature>
+func signTicket(secret string, bookingID int) string {
+	return fmt.Sprintf("%d.%s", bookingID, ticketSignature(secret, bookingID))
+}
+
+// parseTicket verify the signature of booking token and return the booking id
+func parseTicket(secret string, token string) (int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return 0, errors.Wrap(ErrInputValidationError, "ticket token is not valid")
+	}
+
+	bookingID, err := strconv.Atoi(parts[0])
+	if err != nil || bookingID <= 0 {
+		return 0, errors.Wrap(ErrInputValidationError, "ticket token is not valid")
+	}
+
+	if !hmac.Equal([]byte(parts[1]), []byte(ticketSignature(secret, bookingID))) {
+		return 0, errors.Wrap(ErrInputValidationError, "ticket token is not valid")
+	}
+
+	return bookingID, nil
+}
+
+func ticketSignature(secret string, bookingID int) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("booking:%d", bookingID)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func encodeTicketPDF(detail DetailBookingSaya, qrCode []byte) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("E-Ticket #%d", detail.ID), false)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, fmt.Sprintf("E-Ticket #%d", detail.ID), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 7, detail.PlaceName, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 7, ticketDate(detail.Date), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 7, fmt.Sprintf("%s - %s", detail.StartTime, detail.EndTime), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	imageOptions := gofpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("qr", imageOptions, bytes.NewReader(qrCode))
+	pdf.ImageOptions("qr", pdf.GetX(), pdf.GetY(), 50, 50, true, imageOptions, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, "Receipt", "", 1, "L", false, 0, "")
+
+	widths := []float64{80, 35, 20, 35}
+	pdf.SetFont("Helvetica", "B", 9)
+	for i, header := range []string{"Item", "Price", "Qty", "Total Price"} {
+		pdf.CellFormat(widths[i], 6, header, "1", 0, "L", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, item := range detail.Items {
+		pdf.CellFormat(widths[0], 6, item.Name, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, ticketPrice(item.Price), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, strconv.Itoa(item.Qty), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, ticketPrice(item.TotalPrice), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 6, "Total", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[3], 6, ticketPrice(detail.TotalPrice), "1", 1, "R", false, 0, "")
+
+	paid := 0.0
+	for _, invoice := range detail.Invoices {
+		if invoice.Status == util.InvoicePaid {
+			paid += invoice.Amount
+		}
+	}
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 6, "Paid", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[3], 6, ticketPrice(paid), "1", 1, "R", false, 0, "")
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func ticketPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64)
+}
+
+// ticketDate trim the time part of booking date
+func ticketDate(date string) string {
+	if len(date) > len(util.DateLayout) {
+		return date[:len(util.DateLayout)]
+	}
+
+	return date
+}
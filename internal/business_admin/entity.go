@@ -11,11 +11,57 @@ type BalanceDetail struct {
 // DisbursementDetail consist related information for disbursement
 type DisbursementDetail struct {
 	ID       int       `json:"id"`
-	PlaceID  int       `json:"place_id"`
+	PlaceID  int       `json:"place_id" db:"place_id"`
 	Date     time.Time `json:"date"`
 	XenditID string    `json:"xendit_id" db:"xendit_id"`
 	Amount   float64   `json:"amount"`
 	Status   int       `json:"status"`
+	// UserID is the owner of the place, it is only filled when the disbursement is looked up by its xendit ID
+	UserID int `json:"-" db:"user_id"`
+
+	// PayoutPeriod and Attempt are only filled for scheduled payout
+	PayoutPeriod string `json:"payout_period" db:"payout_period"`
+	Attempt      int    `json:"attempt"`
+}
+
+// PayoutSchedule for automatic payout setting of business admin
+type PayoutSchedule struct {
+	Schedule       int     `json:"schedule" db:"payout_schedule"`
+	MinimumBalance float64 `json:"minimum_balance" db:"payout_minimum_balance"`
+}
+
+// PayoutDetails is the bank account that receive disbursement of business admin
+type PayoutDetails struct {
+	BankCode          string `json:"bank_code" db:"bank_code"`
+	BankAccountNumber string `json:"bank_account_number" db:"bank_account_number"`
+	BankAccountName   string `json:"bank_account_name" db:"bank_account_name"`
+}
+
+// UpdatePayoutDetailsRequest consist new payout details and current password for re-authentication
+type UpdatePayoutDetailsRequest struct {
+	BankCode          string `json:"bank_code"`
+	BankAccountNumber string `json:"bank_account_number"`
+	BankAccountName   string `json:"bank_account_name"`
+	Password          string `json:"password"`
+}
+
+// ScheduledPayoutOwner consist business admin information needed for scheduled payout
+type ScheduledPayoutOwner struct {
+	InfoForDisbursement
+	Balance        float64 `db:"balance"`
+	Schedule       int     `db:"payout_schedule"`
+	MinimumBalance float64 `db:"payout_minimum_balance"`
+}
+
+// ScheduledPayout is the result of scheduled payout for a business admin
+type ScheduledPayout struct {
+	UserID   int     `json:"user_id"`
+	PlaceID  int     `json:"place_id"`
+	Period   string  `json:"period"`
+	Attempt  int     `json:"attempt"`
+	Amount   float64 `json:"amount"`
+	XenditID string  `json:"xendit_id"`
+	Error    string  `json:"error,omitempty"`
 }
 
 // ListTransaction is a container for transaction history of customers
@@ -41,6 +87,41 @@ type ListTransactionRequest struct {
 	UserID int    `json:"user_id"`
 }
 
+// ReportRequest consists of request data for exporting report
+type ReportRequest struct {
+	UserID    int    `json:"user_id"`
+	Report    string `json:"report"`
+	Format    string `json:"format"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// ReportFile is an exported report ready to be downloaded
+type ReportFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// ItemSales consist total sales of an item
+type ItemSales struct {
+	Name       string  `json:"name"`
+	Qty        int     `json:"qty"`
+	TotalPrice float64 `json:"total_price" db:"total_price"`
+}
+
+// MonthlyStatement consist summary of transaction and disbursement of a business admin in a month
+type MonthlyStatement struct {
+	Period            string
+	Name              string
+	Transactions      []Transaction
+	Disbursements     []DisbursementDetail
+	GrossIncome       float64
+	PlatformFee       float64
+	NetIncome         float64
+	TotalDisbursement float64
+}
+
 // CreateDisbursementResponse for create disbursement response entity
 type CreateDisbursementResponse struct {
 	ID        int       `json:"place_id"`
@@ -54,6 +135,7 @@ type InfoForDisbursement struct {
 	ID                int    `json:"id"`
 	Name              string `json:"name"`
 	Email             string `json:"email"`
+	BankCode          string `json:"bank_code" db:"bank_code"`
 	BankAccountName   string `json:"bank_account_name" db:"bank_account_name"`
 	BankAccountNumber string `json:"bank_account_number" db:"bank_account_number"`
 	PlaceID           int    `json:"place_id" db:"place_id"`
@@ -9,6 +9,15 @@ var (
 	// ErrInputValidationError is used if there is input validation error of client given data
 	ErrInputValidationError = errors.New("input validation error")
 
+	// ErrUnauthorized is used if re-authentication of business admin failed
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrNotFound is used if resource not found
+	ErrNotFound = errors.New("not found")
+
+	// ErrForbidden is used if the resource is not belong to the place of business admin
+	ErrForbidden = errors.New("forbidden")
+
 	// ErrInternalServer is returned when the server encounters an internal error
 	ErrInternalServer = errors.New("internal server error")
 )
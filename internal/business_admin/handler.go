@@ -1,6 +1,7 @@
 package businessadmin
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -159,11 +160,9 @@ func (h *Handler) XenditDisbursementCallback(c echo.Context) error {
 func (h *Handler) GetTransactionHistoryDetail(c echo.Context) error {
 	errorList := []string{}
 
-	_, _, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
-	if err != nil {
-		if errors.Cause(err) == middleware.ErrForbidden {
-			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
-		}
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil || user == nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, middleware.ErrForbidden, "user is not registered business admin")
 	}
 
 	bookinIDString := c.Param("bookingID")
@@ -176,10 +175,15 @@ func (h *Handler) GetTransactionHistoryDetail(c echo.Context) error {
 		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
 	}
 
-	transactionHistoryDetail, err := h.service.GetTransactionHistoryDetail(bookingID)
+	transactionHistoryDetail, err := h.service.GetTransactionHistoryDetail(user.ID, bookingID)
 	if err != nil {
-		if errors.Cause(err) == ErrInputValidationError {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
 			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrForbidden:
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
 		}
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
 	}
@@ -296,3 +300,181 @@ func (h *Handler) GetListReviewAndRatingWithPagination(c echo.Context) error {
 		},
 	})
 }
+
+// GetReport is a handler for API request to download transaction, item sales or disbursement report
+func (h *Handler) GetReport(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	params := ReportRequest{
+		UserID:    user.ID,
+		Report:    c.QueryParam("report"),
+		Format:    c.QueryParam("format"),
+		StartDate: c.QueryParam("start_date"),
+		EndDate:   c.QueryParam("end_date"),
+	}
+
+	if params.Format == "" {
+		params.Format = util.ReportFormatCSV
+	}
+
+	file, err := h.service.GetReport(params)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	return c.Blob(http.StatusOK, file.ContentType, file.Content)
+}
+
+// GetMonthlyStatement is a handler for API request to download monthly statement in pdf
+func (h *Handler) GetMonthlyStatement(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	file, err := h.service.GetMonthlyStatement(user.ID, c.QueryParam("month"))
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	return c.Blob(http.StatusOK, file.ContentType, file.Content)
+}
+
+// GetPayoutSchedule is a handler for API request to get automatic payout setting
+func (h *Handler) GetPayoutSchedule(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	schedule, err := h.service.GetPayoutSchedule(user.ID)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    schedule,
+	})
+}
+
+// UpdatePayoutSchedule is a handler for API request to update automatic payout setting
+func (h *Handler) UpdatePayoutSchedule(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	var params PayoutSchedule
+	if err := c.Bind(&params); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, errors.Wrap(ErrInputValidationError, "invalid body"), err.Error())
+	}
+
+	err = h.service.UpdatePayoutSchedule(user.ID, params)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    params,
+	})
+}
+
+// GetPayoutDetails is a handler for API request to get bank account that receive disbursement
+func (h *Handler) GetPayoutDetails(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	details, err := h.service.GetPayoutDetails(user.ID)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		if errors.Cause(err) == ErrNotFound {
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    details,
+	})
+}
+
+// UpdatePayoutDetails is a handler for API request to update bank account that receive disbursement
+func (h *Handler) UpdatePayoutDetails(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	var params UpdatePayoutDetailsRequest
+	if err := c.Bind(&params); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, errors.Wrap(ErrInputValidationError, "invalid body"), err.Error())
+	}
+
+	details, err := h.service.UpdatePayoutDetails(user.ID, params)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		if errors.Cause(err) == ErrUnauthorized {
+			return util.ErrorWrapWithContext(c, http.StatusUnauthorized, err)
+		}
+
+		if errors.Cause(err) == ErrNotFound {
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    details,
+	})
+}
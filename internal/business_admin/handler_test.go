@@ -49,8 +49,48 @@ func (m *MockService) DisbursementCallbackFromXendit(params DisbursementCallback
 	return args.Error(0)
 }
 
-func (m *MockService) GetTransactionHistoryDetail(bookingID int) (*TransactionHistoryDetail, error) {
-	args := m.Called(bookingID)
+func (m *MockService) GetPendingDisbursements() (*[]DisbursementDetail, error) {
+	args := m.Called()
+	return args.Get(0).(*[]DisbursementDetail), args.Error(1)
+}
+
+func (m *MockService) GetPayoutSchedule(userID int) (*PayoutSchedule, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*PayoutSchedule), args.Error(1)
+}
+
+func (m *MockService) UpdatePayoutSchedule(userID int, params PayoutSchedule) error {
+	args := m.Called(userID, params)
+	return args.Error(0)
+}
+
+func (m *MockService) GetPayoutDetails(userID int) (*PayoutDetails, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*PayoutDetails), args.Error(1)
+}
+
+func (m *MockService) UpdatePayoutDetails(userID int, params UpdatePayoutDetailsRequest) (*PayoutDetails, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).(*PayoutDetails), args.Error(1)
+}
+
+func (m *MockService) GetReport(params ReportRequest) (*ReportFile, error) {
+	args := m.Called(params)
+	return args.Get(0).(*ReportFile), args.Error(1)
+}
+
+func (m *MockService) GetMonthlyStatement(userID int, month string) (*ReportFile, error) {
+	args := m.Called(userID, month)
+	return args.Get(0).(*ReportFile), args.Error(1)
+}
+
+func (m *MockService) RunScheduledPayouts(now time.Time) (*[]ScheduledPayout, error) {
+	args := m.Called(now)
+	return args.Get(0).(*[]ScheduledPayout), args.Error(1)
+}
+
+func (m *MockService) GetTransactionHistoryDetail(userID int, bookingID int) (*TransactionHistoryDetail, error) {
+	args := m.Called(userID, bookingID)
 	ret := args.Get(0).(*TransactionHistoryDetail)
 	return ret, args.Error(1)
 }
@@ -1542,7 +1582,7 @@ func TestHandler_GetTransactionHistoryDetailSuccess(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("GetTransactionHistoryDetail", bookingID).Return(&transactionHistoryDetail, nil)
+	mockService.On("GetTransactionHistoryDetail", userModel.ID, bookingID).Return(&transactionHistoryDetail, nil)
 
 	// Tes
 	if assert.NoError(t, h.GetTransactionHistoryDetail(c)) {
@@ -1611,7 +1651,7 @@ func TestHandler_GetTransactionHistoryDetailParseUserDataError(t *testing.T) {
 
 	// Excpectation
 	var transactionHistoryDetail TransactionHistoryDetail
-	mockService.On("GetTransactionHistoryDetail", userModel.ID).Return(&transactionHistoryDetail, nil)
+	mockService.On("GetTransactionHistoryDetail", userModel.ID, 1).Return(&transactionHistoryDetail, nil)
 
 	// Tes
 	util.ErrorHandler(h.GetTransactionHistoryDetail(c), c)
@@ -1764,7 +1804,7 @@ func TestHandler_GetTransactionHistoryDetailWithBookingIDBelowOne(t *testing.T)
 
 	// Excpectation
 	var transactionHistoryDetail TransactionHistoryDetail
-	mockService.On("GetTransactionHistoryDetail", bookingID).Return(&transactionHistoryDetail, errorFromService)
+	mockService.On("GetTransactionHistoryDetail", userModel.ID, bookingID).Return(&transactionHistoryDetail, errorFromService)
 
 	response := h.GetTransactionHistoryDetail(c)
 	util.ErrorHandler(response, c)
@@ -1846,7 +1886,7 @@ func TestHandler_GetTransactionHistoryDetailInternalServerError(t *testing.T) {
 
 	// Excpectation
 	var transactionHistoryDetail TransactionHistoryDetail
-	mockService.On("GetTransactionHistoryDetail", bookingID).Return(&transactionHistoryDetail, errorFromService)
+	mockService.On("GetTransactionHistoryDetail", userModel.ID, bookingID).Return(&transactionHistoryDetail, errorFromService)
 
 	response := h.GetTransactionHistoryDetail(c)
 	util.ErrorHandler(response, c)
@@ -1856,6 +1896,41 @@ func TestHandler_GetTransactionHistoryDetailInternalServerError(t *testing.T) {
 	assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
 }
 
+func TestHandler_GetTransactionHistoryDetailOwnership(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expectedCode int
+	}{
+		"booking of another place": {errors.Wrap(ErrForbidden, "test error"), http.StatusForbidden},
+		"booking not found":        {errors.Wrap(ErrNotFound, "test error"), http.StatusNotFound},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/api/v1/business-admin/transaction-history/:bookingID")
+			c.SetParamNames("bookingID")
+			c.SetParamValues("10")
+			c.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+				Users: []firebaseauth.User{
+					{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "password"}}},
+				},
+			})
+			c.Set("userFromDatabase", &user.Model{ID: 1})
+
+			mockService := new(MockService)
+			h := NewHandler(mockService)
+			mockService.On("GetTransactionHistoryDetail", 1, 10).Return(&TransactionHistoryDetail{}, test.err)
+
+			util.ErrorHandler(h.GetTransactionHistoryDetail(c), c)
+			assert.Equal(t, test.expectedCode, rec.Code)
+		})
+	}
+}
+
 func TestHandler_PutEditProfileSuccess(t *testing.T) {
 	// Setup User Model
 	userData := firebaseauth.UserDataFromToken{
@@ -2879,3 +2954,356 @@ func TestHandler_GetListReviewAndRatingWithPaginationQueryParamEmpty(t *testing.
 		assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
 	}
 }
+
+func newPayoutScheduleContext(method string, body string, providerID string) (echo.Context, *httptest.ResponseRecorder) {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID:          "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: providerID}},
+			},
+		},
+	}
+
+	userModel := user.Model{ID: 1, Status: util.StatusBusinessAdmin}
+
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/business-admin/payout-schedule")
+	ctx.Set("userFromDatabase", &userModel)
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx, rec
+}
+
+func TestHandler_GetPayoutSchedule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		schedule := PayoutSchedule{Schedule: util.PayoutWeekly, MinimumBalance: 50000}
+		expectedResponse, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    schedule,
+		})
+
+		mockService.On("GetPayoutSchedule", 1).Return(&schedule, nil)
+
+		if assert.NoError(t, h.GetPayoutSchedule(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, string(expectedResponse), strings.TrimSuffix(rec.Body.String(), "\n"))
+		}
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetPayoutSchedule(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetPayoutSchedule", 1).Return(&PayoutSchedule{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetPayoutSchedule(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_UpdatePayoutSchedule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, `{"schedule": 1, "minimum_balance": 50000}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePayoutSchedule", 1, PayoutSchedule{Schedule: util.PayoutWeekly, MinimumBalance: 50000}).Return(nil)
+
+		if assert.NoError(t, h.UpdatePayoutSchedule(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed to bind request body", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, `{"schedule": "weekly"}`, "password")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.UpdatePayoutSchedule(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, `{"schedule": 5}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePayoutSchedule", 1, PayoutSchedule{Schedule: 5}).Return(errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.UpdatePayoutSchedule(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, `{"schedule": 1}`, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePayoutSchedule", 1, PayoutSchedule{Schedule: util.PayoutWeekly}).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.UpdatePayoutSchedule(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetPayoutDetails(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		details := PayoutDetails{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "TEST"}
+		expectedResponse, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    details,
+		})
+
+		mockService.On("GetPayoutDetails", 1).Return(&details, nil)
+
+		if assert.NoError(t, h.GetPayoutDetails(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, string(expectedResponse), strings.TrimSuffix(rec.Body.String(), "\n"))
+		}
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetPayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetPayoutDetails", 1).Return(&PayoutDetails{}, errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.GetPayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodGet, "", "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetPayoutDetails", 1).Return(&PayoutDetails{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetPayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_UpdatePayoutDetails(t *testing.T) {
+	body := `{"bank_code": "BNI", "bank_account_number": "009-123456789", "bank_account_name": "TEST", "password": "password"}`
+	params := UpdatePayoutDetailsRequest{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "TEST", Password: "password"}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, body, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		details := PayoutDetails{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "TEST"}
+		mockService.On("UpdatePayoutDetails", 1, params).Return(&details, nil)
+
+		if assert.NoError(t, h.UpdatePayoutDetails(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "password")
+		}
+	})
+
+	t.Run("failed to bind request body", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, `{"bank_code": 1}`, "password")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.UpdatePayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, body, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePayoutDetails", 1, params).Return(&PayoutDetails{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.UpdatePayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed wrong password", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, body, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePayoutDetails", 1, params).Return(&PayoutDetails{}, errors.Wrap(ErrUnauthorized, "test error"))
+
+		util.ErrorHandler(h.UpdatePayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newPayoutScheduleContext(http.MethodPut, body, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePayoutDetails", 1, params).Return(&PayoutDetails{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.UpdatePayoutDetails(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func newReportContext(target string, providerID string) (echo.Context, *httptest.ResponseRecorder) {
+	ctx, rec := newPayoutScheduleContext(http.MethodGet, "", providerID)
+	ctx.SetRequest(httptest.NewRequest(http.MethodGet, target, nil))
+
+	return ctx, rec
+}
+
+func TestHandler_GetReport(t *testing.T) {
+	params := ReportRequest{UserID: 1, Report: util.ReportTransactions, Format: util.ReportFormatCSV, StartDate: "2022-05-01", EndDate: "2022-05-31"}
+	target := "/api/v1/business-admin/reports?report=transactions&start_date=2022-05-01&end_date=2022-05-31"
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		file := ReportFile{FileName: "transactions_2022-05-01_2022-05-31.csv", ContentType: util.TextCSV, Content: []byte("ID\n")}
+		mockService.On("GetReport", params).Return(&file, nil)
+
+		if assert.NoError(t, h.GetReport(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, util.TextCSV, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, `attachment; filename="transactions_2022-05-01_2022-05-31.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, "ID\n", rec.Body.String())
+		}
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetReport(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetReport", params).Return(&ReportFile{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.GetReport(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetReport", params).Return(&ReportFile{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetReport(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetMonthlyStatement(t *testing.T) {
+	target := "/api/v1/business-admin/reports/statement?month=2022-05"
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		file := ReportFile{FileName: "statement_2022-05.pdf", ContentType: util.ApplicationPDF, Content: []byte("%PDF")}
+		mockService.On("GetMonthlyStatement", 1, "2022-05").Return(&file, nil)
+
+		if assert.NoError(t, h.GetMonthlyStatement(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, util.ApplicationPDF, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, `attachment; filename="statement_2022-05.pdf"`, rec.Header().Get(echo.HeaderContentDisposition))
+		}
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetMonthlyStatement(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetMonthlyStatement", 1, "2022-05").Return(&ReportFile{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.GetMonthlyStatement(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newReportContext(target, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetMonthlyStatement", 1, "2022-05").Return(&ReportFile{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetMonthlyStatement(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
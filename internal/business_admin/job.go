@@ -0,0 +1,41 @@
+package businessadmin
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// PayoutJob run the scheduled payout periodically in background
+type PayoutJob struct {
+	*util.Job
+	service Service
+}
+
+// NewPayoutJob for initialize scheduled payout job
+func NewPayoutJob(service Service, interval time.Duration) *PayoutJob {
+	job := &PayoutJob{service: service}
+	job.Job = util.NewJob(interval, func() { job.Run(time.Now()) })
+	return job
+}
+
+// Run a single scheduled payout and log the created disbursement
+func (j *PayoutJob) Run(now time.Time) *[]ScheduledPayout {
+	payouts, err := j.service.RunScheduledPayouts(now)
+	if err != nil {
+		logrus.Errorf("[scheduled payout] failed: %v", err)
+		return nil
+	}
+
+	for _, payout := range *payouts {
+		if payout.Error != "" {
+			logrus.Errorf("[scheduled payout] place %d period %s attempt %d: %s", payout.PlaceID, payout.Period, payout.Attempt, payout.Error)
+			continue
+		}
+
+		logrus.Infof("[scheduled payout] place %d period %s attempt %d: disbursement %s created", payout.PlaceID, payout.Period, payout.Attempt, payout.XenditID)
+	}
+
+	return payouts
+}
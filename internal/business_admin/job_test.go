@@ -0,0 +1,35 @@
+package businessadmin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayoutJob_Run(t *testing.T) {
+	now := time.Date(2022, 5, 4, 10, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		job := NewPayoutJob(mockService, time.Minute)
+
+		payouts := []ScheduledPayout{
+			{UserID: 1, PlaceID: 2, Period: "2022-W18", Attempt: 1, XenditID: "xendit id"},
+			{UserID: 3, PlaceID: 4, Period: "2022-W18", Attempt: 2, Error: "test error"},
+		}
+		mockService.On("RunScheduledPayouts", now).Return(&payouts, nil)
+
+		assert.Equal(t, &payouts, job.Run(now))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mockService := new(MockService)
+		job := NewPayoutJob(mockService, time.Minute)
+
+		mockService.On("RunScheduledPayouts", now).Return(&[]ScheduledPayout{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		assert.Nil(t, job.Run(now))
+	})
+}
@@ -2,10 +2,13 @@ package businessadmin
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // Repo will contain all the function that can be used by repo
@@ -16,14 +19,36 @@ type Repo interface {
 	GetListTransactionsHistoryWithPagination(params ListTransactionRequest) (*ListTransaction, error)
 	GetBusinessAdminInformation(userID int) (*InfoForDisbursement, error)
 	SaveDisbursement(disbursement DisbursementDetail) (int, error)
-	UpdateBalance(float64, int) error
-	UpdateDisbursementStatusByXenditID(int, string) error
+	SettleDisbursement(xenditID string, status int, debit float64, userID int, event notification.Event) (bool, error)
+	GetDisbursementByXenditID(string) (*DisbursementDetail, error)
+	GetPendingDisbursements() (*[]DisbursementDetail, error)
+	GetPayoutSchedule(userID int) (*PayoutSchedule, error)
+	UpdatePayoutSchedule(userID int, schedule PayoutSchedule) error
+	GetScheduledPayoutOwners() (*[]ScheduledPayoutOwner, error)
+	GetLatestPayout(placeID int, period string) (*DisbursementDetail, error)
+	CountPendingDisbursements(placeID int) (int, error)
+	GetLastDisbursementID(placeID int) (int, error)
+	GetPayoutDetails(userID int) (*PayoutDetails, error)
+	UpdatePayoutDetails(userID int, details PayoutDetails) error
+	GetPasswordByUserID(userID int) (string, error)
+	CheckIfBankAccountIsUsed(userID int, bankAccountNumber string) (bool, error)
+	GetTransactionsByDateRange(userID int, startDate, endDate string) (*[]Transaction, error)
+	GetItemSalesByDateRange(userID int, startDate, endDate string) (*[]ItemSales, error)
+	GetDisbursementsByDateRange(placeID int, startDate, endDate string) (*[]DisbursementDetail, error)
+	GetPlatformFeeByDateRange(userID int, startDate, endDate string) (float64, error)
+	GetBookingOwnerID(bookingID int) (int, error)
 	GetTransactionHistoryDetail(int) (*TransactionHistoryDetail, error)
 	GetItemsWrapper(int) (*ItemsWrapper, error)
 	GetCustomerForTransactionHistoryDetail(int) (*CustomerForTrasactionHistoryDetail, error)
 	UpdateProfile(EditProfileRequest) error
 }
 
+// transactionHistoryQuery select successful bookings of a business admin, it is shared by transaction history and report
+const transactionHistoryQuery = `
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
+	FROM bookings b, users u, places p
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)`
+
 type repo struct {
 	db *sqlx.DB
 }
@@ -35,11 +60,94 @@ func NewRepo(db *sqlx.DB) Repo {
 	}
 }
 
-func (r *repo) UpdateBalance(newAmount float64, userID int) error {
-	query := "UPDATE business_owners SET balance = $1 " +
-		"WHERE user_id= $2"
+// SettleDisbursement change the status of pending disbursement, deduct the balance of the business admin and record
+// the event in a transaction, false is returned when the disbursement is already settled so it is never deducted twice
+func (r *repo) SettleDisbursement(xenditID string, status int, debit float64, userID int, event notification.Event) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var settledID int
+	query := "UPDATE disbursements SET status = $1 WHERE xendit_id = $2 AND status = $3 RETURNING id"
+	err = tx.Get(&settledID, query, status, xenditID, util.XenditDisbursementPending)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if debit > 0 {
+		query = "UPDATE business_owners SET balance = balance - $1 WHERE user_id = $2"
+		_, err = tx.Exec(query, debit, userID)
+		if err != nil {
+			return false, errors.Wrap(ErrInternalServerError, err.Error())
+		}
+	}
+
+	err = notification.InsertEvent(tx, event)
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return true, nil
+}
+
+func (r *repo) GetDisbursementByXenditID(xenditID string) (*DisbursementDetail, error) {
+	var result DisbursementDetail
+
+	query := `SELECT d.id, d.place_id, p.user_id, d.date, d.xendit_id, d.amount, d.status
+			FROM disbursements d
+			INNER JOIN places p ON p.id = d.place_id
+			WHERE d.xendit_id = $1`
+	err := r.db.Get(&result, query, xenditID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("disbursement with xendit_id = %s is not found", xenditID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) GetPendingDisbursements() (*[]DisbursementDetail, error) {
+	result := make([]DisbursementDetail, 0)
+
+	query := "SELECT id, place_id, date, xendit_id, amount, status FROM disbursements WHERE status = $1 ORDER BY id"
+	err := r.db.Select(&result, query, util.XenditDisbursementPending)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) GetPayoutSchedule(userID int) (*PayoutSchedule, error) {
+	var result PayoutSchedule
 
-	_, err := r.db.Exec(query, newAmount, userID)
+	query := "SELECT payout_schedule, payout_minimum_balance FROM business_owners WHERE user_id = $1"
+	err := r.db.Get(&result, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) UpdatePayoutSchedule(userID int, schedule PayoutSchedule) error {
+	query := "UPDATE business_owners SET payout_schedule = $1, payout_minimum_balance = $2, updated_at = NOW() WHERE user_id = $3"
+
+	_, err := r.db.Exec(query, schedule.Schedule, schedule.MinimumBalance, userID)
 	if err != nil {
 		return errors.Wrap(ErrInternalServerError, err.Error())
 	}
@@ -47,11 +155,26 @@ func (r *repo) UpdateBalance(newAmount float64, userID int) error {
 	return nil
 }
 
-func (r *repo) UpdateDisbursementStatusByXenditID(newStatus int, xenditID string) error {
-	query := "UPDATE disbursements as d SET status = $1 " +
-		"WHERE xendit_id = $2"
+func (r *repo) GetPayoutDetails(userID int) (*PayoutDetails, error) {
+	var result PayoutDetails
+
+	query := "SELECT bank_code, bank_account_number, bank_account_name FROM business_owners WHERE user_id = $1"
+	err := r.db.Get(&result, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, "business admin is not found")
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) UpdatePayoutDetails(userID int, details PayoutDetails) error {
+	query := "UPDATE business_owners SET bank_code = $1, bank_account_number = $2, bank_account_name = $3, updated_at = NOW() WHERE user_id = $4"
 
-	_, err := r.db.Exec(query, newStatus, xenditID)
+	_, err := r.db.Exec(query, details.BankCode, details.BankAccountNumber, details.BankAccountName, userID)
 	if err != nil {
 		return errors.Wrap(ErrInternalServerError, err.Error())
 	}
@@ -59,10 +182,99 @@ func (r *repo) UpdateDisbursementStatusByXenditID(newStatus int, xenditID string
 	return nil
 }
 
+func (r *repo) GetPasswordByUserID(userID int) (string, error) {
+	var password string
+
+	query := "SELECT password FROM users WHERE id = $1"
+	err := r.db.Get(&password, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.Wrap(ErrNotFound, "business admin is not found")
+		}
+
+		return "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return password, nil
+}
+
+// CheckIfBankAccountIsUsed returns true if the bank account is registered by another business admin
+func (r *repo) CheckIfBankAccountIsUsed(userID int, bankAccountNumber string) (bool, error) {
+	var count int
+
+	query := "SELECT COUNT(id) FROM business_owners WHERE bank_account_number = $1 AND user_id <> $2"
+	err := r.db.Get(&count, query, bankAccountNumber, userID)
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return count > 0, nil
+}
+
+func (r *repo) GetScheduledPayoutOwners() (*[]ScheduledPayoutOwner, error) {
+	result := make([]ScheduledPayoutOwner, 0)
+
+	query := "SELECT u.id, u.name, u.email, b.bank_code, b.bank_account_number, b.bank_account_name, p.id as place_id, " +
+		"b.balance, b.payout_schedule, b.payout_minimum_balance " +
+		"FROM users as u " +
+		"JOIN business_owners as b ON b.user_id = u.id " +
+		"JOIN places as p ON p.user_id = u.id " +
+		"WHERE b.payout_schedule <> $1 ORDER BY u.id"
+
+	err := r.db.Select(&result, query, util.PayoutManual)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) GetLatestPayout(placeID int, period string) (*DisbursementDetail, error) {
+	var result DisbursementDetail
+
+	query := "SELECT id, place_id, date, xendit_id, amount, status, payout_period, attempt FROM disbursements " +
+		"WHERE place_id = $1 AND payout_period = $2 ORDER BY attempt DESC LIMIT 1"
+	err := r.db.Get(&result, query, placeID, period)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("payout for period %s is not found", period))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) CountPendingDisbursements(placeID int) (int, error) {
+	var result int
+
+	query := "SELECT COUNT(id) FROM disbursements WHERE place_id = $1 AND status = $2"
+	err := r.db.Get(&result, query, placeID, util.XenditDisbursementPending)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return result, nil
+}
+
+// GetLastDisbursementID return the ID of the latest disbursement of the place whatever its status, 0 when there is none
+func (r *repo) GetLastDisbursementID(placeID int) (int, error) {
+	var result int
+
+	query := "SELECT COALESCE(MAX(id), 0) FROM disbursements WHERE place_id = $1"
+	err := r.db.Get(&result, query, placeID)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return result, nil
+}
+
 func (r *repo) GetBusinessAdminInformation(userID int) (*InfoForDisbursement, error) {
 	var disbursementInfo InfoForDisbursement
 
-	query := "SELECT u.id, u.name, u.email, b.bank_account_number, b.bank_account_name, p.id as place_id " +
+	query := "SELECT u.id, u.name, u.email, b.bank_code, b.bank_account_number, b.bank_account_name, p.id as place_id " +
 		"FROM users as u " +
 		"JOIN business_owners as b ON b.user_id = u.id " +
 		"JOIN places as p ON p.user_id = u.id " +
@@ -79,11 +291,11 @@ func (r *repo) GetBusinessAdminInformation(userID int) (*InfoForDisbursement, er
 func (r *repo) SaveDisbursement(disbursement DisbursementDetail) (int, error) {
 	var lastInsertedID int
 
-	query := "INSERT INTO disbursements (place_id, date, xendit_id, amount, status) " +
-		"VALUES ($1, $2, $3, $4, $5) " +
+	query := "INSERT INTO disbursements (place_id, date, xendit_id, amount, status, payout_period, attempt) " +
+		"VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7) " +
 		"RETURNING id"
 
-	if err := r.db.QueryRow(query, disbursement.PlaceID, disbursement.Date, disbursement.XenditID, disbursement.Amount, disbursement.Status).Scan(&lastInsertedID); err != nil {
+	if err := r.db.QueryRow(query, disbursement.PlaceID, disbursement.Date, disbursement.XenditID, disbursement.Amount, disbursement.Status, disbursement.PayoutPeriod, disbursement.Attempt).Scan(&lastInsertedID); err != nil {
 		return 0, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
@@ -139,10 +351,7 @@ func (r *repo) GetListTransactionsHistoryWithPagination(params ListTransactionRe
 	listTransaction.Transactions = make([]Transaction, 0)
 	listTransaction.TotalCount = 0
 
-	query := `
-	SELECT b.id, u.name, u.image, b.total_price + p.booking_price as total_price, b.date
-	FROM bookings b, users u, places p
-	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5)
+	query := transactionHistoryQuery + `
 	ORDER BY b.date DESC LIMIT $2 OFFSET $3
 	`
 
@@ -157,7 +366,7 @@ func (r *repo) GetListTransactionsHistoryWithPagination(params ListTransactionRe
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	query = "SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5)"
+	query = "SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)"
 
 	err = r.db.Get(&listTransaction.TotalCount, query, params.UserID)
 	if err != nil {
@@ -172,6 +381,94 @@ func (r *repo) GetListTransactionsHistoryWithPagination(params ListTransactionRe
 	return &listTransaction, nil
 }
 
+func (r *repo) GetTransactionsByDateRange(userID int, startDate, endDate string) (*[]Transaction, error) {
+	result := make([]Transaction, 0)
+
+	query := transactionHistoryQuery + `
+	AND b.date BETWEEN $2 AND $3
+	ORDER BY b.date, b.id
+	`
+
+	err := r.db.Select(&result, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) GetItemSalesByDateRange(userID int, startDate, endDate string) (*[]ItemSales, error) {
+	result := make([]ItemSales, 0)
+
+	query := `
+	SELECT i.name, SUM(bi.qty) as qty, SUM(bi.total_price) as total_price
+	FROM bookings b
+	INNER JOIN booking_items bi ON b.id = bi.booking_id
+	INNER JOIN items i ON bi.item_id = i.id
+	INNER JOIN places p ON b.place_id = p.id
+	WHERE p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6) AND b.date BETWEEN $2 AND $3
+	GROUP BY i.name
+	ORDER BY i.name
+	`
+
+	err := r.db.Select(&result, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) GetDisbursementsByDateRange(placeID int, startDate, endDate string) (*[]DisbursementDetail, error) {
+	result := make([]DisbursementDetail, 0)
+
+	query := "SELECT id, place_id, date, xendit_id, amount, status, COALESCE(payout_period, '') as payout_period, attempt FROM disbursements " +
+		"WHERE place_id = $1 AND date::date BETWEEN $2 AND $3 ORDER BY date, id"
+
+	err := r.db.Select(&result, query, placeID, startDate, endDate)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r *repo) GetPlatformFeeByDateRange(userID int, startDate, endDate string) (float64, error) {
+	var result float64
+
+	query := `
+	SELECT COALESCE(SUM(bi.platform_fee), 0)
+	FROM booking_invoices bi
+	INNER JOIN bookings b ON bi.booking_id = b.id
+	INNER JOIN places p ON b.place_id = p.id
+	WHERE p.user_id = $1 AND bi.status = $2 AND b.date BETWEEN $3 AND $4
+	`
+
+	err := r.db.Get(&result, query, userID, util.InvoicePaid, startDate, endDate)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return result, nil
+}
+
+// GetBookingOwnerID return the business admin who own the place of the booking
+func (r *repo) GetBookingOwnerID(bookingID int) (int, error) {
+	var ownerID int
+
+	query := "SELECT p.user_id FROM bookings b INNER JOIN places p ON p.id = b.place_id WHERE b.id = $1"
+	err := r.db.Get(&ownerID, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id %d is not found", bookingID))
+		}
+
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return ownerID, nil
+}
+
 func (r *repo) GetTransactionHistoryDetail(bookingID int) (*TransactionHistoryDetail, error) {
 	var transactionHistoryDetail TransactionHistoryDetail
 
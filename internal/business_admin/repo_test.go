@@ -11,6 +11,8 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 func TestRepo_GetLatestDisbursementSuccess(t *testing.T) {
@@ -264,15 +266,15 @@ func TestRepo_GetListTransactionsHistoryWithPaginationSuccess(t *testing.T) {
 			listTransactionExpected.Transactions[1].Price,
 			listTransactionExpected.Transactions[1].Date)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT b.id, u.name, u.image, b.total_price + p.booking_price as total_price, b.date
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
 	FROM bookings b, users u, places p
-	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5)
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
 	ORDER BY b.date DESC LIMIT $2 OFFSET $3`)).
 		WithArgs(params.UserID, params.Limit, (params.Page-1)*params.Limit).
 		WillReturnRows(rows)
 
 	rows = mock.NewRows([]string{"count"}).AddRow(10)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5)")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)")).
 		WithArgs(params.UserID).
 		WillReturnRows(rows)
 
@@ -301,9 +303,9 @@ func TestRepo_GetListTransactionsHistoryWithPaginationError(t *testing.T) {
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 	repoMock := NewRepo(sqlxDB)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT b.id, u.name, u.image, b.total_price + p.booking_price as total_price, b.date
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
 	FROM bookings b, users u, places p
-	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5)
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
 	ORDER BY b.date DESC LIMIT $2 OFFSET $3`)).
 		WithArgs(params.UserID, params.Limit, (params.Page-1)*params.Limit).
 		WillReturnError(sql.ErrTxDone)
@@ -336,13 +338,13 @@ func TestRepo_GetListTransactionsHistoryWithPaginationCountError(t *testing.T) {
 		NewRows([]string{"id", "name", "image", "total_price", "date"}).
 		AddRow("1", "test name", "image", 10, "date")
 	mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT b.id, u.name, u.image, b.total_price + p.booking_price as total_price, b.date
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
 	FROM bookings b, users u, places p
-	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5)
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
 	ORDER BY b.date DESC LIMIT $2 OFFSET $3`)).
 		WithArgs(params.UserID, params.Limit, (params.Page-1)*params.Limit).
 		WillReturnRows(rows)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5)")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)")).
 		WithArgs(params.UserID).
 		WillReturnError(sql.ErrConnDone)
 
@@ -375,9 +377,9 @@ func TestRepo_GetListTransactionsHistoryWithPaginationEmpty(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT b.id, u.name, u.image, b.total_price + p.booking_price as total_price, b.date
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
 	FROM bookings b, users u, places p
-	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5)
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
 	ORDER BY b.date DESC LIMIT $2 OFFSET $3`)).
 		WithArgs(params.UserID, params.Limit, (params.Page-1)*params.Limit).
 		WillReturnError(sql.ErrNoRows)
@@ -416,13 +418,13 @@ func TestRepo_GetListTransactionsHistoryWithPaginationCountEmpty(t *testing.T) {
 		NewRows([]string{"id", "name", "image", "total_price", "date"}).
 		AddRow("1", "name", "image", 10, "date")
 	mock.ExpectQuery(regexp.QuoteMeta(`
-	SELECT b.id, u.name, u.image, b.total_price + p.booking_price as total_price, b.date
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
 	FROM bookings b, users u, places p
-	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5)
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
 	ORDER BY b.date DESC LIMIT $2 OFFSET $3`)).
 		WithArgs(params.UserID, params.Limit, (params.Page-1)*params.Limit).
 		WillReturnRows(rows)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5)")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(b.id) FROM bookings b, places p WHERE b.place_id = p.id AND p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)")).
 		WithArgs(params.UserID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -440,6 +442,7 @@ func TestRepo_GetBusinessAdminInformation(t *testing.T) {
 			ID:                1,
 			Name:              "name",
 			Email:             "email@email.com",
+			BankCode:          "BCA",
 			BankAccountName:   "name",
 			BankAccountNumber: "number",
 			PlaceID:           1,
@@ -454,15 +457,15 @@ func TestRepo_GetBusinessAdminInformation(t *testing.T) {
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "SELECT u.id, u.name, u.email, b.bank_account_number, b.bank_account_name, p.id as place_id " +
+		query := "SELECT u.id, u.name, u.email, b.bank_code, b.bank_account_number, b.bank_account_name, p.id as place_id " +
 			"FROM users as u " +
 			"JOIN business_owners as b ON b.user_id = u.id " +
 			"JOIN places as p ON p.user_id = u.id " +
 			"WHERE u.id = $1"
 
 		rows := mock.
-			NewRows([]string{"id", "name", "email", "bank_account_number", "bank_account_name", "place_id"}).
-			AddRow(1, "name", "email@email.com", "number", "name", 1)
+			NewRows([]string{"id", "name", "email", "bank_code", "bank_account_number", "bank_account_name", "place_id"}).
+			AddRow(1, "name", "email@email.com", "BCA", "number", "name", 1)
 
 		mock.ExpectQuery(regexp.QuoteMeta(query)).
 			WithArgs(1).
@@ -484,7 +487,7 @@ func TestRepo_GetBusinessAdminInformation(t *testing.T) {
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "SELECT u.id, u.name, u.email, b.bank_account_number, b.bank_account_name, p.id as place_id " +
+		query := "SELECT u.id, u.name, u.email, b.bank_code, b.bank_account_number, b.bank_account_name, p.id as place_id " +
 			"FROM users as u " +
 			"JOIN business_owners as b ON b.user_id = u.id " +
 			"JOIN places as p ON p.user_id = u.id " +
@@ -521,8 +524,8 @@ func TestRepo_SaveDisbursement(t *testing.T) {
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "INSERT INTO disbursements (place_id, date, xendit_id, amount, status) " +
-			"VALUES ($1, $2, $3, $4, $5) " +
+		query := "INSERT INTO disbursements (place_id, date, xendit_id, amount, status, payout_period, attempt) " +
+			"VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7) " +
 			"RETURNING id"
 
 		rows := mock.NewRows([]string{"id"})
@@ -555,8 +558,8 @@ func TestRepo_SaveDisbursement(t *testing.T) {
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "INSERT INTO disbursements (place_id, date, xendit_id, amount, status) " +
-			"VALUES ($1, $2, $3, $4, $5) " +
+		query := "INSERT INTO disbursements (place_id, date, xendit_id, amount, status, payout_period, attempt) " +
+			"VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7) " +
 			"RETURNING id"
 
 		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(ErrInternalServerError)
@@ -568,84 +571,670 @@ func TestRepo_SaveDisbursement(t *testing.T) {
 	})
 }
 
-func TestRepo_UpdateBalance(t *testing.T) {
+func TestRepo_SettleDisbursement(t *testing.T) {
+	settleQuery := "UPDATE disbursements SET status = $1 WHERE xendit_id = $2 AND status = $3 RETURNING id"
+	debitQuery := "UPDATE business_owners SET balance = balance - $1 WHERE user_id = $2"
+	eventQuery := "INSERT INTO notification_events (type, booking_id, user_id, amount)"
+	completedEvent := notification.Event{Type: util.NotificationDisbursementCompleted, UserID: 1, Amount: 4450}
+	failedEvent := notification.Event{Type: util.NotificationDisbursementFailed, UserID: 1, Amount: 4450}
+
+	t.Run("success completed disbursement deduct the balance", func(t *testing.T) {
+		// Mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.XenditDisbursementCompleted, "test", util.XenditDisbursementPending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(debitQuery)).WithArgs(10000.0, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(eventQuery)).WithArgs(util.NotificationDisbursementCompleted, 0, 1, 4450.0).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		settled, err := repo.SettleDisbursement("test", util.XenditDisbursementCompleted, 10000, 1, completedEvent)
+		assert.Nil(t, err)
+		assert.True(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success failed disbursement keep the balance", func(t *testing.T) {
+		// Mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.XenditDisbursementFailed, "test", util.XenditDisbursementPending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(eventQuery)).WithArgs(util.NotificationDisbursementFailed, 0, 1, 4450.0).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		settled, err := repo.SettleDisbursement("test", util.XenditDisbursementFailed, 0, 1, failedEvent)
+		assert.Nil(t, err)
+		assert.True(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("disbursement is already settled", func(t *testing.T) {
+		// Mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.XenditDisbursementCompleted, "test", util.XenditDisbursementPending).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		settled, err := repo.SettleDisbursement("test", util.XenditDisbursementCompleted, 10000, 1, completedEvent)
+		assert.Nil(t, err)
+		assert.False(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed deduct balance", func(t *testing.T) {
+		// Mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.XenditDisbursementCompleted, "test", util.XenditDisbursementPending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(debitQuery)).WithArgs(10000.0, 1).WillReturnError(ErrInternalServerError)
+		mock.ExpectRollback()
+
+		settled, err := repo.SettleDisbursement("test", util.XenditDisbursementCompleted, 10000, 1, completedEvent)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.False(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed record event", func(t *testing.T) {
+		// Mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(settleQuery)).WithArgs(util.XenditDisbursementCompleted, "test", util.XenditDisbursementPending).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(debitQuery)).WithArgs(10000.0, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(eventQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		settled, err := repo.SettleDisbursement("test", util.XenditDisbursementCompleted, 10000, 1, completedEvent)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.False(t, settled)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepo_GetDisbursementByXenditID(t *testing.T) {
+	query := "SELECT d.id, d.place_id, p.user_id, d.date, d.xendit_id, d.amount, d.status FROM disbursements d INNER JOIN places p ON p.id = d.place_id WHERE d.xendit_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		expected := DisbursementDetail{ID: 1, PlaceID: 2, UserID: 3, Date: time.Now(), XenditID: "test", Amount: 10000, Status: util.XenditDisbursementPending}
+		rows := mock.NewRows([]string{"id", "place_id", "user_id", "date", "xendit_id", "amount", "status"}).
+			AddRow(expected.ID, expected.PlaceID, expected.UserID, expected.Date, expected.XenditID, expected.Amount, expected.Status)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("test").WillReturnRows(rows)
+
+		disbursement, err := repo.GetDisbursementByXenditID("test")
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, disbursement)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("test").WillReturnError(sql.ErrNoRows)
+
+		disbursement, err := repo.GetDisbursementByXenditID("test")
+		assert.Nil(t, disbursement)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("test").WillReturnError(sql.ErrTxDone)
+
+		disbursement, err := repo.GetDisbursementByXenditID("test")
+		assert.Nil(t, disbursement)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPendingDisbursements(t *testing.T) {
+	query := "SELECT id, place_id, date, xendit_id, amount, status FROM disbursements WHERE status = $1 ORDER BY id"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		expected := []DisbursementDetail{{ID: 1, PlaceID: 2, Date: time.Now(), XenditID: "test", Amount: 10000, Status: util.XenditDisbursementPending}}
+		rows := mock.NewRows([]string{"id", "place_id", "date", "xendit_id", "amount", "status"})
+		for _, d := range expected {
+			rows.AddRow(d.ID, d.PlaceID, d.Date, d.XenditID, d.Amount, d.Status)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.XenditDisbursementPending).WillReturnRows(rows)
+
+		disbursements, err := repo.GetPendingDisbursements()
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, disbursements)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.XenditDisbursementPending).WillReturnError(sql.ErrTxDone)
+
+		disbursements, err := repo.GetPendingDisbursements()
+		assert.Nil(t, disbursements)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPayoutSchedule(t *testing.T) {
+	query := "SELECT payout_schedule, payout_minimum_balance FROM business_owners WHERE user_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		rows := mock.NewRows([]string{"payout_schedule", "payout_minimum_balance"}).AddRow(util.PayoutWeekly, 50000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
+
+		schedule, err := repo.GetPayoutSchedule(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &PayoutSchedule{Schedule: util.PayoutWeekly, MinimumBalance: 50000}, schedule)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		schedule, err := repo.GetPayoutSchedule(1)
+		assert.Nil(t, schedule)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdatePayoutSchedule(t *testing.T) {
+	query := "UPDATE business_owners SET payout_schedule = $1, payout_minimum_balance = $2, updated_at = NOW() WHERE user_id = $3"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.PayoutMonthly, 50000.0, 1).WillReturnResult(driver.ResultNoRows)
+
+		err = repo.UpdatePayoutSchedule(1, PayoutSchedule{Schedule: util.PayoutMonthly, MinimumBalance: 50000})
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.PayoutMonthly, 50000.0, 1).WillReturnError(sql.ErrTxDone)
+
+		err = repo.UpdatePayoutSchedule(1, PayoutSchedule{Schedule: util.PayoutMonthly, MinimumBalance: 50000})
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPayoutDetails(t *testing.T) {
+	query := "SELECT bank_code, bank_account_number, bank_account_name FROM business_owners WHERE user_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		rows := mock.NewRows([]string{"bank_code", "bank_account_number", "bank_account_name"}).AddRow("BNI", "009-123456789", "TEST")
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
+
+		details, err := repo.GetPayoutDetails(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &PayoutDetails{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "TEST"}, details)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		details, err := repo.GetPayoutDetails(1)
+		assert.Nil(t, details)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		details, err := repo.GetPayoutDetails(1)
+		assert.Nil(t, details)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdatePayoutDetails(t *testing.T) {
+	query := "UPDATE business_owners SET bank_code = $1, bank_account_number = $2, bank_account_name = $3, updated_at = NOW() WHERE user_id = $4"
+	details := PayoutDetails{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "TEST"}
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("BNI", "009-123456789", "TEST", 1).WillReturnResult(driver.ResultNoRows)
+
+		err = repo.UpdatePayoutDetails(1, details)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("BNI", "009-123456789", "TEST", 1).WillReturnError(sql.ErrTxDone)
+
+		err = repo.UpdatePayoutDetails(1, details)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPasswordByUserID(t *testing.T) {
+	query := "SELECT password FROM users WHERE id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"password"}).AddRow("hashed"))
+
+		password, err := repo.GetPasswordByUserID(1)
+		assert.Nil(t, err)
+		assert.Equal(t, "hashed", password)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err = repo.GetPasswordByUserID(1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		_, err = repo.GetPasswordByUserID(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_CheckIfBankAccountIsUsed(t *testing.T) {
+	query := "SELECT COUNT(id) FROM business_owners WHERE bank_account_number = $1 AND user_id <> $2"
+
 	t.Run("success", func(t *testing.T) {
-		// Mock DB
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "UPDATE business_owners SET balance = $1 " +
-			"WHERE user_id= $2"
-
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(10000.0, 1).WillReturnResult(driver.ResultNoRows)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("009-123456789", 1).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
 
-		err = repo.UpdateBalance(10000, 1)
+		isUsed, err := repo.CheckIfBankAccountIsUsed(1, "009-123456789")
 		assert.Nil(t, err)
+		assert.True(t, isUsed)
 	})
 
-	t.Run("failed", func(t *testing.T) {
-		// Mock DB
+	t.Run("failed internal server error", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "UPDATE business_owners SET balance = $1 " +
-			"WHERE user_id= $2"
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("009-123456789", 1).WillReturnError(sql.ErrTxDone)
+
+		_, err = repo.CheckIfBankAccountIsUsed(1, "009-123456789")
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetScheduledPayoutOwners(t *testing.T) {
+	query := "SELECT u.id, u.name, u.email, b.bank_code, b.bank_account_number, b.bank_account_name, p.id as place_id, " +
+		"b.balance, b.payout_schedule, b.payout_minimum_balance " +
+		"FROM users as u " +
+		"JOIN business_owners as b ON b.user_id = u.id " +
+		"JOIN places as p ON p.user_id = u.id " +
+		"WHERE b.payout_schedule <> $1 ORDER BY u.id"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(10000.0, 1).WillReturnError(ErrInternalServerError)
+		expected := []ScheduledPayoutOwner{
+			{
+				InfoForDisbursement: InfoForDisbursement{ID: 1, Name: "test", Email: "test@test.com", BankCode: "BNI", BankAccountName: "TEST", BankAccountNumber: "123", PlaceID: 2},
+				Balance:             100000,
+				Schedule:            util.PayoutWeekly,
+				MinimumBalance:      50000,
+			},
+		}
 
-		err = repo.UpdateBalance(10000, 1)
-		assert.NotNil(t, err)
+		rows := mock.NewRows([]string{"id", "name", "email", "bank_code", "bank_account_number", "bank_account_name", "place_id", "balance", "payout_schedule", "payout_minimum_balance"})
+		for _, o := range expected {
+			rows.AddRow(o.ID, o.Name, o.Email, o.BankCode, o.BankAccountNumber, o.BankAccountName, o.PlaceID, o.Balance, o.Schedule, o.MinimumBalance)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.PayoutManual).WillReturnRows(rows)
+
+		owners, err := repo.GetScheduledPayoutOwners()
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, owners)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.PayoutManual).WillReturnError(sql.ErrTxDone)
+
+		owners, err := repo.GetScheduledPayoutOwners()
+		assert.Nil(t, owners)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 }
 
-func TestRepo_UpdateDisbursementStatusByXenditID(t *testing.T) {
+func TestRepo_GetLatestPayout(t *testing.T) {
+	query := "SELECT id, place_id, date, xendit_id, amount, status, payout_period, attempt FROM disbursements " +
+		"WHERE place_id = $1 AND payout_period = $2 ORDER BY attempt DESC LIMIT 1"
+
 	t.Run("success", func(t *testing.T) {
-		// Mock DB
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		expected := DisbursementDetail{ID: 1, PlaceID: 2, Date: time.Now(), XenditID: "test", Amount: 10000, Status: util.XenditDisbursementFailed, PayoutPeriod: "2022-W18", Attempt: 1}
+		rows := mock.NewRows([]string{"id", "place_id", "date", "xendit_id", "amount", "status", "payout_period", "attempt"}).
+			AddRow(expected.ID, expected.PlaceID, expected.Date, expected.XenditID, expected.Amount, expected.Status, expected.PayoutPeriod, expected.Attempt)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "2022-W18").WillReturnRows(rows)
+
+		payout, err := repo.GetLatestPayout(2, "2022-W18")
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, payout)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "2022-W18").WillReturnError(sql.ErrNoRows)
+
+		payout, err := repo.GetLatestPayout(2, "2022-W18")
+		assert.Nil(t, payout)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "UPDATE disbursements as d SET status = $1 " +
-			"WHERE xendit_id = $2"
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "2022-W18").WillReturnError(sql.ErrTxDone)
+
+		payout, err := repo.GetLatestPayout(2, "2022-W18")
+		assert.Nil(t, payout)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetLastDisbursementID(t *testing.T) {
+	query := "SELECT COALESCE(MAX(id), 0) FROM disbursements WHERE place_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(0, "test").WillReturnResult(driver.ResultNoRows)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnRows(mock.NewRows([]string{"max"}).AddRow(5))
 
-		err = repo.UpdateDisbursementStatusByXenditID(0, "test")
+		ID, err := repo.GetLastDisbursementID(2)
 		assert.Nil(t, err)
+		assert.Equal(t, 5, ID)
 	})
 
-	t.Run("failed", func(t *testing.T) {
-		// Mock DB
+	t.Run("failed internal server error", func(t *testing.T) {
 		mockDB, mock, err := sqlmock.New()
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := "UPDATE disbursements as d SET status = $1 " +
-			"WHERE xendit_id = $2"
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrTxDone)
+
+		ID, err := repo.GetLastDisbursementID(2)
+		assert.Equal(t, 0, ID)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(0, "test").WillReturnError(ErrInternalServerError)
+func TestRepo_CountPendingDisbursements(t *testing.T) {
+	query := "SELECT COUNT(id) FROM disbursements WHERE place_id = $1 AND status = $2"
 
-		err = repo.UpdateDisbursementStatusByXenditID(0, "test")
-		assert.NotNil(t, err)
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, util.XenditDisbursementPending).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+
+		count, err := repo.CountPendingDisbursements(2)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, util.XenditDisbursementPending).WillReturnError(sql.ErrTxDone)
+
+		_, err = repo.CountPendingDisbursements(2)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 }
@@ -720,6 +1309,49 @@ func TestRepo_GetTransactionHistoryDetailInternalServerError(t *testing.T) {
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 }
 
+func TestRepo_GetBookingOwnerID(t *testing.T) {
+	query := "SELECT p.user_id FROM bookings b INNER JOIN places p ON p.id = b.place_id WHERE b.id = $1"
+
+	newRepoMock := func(t *testing.T) (Repo, sqlmock.Sqlmock, func() error) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		return NewRepo(sqlx.NewDb(mockDB, "sqlmock")), mock, mockDB.Close
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"user_id"}).AddRow(2))
+
+		ownerID, err := repoMock.GetBookingOwnerID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, ownerID)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetBookingOwnerID(1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newRepoMock(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetBookingOwnerID(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
 func TestRepo_GetItemsWrapperSuccess(t *testing.T) {
 	bookingID := 1
 	itemsWrapperExpected := &ItemsWrapper{
@@ -948,3 +1580,182 @@ func TestRepo_UpdateProfileError(t *testing.T) {
 	err = repoMock.UpdateProfile(editProfileRequest)
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 }
+
+func TestRepo_GetTransactionsByDateRange(t *testing.T) {
+	query := `
+	SELECT b.id, u.name, u.image, b.total_price + COALESCE(b.booking_price, p.booking_price, 0) as total_price, b.date
+	FROM bookings b, users u, places p
+	WHERE b.place_id = p.id AND p.user_id = $1 AND b.user_id = u.id AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6)
+	AND b.date BETWEEN $2 AND $3
+	ORDER BY b.date, b.id`
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		expected := []Transaction{{ID: 1, Name: "test", Image: "image", Price: 10000, Date: "2022-05-01"}}
+		rows := mock.NewRows([]string{"id", "name", "image", "total_price", "date"}).AddRow(1, "test", "image", 10000.0, "2022-05-01")
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-05-01", "2022-05-31").WillReturnRows(rows)
+
+		transactions, err := repo.GetTransactionsByDateRange(1, "2022-05-01", "2022-05-31")
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, transactions)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-05-01", "2022-05-31").WillReturnError(sql.ErrTxDone)
+
+		transactions, err := repo.GetTransactionsByDateRange(1, "2022-05-01", "2022-05-31")
+		assert.Nil(t, transactions)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetItemSalesByDateRange(t *testing.T) {
+	query := `
+	SELECT i.name, SUM(bi.qty) as qty, SUM(bi.total_price) as total_price
+	FROM bookings b
+	INNER JOIN booking_items bi ON b.id = bi.booking_id
+	INNER JOIN items i ON bi.item_id = i.id
+	INNER JOIN places p ON b.place_id = p.id
+	WHERE p.user_id = $1 AND (b.status = 2 OR b.status = 3 OR b.status = 5 OR b.status = 6) AND b.date BETWEEN $2 AND $3
+	GROUP BY i.name
+	ORDER BY i.name`
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		expected := []ItemSales{{Name: "kopi", Qty: 3, TotalPrice: 30000}}
+		rows := mock.NewRows([]string{"name", "qty", "total_price"}).AddRow("kopi", 3, 30000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-05-01", "2022-05-31").WillReturnRows(rows)
+
+		itemSales, err := repo.GetItemSalesByDateRange(1, "2022-05-01", "2022-05-31")
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, itemSales)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, "2022-05-01", "2022-05-31").WillReturnError(sql.ErrTxDone)
+
+		itemSales, err := repo.GetItemSalesByDateRange(1, "2022-05-01", "2022-05-31")
+		assert.Nil(t, itemSales)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetDisbursementsByDateRange(t *testing.T) {
+	query := "SELECT id, place_id, date, xendit_id, amount, status, COALESCE(payout_period, '') as payout_period, attempt FROM disbursements " +
+		"WHERE place_id = $1 AND date::date BETWEEN $2 AND $3 ORDER BY date, id"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		date := time.Date(2022, 5, 4, 10, 0, 0, 0, time.UTC)
+		expected := []DisbursementDetail{{ID: 1, PlaceID: 2, Date: date, XenditID: "xendit id", Amount: 50000, Status: util.XenditDisbursementCompleted}}
+		rows := mock.NewRows([]string{"id", "place_id", "date", "xendit_id", "amount", "status", "payout_period", "attempt"}).
+			AddRow(1, 2, date, "xendit id", 50000.0, util.XenditDisbursementCompleted, "", 0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "2022-05-01", "2022-05-31").WillReturnRows(rows)
+
+		disbursements, err := repo.GetDisbursementsByDateRange(2, "2022-05-01", "2022-05-31")
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, disbursements)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "2022-05-01", "2022-05-31").WillReturnError(sql.ErrTxDone)
+
+		disbursements, err := repo.GetDisbursementsByDateRange(2, "2022-05-01", "2022-05-31")
+		assert.Nil(t, disbursements)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPlatformFeeByDateRange(t *testing.T) {
+	query := `
+	SELECT COALESCE(SUM(bi.platform_fee), 0)
+	FROM booking_invoices bi
+	INNER JOIN bookings b ON bi.booking_id = b.id
+	INNER JOIN places p ON b.place_id = p.id
+	WHERE p.user_id = $1 AND bi.status = $2 AND b.date BETWEEN $3 AND $4`
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		rows := mock.NewRows([]string{"coalesce"}).AddRow(3000.0)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.InvoicePaid, "2022-05-01", "2022-05-31").WillReturnRows(rows)
+
+		platformFee, err := repo.GetPlatformFeeByDateRange(1, "2022-05-01", "2022-05-31")
+		assert.Nil(t, err)
+		assert.Equal(t, 3000.0, platformFee)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, util.InvoicePaid, "2022-05-01", "2022-05-31").WillReturnError(sql.ErrTxDone)
+
+		_, err = repo.GetPlatformFeeByDateRange(1, "2022-05-01", "2022-05-31")
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
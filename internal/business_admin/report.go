@@ -0,0 +1,183 @@
+package businessadmin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// reportTable is the tabular content of a report before it is encoded to csv or xlsx
+type reportTable struct {
+	Title string
+	Rows  [][]interface{}
+}
+
+func transactionsTable(transactions []Transaction) reportTable {
+	rows := [][]interface{}{{"ID", "Customer", "Date", "Total Price"}}
+	for _, transaction := range transactions {
+		rows = append(rows, []interface{}{transaction.ID, transaction.Name, reportDate(transaction.Date), transaction.Price})
+	}
+
+	return reportTable{Title: "Transactions", Rows: rows}
+}
+
+func itemSalesTable(itemSales []ItemSales) reportTable {
+	rows := [][]interface{}{{"Item", "Qty", "Total Price"}}
+	for _, item := range itemSales {
+		rows = append(rows, []interface{}{item.Name, item.Qty, item.TotalPrice})
+	}
+
+	return reportTable{Title: "Item Sales", Rows: rows}
+}
+
+func disbursementsTable(disbursements []DisbursementDetail) reportTable {
+	rows := [][]interface{}{{"ID", "Date", "Xendit ID", "Amount", "Status", "Payout Period", "Attempt"}}
+	for _, disbursement := range disbursements {
+		rows = append(rows, []interface{}{
+			disbursement.ID,
+			disbursement.Date.Format(util.DateLayout),
+			disbursement.XenditID,
+			disbursement.Amount,
+			disbursementStatus(disbursement.Status),
+			disbursement.PayoutPeriod,
+			disbursement.Attempt,
+		})
+	}
+
+	return reportTable{Title: "Disbursements", Rows: rows}
+}
+
+func encodeCSV(table reportTable) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = csvCell(cell)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+func encodeXLSX(table reportTable) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	err := util.WriteXLSX(&buffer, []util.Sheet{{Name: table.Title, Rows: table.Rows}})
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func encodeStatementPDF(statement MonthlyStatement) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Monthly Statement %s", statement.Period), false)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Monthly Statement %s", statement.Period), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 7, statement.Name, "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, "Summary", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	summary := [][]string{
+		{"Total transactions", strconv.Itoa(len(statement.Transactions))},
+		{"Gross income", reportCell(statement.GrossIncome)},
+		{"Platform fee", reportCell(statement.PlatformFee)},
+		{"Net income", reportCell(statement.NetIncome)},
+		{"Total disbursement", reportCell(statement.TotalDisbursement)},
+	}
+	for _, row := range summary {
+		pdf.CellFormat(60, 7, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 7, row[1], "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	writePDFTable(pdf, transactionsTable(statement.Transactions), []float64{20, 80, 40, 40})
+	pdf.Ln(4)
+	writePDFTable(pdf, disbursementsTable(statement.Disbursements), []float64{15, 25, 45, 30, 25, 30, 15})
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writePDFTable(pdf *gofpdf.Fpdf, table reportTable, widths []float64) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, table.Title, "", 1, "L", false, 0, "")
+
+	for i, row := range table.Rows {
+		if i == 0 {
+			pdf.SetFont("Helvetica", "B", 9)
+		} else {
+			pdf.SetFont("Helvetica", "", 9)
+		}
+
+		for j, cell := range row {
+			pdf.CellFormat(widths[j], 6, reportCell(cell), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func reportCell(cell interface{}) string {
+	if value, ok := cell.(float64); ok {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+
+	return fmt.Sprint(cell)
+}
+
+// csvCell prefix text starting with a formula character with a quote, so customer and item names
+// are not evaluated as formula when the csv is opened in a spreadsheet
+func csvCell(cell interface{}) string {
+	value, ok := cell.(string)
+	if !ok {
+		return reportCell(cell)
+	}
+
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// reportDate trim the time part of booking date
+func reportDate(date string) string {
+	if len(date) > len(util.DateLayout) {
+		return date[:len(util.DateLayout)]
+	}
+
+	return date
+}
+
+func disbursementStatus(status int) string {
+	switch status {
+	case util.XenditDisbursementPending:
+		return util.XenditDisbursementPendingString
+	case util.XenditDisbursementCompleted:
+		return util.XenditDisbursementCompletedString
+	default:
+		return util.XenditDisbursementFailedString
+	}
+}
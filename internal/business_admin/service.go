@@ -2,15 +2,16 @@ package businessadmin
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/place"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
 
 	"github.com/pkg/errors"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Service are interface can be used by service
@@ -19,63 +20,335 @@ type Service interface {
 	GetListTransactionsHistoryWithPagination(params ListTransactionRequest) (*ListTransaction, *util.Pagination, error)
 	CreateDisbursement(int, float64) (*CreateDisbursementResponse, error)
 	DisbursementCallbackFromXendit(params DisbursementCallback) error
-	GetTransactionHistoryDetail(int) (*TransactionHistoryDetail, error)
+	GetPendingDisbursements() (*[]DisbursementDetail, error)
+	GetPayoutSchedule(userID int) (*PayoutSchedule, error)
+	UpdatePayoutSchedule(userID int, params PayoutSchedule) error
+	GetPayoutDetails(userID int) (*PayoutDetails, error)
+	UpdatePayoutDetails(userID int, params UpdatePayoutDetailsRequest) (*PayoutDetails, error)
+	RunScheduledPayouts(now time.Time) (*[]ScheduledPayout, error)
+	GetReport(params ReportRequest) (*ReportFile, error)
+	GetMonthlyStatement(userID int, month string) (*ReportFile, error)
+	GetTransactionHistoryDetail(userID int, bookingID int) (*TransactionHistoryDetail, error)
 	PutEditProfile(EditProfileRequest) error
 	GetPlaceDetail(userID int) (*PlaceDetail, error)
 	GetListReviewAndRatingWithPagination(userID int, params ListReviewRequest) (*place.ListReview, *util.Pagination, error)
 }
 
 type service struct {
-	repo          Repo
-	xenditService xendit.Service
-	placeService  place.Service
+	repo                Repo
+	xenditService       xendit.Service
+	placeService        place.Service
+	notificationService notification.Notifier
 }
 
 // NewService create new service
-func NewService(repo Repo, xenditService xendit.Service, placeService place.Service) Service {
+func NewService(repo Repo, xenditService xendit.Service, placeService place.Service, notificationService notification.Notifier) Service {
 	return &service{
-		repo:          repo,
-		xenditService: xenditService,
-		placeService:  placeService,
+		repo:                repo,
+		xenditService:       xenditService,
+		placeService:        placeService,
+		notificationService: notificationService,
 	}
 }
 
+// DisbursementCallbackFromXendit settle the disbursement, the owner is taken from the saved disbursement
+// because the external ID of scheduled payout is the payout key instead of the user ID
 func (s *service) DisbursementCallbackFromXendit(params DisbursementCallback) error {
-	userID, err := strconv.Atoi(params.ExternalID)
+	if params.Status != util.XenditDisbursementCompletedString && params.Status != util.XenditDisbursementFailedString {
+		return errors.Wrap(ErrInputValidationError, "status must be COMPLETED or FAILED")
+	}
+
+	disbursement, err := s.repo.GetDisbursementByXenditID(params.ID)
 	if err != nil {
-		return errors.Wrap(ErrInputValidationError, "external id is not valid")
+		return err
 	}
 
-	switch params.Status {
-	case util.XenditDisbursementCompletedString:
-		currentBalance, err := s.repo.GetBalance(userID)
-		if err != nil {
-			return err
+	// xendit may send the same callback more than once and reconciliation may have applied it already
+	if disbursement.Status != util.XenditDisbursementPending {
+		return nil
+	}
+
+	userID := disbursement.UserID
+
+	var (
+		status    = util.XenditDisbursementFailed
+		debit     float64
+		eventType = util.NotificationDisbursementFailed
+	)
+	if params.Status == util.XenditDisbursementCompletedString {
+		status = util.XenditDisbursementCompleted
+		debit = params.Amount + util.XenditDisbursementFee + (util.XenditDisbursementFee * util.XenditVATPercentage)
+		eventType = util.NotificationDisbursementCompleted
+	}
+
+	event := notification.Event{Type: eventType, UserID: userID, Amount: params.Amount}
+	settled, err := s.repo.SettleDisbursement(params.ID, status, debit, userID, event)
+	if err != nil {
+		return err
+	}
+
+	if !settled {
+		return nil
+	}
+
+	s.notificationService.Notify(event)
+	return nil
+}
+
+func (s *service) GetPendingDisbursements() (*[]DisbursementDetail, error) {
+	return s.repo.GetPendingDisbursements()
+}
+
+func (s *service) GetPayoutSchedule(userID int) (*PayoutSchedule, error) {
+	if userID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "userID must be positive integer")
+	}
+
+	return s.repo.GetPayoutSchedule(userID)
+}
+
+func (s *service) UpdatePayoutSchedule(userID int, params PayoutSchedule) error {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be positive integer")
+	}
+
+	if params.Schedule < util.PayoutManual || params.Schedule > util.PayoutMonthly {
+		errorList = append(errorList, fmt.Sprintf("schedule should be %d - %d", util.PayoutManual, util.PayoutMonthly))
+	}
+
+	if params.MinimumBalance < 0 {
+		errorList = append(errorList, "minimum balance cannot be negative")
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	return s.repo.UpdatePayoutSchedule(userID, params)
+}
+
+func (s *service) GetPayoutDetails(userID int) (*PayoutDetails, error) {
+	if userID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "userID must be positive integer")
+	}
+
+	return s.repo.GetPayoutDetails(userID)
+}
+
+// UpdatePayoutDetails change the bank account that receive disbursement,
+// the business admin has to re-enter the current password to do so
+func (s *service) UpdatePayoutDetails(userID int, params UpdatePayoutDetailsRequest) (*PayoutDetails, error) {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be positive integer")
+	}
+
+	if params.Password == "" {
+		errorList = append(errorList, "password is required")
+	}
+
+	if !util.IsSupportedBankCode(params.BankCode) {
+		errorList = append(errorList, "bank code is not supported")
+	} else if reason := util.ValidateBankAccountNumber(params.BankCode, params.BankAccountNumber); reason != "" {
+		errorList = append(errorList, reason)
+	}
+
+	if len(params.BankAccountName) < 3 || len(params.BankAccountName) > 50 {
+		errorList = append(errorList, "bank account name should be 3 - 50 characters")
+	}
+
+	for _, char := range strings.ToUpper(params.BankAccountName) {
+		if char != ' ' && (char < 'A' || char > 'Z') {
+			errorList = append(errorList, "bank account name is invalid")
+			break
 		}
+	}
 
-		newBalance := currentBalance.Balance - (params.Amount + util.XenditDisbursementFee + (util.XenditDisbursementFee * util.XenditVATPercentage))
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
 
-		err = s.repo.UpdateBalance(newBalance, userID)
-		if err != nil {
-			return err
+	password, err := s.repo.GetPasswordByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(password), []byte(params.Password)); err != nil {
+		return nil, errors.Wrap(ErrUnauthorized, "password is incorrect")
+	}
+
+	isUsed, err := s.repo.CheckIfBankAccountIsUsed(userID, params.BankAccountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if isUsed {
+		return nil, errors.Wrap(ErrInputValidationError, "bank account is already used by another business admin")
+	}
+
+	details := PayoutDetails{
+		BankCode:          params.BankCode,
+		BankAccountNumber: params.BankAccountNumber,
+		BankAccountName:   params.BankAccountName,
+	}
+
+	err = s.repo.UpdatePayoutDetails(userID, details)
+	if err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// RunScheduledPayouts create disbursement for every business admin that has automatic payout
+// and has not been paid in the current period. Failed payout is retried on the next run
+// until util.MaximumPayoutAttempt is reached.
+func (s *service) RunScheduledPayouts(now time.Time) (*[]ScheduledPayout, error) {
+	owners, err := s.repo.GetScheduledPayoutOwners()
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := make([]ScheduledPayout, 0)
+	for _, owner := range *owners {
+		payout, created := s.runScheduledPayout(owner, now)
+		if created {
+			payouts = append(payouts, payout)
 		}
+	}
 
-		err = s.repo.UpdateDisbursementStatusByXenditID(util.XenditDisbursementCompleted, params.ID)
-		if err != nil {
-			return err
+	return &payouts, nil
+}
+
+func (s *service) runScheduledPayout(owner ScheduledPayoutOwner, now time.Time) (ScheduledPayout, bool) {
+	period := payoutPeriod(owner.Schedule, now)
+	payout := ScheduledPayout{
+		UserID:  owner.ID,
+		PlaceID: owner.PlaceID,
+		Period:  period,
+		Attempt: 1,
+	}
+	retry := false
+
+	latestPayout, err := s.repo.GetLatestPayout(owner.PlaceID, period)
+	if err == nil {
+		if latestPayout.Status != util.XenditDisbursementFailed || latestPayout.Attempt >= util.MaximumPayoutAttempt {
+			return payout, false
 		}
 
-		return nil
-	case util.XenditDisbursementFailedString:
-		err = s.repo.UpdateDisbursementStatusByXenditID(util.XenditDisbursementFailed, params.ID)
+		payout.Attempt = latestPayout.Attempt + 1
+		retry = true
+	} else if errors.Cause(err) != ErrNotFound {
+		payout.Error = err.Error()
+		return payout, true
+	}
+
+	fee := util.XenditDisbursementFee + (util.XenditDisbursementFee * util.XenditVATPercentage)
+	if owner.Balance < owner.MinimumBalance || owner.Balance <= fee {
+		return payout, false
+	}
+
+	// balance is only deducted when the disbursement is completed
+	pendingDisbursements, err := s.repo.CountPendingDisbursements(owner.PlaceID)
+	if err != nil {
+		payout.Error = err.Error()
+		return payout, true
+	}
+
+	if pendingDisbursements > 0 {
+		return payout, false
+	}
+
+	payout.Amount = owner.Balance - fee
+
+	disbursement := DisbursementDetail{
+		PlaceID:      owner.PlaceID,
+		Date:         now,
+		Amount:       payout.Amount,
+		Status:       util.XenditDisbursementPending,
+		PayoutPeriod: period,
+		Attempt:      payout.Attempt,
+	}
+
+	// every disbursement of the payout period share the external ID so they can be found at xendit,
+	// the idempotency key only changes after xendit reported the previous disbursement of the period as failed
+	externalID := fmt.Sprintf("payout-%d-%s", owner.PlaceID, period)
+	idempotencyKey := externalID
+	if retry {
+		// a failed attempt may have reached xendit even though the request returned an error,
+		// so the disbursements of the period are checked before sending a new one
+		xenditDisbursements, err := s.xenditService.GetDisbursementsByExternalID(externalID)
 		if err != nil {
-			return err
+			payout.Error = err.Error()
+			return payout, true
 		}
 
-		return nil
-	default:
-		return errors.Wrap(ErrInputValidationError, "status must be COMPLETED or FAILED")
+		failedDisbursements := 0
+		for _, xenditDisbursement := range xenditDisbursements {
+			if xenditDisbursement.Status != util.XenditDisbursementFailedString {
+				// the disbursement is recorded as pending so the callback or reconciliation settle it
+				disbursement.XenditID = xenditDisbursement.ID
+				disbursement.Amount = xenditDisbursement.Amount
+				payout.XenditID = xenditDisbursement.ID
+				payout.Amount = xenditDisbursement.Amount
+
+				_, err = s.repo.SaveDisbursement(disbursement)
+				if err != nil {
+					payout.Error = err.Error()
+				}
+
+				return payout, true
+			}
+
+			failedDisbursements++
+		}
+
+		if failedDisbursements > 0 {
+			idempotencyKey = fmt.Sprintf("%s-%d", idempotencyKey, failedDisbursements)
+		}
 	}
+
+	xenditDisbursementParams := xendit.CreateDisbursementParams{
+		ID:                owner.ID,
+		BankCode:          owner.BankCode,
+		BankAccountName:   owner.BankAccountName,
+		BankAccountNumber: owner.BankAccountNumber,
+		Amount:            payout.Amount,
+		Description:       fmt.Sprintf("Scheduled payout %s by %s", period, owner.Name),
+		Email:             []string{owner.Email},
+		IdempotencyKey:    idempotencyKey,
+		ExternalID:        externalID,
+	}
+
+	createXenditDisbursement, err := s.xenditService.CreateDisbursement(xenditDisbursementParams)
+	if err != nil {
+		// the failed attempt is saved so the next run look it up at xendit before retrying
+		disbursement.Status = util.XenditDisbursementFailed
+		payout.Error = err.Error()
+	} else {
+		disbursement.XenditID = createXenditDisbursement.ID
+		disbursement.Amount = createXenditDisbursement.Amount
+		payout.XenditID = createXenditDisbursement.ID
+	}
+
+	_, err = s.repo.SaveDisbursement(disbursement)
+	if err != nil {
+		payout.Error = err.Error()
+	}
+
+	return payout, true
+}
+
+func payoutPeriod(schedule int, now time.Time) string {
+	if schedule == util.PayoutWeekly {
+		year, week := now.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+
+	return now.Format("2006-01")
 }
 
 func (s *service) CreateDisbursement(userID int, amount float64) (*CreateDisbursementResponse, error) {
@@ -111,13 +384,21 @@ func (s *service) CreateDisbursement(userID int, amount float64) (*CreateDisburs
 
 	amount -= util.XenditDisbursementFee + (util.XenditDisbursementFee * util.XenditVATPercentage)
 
+	// the key only changes once a disbursement is saved, so a retried request is never disbursed twice
+	lastDisbursementID, err := s.repo.GetLastDisbursementID(businessAdminInfo.PlaceID)
+	if err != nil {
+		return nil, err
+	}
+
 	xenditDisbursementParams := xendit.CreateDisbursementParams{
 		ID:                businessAdminInfo.ID,
+		BankCode:          businessAdminInfo.BankCode,
 		BankAccountName:   businessAdminInfo.BankAccountName,
 		BankAccountNumber: businessAdminInfo.BankAccountNumber,
 		Amount:            amount,
 		Description:       fmt.Sprintf("Disbursement by %s", businessAdminInfo.Name),
 		Email:             []string{businessAdminInfo.Email},
+		IdempotencyKey:    fmt.Sprintf("disbursement-%d-%d", businessAdminInfo.PlaceID, lastDisbursementID),
 	}
 
 	createXenditDisbursement, err := s.xenditService.CreateDisbursement(xenditDisbursementParams)
@@ -214,9 +495,173 @@ func (s *service) GetListTransactionsHistoryWithPagination(params ListTransactio
 	return listTransaction, &pagination, err
 }
 
-func (s *service) GetTransactionHistoryDetail(bookingID int) (*TransactionHistoryDetail, error) {
+// GetReport export transaction history, item sales or disbursement history in a date range as csv or xlsx
+func (s *service) GetReport(params ReportRequest) (*ReportFile, error) {
+	var errorList []string
+
+	if params.UserID <= 0 {
+		errorList = append(errorList, "userID must be positive integer")
+	}
+
+	if params.Report != util.ReportTransactions && params.Report != util.ReportItemSales && params.Report != util.ReportDisbursements {
+		errorList = append(errorList, fmt.Sprintf("report should be %s, %s or %s", util.ReportTransactions, util.ReportItemSales, util.ReportDisbursements))
+	}
+
+	if params.Format != util.ReportFormatCSV && params.Format != util.ReportFormatXLSX {
+		errorList = append(errorList, fmt.Sprintf("format should be %s or %s", util.ReportFormatCSV, util.ReportFormatXLSX))
+	}
+
+	startDate, errStartDate := time.Parse(util.DateLayout, params.StartDate)
+	if errStartDate != nil {
+		errorList = append(errorList, "start_date should be in YYYY-MM-DD format")
+	}
+
+	endDate, errEndDate := time.Parse(util.DateLayout, params.EndDate)
+	if errEndDate != nil {
+		errorList = append(errorList, "end_date should be in YYYY-MM-DD format")
+	}
+
+	if errStartDate == nil && errEndDate == nil {
+		if endDate.Before(startDate) {
+			errorList = append(errorList, "end_date should not be before start_date")
+		} else if endDate.Sub(startDate).Hours()/24 >= util.MaximumReportRange {
+			errorList = append(errorList, fmt.Sprintf("date range should be at most %d days", util.MaximumReportRange))
+		}
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	var table reportTable
+	switch params.Report {
+	case util.ReportTransactions:
+		transactions, err := s.repo.GetTransactionsByDateRange(params.UserID, params.StartDate, params.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		table = transactionsTable(*transactions)
+	case util.ReportItemSales:
+		itemSales, err := s.repo.GetItemSalesByDateRange(params.UserID, params.StartDate, params.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		table = itemSalesTable(*itemSales)
+	case util.ReportDisbursements:
+		placeID, err := s.repo.GetPlaceIDByUserID(params.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		disbursements, err := s.repo.GetDisbursementsByDateRange(placeID, params.StartDate, params.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		table = disbursementsTable(*disbursements)
+	}
+
+	file := ReportFile{
+		FileName:    fmt.Sprintf("%s_%s_%s.%s", params.Report, params.StartDate, params.EndDate, params.Format),
+		ContentType: util.TextCSV,
+	}
+
+	var err error
+	if params.Format == util.ReportFormatXLSX {
+		file.ContentType = util.ApplicationXLSX
+		file.Content, err = encodeXLSX(table)
+	} else {
+		file.Content, err = encodeCSV(table)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &file, nil
+}
+
+// GetMonthlyStatement export transactions, platform fee, net income and disbursements in a month as pdf
+func (s *service) GetMonthlyStatement(userID int, month string) (*ReportFile, error) {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be positive integer")
+	}
+
+	firstDay, err := time.Parse("2006-01", month)
+	if err != nil {
+		errorList = append(errorList, "month should be in YYYY-MM format")
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	startDate := firstDay.Format(util.DateLayout)
+	endDate := firstDay.AddDate(0, 1, -1).Format(util.DateLayout)
+
+	businessAdminInfo, err := s.repo.GetBusinessAdminInformation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.GetTransactionsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	platformFee, err := s.repo.GetPlatformFeeByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	disbursements, err := s.repo.GetDisbursementsByDateRange(businessAdminInfo.PlaceID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := MonthlyStatement{
+		Period:        month,
+		Name:          businessAdminInfo.Name,
+		Transactions:  *transactions,
+		Disbursements: *disbursements,
+		PlatformFee:   platformFee,
+	}
+
+	// platform fee is paid by customer on top of the booking price
+	for _, transaction := range statement.Transactions {
+		statement.NetIncome += transaction.Price
+	}
+	statement.GrossIncome = statement.NetIncome + statement.PlatformFee
+
+	for _, disbursement := range statement.Disbursements {
+		if disbursement.Status == util.XenditDisbursementCompleted {
+			statement.TotalDisbursement += disbursement.Amount
+		}
+	}
+
+	content, err := encodeStatementPDF(statement)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &ReportFile{
+		FileName:    fmt.Sprintf("statement_%s.pdf", month),
+		ContentType: util.ApplicationPDF,
+		Content:     content,
+	}, nil
+}
+
+func (s *service) GetTransactionHistoryDetail(userID int, bookingID int) (*TransactionHistoryDetail, error) {
 	var errorList []string
 
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
 	if bookingID <= 0 {
 		errorList = append(errorList, "bookingID must be above 0")
 	}
@@ -225,6 +670,15 @@ func (s *service) GetTransactionHistoryDetail(bookingID int) (*TransactionHistor
 		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
 	}
 
+	ownerID, err := s.repo.GetBookingOwnerID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID != userID {
+		return nil, errors.Wrap(ErrForbidden, "booking is not for your place")
+	}
+
 	itemsWrapper, err := s.repo.GetItemsWrapper(bookingID)
 	if err != nil {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
@@ -297,7 +751,7 @@ func (s *service) GetPlaceDetail(userID int) (*PlaceDetail, error) {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	placeDetail, err := s.placeService.GetDetail(placeID)
+	placeDetail, err := s.placeService.GetDetail(placeID, 0)
 	if err != nil {
 		return nil, err
 	}
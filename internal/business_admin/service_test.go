@@ -11,9 +11,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/tkuchiki/faketime"
 	xendit2 "github.com/xendit/xendit-go"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/place"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type MockRepository struct {
@@ -43,6 +45,11 @@ func (m *MockRepository) GetListTransactionsHistoryWithPagination(params ListTra
 	return &ret, args.Error(1)
 }
 
+func (m *MockRepository) GetBookingOwnerID(bookingID int) (int, error) {
+	args := m.Called(bookingID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockRepository) GetTransactionHistoryDetail(bookingID int) (*TransactionHistoryDetail, error) {
 	args := m.Called(bookingID)
 	ret := args.Get(0).(TransactionHistoryDetail)
@@ -72,16 +79,91 @@ func (m *MockRepository) SaveDisbursement(disbursement DisbursementDetail) (int,
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockRepository) UpdateBalance(newBalance float64, userID int) error {
-	args := m.Called(newBalance, userID)
+func (m *MockRepository) SettleDisbursement(xenditID string, status int, debit float64, userID int, event notification.Event) (bool, error) {
+	args := m.Called(xenditID, status, debit, userID, event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetDisbursementByXenditID(xenditID string) (*DisbursementDetail, error) {
+	args := m.Called(xenditID)
+	return args.Get(0).(*DisbursementDetail), args.Error(1)
+}
+
+func (m *MockRepository) GetPendingDisbursements() (*[]DisbursementDetail, error) {
+	args := m.Called()
+	return args.Get(0).(*[]DisbursementDetail), args.Error(1)
+}
+
+func (m *MockRepository) GetPayoutSchedule(userID int) (*PayoutSchedule, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*PayoutSchedule), args.Error(1)
+}
+
+func (m *MockRepository) UpdatePayoutSchedule(userID int, schedule PayoutSchedule) error {
+	args := m.Called(userID, schedule)
 	return args.Error(0)
 }
 
-func (m *MockRepository) UpdateDisbursementStatusByXenditID(newStatus int, xenditID string) error {
-	args := m.Called(newStatus, xenditID)
+func (m *MockRepository) GetPayoutDetails(userID int) (*PayoutDetails, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*PayoutDetails), args.Error(1)
+}
+
+func (m *MockRepository) UpdatePayoutDetails(userID int, details PayoutDetails) error {
+	args := m.Called(userID, details)
 	return args.Error(0)
 }
 
+func (m *MockRepository) GetPasswordByUserID(userID int) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) CheckIfBankAccountIsUsed(userID int, bankAccountNumber string) (bool, error) {
+	args := m.Called(userID, bankAccountNumber)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetTransactionsByDateRange(userID int, startDate, endDate string) (*[]Transaction, error) {
+	args := m.Called(userID, startDate, endDate)
+	return args.Get(0).(*[]Transaction), args.Error(1)
+}
+
+func (m *MockRepository) GetItemSalesByDateRange(userID int, startDate, endDate string) (*[]ItemSales, error) {
+	args := m.Called(userID, startDate, endDate)
+	return args.Get(0).(*[]ItemSales), args.Error(1)
+}
+
+func (m *MockRepository) GetDisbursementsByDateRange(placeID int, startDate, endDate string) (*[]DisbursementDetail, error) {
+	args := m.Called(placeID, startDate, endDate)
+	return args.Get(0).(*[]DisbursementDetail), args.Error(1)
+}
+
+func (m *MockRepository) GetPlatformFeeByDateRange(userID int, startDate, endDate string) (float64, error) {
+	args := m.Called(userID, startDate, endDate)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockRepository) GetScheduledPayoutOwners() (*[]ScheduledPayoutOwner, error) {
+	args := m.Called()
+	return args.Get(0).(*[]ScheduledPayoutOwner), args.Error(1)
+}
+
+func (m *MockRepository) GetLatestPayout(placeID int, period string) (*DisbursementDetail, error) {
+	args := m.Called(placeID, period)
+	return args.Get(0).(*DisbursementDetail), args.Error(1)
+}
+
+func (m *MockRepository) CountPendingDisbursements(placeID int) (int, error) {
+	args := m.Called(placeID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) GetLastDisbursementID(placeID int) (int, error) {
+	args := m.Called(placeID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockRepository) UpdateProfile(editProfileRequest EditProfileRequest) error {
 	args := m.Called(editProfileRequest)
 	return args.Error(0)
@@ -111,6 +193,26 @@ func (x *MockXenditService) GetDisbursement(ID string) (*xendit2.Disbursement, e
 	return args.Get(0).(*xendit2.Disbursement), args.Error(1)
 }
 
+func (x *MockXenditService) GetDisbursementsByExternalID(externalID string) ([]xendit2.Disbursement, error) {
+	args := x.Called(externalID)
+	return args.Get(0).([]xendit2.Disbursement), args.Error(1)
+}
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+// newMockNotificationService accept every notification, assert on a new MockNotificationService to check the event
+func newMockNotificationService() *MockNotificationService {
+	mockNotification := new(MockNotificationService)
+	mockNotification.On("Notify", mock.Anything).Maybe()
+	return mockNotification
+}
+
+func (m *MockNotificationService) Notify(event notification.Event) {
+	m.Called(event)
+}
+
 type MockPlaceService struct {
 	mock.Mock
 }
@@ -122,8 +224,8 @@ func (x *MockPlaceService) GetPlaceListWithPagination(params place.PlacesListReq
 	return placeList, &pagination, args.Error(2)
 }
 
-func (x *MockPlaceService) GetDetail(placeID int) (*place.Detail, error) {
-	args := x.Called(placeID)
+func (x *MockPlaceService) GetDetail(placeID int, userID int) (*place.Detail, error) {
+	args := x.Called(placeID, userID)
 	return args.Get(0).(*place.Detail), args.Error(1)
 }
 
@@ -134,6 +236,25 @@ func (x *MockPlaceService) GetListReviewAndRatingWithPagination(params place.Lis
 	return ret, &pagination, args.Error(2)
 }
 
+func (x *MockPlaceService) BackfillStats() (int64, error) {
+	args := x.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (x *MockPlaceService) AddFavorite(userID int, placeID int) error {
+	return x.Called(userID, placeID).Error(0)
+}
+
+func (x *MockPlaceService) RemoveFavorite(userID int, placeID int) error {
+	return x.Called(userID, placeID).Error(0)
+}
+
+func (x *MockPlaceService) GetFavoritesWithPagination(params place.FavoriteListRequest) (*place.PlacesList, *util.Pagination, error) {
+	args := x.Called(params)
+	pagination := args.Get(1).(util.Pagination)
+	return args.Get(0).(*place.PlacesList), &pagination, args.Error(2)
+}
+
 func TestService_GetBalanceDetailSuccess(t *testing.T) {
 	userID := 1
 	placeID := 2
@@ -148,7 +269,7 @@ func TestService_GetBalanceDetailSuccess(t *testing.T) {
 	}
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	mockRepo.On("GetPlaceIDByUserID", userID).Return(placeID, nil)
 	mockRepo.On("GetLatestDisbursement", placeID).Return(latestDisbursement, nil)
@@ -170,7 +291,7 @@ func TestService_GetBalanceDetailWithWrongInput(t *testing.T) {
 	userID := 0
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	balanceDetail, err := mockService.GetBalanceDetail(userID)
 
@@ -182,7 +303,7 @@ func TestService_GetBalanceDetailFailedCalledGetPlaceIDByUserID(t *testing.T) {
 	userID := 10
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	mockRepo.On("GetPlaceIDByUserID", userID).Return(0, ErrInternalServerError)
 
@@ -199,7 +320,7 @@ func TestService_GetBalanceDetailFailedCalledGetLatestDisbursement(t *testing.T)
 	var disbursementsDetail DisbursementDetail
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	mockRepo.On("GetPlaceIDByUserID", userID).Return(placeID, nil)
 	mockRepo.On("GetLatestDisbursement", placeID).Return(disbursementsDetail, ErrInternalServerError)
@@ -218,7 +339,7 @@ func TestService_GetBalanceDetailFailedCalledGetBalance(t *testing.T) {
 	var balanceDetail BalanceDetail
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	mockRepo.On("GetPlaceIDByUserID", userID).Return(placeID, nil)
 	mockRepo.On("GetLatestDisbursement", placeID).Return(disbursementsDetail, nil)
@@ -255,7 +376,7 @@ func TestService_GetListTransactionHistoryWithPaginationSuccess(t *testing.T) {
 
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	params := ListTransactionRequest{
 		Limit:  10,
@@ -306,7 +427,7 @@ func TestService_GetListTransactionHistoryWithPaginationSuccessWithDefaultParam(
 
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	paramsDefault := ListTransactionRequest{
 		Limit:  10,
@@ -336,7 +457,7 @@ func TestService_GetListTransactionHistoryWithPaginationFailedLimitExceedMaxLimi
 
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	// Test
 	listTransactionResult, _, err := mockService.GetListTransactionsHistoryWithPagination(params)
@@ -357,7 +478,7 @@ func TestService_GetListItemByIDWithPaginationError(t *testing.T) {
 
 	// Mock DB
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	mockRepo.On("GetListTransactionsHistoryWithPagination", params).Return(listTransaction, ErrInternalServerError)
 
@@ -379,7 +500,7 @@ func TestService_GetListItemWithPaginationFailedURLIsEmpty(t *testing.T) {
 
 	// Init mock repo and mock service
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 	// Test
 	listTransactionResult, _, err := mockService.GetListTransactionsHistoryWithPagination(params)
@@ -392,7 +513,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
 		defer f.Undo()
@@ -425,6 +546,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 			Amount:            4450,
 			Description:       fmt.Sprintf("Disbursement by %s", businessAdminInfo.Name),
 			Email:             []string{businessAdminInfo.Email},
+			IdempotencyKey:    "disbursement-1-3",
 		}
 
 		createXenditDisbursement := xendit2.Disbursement{ID: "1", Amount: 4450}
@@ -446,6 +568,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 
 		mockRepo.On("GetBusinessAdminInformation", 1).Return(businessAdminInfo, nil)
 		mockRepo.On("GetLatestDisbursement", 1).Return(lastDisbursementInfo, nil)
+		mockRepo.On("GetLastDisbursementID", 1).Return(3, nil)
 		mockXendit.On("CreateDisbursement", xenditDisbursementParams).Return(&createXenditDisbursement, nil)
 		mockRepo.On("SaveDisbursement", disbursement).Return(1, nil)
 
@@ -458,7 +581,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 	t.Run("error while calling SaveDisbursement", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
 		defer f.Undo()
@@ -491,6 +614,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 			Amount:            4450,
 			Description:       fmt.Sprintf("Disbursement by %s", businessAdminInfo.Name),
 			Email:             []string{businessAdminInfo.Email},
+			IdempotencyKey:    "disbursement-1-3",
 		}
 
 		createXenditDisbursement := xendit2.Disbursement{ID: "1", Amount: 4450}
@@ -505,6 +629,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 
 		mockRepo.On("GetBusinessAdminInformation", 1).Return(businessAdminInfo, nil)
 		mockRepo.On("GetLatestDisbursement", 1).Return(lastDisbursementInfo, nil)
+		mockRepo.On("GetLastDisbursementID", 1).Return(3, nil)
 		mockXendit.On("CreateDisbursement", xenditDisbursementParams).Return(&createXenditDisbursement, nil)
 		mockRepo.On("SaveDisbursement", disbursement).Return(1, errors.Wrap(ErrInternalServerError, "test error"))
 
@@ -517,7 +642,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 	t.Run("error while calling CreateDisbursement", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
 		defer f.Undo()
@@ -550,12 +675,14 @@ func TestService_CreateDisbursement(t *testing.T) {
 			Amount:            4450,
 			Description:       fmt.Sprintf("Disbursement by %s", businessAdminInfo.Name),
 			Email:             []string{businessAdminInfo.Email},
+			IdempotencyKey:    "disbursement-1-3",
 		}
 
 		createXenditDisbursement := xendit2.Disbursement{ID: "1", Amount: 4450}
 
 		mockRepo.On("GetBusinessAdminInformation", 1).Return(businessAdminInfo, nil)
 		mockRepo.On("GetLatestDisbursement", 1).Return(lastDisbursementInfo, nil)
+		mockRepo.On("GetLastDisbursementID", 1).Return(3, nil)
 		mockXendit.On("CreateDisbursement", xenditDisbursementParams).Return(&createXenditDisbursement, errors.Wrap(ErrInternalServerError, "test error"))
 
 		resp, err := service.CreateDisbursement(1, 10000)
@@ -567,7 +694,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 	t.Run("error while calling GetLatestDisbursement", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
 		defer f.Undo()
@@ -602,10 +729,32 @@ func TestService_CreateDisbursement(t *testing.T) {
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 
+	t.Run("error while calling GetLastDisbursementID", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockXendit := new(MockXenditService)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
+
+		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
+		defer f.Undo()
+		f.Do()
+
+		businessAdminInfo := InfoForDisbursement{ID: 1, Name: "test", PlaceID: 1}
+		lastDisbursementInfo := DisbursementDetail{ID: 1, PlaceID: 1, Date: time.Date(2022, 03, 01, 0, 0, 0, 0, time.Local)}
+
+		mockRepo.On("GetBusinessAdminInformation", 1).Return(businessAdminInfo, nil)
+		mockRepo.On("GetLatestDisbursement", 1).Return(lastDisbursementInfo, nil)
+		mockRepo.On("GetLastDisbursementID", 1).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		resp, err := service.CreateDisbursement(1, 10000)
+		assert.Nil(t, resp)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		mockXendit.AssertNotCalled(t, "CreateDisbursement", mock.Anything)
+	})
+
 	t.Run("error while input validation", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		resp, err := service.CreateDisbursement(-1, -10000)
 		assert.NotNil(t, err)
@@ -616,7 +765,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 	t.Run("error while calling GetBusinessAdminInformation", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
 		defer f.Undo()
@@ -642,7 +791,7 @@ func TestService_CreateDisbursement(t *testing.T) {
 	t.Run("input validation error when last disbursement is yesterday", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockXendit := new(MockXenditService)
-		service := NewService(mockRepo, mockXendit, nil)
+		service := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
 
 		f := faketime.NewFaketime(2022, 04, 02, 0, 0, 0, 0, time.Local)
 		defer f.Undo()
@@ -679,9 +828,13 @@ func TestService_CreateDisbursement(t *testing.T) {
 }
 
 func TestService_DisbursementCallbackFromXendit(t *testing.T) {
+	completedEvent := notification.Event{Type: util.NotificationDisbursementCompleted, UserID: 1, Amount: 4450}
+	failedEvent := notification.Event{Type: util.NotificationDisbursementFailed, UserID: 1, Amount: 10000}
+
 	t.Run("success status completed", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		mockNotification := new(MockNotificationService)
+		service := NewService(mockRepo, nil, nil, mockNotification)
 
 		// input
 		params := DisbursementCallback{
@@ -695,18 +848,18 @@ func TestService_DisbursementCallbackFromXendit(t *testing.T) {
 			Status:                  "COMPLETED",
 		}
 
-		balanceDetail := BalanceDetail{Balance: 10000, LatestDisbursementDate: time.Now().String()}
-		mockRepo.On("GetBalance", 1).Return(balanceDetail, nil)
-		mockRepo.On("UpdateBalance", 0.0, 1).Return(nil)
-		mockRepo.On("UpdateDisbursementStatusByXenditID", 1, "test").Return(nil)
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{Status: util.XenditDisbursementPending, UserID: 1}, nil)
+		mockRepo.On("SettleDisbursement", "test", util.XenditDisbursementCompleted, 10000.0, 1, completedEvent).Return(true, nil)
+		mockNotification.On("Notify", completedEvent)
 
 		err := service.DisbursementCallbackFromXendit(params)
 		assert.Nil(t, err)
+		mockNotification.AssertExpectations(t)
 	})
 
 	t.Run("failed status", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		service := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 		// input
 		params := DisbursementCallback{
@@ -725,9 +878,9 @@ func TestService_DisbursementCallbackFromXendit(t *testing.T) {
 		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 	})
 
-	t.Run("failed calling update disbursement status", func(t *testing.T) {
+	t.Run("failed settle disbursement", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		service := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 		// input
 		params := DisbursementCallback{
@@ -741,19 +894,17 @@ func TestService_DisbursementCallbackFromXendit(t *testing.T) {
 			Status:                  "COMPLETED",
 		}
 
-		balanceDetail := BalanceDetail{Balance: 10000, LatestDisbursementDate: time.Now().String()}
-		mockRepo.On("GetBalance", 1).Return(balanceDetail, nil)
-		mockRepo.On("UpdateBalance", 0.0, 1).Return(nil)
-		mockRepo.On("UpdateDisbursementStatusByXenditID", 1, "test").Return(errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{Status: util.XenditDisbursementPending, UserID: 1}, nil)
+		mockRepo.On("SettleDisbursement", "test", util.XenditDisbursementCompleted, 10000.0, 1, completedEvent).Return(false, errors.Wrap(ErrInternalServerError, "test error"))
 
 		err := service.DisbursementCallbackFromXendit(params)
 		assert.NotNil(t, err)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 
-	t.Run("failed calling update disbursement status on failed callback case", func(t *testing.T) {
+	t.Run("failed settle disbursement on failed callback case", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		service := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 		// input
 		params := DisbursementCallback{
@@ -767,41 +918,38 @@ func TestService_DisbursementCallbackFromXendit(t *testing.T) {
 			Status:                  "FAILED",
 		}
 
-		mockRepo.On("UpdateDisbursementStatusByXenditID", 2, "test").Return(errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{Status: util.XenditDisbursementPending, UserID: 1}, nil)
+		mockRepo.On("SettleDisbursement", "test", util.XenditDisbursementFailed, 0.0, 1, failedEvent).Return(false, errors.Wrap(ErrInternalServerError, "test error"))
 
 		err := service.DisbursementCallbackFromXendit(params)
 		assert.NotNil(t, err)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 
-	t.Run("failed calling update balance", func(t *testing.T) {
+	t.Run("success disbursement settled by concurrent callback", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		mockNotification := new(MockNotificationService)
+		service := NewService(mockRepo, nil, nil, mockNotification)
 
 		// input
 		params := DisbursementCallback{
-			ID:                      "test",
-			ExternalID:              "1",
-			Amount:                  4450,
-			BankCode:                "BCA",
-			AccountHolderName:       "TEST",
-			DisbursementDescription: "test",
-			FailureCode:             "",
-			Status:                  "COMPLETED",
+			ID:         "test",
+			ExternalID: "1",
+			Amount:     4450,
+			Status:     "COMPLETED",
 		}
 
-		balanceDetail := BalanceDetail{Balance: 10000, LatestDisbursementDate: time.Now().String()}
-		mockRepo.On("GetBalance", 1).Return(balanceDetail, nil)
-		mockRepo.On("UpdateBalance", 0.0, 1).Return(errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{Status: util.XenditDisbursementPending, UserID: 1}, nil)
+		mockRepo.On("SettleDisbursement", "test", util.XenditDisbursementCompleted, 10000.0, 1, completedEvent).Return(false, nil)
 
 		err := service.DisbursementCallbackFromXendit(params)
-		assert.NotNil(t, err)
-		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, err)
+		mockNotification.AssertNotCalled(t, "Notify", mock.Anything)
 	})
 
-	t.Run("failed when calling get balance", func(t *testing.T) {
+	t.Run("success status failed", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		service := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 		// input
 		params := DisbursementCallback{
@@ -812,58 +960,75 @@ func TestService_DisbursementCallbackFromXendit(t *testing.T) {
 			AccountHolderName:       "TEST",
 			DisbursementDescription: "test",
 			FailureCode:             "",
-			Status:                  "COMPLETED",
+			Status:                  "FAILED",
 		}
 
-		balanceDetail := BalanceDetail{Balance: 10000, LatestDisbursementDate: time.Now().String()}
-		mockRepo.On("GetBalance", 1).Return(balanceDetail, errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{Status: util.XenditDisbursementPending, UserID: 1}, nil)
+		mockRepo.On("SettleDisbursement", "test", util.XenditDisbursementFailed, 0.0, 1, failedEvent).Return(true, nil)
 
 		err := service.DisbursementCallbackFromXendit(params)
-		assert.NotNil(t, err)
-		assert.Error(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, err)
 	})
 
-	t.Run("success status failed", func(t *testing.T) {
+	t.Run("success already applied", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		service := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 		// input
 		params := DisbursementCallback{
-			ID:                      "test",
-			ExternalID:              "1",
-			Amount:                  10000,
-			BankCode:                "BCA",
-			AccountHolderName:       "TEST",
-			DisbursementDescription: "test",
-			FailureCode:             "",
-			Status:                  "FAILED",
+			ID:         "test",
+			ExternalID: "1",
+			Amount:     4450,
+			Status:     "COMPLETED",
 		}
 
-		mockRepo.On("UpdateDisbursementStatusByXenditID", 2, "test").Return(nil)
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{Status: util.XenditDisbursementCompleted}, nil)
 
 		err := service.DisbursementCallbackFromXendit(params)
+		mockRepo.AssertExpectations(t)
 		assert.Nil(t, err)
 	})
 
-	t.Run("failed parse user id", func(t *testing.T) {
+	t.Run("failed get disbursement", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := NewService(mockRepo, nil, nil)
+		service := NewService(mockRepo, nil, nil, newMockNotificationService())
 
 		// input
 		params := DisbursementCallback{
-			ID:                      "test",
-			ExternalID:              "test",
-			Amount:                  10000,
-			BankCode:                "BCA",
-			AccountHolderName:       "TEST",
-			DisbursementDescription: "test",
-			FailureCode:             "",
-			Status:                  "FAILED",
+			ID:         "test",
+			ExternalID: "1",
+			Amount:     4450,
+			Status:     "COMPLETED",
 		}
 
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{}, errors.Wrap(ErrNotFound, "test error"))
+
 		err := service.DisbursementCallbackFromXendit(params)
-		assert.NotNil(t, err)
-		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("success payout settle the owner of the disbursement", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		service := NewService(mockRepo, nil, nil, mockNotification)
+
+		// input
+		params := DisbursementCallback{
+			ID:         "test",
+			ExternalID: "payout-2-2022-05",
+			Amount:     10000,
+			Status:     "FAILED",
+		}
+
+		event := notification.Event{Type: util.NotificationDisbursementFailed, UserID: 3, Amount: 10000}
+		mockRepo.On("GetDisbursementByXenditID", "test").Return(&DisbursementDetail{PlaceID: 2, UserID: 3, Status: util.XenditDisbursementPending}, nil)
+		mockRepo.On("SettleDisbursement", "test", util.XenditDisbursementFailed, 0.0, 3, event).Return(true, nil)
+		mockNotification.On("Notify", event)
+
+		err := service.DisbursementCallbackFromXendit(params)
+		assert.Nil(t, err)
+		mockRepo.AssertExpectations(t)
+		mockNotification.AssertExpectations(t)
 	})
 }
 
@@ -871,9 +1036,9 @@ func TestService_GetTransactionHistoryDetailWithWrongInput(t *testing.T) {
 	bookingID := 0
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
-	balanceDetail, err := mockService.GetTransactionHistoryDetail(bookingID)
+	balanceDetail, err := mockService.GetTransactionHistoryDetail(1, bookingID)
 
 	assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 	assert.Nil(t, balanceDetail)
@@ -910,13 +1075,14 @@ func TestService_GetTransactionHistoryDetailSuccess(t *testing.T) {
 	}
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
+	mockRepo.On("GetBookingOwnerID", bookingID).Return(1, nil)
 	mockRepo.On("GetItemsWrapper", bookingID).Return(itemsWrapper, nil)
 	mockRepo.On("GetCustomerForTransactionHistoryDetail", bookingID).Return(customer, nil)
 	mockRepo.On("GetTransactionHistoryDetail", bookingID).Return(transactionHistoryDetail, nil)
 
-	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(bookingID)
+	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	transactionHistoryDetail.CustomerName = customer.CustomerName
@@ -933,11 +1099,12 @@ func TestService_GetTransactionHistoryDetailFailedCalledGetItemsWrapper(t *testi
 	var itemsWrapper ItemsWrapper
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
+	mockRepo.On("GetBookingOwnerID", bookingID).Return(1, nil)
 	mockRepo.On("GetItemsWrapper", bookingID).Return(itemsWrapper, ErrInternalServerError)
 
-	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(bookingID)
+	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -950,12 +1117,13 @@ func TestService_GetTransactionHistoryDetailFailedCalledGetCustomerForTransactio
 	var customer CustomerForTrasactionHistoryDetail
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
+	mockRepo.On("GetBookingOwnerID", bookingID).Return(1, nil)
 	mockRepo.On("GetItemsWrapper", bookingID).Return(itemsWrapper, nil)
 	mockRepo.On("GetCustomerForTransactionHistoryDetail", bookingID).Return(customer, ErrInternalServerError)
 
-	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(bookingID)
+	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
@@ -969,19 +1137,43 @@ func TestService_GetTransactionHistoryDetailFailedCalledGetTransactionHistoryDet
 	var transactionHistoryDetail TransactionHistoryDetail
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, nil, nil)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
 
+	mockRepo.On("GetBookingOwnerID", bookingID).Return(1, nil)
 	mockRepo.On("GetItemsWrapper", bookingID).Return(itemsWrapper, nil)
 	mockRepo.On("GetCustomerForTransactionHistoryDetail", bookingID).Return(customer, nil)
 	mockRepo.On("GetTransactionHistoryDetail", bookingID).Return(transactionHistoryDetail, ErrInternalServerError)
 
-	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(bookingID)
+	transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(1, bookingID)
 	mockRepo.AssertExpectations(t)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	assert.Nil(t, transactionHistoryDetailResult)
 }
 
+func TestService_GetTransactionHistoryDetailOwnership(t *testing.T) {
+	t.Run("booking of another place", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+		mockRepo.On("GetBookingOwnerID", 1).Return(2, nil)
+
+		transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(3, 1)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+		assert.Nil(t, transactionHistoryDetailResult)
+		mockRepo.AssertNotCalled(t, "GetTransactionHistoryDetail", 1)
+	})
+
+	t.Run("booking not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+		mockRepo.On("GetBookingOwnerID", 1).Return(0, errors.Wrap(ErrNotFound, "test error"))
+
+		transactionHistoryDetailResult, err := mockService.GetTransactionHistoryDetail(3, 1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		assert.Nil(t, transactionHistoryDetailResult)
+	})
+}
+
 func TestService_PutEditProfileSuccess(t *testing.T) {
 	userID := 1
 	name := "ini contoh name"
@@ -995,7 +1187,7 @@ func TestService_PutEditProfileSuccess(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	mockRepo.On("UpdateProfile", bodyRequest).Return(nil)
 
@@ -1018,7 +1210,7 @@ func TestService_PutEditProfileFailedCalledUpdateProfile(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	mockRepo.On("UpdateProfile", bodyRequest).Return(ErrInternalServerError)
 
@@ -1041,7 +1233,7 @@ func TestService_PutEditProfileNoNameValidationError(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	err := mockService.PutEditProfile(bodyRequest)
 
@@ -1062,7 +1254,7 @@ func TestService_PutEditProfileLengthNameValidationError(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	err := mockService.PutEditProfile(bodyRequest)
 
@@ -1083,7 +1275,7 @@ func TestService_PutEditProfileNoDescValidationError(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	err := mockService.PutEditProfile(bodyRequest)
 
@@ -1104,7 +1296,7 @@ func TestService_PutEditProfileMinLengthDescValidationError(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	err := mockService.PutEditProfile(bodyRequest)
 
@@ -1131,7 +1323,7 @@ func TestService_PutEditProfileMaxLengthDescValidationError(t *testing.T) {
 
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	err := mockService.PutEditProfile(bodyRequest)
 
@@ -1143,7 +1335,7 @@ func TestService_GetPlaceDetail(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockPlace := new(MockPlaceService)
-		mockService := NewService(mockRepo, nil, mockPlace)
+		mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 		userID := 1
 		placeID := 2
@@ -1174,7 +1366,7 @@ func TestService_GetPlaceDetail(t *testing.T) {
 			},
 		}
 
-		mockPlace.On("GetDetail", placeID).Return(&placeDetail, nil)
+		mockPlace.On("GetDetail", placeID, 0).Return(&placeDetail, nil)
 
 		expectedOutput := PlaceDetail{
 			ID:            1,
@@ -1198,7 +1390,7 @@ func TestService_GetPlaceDetail(t *testing.T) {
 	t.Run("error while calling GetPlaceIDByUserID", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockPlace := new(MockPlaceService)
-		mockService := NewService(mockRepo, nil, mockPlace)
+		mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 		userID := 1
 
@@ -1214,7 +1406,7 @@ func TestService_GetPlaceDetail(t *testing.T) {
 	t.Run("error while calling GetPlaceIDByUserID", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockPlace := new(MockPlaceService)
-		mockService := NewService(mockRepo, nil, mockPlace)
+		mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 		userID := 1
 		placeID := 2
@@ -1223,7 +1415,7 @@ func TestService_GetPlaceDetail(t *testing.T) {
 
 		var placeDetail place.Detail
 
-		mockPlace.On("GetDetail", placeID).Return(&placeDetail, ErrInternalServerError)
+		mockPlace.On("GetDetail", placeID, 0).Return(&placeDetail, ErrInternalServerError)
 
 		resp, err := mockService.GetPlaceDetail(userID)
 		mockRepo.AssertExpectations(t)
@@ -1235,7 +1427,7 @@ func TestService_GetPlaceDetail(t *testing.T) {
 	t.Run("error while input validation", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockPlace := new(MockPlaceService)
-		mockService := NewService(mockRepo, nil, mockPlace)
+		mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 		resp, err := mockService.GetPlaceDetail(-1)
 		mockRepo.AssertExpectations(t)
@@ -1250,7 +1442,7 @@ func TestService_GetListReviewAndRatingWithPaginationSuccess(t *testing.T) {
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := 1
 	placeID := 2
@@ -1319,7 +1511,7 @@ func TestService_GetListReviewAndRatingWithPaginationSuccessWithDefaultParam(t *
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := 1
 	placeID := 2
@@ -1387,7 +1579,7 @@ func TestService_GetListReviewAndRatingWithPaginationFailedLimitExceedMaxLimit(t
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := 1
 	placeID := 2
@@ -1412,7 +1604,7 @@ func TestService_GetListReviewAndRatingWithPaginationFailedPathEmpty(t *testing.
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := 1
 	placeID := 2
@@ -1437,7 +1629,7 @@ func TestService_GetListReviewAndRatingWithPaginationCalledGetPlaceIDByUserIDNeg
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := -1
 
@@ -1460,7 +1652,7 @@ func TestService_GetListReviewAndRatingWithPaginationFailedCalledGetPlaceIDByUse
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := 1
 
@@ -1486,7 +1678,7 @@ func TestService_GetListReviewAndRatingWithPaginationFailedCalledPlaceService(t
 	// Init mock repository and mock service
 	mockRepo := new(MockRepository)
 	mockPlace := new(MockPlaceService)
-	mockService := NewService(mockRepo, nil, mockPlace)
+	mockService := NewService(mockRepo, nil, mockPlace, newMockNotificationService())
 
 	userID := 1
 	placeID := 2
@@ -1521,3 +1713,616 @@ func TestService_GetListReviewAndRatingWithPaginationFailedCalledPlaceService(t
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	assert.Nil(t, listReviewResult)
 }
+
+func TestService_GetPendingDisbursements(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+	expected := []DisbursementDetail{{ID: 1, PlaceID: 1, XenditID: "test", Amount: 10000, Status: util.XenditDisbursementPending}}
+	mockRepo.On("GetPendingDisbursements").Return(&expected, nil)
+
+	disbursements, err := mockService.GetPendingDisbursements()
+	mockRepo.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &expected, disbursements)
+}
+
+func TestService_GetPayoutSchedule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		expected := PayoutSchedule{Schedule: util.PayoutWeekly, MinimumBalance: 50000}
+		mockRepo.On("GetPayoutSchedule", 1).Return(&expected, nil)
+
+		schedule, err := mockService.GetPayoutSchedule(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &expected, schedule)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), nil, nil, newMockNotificationService())
+
+		schedule, err := mockService.GetPayoutSchedule(0)
+		assert.Nil(t, schedule)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_UpdatePayoutSchedule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		params := PayoutSchedule{Schedule: util.PayoutMonthly, MinimumBalance: 50000}
+		mockRepo.On("UpdatePayoutSchedule", 1, params).Return(nil)
+
+		err := mockService.UpdatePayoutSchedule(1, params)
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		testCases := []PayoutSchedule{
+			{Schedule: 3, MinimumBalance: 0},
+			{Schedule: -1, MinimumBalance: 0},
+			{Schedule: util.PayoutWeekly, MinimumBalance: -1},
+		}
+
+		for _, params := range testCases {
+			mockService := NewService(new(MockRepository), nil, nil, newMockNotificationService())
+
+			err := mockService.UpdatePayoutSchedule(1, params)
+			assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		}
+	})
+}
+
+func TestService_GetPayoutDetails(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		details := PayoutDetails{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "TEST"}
+		mockRepo.On("GetPayoutDetails", 1).Return(&details, nil)
+
+		result, err := mockService.GetPayoutDetails(1)
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, err)
+		assert.Equal(t, &details, result)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), nil, nil, newMockNotificationService())
+
+		result, err := mockService.GetPayoutDetails(0)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_UpdatePayoutDetails(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	params := UpdatePayoutDetailsRequest{
+		BankCode:          "BNI",
+		BankAccountNumber: "009-123456789",
+		BankAccountName:   "Test Name",
+		Password:          "password",
+	}
+	details := PayoutDetails{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "Test Name"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetPasswordByUserID", 1).Return(string(hashedPassword), nil)
+		mockRepo.On("CheckIfBankAccountIsUsed", 1, params.BankAccountNumber).Return(false, nil)
+		mockRepo.On("UpdatePayoutDetails", 1, details).Return(nil)
+
+		result, err := mockService.UpdatePayoutDetails(1, params)
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, err)
+		assert.Equal(t, &details, result)
+	})
+
+	t.Run("success e-wallet", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		eWalletParams := UpdatePayoutDetailsRequest{BankCode: "OVO", BankAccountNumber: "081234567890", BankAccountName: "Test Name", Password: "password"}
+		eWalletDetails := PayoutDetails{BankCode: "OVO", BankAccountNumber: "081234567890", BankAccountName: "Test Name"}
+
+		mockRepo.On("GetPasswordByUserID", 1).Return(string(hashedPassword), nil)
+		mockRepo.On("CheckIfBankAccountIsUsed", 1, eWalletParams.BankAccountNumber).Return(false, nil)
+		mockRepo.On("UpdatePayoutDetails", 1, eWalletDetails).Return(nil)
+
+		result, err := mockService.UpdatePayoutDetails(1, eWalletParams)
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, err)
+		assert.Equal(t, &eWalletDetails, result)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		testCases := []UpdatePayoutDetailsRequest{
+			{BankCode: "UNKNOWN", BankAccountNumber: "009-123456789", BankAccountName: "Test Name", Password: "password"},
+			{BankCode: "BNI", BankAccountNumber: "009123456789", BankAccountName: "Test Name", Password: "password"},
+			{BankCode: "OVO", BankAccountNumber: "009-123456789", BankAccountName: "Test Name", Password: "password"},
+			{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "T3st", Password: "password"},
+			{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "Te", Password: "password"},
+			{BankCode: "BNI", BankAccountNumber: "009-123456789", BankAccountName: "Test Name"},
+		}
+
+		for _, testCase := range testCases {
+			mockService := NewService(new(MockRepository), nil, nil, newMockNotificationService())
+
+			result, err := mockService.UpdatePayoutDetails(1, testCase)
+			assert.Nil(t, result)
+			assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		}
+	})
+
+	t.Run("failed get password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetPasswordByUserID", 1).Return("", errors.Wrap(ErrInternalServerError, "test error"))
+
+		result, err := mockService.UpdatePayoutDetails(1, params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed wrong password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetPasswordByUserID", 1).Return(string(hashedPassword), nil)
+
+		wrongPassword := params
+		wrongPassword.Password = "wrong password"
+
+		result, err := mockService.UpdatePayoutDetails(1, wrongPassword)
+		mockRepo.AssertNotCalled(t, "UpdatePayoutDetails", mock.Anything, mock.Anything)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+	})
+
+	t.Run("failed bank account is used", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetPasswordByUserID", 1).Return(string(hashedPassword), nil)
+		mockRepo.On("CheckIfBankAccountIsUsed", 1, params.BankAccountNumber).Return(true, nil)
+
+		result, err := mockService.UpdatePayoutDetails(1, params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed update payout details", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetPasswordByUserID", 1).Return(string(hashedPassword), nil)
+		mockRepo.On("CheckIfBankAccountIsUsed", 1, params.BankAccountNumber).Return(false, nil)
+		mockRepo.On("UpdatePayoutDetails", 1, details).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		result, err := mockService.UpdatePayoutDetails(1, params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_RunScheduledPayouts(t *testing.T) {
+	now := time.Date(2022, 5, 4, 10, 0, 0, 0, time.UTC)
+	fee := util.XenditDisbursementFee + (util.XenditDisbursementFee * util.XenditVATPercentage)
+
+	newOwner := func(schedule int, balance float64) ScheduledPayoutOwner {
+		return ScheduledPayoutOwner{
+			InfoForDisbursement: InfoForDisbursement{ID: 1, Name: "test", Email: "test@test.com", BankAccountName: "TEST", BankAccountNumber: "123", PlaceID: 2},
+			Balance:             balance,
+			Schedule:            schedule,
+			MinimumBalance:      50000,
+		}
+	}
+
+	t.Run("success create weekly payout", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockXendit := new(MockXenditService)
+		mockService := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
+
+		owners := []ScheduledPayoutOwner{newOwner(util.PayoutWeekly, 100000)}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-W18").Return(&DisbursementDetail{}, errors.Wrap(ErrNotFound, "test error"))
+		mockRepo.On("CountPendingDisbursements", 2).Return(0, nil)
+		mockXendit.On("CreateDisbursement", xendit.CreateDisbursementParams{
+			ID:                1,
+			BankAccountName:   "TEST",
+			BankAccountNumber: "123",
+			Amount:            100000 - fee,
+			Description:       "Scheduled payout 2022-W18 by test",
+			Email:             []string{"test@test.com"},
+			IdempotencyKey:    "payout-2-2022-W18",
+			ExternalID:        "payout-2-2022-W18",
+		}).Return(&xendit2.Disbursement{ID: "xendit id", Amount: 100000 - fee}, nil)
+		mockRepo.On("SaveDisbursement", DisbursementDetail{
+			PlaceID:      2,
+			Date:         now,
+			XenditID:     "xendit id",
+			Amount:       100000 - fee,
+			Status:       util.XenditDisbursementPending,
+			PayoutPeriod: "2022-W18",
+			Attempt:      1,
+		}).Return(1, nil)
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+		mockRepo.AssertExpectations(t)
+		mockXendit.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &[]ScheduledPayout{
+			{UserID: 1, PlaceID: 2, Period: "2022-W18", Attempt: 1, Amount: 100000 - fee, XenditID: "xendit id"},
+		}, payouts)
+	})
+
+	t.Run("success retry failed monthly payout", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockXendit := new(MockXenditService)
+		mockService := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
+
+		owners := []ScheduledPayoutOwner{newOwner(util.PayoutMonthly, 100000)}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-05").Return(&DisbursementDetail{Status: util.XenditDisbursementFailed, Attempt: 1}, nil)
+		mockRepo.On("CountPendingDisbursements", 2).Return(0, nil)
+		mockXendit.On("GetDisbursementsByExternalID", "payout-2-2022-05").Return([]xendit2.Disbursement{}, nil)
+		mockXendit.On("CreateDisbursement", mock.MatchedBy(func(params xendit.CreateDisbursementParams) bool {
+			return params.IdempotencyKey == "payout-2-2022-05"
+		})).Return(&xendit2.Disbursement{}, errors.Wrap(xendit.ErrXenditCreateDisbursement, "test error"))
+		mockRepo.On("SaveDisbursement", mock.MatchedBy(func(disbursement DisbursementDetail) bool {
+			return disbursement.Status == util.XenditDisbursementFailed && disbursement.Attempt == 2
+		})).Return(1, nil)
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Len(t, *payouts, 1)
+		assert.Equal(t, 2, (*payouts)[0].Attempt)
+		assert.NotEmpty(t, (*payouts)[0].Error)
+	})
+
+	t.Run("success retry payout failed by xendit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockXendit := new(MockXenditService)
+		mockService := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
+
+		owners := []ScheduledPayoutOwner{newOwner(util.PayoutMonthly, 100000)}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-05").Return(&DisbursementDetail{XenditID: "failed id", Status: util.XenditDisbursementFailed, Attempt: 1}, nil)
+		mockRepo.On("CountPendingDisbursements", 2).Return(0, nil)
+		mockXendit.On("GetDisbursementsByExternalID", "payout-2-2022-05").Return([]xendit2.Disbursement{
+			{ID: "failed id", ExternalID: "payout-2-2022-05", Status: util.XenditDisbursementFailedString},
+		}, nil)
+		mockXendit.On("CreateDisbursement", mock.MatchedBy(func(params xendit.CreateDisbursementParams) bool {
+			return params.IdempotencyKey == "payout-2-2022-05-1"
+		})).Return(&xendit2.Disbursement{ID: "xendit id", Amount: 100000 - fee}, nil)
+		mockRepo.On("SaveDisbursement", mock.MatchedBy(func(disbursement DisbursementDetail) bool {
+			return disbursement.XenditID == "xendit id" && disbursement.Status == util.XenditDisbursementPending && disbursement.Attempt == 2
+		})).Return(1, nil)
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+		mockRepo.AssertExpectations(t)
+		mockXendit.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &[]ScheduledPayout{
+			{UserID: 1, PlaceID: 2, Period: "2022-05", Attempt: 2, Amount: 100000 - fee, XenditID: "xendit id"},
+		}, payouts)
+	})
+
+	t.Run("success record disbursement created by failed attempt after the owner is renamed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockXendit := new(MockXenditService)
+		mockService := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
+
+		owners := []ScheduledPayoutOwner{newOwner(util.PayoutMonthly, 100000)}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-05").Return(&DisbursementDetail{Status: util.XenditDisbursementFailed, Attempt: 1}, nil)
+		mockRepo.On("CountPendingDisbursements", 2).Return(0, nil)
+		mockXendit.On("GetDisbursementsByExternalID", "payout-2-2022-05").Return([]xendit2.Disbursement{
+			{ID: "xendit id", Amount: 90000, ExternalID: "payout-2-2022-05", DisbursementDescription: "Scheduled payout 2022-05 by old name", Status: util.XenditDisbursementPendingString},
+		}, nil)
+		mockRepo.On("SaveDisbursement", DisbursementDetail{
+			PlaceID:      2,
+			Date:         now,
+			XenditID:     "xendit id",
+			Amount:       90000,
+			Status:       util.XenditDisbursementPending,
+			PayoutPeriod: "2022-05",
+			Attempt:      2,
+		}).Return(1, nil)
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+		mockRepo.AssertExpectations(t)
+		mockXendit.AssertNotCalled(t, "CreateDisbursement", mock.Anything)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &[]ScheduledPayout{
+			{UserID: 1, PlaceID: 2, Period: "2022-05", Attempt: 2, Amount: 90000, XenditID: "xendit id"},
+		}, payouts)
+	})
+
+	t.Run("failed look up disbursement before retry", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockXendit := new(MockXenditService)
+		mockService := NewService(mockRepo, mockXendit, nil, newMockNotificationService())
+
+		owners := []ScheduledPayoutOwner{newOwner(util.PayoutMonthly, 100000)}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-05").Return(&DisbursementDetail{Status: util.XenditDisbursementFailed, Attempt: 1}, nil)
+		mockRepo.On("CountPendingDisbursements", 2).Return(0, nil)
+		mockXendit.On("GetDisbursementsByExternalID", "payout-2-2022-05").Return([]xendit2.Disbursement{}, errors.Wrap(xendit.ErrXenditGetDisbursement, "test error"))
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+		mockXendit.AssertNotCalled(t, "CreateDisbursement", mock.Anything)
+		mockRepo.AssertNotCalled(t, "SaveDisbursement", mock.Anything)
+
+		assert.Nil(t, err)
+		assert.Len(t, *payouts, 1)
+		assert.NotEmpty(t, (*payouts)[0].Error)
+	})
+
+	t.Run("success skip owner", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockXenditService), nil, newMockNotificationService())
+
+		paidOwner := newOwner(util.PayoutWeekly, 100000)
+		exhaustedOwner := newOwner(util.PayoutWeekly, 100000)
+		exhaustedOwner.PlaceID = 3
+		lowBalanceOwner := newOwner(util.PayoutWeekly, 10000)
+		lowBalanceOwner.PlaceID = 4
+		pendingOwner := newOwner(util.PayoutWeekly, 100000)
+		pendingOwner.PlaceID = 5
+		owners := []ScheduledPayoutOwner{paidOwner, exhaustedOwner, lowBalanceOwner, pendingOwner}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-W18").Return(&DisbursementDetail{Status: util.XenditDisbursementCompleted, Attempt: 1}, nil)
+		mockRepo.On("GetLatestPayout", 3, "2022-W18").Return(&DisbursementDetail{Status: util.XenditDisbursementFailed, Attempt: util.MaximumPayoutAttempt}, nil)
+		mockRepo.On("GetLatestPayout", 4, "2022-W18").Return(&DisbursementDetail{}, errors.Wrap(ErrNotFound, "test error"))
+		mockRepo.On("GetLatestPayout", 5, "2022-W18").Return(&DisbursementDetail{}, errors.Wrap(ErrNotFound, "test error"))
+		mockRepo.On("CountPendingDisbursements", 5).Return(1, nil)
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Empty(t, *payouts)
+	})
+
+	t.Run("success report repo error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockXenditService), nil, newMockNotificationService())
+
+		owner := newOwner(util.PayoutWeekly, 100000)
+		otherOwner := newOwner(util.PayoutWeekly, 100000)
+		otherOwner.PlaceID = 3
+		owners := []ScheduledPayoutOwner{owner, otherOwner}
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&owners, nil)
+		mockRepo.On("GetLatestPayout", 2, "2022-W18").Return(&DisbursementDetail{}, errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("GetLatestPayout", 3, "2022-W18").Return(&DisbursementDetail{}, errors.Wrap(ErrNotFound, "test error"))
+		mockRepo.On("CountPendingDisbursements", 3).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+
+		assert.Nil(t, err)
+		assert.Len(t, *payouts, 2)
+		for _, payout := range *payouts {
+			assert.NotEmpty(t, payout.Error)
+		}
+	})
+
+	t.Run("failed get owners", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockXenditService), nil, newMockNotificationService())
+
+		mockRepo.On("GetScheduledPayoutOwners").Return(&[]ScheduledPayoutOwner{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		payouts, err := mockService.RunScheduledPayouts(now)
+
+		assert.Nil(t, payouts)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetReport(t *testing.T) {
+	params := ReportRequest{
+		UserID:    1,
+		Report:    util.ReportTransactions,
+		Format:    util.ReportFormatCSV,
+		StartDate: "2022-05-01",
+		EndDate:   "2022-05-31",
+	}
+
+	t.Run("success transactions csv", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		transactions := []Transaction{{ID: 1, Name: "test", Price: 10000, Date: "2022-05-01T00:00:00Z"}}
+		mockRepo.On("GetTransactionsByDateRange", 1, "2022-05-01", "2022-05-31").Return(&transactions, nil)
+
+		file, err := mockService.GetReport(params)
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "transactions_2022-05-01_2022-05-31.csv", file.FileName)
+		assert.Equal(t, util.TextCSV, file.ContentType)
+		assert.Equal(t, "ID,Customer,Date,Total Price\n1,test,2022-05-01,10000.00\n", string(file.Content))
+	})
+
+	t.Run("success escape formula in csv", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		itemSales := []ItemSales{{Name: "=HYPERLINK(\"x\")", Qty: 1, TotalPrice: 10000}, {Name: "@kopi", Qty: 2, TotalPrice: -5000}}
+		mockRepo.On("GetItemSalesByDateRange", 1, "2022-05-01", "2022-05-31").Return(&itemSales, nil)
+
+		itemParams := params
+		itemParams.Report = util.ReportItemSales
+
+		file, err := mockService.GetReport(itemParams)
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Item,Qty,Total Price\n\"'=HYPERLINK(\"\"x\"\")\",1,10000.00\n'@kopi,2,-5000.00\n", string(file.Content))
+	})
+
+	t.Run("success item sales xlsx", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		itemSales := []ItemSales{{Name: "kopi", Qty: 3, TotalPrice: 30000}}
+		mockRepo.On("GetItemSalesByDateRange", 1, "2022-05-01", "2022-05-31").Return(&itemSales, nil)
+
+		itemParams := params
+		itemParams.Report = util.ReportItemSales
+		itemParams.Format = util.ReportFormatXLSX
+
+		file, err := mockService.GetReport(itemParams)
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "items_2022-05-01_2022-05-31.xlsx", file.FileName)
+		assert.Equal(t, util.ApplicationXLSX, file.ContentType)
+		assert.Equal(t, "PK", string(file.Content[:2]))
+	})
+
+	t.Run("success disbursements csv", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		disbursements := []DisbursementDetail{
+			{ID: 1, Date: time.Date(2022, 5, 4, 10, 0, 0, 0, time.UTC), XenditID: "xendit id", Amount: 50000, Status: util.XenditDisbursementCompleted},
+		}
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(2, nil)
+		mockRepo.On("GetDisbursementsByDateRange", 2, "2022-05-01", "2022-05-31").Return(&disbursements, nil)
+
+		disbursementParams := params
+		disbursementParams.Report = util.ReportDisbursements
+
+		file, err := mockService.GetReport(disbursementParams)
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Contains(t, string(file.Content), "1,2022-05-04,xendit id,50000.00,COMPLETED,,0")
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		testCases := []ReportRequest{
+			{UserID: 0, Report: util.ReportTransactions, Format: util.ReportFormatCSV, StartDate: "2022-05-01", EndDate: "2022-05-31"},
+			{UserID: 1, Report: "unknown", Format: util.ReportFormatCSV, StartDate: "2022-05-01", EndDate: "2022-05-31"},
+			{UserID: 1, Report: util.ReportTransactions, Format: "doc", StartDate: "2022-05-01", EndDate: "2022-05-31"},
+			{UserID: 1, Report: util.ReportTransactions, Format: util.ReportFormatCSV, StartDate: "01-05-2022", EndDate: "2022-05-31"},
+			{UserID: 1, Report: util.ReportTransactions, Format: util.ReportFormatCSV, StartDate: "2022-05-31", EndDate: "2022-05-01"},
+			{UserID: 1, Report: util.ReportTransactions, Format: util.ReportFormatCSV, StartDate: "2021-05-01", EndDate: "2022-05-31"},
+		}
+
+		for _, testCase := range testCases {
+			mockService := NewService(new(MockRepository), nil, nil, newMockNotificationService())
+
+			file, err := mockService.GetReport(testCase)
+			assert.Nil(t, file)
+			assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		}
+	})
+
+	t.Run("failed get transactions", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetTransactionsByDateRange", 1, "2022-05-01", "2022-05-31").Return(&[]Transaction{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		file, err := mockService.GetReport(params)
+		assert.Nil(t, file)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed get place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetPlaceIDByUserID", 1).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		disbursementParams := params
+		disbursementParams.Report = util.ReportDisbursements
+
+		file, err := mockService.GetReport(disbursementParams)
+		assert.Nil(t, file)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetMonthlyStatement(t *testing.T) {
+	info := InfoForDisbursement{ID: 1, Name: "test", PlaceID: 2}
+	transactions := []Transaction{{ID: 1, Name: "customer", Price: 10000, Date: "2022-05-01"}, {ID: 2, Name: "customer", Price: 20000, Date: "2022-05-02"}}
+	disbursements := []DisbursementDetail{
+		{ID: 1, Date: time.Date(2022, 5, 4, 10, 0, 0, 0, time.UTC), Amount: 25000, Status: util.XenditDisbursementCompleted},
+		{ID: 2, Date: time.Date(2022, 5, 5, 10, 0, 0, 0, time.UTC), Amount: 5000, Status: util.XenditDisbursementFailed},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetBusinessAdminInformation", 1).Return(info, nil)
+		mockRepo.On("GetTransactionsByDateRange", 1, "2022-05-01", "2022-05-31").Return(&transactions, nil)
+		mockRepo.On("GetPlatformFeeByDateRange", 1, "2022-05-01", "2022-05-31").Return(2000.0, nil)
+		mockRepo.On("GetDisbursementsByDateRange", 2, "2022-05-01", "2022-05-31").Return(&disbursements, nil)
+
+		file, err := mockService.GetMonthlyStatement(1, "2022-05")
+		mockRepo.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "statement_2022-05.pdf", file.FileName)
+		assert.Equal(t, util.ApplicationPDF, file.ContentType)
+		assert.Equal(t, "%PDF", string(file.Content[:4]))
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), nil, nil, newMockNotificationService())
+
+		file, err := mockService.GetMonthlyStatement(0, "05-2022")
+		assert.Nil(t, file)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get business admin information", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetBusinessAdminInformation", 1).Return(InfoForDisbursement{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		file, err := mockService.GetMonthlyStatement(1, "2022-05")
+		assert.Nil(t, file)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed get platform fee", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil, nil, newMockNotificationService())
+
+		mockRepo.On("GetBusinessAdminInformation", 1).Return(info, nil)
+		mockRepo.On("GetTransactionsByDateRange", 1, "2022-05-01", "2022-05-31").Return(&transactions, nil)
+		mockRepo.On("GetPlatformFeeByDateRange", 1, "2022-05-01", "2022-05-31").Return(0.0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		file, err := mockService.GetMonthlyStatement(1, "2022-05")
+		assert.Nil(t, file)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -2,6 +2,8 @@ package businessadminauth
 
 import (
 	"time"
+
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // User is a media to retrieve the user_id
@@ -20,6 +22,7 @@ type User struct {
 type RegisterBusinessAdminRequest struct {
 	AdminPhoneNumber        string  `json:"admin_phone_number"`
 	AdminEmail              string  `json:"admin_email"`
+	AdminBankCode           string  `json:"admin_bank_code"`
 	AdminBankAccount        string  `json:"admin_bank_account"`
 	AdminName               string  `json:"admin_name"`
 	AdminBankAccountName    string  `json:"admin_bank_account_name"`
@@ -39,6 +42,14 @@ type RegisterBusinessAdminRequest struct {
 	PlaceLong               float64 `json:"place_long"`
 }
 
+// GetBankCode return the requested bank code, registration without bank code is defaulted to BCA
+func (r RegisterBusinessAdminRequest) GetBankCode() string {
+	if r.AdminBankCode == "" {
+		return util.BankBCA
+	}
+	return r.AdminBankCode
+}
+
 // LoginCredential is a media mainly to put the new-generated password
 type LoginCredential struct {
 	PlaceName string `json:"place_name"`
@@ -54,7 +65,9 @@ type BusinessAdmin struct {
 	Email             string
 	Password          string
 	Status            int
+	LocalID           string
 	Balance           float64
+	BankCode          string
 	BankAccountNumber string
 	BankAccountName   string
 }
@@ -63,6 +76,7 @@ type BusinessAdmin struct {
 type BusinessAdminModel struct {
 	ID                int       `db:"id"`
 	Balance           float64   `db:"balance"`
+	BankCode          string    `db:"bank_code"`
 	BankAccountNumber string    `db:"bank_account_number"`
 	UserID            int       `db:"user_id"`
 	CreatedAt         time.Time `db:"created_at"`
@@ -82,3 +96,28 @@ type LoginResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
+
+// ChangePasswordRequest is a media to bind JSON request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ForgotPasswordRequest is a media to bind JSON request
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest is a media to bind JSON request
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// PasswordReset represent a reset token which has not been used nor expired
+type PasswordReset struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	LocalID   string    `db:"local_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
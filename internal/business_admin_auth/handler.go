@@ -3,6 +3,7 @@ package businessadminauth
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 	"net/http"
 )
@@ -74,3 +75,76 @@ func (h *Handler) Login(c echo.Context) error {
 		},
 	})
 }
+
+// ChangePassword replace the password of signed in business admin, the admin must login again afterwards
+func (h *Handler) ChangePassword(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+	if user == nil {
+		return util.ErrorWrapWithContext(c, http.StatusUnauthorized, ErrUnauthorized, "user is not registered")
+	}
+
+	var request ChangePasswordRequest
+	if err := c.Bind(&request); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, err.Error())
+	}
+
+	if err := h.service.ChangePassword(user.Email, middleware.ParseToken(c), request); err != nil {
+		return passwordErrorWithContext(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "Kata sandi berhasil diubah, silakan login kembali",
+	})
+}
+
+// ForgotPassword send the reset token to the email, the response is the same whether the email is registered or not
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	var request ForgotPasswordRequest
+	if err := c.Bind(&request); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, err.Error())
+	}
+
+	if err := h.service.ForgotPassword(request); err != nil {
+		return passwordErrorWithContext(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "Jika email terdaftar, kode untuk mengatur ulang kata sandi telah dikirim",
+	})
+}
+
+// ResetPassword replace the password by the reset token sent through ForgotPassword
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var request ResetPasswordRequest
+	if err := c.Bind(&request); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, err.Error())
+	}
+
+	if err := h.service.ResetPassword(request); err != nil {
+		return passwordErrorWithContext(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "Kata sandi berhasil diatur ulang, silakan login kembali",
+	})
+}
+
+func passwordErrorWithContext(c echo.Context, err error) error {
+	if errors.Is(err, ErrInputValidationError) {
+		return util.ErrorWrapWithContext(c, http.StatusUnprocessableEntity, err)
+	} else if errors.Is(err, ErrUnauthorized) {
+		return util.ErrorWrapWithContext(c, http.StatusUnauthorized, err)
+	} else if errors.Is(err, ErrNotFound) {
+		return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+	}
+
+	return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 	"net/http"
 	"net/http/httptest"
@@ -30,6 +32,32 @@ func (m *MockService) RegisterBusinessAdmin(request RegisterBusinessAdminRequest
 	return loginCredential, args.Error(1)
 }
 
+func (m *MockService) ChangePassword(email, idToken string, request ChangePasswordRequest) error {
+	args := m.Called(email, idToken, request)
+	return args.Error(0)
+}
+
+func (m *MockService) ForgotPassword(request ForgotPasswordRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockService) ResetPassword(request ResetPasswordRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+// setUser set the signed in user who make the request
+func setUser(c echo.Context, providerID string, userModel *user.Model) {
+	c.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: providerID}}},
+		},
+	})
+	c.Set("userFromDatabase", userModel)
+	c.Set("token", "id-token")
+}
+
 func TestHandler_RegisterBusinessAdminSuccess(t *testing.T) {
 	t.Setenv("BASE_URL", "localhost:8080")
 
@@ -252,3 +280,165 @@ func TestHandler_Login(t *testing.T) {
 		assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
 	})
 }
+
+func TestHandler_ChangePassword(t *testing.T) {
+	e := echo.New()
+	request := ChangePasswordRequest{CurrentPassword: "mockpass", NewPassword: "NewPassword1"}
+	payload, _ := json.Marshal(request)
+
+	newContext := func(body []byte) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/auth/business-admin/password", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ChangePassword", "test@gmail.com", "id-token", request).Return(nil)
+
+		c, rec := newContext(payload)
+		setUser(c, "password", &user.Model{ID: 1, Email: "test@gmail.com"})
+
+		assert.NoError(t, mockHandler.ChangePassword(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("not business admin", func(t *testing.T) {
+		mockHandler := NewHandler(new(MockService))
+
+		c, rec := newContext(payload)
+		setUser(c, "phone", &user.Model{ID: 1})
+
+		util.ErrorHandler(mockHandler.ChangePassword(c), c)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("user is not registered", func(t *testing.T) {
+		mockHandler := NewHandler(new(MockService))
+
+		c, rec := newContext(payload)
+		setUser(c, "password", nil)
+
+		util.ErrorHandler(mockHandler.ChangePassword(c), c)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("failed to decode request", func(t *testing.T) {
+		mockHandler := NewHandler(new(MockService))
+
+		body, _ := json.Marshal(map[string]interface{}{"new_password": 123})
+		c, rec := newContext(body)
+		setUser(c, "password", &user.Model{ID: 1, Email: "test@gmail.com"})
+
+		util.ErrorHandler(mockHandler.ChangePassword(c), c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ChangePassword", "test@gmail.com", "id-token", request).
+			Return(errors.Wrap(ErrUnauthorized, "wrong current password"))
+
+		c, rec := newContext(payload)
+		setUser(c, "password", &user.Model{ID: 1, Email: "test@gmail.com"})
+
+		util.ErrorHandler(mockHandler.ChangePassword(c), c)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestHandler_ForgotPassword(t *testing.T) {
+	e := echo.New()
+	request := ForgotPasswordRequest{Email: "test@gmail.com"}
+	payload, _ := json.Marshal(request)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ForgotPassword", request).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/business-admin/forgot-password", bytes.NewBuffer(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+
+		assert.NoError(t, mockHandler.ForgotPassword(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("input validation error from service", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ForgotPassword", request).Return(errors.Wrap(ErrInputValidationError, "invalid email address"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/business-admin/forgot-password", bytes.NewBuffer(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		util.ErrorHandler(mockHandler.ForgotPassword(c), c)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("internal server error from service", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ForgotPassword", request).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/business-admin/forgot-password", bytes.NewBuffer(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		util.ErrorHandler(mockHandler.ForgotPassword(c), c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_ResetPassword(t *testing.T) {
+	e := echo.New()
+	request := ResetPasswordRequest{Token: "token", NewPassword: "NewPassword1"}
+	payload, _ := json.Marshal(request)
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ResetPassword", request).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/business-admin/reset-password", bytes.NewBuffer(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+
+		assert.NoError(t, mockHandler.ResetPassword(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid or expired token", func(t *testing.T) {
+		mockService := new(MockService)
+		mockHandler := NewHandler(mockService)
+		mockService.On("ResetPassword", request).Return(errors.Wrap(ErrNotFound, "reset token is invalid or expired"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/business-admin/reset-password", bytes.NewBuffer(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		util.ErrorHandler(mockHandler.ResetPassword(c), c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("failed to decode request", func(t *testing.T) {
+		mockHandler := NewHandler(new(MockService))
+
+		body, _ := json.Marshal(map[string]interface{}{"token": 123})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/business-admin/reset-password", bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		util.ErrorHandler(mockHandler.ResetPassword(c), c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -8,8 +8,10 @@ import (
 	"net/mail"
 	"strconv"
 	"strings"
+	"time"
 
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -31,8 +33,8 @@ type repo struct {
 // Repo is an interface to define methods in it
 type Repo interface {
 	GetBusinessAdminByEmail(email string) (*BusinessAdmin, error)
-	CreateUser(phoneNumber, name, email, password string, status int) error                     //status = 1
-	CreateBusinessAdmin(userID int, bankAccount, bankAccountName string, balance float32) error //balance = 0.0
+	CreateUser(phoneNumber, name, email, password string, status int) error                               //status = 1
+	CreateBusinessAdmin(userID int, bankCode, bankAccount, bankAccountName string, balance float32) error //balance = 0.0
 	CreatePlace(name, address string, capacity int, description string,
 		userID, interval int, openHour, closeHour, image string,
 		minHourBooking, maxHourBooking, minSlotBooking, maxSlotBooking int,
@@ -51,6 +53,12 @@ type Repo interface {
 	GeneratePassword() string
 	VerifyHour(hour, hourName string) (bool, error)
 	CompareOpenAndCloseHour(openHour, closeHour string) bool
+
+	UpdatePassword(userID int, hashedPassword string, updateAuthProvider func() error) error
+	CreatePasswordReset(userID int, tokenHash string, expiresAt time.Time) error
+	ConsumePasswordReset(tokenHash string) (*PasswordReset, error)
+	RestorePasswordReset(ID int) error
+	ResetPassword(userID int, hashedPassword string, updateAuthProvider func() error) error
 }
 
 // CreateUser is a method in which we insert a new row of users
@@ -71,10 +79,10 @@ func (r repo) CreateUser(phoneNumber, name, email, password string, status int)
 }
 
 // CreateBusinessAdmin is a method in which we insert a new row of business_owners
-func (r repo) CreateBusinessAdmin(userID int, bankAccount, bankAccountName string, balance float32) error {
+func (r repo) CreateBusinessAdmin(userID int, bankCode, bankAccount, bankAccountName string, balance float32) error {
 
-	_, err := r.db.Exec("INSERT INTO business_owners (balance, bank_account_number, bank_account_name, user_id) VALUES ($1, $2, $3, $4)",
-		balance, bankAccount, bankAccountName, userID)
+	_, err := r.db.Exec("INSERT INTO business_owners (balance, bank_code, bank_account_number, bank_account_name, user_id) VALUES ($1, $2, $3, $4, $5)",
+		balance, bankCode, bankAccount, bankAccountName, userID)
 
 	if err != nil {
 		return errors.Wrap(ErrInternalServerError, err.Error())
@@ -308,21 +316,14 @@ func (r repo) CheckBusinessAdminFields(request RegisterBusinessAdminRequest) err
 		}
 	}
 
-	// Check if admin bank account format is valid
-	if len(request.AdminBankAccount) < 10 {
-		return errors.Wrap(ErrInputValidationError, "bank account is at least 10 characters")
-	}
-	if len(request.AdminBankAccount) > 25 {
-		return errors.Wrap(ErrInputValidationError, "bank account is at most 25 characters")
+	// Check if admin bank code is supported
+	if !util.IsSupportedBankCode(request.GetBankCode()) {
+		return errors.Wrap(ErrInputValidationError, "bank code is not supported")
 	}
-	for index, char := range request.AdminBankAccount {
-		if index == 3 {
-			if char != '-' {
-				return errors.Wrap(ErrInputValidationError, "the valid bank account number format is XXX-YYY...YYY where XXX is the bank code")
-			}
-		} else if char < '0' || char > '9' {
-			return errors.Wrap(ErrInputValidationError, "bank account number is invalid")
-		}
+
+	// Check if admin bank account format is valid
+	if reason := util.ValidateBankAccountNumber(request.GetBankCode(), request.AdminBankAccount); reason != "" {
+		return errors.Wrap(ErrInputValidationError, reason)
 	}
 
 	// Check if admin bank account is unique
@@ -514,8 +515,118 @@ func (r repo) GetBusinessAdminByEmail(email string) (*BusinessAdmin, error) {
 		Email:             userModel.Email,
 		Password:          userModel.Password,
 		Status:            userModel.Status,
+		LocalID:           userModel.FirebaseLocalID,
 		Balance:           businessAdminModel.Balance,
+		BankCode:          businessAdminModel.BankCode,
 		BankAccountNumber: businessAdminModel.BankAccountNumber,
 		BankAccountName:   businessAdminModel.BankAccountName,
 	}, nil
 }
+
+// UpdatePassword replace the password of the user, sessions signed in before now are no longer valid.
+// updateAuthProvider is called before the transaction is committed, the password is kept when it returns an error
+func (r repo) UpdatePassword(userID int, hashedPassword string, updateAuthProvider func() error) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET password = $2, sessions_valid_after = date_trunc('second', now() AT TIME ZONE 'UTC'), updated_at = now()
+			WHERE id = $1`
+
+	result, err := tx.Exec(query, userID, hashedPassword)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrNotFound, "user not found")
+	}
+
+	if err := updateAuthProvider(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// CreatePasswordReset save hash of the reset token, the token itself is only sent to the user
+func (r repo) CreatePasswordReset(userID int, tokenHash string, expiresAt time.Time) error {
+	query := "INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, $3)"
+
+	if _, err := r.db.Exec(query, userID, tokenHash, expiresAt); err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// ConsumePasswordReset mark the reset token as used and returns it, only when it has not been used nor expired
+func (r repo) ConsumePasswordReset(tokenHash string) (*PasswordReset, error) {
+	var passwordReset PasswordReset
+	query := `UPDATE password_resets pr SET used_at = NOW()
+			FROM users u
+			WHERE u.id = pr.user_id AND pr.token_hash = $1 AND pr.used_at IS NULL AND pr.expires_at > NOW()
+			RETURNING pr.id, pr.user_id, COALESCE(u.firebase_local_id, '') AS local_id, pr.expires_at`
+
+	if err := r.db.Get(&passwordReset, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(ErrNotFound, "reset token is invalid or expired")
+		}
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &passwordReset, nil
+}
+
+// RestorePasswordReset make a consumed reset token usable again when the password could not be replaced
+func (r repo) RestorePasswordReset(ID int) error {
+	query := "UPDATE password_resets SET used_at = NULL WHERE id = $1"
+
+	if _, err := r.db.Exec(query, ID); err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// ResetPassword replace the password of the user in a transaction, other reset tokens of the user are discarded as well.
+// updateAuthProvider is called before the transaction is committed, the password is kept when it returns an error
+func (r repo) ResetPassword(userID int, hashedPassword string, updateAuthProvider func() error) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL"
+	if _, err := tx.Exec(query, userID); err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	query = `UPDATE users SET password = $2, sessions_valid_after = date_trunc('second', now() AT TIME ZONE 'UTC'), updated_at = now()
+			WHERE id = $1`
+	if _, err := tx.Exec(query, userID, hashedPassword); err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if err := updateAuthProvider(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func TestRepo_CheckRequiredFields(t *testing.T) {
@@ -320,6 +322,20 @@ func TestRepo_CheckBusinessAdminFields(t *testing.T) {
 		request.AdminBankAccount = "008-112492374950"
 	})
 
+	t.Run("unsupported bank code", func(t *testing.T) {
+		request.AdminBankCode = "UNKNOWN"
+		err = repoMock.CheckBusinessAdminFields(*request)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		request.AdminBankCode = ""
+	})
+
+	t.Run("invalid e-wallet account number", func(t *testing.T) {
+		request.AdminBankCode = "OVO"
+		err = repoMock.CheckBusinessAdminFields(*request)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		request.AdminBankCode = ""
+	})
+
 	t.Run("bank account is taken", func(t *testing.T) {
 		request.AdminBankAccount = "008-123456789"
 		rows := mock.
@@ -1040,20 +1056,20 @@ func TestRepo_CreateBusinessAdmin(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		_ = mock.
 			NewRows([]string{"balance", "bank_account_number", "bank_account_name", "user_id"})
-		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO business_owners (balance, bank_account_number, bank_account_name, user_id) VALUES ($1, $2, $3, $4)")).
-			WithArgs(balance, request.AdminBankAccount, request.AdminBankAccountName, userID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO business_owners (balance, bank_code, bank_account_number, bank_account_name, user_id) VALUES ($1, $2, $3, $4, $5)")).
+			WithArgs(balance, util.BankBCA, request.AdminBankAccount, request.AdminBankAccountName, userID).WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err = repoMock.CreateBusinessAdmin(userID, request.AdminBankAccount, request.AdminBankAccountName, float32(balance))
+		err = repoMock.CreateBusinessAdmin(userID, util.BankBCA, request.AdminBankAccount, request.AdminBankAccountName, float32(balance))
 		assert.NoError(t, err)
 	})
 
 	t.Run("wrong fields", func(t *testing.T) {
 		_ = mock.
 			NewRows([]string{"balance", "bank_account_number", "bank_account_name", "user_id"})
-		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO business_owners (balance, bank_account_number, bank_account_name, user_id) VALUES ($1, $2, $3, $4)")).
-			WithArgs(balance, request.AdminBankAccount, request.AdminBankAccountName, userID).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO business_owners (balance, bank_code, bank_account_number, bank_account_name, user_id) VALUES ($1, $2, $3, $4, $5)")).
+			WithArgs(balance, util.BankBCA, request.AdminBankAccount, request.AdminBankAccountName, userID).WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err = repoMock.CreateBusinessAdmin(userID, request.AdminBankAccountName, request.AdminBankAccount, float32(balance))
+		err = repoMock.CreateBusinessAdmin(userID, util.BankBCA, request.AdminBankAccountName, request.AdminBankAccount, float32(balance))
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 }
@@ -1167,6 +1183,7 @@ func Test_repo_GetBusinessAdminByEmail(t *testing.T) {
 		Password:          "testpassword",
 		Status:            1,
 		Balance:           1000,
+		BankCode:          "BCA",
 		BankAccountNumber: "12321asdfasdf",
 		BankAccountName:   "BCA",
 	}
@@ -1188,8 +1205,8 @@ func Test_repo_GetBusinessAdminByEmail(t *testing.T) {
 			WillReturnRows(rows)
 
 		rows = mock.
-			NewRows([]string{"id", "balance", "bank_account_number", "user_id", "bank_account_name"}).
-			AddRow(1, expected.Balance, expected.BankAccountNumber, expected.ID, expected.BankAccountName)
+			NewRows([]string{"id", "balance", "bank_code", "bank_account_number", "user_id", "bank_account_name"}).
+			AddRow(1, expected.Balance, expected.BankCode, expected.BankAccountNumber, expected.ID, expected.BankAccountName)
 		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM business_owners WHERE user_id = $1")).
 			WithArgs(expected.ID).
 			WillReturnRows(rows)
@@ -1253,3 +1270,189 @@ func Test_repo_GetBusinessAdminByEmail(t *testing.T) {
 		assert.Nil(t, actual)
 	})
 }
+
+func TestRepo_UpdatePassword(t *testing.T) {
+	query := "UPDATE users SET password = $2, sessions_valid_after = date_trunc('second', now() AT TIME ZONE 'UTC'), updated_at = now()"
+	updateAuthProvider := func() error { return nil }
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	repoMock := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdatePassword(1, "hash", updateAuthProvider))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejected by auth provider", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := repoMock.UpdatePassword(1, "hash", func() error { return errors.Wrap(ErrInputValidationError, "password is too weak") })
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, "hash").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.Equal(t, ErrNotFound, errors.Cause(repoMock.UpdatePassword(1, "hash", updateAuthProvider)))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, "hash").WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(repoMock.UpdatePassword(1, "hash", updateAuthProvider)))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepo_CreatePasswordReset(t *testing.T) {
+	query := "INSERT INTO password_resets (user_id, token_hash, expires_at) VALUES ($1, $2, $3)"
+	expiresAt := time.Now()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	repoMock := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, "token-hash", expiresAt).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.Nil(t, repoMock.CreatePasswordReset(1, "token-hash", expiresAt))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, "token-hash", expiresAt).WillReturnError(sql.ErrConnDone)
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(repoMock.CreatePasswordReset(1, "token-hash", expiresAt)))
+	})
+}
+
+func TestRepo_ConsumePasswordReset(t *testing.T) {
+	query := `UPDATE password_resets pr SET used_at = NOW()
+			FROM users u
+			WHERE u.id = pr.user_id AND pr.token_hash = $1 AND pr.used_at IS NULL AND pr.expires_at > NOW()
+			RETURNING pr.id, pr.user_id, COALESCE(u.firebase_local_id, '') AS local_id, pr.expires_at`
+	expiresAt := time.Now()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	repoMock := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("token-hash").
+			WillReturnRows(mock.NewRows([]string{"id", "user_id", "local_id", "expires_at"}).AddRow(1, 2, "local-id", expiresAt))
+
+		result, err := repoMock.ConsumePasswordReset("token-hash")
+		assert.Nil(t, err)
+		assert.Equal(t, &PasswordReset{ID: 1, UserID: 2, LocalID: "local-id", ExpiresAt: expiresAt}, result)
+	})
+
+	t.Run("invalid, used or expired token", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("token-hash").WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.ConsumePasswordReset("token-hash")
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("token-hash").WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.ConsumePasswordReset("token-hash")
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, result)
+	})
+}
+
+func TestRepo_RestorePasswordReset(t *testing.T) {
+	query := "UPDATE password_resets SET used_at = NULL WHERE id = $1"
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	repoMock := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.Nil(t, repoMock.RestorePasswordReset(1))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(repoMock.RestorePasswordReset(1)))
+	})
+}
+
+func TestRepo_ResetPassword(t *testing.T) {
+	discardQuery := "UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL"
+	updateQuery := "UPDATE users SET password = $2, sessions_valid_after = date_trunc('second', now() AT TIME ZONE 'UTC'), updated_at = now()"
+	updateAuthProvider := func() error { return nil }
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	repoMock := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(discardQuery)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(1, "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.ResetPassword(1, "hash", updateAuthProvider))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejected by auth provider", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(discardQuery)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(1, "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := repoMock.ResetPassword(1, "hash", func() error { return errors.Wrap(ErrInputValidationError, "password is too weak") })
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to update password", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(discardQuery)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(1, "hash").WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(repoMock.ResetPassword(1, "hash", updateAuthProvider)))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed to begin transaction", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(repoMock.ResetPassword(1, "hash", updateAuthProvider)))
+	})
+}
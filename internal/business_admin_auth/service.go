@@ -1,26 +1,32 @@
 package businessadminauth
 
 import (
-	"bytes"
-	"encoding/json"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"github.com/labstack/echo/v4"
-	"net/http"
 	"net/mail"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	authprovider "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth_provider"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/pkg/errors"
 )
 
-// NewService is a constructor to get a Service instance
-func NewService(repo Repo, firebaseAPIKey, identityToolkitURL string) Service {
+// NewService is a constructor to get a Service instance. Password reset is delegated to firebaseAuth when it is not nil,
+// otherwise the reset token is managed by the service and it can not be sent when mailer is nil
+func NewService(repo Repo, authProvider authprovider.Provider, mailer notification.Channel, firebaseAuth firebaseauth.Repo) Service {
 	return &service{
-		repo:               repo,
-		firebaseAPIKey:     firebaseAPIKey,
-		identityToolkitURL: identityToolkitURL,
+		repo:         repo,
+		authProvider: authProvider,
+		mailer:       mailer,
+		firebaseAuth: firebaseAuth,
 	}
 }
 
@@ -28,13 +34,17 @@ func NewService(repo Repo, firebaseAPIKey, identityToolkitURL string) Service {
 type Service interface {
 	RegisterBusinessAdmin(request RegisterBusinessAdminRequest) (*LoginCredential, error)
 	Login(email, password, recaptchaToken string) (string, string, error)
+	ChangePassword(email, idToken string, request ChangePasswordRequest) error
+	ForgotPassword(request ForgotPasswordRequest) error
+	ResetPassword(request ResetPasswordRequest) error
 }
 
 // service is a struct of service
 type service struct {
-	repo               Repo
-	firebaseAPIKey     string
-	identityToolkitURL string
+	repo         Repo
+	authProvider authprovider.Provider
+	mailer       notification.Channel
+	firebaseAuth firebaseauth.Repo
 }
 
 // RegisterBusinessAdmin is called to make users, business_owners and places, with a return of LoginCredential
@@ -86,7 +96,7 @@ func (s service) RegisterBusinessAdmin(request RegisterBusinessAdminRequest) (*L
 
 	// Creating new BusinessAdmin
 	var balance float32 = 0.0
-	err = s.repo.CreateBusinessAdmin(userID, request.AdminBankAccount, request.AdminBankAccountName, balance)
+	err = s.repo.CreateBusinessAdmin(userID, request.GetBankCode(), request.AdminBankAccount, request.AdminBankAccountName, balance)
 	if err != nil {
 		return nil, err
 	}
@@ -128,28 +138,231 @@ func (s service) Login(email, password, recaptchaToken string) (string, string,
 		return "", "", errors.Wrap(ErrUnauthorized, "wrong email/password")
 	}
 
-	apiURL := fmt.Sprintf("%s/v1/accounts:signInWithPassword?key=%s", s.identityToolkitURL, s.firebaseAPIKey)
-	data := map[string]interface{}{
-		"email":             email,
-		"password":          password,
-		"captchaResponse":   recaptchaToken,
-		"returnSecureToken": true,
+	result, err := s.authProvider.SignInWithPassword(firebaseauth.SignInWithPasswordParams{
+		Email:          email,
+		Password:       password,
+		RecaptchaToken: recaptchaToken,
+	})
+	if err != nil {
+		logrus.Error("[error while signing in to auth provider] ", err.Error())
+		if errors.Cause(err) == authprovider.ErrInputValidation {
+			return "", "", errors.Wrap(ErrUnauthorized, "wrong email/password")
+		}
+
+		return "", "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return result.IDToken, result.RefreshToken, nil
+}
+
+// ChangePassword replace the password of the signed in business admin after the current password is confirmed,
+// every session including the current one must login again afterwards
+func (s service) ChangePassword(email, idToken string, request ChangePasswordRequest) error {
+	var errorList []string
+
+	if request.CurrentPassword == "" {
+		errorList = append(errorList, "current password cannot be empty")
+	}
+
+	if reason := util.ValidatePassword(request.NewPassword); reason != "" {
+		errorList = append(errorList, reason)
+	} else if request.NewPassword == request.CurrentPassword {
+		errorList = append(errorList, "new password must be different from current password")
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
 	}
-	jsonData, _ := json.Marshal(data)
 
-	resp, err := http.Post(apiURL, echo.MIMEApplicationJSON, bytes.NewBuffer(jsonData))
+	businessAdmin, err := s.repo.GetBusinessAdminByEmail(email)
 	if err != nil {
-		logrus.Error("[error while creating request] ", err.Error())
-		return "", "", errors.Wrap(ErrInternalServerError, err.Error())
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(businessAdmin.Password), []byte(request.CurrentPassword)); err != nil {
+		return errors.Wrap(ErrUnauthorized, "wrong current password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), 10)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return s.repo.UpdatePassword(businessAdmin.ID, string(hashedPassword), func() error {
+		return s.updateAuthProviderPassword(firebaseauth.UpdatePasswordParams{
+			IDToken:  idToken,
+			LocalID:  businessAdmin.LocalID,
+			Password: request.NewPassword,
+		})
+	})
+}
+
+// ForgotPassword send a single use reset token to the email of business admin,
+// unregistered email is not reported so it can not be used to find out registered email
+func (s service) ForgotPassword(request ForgotPasswordRequest) error {
+	if _, err := mail.ParseAddress(request.Email); err != nil {
+		return errors.Wrap(ErrInputValidationError, "invalid email address")
+	}
+
+	if s.firebaseAuth == nil && s.mailer == nil {
+		return errors.Wrap(ErrInternalServerError, "email channel is not configured")
+	}
+
+	businessAdmin, err := s.repo.GetBusinessAdminByEmail(request.Email)
+	if err != nil {
+		if errors.Cause(err) == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	// firebase send the reset code as part of the link to the action URL configured in the firebase console
+	if s.firebaseAuth != nil {
+		if err := s.firebaseAuth.SendPasswordResetEmail(businessAdmin.Email); err != nil {
+			logrus.Error("[error while sending password reset email through firebase] ", err.Error())
+			if errors.Cause(err) == firebaseauth.ErrInputValidation {
+				return nil
+			}
+
+			return errors.Wrap(ErrInternalServerError, err.Error())
+		}
+
+		return nil
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(util.PasswordResetExpiryMinutes * time.Minute)
+	if err := s.repo.CreatePasswordReset(businessAdmin.ID, hashToken(token), expiresAt); err != nil {
+		return err
 	}
 
-	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-		logrus.Error("[non ok status response] ", resp.StatusCode)
-		return "", "", errors.Wrap(ErrInternalServerError, "non-ok status code")
+	err = s.mailer.Send(notification.Message{
+		Recipient: businessAdmin.Email,
+		Subject:   "Atur ulang kata sandi",
+		Body: fmt.Sprintf("Gunakan kode berikut untuk mengatur ulang kata sandi akun Anda sebelum %s: %s. Abaikan email ini jika Anda tidak memintanya.",
+			expiresAt.Format("2006-01-02 15:04"), token),
+	})
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	var jsonResponse map[string]interface{}
-	json.NewDecoder(resp.Body).Decode(&jsonResponse)
+	return nil
+}
+
+// ResetPassword replace the password of the owner of the reset token, the token can only be used once.
+// The token is consumed before the auth provider is updated and restored when the password can not be replaced
+func (s service) ResetPassword(request ResetPasswordRequest) error {
+	var errorList []string
+
+	if request.Token == "" {
+		errorList = append(errorList, "token is required")
+	}
+
+	if reason := util.ValidatePassword(request.NewPassword); reason != "" {
+		errorList = append(errorList, reason)
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	if s.firebaseAuth != nil {
+		return s.resetFirebasePassword(request)
+	}
+
+	passwordReset, err := s.repo.ConsumePasswordReset(hashToken(request.Token))
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), 10)
+	if err != nil {
+		return s.restorePasswordReset(passwordReset.ID, errors.Wrap(ErrInternalServerError, err.Error()))
+	}
+
+	err = s.repo.ResetPassword(passwordReset.UserID, string(hashedPassword), func() error {
+		return s.updateAuthProviderPassword(firebaseauth.UpdatePasswordParams{
+			LocalID:  passwordReset.LocalID,
+			Password: request.NewPassword,
+		})
+	})
+	if err != nil {
+		return s.restorePasswordReset(passwordReset.ID, err)
+	}
+
+	return nil
+}
+
+// resetFirebasePassword replace the password by the reset code sent by firebase, the code is verified to find the business admin
+// and it is only used inside the transaction of the local password so the password stays the same in both places
+func (s service) resetFirebasePassword(request ResetPasswordRequest) error {
+	result, err := s.firebaseAuth.ResetPassword(firebaseauth.ResetPasswordParams{OOBCode: request.Token})
+	if err != nil {
+		return wrapUpdatePasswordError(err)
+	}
+
+	businessAdmin, err := s.repo.GetBusinessAdminByEmail(result.Email)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), 10)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return s.repo.ResetPassword(businessAdmin.ID, string(hashedPassword), func() error {
+		_, err := s.firebaseAuth.ResetPassword(firebaseauth.ResetPasswordParams{OOBCode: request.Token, NewPassword: request.NewPassword})
+		if err != nil {
+			return wrapUpdatePasswordError(err)
+		}
+
+		return nil
+	})
+}
+
+// restorePasswordReset make the consumed reset token usable again so the user can retry, cause is always returned
+func (s service) restorePasswordReset(passwordResetID int, cause error) error {
+	if err := s.repo.RestorePasswordReset(passwordResetID); err != nil {
+		logrus.Error("[error while restoring password reset token] ", err.Error())
+	}
+
+	return cause
+}
+
+// updateAuthProviderPassword is called inside the transaction of the local password,
+// so the local password is only replaced when the auth provider accept the new one
+func (s service) updateAuthProviderPassword(params firebaseauth.UpdatePasswordParams) error {
+	if err := s.authProvider.UpdatePassword(params); err != nil {
+		return wrapUpdatePasswordError(err)
+	}
+
+	return nil
+}
+
+func wrapUpdatePasswordError(err error) error {
+	logrus.Error("[error while updating password in auth provider] ", err.Error())
+	if errors.Cause(err) == authprovider.ErrInputValidation {
+		return errors.Wrap(ErrInputValidationError, err.Error())
+	}
+
+	return errors.Wrap(ErrInternalServerError, err.Error())
+}
+
+func generateToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return hex.EncodeToString(token), nil
+}
 
-	return jsonResponse["idToken"].(string), jsonResponse["refreshToken"].(string), nil
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
@@ -2,12 +2,19 @@ package businessadminauth
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	authprovider "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/auth_provider"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type MockRepository struct {
@@ -40,8 +47,8 @@ func (m *MockRepository) CheckBusinessAdminFields(request RegisterBusinessAdminR
 	return args.Error(0)
 }
 
-func (m *MockRepository) CreateBusinessAdmin(userID int, bankAccount, bankAccountName string, balance float32) error {
-	args := m.Called(userID, bankAccount, bankAccountName, balance)
+func (m *MockRepository) CreateBusinessAdmin(userID int, bankCode, bankAccount, bankAccountName string, balance float32) error {
+	args := m.Called(userID, bankCode, bankAccount, bankAccountName, balance)
 	return args.Error(0)
 }
 
@@ -103,9 +110,113 @@ func (m *MockRepository) GetBusinessAdminByEmail(email string) (*BusinessAdmin,
 	return args.Get(0).(*BusinessAdmin), args.Error(1)
 }
 
+// UpdatePassword calls updateAuthProvider as the transaction does unless the local update is set to fail
+func (m *MockRepository) UpdatePassword(userID int, hashedPassword string, updateAuthProvider func() error) error {
+	args := m.Called(userID, hashedPassword)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return updateAuthProvider()
+}
+
+func (m *MockRepository) CreatePasswordReset(userID int, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(userID, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ConsumePasswordReset(tokenHash string) (*PasswordReset, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PasswordReset), args.Error(1)
+}
+
+func (m *MockRepository) RestorePasswordReset(ID int) error {
+	args := m.Called(ID)
+	return args.Error(0)
+}
+
+// ResetPassword calls updateAuthProvider as the transaction does unless the local update is set to fail
+func (m *MockRepository) ResetPassword(userID int, hashedPassword string, updateAuthProvider func() error) error {
+	args := m.Called(userID, hashedPassword)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return updateAuthProvider()
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Name() string {
+	return util.NotificationChannelEmail
+}
+
+func (m *MockMailer) Recipient(recipient notification.Recipient) string {
+	return recipient.Email
+}
+
+func (m *MockMailer) Send(message notification.Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+type MockAuthProvider struct {
+	mock.Mock
+}
+
+func (m *MockAuthProvider) SendOTP(params firebaseauth.SendOTPParams) (*firebaseauth.SendOTPResult, error) {
+	args := m.Called(params)
+	return args.Get(0).(*firebaseauth.SendOTPResult), args.Error(1)
+}
+
+func (m *MockAuthProvider) VerifyOTP(params firebaseauth.VerifyOTPParams) (*firebaseauth.VerifyOTPResult, error) {
+	args := m.Called(params)
+	return args.Get(0).(*firebaseauth.VerifyOTPResult), args.Error(1)
+}
+
+func (m *MockAuthProvider) SignInWithPassword(params firebaseauth.SignInWithPasswordParams) (*firebaseauth.SignInWithPasswordResult, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*firebaseauth.SignInWithPasswordResult), args.Error(1)
+}
+
+func (m *MockAuthProvider) RefreshToken(refreshToken string) (*firebaseauth.RefreshTokenResult, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*firebaseauth.RefreshTokenResult), args.Error(1)
+}
+
+func (m *MockAuthProvider) UpdatePassword(params firebaseauth.UpdatePasswordParams) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) SendPasswordResetEmail(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthProvider) ResetPassword(params firebaseauth.ResetPasswordParams) (*firebaseauth.ResetPasswordResult, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*firebaseauth.ResetPasswordResult), args.Error(1)
+}
+
+func (m *MockAuthProvider) GetUserDataFromToken(token string) (*firebaseauth.UserDataFromToken, error) {
+	args := m.Called(token)
+	return args.Get(0).(*firebaseauth.UserDataFromToken), args.Error(1)
+}
+
 func TestService_RegisterBusinessAdmin(t *testing.T) {
-	mockAPIKey := "1234567890"
-	mockIdentityToolkitURL := "https://test"
 	request := RegisterBusinessAdminRequest{
 		AdminPhoneNumber:        "089782828888",
 		AdminEmail:              "sebuahemail@gmail.com",
@@ -129,7 +240,7 @@ func TestService_RegisterBusinessAdmin(t *testing.T) {
 	}
 
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, mockAPIKey, mockIdentityToolkitURL)
+	mockService := NewService(mockRepo, new(MockAuthProvider), nil, nil)
 
 	// var mockEmptyErrorList []string
 	// mockRepo.On("CheckRequiredFields", request, mockEmptyErrorList).Return(mockEmptyErrorList)
@@ -144,7 +255,7 @@ func TestService_RegisterBusinessAdmin(t *testing.T) {
 	// mockRepo.On("RetrieveUserID", request.AdminPhoneNumber).Return(1, nil)
 	// mockUserID := 1
 	// var mockBalance float32 = 0.0
-	// mockRepo.On("CreateBusinessAdmin", mockUserID, request.AdminBankAccount, request.AdminBankAccountName, mockBalance).Return(nil)
+	// mockRepo.On("CreateBusinessAdmin", mockUserID, util.BankBCA, request.AdminBankAccount, request.AdminBankAccountName, mockBalance).Return(nil)
 	// mockRepo.On("CreatePlace", request.PlaceName, request.PlaceAddress, request.PlaceCapacity,
 	// 	request.PlaceDescription, mockUserID, request.PlaceInterval, request.PlaceOpenHour, request.PlaceCloseHour,
 	// 	request.PlaceImage, request.PlaceMinIntervalBooking, request.PlaceMaxIntervalBooking, request.PlaceMinSlotBooking,
@@ -164,7 +275,7 @@ func TestService_RegisterBusinessAdmin(t *testing.T) {
 		mockRepo.On("RetrieveUserID", request.AdminPhoneNumber).Return(1, nil)
 		mockUserID := 1
 		var mockBalance float32 = 0.0
-		mockRepo.On("CreateBusinessAdmin", mockUserID, request.AdminBankAccount, request.AdminBankAccountName, mockBalance).Return(nil)
+		mockRepo.On("CreateBusinessAdmin", mockUserID, util.BankBCA, request.AdminBankAccount, request.AdminBankAccountName, mockBalance).Return(nil)
 		mockRepo.On("CreatePlace", request.PlaceName, request.PlaceAddress, request.PlaceCapacity,
 			request.PlaceDescription, mockUserID, request.PlaceInterval, request.PlaceOpenHour, request.PlaceCloseHour,
 			request.PlaceImage, request.PlaceMinIntervalBooking, request.PlaceMaxIntervalBooking, request.PlaceMinSlotBooking,
@@ -292,13 +403,17 @@ func TestService_RegisterBusinessAdmin(t *testing.T) {
 }
 
 func TestService_Login(t *testing.T) {
-	mockAPIKey := "1234567890"
-	mockIdentityToolkitURL := "https://stoplight.io/mocks/oop-ppl/wave-api/41710619/mock/firebase"
 	mockRepo := new(MockRepository)
-	mockService := NewService(mockRepo, mockAPIKey, mockIdentityToolkitURL)
+	mockAuthProvider := new(MockAuthProvider)
+	mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
 	mockEmail := "mock@email.com"
 	mockPassword := "$2a$10$PTygXSgiOxD1WxRMtIa5leSqVj7R80KY.yx9XL046UPl35ztS/cxu" // plaintext: mockpass
 	mockRecaptchaToken := "asdfasdf"
+	mockSignInParams := firebaseauth.SignInWithPasswordParams{
+		Email:          mockEmail,
+		Password:       "mockpass",
+		RecaptchaToken: mockRecaptchaToken,
+	}
 
 	expectedBusinessAdmin := BusinessAdmin{
 		ID:                1,
@@ -314,6 +429,10 @@ func TestService_Login(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&expectedBusinessAdmin, nil)
+		mockAuthProvider.On("SignInWithPassword", mockSignInParams).Return(&firebaseauth.SignInWithPasswordResult{
+			IDToken:      "string",
+			RefreshToken: "asdfasf",
+		}, nil)
 
 		actualAccessToken, actualRefreshToken, err := mockService.Login(mockEmail, "mockpass", mockRecaptchaToken)
 
@@ -334,7 +453,7 @@ func TestService_Login(t *testing.T) {
 
 	t.Run("failed to get business admin by email", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo, mockAPIKey, mockIdentityToolkitURL)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
 		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(nil, ErrInternalServerError)
 
 		_, _, err := mockService.Login(mockEmail, mockPassword, mockRecaptchaToken)
@@ -348,21 +467,350 @@ func TestService_Login(t *testing.T) {
 		assert.True(t, errors.Is(err, ErrUnauthorized))
 	})
 
-	t.Run("error while creating request", func(t *testing.T) {
+	t.Run("rejected by auth provider", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo, mockAPIKey, "https://wrongurl")
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
 
 		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&expectedBusinessAdmin, nil)
+		mockAuthProvider.On("SignInWithPassword", mockSignInParams).Return(nil, errors.Wrap(authprovider.ErrInputValidation, "wrong email/password"))
 		_, _, err := mockService.Login(mockEmail, "mockpass", mockRecaptchaToken)
-		assert.True(t, errors.Is(err, ErrInternalServerError))
+		assert.True(t, errors.Is(err, ErrUnauthorized))
 	})
 
-	t.Run("non ok response code", func(t *testing.T) {
+	t.Run("auth provider error", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo, mockAPIKey, mockIdentityToolkitURL+"status=failed")
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
 
 		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&expectedBusinessAdmin, nil)
+		mockAuthProvider.On("SignInWithPassword", mockSignInParams).Return(nil, errors.Wrap(authprovider.ErrInternalServer, "test error"))
 		_, _, err := mockService.Login(mockEmail, "mockpass", mockRecaptchaToken)
 		assert.True(t, errors.Is(err, ErrInternalServerError))
 	})
 }
+
+func TestService_ChangePassword(t *testing.T) {
+	mockEmail := "mock@email.com"
+	businessAdmin := &BusinessAdmin{
+		ID:       1,
+		Email:    mockEmail,
+		Password: "$2a$10$PTygXSgiOxD1WxRMtIa5leSqVj7R80KY.yx9XL046UPl35ztS/cxu", // plaintext: mockpass
+		LocalID:  "local-id",
+	}
+	request := ChangePasswordRequest{CurrentPassword: "mockpass", NewPassword: "NewPassword1"}
+	updatePasswordParams := firebaseauth.UpdatePasswordParams{IDToken: "id-token", LocalID: "local-id", Password: "NewPassword1"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(businessAdmin, nil)
+		mockAuthProvider.On("UpdatePassword", updatePasswordParams).Return(nil)
+		mockRepo.On("UpdatePassword", 1, mock.MatchedBy(func(hashedPassword string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte("NewPassword1")) == nil
+		})).Return(nil)
+
+		assert.Nil(t, mockService.ChangePassword(mockEmail, "id-token", request))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("weak new password", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockAuthProvider), nil, nil)
+
+		err := mockService.ChangePassword(mockEmail, "id-token", ChangePasswordRequest{CurrentPassword: "mockpass", NewPassword: "password"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("new password same as current password", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockAuthProvider), nil, nil)
+
+		err := mockService.ChangePassword(mockEmail, "id-token", ChangePasswordRequest{CurrentPassword: "Password1", NewPassword: "Password1"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(businessAdmin, nil)
+
+		err := mockService.ChangePassword(mockEmail, "id-token", ChangePasswordRequest{CurrentPassword: "wrongpass", NewPassword: "NewPassword1"})
+		assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+	})
+
+	t.Run("rejected by auth provider", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(businessAdmin, nil)
+		mockRepo.On("UpdatePassword", 1, mock.Anything).Return(nil)
+		mockAuthProvider.On("UpdatePassword", updatePasswordParams).Return(errors.Wrap(authprovider.ErrInputValidation, "password is too weak"))
+
+		err := mockService.ChangePassword(mockEmail, "id-token", request)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("auth provider error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(businessAdmin, nil)
+		mockRepo.On("UpdatePassword", 1, mock.Anything).Return(nil)
+		mockAuthProvider.On("UpdatePassword", updatePasswordParams).Return(errors.Wrap(authprovider.ErrInternalServer, "test error"))
+
+		err := mockService.ChangePassword(mockEmail, "id-token", request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed to update local password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(businessAdmin, nil)
+		mockRepo.On("UpdatePassword", 1, mock.Anything).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		err := mockService.ChangePassword(mockEmail, "id-token", request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		mockAuthProvider.AssertNotCalled(t, "UpdatePassword", mock.Anything)
+	})
+}
+
+func TestService_ForgotPassword(t *testing.T) {
+	mockEmail := "mock@email.com"
+	request := ForgotPasswordRequest{Email: mockEmail}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockMailer := new(MockMailer)
+		mockService := NewService(mockRepo, new(MockAuthProvider), mockMailer, nil)
+
+		var sentToken string
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&BusinessAdmin{ID: 1, Email: mockEmail}, nil)
+		mockRepo.On("CreatePasswordReset", 1, mock.Anything, mock.Anything).Return(nil)
+		mockMailer.On("Send", mock.MatchedBy(func(message notification.Message) bool {
+			sentToken = strings.SplitN(strings.SplitN(message.Body, ": ", 2)[1], ".", 2)[0]
+			return message.Recipient == mockEmail
+		})).Return(nil)
+
+		before := time.Now()
+		assert.Nil(t, mockService.ForgotPassword(request))
+
+		call := mockRepo.Calls[1]
+		expiresAt := call.Arguments.Get(2).(time.Time)
+		assert.Len(t, sentToken, 64)
+		assert.Equal(t, hashToken(sentToken), call.Arguments.String(1))
+		assert.False(t, expiresAt.Before(before.Add(util.PasswordResetExpiryMinutes*time.Minute)))
+	})
+
+	t.Run("unregistered email", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockMailer := new(MockMailer)
+		mockService := NewService(mockRepo, new(MockAuthProvider), mockMailer, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(nil, errors.Wrap(ErrNotFound, "user not found"))
+
+		assert.Nil(t, mockService.ForgotPassword(request))
+		mockMailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("invalid email address", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockAuthProvider), new(MockMailer), nil)
+
+		err := mockService.ForgotPassword(ForgotPasswordRequest{Email: "randomwrongemailformat"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("sent by firebase", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, mockFirebaseAuth)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&BusinessAdmin{ID: 1, Email: mockEmail}, nil)
+		mockFirebaseAuth.On("SendPasswordResetEmail", mockEmail).Return(nil)
+
+		assert.Nil(t, mockService.ForgotPassword(request))
+		mockFirebaseAuth.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreatePasswordReset", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unregistered email is not sent by firebase", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, mockFirebaseAuth)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(nil, errors.Wrap(ErrNotFound, "user not found"))
+
+		assert.Nil(t, mockService.ForgotPassword(request))
+		mockFirebaseAuth.AssertNotCalled(t, "SendPasswordResetEmail", mock.Anything)
+	})
+
+	t.Run("firebase account not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, mockFirebaseAuth)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&BusinessAdmin{ID: 1, Email: mockEmail}, nil)
+		mockFirebaseAuth.On("SendPasswordResetEmail", mockEmail).Return(errors.Wrap(firebaseauth.ErrInputValidation, "email not found"))
+
+		assert.Nil(t, mockService.ForgotPassword(request))
+	})
+
+	t.Run("failed to send email through firebase", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, mockFirebaseAuth)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&BusinessAdmin{ID: 1, Email: mockEmail}, nil)
+		mockFirebaseAuth.On("SendPasswordResetEmail", mockEmail).Return(errors.Wrap(firebaseauth.ErrInternalServer, "test error"))
+
+		err := mockService.ForgotPassword(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("email channel is not configured", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockAuthProvider), nil, nil)
+
+		err := mockService.ForgotPassword(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed to send email", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockMailer := new(MockMailer)
+		mockService := NewService(mockRepo, new(MockAuthProvider), mockMailer, nil)
+
+		mockRepo.On("GetBusinessAdminByEmail", mockEmail).Return(&BusinessAdmin{ID: 1, Email: mockEmail}, nil)
+		mockRepo.On("CreatePasswordReset", 1, mock.Anything, mock.Anything).Return(nil)
+		mockMailer.On("Send", mock.Anything).Return(errors.New("test error"))
+
+		err := mockService.ForgotPassword(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_ResetPassword(t *testing.T) {
+	request := ResetPasswordRequest{Token: "token", NewPassword: "NewPassword1"}
+	updatePasswordParams := firebaseauth.UpdatePasswordParams{LocalID: "local-id", Password: "NewPassword1"}
+	passwordReset := &PasswordReset{ID: 1, UserID: 2, LocalID: "local-id"}
+	matchHashedPassword := mock.MatchedBy(func(hashedPassword string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte("NewPassword1")) == nil
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("ConsumePasswordReset", hashToken("token")).Return(passwordReset, nil)
+		mockAuthProvider.On("UpdatePassword", updatePasswordParams).Return(nil)
+		mockRepo.On("ResetPassword", 2, matchHashedPassword).Return(nil)
+
+		assert.Nil(t, mockService.ResetPassword(request))
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "RestorePasswordReset", mock.Anything)
+	})
+
+	t.Run("success with firebase reset code", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, mockFirebaseAuth)
+
+		mockFirebaseAuth.On("ResetPassword", firebaseauth.ResetPasswordParams{OOBCode: "token"}).
+			Return(&firebaseauth.ResetPasswordResult{Email: "mock@email.com"}, nil)
+		mockRepo.On("GetBusinessAdminByEmail", "mock@email.com").Return(&BusinessAdmin{ID: 2, Email: "mock@email.com"}, nil)
+		mockRepo.On("ResetPassword", 2, matchHashedPassword).Return(nil)
+		mockFirebaseAuth.On("ResetPassword", firebaseauth.ResetPasswordParams{OOBCode: "token", NewPassword: "NewPassword1"}).
+			Return(&firebaseauth.ResetPasswordResult{Email: "mock@email.com"}, nil)
+
+		assert.Nil(t, mockService.ResetPassword(request))
+		mockRepo.AssertExpectations(t)
+		mockFirebaseAuth.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ConsumePasswordReset", mock.Anything)
+		mockAuthProvider.AssertNotCalled(t, "UpdatePassword", mock.Anything)
+	})
+
+	t.Run("invalid or expired firebase reset code", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, mockFirebaseAuth)
+
+		mockFirebaseAuth.On("ResetPassword", firebaseauth.ResetPasswordParams{OOBCode: "token"}).
+			Return(nil, errors.Wrap(firebaseauth.ErrInputValidation, "reset code is invalid or expired"))
+
+		err := mockService.ResetPassword(request)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("firebase reset code rejected when replacing password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockFirebaseAuth := new(MockAuthProvider)
+		mockService := NewService(mockRepo, new(MockAuthProvider), nil, mockFirebaseAuth)
+
+		mockFirebaseAuth.On("ResetPassword", firebaseauth.ResetPasswordParams{OOBCode: "token"}).
+			Return(&firebaseauth.ResetPasswordResult{Email: "mock@email.com"}, nil)
+		mockRepo.On("GetBusinessAdminByEmail", "mock@email.com").Return(&BusinessAdmin{ID: 2, Email: "mock@email.com"}, nil)
+		mockRepo.On("ResetPassword", 2, matchHashedPassword).Return(nil)
+		mockFirebaseAuth.On("ResetPassword", firebaseauth.ResetPasswordParams{OOBCode: "token", NewPassword: "NewPassword1"}).
+			Return(nil, errors.Wrap(firebaseauth.ErrInternalServer, "test error"))
+
+		err := mockService.ResetPassword(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("weak password and empty token", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockAuthProvider), nil, nil)
+
+		err := mockService.ResetPassword(ResetPasswordRequest{NewPassword: "pass"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("invalid, used or expired token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("ConsumePasswordReset", hashToken("token")).Return(nil, errors.Wrap(ErrNotFound, "reset token is invalid or expired"))
+
+		err := mockService.ResetPassword(request)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		mockAuthProvider.AssertNotCalled(t, "UpdatePassword", mock.Anything)
+	})
+
+	t.Run("rejected by auth provider", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("ConsumePasswordReset", hashToken("token")).Return(passwordReset, nil)
+		mockRepo.On("ResetPassword", 2, matchHashedPassword).Return(nil)
+		mockAuthProvider.On("UpdatePassword", updatePasswordParams).
+			Return(errors.Wrap(authprovider.ErrInputValidation, "password of firebase account can only be updated by the signed in user"))
+		mockRepo.On("RestorePasswordReset", 1).Return(nil)
+
+		err := mockService.ResetPassword(request)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed to replace password", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockAuthProvider := new(MockAuthProvider)
+		mockService := NewService(mockRepo, mockAuthProvider, nil, nil)
+
+		mockRepo.On("ConsumePasswordReset", hashToken("token")).Return(passwordReset, nil)
+		mockRepo.On("ResetPassword", 2, matchHashedPassword).Return(errors.Wrap(ErrInternalServerError, "test error"))
+		mockRepo.On("RestorePasswordReset", 1).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		err := mockService.ResetPassword(request)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		mockRepo.AssertExpectations(t)
+		mockAuthProvider.AssertNotCalled(t, "UpdatePassword", mock.Anything)
+	})
+}
@@ -154,6 +154,11 @@ func (h *Handler) GetListItemAdminWithPagination(c echo.Context) error {
 // DeleteItemAdminByID is a handler for API request for delete item by admin, currently updating is_active column
 func (h *Handler) DeleteItemAdminByID(c echo.Context) error {
 	errorList := []string{}
+
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil || user == nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, middleware.ErrForbidden, "user is not registered business admin")
+	}
 	itemIDString := c.Param("itemID")
 
 	itemID, err := strconv.Atoi(itemIDString)
@@ -165,8 +170,12 @@ func (h *Handler) DeleteItemAdminByID(c echo.Context) error {
 		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
 	}
 
-	err = h.service.DeleteItemAdminByID(itemID)
+	err = h.service.DeleteItemAdminByID(user.ID, itemID)
 	if err != nil {
+		if errors.Cause(err) == ErrNotFound {
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
 		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
 	}
 
@@ -179,6 +188,11 @@ func (h *Handler) DeleteItemAdminByID(c echo.Context) error {
 // UpdateItem is a handler for updating item API request by business admin
 func (h *Handler) UpdateItem(c echo.Context) error {
 	var errorList []string
+
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil || user == nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, middleware.ErrForbidden, "user is not registered business admin")
+	}
 	itemIDString := c.Param("itemID")
 	itemID, err := strconv.Atoi(itemIDString)
 	if err != nil {
@@ -195,7 +209,7 @@ func (h *Handler) UpdateItem(c echo.Context) error {
 		return util.ErrorWrapWithContext(c, http.StatusBadRequest, fmt.Errorf("Request tidak valid"))
 	}
 
-	if err := h.service.UpdateItem(itemID, Item{
+	if err := h.service.UpdateItem(user.ID, itemID, Item{
 		Name:        itemRequest.Name,
 		Image:       itemRequest.Image,
 		Description: itemRequest.Description,
@@ -38,13 +38,13 @@ func (m *MockService) GetItemByID(placeID int, itemID int) (*Item, error) {
 	return item, args.Error(1)
 }
 
-func (m *MockService) UpdateItem(ID int, item Item) error {
-	args := m.Called(ID, item)
+func (m *MockService) UpdateItem(userID int, ID int, item Item) error {
+	args := m.Called(userID, ID, item)
 	return args.Error(0)
 }
 
-func (m *MockService) DeleteItemAdminByID(itemID int) error {
-	args := m.Called(itemID)
+func (m *MockService) DeleteItemAdminByID(userID int, itemID int) error {
+	args := m.Called(userID, itemID)
 	return args.Error(0)
 }
 
@@ -1127,6 +1127,7 @@ func TestHandler_DeleteItemAdminByID(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/business-admin/business-profile/list-items", nil)
 	rec := httptest.NewRecorder()
 	ctx := e.NewContext(req, rec)
+	setBusinessAdmin(ctx)
 	ctx.SetPath("/:itemID")
 	ctx.SetParamNames("itemID")
 	ctx.SetParamValues("1")
@@ -1146,7 +1147,7 @@ func TestHandler_DeleteItemAdminByID(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("DeleteItemAdminByID", itemID).Return(nil)
+	mockService.On("DeleteItemAdminByID", 1, itemID).Return(nil)
 
 	// Tes
 	if assert.NoError(t, h.DeleteItemAdminByID(ctx)) {
@@ -1163,6 +1164,7 @@ func TestHandler_DeleteItemAdminByIDItemIDError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/", nil)
 	rec := httptest.NewRecorder()
 	ctx := e.NewContext(req, rec)
+	setBusinessAdmin(ctx)
 	ctx.SetPath("/api/v1/business-admin/business-profile/list-items/:itemID")
 	ctx.SetParamNames("itemID")
 	ctx.SetParamValues("test")
@@ -1197,6 +1199,7 @@ func TestHandler_DeleteItemAdminByIDInternalServerError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodDelete, "/", nil)
 	rec := httptest.NewRecorder()
 	ctx := e.NewContext(req, rec)
+	setBusinessAdmin(ctx)
 	ctx.SetPath("/api/v1/business-admin/business-profile/list-items/:itemID")
 	ctx.SetParamNames("itemID")
 	ctx.SetParamValues("1")
@@ -1217,7 +1220,7 @@ func TestHandler_DeleteItemAdminByIDInternalServerError(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("DeleteItemAdminByID", itemID).Return(internalServerError)
+	mockService.On("DeleteItemAdminByID", 1, itemID).Return(internalServerError)
 
 	// Tes
 	util.ErrorHandler(h.DeleteItemAdminByID(ctx), ctx)
@@ -1251,6 +1254,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 		rec := httptest.NewRecorder()
 		ctx := e.NewContext(req, rec)
+		setBusinessAdmin(ctx)
 		ctx.SetParamNames("itemID")
 		ctx.SetParamValues("1")
 
@@ -1263,7 +1267,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		}
 		expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
-		mockService.On("UpdateItem", itemID, expectedItem).Return(nil)
+		mockService.On("UpdateItem", 1, itemID, expectedItem).Return(nil)
 
 		if assert.NoError(t, h.UpdateItem(ctx)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -1279,6 +1283,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		rec := httptest.NewRecorder()
 
 		ctx := e.NewContext(req, rec)
+		setBusinessAdmin(ctx)
 		ctx.SetParamNames("itemID")
 		ctx.SetParamValues("asdf")
 
@@ -1312,6 +1317,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		rec := httptest.NewRecorder()
 
 		ctx := e.NewContext(req, rec)
+		setBusinessAdmin(ctx)
 		ctx.SetParamNames("itemID")
 		ctx.SetParamValues("1")
 
@@ -1330,6 +1336,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 		rec := httptest.NewRecorder()
 		ctx := e.NewContext(req, rec)
+		setBusinessAdmin(ctx)
 		ctx.SetParamNames("itemID")
 		ctx.SetParamValues("1")
 
@@ -1337,7 +1344,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		h := NewHandler(mockService)
 		itemID := 1
 
-		mockService.On("UpdateItem", itemID, Item{}).Return(ErrNotFound)
+		mockService.On("UpdateItem", 1, itemID, Item{}).Return(ErrNotFound)
 
 		util.ErrorHandler(h.UpdateItem(ctx), ctx)
 		if assert.Error(t, h.UpdateItem(ctx)) {
@@ -1352,6 +1359,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 		rec := httptest.NewRecorder()
 		ctx := e.NewContext(req, rec)
+		setBusinessAdmin(ctx)
 		ctx.SetParamNames("itemID")
 		ctx.SetParamValues("1")
 
@@ -1359,7 +1367,7 @@ func TestHandler_UpdateItem(t *testing.T) {
 		h := NewHandler(mockService)
 		itemID := 1
 
-		mockService.On("UpdateItem", itemID, Item{}).Return(ErrInternalServerError)
+		mockService.On("UpdateItem", 1, itemID, Item{}).Return(ErrInternalServerError)
 
 		util.ErrorHandler(h.UpdateItem(ctx), ctx)
 		if assert.Error(t, h.UpdateItem(ctx)) {
@@ -1693,3 +1701,16 @@ func TestHandler_CreateItem(t *testing.T) {
 		}
 	})
 }
+
+func setBusinessAdmin(ctx echo.Context) {
+	ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{ProviderID: "password"},
+				},
+			},
+		},
+	})
+	ctx.Set("userFromDatabase", &user.Model{ID: 1, Status: util.StatusBusinessAdmin})
+}
@@ -25,8 +25,9 @@ type Repo interface {
 	GetListItemWithPagination(params ListItemRequest) (*ListItem, error)
 	GetItemByID(placeID int, itemID int) (*Item, error)
 	GetListItemAdminWithPagination(params ListItemRequest) (*ListItem, error)
-	DeleteItemAdminByID(itemID int) error
-	UpdateItem(ID int, item Item) error
+	IsItemOwner(userID int, itemID int) (bool, error)
+	DeleteItemAdminByID(userID int, itemID int) error
+	UpdateItem(userID int, ID int, item Item) error
 	CreateItem(userID int, item Item) error
 }
 
@@ -144,36 +145,73 @@ func (r repo) GetListItemAdminWithPagination(params ListItemRequest) (*ListItem,
 	return &listItem, nil
 }
 
-func (r repo) DeleteItemAdminByID(itemID int) error {
+// IsItemOwner return true if the item is in the place of the business admin
+func (r repo) IsItemOwner(userID int, itemID int) (bool, error) {
+	var isOwner bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM items i INNER JOIN places p ON p.id = i.place_id
+			WHERE i.id = $1 AND p.user_id = $2
+		)
+	`
+
+	err := r.db.Get(&isOwner, query, itemID, userID)
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return isOwner, nil
+}
+
+func (r repo) DeleteItemAdminByID(userID int, itemID int) error {
 	query := `
 		UPDATE items
 		SET is_active = FALSE
-		WHERE items.id = $1;
+		FROM places
+		WHERE items.id = $1 AND items.place_id = places.id AND places.user_id = $2;
 	`
 
-	_, err := r.db.Exec(query, itemID)
+	result, err := r.db.Exec(query, itemID, userID)
 	if err != nil {
 		return errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrNotFound, fmt.Sprintf("item with id %d is not found", itemID))
+	}
+
 	return nil
 }
 
-func (r repo) UpdateItem(ID int, item Item) error {
+func (r repo) UpdateItem(userID int, ID int, item Item) error {
 	query := `
 		UPDATE items
 		SET name=$1, image=$2, description=$3, price=$4, updated_at=now()
-		WHERE id=$5
+		FROM places
+		WHERE items.id=$5 AND items.place_id = places.id AND places.user_id=$6
 	`
 
-	_, err := r.db.Exec(query, item.Name, item.Image, item.Description, item.Price, ID)
+	result, err := r.db.Exec(query, item.Name, item.Image, item.Description, item.Price, ID, userID)
 	if err != nil {
 		logrus.Errorf("error executing query: %v", err)
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("item not found: %w", ErrNotFound)
-		}
 		return fmt.Errorf("failed to execute query: %w", ErrInternalServerError)
 	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", ErrInternalServerError)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("item not found: %w", ErrNotFound)
+	}
+
 	return nil
 }
 
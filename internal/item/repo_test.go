@@ -683,8 +683,68 @@ func TestRepo_GetListItemAdminWithPaginationCountEmpty(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRepo_IsItemOwner(t *testing.T) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM items i INNER JOIN places p ON p.id = i.place_id
+			WHERE i.id = $1 AND p.user_id = $2
+		)
+	`
+
+	t.Run("owner", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 1).WillReturnRows(rows)
+
+		isOwner, err := repo.IsItemOwner(1, 1)
+		assert.Nil(t, err)
+		assert.True(t, isOwner)
+	})
+
+	t.Run("item of another place", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 2).WillReturnRows(rows)
+
+		isOwner, err := repo.IsItemOwner(2, 1)
+		assert.Nil(t, err)
+		assert.False(t, isOwner)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 1).WillReturnError(ErrInternalServerError)
+
+		_, err = repo.IsItemOwner(1, 1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
 func TestRepo_DeleteItemAdminByID(t *testing.T) {
+	query := `
+		UPDATE items
+		SET is_active = FALSE
+		FROM places
+		WHERE items.id = $1 AND items.place_id = places.id AND places.user_id = $2;
+	`
+
 	t.Run("success", func(t *testing.T) {
+		userID := 1
 		itemID := 1
 		// Mock DB
 		mockDB, mock, err := sqlmock.New()
@@ -695,19 +755,14 @@ func TestRepo_DeleteItemAdminByID(t *testing.T) {
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := `
-			UPDATE items
-			SET is_active = FALSE
-			WHERE items.id = $1;
-		`
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(itemID, userID).WillReturnResult(sqlmock.NewResult(0, 1))
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(itemID).WillReturnResult(driver.ResultNoRows)
-
-		err = repo.DeleteItemAdminByID(itemID)
+		err = repo.DeleteItemAdminByID(userID, itemID)
 		assert.Nil(t, err)
 	})
 
-	t.Run("failed", func(t *testing.T) {
+	t.Run("item of another place", func(t *testing.T) {
+		userID := 2
 		itemID := 1
 		// Mock DB
 		mockDB, mock, err := sqlmock.New()
@@ -718,15 +773,27 @@ func TestRepo_DeleteItemAdminByID(t *testing.T) {
 		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 		repo := NewRepo(sqlxDB)
 
-		query := `
-			UPDATE items
-			SET is_active = FALSE
-			WHERE items.id = $1;
-		`
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(itemID, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err = repo.DeleteItemAdminByID(userID, itemID)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		userID := 1
+		itemID := 1
+		// Mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repo := NewRepo(sqlxDB)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(itemID).WillReturnError(ErrInternalServerError)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(itemID, userID).WillReturnError(ErrInternalServerError)
 
-		err = repo.DeleteItemAdminByID(itemID)
+		err = repo.DeleteItemAdminByID(userID, itemID)
 		assert.NotNil(t, err)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
@@ -756,28 +823,30 @@ func TestRepo_UpdateItem(t *testing.T) {
 			sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 			repo := NewRepo(sqlxDB)
 
+			userID := 1
 			itemID := 1
 			item := Item{}
 			query := `
 				UPDATE items
 				SET name=$1, image=$2, description=$3, price=$4, updated_at=now()
-				WHERE id=$5
+				FROM places
+				WHERE items.id=$5 AND items.place_id = places.id AND places.user_id=$6
 			`
 
 			expectedExec := mock.
 				ExpectExec(regexp.QuoteMeta(query)).
-				WithArgs(item.Name, item.Image, item.Description, item.Price, itemID)
+				WithArgs(item.Name, item.Image, item.Description, item.Price, itemID, userID)
 			if test.wantError != nil {
 				if errors.Is(test.wantError, ErrNotFound) {
-					expectedExec.WillReturnError(sql.ErrNoRows)
+					expectedExec.WillReturnResult(sqlmock.NewResult(0, 0))
 				} else {
 					expectedExec.WillReturnError(test.wantError)
 				}
 			} else {
-				expectedExec.WillReturnResult(driver.ResultNoRows)
+				expectedExec.WillReturnResult(sqlmock.NewResult(0, 1))
 			}
 
-			err = repo.UpdateItem(itemID, item)
+			err = repo.UpdateItem(userID, itemID, item)
 			if test.wantError != nil {
 				assert.True(t, errors.Is(err, test.wantError))
 			} else {
@@ -23,8 +23,8 @@ func NewService(repo Repo, cloudinary cloudinary.Repo) Service {
 type Service interface {
 	GetListItemWithPagination(params ListItemRequest) (*ListItem, *util.Pagination, error)
 	GetItemByID(placeID int, itemID int) (*Item, error)
-	DeleteItemAdminByID(itemID int) error
-	UpdateItem(ID int, item Item) error
+	DeleteItemAdminByID(userID int, itemID int) error
+	UpdateItem(userID int, ID int, item Item) error
 	CreateItem(userID int, item Item) error
 }
 
@@ -86,8 +86,8 @@ func (s service) GetItemByID(placeID int, itemID int) (*Item, error) {
 	return item, err
 }
 
-func (s service) DeleteItemAdminByID(itemID int) error {
-	err := s.repo.DeleteItemAdminByID(itemID)
+func (s service) DeleteItemAdminByID(userID int, itemID int) error {
+	err := s.repo.DeleteItemAdminByID(userID, itemID)
 
 	if err != nil {
 		return err
@@ -96,7 +96,17 @@ func (s service) DeleteItemAdminByID(itemID int) error {
 	return nil
 }
 
-func (s service) UpdateItem(ID int, item Item) error {
+func (s service) UpdateItem(userID int, ID int, item Item) error {
+	// make sure the item is in the place before the image is uploaded
+	isOwner, err := s.repo.IsItemOwner(userID, ID)
+	if err != nil {
+		return err
+	}
+
+	if !isOwner {
+		return fmt.Errorf("item not found: %w", ErrNotFound)
+	}
+
 	if !strings.HasPrefix(item.Image, "data:") {
 		return fmt.Errorf("string is not a data URI: %w", ErrInputValidationError)
 	}
@@ -108,7 +118,7 @@ func (s service) UpdateItem(ID int, item Item) error {
 
 	imageString := strings.Split(withoutData, ",")[1]
 
-	_, err := base64.StdEncoding.DecodeString(imageString)
+	_, err = base64.StdEncoding.DecodeString(imageString)
 	if err != nil {
 		logrus.Errorf("image string is not base64: %v", err)
 		return fmt.Errorf("image string is not base64: %w", ErrInputValidationError)
@@ -120,7 +130,7 @@ func (s service) UpdateItem(ID int, item Item) error {
 	}
 
 	item.Image = imageURL
-	if err := s.repo.UpdateItem(ID, item); err != nil {
+	if err := s.repo.UpdateItem(userID, ID, item); err != nil {
 		return err
 	}
 	return nil
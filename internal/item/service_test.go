@@ -31,13 +31,18 @@ func (m *MockRepository) GetListItemAdminWithPagination(params ListItemRequest)
 	return &ret, args.Error(1)
 }
 
-func (m *MockRepository) DeleteItemAdminByID(itemID int) error {
-	args := m.Called(itemID)
+func (m *MockRepository) IsItemOwner(userID int, itemID int) (bool, error) {
+	args := m.Called(userID, itemID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteItemAdminByID(userID int, itemID int) error {
+	args := m.Called(userID, itemID)
 	return args.Error(0)
 }
 
-func (m *MockRepository) UpdateItem(ID int, item Item) error {
-	args := m.Called(ID, item)
+func (m *MockRepository) UpdateItem(userID int, ID int, item Item) error {
+	args := m.Called(userID, ID, item)
 	return args.Error(0)
 }
 
@@ -331,9 +336,9 @@ func TestService_DeleteItemAdminByID(t *testing.T) {
 		// input
 		itemID := 1
 
-		mockRepo.On("DeleteItemAdminByID", itemID).Return(nil)
+		mockRepo.On("DeleteItemAdminByID", 1, itemID).Return(nil)
 
-		err := service.DeleteItemAdminByID(itemID)
+		err := service.DeleteItemAdminByID(1, itemID)
 		assert.Nil(t, err)
 	})
 
@@ -344,12 +349,26 @@ func TestService_DeleteItemAdminByID(t *testing.T) {
 		// input
 		itemID := 1
 
-		mockRepo.On("DeleteItemAdminByID", itemID).Return(ErrInternalServerError)
+		mockRepo.On("DeleteItemAdminByID", 1, itemID).Return(ErrInternalServerError)
 
-		err := service.DeleteItemAdminByID(itemID)
+		err := service.DeleteItemAdminByID(1, itemID)
 		assert.NotNil(t, err)
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
+
+	t.Run("item of another place", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, nil)
+
+		// input
+		itemID := 1
+
+		mockRepo.On("DeleteItemAdminByID", 2, itemID).Return(ErrNotFound)
+
+		err := service.DeleteItemAdminByID(2, itemID)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
 }
 
 func TestService_UpdateItem(t *testing.T) {
@@ -359,6 +378,7 @@ func TestService_UpdateItem(t *testing.T) {
 		expectedItem          Item
 		wantError             error
 		cloudinaryExpectError bool
+		notOwner              bool
 	}{
 		"success": {
 			expectedItem: Item{
@@ -406,6 +426,16 @@ func TestService_UpdateItem(t *testing.T) {
 			},
 			wantError: ErrInternalServerError,
 		},
+		"item of another place": {
+			expectedItem: Item{
+				Name:        "Nama item",
+				Image:       imageString,
+				Description: "Deskripsi item",
+				Price:       1000,
+			},
+			notOwner:  true,
+			wantError: ErrNotFound,
+		},
 	}
 
 	for name, test := range tests {
@@ -413,9 +443,11 @@ func TestService_UpdateItem(t *testing.T) {
 			mockRepo := new(MockRepository)
 			mockCloudinary := new(MockCloudinary)
 			service := NewService(mockRepo, mockCloudinary)
+			userID := 1
 			expectedID := 1
 
-			mockRepo.On("UpdateItem", expectedID, test.expectedItem).Return(test.wantError)
+			mockRepo.On("IsItemOwner", userID, expectedID).Return(!test.notOwner, nil)
+			mockRepo.On("UpdateItem", userID, expectedID, test.expectedItem).Return(test.wantError)
 
 			if test.cloudinaryExpectError {
 				mockCloudinary.On("UploadFile", test.expectedItem.Image, "Item Image", fmt.Sprintf("%d-%s", test.expectedItem.ID, test.expectedItem.Name)).Return("", test.wantError)
@@ -423,7 +455,10 @@ func TestService_UpdateItem(t *testing.T) {
 				mockCloudinary.On("UploadFile", test.expectedItem.Image, "Item Image", fmt.Sprintf("%d-%s", test.expectedItem.ID, test.expectedItem.Name)).Return(imageString, nil)
 			}
 
-			err := service.UpdateItem(expectedID, test.expectedItem)
+			err := service.UpdateItem(userID, expectedID, test.expectedItem)
+			if test.notOwner {
+				mockCloudinary.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything, mock.Anything)
+			}
 			if test.wantError != nil {
 				assert.True(t, errors.Is(err, test.wantError))
 			} else {
@@ -0,0 +1,39 @@
+package member
+
+import "time"
+
+// Member is a staff account of a place
+type Member struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Invitation is sent by email to the staff candidate, the token can only be used once before it expires
+type Invitation struct {
+	ID         int        `json:"id" db:"id"`
+	PlaceID    int        `json:"-" db:"place_id"`
+	PlaceName  string     `json:"place_name" db:"place_name"`
+	Email      string     `json:"email" db:"email"`
+	Role       string     `json:"role" db:"role"`
+	Token      string     `json:"-" db:"token"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at" db:"accepted_at"`
+}
+
+// InviteRequest is the body of invite staff endpoint
+type InviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// AcceptRequest is the body of accept invitation endpoint, it create the staff account
+type AcceptRequest struct {
+	Token       string `json:"token"`
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	Password    string `json:"password"`
+}
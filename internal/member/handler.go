@@ -0,0 +1,119 @@
+package member
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Handler struct for member
+type Handler struct {
+	service Service
+}
+
+// NewHandler is used to initialize Handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetMembers for handling get staff list of the place endpoint
+func (h *Handler) GetMembers(c echo.Context) error {
+	membership, err := middleware.ParseMembership(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	members, err := h.service.GetMembers(membership.PlaceID)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    members,
+	})
+}
+
+// Invite for handling invite staff by email endpoint
+func (h *Handler) Invite(c echo.Context) error {
+	membership, err := middleware.ParseMembership(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	var req InviteRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	invitation, err := h.service.Invite(membership.PlaceID, req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    invitation,
+	})
+}
+
+// RemoveMember for handling remove staff from the place endpoint
+func (h *Handler) RemoveMember(c echo.Context) error {
+	membership, err := middleware.ParseMembership(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	memberID, err := strconv.Atoi(c.Param("memberID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "memberID must be number")
+	}
+
+	err = h.service.RemoveMember(membership.PlaceID, memberID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// AcceptInvitation for handling create staff account from invitation endpoint
+func (h *Handler) AcceptInvitation(c echo.Context) error {
+	var req AcceptRequest
+	if err := c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	invitation, err := h.service.AcceptInvitation(req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    invitation,
+	})
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch errors.Cause(err) {
+	case ErrInputValidationError:
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+	case ErrNotFound:
+		return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+	default:
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,234 @@
+package member
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetMembers(placeID int) (*[]Member, error) {
+	args := m.Called(placeID)
+	return args.Get(0).(*[]Member), args.Error(1)
+}
+
+func (m *MockService) Invite(placeID int, request InviteRequest) (*Invitation, error) {
+	args := m.Called(placeID, request)
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockService) AcceptInvitation(request AcceptRequest) (*Invitation, error) {
+	args := m.Called(request)
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockService) RemoveMember(placeID int, memberID int) error {
+	args := m.Called(placeID, memberID)
+	return args.Error(0)
+}
+
+func newMemberContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder) echo.Context {
+	ctx := e.NewContext(req, rec)
+	ctx.Set("membership", &user.Membership{PlaceID: 10, OwnerID: 1, Role: util.RoleOwner})
+
+	return ctx
+}
+
+func TestHandler_GetMembers(t *testing.T) {
+	url := "/api/v1/business-admin/staff"
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		ctx := newMemberContext(e, httptest.NewRequest(http.MethodGet, url, nil), rec)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetMembers", 10).Return(&[]Member{{ID: 1, Name: "Kasir", Role: util.RoleCashier}}, nil)
+
+		assert.NoError(t, h.GetMembers(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"role":"cashier"`)
+	})
+
+	t.Run("failed membership is not resolved", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, url, nil), rec)
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetMembers(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		ctx := newMemberContext(e, httptest.NewRequest(http.MethodGet, url, nil), rec)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetMembers", 10).Return(&[]Member{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetMembers(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_Invite(t *testing.T) {
+	url := "/api/v1/business-admin/staff/invitations"
+	body := `{"email": "kasir@example.com", "role": "cashier"}`
+	inviteRequest := InviteRequest{Email: "kasir@example.com", Role: util.RoleCashier}
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		return newMemberContext(e, req, rec), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext(body)
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("Invite", 10, inviteRequest).Return(&Invitation{ID: 1, Email: inviteRequest.Email, Role: inviteRequest.Role, Token: "secret"}, nil)
+
+		assert.NoError(t, h.Invite(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "secret")
+	})
+
+	t.Run("failed invalid body", func(t *testing.T) {
+		ctx, rec := newContext("{")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.Invite(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newContext(body)
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("Invite", 10, inviteRequest).Return(&Invitation{}, errors.Wrap(ErrInputValidationError, "email is already registered"))
+
+		util.ErrorHandler(h.Invite(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext(body)
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("Invite", 10, inviteRequest).Return(&Invitation{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.Invite(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_RemoveMember(t *testing.T) {
+	newContext := func(memberID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		ctx := newMemberContext(e, httptest.NewRequest(http.MethodDelete, "/api/v1/business-admin/staff/2", nil), rec)
+		ctx.SetParamNames("memberID")
+		ctx.SetParamValues(memberID)
+
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("2")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("RemoveMember", 10, 2).Return(nil)
+
+		assert.NoError(t, h.RemoveMember(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed memberID is not number", func(t *testing.T) {
+		ctx, rec := newContext("a")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.RemoveMember(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		ctx, rec := newContext("2")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("RemoveMember", 10, 2).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.RemoveMember(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_AcceptInvitation(t *testing.T) {
+	url := "/api/v1/auth/business-admin/staff/accept"
+	body := `{"token": "token", "name": "Kasir", "phone_number": "08123456789", "password": "password"}`
+	acceptRequest := AcceptRequest{Token: "token", Name: "Kasir", PhoneNumber: "08123456789", Password: "password"}
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext(body)
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("AcceptInvitation", acceptRequest).Return(&Invitation{ID: 1, PlaceName: "Futsal", Role: util.RoleCashier}, nil)
+
+		assert.NoError(t, h.AcceptInvitation(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("failed invalid body", func(t *testing.T) {
+		ctx, rec := newContext("{")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.AcceptInvitation(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed invitation is invalid or expired", func(t *testing.T) {
+		ctx, rec := newContext(body)
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("AcceptInvitation", acceptRequest).Return(&Invitation{}, errors.Wrap(ErrNotFound, "invitation is invalid or expired"))
+
+		util.ErrorHandler(h.AcceptInvitation(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
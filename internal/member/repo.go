@@ -0,0 +1,158 @@
+package member
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// uniqueViolation is the postgres error code when a unique constraint is violated
+const uniqueViolation = "23505"
+
+// NewRepo used to initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	GetMembers(placeID int) (*[]Member, error)
+	DeleteMember(placeID int, memberID int) error
+	IsEmailRegistered(email string) (bool, error)
+	UpsertInvitation(invitation Invitation) (*Invitation, error)
+	AcceptInvitation(request AcceptRequest, hashedPassword string) (*Invitation, error)
+}
+
+func (r repo) GetMembers(placeID int) (*[]Member, error) {
+	members := make([]Member, 0)
+
+	query := `SELECT pm.id, pm.user_id, u.name, COALESCE(u.email, '') AS email, pm.role, pm.created_at
+			FROM place_members pm
+			INNER JOIN users u ON u.id = pm.user_id
+			WHERE pm.place_id = $1
+			ORDER BY pm.created_at, pm.id`
+
+	err := r.db.Select(&members, query, placeID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &members, nil
+}
+
+func (r repo) DeleteMember(placeID int, memberID int) error {
+	query := "DELETE FROM place_members WHERE id = $1 AND place_id = $2"
+
+	result, err := r.db.Exec(query, memberID, placeID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrNotFound, fmt.Sprintf("member with id %d is not found", memberID))
+	}
+
+	return nil
+}
+
+func (r repo) IsEmailRegistered(email string) (bool, error) {
+	var registered bool
+
+	query := "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)"
+	err := r.db.Get(&registered, query, email)
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return registered, nil
+}
+
+// UpsertInvitation replace the previous invitation of the same email, so the old token can no longer be used
+func (r repo) UpsertInvitation(invitation Invitation) (*Invitation, error) {
+	var result Invitation
+
+	query := `INSERT INTO place_member_invitations (place_id, email, role, token, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (place_id, email) DO UPDATE SET
+				role = EXCLUDED.role, token = EXCLUDED.token, expires_at = EXCLUDED.expires_at, accepted_at = NULL, created_at = NOW()
+			RETURNING id, place_id, (SELECT name FROM places WHERE id = $1) AS place_name, email, role, token, expires_at, accepted_at`
+
+	err := r.db.Get(&result, query, invitation.PlaceID, invitation.Email, invitation.Role, invitation.Token, invitation.ExpiresAt)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+// AcceptInvitation mark the invitation as used and create the staff account in a transaction
+func (r repo) AcceptInvitation(request AcceptRequest, hashedPassword string) (*Invitation, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var invitation Invitation
+	query := `UPDATE place_member_invitations SET accepted_at = NOW()
+			WHERE token = $1 AND accepted_at IS NULL AND expires_at > NOW()
+			RETURNING id, place_id, (SELECT name FROM places WHERE id = place_id) AS place_name, email, role, token, expires_at, accepted_at`
+	err = tx.Get(&invitation, query, request.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, "invitation is invalid or expired")
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	var userID int
+	query = "INSERT INTO users (phone_number, name, email, password, status) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	err = tx.Get(&userID, query, request.PhoneNumber, request.Name, invitation.Email, hashedPassword, util.StatusBusinessAdmin)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return nil, errors.Wrap(ErrInputValidationError, "email or phone number is already registered")
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	// the owner of a place can not be a member, so the membership of a user is never ambiguous
+	query = `INSERT INTO place_members (place_id, user_id, role) SELECT $1, $2, $3
+			WHERE NOT EXISTS (SELECT 1 FROM places WHERE user_id = $2)`
+	result, err := tx.Exec(query, invitation.PlaceID, userID, invitation.Role)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "owner of a place can not be a member")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &invitation, nil
+}
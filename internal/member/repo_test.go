@@ -0,0 +1,234 @@
+package member
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+var invitationColumns = []string{"id", "place_id", "place_name", "email", "role", "token", "expires_at", "accepted_at"}
+
+func TestRepo_GetMembers(t *testing.T) {
+	query := "SELECT pm.id, pm.user_id, u.name, COALESCE(u.email, '') AS email, pm.role, pm.created_at"
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(10).
+			WillReturnRows(mock.NewRows([]string{"id", "user_id", "name", "email", "role", "created_at"}).
+				AddRow(1, 2, "Kasir", "kasir@example.com", util.RoleCashier, now))
+
+		result, err := repoMock.GetMembers(10)
+		assert.Nil(t, err)
+		assert.Equal(t, &[]Member{{ID: 1, UserID: 2, Name: "Kasir", Email: "kasir@example.com", Role: util.RoleCashier, CreatedAt: now}}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(10).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetMembers(10)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_DeleteMember(t *testing.T) {
+	query := "DELETE FROM place_members WHERE id = $1 AND place_id = $2"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(2, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.Nil(t, repoMock.DeleteMember(10, 2))
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(2, 10).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repoMock.DeleteMember(10, 2)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(2, 10).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.DeleteMember(10, 2)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_IsEmailRegistered(t *testing.T) {
+	query := "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("kasir@example.com").
+			WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+
+		registered, err := repoMock.IsEmailRegistered("kasir@example.com")
+		assert.Nil(t, err)
+		assert.True(t, registered)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("kasir@example.com").WillReturnError(sql.ErrConnDone)
+
+		_, err := repoMock.IsEmailRegistered("kasir@example.com")
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpsertInvitation(t *testing.T) {
+	query := "INSERT INTO place_member_invitations (place_id, email, role, token, expires_at)"
+	expiresAt := time.Now().Add(time.Hour)
+	invitation := Invitation{PlaceID: 10, Email: "kasir@example.com", Role: util.RoleCashier, Token: "token", ExpiresAt: expiresAt}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(10, invitation.Email, invitation.Role, invitation.Token, expiresAt).
+			WillReturnRows(mock.NewRows(invitationColumns).AddRow(1, 10, "Futsal", invitation.Email, invitation.Role, invitation.Token, expiresAt, nil))
+
+		result, err := repoMock.UpsertInvitation(invitation)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, "Futsal", result.PlaceName)
+		assert.Nil(t, result.AcceptedAt)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.UpsertInvitation(invitation)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_AcceptInvitation(t *testing.T) {
+	updateQuery := "UPDATE place_member_invitations SET accepted_at = NOW()"
+	userQuery := "INSERT INTO users (phone_number, name, email, password, status) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	memberQuery := "INSERT INTO place_members (place_id, user_id, role) SELECT $1, $2, $3"
+	request := AcceptRequest{Token: "token", Name: "Kasir", PhoneNumber: "08123456789", Password: "password"}
+	now := time.Now()
+
+	invitationRows := func(mock sqlmock.Sqlmock) *sqlmock.Rows {
+		return mock.NewRows(invitationColumns).AddRow(1, 10, "Futsal", "kasir@example.com", util.RoleCashier, "token", now, now)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(updateQuery)).WithArgs("token").WillReturnRows(invitationRows(mock))
+		mock.ExpectQuery(regexp.QuoteMeta(userQuery)).
+			WithArgs(request.PhoneNumber, request.Name, "kasir@example.com", "hashed", util.StatusBusinessAdmin).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(regexp.QuoteMeta(memberQuery)).WithArgs(10, 5, util.RoleCashier).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		result, err := repoMock.AcceptInvitation(request, "hashed")
+		assert.Nil(t, err)
+		assert.Equal(t, 10, result.PlaceID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed invitation is invalid or expired", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(updateQuery)).WithArgs("token").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		result, err := repoMock.AcceptInvitation(request, "hashed")
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed email or phone number is already registered", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(updateQuery)).WithArgs("token").WillReturnRows(invitationRows(mock))
+		mock.ExpectQuery(regexp.QuoteMeta(userQuery)).WillReturnError(&pq.Error{Code: uniqueViolation})
+		mock.ExpectRollback()
+
+		result, err := repoMock.AcceptInvitation(request, "hashed")
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed user is owner of a place", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(updateQuery)).WithArgs("token").WillReturnRows(invitationRows(mock))
+		mock.ExpectQuery(regexp.QuoteMeta(userQuery)).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(regexp.QuoteMeta(memberQuery)).WithArgs(10, 5, util.RoleCashier).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		result, err := repoMock.AcceptInvitation(request, "hashed")
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "owner of a place can not be a member")
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(updateQuery)).WithArgs("token").WillReturnRows(invitationRows(mock))
+		mock.ExpectQuery(regexp.QuoteMeta(userQuery)).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectExec(regexp.QuoteMeta(memberQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		result, err := repoMock.AcceptInvitation(request, "hashed")
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
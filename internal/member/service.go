@@ -0,0 +1,146 @@
+package member
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	GetMembers(placeID int) (*[]Member, error)
+	Invite(placeID int, request InviteRequest) (*Invitation, error)
+	AcceptInvitation(request AcceptRequest) (*Invitation, error)
+	RemoveMember(placeID int, memberID int) error
+}
+
+type service struct {
+	repo   Repo
+	mailer notification.Channel
+}
+
+// NewService for initialize service, invitation can not be sent when mailer is nil
+func NewService(repo Repo, mailer notification.Channel) Service {
+	return &service{
+		repo:   repo,
+		mailer: mailer,
+	}
+}
+
+func (s *service) GetMembers(placeID int) (*[]Member, error) {
+	return s.repo.GetMembers(placeID)
+}
+
+// Invite save the invitation then send the token to the email, inviting the same email again replace the old token
+func (s *service) Invite(placeID int, request InviteRequest) (*Invitation, error) {
+	var errorList []string
+
+	if _, err := mail.ParseAddress(request.Email); err != nil {
+		errorList = append(errorList, "email address is invalid")
+	}
+
+	if request.Role != util.RoleManager && request.Role != util.RoleCashier {
+		errorList = append(errorList, fmt.Sprintf("role must be %s or %s", util.RoleManager, util.RoleCashier))
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	if s.mailer == nil {
+		return nil, errors.Wrap(ErrInternalServerError, "email channel is not configured")
+	}
+
+	registered, err := s.repo.IsEmailRegistered(request.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if registered {
+		return nil, errors.Wrap(ErrInputValidationError, "email is already registered")
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.repo.UpsertInvitation(Invitation{
+		PlaceID:   placeID,
+		Email:     request.Email,
+		Role:      request.Role,
+		Token:     token,
+		ExpiresAt: time.Now().Add(util.StaffInvitationExpiryHours * time.Hour),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(notification.Message{
+		Recipient: invitation.Email,
+		Subject:   fmt.Sprintf("Undangan staff %s", invitation.PlaceName),
+		Body: fmt.Sprintf("Anda diundang menjadi %s di %s. Gunakan kode undangan berikut untuk membuat akun sebelum %s: %s",
+			invitation.Role, invitation.PlaceName, invitation.ExpiresAt.Format("2006-01-02 15:04"), invitation.Token),
+	})
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return invitation, nil
+}
+
+func (s *service) AcceptInvitation(request AcceptRequest) (*Invitation, error) {
+	var errorList []string
+
+	if request.Token == "" {
+		errorList = append(errorList, "token is required")
+	}
+
+	if len(request.Name) < util.MinimumNameLength || len(request.Name) > 50 {
+		errorList = append(errorList, "name must be 3 - 50 characters")
+	}
+
+	if request.PhoneNumber == "" || len(request.PhoneNumber) > 15 || strings.Trim(request.PhoneNumber, "0123456789") != "" {
+		errorList = append(errorList, "phone number is invalid")
+	}
+
+	if len(request.Password) < util.MinimumPasswordLength {
+		errorList = append(errorList, fmt.Sprintf("password is at least %d characters", util.MinimumPasswordLength))
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), 10)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return s.repo.AcceptInvitation(request, string(hashedPassword))
+}
+
+func (s *service) RemoveMember(placeID int, memberID int) error {
+	if memberID <= 0 {
+		return errors.Wrap(ErrInputValidationError, "memberID must be above 0")
+	}
+
+	return s.repo.DeleteMember(placeID, memberID)
+}
+
+func generateToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,198 @@
+package member
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetMembers(placeID int) (*[]Member, error) {
+	args := m.Called(placeID)
+	return args.Get(0).(*[]Member), args.Error(1)
+}
+
+func (m *MockRepository) DeleteMember(placeID int, memberID int) error {
+	args := m.Called(placeID, memberID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) IsEmailRegistered(email string) (bool, error) {
+	args := m.Called(email)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) UpsertInvitation(invitation Invitation) (*Invitation, error) {
+	args := m.Called(invitation)
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+func (m *MockRepository) AcceptInvitation(request AcceptRequest, hashedPassword string) (*Invitation, error) {
+	args := m.Called(request, hashedPassword)
+	return args.Get(0).(*Invitation), args.Error(1)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Name() string {
+	return util.NotificationChannelEmail
+}
+
+func (m *MockMailer) Recipient(recipient notification.Recipient) string {
+	return recipient.Email
+}
+
+func (m *MockMailer) Send(message notification.Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func TestService_GetMembers(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockService := NewService(mockRepo, nil)
+
+	members := &[]Member{{ID: 1, Role: util.RoleCashier}}
+	mockRepo.On("GetMembers", 10).Return(members, nil)
+
+	result, err := mockService.GetMembers(10)
+	assert.Nil(t, err)
+	assert.Equal(t, members, result)
+}
+
+func TestService_Invite(t *testing.T) {
+	request := InviteRequest{Email: "kasir@example.com", Role: util.RoleCashier}
+	isInvitation := mock.MatchedBy(func(invitation Invitation) bool {
+		return invitation.PlaceID == 10 && invitation.Email == request.Email && invitation.Role == request.Role &&
+			len(invitation.Token) == 64 && invitation.ExpiresAt.After(time.Now().Add(71*time.Hour))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockMailer := new(MockMailer)
+		mockService := NewService(mockRepo, mockMailer)
+
+		invitation := &Invitation{ID: 1, PlaceName: "Futsal", Email: request.Email, Role: request.Role, Token: "token"}
+		mockRepo.On("IsEmailRegistered", request.Email).Return(false, nil)
+		mockRepo.On("UpsertInvitation", isInvitation).Return(invitation, nil)
+		mockMailer.On("Send", mock.MatchedBy(func(message notification.Message) bool {
+			return message.Recipient == request.Email && message.Subject == "Undangan staff Futsal"
+		})).Return(nil)
+
+		result, err := mockService.Invite(10, request)
+		assert.Nil(t, err)
+		assert.Equal(t, invitation, result)
+		mockMailer.AssertExpectations(t)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), new(MockMailer))
+
+		result, err := mockService.Invite(10, InviteRequest{Email: "invalid", Role: util.RoleOwner})
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "email address is invalid")
+		assert.Contains(t, err.Error(), "role must be manager or cashier")
+	})
+
+	t.Run("failed email channel is not configured", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), nil)
+
+		result, err := mockService.Invite(10, request)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed email is already registered", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, new(MockMailer))
+
+		mockRepo.On("IsEmailRegistered", request.Email).Return(true, nil)
+
+		result, err := mockService.Invite(10, request)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed send email", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockMailer := new(MockMailer)
+		mockService := NewService(mockRepo, mockMailer)
+
+		mockRepo.On("IsEmailRegistered", request.Email).Return(false, nil)
+		mockRepo.On("UpsertInvitation", isInvitation).Return(&Invitation{Email: request.Email}, nil)
+		mockMailer.On("Send", mock.Anything).Return(errors.New("smtp error"))
+
+		result, err := mockService.Invite(10, request)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_AcceptInvitation(t *testing.T) {
+	request := AcceptRequest{Token: "token", Name: "Kasir", PhoneNumber: "08123456789", Password: "password"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil)
+
+		invitation := &Invitation{ID: 1, PlaceID: 10, Role: util.RoleCashier}
+		mockRepo.On("AcceptInvitation", request, mock.MatchedBy(func(hashedPassword string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(request.Password)) == nil
+		})).Return(invitation, nil)
+
+		result, err := mockService.AcceptInvitation(request)
+		assert.Nil(t, err)
+		assert.Equal(t, invitation, result)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), nil)
+
+		result, err := mockService.AcceptInvitation(AcceptRequest{Name: "ab", PhoneNumber: "08a", Password: "short"})
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "token is required")
+		assert.Contains(t, err.Error(), "name must be 3 - 50 characters")
+		assert.Contains(t, err.Error(), "phone number is invalid")
+		assert.Contains(t, err.Error(), "password is at least 8 characters")
+	})
+
+	t.Run("failed invitation is invalid or expired", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil)
+
+		mockRepo.On("AcceptInvitation", request, mock.Anything).Return(&Invitation{}, errors.Wrap(ErrNotFound, "invitation is invalid or expired"))
+
+		_, err := mockService.AcceptInvitation(request)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestService_RemoveMember(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, nil)
+
+		mockRepo.On("DeleteMember", 10, 2).Return(nil)
+
+		assert.Nil(t, mockService.RemoveMember(10, 2))
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), nil)
+
+		err := mockService.RemoveMember(10, 0)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
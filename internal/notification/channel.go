@@ -0,0 +1,220 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Channel is a way to deliver notification to the user
+type Channel interface {
+	Name() string
+	// Recipient return the address of user in this channel, empty if the user can not be reached
+	Recipient(recipient Recipient) string
+	Send(message Message) error
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+type emailChannel struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewEmailChannel for initialize email channel that send through smtp
+func NewEmailChannel(host, port, username, password, from string) Channel {
+	return &emailChannel{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (c emailChannel) Name() string {
+	return util.NotificationChannelEmail
+}
+
+func (c emailChannel) Recipient(recipient Recipient) string {
+	return recipient.Email
+}
+
+func (c emailChannel) Send(message Message) error {
+	var auth smtp.Auth
+	if c.username != "" {
+		auth = smtp.PlainAuth("", c.username, c.password, c.host)
+	}
+
+	err := smtp.SendMail(net.JoinHostPort(c.host, c.port), auth, c.from, []string{message.Recipient}, encodeEmail(c.from, message))
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func encodeEmail(from string, message Message) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	buffer.WriteString(fmt.Sprintf("To: %s\r\n", message.Recipient))
+	buffer.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject)))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buffer.WriteString("\r\n")
+	buffer.WriteString(message.Body)
+
+	return buffer.Bytes()
+}
+
+type pushChannel struct {
+	url       string
+	serverKey string
+}
+
+// NewPushChannel for initialize push notification channel that send through firebase cloud messaging
+func NewPushChannel(url, serverKey string) Channel {
+	return &pushChannel{
+		url:       url,
+		serverKey: serverKey,
+	}
+}
+
+func (c pushChannel) Name() string {
+	return util.NotificationChannelPush
+}
+
+func (c pushChannel) Recipient(recipient Recipient) string {
+	return recipient.DeviceToken
+}
+
+func (c pushChannel) Send(message Message) error {
+	reqBody := map[string]interface{}{
+		"to": message.Recipient,
+		"notification": map[string]string{
+			"title": message.Subject,
+			"body":  message.Body,
+		},
+		"data": map[string]string{
+			"event": message.Event,
+		},
+	}
+
+	respBuffer, err := postJSON(c.url, "key="+c.serverKey, reqBody)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Failure int `json:"failure"`
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	err = json.Unmarshal(respBuffer, &resp)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if resp.Failure > 0 {
+		errorMessage := "push notification is rejected"
+		if len(resp.Results) > 0 && resp.Results[0].Error != "" {
+			errorMessage = resp.Results[0].Error
+		}
+
+		return errors.Wrap(ErrInternalServerError, errorMessage)
+	}
+
+	return nil
+}
+
+type smsChannel struct {
+	url   string
+	token string
+}
+
+// NewSMSChannel for initialize sms channel that send through sms gateway
+func NewSMSChannel(url, token string) Channel {
+	return &smsChannel{
+		url:   url,
+		token: token,
+	}
+}
+
+func (c smsChannel) Name() string {
+	return util.NotificationChannelSMS
+}
+
+func (c smsChannel) Recipient(recipient Recipient) string {
+	return recipient.PhoneNumber
+}
+
+func (c smsChannel) Send(message Message) error {
+	reqBody := map[string]string{
+		"to":      message.Recipient,
+		"message": fmt.Sprintf("%s: %s", message.Subject, message.Body),
+	}
+
+	_, err := postJSON(c.url, "Bearer "+c.token, reqBody)
+	return err
+}
+
+type inAppChannel struct {
+	repo Repo
+}
+
+// NewInAppChannel for initialize channel that store notification for the application inbox
+func NewInAppChannel(repo Repo) Channel {
+	return &inAppChannel{
+		repo: repo,
+	}
+}
+
+func (c inAppChannel) Name() string {
+	return util.NotificationChannelInApp
+}
+
+func (c inAppChannel) Recipient(recipient Recipient) string {
+	return strconv.Itoa(recipient.UserID)
+}
+
+func (c inAppChannel) Send(message Message) error {
+	return c.repo.InsertInAppNotification(message)
+}
+
+func postJSON(url, authorization string, reqBody interface{}) ([]byte, error) {
+	reqBodyJSON, _ := json.Marshal(reqBody)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(reqBodyJSON))
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBuffer, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.Wrap(ErrInternalServerError, fmt.Sprintf("status code %d: %s", resp.StatusCode, respBuffer))
+	}
+
+	return respBuffer, nil
+}
@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+var message = Message{UserID: 1, Event: util.NotificationBookingPaid, Recipient: "recipient", Subject: "Booking #1 is paid", Body: "Hi Rafi"}
+
+func TestPushChannel_Send(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var reqBody map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "key=server-key", r.Header.Get("Authorization"))
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &reqBody)
+			_, _ = w.Write([]byte(`{"success": 1, "failure": 0}`))
+		}))
+		defer server.Close()
+
+		err := NewPushChannel(server.URL, "server-key").Send(message)
+		assert.Nil(t, err)
+		assert.Equal(t, "recipient", reqBody["to"])
+		assert.Equal(t, map[string]interface{}{"title": "Booking #1 is paid", "body": "Hi Rafi"}, reqBody["notification"])
+	})
+
+	t.Run("failed rejected token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"success": 0, "failure": 1, "results": [{"error": "NotRegistered"}]}`))
+		}))
+		defer server.Close()
+
+		err := NewPushChannel(server.URL, "server-key").Send(message)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "NotRegistered")
+	})
+
+	t.Run("failed unauthorized", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		err := NewPushChannel(server.URL, "server-key").Send(message)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestSMSChannel_Send(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"to": "recipient", "message": "Booking #1 is paid: Hi Rafi"}`, string(body))
+		}))
+		defer server.Close()
+
+		err := NewSMSChannel(server.URL, "token").Send(message)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed gateway error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := NewSMSChannel(server.URL, "token").Send(message)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestInAppChannel_Send(t *testing.T) {
+	mockRepo := new(MockRepository)
+	channel := NewInAppChannel(mockRepo)
+
+	mockRepo.On("InsertInAppNotification", message).Return(nil)
+
+	assert.Nil(t, channel.Send(message))
+	assert.Equal(t, "1", channel.Recipient(Recipient{UserID: 1}))
+}
+
+func TestChannel_Recipient(t *testing.T) {
+	recipient := Recipient{UserID: 1, Email: "rafi@mail.com", PhoneNumber: "+6281234567890", DeviceToken: "token"}
+
+	assert.Equal(t, "rafi@mail.com", NewEmailChannel("localhost", "25", "", "", "noreply@mail.com").Recipient(recipient))
+	assert.Equal(t, "token", NewPushChannel("", "").Recipient(recipient))
+	assert.Equal(t, "+6281234567890", NewSMSChannel("", "").Recipient(recipient))
+}
+
+func TestEncodeEmail(t *testing.T) {
+	email := string(encodeEmail("noreply@mail.com", Message{Recipient: "rafi@mail.com", Subject: "Pembayaran berhasil", Body: "Halo Rafi"}))
+
+	assert.True(t, strings.HasPrefix(email, "From: noreply@mail.com\r\nTo: rafi@mail.com\r\nSubject: Pembayaran berhasil\r\n"))
+	assert.True(t, strings.HasSuffix(email, "\r\n\r\nHalo Rafi"))
+}
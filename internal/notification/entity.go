@@ -0,0 +1,95 @@
+package notification
+
+import "time"
+
+// Event is something happened in the application that should be notified to the user
+type Event struct {
+	ID        int    `db:"id"`
+	Type      string `db:"type"`
+	BookingID int    `db:"booking_id"`
+	// UserID is the receiver of event that is not related to a booking
+	UserID int     `db:"user_id"`
+	Amount float64 `db:"amount"`
+}
+
+// BookingData is the booking information used to render booking notification
+type BookingData struct {
+	ID           int     `db:"id"`
+	CustomerID   int     `db:"customer_id"`
+	OwnerID      int     `db:"owner_id"`
+	CustomerName string  `db:"customer_name"`
+	PlaceName    string  `db:"place_name"`
+	Date         string  `db:"date"`
+	StartTime    string  `db:"start_time"`
+	EndTime      string  `db:"end_time"`
+	TotalPrice   float64 `db:"total_price"`
+}
+
+// Recipient is the contact of user that receive the notification
+type Recipient struct {
+	UserID      int    `db:"id"`
+	Name        string `db:"name"`
+	Email       string `db:"email"`
+	PhoneNumber string `db:"phone_number"`
+	DeviceToken string `db:"device_token"`
+	Locale      string `db:"locale"`
+}
+
+// Message is a rendered notification in the outbox
+type Message struct {
+	ID            int       `db:"id"`
+	UserID        int       `db:"user_id"`
+	Event         string    `db:"event"`
+	Channel       string    `db:"channel"`
+	Recipient     string    `db:"recipient"`
+	Subject       string    `db:"subject"`
+	Body          string    `db:"body"`
+	Status        int       `db:"status"`
+	Attempt       int       `db:"attempt"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	LastError     string    `db:"last_error"`
+}
+
+// Preference is the notification setting of a user
+type Preference struct {
+	UserID      int    `json:"-"`
+	Locale      string `json:"locale"`
+	DeviceToken string `json:"device_token"`
+}
+
+// Report is the result of a single outbox run
+type Report struct {
+	Queued  int `json:"queued"`
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+}
+
+// Notification is an entry of the user in-app inbox
+type Notification struct {
+	ID        int        `json:"id"`
+	Event     string     `json:"event"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ListRequest for inbox list request params
+type ListRequest struct {
+	Limit  int    `json:"limit"`
+	Page   int    `json:"page"`
+	Path   string `json:"path"`
+	UserID int    `json:"user_id"`
+}
+
+// List for inbox list with total count for pagination
+type List struct {
+	Notifications []Notification `json:"notifications"`
+	TotalCount    int            `json:"total_count"`
+}
+
+// UnreadCount for number of unread notification in the inbox
+type UnreadCount struct {
+	UnreadCount int `json:"unread_count"`
+}
@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Handler struct for notification
+type Handler struct {
+	service Service
+}
+
+// NewHandler is used to initialize Handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// UpdatePreference for handling update notification preference endpoint
+func (h *Handler) UpdatePreference(c echo.Context) error {
+	userModel, err := parseUser(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	var preference Preference
+	if err := c.Bind(&preference); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, errors.Wrap(ErrInputValidationError, err.Error()))
+	}
+	preference.UserID = userModel.ID
+
+	err = h.service.UpdatePreference(preference)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// GetListWithPagination for handling get inbox list endpoint
+func (h *Handler) GetListWithPagination(c echo.Context) error {
+	userModel, err := parseUser(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	page, limit, errorList := util.ValidateParams(c.QueryParam("page"), c.QueryParam("limit"))
+	if len(errorList) > 0 {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
+	}
+
+	params := ListRequest{
+		Limit:  limit,
+		Page:   page,
+		Path:   "/api/v1/notifications",
+		UserID: userModel.ID,
+	}
+
+	list, pagination, err := h.service.GetListWithPagination(params)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data: map[string]interface{}{
+			"notifications": list.Notifications,
+			"pagination":    pagination,
+		},
+	})
+}
+
+// GetUnreadCount for handling get number of unread notification endpoint
+func (h *Handler) GetUnreadCount(c echo.Context) error {
+	userModel, err := parseUser(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	unreadCount, err := h.service.GetUnreadCount(userModel.ID)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    unreadCount,
+	})
+}
+
+// MarkAsRead for handling mark a notification as read endpoint
+func (h *Handler) MarkAsRead(c echo.Context) error {
+	userModel, err := parseUser(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	notificationID, err := strconv.Atoi(c.Param("notificationID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "notificationID must be number")
+	}
+
+	err = h.service.MarkAsRead(userModel.ID, notificationID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// MarkAllAsRead for handling mark every notification in the inbox as read endpoint
+func (h *Handler) MarkAllAsRead(c echo.Context) error {
+	userModel, err := parseUser(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	err = h.service.MarkAllAsRead(userModel.ID)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// parseUser return the registered user, notification is available for both customer and business admin
+func parseUser(c echo.Context) (*user.Model, error) {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusCustomer)
+	if err != nil {
+		_, userModel, err = middleware.ParseUserData(c, util.StatusBusinessAdmin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if userModel == nil {
+		return nil, errors.Wrap(middleware.ErrForbidden, "user is not registered")
+	}
+
+	return userModel, nil
+}
@@ -0,0 +1,335 @@
+package notification
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Notify(event Event) {
+	m.Called(event)
+}
+
+func (m *MockService) ProcessOutbox() (*Report, error) {
+	args := m.Called()
+	return args.Get(0).(*Report), args.Error(1)
+}
+
+func (m *MockService) UpdatePreference(preference Preference) error {
+	args := m.Called(preference)
+	return args.Error(0)
+}
+
+func (m *MockService) GetListWithPagination(params ListRequest) (*List, *util.Pagination, error) {
+	args := m.Called(params)
+	return args.Get(0).(*List), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+func (m *MockService) GetUnreadCount(userID int) (*UnreadCount, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*UnreadCount), args.Error(1)
+}
+
+func (m *MockService) MarkAsRead(userID int, notificationID int) error {
+	args := m.Called(userID, notificationID)
+	return args.Error(0)
+}
+
+func (m *MockService) MarkAllAsRead(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func newUserContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string, userModel *user.Model) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	ctx := e.NewContext(req, rec)
+	if userModel != nil {
+		ctx.Set("userFromDatabase", userModel)
+	}
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_UpdatePreference(t *testing.T) {
+	url := "/api/v1/user/notification-preference"
+	body := `{"locale": "en", "device_token": "token"}`
+	preference := Preference{UserID: 1, Locale: util.LocaleEnglish, DeviceToken: "token"}
+
+	for _, providerID := range []string{"phone", "password"} {
+		t.Run("success "+providerID, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := newUserContext(e, req, rec, providerID, &user.Model{ID: 1})
+
+			mockService := new(MockService)
+			h := NewHandler(mockService)
+
+			mockService.On("UpdatePreference", preference).Return(nil)
+
+			assert.NoError(t, h.UpdatePreference(ctx))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "{\"status\":200,\"message\":\"success\"}\n", rec.Body.String())
+		})
+	}
+
+	t.Run("failed user is not registered", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", nil)
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.UpdatePreference(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePreference", preference).Return(errors.Wrap(ErrInputValidationError, "locale must be id or en"))
+
+		util.ErrorHandler(h.UpdatePreference(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdatePreference", preference).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.UpdatePreference(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetListWithPagination(t *testing.T) {
+	url := "/api/v1/notifications?limit=10&page=1"
+	params := ListRequest{UserID: 1, Limit: 10, Page: 1, Path: "/api/v1/notifications"}
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		list := List{Notifications: []Notification{{ID: 1, Event: util.NotificationBookingCreated, Title: "title", Body: "body"}}, TotalCount: 1}
+		pagination := util.GeneratePagination(1, 10, 1, "/api/v1/notifications")
+		mockService.On("GetListWithPagination", params).Return(&list, &pagination, nil)
+
+		assert.NoError(t, h.GetListWithPagination(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"notifications":[{"id":1,"event":"booking_created","title":"title","body":"body","read_at":null`)
+		assert.Contains(t, rec.Body.String(), `"pagination":{"limit":10,"page":1`)
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", nil)
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetListWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed invalid params", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications?limit=a", nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetListWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetListWithPagination", params).Return(&List{}, &util.Pagination{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetListWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetUnreadCount(t *testing.T) {
+	url := "/api/v1/notifications/unread-count"
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetUnreadCount", 1).Return(&UnreadCount{UnreadCount: 3}, nil)
+
+		assert.NoError(t, h.GetUnreadCount(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "{\"status\":200,\"message\":\"success\",\"data\":{\"unread_count\":3}}\n", rec.Body.String())
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetUnreadCount", 1).Return(&UnreadCount{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetUnreadCount(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_MarkAsRead(t *testing.T) {
+	url := "/api/v1/notifications/5/read"
+
+	newContext := func(notificationID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+		ctx.SetParamNames("notificationID")
+		ctx.SetParamValues(notificationID)
+
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("5")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("MarkAsRead", 1, 5).Return(nil)
+
+		assert.NoError(t, h.MarkAsRead(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed notificationID is not number", func(t *testing.T) {
+		ctx, rec := newContext("a")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.MarkAsRead(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		ctx, rec := newContext("5")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("MarkAsRead", 1, 5).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.MarkAsRead(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext("5")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("MarkAsRead", 1, 5).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.MarkAsRead(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_MarkAllAsRead(t *testing.T) {
+	url := "/api/v1/notifications/read-all"
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("MarkAllAsRead", 1).Return(nil)
+
+		assert.NoError(t, h.MarkAllAsRead(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("MarkAllAsRead", 1).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.MarkAllAsRead(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
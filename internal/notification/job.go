@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Job queue the recorded events and send the outbox messages periodically in background
+type Job struct {
+	*util.Job
+	service Service
+}
+
+// NewJob for initialize notification job
+func NewJob(service Service, interval time.Duration) *Job {
+	job := &Job{service: service}
+	job.Job = util.NewJob(interval, func() { job.Run() })
+	return job
+}
+
+// Run a single outbox delivery and log the result
+func (j *Job) Run() *Report {
+	report, err := j.service.ProcessOutbox()
+	if err != nil {
+		logrus.Errorf("[notification] failed: %v", err)
+		return nil
+	}
+
+	if report.Queued+report.Sent+report.Retried+report.Failed > 0 {
+		logrus.Infof("[notification] queued %d events, sent %d, retried %d and failed %d messages", report.Queued, report.Sent, report.Retried, report.Failed)
+	}
+
+	return report
+}
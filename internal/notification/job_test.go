@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJob_Run(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		job := NewJob(mockService, time.Minute)
+
+		mockService.On("ProcessOutbox").Return(&Report{Sent: 2, Retried: 1}, nil)
+
+		assert.Equal(t, &Report{Sent: 2, Retried: 1}, job.Run())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mockService := new(MockService)
+		job := NewJob(mockService, time.Minute)
+
+		mockService.On("ProcessOutbox").Return(&Report{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		assert.Nil(t, job.Run())
+	})
+}
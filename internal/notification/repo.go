@@ -0,0 +1,263 @@
+package notification
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// NewRepo used to initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	GetBookingData(bookingID int) (*BookingData, error)
+	GetRecipient(userID int) (*Recipient, error)
+	GetPendingEvents(limit int) (*[]Event, error)
+	QueueEvent(eventID int, messages []Message) (bool, error)
+	GetPendingOutbox(limit int) (*[]Message, error)
+	UpdateOutbox(message Message) error
+	InsertInAppNotification(message Message) error
+	UpdatePreference(preference Preference) error
+	GetListWithPagination(params ListRequest) (*List, error)
+	CountUnread(userID int) (int, error)
+	MarkAsRead(userID int, notificationID int) error
+	MarkAllAsRead(userID int) error
+}
+
+func (r repo) GetBookingData(bookingID int) (*BookingData, error) {
+	var result BookingData
+
+	query := `SELECT b.id, b.user_id AS customer_id, p.user_id AS owner_id, u.name AS customer_name, p.name AS place_name,
+				to_char(b.date, 'YYYY-MM-DD') AS date, to_char(b.start_time, 'HH24:MI') AS start_time, to_char(b.end_time, 'HH24:MI') AS end_time,
+				b.total_price + COALESCE(b.booking_price, p.booking_price, 0) AS total_price
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			INNER JOIN users u ON b.user_id = u.id
+			WHERE b.id = $1`
+
+	err := r.db.Get(&result, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id %d is not found", bookingID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetRecipient(userID int) (*Recipient, error) {
+	var result Recipient
+
+	query := "SELECT id, name, COALESCE(email, '') AS email, COALESCE(phone_number, '') AS phone_number, device_token, locale FROM users WHERE id = $1"
+	err := r.db.Get(&result, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("user with id %d is not found", userID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+// InsertEvent record the event in the transaction of the business change, so the event is never lost
+// when the application stop right after the change is committed
+func InsertEvent(tx *sqlx.Tx, event Event) error {
+	query := "INSERT INTO notification_events (type, booking_id, user_id, amount) VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4)"
+
+	_, err := tx.Exec(query, event.Type, event.BookingID, event.UserID, event.Amount)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetPendingEvents(limit int) (*[]Event, error) {
+	result := make([]Event, 0)
+
+	query := `SELECT id, type, COALESCE(booking_id, 0) AS booking_id, COALESCE(user_id, 0) AS user_id, amount
+			FROM notification_events
+			WHERE queued_at IS NULL
+			ORDER BY id
+			LIMIT $1`
+
+	err := r.db.Select(&result, query, limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+// QueueEvent mark the event as queued and put its messages to the outbox in a transaction,
+// false is returned when the event is already queued so the messages are never sent twice
+func (r repo) QueueEvent(eventID int, messages []Message) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer tx.Rollback()
+
+	var queuedID int
+	query := "UPDATE notification_events SET queued_at = NOW() WHERE id = $1 AND queued_at IS NULL RETURNING id"
+	err = tx.Get(&queuedID, query, eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if len(messages) > 0 {
+		query = `INSERT INTO notification_outbox (user_id, event, channel, recipient, subject, body, status)
+			VALUES (:user_id, :event, :channel, :recipient, :subject, :body, :status)`
+
+		_, err = tx.NamedExec(query, messages)
+		if err != nil {
+			return false, errors.Wrap(ErrInternalServerError, err.Error())
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return true, nil
+}
+
+func (r repo) GetPendingOutbox(limit int) (*[]Message, error) {
+	result := make([]Message, 0)
+
+	query := `SELECT id, user_id, event, channel, recipient, subject, body, status, attempt, next_attempt_at, last_error
+			FROM notification_outbox
+			WHERE status = $1 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $2`
+
+	err := r.db.Select(&result, query, util.NotificationPending, limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) UpdateOutbox(message Message) error {
+	query := `UPDATE notification_outbox
+			SET status = $1, attempt = $2, next_attempt_at = $3, last_error = $4, updated_at = NOW()
+			WHERE id = $5`
+
+	_, err := r.db.Exec(query, message.Status, message.Attempt, message.NextAttemptAt, message.LastError, message.ID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) InsertInAppNotification(message Message) error {
+	query := "INSERT INTO notifications (user_id, event, title, body) VALUES ($1, $2, $3, $4)"
+
+	_, err := r.db.Exec(query, message.UserID, message.Event, message.Subject, message.Body)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) UpdatePreference(preference Preference) error {
+	query := "UPDATE users SET locale = $1, device_token = $2, updated_at = NOW() WHERE id = $3"
+
+	_, err := r.db.Exec(query, preference.Locale, preference.DeviceToken, preference.UserID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetListWithPagination(params ListRequest) (*List, error) {
+	var list List
+	list.Notifications = make([]Notification, 0)
+
+	query := `SELECT id, event, title, body, read_at, created_at
+			FROM notifications
+			WHERE user_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2 OFFSET $3`
+
+	err := r.db.Select(&list.Notifications, query, params.UserID, params.Limit, (params.Page-1)*params.Limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	query = "SELECT COUNT(id) FROM notifications WHERE user_id = $1"
+	err = r.db.Get(&list.TotalCount, query, params.UserID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &list, nil
+}
+
+func (r repo) CountUnread(userID int) (int, error) {
+	var count int
+
+	query := "SELECT COUNT(id) FROM notifications WHERE user_id = $1 AND read_at IS NULL"
+	err := r.db.Get(&count, query, userID)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+func (r repo) MarkAsRead(userID int, notificationID int) error {
+	query := "UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2"
+
+	result, err := r.db.Exec(query, notificationID, userID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrNotFound, fmt.Sprintf("notification with id %d is not found", notificationID))
+	}
+
+	return nil
+}
+
+func (r repo) MarkAllAsRead(userID int) error {
+	query := "UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL"
+
+	_, err := r.db.Exec(query, userID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
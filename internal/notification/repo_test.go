@@ -0,0 +1,488 @@
+package notification
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+func TestRepo_GetBookingData(t *testing.T) {
+	query := "SELECT b.id, b.user_id AS customer_id, p.user_id AS owner_id, u.name AS customer_name, p.name AS place_name"
+	columns := []string{"id", "customer_id", "owner_id", "customer_name", "place_name", "date", "start_time", "end_time", "total_price"}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).
+			WillReturnRows(mock.NewRows(columns).AddRow(1, 2, 3, "Rafi", "Kopi Kenangan", "2022-05-01", "10:00", "12:00", 1500000.0))
+
+		result, err := repoMock.GetBookingData(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &bookingData, result)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetBookingData(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetBookingData(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetRecipient(t *testing.T) {
+	query := "SELECT id, name, COALESCE(email, '') AS email, COALESCE(phone_number, '') AS phone_number, device_token, locale FROM users WHERE id = $1"
+	columns := []string{"id", "name", "email", "phone_number", "device_token", "locale"}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).
+			WillReturnRows(mock.NewRows(columns).AddRow(2, "Rafi", "rafi@mail.com", "", "", "id"))
+
+		result, err := repoMock.GetRecipient(2)
+		assert.Nil(t, err)
+		assert.Equal(t, &customer, result)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetRecipient(2)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetRecipient(2)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestInsertEvent(t *testing.T) {
+	query := "INSERT INTO notification_events (type, booking_id, user_id, amount) VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4)"
+	event := Event{Type: util.NotificationBookingPaid, BookingID: 1}
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.NotificationBookingPaid, 1, 0, float64(0)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		tx, _ := sqlx.NewDb(mockDB, "sqlmock").Beginx()
+		err = InsertEvent(tx, event)
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		tx, _ := sqlx.NewDb(mockDB, "sqlmock").Beginx()
+		err = InsertEvent(tx, event)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPendingEvents(t *testing.T) {
+	query := "SELECT id, type, COALESCE(booking_id, 0) AS booking_id, COALESCE(user_id, 0) AS user_id, amount FROM notification_events WHERE queued_at IS NULL ORDER BY id LIMIT $1"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(100).
+			WillReturnRows(mock.NewRows([]string{"id", "type", "booking_id", "user_id", "amount"}).
+				AddRow(1, util.NotificationBookingPaid, 2, 0, 0).
+				AddRow(2, util.NotificationDisbursementCompleted, 0, 3, 250000))
+
+		result, err := repoMock.GetPendingEvents(100)
+		assert.Nil(t, err)
+		assert.Equal(t, &[]Event{
+			{ID: 1, Type: util.NotificationBookingPaid, BookingID: 2},
+			{ID: 2, Type: util.NotificationDisbursementCompleted, UserID: 3, Amount: 250000},
+		}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(100).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetPendingEvents(100)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_QueueEvent(t *testing.T) {
+	queueQuery := "UPDATE notification_events SET queued_at = NOW() WHERE id = $1 AND queued_at IS NULL RETURNING id"
+	insertQuery := "INSERT INTO notification_outbox (user_id, event, channel, recipient, subject, body, status)"
+	messages := []Message{
+		{UserID: 2, Event: util.NotificationBookingPaid, Channel: util.NotificationChannelInApp, Recipient: "2", Subject: "subject", Body: "body"},
+		{UserID: 2, Event: util.NotificationBookingPaid, Channel: util.NotificationChannelEmail, Recipient: "rafi@mail.com", Subject: "subject", Body: "body"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(queueQuery)).WithArgs(7).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(regexp.QuoteMeta(insertQuery)).
+			WithArgs(2, util.NotificationBookingPaid, util.NotificationChannelInApp, "2", "subject", "body", util.NotificationPending,
+				2, util.NotificationBookingPaid, util.NotificationChannelEmail, "rafi@mail.com", "subject", "body", util.NotificationPending).
+			WillReturnResult(sqlmock.NewResult(2, 2))
+		mock.ExpectCommit()
+
+		queued, err := repoMock.QueueEvent(7, messages)
+		assert.Nil(t, err)
+		assert.True(t, queued)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success event without message", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(queueQuery)).WithArgs(7).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectCommit()
+
+		queued, err := repoMock.QueueEvent(7, nil)
+		assert.Nil(t, err)
+		assert.True(t, queued)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success already queued", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(queueQuery)).WithArgs(7).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		queued, err := repoMock.QueueEvent(7, messages)
+		assert.Nil(t, err)
+		assert.False(t, queued)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed insert outbox", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(queueQuery)).WithArgs(7).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(regexp.QuoteMeta(insertQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		queued, err := repoMock.QueueEvent(7, messages)
+		assert.False(t, queued)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepo_GetPendingOutbox(t *testing.T) {
+	query := "WHERE status = $1 AND next_attempt_at <= NOW()"
+	columns := []string{"id", "user_id", "event", "channel", "recipient", "subject", "body", "status", "attempt", "next_attempt_at", "last_error"}
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.NotificationPending, 100).
+			WillReturnRows(mock.NewRows(columns).AddRow(1, 2, util.NotificationBookingPaid, util.NotificationChannelEmail, "rafi@mail.com", "subject", "body", 0, 1, now, "smtp error"))
+
+		result, err := repoMock.GetPendingOutbox(100)
+		assert.Nil(t, err)
+		assert.Equal(t, &[]Message{{
+			ID:            1,
+			UserID:        2,
+			Event:         util.NotificationBookingPaid,
+			Channel:       util.NotificationChannelEmail,
+			Recipient:     "rafi@mail.com",
+			Subject:       "subject",
+			Body:          "body",
+			Attempt:       1,
+			NextAttemptAt: now,
+			LastError:     "smtp error",
+		}}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.NotificationPending, 100).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetPendingOutbox(100)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdateOutbox(t *testing.T) {
+	query := "UPDATE notification_outbox SET status = $1, attempt = $2, next_attempt_at = $3, last_error = $4, updated_at = NOW() WHERE id = $5"
+	message := Message{ID: 1, Status: util.NotificationSent, Attempt: 2, NextAttemptAt: time.Now()}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.NotificationSent, 2, message.NextAttemptAt, "", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.UpdateOutbox(message)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.UpdateOutbox(message)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_InsertInAppNotification(t *testing.T) {
+	query := "INSERT INTO notifications (user_id, event, title, body) VALUES ($1, $2, $3, $4)"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, util.NotificationBookingPaid, "Booking #1 is paid", "Hi Rafi").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repoMock.InsertInAppNotification(message)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.InsertInAppNotification(message)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdatePreference(t *testing.T) {
+	query := "UPDATE users SET locale = $1, device_token = $2, updated_at = NOW() WHERE id = $3"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("en", "token", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.UpdatePreference(Preference{UserID: 1, Locale: "en", DeviceToken: "token"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.UpdatePreference(Preference{UserID: 1, Locale: "en"})
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetListWithPagination(t *testing.T) {
+	query := "SELECT id, event, title, body, read_at, created_at FROM notifications WHERE user_id = $1"
+	countQuery := "SELECT COUNT(id) FROM notifications WHERE user_id = $1"
+	params := ListRequest{UserID: 1, Limit: 10, Page: 2}
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 10, 10).
+			WillReturnRows(mock.NewRows([]string{"id", "event", "title", "body", "read_at", "created_at"}).
+				AddRow(2, util.NotificationBookingPaid, "title", "body", nil, now).
+				AddRow(1, util.NotificationBookingCreated, "title", "body", now, now))
+		mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(12))
+
+		result, err := repoMock.GetListWithPagination(params)
+		assert.Nil(t, err)
+		assert.Equal(t, &List{
+			Notifications: []Notification{
+				{ID: 2, Event: util.NotificationBookingPaid, Title: "title", Body: "body", CreatedAt: now},
+				{ID: 1, Event: util.NotificationBookingCreated, Title: "title", Body: "body", ReadAt: &now, CreatedAt: now},
+			},
+			TotalCount: 12,
+		}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 10, 10).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetListWithPagination(params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed count internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1, 10, 10).
+			WillReturnRows(mock.NewRows([]string{"id", "event", "title", "body", "read_at", "created_at"}))
+		mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetListWithPagination(params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_CountUnread(t *testing.T) {
+	query := "SELECT COUNT(id) FROM notifications WHERE user_id = $1 AND read_at IS NULL"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+
+		count, err := repoMock.CountUnread(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		_, err := repoMock.CountUnread(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_MarkAsRead(t *testing.T) {
+	query := "UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.MarkAsRead(1, 5)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repoMock.MarkAsRead(1, 5)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.MarkAsRead(1, 5)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_MarkAllAsRead(t *testing.T) {
+	query := "UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 4))
+
+		err := repoMock.MarkAllAsRead(1)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.MarkAllAsRead(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
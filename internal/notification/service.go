@@ -0,0 +1,275 @@
+package notification
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Notifier is the part of service used by other module to send notification
+type Notifier interface {
+	Notify(event Event)
+}
+
+// Notifiers is used to send the event to every notifier
+type Notifiers []Notifier
+
+// Notify send the event to every notifier in order
+func (n Notifiers) Notify(event Event) {
+	for _, notifier := range n {
+		notifier.Notify(event)
+	}
+}
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	Notify(event Event)
+	ProcessOutbox() (*Report, error)
+	UpdatePreference(preference Preference) error
+	GetListWithPagination(params ListRequest) (*List, *util.Pagination, error)
+	GetUnreadCount(userID int) (*UnreadCount, error)
+	MarkAsRead(userID int, notificationID int) error
+	MarkAllAsRead(userID int) error
+}
+
+type service struct {
+	repo     Repo
+	channels []Channel
+}
+
+// NewService for initialize service, notification is only sent through the given channels
+func NewService(repo Repo, channels ...Channel) Service {
+	return &service{
+		repo:     repo,
+		channels: channels,
+	}
+}
+
+// Notify put the messages of the events recorded by the committed business transaction to the outbox,
+// the job queue them later when this call is failed so failure is only logged and never fail the caller
+func (s *service) Notify(event Event) {
+	_, err := s.queueEvents()
+	if err != nil {
+		logrus.Errorf("[notification] failed to queue %s: %v", event.Type, err)
+	}
+}
+
+// queueEvents build the messages of pending events and put them to the outbox, event that can never be built
+// is dropped while the one failed by internal error is kept for the next run
+func (s *service) queueEvents() (int, error) {
+	events, err := s.repo.GetPendingEvents(util.NotificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var queued int
+	for _, event := range *events {
+		messages, err := s.buildMessages(event)
+		if err != nil {
+			logrus.Errorf("[notification] failed to build %s: %v", event.Type, err)
+			if errors.Cause(err) == ErrInternalServerError {
+				continue
+			}
+
+			messages = nil
+		}
+
+		ok, err := s.repo.QueueEvent(event.ID, messages)
+		if err != nil {
+			return queued, err
+		}
+
+		if ok {
+			queued++
+		}
+	}
+
+	return queued, nil
+}
+
+func (s *service) buildMessages(event Event) ([]Message, error) {
+	audiences, ok := eventAudiences[event.Type]
+	if !ok {
+		return nil, errors.Wrap(ErrInputValidationError, fmt.Sprintf("event %s is unknown", event.Type))
+	}
+
+	data := templateData{Amount: event.Amount}
+	receivers := map[string]int{audienceOwner: event.UserID}
+	if event.BookingID > 0 {
+		booking, err := s.repo.GetBookingData(event.BookingID)
+		if err != nil {
+			return nil, err
+		}
+
+		data.BookingID = booking.ID
+		data.CustomerName = booking.CustomerName
+		data.PlaceName = booking.PlaceName
+		data.Date = booking.Date
+		data.StartTime = booking.StartTime
+		data.EndTime = booking.EndTime
+		if data.Amount == 0 {
+			data.Amount = booking.TotalPrice
+		}
+
+		receivers[audienceCustomer] = booking.CustomerID
+		receivers[audienceOwner] = booking.OwnerID
+	}
+
+	var messages []Message
+	for _, audience := range audiences {
+		if receivers[audience] <= 0 {
+			return nil, errors.Wrap(ErrInputValidationError, fmt.Sprintf("%s of event %s is unknown", audience, event.Type))
+		}
+
+		recipient, err := s.repo.GetRecipient(receivers[audience])
+		if err != nil {
+			return nil, err
+		}
+
+		data.Name = recipient.Name
+		subject, body, err := render(event.Type, audience, recipient.Locale, data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, channel := range s.channels {
+			address := channel.Recipient(*recipient)
+			if address == "" {
+				continue
+			}
+
+			messages = append(messages, Message{
+				UserID:    recipient.UserID,
+				Event:     event.Type,
+				Channel:   channel.Name(),
+				Recipient: address,
+				Subject:   subject,
+				Body:      body,
+				Status:    util.NotificationPending,
+			})
+		}
+	}
+
+	return messages, nil
+}
+
+func (s *service) ProcessOutbox() (*Report, error) {
+	queued, err := s.queueEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.repo.GetPendingOutbox(util.NotificationBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := map[string]Channel{}
+	for _, channel := range s.channels {
+		channels[channel.Name()] = channel
+	}
+
+	report := Report{Queued: queued}
+	for _, message := range *messages {
+		var sendErr error
+		if channel, ok := channels[message.Channel]; ok {
+			sendErr = channel.Send(message)
+		} else {
+			sendErr = errors.Wrap(ErrInternalServerError, fmt.Sprintf("channel %s is not configured", message.Channel))
+		}
+
+		message.Attempt++
+		switch {
+		case sendErr == nil:
+			message.Status = util.NotificationSent
+			message.LastError = ""
+			report.Sent++
+		case message.Attempt >= util.MaximumNotificationAttempt:
+			message.Status = util.NotificationFailed
+			message.LastError = sendErr.Error()
+			report.Failed++
+		default:
+			message.NextAttemptAt = time.Now().Add(retryDelay(message.Attempt))
+			message.LastError = sendErr.Error()
+			report.Retried++
+		}
+
+		err = s.repo.UpdateOutbox(message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
+
+// retryDelay is doubled on every attempt, started from NotificationRetryBaseMinutes
+func retryDelay(attempt int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempt-1))*util.NotificationRetryBaseMinutes) * time.Minute
+}
+
+func (s *service) UpdatePreference(preference Preference) error {
+	if preference.Locale != util.LocaleIndonesian && preference.Locale != util.LocaleEnglish {
+		return errors.Wrap(ErrInputValidationError, "locale must be id or en")
+	}
+
+	return s.repo.UpdatePreference(preference)
+}
+
+func (s *service) GetListWithPagination(params ListRequest) (*List, *util.Pagination, error) {
+	var errorList []string
+
+	if params.Page <= 0 {
+		params.Page = util.DefaultPage
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = util.DefaultLimit
+	}
+
+	if params.Limit > util.MaxLimit {
+		errorList = append(errorList, "limit should be 1 - 100")
+	}
+
+	if params.Path == "" {
+		errorList = append(errorList, "path is required for pagination")
+	}
+
+	if len(errorList) > 0 {
+		return nil, nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	list, err := s.repo.GetListWithPagination(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := util.GeneratePagination(list.TotalCount, params.Limit, params.Page, params.Path)
+	return list, &pagination, nil
+}
+
+func (s *service) GetUnreadCount(userID int) (*UnreadCount, error) {
+	count, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnreadCount{UnreadCount: count}, nil
+}
+
+func (s *service) MarkAsRead(userID int, notificationID int) error {
+	if notificationID <= 0 {
+		return errors.Wrap(ErrInputValidationError, "notificationID must be above 0")
+	}
+
+	return s.repo.MarkAsRead(userID, notificationID)
+}
+
+func (s *service) MarkAllAsRead(userID int) error {
+	return s.repo.MarkAllAsRead(userID)
+}
@@ -0,0 +1,509 @@
+package notification
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetBookingData(bookingID int) (*BookingData, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*BookingData), args.Error(1)
+}
+
+func (m *MockRepository) GetRecipient(userID int) (*Recipient, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*Recipient), args.Error(1)
+}
+
+func (m *MockRepository) GetPendingEvents(limit int) (*[]Event, error) {
+	args := m.Called(limit)
+	return args.Get(0).(*[]Event), args.Error(1)
+}
+
+func (m *MockRepository) QueueEvent(eventID int, messages []Message) (bool, error) {
+	args := m.Called(eventID, messages)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetPendingOutbox(limit int) (*[]Message, error) {
+	args := m.Called(limit)
+	return args.Get(0).(*[]Message), args.Error(1)
+}
+
+func (m *MockRepository) UpdateOutbox(message Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockRepository) InsertInAppNotification(message Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdatePreference(preference Preference) error {
+	args := m.Called(preference)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetListWithPagination(params ListRequest) (*List, error) {
+	args := m.Called(params)
+	return args.Get(0).(*List), args.Error(1)
+}
+
+func (m *MockRepository) CountUnread(userID int) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) MarkAsRead(userID int, notificationID int) error {
+	args := m.Called(userID, notificationID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MarkAllAsRead(userID int) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockChannel struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockChannel) Name() string {
+	return m.name
+}
+
+func (m *MockChannel) Recipient(recipient Recipient) string {
+	if m.name == util.NotificationChannelEmail {
+		return recipient.Email
+	}
+
+	return strconv.Itoa(recipient.UserID)
+}
+
+func (m *MockChannel) Send(message Message) error {
+	args := m.Called(message)
+	return args.Error(0)
+}
+
+var (
+	bookingData = BookingData{
+		ID:           1,
+		CustomerID:   2,
+		OwnerID:      3,
+		CustomerName: "Rafi",
+		PlaceName:    "Kopi Kenangan",
+		Date:         "2022-05-01",
+		StartTime:    "10:00",
+		EndTime:      "12:00",
+		TotalPrice:   1500000,
+	}
+	customer = Recipient{UserID: 2, Name: "Rafi", Email: "rafi@mail.com", Locale: util.LocaleIndonesian}
+	owner    = Recipient{UserID: 3, Name: "Budi", Locale: util.LocaleEnglish}
+)
+
+func TestService_Notify(t *testing.T) {
+	t.Run("success booking event", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		inApp := &MockChannel{name: util.NotificationChannelInApp}
+		email := &MockChannel{name: util.NotificationChannelEmail}
+		mockService := NewService(mockRepo, inApp, email)
+
+		event := Event{ID: 7, Type: util.NotificationBookingCreated, BookingID: 1}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetBookingData", 1).Return(&bookingData, nil)
+		mockRepo.On("GetRecipient", 2).Return(&customer, nil)
+		mockRepo.On("GetRecipient", 3).Return(&owner, nil)
+		mockRepo.On("QueueEvent", 7, mock.Anything).Return(true, nil)
+
+		mockService.Notify(Event{Type: util.NotificationBookingCreated, BookingID: 1})
+
+		messages := mockRepo.Calls[4].Arguments.Get(1).([]Message)
+		assert.Len(t, messages, 3)
+		assert.Equal(t, Message{
+			UserID:    2,
+			Event:     util.NotificationBookingCreated,
+			Channel:   util.NotificationChannelInApp,
+			Recipient: "2",
+			Subject:   "Booking #1 menunggu konfirmasi",
+			Body:      "Halo Rafi, booking kamu di Kopi Kenangan pada 2022-05-01 pukul 10:00 - 12:00 sudah kami terima dan sedang menunggu konfirmasi.",
+			Status:    util.NotificationPending,
+		}, messages[0])
+		assert.Equal(t, util.NotificationChannelEmail, messages[1].Channel)
+		assert.Equal(t, "rafi@mail.com", messages[1].Recipient)
+		assert.Equal(t, 3, messages[2].UserID)
+		assert.Equal(t, "New booking #1", messages[2].Subject)
+	})
+
+	t.Run("success disbursement event", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, &MockChannel{name: util.NotificationChannelInApp})
+
+		event := Event{ID: 7, Type: util.NotificationDisbursementCompleted, UserID: 3, Amount: 250000}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetRecipient", 3).Return(&Recipient{UserID: 3, Name: "Budi", Locale: util.LocaleIndonesian}, nil)
+		mockRepo.On("QueueEvent", 7, []Message{{
+			UserID:    3,
+			Event:     util.NotificationDisbursementCompleted,
+			Channel:   util.NotificationChannelInApp,
+			Recipient: "3",
+			Subject:   "Penarikan dana berhasil",
+			Body:      "Halo Budi, penarikan dana sebesar Rp250.000 sudah berhasil dikirim ke rekening kamu.",
+			Status:    util.NotificationPending,
+		}}).Return(true, nil)
+
+		mockService.Notify(event)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("success event is already queued by other worker", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, &MockChannel{name: util.NotificationChannelInApp})
+
+		event := Event{ID: 7, Type: util.NotificationDisbursementFailed, UserID: 3}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetRecipient", 3).Return(&owner, nil)
+		mockRepo.On("QueueEvent", 7, mock.Anything).Return(false, nil)
+
+		mockService.Notify(event)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("no channel can reach the user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, &MockChannel{name: util.NotificationChannelEmail})
+
+		event := Event{ID: 7, Type: util.NotificationBookingReviewed, BookingID: 1}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetBookingData", 1).Return(&bookingData, nil)
+		mockRepo.On("GetRecipient", 3).Return(&owner, nil)
+		mockRepo.On("QueueEvent", 7, []Message(nil)).Return(true, nil)
+
+		mockService.Notify(event)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed unknown event is dropped", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		event := Event{ID: 7, Type: "unknown", BookingID: 1}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("QueueEvent", 7, []Message(nil)).Return(true, nil)
+
+		mockService.Notify(event)
+
+		mockRepo.AssertNotCalled(t, "GetBookingData", mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed booking not found is dropped", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		event := Event{ID: 7, Type: util.NotificationBookingPaid, BookingID: 1}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetBookingData", 1).Return(&BookingData{}, errors.Wrap(ErrNotFound, "test error"))
+		mockRepo.On("QueueEvent", 7, []Message(nil)).Return(true, nil)
+
+		mockService.Notify(event)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("failed internal server error is kept for the next run", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		event := Event{ID: 7, Type: util.NotificationBookingPaid, BookingID: 1}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetBookingData", 1).Return(&BookingData{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		mockService.Notify(event)
+
+		mockRepo.AssertNotCalled(t, "QueueEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed get pending events", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		mockService.Notify(Event{Type: util.NotificationBookingPaid, BookingID: 1})
+
+		mockRepo.AssertNotCalled(t, "QueueEvent", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_ProcessOutbox(t *testing.T) {
+	message := Message{ID: 1, UserID: 2, Channel: util.NotificationChannelEmail, Recipient: "rafi@mail.com", Status: util.NotificationPending}
+
+	t.Run("success sent", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		email := &MockChannel{name: util.NotificationChannelEmail}
+		mockService := NewService(mockRepo, email)
+
+		sent := message
+		sent.Attempt = 1
+		sent.Status = util.NotificationSent
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, nil)
+		mockRepo.On("GetPendingOutbox", util.NotificationBatchSize).Return(&[]Message{message}, nil)
+		email.On("Send", message).Return(nil)
+		mockRepo.On("UpdateOutbox", sent).Return(nil)
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, err)
+		assert.Equal(t, &Report{Sent: 1}, report)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("success retried", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		email := &MockChannel{name: util.NotificationChannelEmail}
+		mockService := NewService(mockRepo, email)
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, nil)
+		mockRepo.On("GetPendingOutbox", util.NotificationBatchSize).Return(&[]Message{message}, nil)
+		email.On("Send", message).Return(errors.Wrap(ErrInternalServerError, "smtp error"))
+		mockRepo.On("UpdateOutbox", mock.Anything).Return(nil)
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, err)
+		assert.Equal(t, &Report{Retried: 1}, report)
+
+		updated := mockRepo.Calls[2].Arguments.Get(0).(Message)
+		assert.Equal(t, util.NotificationPending, updated.Status)
+		assert.Equal(t, 1, updated.Attempt)
+		assert.Equal(t, "smtp error: internal server error", updated.LastError)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), updated.NextAttemptAt, time.Second)
+	})
+
+	t.Run("success failed after maximum attempt", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		lastAttempt := message
+		lastAttempt.Attempt = util.MaximumNotificationAttempt - 1
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, nil)
+		mockRepo.On("GetPendingOutbox", util.NotificationBatchSize).Return(&[]Message{lastAttempt}, nil)
+		mockRepo.On("UpdateOutbox", mock.Anything).Return(nil)
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, err)
+		assert.Equal(t, &Report{Failed: 1}, report)
+
+		updated := mockRepo.Calls[2].Arguments.Get(0).(Message)
+		assert.Equal(t, util.NotificationFailed, updated.Status)
+		assert.Contains(t, updated.LastError, "channel email is not configured")
+	})
+
+	t.Run("success queue pending events", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, &MockChannel{name: util.NotificationChannelInApp})
+
+		event := Event{ID: 7, Type: util.NotificationDisbursementFailed, UserID: 3}
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{event}, nil)
+		mockRepo.On("GetRecipient", 3).Return(&owner, nil)
+		mockRepo.On("QueueEvent", 7, mock.Anything).Return(true, nil)
+		mockRepo.On("GetPendingOutbox", util.NotificationBatchSize).Return(&[]Message{}, nil)
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, err)
+		assert.Equal(t, &Report{Queued: 1}, report)
+	})
+
+	t.Run("failed get pending events", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, report)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "GetPendingOutbox", mock.Anything)
+	})
+
+	t.Run("failed get pending outbox", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, nil)
+		mockRepo.On("GetPendingOutbox", util.NotificationBatchSize).Return(&[]Message{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, report)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed update outbox", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		email := &MockChannel{name: util.NotificationChannelEmail}
+		mockService := NewService(mockRepo, email)
+
+		mockRepo.On("GetPendingEvents", util.NotificationBatchSize).Return(&[]Event{}, nil)
+		mockRepo.On("GetPendingOutbox", util.NotificationBatchSize).Return(&[]Message{message}, nil)
+		email.On("Send", message).Return(nil)
+		mockRepo.On("UpdateOutbox", mock.Anything).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		report, err := mockService.ProcessOutbox()
+		assert.Nil(t, report)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Minute, retryDelay(1))
+	assert.Equal(t, 2*time.Minute, retryDelay(2))
+	assert.Equal(t, 8*time.Minute, retryDelay(4))
+}
+
+func TestService_UpdatePreference(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+		preference := Preference{UserID: 1, Locale: util.LocaleEnglish, DeviceToken: "token"}
+
+		mockRepo.On("UpdatePreference", preference).Return(nil)
+
+		err := mockService.UpdatePreference(preference)
+		assert.Nil(t, err)
+	})
+
+	t.Run("failed invalid locale", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		err := mockService.UpdatePreference(Preference{UserID: 1, Locale: "fr"})
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetListWithPagination(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+		params := ListRequest{UserID: 1, Path: "/api/v1/notifications"}
+		list := List{Notifications: []Notification{{ID: 1}}, TotalCount: 11}
+
+		mockRepo.On("GetListWithPagination", ListRequest{UserID: 1, Path: "/api/v1/notifications", Limit: util.DefaultLimit, Page: util.DefaultPage}).Return(&list, nil)
+
+		result, pagination, err := mockService.GetListWithPagination(params)
+		assert.Nil(t, err)
+		assert.Equal(t, &list, result)
+		assert.Equal(t, 2, pagination.TotalPage)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		result, pagination, err := mockService.GetListWithPagination(ListRequest{UserID: 1, Limit: 101})
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "limit should be 1 - 100,path is required for pagination")
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetListWithPagination", mock.Anything).Return(&List{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		result, pagination, err := mockService.GetListWithPagination(ListRequest{UserID: 1, Path: "/api/v1/notifications"})
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetUnreadCount(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("CountUnread", 1).Return(3, nil)
+
+		result, err := mockService.GetUnreadCount(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &UnreadCount{UnreadCount: 3}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("CountUnread", 1).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		result, err := mockService.GetUnreadCount(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_MarkAsRead(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("MarkAsRead", 1, 5).Return(nil)
+
+		assert.Nil(t, mockService.MarkAsRead(1, 5))
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		err := mockService.MarkAsRead(1, 0)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("MarkAsRead", 1, 5).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		err := mockService.MarkAsRead(1, 5)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestService_MarkAllAsRead(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockService := NewService(mockRepo)
+
+	mockRepo.On("MarkAllAsRead", 1).Return(nil)
+
+	assert.Nil(t, mockService.MarkAllAsRead(1))
+}
+
+func TestNotifiers_Notify(t *testing.T) {
+	first := new(MockService)
+	second := new(MockService)
+	event := Event{Type: util.NotificationBookingPaid, BookingID: 1}
+
+	first.On("Notify", event)
+	second.On("Notify", event)
+
+	Notifiers{first, second}.Notify(event)
+
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
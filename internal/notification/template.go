@@ -0,0 +1,234 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+const (
+	audienceCustomer = "customer"
+	audienceOwner    = "owner"
+)
+
+// eventAudiences is the receiver of every event
+var eventAudiences = map[string][]string{
+	util.NotificationBookingCreated:        {audienceCustomer, audienceOwner},
+	util.NotificationBookingConfirmed:      {audienceCustomer},
+	util.NotificationBookingPaid:           {audienceCustomer, audienceOwner},
+	util.NotificationBookingExpired:        {audienceCustomer},
+	util.NotificationBookingCancelled:      {audienceCustomer},
+	util.NotificationBookingReviewed:       {audienceOwner},
+	util.NotificationDisbursementCompleted: {audienceOwner},
+	util.NotificationDisbursementFailed:    {audienceOwner},
+}
+
+// templateData is the value that can be used in the message template
+type templateData struct {
+	Name         string
+	BookingID    int
+	CustomerName string
+	PlaceName    string
+	Date         string
+	StartTime    string
+	EndTime      string
+	Amount       float64
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// templateSources is keyed by event, audience and then locale, the first text is the subject and the second is the body
+var templateSources = map[string]map[string]map[string][2]string{
+	util.NotificationBookingCreated: {
+		audienceCustomer: {
+			util.LocaleIndonesian: {
+				"Booking #{{.BookingID}} menunggu konfirmasi",
+				"Halo {{.Name}}, booking kamu di {{.PlaceName}} pada {{.Date}} pukul {{.StartTime}} - {{.EndTime}} sudah kami terima dan sedang menunggu konfirmasi.",
+			},
+			util.LocaleEnglish: {
+				"Booking #{{.BookingID}} is waiting for confirmation",
+				"Hi {{.Name}}, your booking at {{.PlaceName}} on {{.Date}} at {{.StartTime}} - {{.EndTime}} has been received and is waiting for confirmation.",
+			},
+		},
+		audienceOwner: {
+			util.LocaleIndonesian: {
+				"Booking baru #{{.BookingID}}",
+				"Halo {{.Name}}, {{.CustomerName}} membuat booking di {{.PlaceName}} pada {{.Date}} pukul {{.StartTime}} - {{.EndTime}}. Silakan konfirmasi booking tersebut.",
+			},
+			util.LocaleEnglish: {
+				"New booking #{{.BookingID}}",
+				"Hi {{.Name}}, {{.CustomerName}} made a booking at {{.PlaceName}} on {{.Date}} at {{.StartTime}} - {{.EndTime}}. Please confirm the booking.",
+			},
+		},
+	},
+	util.NotificationBookingConfirmed: {
+		audienceCustomer: {
+			util.LocaleIndonesian: {
+				"Booking #{{.BookingID}} dikonfirmasi",
+				"Halo {{.Name}}, booking kamu di {{.PlaceName}} pada {{.Date}} sudah dikonfirmasi. Silakan lakukan pembayaran melalui aplikasi.",
+			},
+			util.LocaleEnglish: {
+				"Booking #{{.BookingID}} is confirmed",
+				"Hi {{.Name}}, your booking at {{.PlaceName}} on {{.Date}} is confirmed. Please complete the payment in the application.",
+			},
+		},
+	},
+	util.NotificationBookingPaid: {
+		audienceCustomer: {
+			util.LocaleIndonesian: {
+				"Pembayaran booking #{{.BookingID}} berhasil",
+				"Halo {{.Name}}, pembayaran booking kamu di {{.PlaceName}} pada {{.Date}} pukul {{.StartTime}} - {{.EndTime}} berhasil. E-ticket sudah dapat dilihat di aplikasi.",
+			},
+			util.LocaleEnglish: {
+				"Booking #{{.BookingID}} is paid",
+				"Hi {{.Name}}, the payment of your booking at {{.PlaceName}} on {{.Date}} at {{.StartTime}} - {{.EndTime}} is successful. The e-ticket is available in the application.",
+			},
+		},
+		audienceOwner: {
+			util.LocaleIndonesian: {
+				"Booking #{{.BookingID}} sudah dibayar",
+				"Halo {{.Name}}, booking {{.CustomerName}} pada {{.Date}} pukul {{.StartTime}} - {{.EndTime}} sudah dibayar sebesar {{rupiah .Amount}}.",
+			},
+			util.LocaleEnglish: {
+				"Booking #{{.BookingID}} is paid",
+				"Hi {{.Name}}, the booking of {{.CustomerName}} on {{.Date}} at {{.StartTime}} - {{.EndTime}} is paid for {{rupiah .Amount}}.",
+			},
+		},
+	},
+	util.NotificationBookingExpired: {
+		audienceCustomer: {
+			util.LocaleIndonesian: {
+				"Booking #{{.BookingID}} kedaluwarsa",
+				"Halo {{.Name}}, booking kamu di {{.PlaceName}} pada {{.Date}} sudah kedaluwarsa karena tidak dikonfirmasi atau dibayar tepat waktu.",
+			},
+			util.LocaleEnglish: {
+				"Booking #{{.BookingID}} is expired",
+				"Hi {{.Name}}, your booking at {{.PlaceName}} on {{.Date}} is expired because it was not confirmed or paid in time.",
+			},
+		},
+	},
+	util.NotificationBookingCancelled: {
+		audienceCustomer: {
+			util.LocaleIndonesian: {
+				"Booking #{{.BookingID}} dibatalkan",
+				"Halo {{.Name}}, mohon maaf booking kamu di {{.PlaceName}} pada {{.Date}} pukul {{.StartTime}} - {{.EndTime}} dibatalkan oleh pemilik tempat.",
+			},
+			util.LocaleEnglish: {
+				"Booking #{{.BookingID}} is cancelled",
+				"Hi {{.Name}}, we are sorry that your booking at {{.PlaceName}} on {{.Date}} at {{.StartTime}} - {{.EndTime}} is cancelled by the place owner.",
+			},
+		},
+	},
+	util.NotificationBookingReviewed: {
+		audienceOwner: {
+			util.LocaleIndonesian: {
+				"Ulasan baru untuk booking #{{.BookingID}}",
+				"Halo {{.Name}}, {{.CustomerName}} memberikan ulasan untuk booking di {{.PlaceName}} pada {{.Date}}.",
+			},
+			util.LocaleEnglish: {
+				"New review for booking #{{.BookingID}}",
+				"Hi {{.Name}}, {{.CustomerName}} left a review for the booking at {{.PlaceName}} on {{.Date}}.",
+			},
+		},
+	},
+	util.NotificationDisbursementCompleted: {
+		audienceOwner: {
+			util.LocaleIndonesian: {
+				"Penarikan dana berhasil",
+				"Halo {{.Name}}, penarikan dana sebesar {{rupiah .Amount}} sudah berhasil dikirim ke rekening kamu.",
+			},
+			util.LocaleEnglish: {
+				"Disbursement completed",
+				"Hi {{.Name}}, the disbursement of {{rupiah .Amount}} has been sent to your bank account.",
+			},
+		},
+	},
+	util.NotificationDisbursementFailed: {
+		audienceOwner: {
+			util.LocaleIndonesian: {
+				"Penarikan dana gagal",
+				"Halo {{.Name}}, penarikan dana sebesar {{rupiah .Amount}} gagal. Silakan periksa detail rekening kamu dan coba lagi.",
+			},
+			util.LocaleEnglish: {
+				"Disbursement failed",
+				"Hi {{.Name}}, the disbursement of {{rupiah .Amount}} failed. Please check your bank account details and try again.",
+			},
+		},
+	},
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[string]map[string]map[string]messageTemplate {
+	funcs := template.FuncMap{"rupiah": formatRupiah}
+
+	result := map[string]map[string]map[string]messageTemplate{}
+	for event, audiences := range templateSources {
+		result[event] = map[string]map[string]messageTemplate{}
+		for audience, locales := range audiences {
+			result[event][audience] = map[string]messageTemplate{}
+			for locale, source := range locales {
+				name := fmt.Sprintf("%s.%s.%s", event, audience, locale)
+				result[event][audience][locale] = messageTemplate{
+					subject: template.Must(template.New(name + ".subject").Funcs(funcs).Parse(source[0])),
+					body:    template.Must(template.New(name + ".body").Funcs(funcs).Parse(source[1])),
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// render the subject and body of event for the audience, unknown locale fallback to indonesian
+func render(event, audience, locale string, data templateData) (string, string, error) {
+	messageTemplates, ok := templates[event][audience]
+	if !ok {
+		return "", "", errors.Wrap(ErrInputValidationError, fmt.Sprintf("there are no template for %s %s", event, audience))
+	}
+
+	messageTemplate, ok := messageTemplates[locale]
+	if !ok {
+		messageTemplate = messageTemplates[util.LocaleIndonesian]
+	}
+
+	var subject, body bytes.Buffer
+	if err := messageTemplate.subject.Execute(&subject, data); err != nil {
+		return "", "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if err := messageTemplate.body.Execute(&body, data); err != nil {
+		return "", "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return subject.String(), body.String(), nil
+}
+
+// formatRupiah format amount with dot as thousand separator, e.g. Rp1.500.000
+func formatRupiah(amount float64) string {
+	digits := strconv.FormatInt(int64(math.Round(amount)), 10)
+
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var result strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			result.WriteRune('.')
+		}
+		result.WriteRune(digit)
+	}
+
+	return sign + "Rp" + result.String()
+}
@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func TestRender(t *testing.T) {
+	data := templateData{Name: "Budi", BookingID: 1, CustomerName: "Rafi", Date: "2022-05-01", StartTime: "10:00", EndTime: "12:00", Amount: 1500000}
+
+	t.Run("success", func(t *testing.T) {
+		subject, body, err := render(util.NotificationBookingPaid, audienceOwner, util.LocaleEnglish, data)
+		assert.Nil(t, err)
+		assert.Equal(t, "Booking #1 is paid", subject)
+		assert.Equal(t, "Hi Budi, the booking of Rafi on 2022-05-01 at 10:00 - 12:00 is paid for Rp1.500.000.", body)
+	})
+
+	t.Run("success fallback to indonesian", func(t *testing.T) {
+		subject, _, err := render(util.NotificationBookingPaid, audienceOwner, "", data)
+		assert.Nil(t, err)
+		assert.Equal(t, "Booking #1 sudah dibayar", subject)
+	})
+
+	t.Run("failed no template", func(t *testing.T) {
+		_, _, err := render(util.NotificationBookingReviewed, audienceCustomer, util.LocaleIndonesian, data)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	for event, audiences := range eventAudiences {
+		for _, audience := range audiences {
+			for _, locale := range []string{util.LocaleIndonesian, util.LocaleEnglish} {
+				_, ok := templates[event][audience][locale]
+				assert.True(t, ok, "%s %s %s", event, audience, locale)
+			}
+		}
+	}
+}
+
+func TestFormatRupiah(t *testing.T) {
+	assert.Equal(t, "Rp0", formatRupiah(0))
+	assert.Equal(t, "Rp500", formatRupiah(500))
+	assert.Equal(t, "Rp1.000", formatRupiah(1000))
+	assert.Equal(t, "Rp1.500.000", formatRupiah(1499999.6))
+	assert.Equal(t, "-Rp25.000", formatRupiah(-25000))
+}
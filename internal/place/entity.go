@@ -1,32 +1,45 @@
 package place
 
-import "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+import (
+	"github.com/lib/pq"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
 
 // Detail contain important information in Place
 type Detail struct {
-	ID                 int          `json:"id"`
-	Name               string       `json:"name"`
-	Image              string       `json:"image"`
-	Address            string       `json:"address"`
-	Description        string       `json:"description"`
-	OpenHour           string       `json:"open_hour" db:"open_hour"`
-	CloseHour          string       `json:"close_hour" db:"close_hour"`
-	BookingPrice       int          `json:"booking_price" db:"booking_price"`
-	MinSlot            int          `json:"min_slot" db:"min_slot_booking"`
-	MaxSlot            int          `json:"max_slot" db:"max_slot_booking"`
-	Capacity           int          `json:"capacity" db:"capacity"`
-	MinIntervalBooking int          `json:"min_interval_booking" db:"min_interval_booking"`
-	MaxIntervalBooking int          `json:"max_interval_booking" db:"max_interval_booking"`
-	AverageRating      float64      `json:"average_rating" db:"rating"`
-	ReviewCount        int          `json:"review_count"`
-	Reviews            []UserReview `json:"reviews"`
+	ID                 int           `json:"id"`
+	Name               string        `json:"name"`
+	Image              string        `json:"image"`
+	Address            string        `json:"address"`
+	Description        string        `json:"description"`
+	OpenHour           string        `json:"open_hour" db:"open_hour"`
+	CloseHour          string        `json:"close_hour" db:"close_hour"`
+	BookingPrice       int           `json:"booking_price" db:"booking_price"`
+	MinSlot            int           `json:"min_slot" db:"min_slot_booking"`
+	MaxSlot            int           `json:"max_slot" db:"max_slot_booking"`
+	Capacity           int           `json:"capacity" db:"capacity"`
+	MinIntervalBooking int           `json:"min_interval_booking" db:"min_interval_booking"`
+	MaxIntervalBooking int           `json:"max_interval_booking" db:"max_interval_booking"`
+	AverageRating      float64       `json:"average_rating" db:"rating"`
+	AspectRatings      AspectRatings `json:"aspect_ratings"`
+	ReviewCount        int           `json:"review_count"`
+	Reviews            []UserReview  `json:"reviews"`
+	IsFavorite         bool          `json:"is_favorite"`
 }
 
 // AverageRatingAndReviews contain 2 reviews, average rating, and review count of place
 type AverageRatingAndReviews struct {
-	AverageRating float64      `json:"average_rating"`
-	ReviewCount   int          `json:"review_count" db:"count_review"`
-	Reviews       []UserReview `json:"reviews"`
+	AverageRating float64       `json:"average_rating"`
+	AspectRatings AspectRatings `json:"aspect_ratings"`
+	ReviewCount   int           `json:"review_count" db:"count_review"`
+	Reviews       []UserReview  `json:"reviews"`
+}
+
+// AspectRatings contain average rating of each aspect, review without the aspect rating is not counted
+type AspectRatings struct {
+	Cleanliness float64 `json:"cleanliness"`
+	Service     float64 `json:"service"`
+	Value       float64 `json:"value"`
 }
 
 // UserReview will wrap Review of each user and another information
@@ -34,6 +47,7 @@ type UserReview struct {
 	User    string  `json:"user"`
 	Rating  float64 `json:"rating"`
 	Content string  `json:"content"`
+	Reply   *string `json:"reply"`
 }
 
 // PlacesList will wrap the Places with total count for pagination purposes
@@ -52,6 +66,7 @@ type Place struct {
 	Rating      float64 `json:"rating"`
 	ReviewCount int     `json:"review_count" db:"review_count"`
 	Image       string  `json:"image"`
+	IsFavorite  bool    `json:"is_favorite" db:"is_favorite"`
 }
 
 // PlacesListRequest will wrap request data from client
@@ -74,6 +89,17 @@ type PlacesListRequest struct {
 
 	// Category params
 	Category string `query:"category"`
+
+	// UserID of the caller, is_favorite is only filled when it is above 0
+	UserID int `json:"-"`
+}
+
+// FavoriteListRequest will wrap request data of favorite places list
+type FavoriteListRequest struct {
+	Limit  int    `json:"limit"`
+	Page   int    `json:"page"`
+	Path   string `json:"path"`
+	UserID int    `json:"user_id"`
 }
 
 // PlacesListResponse will wrap response data to client
@@ -90,11 +116,17 @@ type PlacesRatingAndReviewCount struct {
 
 // Review consist of informations about review and rating from customer
 type Review struct {
-	ID      int    `json:"id"`
-	Name    string `json:"name"`
-	Content string `json:"content"`
-	Rating  int    `json:"rating"`
-	Date    string `json:"created_at" db:"created_at"`
+	ID                int            `json:"id"`
+	Name              string         `json:"name"`
+	Content           string         `json:"content"`
+	Rating            int            `json:"rating"`
+	CleanlinessRating *int           `json:"cleanliness_rating" db:"cleanliness_rating"`
+	ServiceRating     *int           `json:"service_rating" db:"service_rating"`
+	ValueRating       *int           `json:"value_rating" db:"value_rating"`
+	Photos            pq.StringArray `json:"photos"`
+	Date              string         `json:"created_at" db:"created_at"`
+	Reply             *string        `json:"reply"`
+	RepliedAt         *string        `json:"replied_at" db:"replied_at"`
 }
 
 // ListReview is a container for review
@@ -105,10 +137,23 @@ type ListReview struct {
 
 // ListReviewRequest consist of request for pagination and sorting purpose
 type ListReviewRequest struct {
-	Limit   int    `json:"limit"`
-	Page    int    `json:"page"`
-	Path    string `json:"path"`
-	PlaceID int    `json:"place_id"`
-	Rating  bool   `json:"rating"`
-	Latest  bool   `json:"latest"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page"`
+	Path       string `json:"path"`
+	PlaceID    int    `json:"place_id"`
+	Rating     bool   `json:"rating"`
+	Latest     bool   `json:"latest"`
+	WithPhotos bool   `json:"with_photos"`
+}
+
+// Stats is the denormalized aggregate of place, maintained on review and booking changes
+type Stats struct {
+	ReviewCount      int `db:"review_count"`
+	RatingSum        int `db:"rating_sum"`
+	CleanlinessSum   int `db:"cleanliness_sum"`
+	CleanlinessCount int `db:"cleanliness_count"`
+	ServiceSum       int `db:"service_sum"`
+	ServiceCount     int `db:"service_count"`
+	ValueSum         int `db:"value_sum"`
+	ValueCount       int `db:"value_count"`
 }
@@ -11,4 +11,7 @@ var (
 
 	// ErrInputValidationError is used if there is input validation error of client given data
 	ErrInputValidationError = errors.New("input validation error")
+
+	// ErrNotFound is used if the place or favorite is not found
+	ErrNotFound = errors.New("not found")
 )
@@ -7,6 +7,8 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
@@ -36,7 +38,7 @@ func (h *Handler) GetDetail(c echo.Context) error {
 		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
 	}
 
-	placeDetail, err := h.service.GetDetail(placeID)
+	placeDetail, err := h.service.GetDetail(placeID, optionalUserID(c))
 	if err != nil {
 		if errors.Cause(err) == ErrInputValidationError {
 			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
@@ -56,7 +58,8 @@ func (h *Handler) GetDetail(c echo.Context) error {
 func (h *Handler) GetPlacesListWithPagination(c echo.Context) error {
 	var errorList []string
 	params := PlacesListRequest{
-		Path: "/api/v1/place",
+		Path:   "/api/v1/place",
+		UserID: optionalUserID(c),
 	}
 
 	errs := echo.QueryParamsBinder(c).FailFast(false).
@@ -121,6 +124,7 @@ func (h *Handler) GetListReviewAndRatingWithPagination(c echo.Context) error {
 	pageString := c.QueryParam("page")
 	latestString := c.QueryParam("latest")
 	ratingString := c.QueryParam("rating")
+	withPhotosString := c.QueryParam("with_photos")
 
 	placeID, err := strconv.Atoi(placeIDString)
 	if err != nil {
@@ -145,6 +149,15 @@ func (h *Handler) GetListReviewAndRatingWithPagination(c echo.Context) error {
 		}
 	}
 
+	withPhotos, err := strconv.ParseBool(withPhotosString)
+	if err != nil {
+		if withPhotosString == "" {
+			withPhotos = false
+		} else {
+			errorList = append(errorList, "with_photos parameter should be boolean type")
+		}
+	}
+
 	page, limit, errorsFromValidator := util.ValidateParams(pageString, limitString)
 	errorList = append(errorList, errorsFromValidator...)
 
@@ -158,6 +171,7 @@ func (h *Handler) GetListReviewAndRatingWithPagination(c echo.Context) error {
 	params.Page = page
 	params.Latest = latest
 	params.Rating = rating
+	params.WithPhotos = withPhotos
 	params.PlaceID = placeID
 
 	listReview, pagination, err := h.service.GetListReviewAndRatingWithPagination(params)
@@ -179,3 +193,119 @@ func (h *Handler) GetListReviewAndRatingWithPagination(c echo.Context) error {
 		},
 	})
 }
+
+// AddFavorite will save the place to favorites of the customer
+func (h *Handler) AddFavorite(c echo.Context) error {
+	userModel, err := parseCustomer(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	placeID, err := strconv.Atoi(c.Param("placeID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "placeID must be number")
+	}
+
+	err = h.service.AddFavorite(userModel.ID, placeID)
+	if err != nil {
+		return favoriteErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// RemoveFavorite will remove the place from favorites of the customer
+func (h *Handler) RemoveFavorite(c echo.Context) error {
+	userModel, err := parseCustomer(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	placeID, err := strconv.Atoi(c.Param("placeID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "placeID must be number")
+	}
+
+	err = h.service.RemoveFavorite(userModel.ID, placeID)
+	if err != nil {
+		return favoriteErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// GetFavoritesWithPagination will retrieve the favorite places of the customer
+func (h *Handler) GetFavoritesWithPagination(c echo.Context) error {
+	userModel, err := parseCustomer(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	page, limit, errorList := util.ValidateParams(c.QueryParam("page"), c.QueryParam("limit"))
+	if len(errorList) > 0 {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
+	}
+
+	params := FavoriteListRequest{
+		Limit:  limit,
+		Page:   page,
+		Path:   "/api/v1/user/favorites",
+		UserID: userModel.ID,
+	}
+
+	favorites, pagination, err := h.service.GetFavoritesWithPagination(params)
+	if err != nil {
+		return favoriteErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data: map[string]interface{}{
+			"places":      favorites.Places,
+			"total_count": favorites.TotalCount,
+			"pagination":  pagination,
+		},
+	})
+}
+
+func favoriteErrorResponse(c echo.Context, err error) error {
+	switch errors.Cause(err) {
+	case ErrInputValidationError:
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+	case ErrNotFound:
+		return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+	}
+
+	return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+}
+
+// parseCustomer return the registered customer, favorites is only available for customer
+func parseCustomer(c echo.Context) (*user.Model, error) {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusCustomer)
+	if err != nil {
+		return nil, err
+	}
+
+	if userModel == nil {
+		return nil, errors.Wrap(middleware.ErrForbidden, "user is not registered")
+	}
+
+	return userModel, nil
+}
+
+// optionalUserID return the id of the registered customer, anonymous caller is 0
+func optionalUserID(c echo.Context) int {
+	userModel, err := parseCustomer(c)
+	if err != nil {
+		return 0
+	}
+
+	return userModel.ID
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
@@ -27,8 +29,8 @@ func (m *MockService) GetPlaceListWithPagination(params PlacesListRequest) (*Pla
 	return args.Get(0).(*PlacesList), args.Get(1).(*util.Pagination), args.Error(2)
 }
 
-func (m *MockService) GetDetail(placeID int) (*Detail, error) {
-	args := m.Called(placeID)
+func (m *MockService) GetDetail(placeID int, userID int) (*Detail, error) {
+	args := m.Called(placeID, userID)
 	placeDetail := args.Get(0).(*Detail)
 	return placeDetail, args.Error(1)
 }
@@ -40,6 +42,25 @@ func (m *MockService) GetListReviewAndRatingWithPagination(params ListReviewRequ
 	return listReview, &pagination, args.Error(2)
 }
 
+func (m *MockService) BackfillStats() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockService) AddFavorite(userID int, placeID int) error {
+	return m.Called(userID, placeID).Error(0)
+}
+
+func (m *MockService) RemoveFavorite(userID int, placeID int) error {
+	return m.Called(userID, placeID).Error(0)
+}
+
+func (m *MockService) GetFavoritesWithPagination(params FavoriteListRequest) (*PlacesList, *util.Pagination, error) {
+	args := m.Called(params)
+	pagination := args.Get(1).(util.Pagination)
+	return args.Get(0).(*PlacesList), &pagination, args.Error(2)
+}
+
 func TestHandler_GetDetailSuccess(t *testing.T) {
 	// Setting up echo router
 	e := echo.New()
@@ -93,7 +114,7 @@ func TestHandler_GetDetailSuccess(t *testing.T) {
 	expectedResponseJSON, _ := json.Marshal(expectedResponse)
 
 	// Excpectation
-	mockService.On("GetDetail", placeID).Return(&placeDetail, nil)
+	mockService.On("GetDetail", placeID, 0).Return(&placeDetail, nil)
 
 	// Test Fields
 	if assert.NoError(t, h.GetDetail(c)) {
@@ -161,7 +182,7 @@ func TestService_GetPlaceListWithPlaceIdBelowOne(t *testing.T) {
 
 	// Excpectation
 	var placeDetail Detail
-	mockService.On("GetDetail", placeID).Return(&placeDetail, errorFromService)
+	mockService.On("GetDetail", placeID, 0).Return(&placeDetail, errorFromService)
 
 	response := h.GetDetail(c)
 	util.ErrorHandler(response, c)
@@ -198,7 +219,7 @@ func TestService_GetPlaceListWithInternalServerError(t *testing.T) {
 
 	// Excpectation
 	var placeDetail Detail
-	mockService.On("GetDetail", placeID).Return(&placeDetail, errorFromService)
+	mockService.On("GetDetail", placeID, 0).Return(&placeDetail, errorFromService)
 
 	response := h.GetDetail(c)
 	util.ErrorHandler(response, c)
@@ -764,3 +785,269 @@ func TestHandler_GetListReviewAndRatingWithPaginationQueryParamEmpty(t *testing.
 		assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
 	}
 }
+
+func TestHandler_GetListReviewAndRatingWithPaginationWithPhotos(t *testing.T) {
+	e := echo.New()
+
+	q := make(url.Values)
+	q.Set("with_photos", "true")
+	req := httptest.NewRequest(http.MethodGet, "/review?"+q.Encode(), nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/:placeID/review")
+	ctx.SetParamNames("placeID")
+	ctx.SetParamValues("1")
+
+	mockService := new(MockService)
+	h := NewHandler(mockService)
+
+	params := ListReviewRequest{
+		Limit:      util.DefaultLimit,
+		Page:       util.DefaultPage,
+		Path:       "/api/v1/place/1/review",
+		PlaceID:    1,
+		Latest:     true,
+		WithPhotos: true,
+	}
+
+	listReview := ListReview{
+		Reviews: []Review{
+			{ID: 1, Name: "test 1", Content: "test 1", Rating: 5, Photos: []string{"https://cdn/1.png"}, Date: "test 1"},
+		},
+		TotalCount: 1,
+	}
+
+	mockService.On("GetListReviewAndRatingWithPagination", params).Return(&listReview, util.Pagination{}, nil)
+
+	if assert.NoError(t, h.GetListReviewAndRatingWithPagination(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"photos":["https://cdn/1.png"]`)
+	}
+}
+
+func TestHandler_GetListReviewAndRatingWithPaginationWithPhotosError(t *testing.T) {
+	e := echo.New()
+
+	q := make(url.Values)
+	q.Set("with_photos", "asd")
+	req := httptest.NewRequest(http.MethodGet, "/review?"+q.Encode(), nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/:placeID/review")
+	ctx.SetParamNames("placeID")
+	ctx.SetParamValues("1")
+
+	h := NewHandler(new(MockService))
+
+	err := h.GetListReviewAndRatingWithPagination(ctx)
+	util.ErrorHandler(err, ctx)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "with_photos parameter should be boolean type")
+}
+
+func newUserContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string, userModel *user.Model) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	ctx := e.NewContext(req, rec)
+	if userModel != nil {
+		ctx.Set("userFromDatabase", userModel)
+	}
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_GetDetailWithFavorite(t *testing.T) {
+	e := echo.New()
+	customer := user.Model{ID: 2, Status: util.StatusCustomer}
+
+	t.Run("customer", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodGet, "/", nil), rec, "phone", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		mockService.On("GetDetail", 1, 2).Return(&Detail{ID: 1, IsFavorite: true}, nil)
+
+		assert.NoError(t, h.GetDetail(c))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"is_favorite":true`)
+	})
+
+	t.Run("business admin is treated as anonymous", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodGet, "/", nil), rec, "password", &user.Model{ID: 3})
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		mockService.On("GetDetail", 1, 0).Return(&Detail{ID: 1}, nil)
+
+		assert.NoError(t, h.GetDetail(c))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestHandler_AddFavorite(t *testing.T) {
+	e := echo.New()
+	customer := user.Model{ID: 2, Status: util.StatusCustomer}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodPost, "/", nil), rec, "phone", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		mockService.On("AddFavorite", 2, 1).Return(nil)
+
+		assert.NoError(t, h.AddFavorite(c))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed not customer", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodPost, "/", nil), rec, "password", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		util.ErrorHandler(h.AddFavorite(c), c)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed unregistered customer", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodPost, "/", nil), rec, "phone", nil)
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		util.ErrorHandler(h.AddFavorite(c), c)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed place id is not number", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodPost, "/", nil), rec, "phone", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("satu")
+
+		util.ErrorHandler(h.AddFavorite(c), c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed place is not found", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodPost, "/", nil), rec, "phone", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("99")
+
+		mockService.On("AddFavorite", 2, 99).Return(errors.Wrap(ErrNotFound, "place with id 99 is not found"))
+
+		util.ErrorHandler(h.AddFavorite(c), c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_RemoveFavorite(t *testing.T) {
+	e := echo.New()
+	customer := user.Model{ID: 2, Status: util.StatusCustomer}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodDelete, "/", nil), rec, "phone", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		mockService.On("RemoveFavorite", 2, 1).Return(nil)
+
+		assert.NoError(t, h.RemoveFavorite(c))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed not in favorites", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodDelete, "/", nil), rec, "phone", &customer)
+		c.SetParamNames("placeID")
+		c.SetParamValues("1")
+
+		mockService.On("RemoveFavorite", 2, 1).Return(errors.Wrap(ErrNotFound, "place with id 1 is not in favorites"))
+
+		util.ErrorHandler(h.RemoveFavorite(c), c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_GetFavoritesWithPagination(t *testing.T) {
+	e := echo.New()
+	customer := user.Model{ID: 2, Status: util.StatusCustomer}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodGet, "/?page=2&limit=1", nil), rec, "phone", &customer)
+
+		params := FavoriteListRequest{Limit: 1, Page: 2, Path: "/api/v1/user/favorites", UserID: 2}
+		favorites := PlacesList{Places: []Place{{ID: 1, Name: "Kopi", IsFavorite: true}}, TotalCount: 2}
+		mockService.On("GetFavoritesWithPagination", params).Return(&favorites, util.Pagination{}, nil)
+
+		assert.NoError(t, h.GetFavoritesWithPagination(c))
+		mockService.AssertExpectations(t)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"is_favorite":true`)
+	})
+
+	t.Run("failed invalid page", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodGet, "/?page=satu", nil), rec, "phone", &customer)
+
+		util.ErrorHandler(h.GetFavoritesWithPagination(c), c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		rec := httptest.NewRecorder()
+		c := newUserContext(e, httptest.NewRequest(http.MethodGet, "/", nil), rec, "phone", &customer)
+
+		params := FavoriteListRequest{Limit: util.DefaultLimit, Page: util.DefaultPage, Path: "/api/v1/user/favorites", UserID: 2}
+		mockService.On("GetFavoritesWithPagination", params).Return(&PlacesList{}, util.Pagination{}, ErrInternalServerError)
+
+		util.ErrorHandler(h.GetFavoritesWithPagination(c), c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -8,7 +8,9 @@ import (
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // Repo will contain all the function that can be used by repo
@@ -18,8 +20,16 @@ type Repo interface {
 	GetDetail(int) (*Detail, error)
 	GetAverageRatingAndReviews(int) (*AverageRatingAndReviews, error)
 	GetListReviewAndRatingWithPagination(params ListReviewRequest) (*ListReview, error)
+	BackfillStats() (int64, error)
+	IsFavorite(userID int, placeID int) (bool, error)
+	InsertFavorite(userID int, placeID int) error
+	DeleteFavorite(userID int, placeID int) error
+	GetFavoritesWithPagination(params FavoriteListRequest) (*PlacesList, error)
 }
 
+// foreignKeyViolation is the postgres error code when the referenced row does not exist
+const foreignKeyViolation = "23503"
+
 type repo struct {
 	db *sqlx.DB
 }
@@ -49,34 +59,37 @@ func (r *repo) GetAverageRatingAndReviews(placeID int) (*AverageRatingAndReviews
 	var result AverageRatingAndReviews
 	result.Reviews = make([]UserReview, 0)
 
-	query := "SELECT COUNT(id) as count_review FROM reviews WHERE place_id = $1"
-	err := r.db.Get(&result, query, placeID)
-	if err != nil {
+	var stats Stats
+	query := `SELECT review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count
+			FROM place_stats
+			WHERE place_id = $1`
+	err := r.db.Get(&stats, query, placeID)
+	if err != nil && err != sql.ErrNoRows {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	var sumRating int
+	result.ReviewCount = stats.ReviewCount
+	result.AverageRating = averageOf(stats.RatingSum, stats.ReviewCount)
+	result.AspectRatings.Cleanliness = averageOf(stats.CleanlinessSum, stats.CleanlinessCount)
+	result.AspectRatings.Service = averageOf(stats.ServiceSum, stats.ServiceCount)
+	result.AspectRatings.Value = averageOf(stats.ValueSum, stats.ValueCount)
 
-	query = "SELECT COALESCE(SUM(rating), 0) as sum_rating FROM reviews WHERE place_id = $1"
-	err = r.db.Get(&sumRating, query, placeID)
+	query = "SELECT users.name as user, reviews.rating as rating, reviews.content as content, reviews.reply as reply FROM reviews LEFT JOIN users ON reviews.user_id = users.id WHERE reviews.place_id = $1 AND NOT reviews.is_hidden LIMIT 2"
+	err = r.db.Select(&result.Reviews, query, placeID)
 	if err != nil {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	var averageRating float64
-	if result.ReviewCount != 0 {
-		averageRating = float64(sumRating) / float64(result.ReviewCount)
-	}
-	var roundedAverageRating = math.Round(averageRating*100) / 100
-	result.AverageRating = roundedAverageRating
+	return &result, nil
+}
 
-	query = "SELECT users.name as user, reviews.rating as rating, reviews.content as content FROM reviews LEFT JOIN users ON reviews.user_id = users.id WHERE reviews.place_id = $1 LIMIT 2"
-	err = r.db.Select(&result.Reviews, query, placeID)
-	if err != nil {
-		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+// averageOf is rounded to 2 decimal places, place without any rating is 0
+func averageOf(sum int, count int) float64 {
+	if count == 0 {
+		return 0
 	}
 
-	return &result, nil
+	return math.Round(float64(sum)/float64(count)*100) / 100
 }
 
 // GetPlacesListWithPagination will do the query to database for getting list places data
@@ -135,20 +148,17 @@ func (r repo) GetPlacesListWithPagination(params PlacesListRequest) (*PlacesList
 		params.Latitude,
 		params.Longitude)
 
-	reviewCountQuery := fmt.Sprintf("SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id")
-
-	ratingQuery := fmt.Sprintf("SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id")
+	ratingQuery := "COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0)"
 
 	query := "SELECT p.id, p.name, p.description, p.address, p.image, "
-	query += fmt.Sprintf("(%s) as rating, ", ratingQuery)
-	query += fmt.Sprintf("(%s) as review_count, ", reviewCountQuery)
+	query += fmt.Sprintf("%s as rating, ", ratingQuery)
+	query += "COALESCE(ps.review_count, 0) as review_count, "
 	query += fmt.Sprintf("CAST(%s AS integer) AS distance ", distanceQuery)
-	query += "FROM places p "
-	countQuery := fmt.Sprintf("SELECT p.*, (%s) as rating FROM places p ", ratingQuery)
-	if params.Sort == "popularity" {
-		query += "LEFT JOIN bookings b on p.id = b.place_id "
-		countQuery += "LEFT JOIN bookings b on p.id = b.place_id "
+	if params.UserID > 0 {
+		query += fmt.Sprintf(", EXISTS (SELECT 1 FROM favorites f WHERE f.place_id = p.id AND f.user_id = %d) AS is_favorite ", params.UserID)
 	}
+	query += "FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id "
+	countQuery := fmt.Sprintf("SELECT p.*, %s as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ", ratingQuery)
 	if len(whereQuery) != 0 {
 		query += fmt.Sprintf("WHERE %s ", strings.Join(whereQuery, " AND "))
 		countQuery += fmt.Sprintf("WHERE %s ", strings.Join(whereQuery, " AND "))
@@ -158,8 +168,7 @@ func (r repo) GetPlacesListWithPagination(params PlacesListRequest) (*PlacesList
 	case "distance":
 		query += "ORDER BY distance "
 	case "popularity":
-		query += "GROUP BY p.id ORDER BY COUNT(p.id) DESC "
-		countQuery += "GROUP BY p.id"
+		query += "ORDER BY COALESCE(ps.booking_count, 0) DESC "
 	}
 
 	if len(params.Rating) != 0 {
@@ -204,9 +213,13 @@ func (r repo) GetPlacesListWithPagination(params PlacesListRequest) (*PlacesList
 func (r repo) GetPlaceRatingAndReviewCountByPlaceID(placeID int) (*PlacesRatingAndReviewCount, error) {
 	var result PlacesRatingAndReviewCount
 
-	query := "SELECT COUNT(rating) as review_count, COALESCE (AVG(rating), 0.0) as rating FROM reviews WHERE place_id = $1"
+	query := "SELECT review_count, COALESCE(rating_sum::float / NULLIF(review_count, 0), 0.0) as rating FROM place_stats WHERE place_id = $1"
 	err := r.db.Get(&result, query, placeID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return &result, nil
+		}
+
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
@@ -219,11 +232,19 @@ func (r repo) GetListReviewAndRatingWithPagination(params ListReviewRequest) (*L
 	listReview.TotalCount = 0
 	
 	mainQuery := `
-	SELECT r.id, u.name, r.content, r.rating, r.created_at
+	SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating,
+		ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos,
+		r.created_at, r.reply, r.replied_at
 	FROM reviews r, users u
-	WHERE r.place_id = $1 AND u.id = r.user_id `
+	WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden `
 	branchQuery := ``
 
+	filterQuery := ``
+	if params.WithPhotos {
+		filterQuery = `AND EXISTS (SELECT 1 FROM review_photos rp WHERE rp.review_id = r.id) `
+	}
+	mainQuery += filterQuery
+
 	if params.Latest && params.Rating {
 		branchQuery = `ORDER BY r.created_at DESC, r.rating DESC`
 	} else if params.Latest && !params.Rating {
@@ -244,7 +265,7 @@ func (r repo) GetListReviewAndRatingWithPagination(params ListReviewRequest) (*L
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
 	}
 
-	mainQuery = `SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id`
+	mainQuery = `SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden ` + filterQuery
 	err = r.db.Get(&listReview.TotalCount, mainQuery, params.PlaceID)
 	if err != nil {
 		return nil, errors.Wrap(ErrInternalServerError, err.Error())
@@ -252,3 +273,119 @@ func (r repo) GetListReviewAndRatingWithPagination(params ListReviewRequest) (*L
 
 	return &listReview, nil
 }
+
+// BackfillStats recompute place_stats of every place from reviews and completed bookings
+func (r repo) BackfillStats() (int64, error) {
+	query := `INSERT INTO place_stats (place_id, review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count, booking_count)
+			SELECT p.id, COALESCE(r.review_count, 0), COALESCE(r.rating_sum, 0), COALESCE(r.cleanliness_sum, 0), COALESCE(r.cleanliness_count, 0),
+				COALESCE(r.service_sum, 0), COALESCE(r.service_count, 0), COALESCE(r.value_sum, 0), COALESCE(r.value_count, 0), COALESCE(b.booking_count, 0)
+			FROM places p
+			LEFT JOIN (
+				SELECT place_id, COUNT(id) AS review_count, SUM(rating) AS rating_sum,
+					SUM(cleanliness_rating) AS cleanliness_sum, COUNT(cleanliness_rating) AS cleanliness_count,
+					SUM(service_rating) AS service_sum, COUNT(service_rating) AS service_count,
+					SUM(value_rating) AS value_sum, COUNT(value_rating) AS value_count
+				FROM reviews
+				WHERE NOT is_hidden
+				GROUP BY place_id
+			) r ON r.place_id = p.id
+			LEFT JOIN (
+				SELECT place_id, COUNT(id) AS booking_count
+				FROM bookings
+				WHERE status = $1 OR status = $2
+				GROUP BY place_id
+			) b ON b.place_id = p.id
+			ON CONFLICT (place_id) DO UPDATE SET
+				review_count = EXCLUDED.review_count, rating_sum = EXCLUDED.rating_sum,
+				cleanliness_sum = EXCLUDED.cleanliness_sum, cleanliness_count = EXCLUDED.cleanliness_count,
+				service_sum = EXCLUDED.service_sum, service_count = EXCLUDED.service_count,
+				value_sum = EXCLUDED.value_sum, value_count = EXCLUDED.value_count,
+				booking_count = EXCLUDED.booking_count, updated_at = NOW()`
+
+	result, err := r.db.Exec(query, util.BookingSelesai, util.BookingDireview)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return rowsAffected, nil
+}
+
+func (r repo) IsFavorite(userID int, placeID int) (bool, error) {
+	var isFavorite bool
+
+	query := "SELECT EXISTS (SELECT 1 FROM favorites WHERE user_id = $1 AND place_id = $2)"
+	err := r.db.Get(&isFavorite, query, userID, placeID)
+	if err != nil {
+		return false, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return isFavorite, nil
+}
+
+func (r repo) InsertFavorite(userID int, placeID int) error {
+	query := "INSERT INTO favorites (user_id, place_id) VALUES ($1, $2) ON CONFLICT (user_id, place_id) DO NOTHING"
+
+	_, err := r.db.Exec(query, userID, placeID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == foreignKeyViolation {
+			return errors.Wrap(ErrNotFound, fmt.Sprintf("place with id %d is not found", placeID))
+		}
+
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) DeleteFavorite(userID int, placeID int) error {
+	query := "DELETE FROM favorites WHERE user_id = $1 AND place_id = $2"
+
+	result, err := r.db.Exec(query, userID, placeID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrNotFound, fmt.Sprintf("place with id %d is not in favorites", placeID))
+	}
+
+	return nil
+}
+
+func (r repo) GetFavoritesWithPagination(params FavoriteListRequest) (*PlacesList, error) {
+	var placeList PlacesList
+	placeList.Places = make([]Place, 0)
+
+	query := `SELECT p.id, p.name, p.description, p.address, p.image,
+				COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) AS rating,
+				COALESCE(ps.review_count, 0) AS review_count, TRUE AS is_favorite
+			FROM favorites f
+			INNER JOIN places p ON p.id = f.place_id
+			LEFT JOIN place_stats ps ON ps.place_id = p.id
+			WHERE f.user_id = $1
+			ORDER BY f.created_at DESC, f.id DESC
+			LIMIT $2 OFFSET $3`
+
+	err := r.db.Select(&placeList.Places, query, params.UserID, params.Limit, (params.Page-1)*params.Limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	query = "SELECT COUNT(id) FROM favorites WHERE user_id = $1"
+	err = r.db.Get(&placeList.TotalCount, query, params.UserID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &placeList, nil
+}
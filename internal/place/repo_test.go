@@ -7,8 +7,10 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 func TestRepo_GetDetailSuccess(t *testing.T) {
@@ -100,6 +102,7 @@ func TestRepo_GetUserReviewForDetailSuccess(t *testing.T) {
 	placeID := 1
 	expectedAverageRatingAndReviews := &AverageRatingAndReviews{
 		AverageRating: 3.50,
+		AspectRatings: AspectRatings{Cleanliness: 4.33, Value: 5},
 		ReviewCount:   30,
 		Reviews: []UserReview{
 			{
@@ -126,13 +129,9 @@ func TestRepo_GetUserReviewForDetailSuccess(t *testing.T) {
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 	repoMock := NewRepo(sqlxDB)
 
-	rows := mock.NewRows([]string{"count_review"}).AddRow(30)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(id) as count_review FROM reviews WHERE place_id = $1")).
-		WithArgs(placeID).
-		WillReturnRows(rows)
-
-	rows = mock.NewRows([]string{"sum_rating"}).AddRow(105)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(rating), 0) as sum_rating FROM reviews WHERE place_id = $1")).
+	rows := mock.NewRows([]string{"review_count", "rating_sum", "cleanliness_sum", "cleanliness_count", "service_sum", "service_count", "value_sum", "value_count"}).
+		AddRow(30, 105, 13, 3, 0, 0, 10, 2)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count FROM place_stats WHERE place_id = $1")).
 		WithArgs(placeID).
 		WillReturnRows(rows)
 
@@ -145,7 +144,7 @@ func TestRepo_GetUserReviewForDetailSuccess(t *testing.T) {
 			expectedAverageRatingAndReviews.Reviews[1].User,
 			expectedAverageRatingAndReviews.Reviews[1].Rating,
 			expectedAverageRatingAndReviews.Reviews[1].Content)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT users.name as user, reviews.rating as rating, reviews.content as content FROM reviews LEFT JOIN users ON reviews.user_id = users.id WHERE reviews.place_id = $1 LIMIT 2")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT users.name as user, reviews.rating as rating, reviews.content as content, reviews.reply as reply FROM reviews LEFT JOIN users ON reviews.user_id = users.id WHERE reviews.place_id = $1 AND NOT reviews.is_hidden LIMIT 2")).
 		WithArgs(placeID).
 		WillReturnRows(rows)
 
@@ -156,7 +155,7 @@ func TestRepo_GetUserReviewForDetailSuccess(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestRepo_GetUserReviewForDetailCountReviewInternalServerError(t *testing.T) {
+func TestRepo_GetUserReviewForDetailStatsInternalServerError(t *testing.T) {
 	placeID := 1
 
 	// Mock DB
@@ -170,7 +169,7 @@ func TestRepo_GetUserReviewForDetailCountReviewInternalServerError(t *testing.T)
 
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(id) as count_review FROM reviews WHERE place_id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("FROM place_stats WHERE place_id = $1")).
 		WithArgs(placeID).
 		WillReturnError(sql.ErrTxDone)
 
@@ -180,7 +179,7 @@ func TestRepo_GetUserReviewForDetailCountReviewInternalServerError(t *testing.T)
 	assert.Nil(t, retrivedAverageRatingAndReviews)
 }
 
-func TestRepo_GetUserReviewForDetailSumRatingInternalServerError(t *testing.T) {
+func TestRepo_GetUserReviewForDetailWithoutStats(t *testing.T) {
 	placeID := 1
 
 	// Mock DB
@@ -195,20 +194,17 @@ func TestRepo_GetUserReviewForDetailSumRatingInternalServerError(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 
-	rows := mock.NewRows([]string{"count_review"}).AddRow(30)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(id) as count_review FROM reviews WHERE place_id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("FROM place_stats WHERE place_id = $1")).
 		WithArgs(placeID).
-		WillReturnRows(rows)
-
-	rows = mock.NewRows([]string{"sum_rating"}).AddRow(105)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(rating), 0) as sum_rating FROM reviews WHERE place_id = $1")).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT users.name as user, reviews.rating as rating, reviews.content as content, reviews.reply as reply FROM reviews")).
 		WithArgs(placeID).
-		WillReturnError(sql.ErrTxDone)
+		WillReturnRows(mock.NewRows([]string{"user", "rating", "content"}))
 
 	// Test
 	retrivedAverageRatingAndReviews, err := repoMock.GetAverageRatingAndReviews(placeID)
-	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
-	assert.Nil(t, retrivedAverageRatingAndReviews)
+	assert.NoError(t, err)
+	assert.Equal(t, &AverageRatingAndReviews{Reviews: []UserReview{}}, retrivedAverageRatingAndReviews)
 }
 
 func TestRepo_GetUserReviewForDetailInternalServerError(t *testing.T) {
@@ -225,17 +221,13 @@ func TestRepo_GetUserReviewForDetailInternalServerError(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 
-	rows := mock.NewRows([]string{"count_review"}).AddRow(30)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(id) as count_review FROM reviews WHERE place_id = $1")).
+	rows := mock.NewRows([]string{"review_count", "rating_sum", "cleanliness_sum", "cleanliness_count", "service_sum", "service_count", "value_sum", "value_count"}).
+		AddRow(30, 105, 13, 3, 0, 0, 10, 2)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count FROM place_stats WHERE place_id = $1")).
 		WithArgs(placeID).
 		WillReturnRows(rows)
 
-	rows = mock.NewRows([]string{"sum_rating"}).AddRow(105)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(rating), 0) as sum_rating FROM reviews WHERE place_id = $1")).
-		WithArgs(placeID).
-		WillReturnRows(rows)
-
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT users.name as user, reviews.rating as rating, reviews.content as content FROM reviews LEFT JOIN users ON reviews.user_id = users.id WHERE reviews.place_id = $1 LIMIT 2")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT users.name as user, reviews.rating as rating, reviews.content as content, reviews.reply as reply FROM reviews LEFT JOIN users ON reviews.user_id = users.id WHERE reviews.place_id = $1 AND NOT reviews.is_hidden LIMIT 2")).
 		WithArgs(placeID).
 		WillReturnError(sql.ErrTxDone)
 
@@ -295,17 +287,17 @@ func TestRepo_GetPlacesListWithPaginationSuccess(t *testing.T) {
 			placeListExpected.Places[1].Image)
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		SELECT p.id, p.name, p.description, p.address, p.image,
-		(SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating,
-		(SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count,
+		COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating,
+		COALESCE(ps.review_count, 0) as review_count,
 		CAST(6371 * ACOS(SIN(RADIANS(41.403380)) * SIN(RADIANS(p.lat)) + COS(RADIANS(41.403380)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(2.174030))) AS integer) AS distance
-		FROM places p LIMIT $1 OFFSET $2
+		FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id LIMIT $1 OFFSET $2
 		`)).
 		WithArgs(params.Limit, (params.Page-1)*params.Limit).
 		WillReturnRows(rows)
 
 	rows = mock.NewRows([]string{"count"}).AddRow(2)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT COUNT(*) FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p ) AS temp`)).
+		SELECT COUNT(*) FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ) AS temp`)).
 		WillReturnRows(rows)
 
 	// Test
@@ -336,7 +328,7 @@ func TestRepo_GetPlacesListWithPaginationEmpty(t *testing.T) {
 
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LIMIT $1 OFFSET $2")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id LIMIT $1 OFFSET $2")).
 		WithArgs(params.Limit, (params.Page-1)*params.Limit).
 		WillReturnError(sql.ErrNoRows)
 
@@ -368,7 +360,7 @@ func TestRepo_GetPlacesListWithPaginationEmptyWhenCount(t *testing.T) {
 
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LIMIT $1 OFFSET $2")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id LIMIT $1 OFFSET $2")).
 		WithArgs(params.Limit, (params.Page-1)*params.Limit).
 		WillReturnError(sql.ErrNoRows)
 
@@ -459,12 +451,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(41.403380)) * SIN(RADIANS(p.lat)) + COS(RADIANS(41.403380)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(2.174030))) AS integer) AS distance FROM places p ORDER BY distance LIMIT $1 OFFSET $2`)).
+			SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(41.403380)) * SIN(RADIANS(p.lat)) + COS(RADIANS(41.403380)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(2.174030))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ORDER BY distance LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p ) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -491,12 +483,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN bookings b on p.id = b.place_id GROUP BY p.id ORDER BY COUNT(p.id) DESC LIMIT $1 OFFSET $2`)).
+			SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ORDER BY COALESCE(ps.booking_count, 0) DESC LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p LEFT JOIN bookings b on p.id = b.place_id GROUP BY p.id) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -523,12 +515,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) LIMIT $1 OFFSET $2`)).
+			SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) ) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) ) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -555,12 +547,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p WHERE ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) LIMIT $1 OFFSET $2`)).
+			SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p WHERE ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) ) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) ) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -587,12 +579,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT * FROM (SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6) LIMIT $1 OFFSET $2`)).
+			SELECT * FROM (SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6) LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT * FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6)) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT * FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6)) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -621,12 +613,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT * FROM (SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) AND ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6) LIMIT $1 OFFSET $2`)).
+			SELECT * FROM (SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) AND ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6) LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT * FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) AND ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6)) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT * FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE ((booking_price < 16000) OR (booking_price >= 16000 AND booking_price < 40000) OR (booking_price >= 40000 AND booking_price < 100000) OR (booking_price >= 100000)) AND ((capacity < 2) OR (capacity >= 2 AND capacity < 5) OR (capacity >= 5 AND capacity < 10) OR (capacity >= 10)) ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6)) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -654,12 +646,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT * FROM (SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN bookings b on p.id = b.place_id GROUP BY p.id ORDER BY COUNT(p.id) DESC ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6) LIMIT $1 OFFSET $2`)).
+			SELECT * FROM (SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ORDER BY COALESCE(ps.booking_count, 0) DESC ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6) LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT * FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p LEFT JOIN bookings b on p.id = b.place_id GROUP BY p.id) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6)) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT * FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id ) AS temp WHERE (rating >= 1 AND rating < 2) OR (rating >= 2 AND rating < 3) OR (rating >= 3 AND rating < 4) OR (rating >= 4 AND rating < 5) OR (rating >= 5 AND rating < 6)) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -686,12 +678,12 @@ func TestRepo_GetPlaceListWithFilterAndSort(t *testing.T) {
 			AddRow("1", "test name", "description", "address")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT p.id, p.name, p.description, p.address, p.image, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating, (SELECT COUNT(r.rating) FROM reviews r WHERE p.id = r.place_id) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p WHERE p.id IN (SELECT place_id FROM place_category pc JOIN categories c ON pc.category_id = c.id WHERE c.content='Indoor') LIMIT $1 OFFSET $2`)).
+			SELECT p.id, p.name, p.description, p.address, p.image, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating, COALESCE(ps.review_count, 0) as review_count, CAST(6371 * ACOS(SIN(RADIANS(0.000000)) * SIN(RADIANS(p.lat)) + COS(RADIANS(0.000000)) * COS(RADIANS(p.lat)) * COS(RADIANS(p.long) - RADIANS(0.000000))) AS integer) AS distance FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE p.id IN (SELECT place_id FROM place_category pc JOIN categories c ON pc.category_id = c.id WHERE c.content='Indoor') LIMIT $1 OFFSET $2`)).
 			WithArgs(params.Limit, (params.Page-1)*params.Limit).WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(2)
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT COUNT(*) FROM (SELECT p.*, (SELECT COALESCE(AVG(r.rating), 0.0) FROM reviews r WHERE r.place_id = p.id) as rating FROM places p WHERE p.id IN (SELECT place_id FROM place_category pc JOIN categories c ON pc.category_id = c.id WHERE c.content='Indoor') ) AS temp`)).
+			SELECT COUNT(*) FROM (SELECT p.*, COALESCE(ps.rating_sum::float / NULLIF(ps.review_count, 0), 0.0) as rating FROM places p LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE p.id IN (SELECT place_id FROM place_category pc JOIN categories c ON pc.category_id = c.id WHERE c.content='Indoor') ) AS temp`)).
 			WillReturnRows(rows)
 
 		_, err = repoMock.GetPlacesListWithPagination(params)
@@ -725,7 +717,7 @@ func TestRepo_GetPlaceReviewSuccess(t *testing.T) {
 	rows := mock.
 		NewRows([]string{"review_count", "rating"}).
 		AddRow(len(ratingData), averageRating)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(rating) as review_count, COALESCE (AVG(rating), 0.0) as rating FROM reviews WHERE place_id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT review_count, COALESCE(rating_sum::float / NULLIF(review_count, 0), 0.0) as rating FROM place_stats WHERE place_id = $1")).
 		WithArgs(1).
 		WillReturnRows(rows)
 
@@ -747,7 +739,7 @@ func TestRepo_GetPlaceReviewFailed(t *testing.T) {
 	// Expectation
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 	repoMock := NewRepo(sqlxDB)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(rating) as review_count, COALESCE (AVG(rating), 0.0) as rating FROM reviews WHERE place_id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT review_count, COALESCE(rating_sum::float / NULLIF(review_count, 0), 0.0) as rating FROM place_stats WHERE place_id = $1")).
 		WithArgs(1).
 		WillReturnError(sql.ErrConnDone)
 
@@ -812,15 +804,15 @@ func TestRepo_GetListReviewWithPaginationSuccess(t *testing.T) {
 		}
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT r.id, u.name, r.content, r.rating, r.created_at
+			SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating, ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos, r.created_at, r.reply, r.replied_at
 			FROM reviews r, users u
-			WHERE r.place_id = $1 AND u.id = r.user_id
+			WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden
 			ORDER BY r.created_at DESC, r.rating DESC LIMIT $2 OFFSET $3`)).
 			WithArgs(params.PlaceID, params.Limit, (params.Page-1)*params.Limit).
 			WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(10)
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden")).
 			WithArgs(params.PlaceID).
 			WillReturnRows(rows)
 
@@ -854,15 +846,15 @@ func TestRepo_GetListReviewWithPaginationSuccess(t *testing.T) {
 		}
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT r.id, u.name, r.content, r.rating, r.created_at
+			SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating, ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos, r.created_at, r.reply, r.replied_at
 			FROM reviews r, users u
-			WHERE r.place_id = $1 AND u.id = r.user_id
+			WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden
 			ORDER BY r.rating DESC LIMIT $2 OFFSET $3`)).
 			WithArgs(params.PlaceID, params.Limit, (params.Page-1)*params.Limit).
 			WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(10)
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden")).
 			WithArgs(params.PlaceID).
 			WillReturnRows(rows)
 
@@ -896,15 +888,15 @@ func TestRepo_GetListReviewWithPaginationSuccess(t *testing.T) {
 		}
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT r.id, u.name, r.content, r.rating, r.created_at
+			SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating, ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos, r.created_at, r.reply, r.replied_at
 			FROM reviews r, users u
-			WHERE r.place_id = $1 AND u.id = r.user_id
+			WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden
 			ORDER BY r.created_at DESC LIMIT $2 OFFSET $3`)).
 			WithArgs(params.PlaceID, params.Limit, (params.Page-1)*params.Limit).
 			WillReturnRows(rows)
 
 		rows = mock.NewRows([]string{"count"}).AddRow(10)
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden")).
 			WithArgs(params.PlaceID).
 			WillReturnRows(rows)
 
@@ -941,9 +933,9 @@ func TestRepo_GetListReviewWithPaginationEmpty(t *testing.T) {
 	// Expectation
 	repoMock := NewRepo(sqlxDB)
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT r.id, u.name, r.content, r.rating, r.created_at
+		SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating, ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos, r.created_at, r.reply, r.replied_at
 		FROM reviews r, users u
-		WHERE r.place_id = $1 AND u.id = r.user_id
+		WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden
 		ORDER BY r.created_at DESC LIMIT $2 OFFSET $3`)).
 		WithArgs(params.PlaceID, params.Limit, (params.Page-1)*params.Limit).
 		WillReturnError(sql.ErrNoRows)
@@ -975,9 +967,9 @@ func TestRepo_GetListReviewWithPaginationError(t *testing.T) {
 		}
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT r.id, u.name, r.content, r.rating, r.created_at
+			SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating, ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos, r.created_at, r.reply, r.replied_at
 			FROM reviews r, users u
-			WHERE r.place_id = $1 AND u.id = r.user_id
+			WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden
 			ORDER BY r.created_at DESC LIMIT $2 OFFSET $3`)).
 			WithArgs(params.PlaceID, params.Limit, (params.Page-1)*params.Limit).
 			WillReturnError(sql.ErrTxDone)
@@ -1002,13 +994,13 @@ func TestRepo_GetListReviewWithPaginationError(t *testing.T) {
 			AddRow(1, "test name", "test content", 1, "test created_at")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT r.id, u.name, r.content, r.rating, r.created_at
+			SELECT r.id, u.name, r.content, r.rating, r.cleanliness_rating, r.service_rating, r.value_rating, ARRAY(SELECT rp.url FROM review_photos rp WHERE rp.review_id = r.id ORDER BY rp.id) AS photos, r.created_at, r.reply, r.replied_at
 			FROM reviews r, users u
-			WHERE r.place_id = $1 AND u.id = r.user_id
+			WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden
 			ORDER BY r.created_at DESC LIMIT $2 OFFSET $3`)).
 			WithArgs(params.PlaceID, params.Limit, (params.Page-1)*params.Limit).
 			WillReturnRows(rows)
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden")).
 			WillReturnError(sql.ErrNoRows)
 
 		// Test
@@ -1017,3 +1009,263 @@ func TestRepo_GetListReviewWithPaginationError(t *testing.T) {
 		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	})
 }
+
+func TestRepo_GetListReviewWithPaginationReply(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+
+	reply := "Terima kasih sudah berkunjung"
+	repliedAt := "2022-05-02"
+	params := ListReviewRequest{Limit: 10, Page: 1, PlaceID: 1}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden LIMIT $2 OFFSET $3")).
+		WithArgs(params.PlaceID, params.Limit, 0).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "content", "rating", "created_at", "reply", "replied_at"}).
+			AddRow(1, "Rafi", "Tempatnya bagus", 5, "2022-05-01", reply, repliedAt).
+			AddRow(2, "Bima", "Biasa saja", 3, "2022-05-01", nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden")).
+		WithArgs(params.PlaceID).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+
+	listReviewResult, err := repoMock.GetListReviewAndRatingWithPagination(params)
+	assert.NoError(t, err)
+	assert.Equal(t, &ListReview{
+		Reviews: []Review{
+			{ID: 1, Name: "Rafi", Content: "Tempatnya bagus", Rating: 5, Date: "2022-05-01", Reply: &reply, RepliedAt: &repliedAt},
+			{ID: 2, Name: "Bima", Content: "Biasa saja", Rating: 3, Date: "2022-05-01"},
+		},
+		TotalCount: 2,
+	}, listReviewResult)
+}
+
+func TestRepo_GetListReviewWithPaginationWithPhotos(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+
+	params := ListReviewRequest{Limit: 10, Page: 1, PlaceID: 1, Latest: true, WithPhotos: true}
+	cleanliness := 4
+
+	mock.ExpectQuery(regexp.QuoteMeta("AND NOT r.is_hidden AND EXISTS (SELECT 1 FROM review_photos rp WHERE rp.review_id = r.id) ORDER BY r.created_at DESC LIMIT $2 OFFSET $3")).
+		WithArgs(params.PlaceID, params.Limit, 0).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "content", "rating", "cleanliness_rating", "service_rating", "value_rating", "photos", "created_at", "reply", "replied_at"}).
+			AddRow(1, "Rafi", "Tempatnya bagus", 5, 4, nil, nil, "{https://cdn/1.png,https://cdn/2.png}", "2022-05-01", nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(r.id) FROM reviews r, users u WHERE r.place_id = $1 AND u.id = r.user_id AND NOT r.is_hidden AND EXISTS (SELECT 1 FROM review_photos rp WHERE rp.review_id = r.id)")).
+		WithArgs(params.PlaceID).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+
+	listReviewResult, err := repoMock.GetListReviewAndRatingWithPagination(params)
+	assert.NoError(t, err)
+	assert.Equal(t, &ListReview{
+		Reviews: []Review{
+			{
+				ID:                1,
+				Name:              "Rafi",
+				Content:           "Tempatnya bagus",
+				Rating:            5,
+				CleanlinessRating: &cleanliness,
+				Photos:            pq.StringArray{"https://cdn/1.png", "https://cdn/2.png"},
+				Date:              "2022-05-01",
+			},
+		},
+		TotalCount: 1,
+	}, listReviewResult)
+}
+
+func TestRepo_BackfillStats(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO place_stats")).
+			WithArgs(util.BookingSelesai, util.BookingDireview).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		updated, err := repoMock.BackfillStats()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), updated)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO place_stats")).
+			WithArgs(util.BookingSelesai, util.BookingDireview).
+			WillReturnError(sql.ErrTxDone)
+
+		updated, err := repoMock.BackfillStats()
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Equal(t, int64(0), updated)
+	})
+}
+
+func TestRepo_GetPlacesListWithFavorite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+
+	params := PlacesListRequest{Limit: 10, Page: 1, UserID: 2}
+	mock.ExpectQuery(regexp.QuoteMeta("AS distance , EXISTS (SELECT 1 FROM favorites f WHERE f.place_id = p.id AND f.user_id = 2) AS is_favorite FROM places p")).
+		WithArgs(params.Limit, 0).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "is_favorite"}).AddRow(1, "Kopi", true).AddRow(2, "Teh", false))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM")).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(2))
+
+	placeList, err := repoMock.GetPlacesListWithPagination(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []Place{{ID: 1, Name: "Kopi", IsFavorite: true}, {ID: 2, Name: "Teh"}}, placeList.Places)
+}
+
+func TestRepo_IsFavorite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+	query := "SELECT EXISTS (SELECT 1 FROM favorites WHERE user_id = $1 AND place_id = $2)"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(2, 1).
+			WillReturnRows(mock.NewRows([]string{"exists"}).AddRow(true))
+
+		isFavorite, err := repoMock.IsFavorite(2, 1)
+		assert.NoError(t, err)
+		assert.True(t, isFavorite)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(2, 1).
+			WillReturnError(sql.ErrTxDone)
+
+		isFavorite, err := repoMock.IsFavorite(2, 1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.False(t, isFavorite)
+	})
+}
+
+func TestRepo_InsertFavorite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+	query := "INSERT INTO favorites (user_id, place_id) VALUES ($1, $2) ON CONFLICT (user_id, place_id) DO NOTHING"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(2, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, repoMock.InsertFavorite(2, 1))
+	})
+
+	t.Run("failed place is not found", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(2, 99).
+			WillReturnError(&pq.Error{Code: foreignKeyViolation})
+
+		err := repoMock.InsertFavorite(2, 99)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(2, 1).
+			WillReturnError(sql.ErrTxDone)
+
+		err := repoMock.InsertFavorite(2, 1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_DeleteFavorite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+	query := "DELETE FROM favorites WHERE user_id = $1 AND place_id = $2"
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(2, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repoMock.DeleteFavorite(2, 1))
+	})
+
+	t.Run("failed not in favorites", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(2, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repoMock.DeleteFavorite(2, 1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetFavoritesWithPagination(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+	repoMock := NewRepo(sqlxDB)
+	params := FavoriteListRequest{Limit: 10, Page: 2, UserID: 2}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM favorites f INNER JOIN places p ON p.id = f.place_id LEFT JOIN place_stats ps ON ps.place_id = p.id WHERE f.user_id = $1 ORDER BY f.created_at DESC, f.id DESC LIMIT $2 OFFSET $3")).
+			WithArgs(2, 10, 10).
+			WillReturnRows(mock.NewRows([]string{"id", "name", "rating", "review_count", "is_favorite"}).AddRow(1, "Kopi", 4.5, 2, true))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(id) FROM favorites WHERE user_id = $1")).
+			WithArgs(2).
+			WillReturnRows(mock.NewRows([]string{"count"}).AddRow(11))
+
+		favorites, err := repoMock.GetFavoritesWithPagination(params)
+		assert.NoError(t, err)
+		assert.Equal(t, &PlacesList{Places: []Place{{ID: 1, Name: "Kopi", Rating: 4.5, ReviewCount: 2, IsFavorite: true}}, TotalCount: 11}, favorites)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM favorites f")).
+			WithArgs(2, 10, 10).
+			WillReturnError(sql.ErrTxDone)
+
+		favorites, err := repoMock.GetFavoritesWithPagination(params)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, favorites)
+	})
+}
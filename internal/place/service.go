@@ -10,8 +10,12 @@ import (
 // Service will contain all the function that can be used by service
 type Service interface {
 	GetPlaceListWithPagination(params PlacesListRequest) (*PlacesList, *util.Pagination, error)
-	GetDetail(placeID int) (*Detail, error)
+	GetDetail(placeID int, userID int) (*Detail, error)
 	GetListReviewAndRatingWithPagination(params ListReviewRequest) (*ListReview, *util.Pagination, error)
+	BackfillStats() (int64, error)
+	AddFavorite(userID int, placeID int) error
+	RemoveFavorite(userID int, placeID int) error
+	GetFavoritesWithPagination(params FavoriteListRequest) (*PlacesList, *util.Pagination, error)
 }
 
 type service struct {
@@ -24,7 +28,8 @@ func NewService(repo Repo) Service {
 		repo: repo}
 }
 
-func (s *service) GetDetail(placeID int) (*Detail, error) {
+// GetDetail of the place, is_favorite is only filled when userID is above 0
+func (s *service) GetDetail(placeID int, userID int) (*Detail, error) {
 	errorList := []string{}
 
 	if placeID <= 0 {
@@ -46,6 +51,7 @@ func (s *service) GetDetail(placeID int) (*Detail, error) {
 	}
 
 	placeDetail.AverageRating = averageRatingAndReviews.AverageRating
+	placeDetail.AspectRatings = averageRatingAndReviews.AspectRatings
 	placeDetail.ReviewCount = averageRatingAndReviews.ReviewCount
 
 	placeDetail.Reviews = make([]UserReview, 0)
@@ -53,6 +59,13 @@ func (s *service) GetDetail(placeID int) (*Detail, error) {
 		placeDetail.Reviews = append(placeDetail.Reviews, i)
 	}
 
+	if userID > 0 {
+		placeDetail.IsFavorite, err = s.repo.IsFavorite(userID, placeID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return placeDetail, nil
 }
 
@@ -161,3 +174,56 @@ func (s service) GetListReviewAndRatingWithPagination(params ListReviewRequest)
 
 	return listReview, &pagination, err
 }
+
+// BackfillStats rebuild place_stats from the source tables, returning the number of place updated
+func (s *service) BackfillStats() (int64, error) {
+	return s.repo.BackfillStats()
+}
+
+func (s *service) AddFavorite(userID int, placeID int) error {
+	if placeID <= 0 {
+		return errors.Wrap(ErrInputValidationError, "placeID must be above 0")
+	}
+
+	return s.repo.InsertFavorite(userID, placeID)
+}
+
+func (s *service) RemoveFavorite(userID int, placeID int) error {
+	if placeID <= 0 {
+		return errors.Wrap(ErrInputValidationError, "placeID must be above 0")
+	}
+
+	return s.repo.DeleteFavorite(userID, placeID)
+}
+
+func (s *service) GetFavoritesWithPagination(params FavoriteListRequest) (*PlacesList, *util.Pagination, error) {
+	var errorList []string
+
+	if params.Page <= 0 {
+		params.Page = util.DefaultPage
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = util.DefaultLimit
+	}
+
+	if params.Limit > util.MaxLimit {
+		errorList = append(errorList, "limit should be 1 - 100")
+	}
+
+	if params.Path == "" {
+		errorList = append(errorList, "path is required for pagination")
+	}
+
+	if len(errorList) > 0 {
+		return nil, nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	favorites, err := s.repo.GetFavoritesWithPagination(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := util.GeneratePagination(favorites.TotalCount, params.Limit, params.Page, params.Path)
+	return favorites, &pagination, nil
+}
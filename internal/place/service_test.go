@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 type MockRepository struct {
@@ -42,6 +43,29 @@ func (m *MockRepository) GetListReviewAndRatingWithPagination(params ListReviewR
 	return &ret, args.Error(1)
 }
 
+func (m *MockRepository) BackfillStats() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) IsFavorite(userID int, placeID int) (bool, error) {
+	args := m.Called(userID, placeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) InsertFavorite(userID int, placeID int) error {
+	return m.Called(userID, placeID).Error(0)
+}
+
+func (m *MockRepository) DeleteFavorite(userID int, placeID int) error {
+	return m.Called(userID, placeID).Error(0)
+}
+
+func (m *MockRepository) GetFavoritesWithPagination(params FavoriteListRequest) (*PlacesList, error) {
+	args := m.Called(params)
+	return args.Get(0).(*PlacesList), args.Error(1)
+}
+
 func TestService_GetDetailSuccess(t *testing.T) {
 	placeID := 1
 	placeDetail := Detail{
@@ -56,6 +80,7 @@ func TestService_GetDetailSuccess(t *testing.T) {
 
 	averageRatingAndReviews := AverageRatingAndReviews{
 		AverageRating: 3.50,
+		AspectRatings: AspectRatings{Cleanliness: 4.25, Service: 3, Value: 4.5},
 		ReviewCount:   30,
 		Reviews: []UserReview{
 			{
@@ -77,9 +102,10 @@ func TestService_GetDetailSuccess(t *testing.T) {
 	mockRepo.On("GetDetail", placeID).Return(placeDetail, nil)
 	mockRepo.On("GetAverageRatingAndReviews", placeID).Return(averageRatingAndReviews, nil)
 
-	placeDetailResult, err := mockService.GetDetail(placeID)
+	placeDetailResult, err := mockService.GetDetail(placeID, 0)
 
 	placeDetail.AverageRating = averageRatingAndReviews.AverageRating
+	placeDetail.AspectRatings = averageRatingAndReviews.AspectRatings
 	placeDetail.ReviewCount = averageRatingAndReviews.ReviewCount
 
 	placeDetail.Reviews = make([]UserReview, 2)
@@ -105,7 +131,7 @@ func TestService_GetDetailWrongInput(t *testing.T) {
 	mockService := NewService(mockRepo)
 
 	// Test
-	placeDetail, err := mockService.GetDetail(placeID)
+	placeDetail, err := mockService.GetDetail(placeID, 0)
 
 	assert.Equal(t, ErrInputValidationError, errors.Cause(err))
 	assert.Nil(t, placeDetail)
@@ -120,7 +146,7 @@ func TestService_GetDetailFailedCalledGetDetail(t *testing.T) {
 
 	mockRepo.On("GetDetail", placeID).Return(placeDetail, ErrInternalServerError)
 
-	placeDetailResult, err := mockService.GetDetail(placeID)
+	placeDetailResult, err := mockService.GetDetail(placeID, 0)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	assert.Nil(t, placeDetailResult)
@@ -137,7 +163,7 @@ func TestService_GetDetailFailedCalledGetAverageRatingAndReviews(t *testing.T) {
 	mockRepo.On("GetDetail", placeID).Return(placeDetail, nil)
 	mockRepo.On("GetAverageRatingAndReviews", placeID).Return(averageRatingAndReviews, ErrInternalServerError)
 
-	placeDetailResult, err := mockService.GetDetail(placeID)
+	placeDetailResult, err := mockService.GetDetail(placeID, 0)
 
 	assert.Equal(t, ErrInternalServerError, errors.Cause(err))
 	assert.Nil(t, placeDetailResult)
@@ -624,3 +650,131 @@ func TestService_FilterSortCategory(t *testing.T) {
 		assert.True(t, errors.Is(err, ErrInputValidationError))
 	})
 }
+
+func TestService_BackfillStats(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockService := NewService(mockRepo)
+
+	mockRepo.On("BackfillStats").Return(int64(3), nil)
+
+	updated, err := mockService.BackfillStats()
+	mockRepo.AssertExpectations(t)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), updated)
+}
+
+func TestService_GetDetailWithFavorite(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetDetail", 1).Return(Detail{ID: 1}, nil)
+		mockRepo.On("GetAverageRatingAndReviews", 1).Return(AverageRatingAndReviews{}, nil)
+		mockRepo.On("IsFavorite", 2, 1).Return(true, nil)
+
+		placeDetail, err := mockService.GetDetail(1, 2)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.True(t, placeDetail.IsFavorite)
+	})
+
+	t.Run("failed called is favorite", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetDetail", 1).Return(Detail{ID: 1}, nil)
+		mockRepo.On("GetAverageRatingAndReviews", 1).Return(AverageRatingAndReviews{}, nil)
+		mockRepo.On("IsFavorite", 2, 1).Return(false, ErrInternalServerError)
+
+		placeDetail, err := mockService.GetDetail(1, 2)
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, placeDetail)
+	})
+}
+
+func TestService_AddFavorite(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("InsertFavorite", 2, 1).Return(nil)
+
+		err := mockService.AddFavorite(2, 1)
+		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed invalid place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		err := mockService.AddFavorite(2, 0)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_RemoveFavorite(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("DeleteFavorite", 2, 1).Return(nil)
+
+		err := mockService.RemoveFavorite(2, 1)
+		mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed invalid place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		err := mockService.RemoveFavorite(2, -1)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetFavoritesWithPagination(t *testing.T) {
+	t.Run("success with default params", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		params := FavoriteListRequest{Limit: util.DefaultLimit, Page: util.DefaultPage, Path: "/api/v1/user/favorites", UserID: 2}
+		favorites := PlacesList{Places: []Place{{ID: 1, IsFavorite: true}}, TotalCount: 1}
+		mockRepo.On("GetFavoritesWithPagination", params).Return(&favorites, nil)
+
+		result, pagination, err := mockService.GetFavoritesWithPagination(FavoriteListRequest{Path: "/api/v1/user/favorites", UserID: 2})
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &favorites, result)
+		assert.Equal(t, util.GeneratePagination(1, util.DefaultLimit, util.DefaultPage, "/api/v1/user/favorites"), *pagination)
+	})
+
+	t.Run("failed limit above maximum", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		result, pagination, err := mockService.GetFavoritesWithPagination(FavoriteListRequest{Limit: 101, Path: "/api/v1/user/favorites", UserID: 2})
+
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+	})
+
+	t.Run("failed called repo", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetFavoritesWithPagination", mock.Anything).Return(&PlacesList{}, ErrInternalServerError)
+
+		result, pagination, err := mockService.GetFavoritesWithPagination(FavoriteListRequest{Path: "/api/v1/user/favorites", UserID: 2})
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+	})
+}
@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"time"
+
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Calculate evaluate all the rules against the booking and return the booking price.
+// Weekday and weekend rules replace the base price, the others are added on top of it.
+func (r RuleSet) Calculate(params QuoteParams) Quote {
+	quote := Quote{
+		BasePrice:  r.BasePrice,
+		Components: make([]Component, 0),
+	}
+
+	price := r.BasePrice
+	isWeekend := params.Date.Weekday() == time.Saturday || params.Date.Weekday() == time.Sunday
+
+	for _, rule := range r.Rules {
+		if (rule.Type == util.PricingRuleWeekday && !isWeekend) || (rule.Type == util.PricingRuleWeekend && isWeekend) {
+			price = rule.Price
+			quote.BasePrice = rule.Price
+			quote.Components = append(quote.Components, Component{Name: rule.Name, Type: rule.Type, Amount: rule.Price})
+		}
+	}
+
+	for _, rule := range r.Rules {
+		var amount float64
+
+		switch rule.Type {
+		case util.PricingRulePeakHour:
+			if isOverlap(rule.StartTime, rule.EndTime, params.StartTime, params.EndTime) {
+				amount = rule.Price
+			}
+		case util.PricingRulePerPerson:
+			amount = rule.Price * float64(params.Count)
+		case util.PricingRuleSpecialDate:
+			if rule.Date == params.Date.Format(util.DateLayout) {
+				amount = rule.Price
+			}
+		default:
+			continue
+		}
+
+		if amount == 0 {
+			continue
+		}
+
+		price += amount
+		quote.Components = append(quote.Components, Component{Name: rule.Name, Type: rule.Type, Amount: amount})
+	}
+
+	quote.Total = price
+	return quote
+}
+
+func isOverlap(ruleStart, ruleEnd string, bookingStart, bookingEnd time.Time) bool {
+	start, err := time.Parse(util.TimeLayout, ruleStart)
+	if err != nil {
+		return false
+	}
+
+	end, err := time.Parse(util.TimeLayout, ruleEnd)
+	if err != nil {
+		return false
+	}
+
+	return secondOfDay(start) < secondOfDay(bookingEnd) && secondOfDay(bookingStart) < secondOfDay(end)
+}
+
+func secondOfDay(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
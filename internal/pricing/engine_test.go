@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func TestRuleSet_Calculate(t *testing.T) {
+	startTime, _ := time.Parse(util.TimeLayout, "17:00:00")
+	endTime, _ := time.Parse(util.TimeLayout, "19:00:00")
+	weekday, _ := time.Parse(util.DateLayout, "2022-05-04")
+	weekend, _ := time.Parse(util.DateLayout, "2022-05-07")
+
+	ruleSet := RuleSet{
+		PlaceID:   1,
+		BasePrice: 10000,
+		Rules: []Rule{
+			{Name: "weekday", Type: util.PricingRuleWeekday, Price: 8000},
+			{Name: "weekend", Type: util.PricingRuleWeekend, Price: 15000},
+			{Name: "peak hour", Type: util.PricingRulePeakHour, Price: 5000, StartTime: "18:00:00", EndTime: "21:00:00"},
+			{Name: "per person", Type: util.PricingRulePerPerson, Price: 1000},
+			{Name: "lebaran", Type: util.PricingRuleSpecialDate, Price: 20000, Date: "2022-05-07"},
+		},
+	}
+
+	t.Run("no rules use base price", func(t *testing.T) {
+		quote := RuleSet{BasePrice: 10000}.Calculate(QuoteParams{
+			Date:      weekday,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Count:     2,
+		})
+
+		assert.Equal(t, 10000.0, quote.Total)
+		assert.Equal(t, 10000.0, quote.BasePrice)
+		assert.Empty(t, quote.Components)
+	})
+
+	t.Run("weekday with peak hour and per person", func(t *testing.T) {
+		quote := ruleSet.Calculate(QuoteParams{
+			Date:      weekday,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Count:     2,
+		})
+
+		assert.Equal(t, 8000.0, quote.BasePrice)
+		assert.Equal(t, 8000.0+5000.0+2000.0, quote.Total)
+		assert.Len(t, quote.Components, 3)
+	})
+
+	t.Run("weekend on special date outside peak hour", func(t *testing.T) {
+		morningStart, _ := time.Parse(util.TimeLayout, "08:00:00")
+		morningEnd, _ := time.Parse(util.TimeLayout, "18:00:00")
+
+		quote := ruleSet.Calculate(QuoteParams{
+			Date:      weekend,
+			StartTime: morningStart,
+			EndTime:   morningEnd,
+			Count:     1,
+		})
+
+		assert.Equal(t, 15000.0, quote.BasePrice)
+		assert.Equal(t, 15000.0+1000.0+20000.0, quote.Total)
+	})
+
+	t.Run("invalid peak hour is ignored", func(t *testing.T) {
+		quote := RuleSet{
+			BasePrice: 10000,
+			Rules: []Rule{
+				{Name: "peak hour", Type: util.PricingRulePeakHour, Price: 5000, StartTime: "invalid", EndTime: "21:00:00"},
+				{Name: "peak hour", Type: util.PricingRulePeakHour, Price: 5000, StartTime: "18:00:00", EndTime: "invalid"},
+			},
+		}.Calculate(QuoteParams{
+			Date:      weekday,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Count:     1,
+		})
+
+		assert.Equal(t, 10000.0, quote.Total)
+	})
+}
@@ -0,0 +1,66 @@
+package pricing
+
+import "time"
+
+// Rule represent a pricing rule owned by a place
+type Rule struct {
+	ID        int     `json:"id"`
+	PlaceID   int     `json:"place_id" db:"place_id"`
+	Name      string  `json:"name"`
+	Type      int     `json:"type"`
+	Price     float64 `json:"price"`
+	StartTime string  `json:"start_time,omitempty" db:"start_time"`
+	EndTime   string  `json:"end_time,omitempty" db:"end_time"`
+	Date      string  `json:"date,omitempty"`
+}
+
+// RuleSet contains base booking price and all pricing rules of a place
+type RuleSet struct {
+	PlaceID           int     `json:"place_id"`
+	BasePrice         float64 `json:"base_price"`
+	DepositPercentage int     `json:"deposit_percentage"`
+	Rules             []Rule  `json:"rules"`
+}
+
+// PlacePricing contains pricing configuration stored in places table
+type PlacePricing struct {
+	BasePrice         float64 `db:"booking_price"`
+	DepositPercentage int     `db:"deposit_percentage"`
+}
+
+// QuoteParams is parameter for calculating booking price
+type QuoteParams struct {
+	Date      time.Time
+	StartTime time.Time
+	EndTime   time.Time
+	Count     int
+}
+
+// Component is a part of booking price coming from a rule
+type Component struct {
+	Name   string  `json:"name"`
+	Type   int     `json:"type"`
+	Amount float64 `json:"amount"`
+}
+
+// Quote is the result of booking price calculation
+type Quote struct {
+	BasePrice  float64     `json:"base_price"`
+	Components []Component `json:"components"`
+	Total      float64     `json:"total"`
+}
+
+// CreateRuleRequest for API request body when creating pricing rule
+type CreateRuleRequest struct {
+	Name      string  `json:"name"`
+	Type      int     `json:"type"`
+	Price     float64 `json:"price"`
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	Date      string  `json:"date"`
+}
+
+// UpdateDepositRequest for API request body when updating deposit percentage
+type UpdateDepositRequest struct {
+	Percentage int `json:"percentage"`
+}
@@ -0,0 +1,139 @@
+package pricing
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Handler for defining handler struct
+type Handler struct {
+	service Service
+}
+
+// NewHandler for initialize handler struct
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetRules is a handler for getting pricing rules of business admin place
+func (h *Handler) GetRules(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	ruleSet, err := h.service.GetRulesByUserID(user.ID)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    ruleSet,
+	})
+}
+
+// CreateRule is a handler for creating pricing rule by business admin
+func (h *Handler) CreateRule(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	var req CreateRuleRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	rule, err := h.service.CreateRule(user.ID, req)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    rule,
+	})
+}
+
+// DeleteRule is a handler for deleting pricing rule by business admin
+func (h *Handler) DeleteRule(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("ruleID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "ruleID must be number")
+	}
+
+	err = h.service.DeleteRule(user.ID, ruleID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		default:
+			return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// UpdateDeposit is a handler for updating deposit percentage of business admin place
+func (h *Handler) UpdateDeposit(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		if errors.Cause(err) == middleware.ErrForbidden {
+			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+		}
+	}
+
+	var req UpdateDepositRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	err = h.service.UpdateDepositPercentage(user.ID, req.Percentage)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
@@ -0,0 +1,329 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetRuleSet(placeID int) (*RuleSet, error) {
+	args := m.Called(placeID)
+	return args.Get(0).(*RuleSet), args.Error(1)
+}
+
+func (m *MockService) GetRulesByUserID(userID int) (*RuleSet, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*RuleSet), args.Error(1)
+}
+
+func (m *MockService) CreateRule(userID int, params CreateRuleRequest) (*Rule, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).(*Rule), args.Error(1)
+}
+
+func (m *MockService) DeleteRule(userID int, ruleID int) error {
+	args := m.Called(userID, ruleID)
+	return args.Error(0)
+}
+
+func (m *MockService) UpdateDepositPercentage(userID int, percentage int) error {
+	args := m.Called(userID, percentage)
+	return args.Error(0)
+}
+
+func newBusinessAdminContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	userModel := user.Model{
+		ID:     1,
+		Status: util.StatusBusinessAdmin,
+	}
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userFromDatabase", &userModel)
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_GetRules(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/business-profile/pricing-rules", nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		ruleSet := RuleSet{
+			PlaceID:   1,
+			BasePrice: 10000,
+			Rules: []Rule{
+				{ID: 1, PlaceID: 1, Name: "weekend", Type: util.PricingRuleWeekend, Price: 15000},
+			},
+		}
+
+		expectedResponse, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data:    ruleSet,
+		})
+
+		mockService.On("GetRulesByUserID", 1).Return(&ruleSet, nil)
+
+		if assert.NoError(t, h.GetRules(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, string(expectedResponse), strings.TrimSuffix(rec.Body.String(), "\n"))
+		}
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/business-profile/pricing-rules", nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetRules(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/business-admin/business-profile/pricing-rules", nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetRulesByUserID", 1).Return(&RuleSet{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetRules(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_CreateRule(t *testing.T) {
+	params := CreateRuleRequest{
+		Name:  "weekend",
+		Type:  util.PricingRuleWeekend,
+		Price: 15000,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		payload, _ := json.Marshal(params)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/business-admin/business-profile/pricing-rules", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", util.ApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		rule := Rule{ID: 1, PlaceID: 1, Name: params.Name, Type: params.Type, Price: params.Price}
+		expectedResponse, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusCreated,
+			Message: "success",
+			Data:    rule,
+		})
+
+		mockService.On("CreateRule", 1, params).Return(&rule, nil)
+
+		if assert.NoError(t, h.CreateRule(ctx)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, string(expectedResponse), strings.TrimSuffix(rec.Body.String(), "\n"))
+		}
+	})
+
+	t.Run("failed to bind request body", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/business-admin/business-profile/pricing-rules", strings.NewReader(`{"name": 1}`))
+		req.Header.Set("Content-Type", util.ApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.CreateRule(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		e := echo.New()
+		payload, _ := json.Marshal(params)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/business-admin/business-profile/pricing-rules", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", util.ApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateRule", 1, params).Return(&Rule{}, errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.CreateRule(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		payload, _ := json.Marshal(params)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/business-admin/business-profile/pricing-rules", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", util.ApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateRule", 1, params).Return(&Rule{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.CreateRule(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_DeleteRule(t *testing.T) {
+	newContext := func(ruleID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/business-admin/business-profile/pricing-rules/"+ruleID, nil)
+		rec := httptest.NewRecorder()
+		ctx := newBusinessAdminContext(e, req, rec, "password")
+		ctx.SetPath("/pricing-rules/:ruleID")
+		ctx.SetParamNames("ruleID")
+		ctx.SetParamValues(ruleID)
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("5")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("DeleteRule", 1, 5).Return(nil)
+
+		if assert.NoError(t, h.DeleteRule(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed rule id is not number", func(t *testing.T) {
+		ctx, rec := newContext("abc")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.DeleteRule(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		ctx, rec := newContext("5")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("DeleteRule", 1, 5).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.DeleteRule(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext("5")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("DeleteRule", 1, 5).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.DeleteRule(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_UpdateDeposit(t *testing.T) {
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/business-admin/business-profile/deposit", strings.NewReader(body))
+		req.Header.Set("Content-Type", util.ApplicationJSON)
+		rec := httptest.NewRecorder()
+		return newBusinessAdminContext(e, req, rec, "password"), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext(`{"percentage": 30}`)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdateDepositPercentage", 1, 30).Return(nil)
+
+		if assert.NoError(t, h.UpdateDeposit(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("failed to bind request body", func(t *testing.T) {
+		ctx, rec := newContext(`{"percentage": "30"}`)
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.UpdateDeposit(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		ctx, rec := newContext(`{"percentage": 130}`)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdateDepositPercentage", 1, 130).Return(errors.Wrap(ErrInputValidationError, "test error"))
+
+		util.ErrorHandler(h.UpdateDeposit(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext(`{"percentage": 30}`)
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("UpdateDepositPercentage", 1, 30).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.UpdateDeposit(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,119 @@
+package pricing
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// NewRepo used to initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	GetPlacePricing(placeID int) (*PlacePricing, error)
+	UpdateDepositPercentage(placeID int, percentage int) error
+	GetRules(placeID int) (*[]Rule, error)
+	GetPlaceIDByUserID(userID int) (int, error)
+	CreateRule(rule Rule) (int, error)
+	DeleteRule(placeID int, ruleID int) error
+}
+
+func (r repo) GetPlacePricing(placeID int) (*PlacePricing, error) {
+	var placePricing PlacePricing
+
+	query := "SELECT COALESCE(booking_price, 0) AS booking_price, deposit_percentage FROM places WHERE id = $1"
+	err := r.db.Get(&placePricing, query, placeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("place with id = %d not found", placeID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &placePricing, nil
+}
+
+func (r repo) UpdateDepositPercentage(placeID int, percentage int) error {
+	query := "UPDATE places SET deposit_percentage = $1, updated_at = NOW() WHERE id = $2"
+	_, err := r.db.Exec(query, percentage, placeID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetRules(placeID int) (*[]Rule, error) {
+	rules := make([]Rule, 0)
+
+	query := `SELECT id, place_id, name, type, price,
+				COALESCE(to_char(start_time, 'HH24:MI:SS'), '') AS start_time,
+				COALESCE(to_char(end_time, 'HH24:MI:SS'), '') AS end_time,
+				COALESCE(to_char(date, 'YYYY-MM-DD'), '') AS date
+			FROM pricing_rules
+			WHERE place_id = $1
+			ORDER BY type, id`
+	err := r.db.Select(&rules, query, placeID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &rules, nil
+}
+
+func (r repo) GetPlaceIDByUserID(userID int) (int, error) {
+	var placeID int
+
+	query := "SELECT id FROM places WHERE user_id = $1"
+	err := r.db.Get(&placeID, query, userID)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return placeID, nil
+}
+
+func (r repo) CreateRule(rule Rule) (int, error) {
+	var ID int
+
+	query := `INSERT INTO pricing_rules (place_id, name, type, price, start_time, end_time, date)
+			VALUES ($1, $2, $3, $4, NULLIF($5, '')::time, NULLIF($6, '')::time, NULLIF($7, '')::date)
+			RETURNING id`
+	err := r.db.QueryRow(query, rule.PlaceID, rule.Name, rule.Type, rule.Price, rule.StartTime, rule.EndTime, rule.Date).Scan(&ID)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return ID, nil
+}
+
+func (r repo) DeleteRule(placeID int, ruleID int) error {
+	query := "DELETE FROM pricing_rules WHERE id = $1 AND place_id = $2"
+	result, err := r.db.Exec(query, ruleID, placeID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if affected == 0 {
+		return errors.Wrap(ErrNotFound, fmt.Sprintf("pricing rule with id = %d not found", ruleID))
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package pricing
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+func TestRepo_GetPlacePricing(t *testing.T) {
+	query := "SELECT COALESCE(booking_price, 0) AS booking_price, deposit_percentage FROM places WHERE id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		rows := mock.NewRows([]string{"booking_price", "deposit_percentage"}).AddRow(10000.0, 30)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
+
+		placePricing, err := repoMock.GetPlacePricing(1)
+		assert.NoError(t, err)
+		assert.Equal(t, &PlacePricing{BasePrice: 10000, DepositPercentage: 30}, placePricing)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetPlacePricing(1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.GetPlacePricing(1)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetRules(t *testing.T) {
+	query := `SELECT id, place_id, name, type, price,
+				COALESCE(to_char(start_time, 'HH24:MI:SS'), '') AS start_time,
+				COALESCE(to_char(end_time, 'HH24:MI:SS'), '') AS end_time,
+				COALESCE(to_char(date, 'YYYY-MM-DD'), '') AS date
+			FROM pricing_rules
+			WHERE place_id = $1
+			ORDER BY type, id`
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		expected := []Rule{
+			{ID: 1, PlaceID: 1, Name: "weekend", Type: util.PricingRuleWeekend, Price: 15000},
+			{ID: 2, PlaceID: 1, Name: "peak", Type: util.PricingRulePeakHour, Price: 5000, StartTime: "18:00:00", EndTime: "21:00:00"},
+		}
+
+		rows := mock.NewRows([]string{"id", "place_id", "name", "type", "price", "start_time", "end_time", "date"})
+		for _, rule := range expected {
+			rows.AddRow(rule.ID, rule.PlaceID, rule.Name, rule.Type, rule.Price, rule.StartTime, rule.EndTime, rule.Date)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(rows)
+
+		rules, err := repoMock.GetRules(1)
+		assert.NoError(t, err)
+		assert.Equal(t, &expected, rules)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrTxDone)
+
+		rules, err := repoMock.GetRules(1)
+		assert.Nil(t, rules)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPlaceIDByUserID(t *testing.T) {
+	query := "SELECT id FROM places WHERE user_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1))
+
+		placeID, err := repoMock.GetPlaceIDByUserID(2)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, placeID)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+		_, err := repoMock.GetPlaceIDByUserID(2)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_CreateRule(t *testing.T) {
+	query := `INSERT INTO pricing_rules (place_id, name, type, price, start_time, end_time, date)
+			VALUES ($1, $2, $3, $4, NULLIF($5, '')::time, NULLIF($6, '')::time, NULLIF($7, '')::date)
+			RETURNING id`
+
+	rule := Rule{PlaceID: 1, Name: "peak", Type: util.PricingRulePeakHour, Price: 5000, StartTime: "18:00:00", EndTime: "21:00:00"}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(rule.PlaceID, rule.Name, rule.Type, rule.Price, rule.StartTime, rule.EndTime, rule.Date).
+			WillReturnRows(mock.NewRows([]string{"id"}).AddRow(3))
+
+		ID, err := repoMock.CreateRule(rule)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, ID)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).
+			WithArgs(rule.PlaceID, rule.Name, rule.Type, rule.Price, rule.StartTime, rule.EndTime, rule.Date).
+			WillReturnError(sql.ErrTxDone)
+
+		_, err := repoMock.CreateRule(rule)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_DeleteRule(t *testing.T) {
+	query := "DELETE FROM pricing_rules WHERE id = $1 AND place_id = $2"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.DeleteRule(1, 5)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repoMock.DeleteRule(1, 5)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed rows affected", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnResult(sqlmock.NewErrorResult(sql.ErrTxDone))
+
+		err := repoMock.DeleteRule(1, 5)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(5, 1).WillReturnError(sql.ErrTxDone)
+
+		err := repoMock.DeleteRule(1, 5)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdateDepositPercentage(t *testing.T) {
+	query := "UPDATE places SET deposit_percentage = $1, updated_at = NOW() WHERE id = $2"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(30, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repoMock.UpdateDepositPercentage(1, 30)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(30, 1).WillReturnError(sql.ErrTxDone)
+
+		err := repoMock.UpdateDepositPercentage(1, 30)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
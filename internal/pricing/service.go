@@ -0,0 +1,174 @@
+package pricing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	GetRuleSet(placeID int) (*RuleSet, error)
+	GetRulesByUserID(userID int) (*RuleSet, error)
+	CreateRule(userID int, params CreateRuleRequest) (*Rule, error)
+	DeleteRule(userID int, ruleID int) error
+	UpdateDepositPercentage(userID int, percentage int) error
+}
+
+type service struct {
+	repo Repo
+}
+
+// NewService for initialize service
+func NewService(repo Repo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s service) GetRuleSet(placeID int) (*RuleSet, error) {
+	if placeID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "placeID must be above 0")
+	}
+
+	placePricing, err := s.repo.GetPlacePricing(placeID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repo.GetRules(placeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuleSet{
+		PlaceID:           placeID,
+		BasePrice:         placePricing.BasePrice,
+		DepositPercentage: placePricing.DepositPercentage,
+		Rules:             *rules,
+	}, nil
+}
+
+func (s service) GetRulesByUserID(userID int) (*RuleSet, error) {
+	if userID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "userID must be above 0")
+	}
+
+	placeID, err := s.repo.GetPlaceIDByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRuleSet(placeID)
+}
+
+func (s service) CreateRule(userID int, params CreateRuleRequest) (*Rule, error) {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	if params.Name == "" {
+		errorList = append(errorList, "name is required")
+	}
+
+	if params.Price < 0 {
+		errorList = append(errorList, "price must not be negative")
+	}
+
+	rule := Rule{
+		Name:  params.Name,
+		Type:  params.Type,
+		Price: params.Price,
+	}
+
+	switch params.Type {
+	case util.PricingRuleWeekday, util.PricingRuleWeekend, util.PricingRulePerPerson:
+	case util.PricingRulePeakHour:
+		startTime, errStart := time.Parse(util.TimeLayout, params.StartTime)
+		endTime, errEnd := time.Parse(util.TimeLayout, params.EndTime)
+		if errStart != nil || errEnd != nil {
+			errorList = append(errorList, "start_time and end_time must be in HH:mm:ss format")
+		} else if !startTime.Before(endTime) {
+			errorList = append(errorList, "start_time must be before end_time")
+		}
+
+		rule.StartTime = params.StartTime
+		rule.EndTime = params.EndTime
+	case util.PricingRuleSpecialDate:
+		if _, err := time.Parse(util.DateLayout, params.Date); err != nil {
+			errorList = append(errorList, "date must be in YYYY-mm-dd format")
+		}
+
+		rule.Date = params.Date
+	default:
+		errorList = append(errorList, "type must be between 0 - 4")
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	placeID, err := s.repo.GetPlaceIDByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.PlaceID = placeID
+	rule.ID, err = s.repo.CreateRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (s service) DeleteRule(userID int, ruleID int) error {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	if ruleID <= 0 {
+		errorList = append(errorList, "ruleID must be above 0")
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	placeID, err := s.repo.GetPlaceIDByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteRule(placeID, ruleID)
+}
+
+func (s service) UpdateDepositPercentage(userID int, percentage int) error {
+	var errorList []string
+
+	if userID <= 0 {
+		errorList = append(errorList, "userID must be above 0")
+	}
+
+	if percentage < 0 || percentage > util.MaximumDepositPercentage {
+		errorList = append(errorList, fmt.Sprintf("percentage must be between 0 - %d", util.MaximumDepositPercentage))
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidationError, strings.Join(errorList, ";"))
+	}
+
+	placeID, err := s.repo.GetPlaceIDByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpdateDepositPercentage(placeID, percentage)
+}
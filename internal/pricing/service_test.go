@@ -0,0 +1,325 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetPlacePricing(placeID int) (*PlacePricing, error) {
+	args := m.Called(placeID)
+	return args.Get(0).(*PlacePricing), args.Error(1)
+}
+
+func (m *MockRepository) UpdateDepositPercentage(placeID int, percentage int) error {
+	args := m.Called(placeID, percentage)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetRules(placeID int) (*[]Rule, error) {
+	args := m.Called(placeID)
+	return args.Get(0).(*[]Rule), args.Error(1)
+}
+
+func (m *MockRepository) GetPlaceIDByUserID(userID int) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateRule(rule Rule) (int, error) {
+	args := m.Called(rule)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteRule(placeID int, ruleID int) error {
+	args := m.Called(placeID, ruleID)
+	return args.Error(0)
+}
+
+func TestService_GetRuleSet(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		rules := []Rule{
+			{ID: 1, PlaceID: 1, Name: "weekend", Type: util.PricingRuleWeekend, Price: 15000},
+		}
+
+		mockRepo.On("GetPlacePricing", 1).Return(&PlacePricing{BasePrice: 10000, DepositPercentage: 30}, nil)
+		mockRepo.On("GetRules", 1).Return(&rules, nil)
+
+		ruleSet, err := mockService.GetRuleSet(1)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &RuleSet{PlaceID: 1, BasePrice: 10000, DepositPercentage: 30, Rules: rules}, ruleSet)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		ruleSet, err := mockService.GetRuleSet(0)
+
+		assert.Nil(t, ruleSet)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get place pricing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlacePricing", 1).Return(&PlacePricing{}, errors.Wrap(ErrNotFound, "test error"))
+
+		ruleSet, err := mockService.GetRuleSet(1)
+
+		assert.Nil(t, ruleSet)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed get rules", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlacePricing", 1).Return(&PlacePricing{BasePrice: 10000, DepositPercentage: 30}, nil)
+		mockRepo.On("GetRules", 1).Return(&[]Rule{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		ruleSet, err := mockService.GetRuleSet(1)
+
+		assert.Nil(t, ruleSet)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_GetRulesByUserID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("GetPlacePricing", 1).Return(&PlacePricing{BasePrice: 10000, DepositPercentage: 30}, nil)
+		mockRepo.On("GetRules", 1).Return(&[]Rule{}, nil)
+
+		ruleSet, err := mockService.GetRulesByUserID(2)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, ruleSet.PlaceID)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		ruleSet, err := mockService.GetRulesByUserID(0)
+
+		assert.Nil(t, ruleSet)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		ruleSet, err := mockService.GetRulesByUserID(2)
+
+		assert.Nil(t, ruleSet)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_CreateRule(t *testing.T) {
+	t.Run("success peak hour", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		params := CreateRuleRequest{
+			Name:      "peak hour",
+			Type:      util.PricingRulePeakHour,
+			Price:     5000,
+			StartTime: "18:00:00",
+			EndTime:   "21:00:00",
+			Date:      "2022-05-07",
+		}
+
+		expectedRule := Rule{
+			PlaceID:   1,
+			Name:      params.Name,
+			Type:      params.Type,
+			Price:     params.Price,
+			StartTime: params.StartTime,
+			EndTime:   params.EndTime,
+		}
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("CreateRule", expectedRule).Return(10, nil)
+
+		rule, err := mockService.CreateRule(2, params)
+		mockRepo.AssertExpectations(t)
+
+		expectedRule.ID = 10
+		assert.NoError(t, err)
+		assert.Equal(t, &expectedRule, rule)
+	})
+
+	t.Run("success special date", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		params := CreateRuleRequest{
+			Name:  "lebaran",
+			Type:  util.PricingRuleSpecialDate,
+			Price: 20000,
+			Date:  "2022-05-02",
+		}
+
+		expectedRule := Rule{
+			PlaceID: 1,
+			Name:    params.Name,
+			Type:    params.Type,
+			Price:   params.Price,
+			Date:    params.Date,
+		}
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("CreateRule", expectedRule).Return(11, nil)
+
+		rule, err := mockService.CreateRule(2, params)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 11, rule.ID)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		testCases := []CreateRuleRequest{
+			{Name: "", Type: util.PricingRuleWeekday, Price: 1000},
+			{Name: "negative", Type: util.PricingRulePerPerson, Price: -1},
+			{Name: "unknown", Type: 10, Price: 1000},
+			{Name: "peak", Type: util.PricingRulePeakHour, Price: 1000, StartTime: "18", EndTime: "21:00:00"},
+			{Name: "peak", Type: util.PricingRulePeakHour, Price: 1000, StartTime: "21:00:00", EndTime: "18:00:00"},
+			{Name: "special", Type: util.PricingRuleSpecialDate, Price: 1000, Date: "07-05-2022"},
+		}
+
+		for _, params := range testCases {
+			mockService := NewService(new(MockRepository))
+
+			rule, err := mockService.CreateRule(2, params)
+
+			assert.Nil(t, rule)
+			assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		}
+	})
+
+	t.Run("failed get place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		rule, err := mockService.CreateRule(2, CreateRuleRequest{Name: "weekday", Type: util.PricingRuleWeekday, Price: 1000})
+
+		assert.Nil(t, rule)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed create rule", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("CreateRule", mock.Anything).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		rule, err := mockService.CreateRule(2, CreateRuleRequest{Name: "weekday", Type: util.PricingRuleWeekday, Price: 1000})
+
+		assert.Nil(t, rule)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_DeleteRule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("DeleteRule", 1, 5).Return(nil)
+
+		err := mockService.DeleteRule(2, 5)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		err := mockService.DeleteRule(0, 0)
+
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		err := mockService.DeleteRule(2, 5)
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("DeleteRule", 1, 5).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		err := mockService.DeleteRule(2, 5)
+
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestService_UpdateDepositPercentage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(1, nil)
+		mockRepo.On("UpdateDepositPercentage", 1, 30).Return(nil)
+
+		err := mockService.UpdateDepositPercentage(2, 30)
+		mockRepo.AssertExpectations(t)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		err := mockService.UpdateDepositPercentage(0, 101)
+
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed get place id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPlaceIDByUserID", 2).Return(0, errors.Wrap(ErrInternalServerError, "test error"))
+
+		err := mockService.UpdateDepositPercentage(2, 30)
+
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -0,0 +1,65 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Broker is the in-process pub/sub between the publisher and the connected user
+type Broker interface {
+	Subscribe(userID int) (<-chan Event, func())
+	Publish(userID int, event Event)
+}
+
+type broker struct {
+	mu          sync.RWMutex
+	subscribers map[int]map[chan Event]struct{}
+}
+
+// NewBroker used to initialize broker, subscriber only receive event published in the same process
+func NewBroker() Broker {
+	return &broker{
+		subscribers: map[int]map[chan Event]struct{}{},
+	}
+}
+
+// Subscribe return the channel of user event and the function to unsubscribe it
+func (b *broker) Subscribe(userID int) (<-chan Event, func()) {
+	events := make(chan Event, util.RealtimeBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[chan Event]struct{}{}
+	}
+	b.subscribers[userID][events] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[userID], events)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			b.mu.Unlock()
+			close(events)
+		})
+	}
+}
+
+// Publish send event to every subscriber of user, event is dropped for subscriber that is too slow
+func (b *broker) Publish(userID int, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for events := range b.subscribers[userID] {
+		select {
+		case events <- event:
+		default:
+			logrus.Warnf("[realtime] dropped %s for user %d", event.Type, userID)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func TestBroker_Publish(t *testing.T) {
+	t.Run("success to every subscriber of user", func(t *testing.T) {
+		b := NewBroker()
+		first, unsubscribeFirst := b.Subscribe(1)
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := b.Subscribe(1)
+		defer unsubscribeSecond()
+		other, unsubscribeOther := b.Subscribe(2)
+		defer unsubscribeOther()
+
+		event := Event{Type: util.RealtimeBookingCreated, BookingID: 1}
+		b.Publish(1, event)
+
+		assert.Equal(t, event, <-first)
+		assert.Equal(t, event, <-second)
+		assert.Len(t, other, 0)
+	})
+
+	t.Run("success drop event of slow subscriber", func(t *testing.T) {
+		b := NewBroker()
+		events, unsubscribe := b.Subscribe(1)
+		defer unsubscribe()
+
+		for i := 0; i <= util.RealtimeBufferSize; i++ {
+			b.Publish(1, Event{BookingID: i})
+		}
+
+		assert.Len(t, events, util.RealtimeBufferSize)
+	})
+
+	t.Run("success unsubscribe", func(t *testing.T) {
+		b := NewBroker()
+		events, unsubscribe := b.Subscribe(1)
+
+		unsubscribe()
+		unsubscribe()
+		b.Publish(1, Event{BookingID: 1})
+
+		_, ok := <-events
+		assert.False(t, ok)
+		assert.Empty(t, b.(*broker).subscribers)
+	})
+}
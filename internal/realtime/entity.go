@@ -0,0 +1,17 @@
+package realtime
+
+// Event is the message pushed to the subscriber
+type Event struct {
+	Type      string `json:"type"`
+	BookingID int    `json:"booking_id"`
+	Status    int    `json:"status"`
+}
+
+// BookingParticipant is the users that should receive the event of a booking
+type BookingParticipant struct {
+	ID         int `db:"id"`
+	PlaceID    int `db:"place_id"`
+	CustomerID int `db:"customer_id"`
+	OwnerID    int `db:"owner_id"`
+	Status     int `db:"status"`
+}
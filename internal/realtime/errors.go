@@ -0,0 +1,11 @@
+package realtime
+
+import "github.com/pkg/errors"
+
+var (
+	// ErrInternalServerError is used if there is error that came from the server
+	ErrInternalServerError = errors.New("internal server error")
+
+	// ErrNotFound is used if the data is not found
+	ErrNotFound = errors.New("not found")
+)
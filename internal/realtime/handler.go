@@ -0,0 +1,92 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Handler struct for realtime
+type Handler struct {
+	service   Service
+	keepAlive time.Duration
+}
+
+// NewHandler is used to initialize Handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:   service,
+		keepAlive: util.RealtimeKeepAliveSeconds * time.Second,
+	}
+}
+
+// Stream for handling server-sent events endpoint, the connection is kept open until the client disconnect
+func (h *Handler) Stream(c echo.Context) error {
+	userModel, err := parseUser(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	events, unsubscribe := h.service.Subscribe(userModel.ID)
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(h.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return nil
+			}
+
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+func parseUser(c echo.Context) (*user.Model, error) {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusCustomer)
+	if err != nil {
+		_, userModel, err = middleware.ParseUserData(c, util.StatusBusinessAdmin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if userModel == nil {
+		return nil, errors.Wrap(middleware.ErrForbidden, "user is not registered")
+	}
+
+	return userModel, nil
+}
@@ -0,0 +1,112 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Notify(event notification.Event) {
+	m.Called(event)
+}
+
+func (m *MockService) Subscribe(userID int) (<-chan Event, func()) {
+	args := m.Called(userID)
+	return args.Get(0).(chan Event), args.Get(1).(func())
+}
+
+func newUserContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string, userModel *user.Model) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	ctx := e.NewContext(req, rec)
+	if userModel != nil {
+		ctx.Set("userFromDatabase", userModel)
+	}
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_Stream(t *testing.T) {
+	url := "/api/v1/events"
+
+	for _, providerID := range []string{"phone", "password"} {
+		t.Run("success "+providerID, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			rec := httptest.NewRecorder()
+			ctx := newUserContext(e, req, rec, providerID, &user.Model{ID: 1})
+
+			mockService := new(MockService)
+			h := NewHandler(mockService)
+
+			unsubscribed := false
+			events := make(chan Event, 1)
+			events <- Event{Type: util.RealtimePaymentReceived, BookingID: 1, Status: util.BookingBerhasil}
+			close(events)
+			mockService.On("Subscribe", 1).Return(events, func() { unsubscribed = true })
+
+			assert.NoError(t, h.Stream(ctx))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, "event: payment_received\ndata: {\"type\":\"payment_received\",\"booking_id\":1,\"status\":2}\n\n", rec.Body.String())
+			assert.True(t, unsubscribed)
+		})
+	}
+
+	t.Run("success client disconnected", func(t *testing.T) {
+		e := echo.New()
+		reqContext, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodGet, url, nil).WithContext(reqContext)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", &user.Model{ID: 1})
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		unsubscribed := false
+		mockService.On("Subscribe", 1).Return(make(chan Event), func() { unsubscribed = true })
+
+		assert.NoError(t, h.Stream(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.True(t, unsubscribed)
+	})
+
+	t.Run("failed user is not registered", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone", nil)
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.Stream(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,59 @@
+package realtime
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// NewRepo used to initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	GetBookingParticipant(bookingID int) (*BookingParticipant, error)
+	GetPlaceMemberIDs(placeID int) ([]int, error)
+}
+
+func (r repo) GetBookingParticipant(bookingID int) (*BookingParticipant, error) {
+	var result BookingParticipant
+
+	query := `SELECT b.id, b.place_id, b.user_id AS customer_id, p.user_id AS owner_id, b.status
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			WHERE b.id = $1`
+
+	err := r.db.Get(&result, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id %d is not found", bookingID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetPlaceMemberIDs(placeID int) ([]int, error) {
+	userIDs := make([]int, 0)
+
+	query := "SELECT user_id FROM place_members WHERE place_id = $1 ORDER BY id"
+
+	err := r.db.Select(&userIDs, query, placeID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return userIDs, nil
+}
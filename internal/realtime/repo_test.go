@@ -0,0 +1,87 @@
+package realtime
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+func TestRepo_GetBookingParticipant(t *testing.T) {
+	query := "SELECT b.id, b.place_id, b.user_id AS customer_id, p.user_id AS owner_id, b.status FROM bookings b INNER JOIN places p ON b.place_id = p.id WHERE b.id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).
+			WillReturnRows(mock.NewRows([]string{"id", "place_id", "customer_id", "owner_id", "status"}).AddRow(1, 4, 2, 3, 1))
+
+		result, err := repoMock.GetBookingParticipant(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &BookingParticipant{ID: 1, PlaceID: 4, CustomerID: 2, OwnerID: 3, Status: 1}, result)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetBookingParticipant(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetBookingParticipant(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPlaceMemberIDs(t *testing.T) {
+	query := "SELECT user_id FROM place_members WHERE place_id = $1 ORDER BY id"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(4).
+			WillReturnRows(mock.NewRows([]string{"user_id"}).AddRow(5).AddRow(6))
+
+		result, err := repoMock.GetPlaceMemberIDs(4)
+		assert.Nil(t, err)
+		assert.Equal(t, []int{5, 6}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(4).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetPlaceMemberIDs(4)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -0,0 +1,78 @@
+package realtime
+
+import (
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	Notify(event notification.Event)
+	Subscribe(userID int) (<-chan Event, func())
+}
+
+type service struct {
+	repo   Repo
+	broker Broker
+}
+
+// NewService for initialize service
+func NewService(repo Repo, broker Broker) Service {
+	return &service{
+		repo:   repo,
+		broker: broker,
+	}
+}
+
+// eventTypes map the notification event to the event pushed to the booking owner, staff and customer
+var eventTypes = map[string]string{
+	util.NotificationBookingCreated:   util.RealtimeBookingCreated,
+	util.NotificationBookingConfirmed: util.RealtimeBookingStatusChanged,
+	util.NotificationBookingCancelled: util.RealtimeBookingStatusChanged,
+	util.NotificationBookingExpired:   util.RealtimeBookingStatusChanged,
+	util.NotificationBookingReviewed:  util.RealtimeBookingStatusChanged,
+	util.NotificationBookingPaid:      util.RealtimePaymentReceived,
+}
+
+// Notify push the booking event to the connected owner, staff and customer, failure is only logged so it never fail the caller
+func (s *service) Notify(event notification.Event) {
+	eventType, ok := eventTypes[event.Type]
+	if !ok || event.BookingID <= 0 {
+		return
+	}
+
+	participant, err := s.repo.GetBookingParticipant(event.BookingID)
+	if err != nil {
+		logrus.Errorf("[realtime] failed to publish %s: %v", event.Type, err)
+		return
+	}
+
+	pushed := Event{
+		Type:      eventType,
+		BookingID: participant.ID,
+		Status:    participant.Status,
+	}
+
+	recipients := []int{participant.CustomerID, participant.OwnerID}
+
+	memberIDs, err := s.repo.GetPlaceMemberIDs(participant.PlaceID)
+	if err != nil {
+		logrus.Errorf("[realtime] failed to get staff of place %d: %v", participant.PlaceID, err)
+	}
+	recipients = append(recipients, memberIDs...)
+
+	published := make(map[int]bool, len(recipients))
+	for _, userID := range recipients {
+		if published[userID] {
+			continue
+		}
+
+		published[userID] = true
+		s.broker.Publish(userID, pushed)
+	}
+}
+
+func (s *service) Subscribe(userID int) (<-chan Event, func()) {
+	return s.broker.Subscribe(userID)
+}
@@ -0,0 +1,115 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetBookingParticipant(bookingID int) (*BookingParticipant, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*BookingParticipant), args.Error(1)
+}
+
+func (m *MockRepository) GetPlaceMemberIDs(placeID int) ([]int, error) {
+	args := m.Called(placeID)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func TestService_Notify(t *testing.T) {
+	participant := BookingParticipant{ID: 1, PlaceID: 4, CustomerID: 2, OwnerID: 3, Status: util.BookingBerhasil}
+
+	t.Run("success push to customer, owner and staff", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, NewBroker())
+		customer, unsubscribeCustomer := mockService.Subscribe(2)
+		defer unsubscribeCustomer()
+		owner, unsubscribeOwner := mockService.Subscribe(3)
+		defer unsubscribeOwner()
+		staff, unsubscribeStaff := mockService.Subscribe(5)
+		defer unsubscribeStaff()
+
+		mockRepo.On("GetBookingParticipant", 1).Return(&participant, nil)
+		mockRepo.On("GetPlaceMemberIDs", 4).Return([]int{5}, nil)
+
+		mockService.Notify(notification.Event{Type: util.NotificationBookingPaid, BookingID: 1})
+
+		expected := Event{Type: util.RealtimePaymentReceived, BookingID: 1, Status: util.BookingBerhasil}
+		assert.Equal(t, expected, <-customer)
+		assert.Equal(t, expected, <-owner)
+		assert.Equal(t, expected, <-staff)
+	})
+
+	t.Run("success push once to user that is both customer and staff", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, NewBroker())
+		customer, unsubscribe := mockService.Subscribe(2)
+		defer unsubscribe()
+
+		mockRepo.On("GetBookingParticipant", 1).Return(&participant, nil)
+		mockRepo.On("GetPlaceMemberIDs", 4).Return([]int{2}, nil)
+
+		mockService.Notify(notification.Event{Type: util.NotificationBookingConfirmed, BookingID: 1})
+
+		assert.Equal(t, util.RealtimeBookingStatusChanged, (<-customer).Type)
+		assert.Len(t, customer, 0)
+	})
+
+	t.Run("success still push to customer and owner when staff lookup failed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, NewBroker())
+		owner, unsubscribe := mockService.Subscribe(3)
+		defer unsubscribe()
+
+		mockRepo.On("GetBookingParticipant", 1).Return(&participant, nil)
+		mockRepo.On("GetPlaceMemberIDs", 4).Return([]int(nil), errors.Wrap(ErrInternalServerError, "test error"))
+
+		mockService.Notify(notification.Event{Type: util.NotificationBookingConfirmed, BookingID: 1})
+
+		assert.Equal(t, util.RealtimeBookingStatusChanged, (<-owner).Type)
+	})
+
+	t.Run("success map status changed event", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, NewBroker())
+		customer, unsubscribe := mockService.Subscribe(2)
+		defer unsubscribe()
+
+		mockRepo.On("GetBookingParticipant", 1).Return(&participant, nil)
+		mockRepo.On("GetPlaceMemberIDs", 4).Return([]int{}, nil)
+
+		mockService.Notify(notification.Event{Type: util.NotificationBookingConfirmed, BookingID: 1})
+
+		assert.Equal(t, util.RealtimeBookingStatusChanged, (<-customer).Type)
+	})
+
+	t.Run("success ignore event that is not related to booking", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, NewBroker())
+
+		mockService.Notify(notification.Event{Type: util.NotificationDisbursementCompleted, UserID: 3})
+
+		mockRepo.AssertNotCalled(t, "GetBookingParticipant", mock.Anything)
+	})
+
+	t.Run("failed booking is not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, NewBroker())
+		customer, unsubscribe := mockService.Subscribe(2)
+		defer unsubscribe()
+
+		mockRepo.On("GetBookingParticipant", 1).Return(&BookingParticipant{}, errors.Wrap(ErrNotFound, "test error"))
+
+		mockService.Notify(notification.Event{Type: util.NotificationBookingCreated, BookingID: 1})
+
+		assert.Len(t, customer, 0)
+	})
+}
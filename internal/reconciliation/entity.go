@@ -0,0 +1,23 @@
+package reconciliation
+
+import "time"
+
+// Report is the result of a single reconciliation run
+type Report struct {
+	StartedAt            time.Time     `json:"started_at"`
+	FinishedAt           time.Time     `json:"finished_at"`
+	CheckedInvoices      int           `json:"checked_invoices"`
+	CheckedDisbursements int           `json:"checked_disbursements"`
+	Discrepancies        []Discrepancy `json:"discrepancies"`
+}
+
+// Discrepancy is a difference between our record and the payment provider record
+type Discrepancy struct {
+	Type           string `json:"type"`
+	ID             int    `json:"id"`
+	XenditID       string `json:"xendit_id"`
+	LocalStatus    string `json:"local_status"`
+	ProviderStatus string `json:"provider_status"`
+	Applied        bool   `json:"applied"`
+	Error          string `json:"error,omitempty"`
+}
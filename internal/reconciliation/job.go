@@ -0,0 +1,42 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Job run the reconciliation periodically in background
+type Job struct {
+	*util.Job
+	service Service
+}
+
+// NewJob for initialize reconciliation job
+func NewJob(service Service, interval time.Duration) *Job {
+	job := &Job{service: service}
+	job.Job = util.NewJob(interval, func() { job.Run() })
+	return job
+}
+
+// Run a single reconciliation and log the discrepancy found
+func (j *Job) Run() *Report {
+	report, err := j.service.Reconcile()
+	if err != nil {
+		logrus.Errorf("[reconciliation] failed: %v", err)
+		return nil
+	}
+
+	for _, discrepancy := range report.Discrepancies {
+		if discrepancy.Error != "" {
+			logrus.Errorf("[reconciliation] %s %s local %s provider %s: %s", discrepancy.Type, discrepancy.XenditID, discrepancy.LocalStatus, discrepancy.ProviderStatus, discrepancy.Error)
+			continue
+		}
+
+		logrus.Infof("[reconciliation] %s %s local %s provider %s: applied", discrepancy.Type, discrepancy.XenditID, discrepancy.LocalStatus, discrepancy.ProviderStatus)
+	}
+
+	logrus.Infof("[reconciliation] checked %d invoices and %d disbursements, found %d discrepancies", report.CheckedInvoices, report.CheckedDisbursements, len(report.Discrepancies))
+	return report
+}
@@ -0,0 +1,36 @@
+package reconciliation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/booking"
+	businessadmin "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/business_admin"
+)
+
+func TestJob_Run(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		bookingService := new(MockBookingService)
+		businessadminService := new(MockBusinessAdminService)
+		job := NewJob(NewService(bookingService, businessadminService, new(MockXenditService)), time.Minute)
+
+		bookingService.On("GetPendingInvoices").Return(&[]booking.Invoice{}, nil)
+		businessadminService.On("GetPendingDisbursements").Return(&[]businessadmin.DisbursementDetail{}, nil)
+
+		report := job.Run()
+
+		assert.NotNil(t, report)
+		assert.Empty(t, report.Discrepancies)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		bookingService := new(MockBookingService)
+		job := NewJob(NewService(bookingService, new(MockBusinessAdminService), new(MockXenditService)), time.Minute)
+
+		bookingService.On("GetPendingInvoices").Return(&[]booking.Invoice{}, errors.Wrap(booking.ErrInternalServerError, "test error"))
+
+		assert.Nil(t, job.Run())
+	})
+}
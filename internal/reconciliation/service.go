@@ -0,0 +1,158 @@
+package reconciliation
+
+import (
+	"time"
+
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/booking"
+	businessadmin "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/business_admin"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+const (
+	// TypeInvoice for discrepancy on booking invoice
+	TypeInvoice = "invoice"
+
+	// TypeDisbursement for discrepancy on disbursement
+	TypeDisbursement = "disbursement"
+)
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	Reconcile() (*Report, error)
+}
+
+type service struct {
+	bookingService       booking.Service
+	businessadminService businessadmin.Service
+	xenditService        xendit.Service
+}
+
+// NewService for initialize service
+func NewService(bookingService booking.Service, businessadminService businessadmin.Service, xenditService xendit.Service) Service {
+	return &service{
+		bookingService:       bookingService,
+		businessadminService: businessadminService,
+		xenditService:        xenditService,
+	}
+}
+
+// Reconcile compare pending invoices and disbursements with xendit and apply
+// the transitions that was missed because the callback never arrived, the callbacks
+// only settle a pending record so a transition that arrive concurrently is applied once
+func (s *service) Reconcile() (*Report, error) {
+	report := Report{
+		StartedAt:     time.Now(),
+		Discrepancies: []Discrepancy{},
+	}
+
+	invoices, err := s.bookingService.GetPendingInvoices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, invoice := range *invoices {
+		report.CheckedInvoices++
+
+		discrepancy, found := s.reconcileInvoice(invoice)
+		if found {
+			report.Discrepancies = append(report.Discrepancies, discrepancy)
+		}
+	}
+
+	disbursements, err := s.businessadminService.GetPendingDisbursements()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, disbursement := range *disbursements {
+		report.CheckedDisbursements++
+
+		discrepancy, found := s.reconcileDisbursement(disbursement)
+		if found {
+			report.Discrepancies = append(report.Discrepancies, discrepancy)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return &report, nil
+}
+
+func (s *service) reconcileInvoice(invoice booking.Invoice) (Discrepancy, bool) {
+	discrepancy := Discrepancy{
+		Type:        TypeInvoice,
+		ID:          invoice.ID,
+		XenditID:    invoice.XenditID,
+		LocalStatus: util.XenditStatusPending,
+	}
+
+	xenditInvoice, err := s.xenditService.GetInvoice(invoice.XenditID)
+	if err != nil {
+		discrepancy.Error = err.Error()
+		return discrepancy, true
+	}
+
+	discrepancy.ProviderStatus = xenditInvoice.Status
+
+	status := xenditInvoice.Status
+	if status == util.XenditStatusSettled {
+		status = util.XenditStatusPaid
+	}
+
+	if status == util.XenditStatusPending {
+		return discrepancy, false
+	}
+
+	err = s.bookingService.XenditInvoicesCallback(booking.XenditInvoicesCallback{
+		ID:         xenditInvoice.ID,
+		ExternalID: xenditInvoice.ExternalID,
+		Status:     status,
+		Amount:     xenditInvoice.Amount,
+	})
+	if err != nil {
+		discrepancy.Error = err.Error()
+		return discrepancy, true
+	}
+
+	discrepancy.Applied = true
+	return discrepancy, true
+}
+
+func (s *service) reconcileDisbursement(disbursement businessadmin.DisbursementDetail) (Discrepancy, bool) {
+	discrepancy := Discrepancy{
+		Type:        TypeDisbursement,
+		ID:          disbursement.ID,
+		XenditID:    disbursement.XenditID,
+		LocalStatus: util.XenditDisbursementPendingString,
+	}
+
+	xenditDisbursement, err := s.xenditService.GetDisbursement(disbursement.XenditID)
+	if err != nil {
+		discrepancy.Error = err.Error()
+		return discrepancy, true
+	}
+
+	discrepancy.ProviderStatus = xenditDisbursement.Status
+
+	if xenditDisbursement.Status == util.XenditDisbursementPendingString {
+		return discrepancy, false
+	}
+
+	err = s.businessadminService.DisbursementCallbackFromXendit(businessadmin.DisbursementCallback{
+		ID:                      xenditDisbursement.ID,
+		ExternalID:              xenditDisbursement.ExternalID,
+		Amount:                  xenditDisbursement.Amount,
+		BankCode:                xenditDisbursement.BankCode,
+		AccountHolderName:       xenditDisbursement.AccountHolderName,
+		DisbursementDescription: xenditDisbursement.DisbursementDescription,
+		FailureCode:             xenditDisbursement.FailureCode,
+		Status:                  xenditDisbursement.Status,
+	})
+	if err != nil {
+		discrepancy.Error = err.Error()
+		return discrepancy, true
+	}
+
+	discrepancy.Applied = true
+	return discrepancy, true
+}
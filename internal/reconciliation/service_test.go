@@ -0,0 +1,337 @@
+package reconciliation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	xendit2 "github.com/xendit/xendit-go"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/booking"
+	businessadmin "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/business_admin"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/place"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/xendit"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockBookingService struct {
+	mock.Mock
+}
+
+func (m *MockBookingService) GetListCustomerBookingWithPagination(params booking.ListRequest) (*booking.ListBooking, *util.Pagination, error) {
+	args := m.Called(params)
+	return args.Get(0).(*booking.ListBooking), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+func (m *MockBookingService) GetAvailableTime(params booking.GetAvailableTimeParams) (*[]booking.AvailableTimeResponse, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]booking.AvailableTimeResponse), args.Error(1)
+}
+
+func (m *MockBookingService) GetAvailableDate(params booking.GetAvailableDateParams) (*[]booking.AvailableDateResponse, error) {
+	args := m.Called(params)
+	return args.Get(0).(*[]booking.AvailableDateResponse), args.Error(1)
+}
+
+func (m *MockBookingService) CreateBooking(params booking.CreateBookingServiceRequest) (*booking.CreateBookingServiceResponse, error) {
+	args := m.Called(params)
+	return args.Get(0).(*booking.CreateBookingServiceResponse), args.Error(1)
+}
+
+func (m *MockBookingService) GetTimeSlots(placeID int, selectedDate time.Time) (*[]booking.TimeSlot, error) {
+	args := m.Called(placeID, selectedDate)
+	return args.Get(0).(*[]booking.TimeSlot), args.Error(1)
+}
+
+func (m *MockBookingService) GetDetail(userID int, bookingID int) (*booking.Detail, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*booking.Detail), args.Error(1)
+}
+
+func (m *MockBookingService) UpdateBookingStatus(userID int, bookingID int, newStatus int) error {
+	args := m.Called(userID, bookingID, newStatus)
+	return args.Error(0)
+}
+
+func (m *MockBookingService) GetMyBookingsOngoing(localID string) (*[]booking.Booking, error) {
+	args := m.Called(localID)
+	return args.Get(0).(*[]booking.Booking), args.Error(1)
+}
+
+func (m *MockBookingService) GetMyBookingsPreviousWithPagination(localID string, params booking.BookingsListRequest) (*booking.List, *util.Pagination, error) {
+	args := m.Called(localID, params)
+	return args.Get(0).(*booking.List), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+func (m *MockBookingService) XenditInvoicesCallback(callback booking.XenditInvoicesCallback) error {
+	args := m.Called(callback)
+	return args.Error(0)
+}
+
+func (m *MockBookingService) GetDetailBookingSaya(userID int, bookingID int) (*booking.DetailBookingSaya, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*booking.DetailBookingSaya), args.Error(1)
+}
+
+func (m *MockBookingService) CreateRemainingInvoice(userID int, bookingID int) (*booking.Invoice, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*booking.Invoice), args.Error(1)
+}
+
+func (m *MockBookingService) GetPendingInvoices() (*[]booking.Invoice, error) {
+	args := m.Called()
+	return args.Get(0).(*[]booking.Invoice), args.Error(1)
+}
+
+func (m *MockBookingService) GetTicket(userID int, bookingID int) (*booking.Ticket, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*booking.Ticket), args.Error(1)
+}
+
+func (m *MockBookingService) GetTicketPDF(userID int, bookingID int) (*booking.TicketFile, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*booking.TicketFile), args.Error(1)
+}
+
+func (m *MockBookingService) CheckIn(userID int, token string) (*booking.CheckInResponse, error) {
+	args := m.Called(userID, token)
+	return args.Get(0).(*booking.CheckInResponse), args.Error(1)
+}
+
+func (m *MockBookingService) CheckInByBookingID(userID int, bookingID int) (*booking.CheckInResponse, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*booking.CheckInResponse), args.Error(1)
+}
+
+func (m *MockBookingService) MarkNoShow(userID int, bookingID int) error {
+	args := m.Called(userID, bookingID)
+	return args.Error(0)
+}
+
+func (m *MockBookingService) GetNoShowPolicy(userID int) (*booking.NoShowPolicy, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*booking.NoShowPolicy), args.Error(1)
+}
+
+func (m *MockBookingService) UpdateNoShowPolicy(userID int, policy booking.NoShowPolicy) error {
+	args := m.Called(userID, policy)
+	return args.Error(0)
+}
+
+type MockBusinessAdminService struct {
+	mock.Mock
+}
+
+func (m *MockBusinessAdminService) GetBalanceDetail(userID int) (*businessadmin.BalanceDetail, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*businessadmin.BalanceDetail), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) GetListTransactionsHistoryWithPagination(params businessadmin.ListTransactionRequest) (*businessadmin.ListTransaction, *util.Pagination, error) {
+	args := m.Called(params)
+	return args.Get(0).(*businessadmin.ListTransaction), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+func (m *MockBusinessAdminService) CreateDisbursement(userID int, amount float64) (*businessadmin.CreateDisbursementResponse, error) {
+	args := m.Called(userID, amount)
+	return args.Get(0).(*businessadmin.CreateDisbursementResponse), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) DisbursementCallbackFromXendit(params businessadmin.DisbursementCallback) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+func (m *MockBusinessAdminService) GetPendingDisbursements() (*[]businessadmin.DisbursementDetail, error) {
+	args := m.Called()
+	return args.Get(0).(*[]businessadmin.DisbursementDetail), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) GetPayoutSchedule(userID int) (*businessadmin.PayoutSchedule, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*businessadmin.PayoutSchedule), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) UpdatePayoutSchedule(userID int, params businessadmin.PayoutSchedule) error {
+	args := m.Called(userID, params)
+	return args.Error(0)
+}
+
+func (m *MockBusinessAdminService) GetPayoutDetails(userID int) (*businessadmin.PayoutDetails, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*businessadmin.PayoutDetails), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) UpdatePayoutDetails(userID int, params businessadmin.UpdatePayoutDetailsRequest) (*businessadmin.PayoutDetails, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).(*businessadmin.PayoutDetails), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) GetReport(params businessadmin.ReportRequest) (*businessadmin.ReportFile, error) {
+	args := m.Called(params)
+	return args.Get(0).(*businessadmin.ReportFile), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) GetMonthlyStatement(userID int, month string) (*businessadmin.ReportFile, error) {
+	args := m.Called(userID, month)
+	return args.Get(0).(*businessadmin.ReportFile), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) RunScheduledPayouts(now time.Time) (*[]businessadmin.ScheduledPayout, error) {
+	args := m.Called(now)
+	return args.Get(0).(*[]businessadmin.ScheduledPayout), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) GetTransactionHistoryDetail(userID int, bookingID int) (*businessadmin.TransactionHistoryDetail, error) {
+	args := m.Called(userID, bookingID)
+	return args.Get(0).(*businessadmin.TransactionHistoryDetail), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) PutEditProfile(params businessadmin.EditProfileRequest) error {
+	args := m.Called(params)
+	return args.Error(0)
+}
+
+func (m *MockBusinessAdminService) GetPlaceDetail(userID int) (*businessadmin.PlaceDetail, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*businessadmin.PlaceDetail), args.Error(1)
+}
+
+func (m *MockBusinessAdminService) GetListReviewAndRatingWithPagination(userID int, params businessadmin.ListReviewRequest) (*place.ListReview, *util.Pagination, error) {
+	args := m.Called(userID, params)
+	return args.Get(0).(*place.ListReview), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+type MockXenditService struct {
+	mock.Mock
+}
+
+func (x *MockXenditService) CreateInvoice(params xendit.CreateInvoiceParams) (*xendit2.Invoice, error) {
+	args := x.Called(params)
+	return args.Get(0).(*xendit2.Invoice), args.Error(1)
+}
+
+func (x *MockXenditService) CreateDisbursement(params xendit.CreateDisbursementParams) (*xendit2.Disbursement, error) {
+	args := x.Called(params)
+	return args.Get(0).(*xendit2.Disbursement), args.Error(1)
+}
+
+func (x *MockXenditService) GetInvoice(ID string) (*xendit2.Invoice, error) {
+	args := x.Called(ID)
+	return args.Get(0).(*xendit2.Invoice), args.Error(1)
+}
+
+func (x *MockXenditService) GetDisbursement(ID string) (*xendit2.Disbursement, error) {
+	args := x.Called(ID)
+	return args.Get(0).(*xendit2.Disbursement), args.Error(1)
+}
+
+func (x *MockXenditService) GetDisbursementsByExternalID(externalID string) ([]xendit2.Disbursement, error) {
+	args := x.Called(externalID)
+	return args.Get(0).([]xendit2.Disbursement), args.Error(1)
+}
+
+func TestService_Reconcile(t *testing.T) {
+	t.Run("success apply missing transitions", func(t *testing.T) {
+		bookingService := new(MockBookingService)
+		businessadminService := new(MockBusinessAdminService)
+		xenditService := new(MockXenditService)
+		mockService := NewService(bookingService, businessadminService, xenditService)
+
+		invoices := []booking.Invoice{
+			{ID: 1, BookingID: 1, XenditID: "paid", Status: util.InvoicePending},
+			{ID: 2, BookingID: 2, XenditID: "settled", Status: util.InvoicePending},
+			{ID: 3, BookingID: 3, XenditID: "pending", Status: util.InvoicePending},
+		}
+		disbursements := []businessadmin.DisbursementDetail{
+			{ID: 1, PlaceID: 1, XenditID: "completed", Status: util.XenditDisbursementPending},
+			{ID: 2, PlaceID: 1, XenditID: "still pending", Status: util.XenditDisbursementPending},
+		}
+
+		bookingService.On("GetPendingInvoices").Return(&invoices, nil)
+		xenditService.On("GetInvoice", "paid").Return(&xendit2.Invoice{ID: "paid", ExternalID: "1", Status: util.XenditStatusPaid, Amount: 23000}, nil)
+		xenditService.On("GetInvoice", "settled").Return(&xendit2.Invoice{ID: "settled", ExternalID: "1", Status: util.XenditStatusSettled, Amount: 13000}, nil)
+		xenditService.On("GetInvoice", "pending").Return(&xendit2.Invoice{ID: "pending", ExternalID: "1", Status: util.XenditStatusPending}, nil)
+		bookingService.On("XenditInvoicesCallback", booking.XenditInvoicesCallback{ID: "paid", ExternalID: "1", Status: util.XenditStatusPaid, Amount: 23000}).Return(nil)
+		bookingService.On("XenditInvoicesCallback", booking.XenditInvoicesCallback{ID: "settled", ExternalID: "1", Status: util.XenditStatusPaid, Amount: 13000}).Return(nil)
+
+		businessadminService.On("GetPendingDisbursements").Return(&disbursements, nil)
+		xenditService.On("GetDisbursement", "completed").Return(&xendit2.Disbursement{ID: "completed", ExternalID: "2", Amount: 10000, Status: util.XenditDisbursementCompletedString}, nil)
+		xenditService.On("GetDisbursement", "still pending").Return(&xendit2.Disbursement{ID: "still pending", ExternalID: "2", Status: util.XenditDisbursementPendingString}, nil)
+		businessadminService.On("DisbursementCallbackFromXendit", businessadmin.DisbursementCallback{ID: "completed", ExternalID: "2", Amount: 10000, Status: util.XenditDisbursementCompletedString}).Return(nil)
+
+		report, err := mockService.Reconcile()
+		bookingService.AssertExpectations(t)
+		businessadminService.AssertExpectations(t)
+		xenditService.AssertExpectations(t)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, report.CheckedInvoices)
+		assert.Equal(t, 2, report.CheckedDisbursements)
+		assert.Equal(t, []Discrepancy{
+			{Type: TypeInvoice, ID: 1, XenditID: "paid", LocalStatus: util.XenditStatusPending, ProviderStatus: util.XenditStatusPaid, Applied: true},
+			{Type: TypeInvoice, ID: 2, XenditID: "settled", LocalStatus: util.XenditStatusPending, ProviderStatus: util.XenditStatusSettled, Applied: true},
+			{Type: TypeDisbursement, ID: 1, XenditID: "completed", LocalStatus: util.XenditDisbursementPendingString, ProviderStatus: util.XenditDisbursementCompletedString, Applied: true},
+		}, report.Discrepancies)
+	})
+
+	t.Run("success report provider and apply error", func(t *testing.T) {
+		bookingService := new(MockBookingService)
+		businessadminService := new(MockBusinessAdminService)
+		xenditService := new(MockXenditService)
+		mockService := NewService(bookingService, businessadminService, xenditService)
+
+		invoices := []booking.Invoice{
+			{ID: 1, BookingID: 1, XenditID: "unreachable", Status: util.InvoicePending},
+			{ID: 2, BookingID: 2, XenditID: "expired", Status: util.InvoicePending},
+		}
+		disbursements := []businessadmin.DisbursementDetail{
+			{ID: 1, PlaceID: 1, XenditID: "failed", Status: util.XenditDisbursementPending},
+		}
+
+		bookingService.On("GetPendingInvoices").Return(&invoices, nil)
+		xenditService.On("GetInvoice", "unreachable").Return(&xendit2.Invoice{}, errors.Wrap(xendit.ErrXenditGetInvoice, "test error"))
+		xenditService.On("GetInvoice", "expired").Return(&xendit2.Invoice{ID: "expired", ExternalID: "1", Status: util.XenditStatusExpired}, nil)
+		bookingService.On("XenditInvoicesCallback", mock.Anything).Return(errors.Wrap(booking.ErrInternalServerError, "test error"))
+
+		businessadminService.On("GetPendingDisbursements").Return(&disbursements, nil)
+		xenditService.On("GetDisbursement", "failed").Return(&xendit2.Disbursement{ID: "failed", ExternalID: "2", Status: util.XenditDisbursementFailedString}, nil)
+		businessadminService.On("DisbursementCallbackFromXendit", mock.Anything).Return(errors.Wrap(businessadmin.ErrInternalServerError, "test error"))
+
+		report, err := mockService.Reconcile()
+
+		assert.Nil(t, err)
+		assert.Len(t, report.Discrepancies, 3)
+		for _, discrepancy := range report.Discrepancies {
+			assert.False(t, discrepancy.Applied)
+			assert.NotEmpty(t, discrepancy.Error)
+		}
+	})
+
+	t.Run("failed get pending invoices", func(t *testing.T) {
+		bookingService := new(MockBookingService)
+		mockService := NewService(bookingService, new(MockBusinessAdminService), new(MockXenditService))
+
+		bookingService.On("GetPendingInvoices").Return(&[]booking.Invoice{}, errors.Wrap(booking.ErrInternalServerError, "test error"))
+
+		report, err := mockService.Reconcile()
+
+		assert.Nil(t, report)
+		assert.Equal(t, booking.ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed get pending disbursements", func(t *testing.T) {
+		bookingService := new(MockBookingService)
+		businessadminService := new(MockBusinessAdminService)
+		mockService := NewService(bookingService, businessadminService, new(MockXenditService))
+
+		bookingService.On("GetPendingInvoices").Return(&[]booking.Invoice{}, nil)
+		businessadminService.On("GetPendingDisbursements").Return(&[]businessadmin.DisbursementDetail{}, errors.Wrap(businessadmin.ErrInternalServerError, "test error"))
+
+		report, err := mockService.Reconcile()
+
+		assert.Nil(t, report)
+		assert.Equal(t, businessadmin.ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -1,10 +1,116 @@
 package review
 
+import "time"
+
 // BookingReview is a struct to accomodate inserting booking reviews
-type BookingReview struct {	
-	UserID		int    	`db:"user_id"`
-	PlaceID 	int    	`db:"place_id"`
-	BookingID 	int		`json:"booking_id" db:"booking_id"`
-	Content		string	`json:"content" db:"content"`
-	Rating 		int		`json:"rating" db:"rating"`
-}
\ No newline at end of file
+type BookingReview struct {
+	UserID            int      `db:"user_id"`
+	PlaceID           int      `db:"place_id"`
+	BookingID         int      `json:"booking_id" db:"booking_id"`
+	Content           string   `json:"content" db:"content"`
+	Rating            int      `json:"rating" db:"rating"`
+	CleanlinessRating *int     `json:"cleanliness_rating" db:"cleanliness_rating"`
+	ServiceRating     *int     `json:"service_rating" db:"service_rating"`
+	ValueRating       *int     `json:"value_rating" db:"value_rating"`
+	Photos            []string `json:"photos" db:"-"`
+}
+
+// ReviewPhoto is the uploaded photo attached to a review
+type ReviewPhoto struct {
+	ReviewID int    `db:"review_id"`
+	URL      string `db:"url"`
+}
+
+// ReviewedBooking is the booking data needed to check whether it can be reviewed
+type ReviewedBooking struct {
+	UserID  int `db:"user_id"`
+	PlaceID int `db:"place_id"`
+	Status  int `db:"status"`
+}
+
+// RatedReview is the rating part of a review that is counted in the place stats
+type RatedReview struct {
+	PlaceID           int  `db:"place_id"`
+	Rating            int  `db:"rating"`
+	CleanlinessRating *int `db:"cleanliness_rating"`
+	ServiceRating     *int `db:"service_rating"`
+	ValueRating       *int `db:"value_rating"`
+	IsHidden          bool `db:"is_hidden"`
+}
+
+// PlaceStatsDelta is the change of review aggregates of a place
+type PlaceStatsDelta struct {
+	PlaceID          int `db:"place_id"`
+	ReviewCount      int `db:"review_count"`
+	RatingSum        int `db:"rating_sum"`
+	CleanlinessSum   int `db:"cleanliness_sum"`
+	CleanlinessCount int `db:"cleanliness_count"`
+	ServiceSum       int `db:"service_sum"`
+	ServiceCount     int `db:"service_count"`
+	ValueSum         int `db:"value_sum"`
+	ValueCount       int `db:"value_count"`
+}
+
+// Review is the stored review with the owner of the reviewed place
+type Review struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	PlaceID   int       `json:"place_id" db:"place_id"`
+	OwnerID   int       `json:"-" db:"owner_id"`
+	BookingID int       `json:"booking_id" db:"booking_id"`
+	Content   string    `json:"content"`
+	Rating    int       `json:"rating"`
+	Reply     *string   `json:"reply"`
+	IsHidden  bool      `json:"is_hidden" db:"is_hidden"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// UpdateReviewRequest for editing review by customer, the sub-ratings are replaced as well while the photos stay fixed
+type UpdateReviewRequest struct {
+	ReviewID          int    `json:"-"`
+	UserID            int    `json:"-"`
+	Content           string `json:"content"`
+	Rating            int    `json:"rating"`
+	CleanlinessRating *int   `json:"cleanliness_rating"`
+	ServiceRating     *int   `json:"service_rating"`
+	ValueRating       *int   `json:"value_rating"`
+}
+
+// ReplyRequest for owner public reply of a review
+type ReplyRequest struct {
+	ReviewID int    `json:"-"`
+	UserID   int    `json:"-"`
+	Reply    string `json:"reply"`
+}
+
+// Report is the report of abusive review by user
+type Report struct {
+	ReviewID int    `json:"-" db:"review_id"`
+	UserID   int    `json:"-" db:"user_id"`
+	Reason   string `json:"reason" db:"reason"`
+}
+
+// ReportedReview is a review in the moderation queue
+type ReportedReview struct {
+	ID             int       `json:"id"`
+	PlaceID        int       `json:"place_id" db:"place_id"`
+	Content        string    `json:"content"`
+	Rating         int       `json:"rating"`
+	IsHidden       bool      `json:"is_hidden" db:"is_hidden"`
+	ReportCount    int       `json:"report_count" db:"report_count"`
+	Reasons        string    `json:"reasons"`
+	LastReportedAt time.Time `json:"last_reported_at" db:"last_reported_at"`
+}
+
+// ModerationListRequest for moderation queue request params
+type ModerationListRequest struct {
+	Limit int    `json:"limit"`
+	Page  int    `json:"page"`
+	Path  string `json:"path"`
+}
+
+// ModerationList for moderation queue with total count for pagination
+type ModerationList struct {
+	Reviews    []ReportedReview `json:"reviews"`
+	TotalCount int              `json:"total_count"`
+}
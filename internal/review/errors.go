@@ -9,4 +9,6 @@ var (
 	ErrInternalServer = errors.New("internal server error")
 	// ErrNotFound is used if resource not found
 	ErrNotFound = errors.New("not found")
+	// ErrForbidden is used if the user is not allowed to change the review
+	ErrForbidden = errors.New("forbidden")
 )
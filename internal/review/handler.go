@@ -2,9 +2,11 @@ package review
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
@@ -21,13 +23,20 @@ func NewHandler(service Service) *Handler {
 	}
 }
 
-// InsertBookingReview for handling booking review endpoint
+// InsertBookingReview for handling booking review endpoint, only the customer of the booking can review it
 func (h *Handler) InsertBookingReview(c echo.Context) error {
 	_, userModel, err := middleware.ParseUserData(c, util.StatusCustomer)
 	if err != nil {
-		if errors.Cause(err) == middleware.ErrForbidden {
-			return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
-		}
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	if userModel == nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, errors.Wrap(middleware.ErrForbidden, "user is not registered"))
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("bookingID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "bookingID must be number")
 	}
 
 	var review BookingReview
@@ -37,13 +46,11 @@ func (h *Handler) InsertBookingReview(c echo.Context) error {
 	}
 
 	review.UserID = userModel.ID
+	review.BookingID = bookingID
 
 	err = h.service.InsertBookingReview(review)
 	if err != nil {
-		if errors.Cause(err) == ErrInputValidation {
-			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
-		}
-		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+		return errorResponse(c, err)
 	}
 
 	return c.JSON(http.StatusCreated, util.APIResponse{
@@ -51,4 +58,192 @@ func (h *Handler) InsertBookingReview(c echo.Context) error {
 		Message: "Booking review is successfully recorded.",
 	})
 
-}
\ No newline at end of file
+}
+
+// UpdateReview for handling edit review by customer endpoint
+func (h *Handler) UpdateReview(c echo.Context) error {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusCustomer)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	if userModel == nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, errors.Wrap(middleware.ErrForbidden, "user is not registered"))
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("reviewID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "reviewID must be number")
+	}
+
+	var req UpdateReviewRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "invalid body")
+	}
+	req.ReviewID = reviewID
+	req.UserID = userModel.ID
+
+	err = h.service.UpdateReview(req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// ReplyReview for handling owner reply of review endpoint
+func (h *Handler) ReplyReview(c echo.Context) error {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("reviewID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "reviewID must be number")
+	}
+
+	var req ReplyRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "invalid body")
+	}
+	req.ReviewID = reviewID
+	req.UserID = userModel.ID
+
+	err = h.service.ReplyReview(req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+	})
+}
+
+// ReportReview for handling report review endpoint, both customer and business admin can report
+func (h *Handler) ReportReview(c echo.Context) error {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusCustomer)
+	if err != nil {
+		_, userModel, err = middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	}
+	if err != nil || userModel == nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, errors.Wrap(middleware.ErrForbidden, "user is not registered"))
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("reviewID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "reviewID must be number")
+	}
+
+	var report Report
+	if err = c.Bind(&report); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "invalid body")
+	}
+	report.ReviewID = reviewID
+	report.UserID = userModel.ID
+
+	err = h.service.ReportReview(report)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+	})
+}
+
+// GetReportedReviewsWithPagination for handling moderation queue endpoint
+func (h *Handler) GetReportedReviewsWithPagination(c echo.Context) error {
+	if _, err := parseModerator(c); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	page, limit, errorList := util.ValidateParams(c.QueryParam("page"), c.QueryParam("limit"))
+	if len(errorList) > 0 {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, errorList...)
+	}
+
+	params := ModerationListRequest{
+		Limit: limit,
+		Page:  page,
+		Path:  "/api/v1/moderation/reviews",
+	}
+
+	list, pagination, err := h.service.GetReportedReviewsWithPagination(params)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data: map[string]interface{}{
+			"reviews":    list.Reviews,
+			"pagination": pagination,
+		},
+	})
+}
+
+// HideReview for handling hide review by moderator endpoint
+func (h *Handler) HideReview(c echo.Context) error {
+	return h.updateVisibility(c, true)
+}
+
+// RestoreReview for handling restore hidden review by moderator endpoint
+func (h *Handler) RestoreReview(c echo.Context) error {
+	return h.updateVisibility(c, false)
+}
+
+func (h *Handler) updateVisibility(c echo.Context, isHidden bool) error {
+	moderator, err := parseModerator(c)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("reviewID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidation, "reviewID must be number")
+	}
+
+	err = h.service.UpdateVisibility(reviewID, isHidden, moderator.ID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// parseModerator return the user if it is a platform moderator
+func parseModerator(c echo.Context) (*user.Model, error) {
+	_, userModel, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if userModel == nil || userModel.Status != util.StatusModerator {
+		return nil, errors.Wrap(middleware.ErrForbidden, "user is not moderator")
+	}
+
+	return userModel, nil
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch errors.Cause(err) {
+	case ErrInputValidation:
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+	case ErrForbidden:
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	case ErrNotFound:
+		return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+	default:
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+}
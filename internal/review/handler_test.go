@@ -27,6 +27,31 @@ func (m *MockService) InsertBookingReview(review BookingReview) error {
 	return args.Error(0)
 }
 
+func (m *MockService) UpdateReview(req UpdateReviewRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockService) ReplyReview(req ReplyRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockService) ReportReview(report Report) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockService) GetReportedReviewsWithPagination(params ModerationListRequest) (*ModerationList, *util.Pagination, error) {
+	args := m.Called(params)
+	return args.Get(0).(*ModerationList), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+func (m *MockService) UpdateVisibility(reviewID int, isHidden bool, moderatorID int) error {
+	args := m.Called(reviewID, isHidden, moderatorID)
+	return args.Error(0)
+}
+
 func TestHandler_InsertBookingReview(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		// Setup echo
@@ -461,3 +486,420 @@ func TestHandler_InsertBookingReview(t *testing.T) {
 }
 
 
+
+func newUserContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string, userModel *user.Model) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	ctx := e.NewContext(req, rec)
+	if userModel != nil {
+		ctx.Set("userFromDatabase", userModel)
+	}
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_UpdateReview(t *testing.T) {
+	e := echo.New()
+	customer := user.Model{ID: 2, Status: util.StatusCustomer}
+	body := `{"content": "Tempatnya nyaman", "rating": 4}`
+	req := UpdateReviewRequest{ReviewID: 1, UserID: 2, Content: "Tempatnya nyaman", Rating: 4}
+
+	newRequest := func(reviewID string, providerID string) (echo.Context, *httptest.ResponseRecorder) {
+		httpReq := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, providerID, &customer)
+		ctx.SetPath("/api/v1/review/:reviewID")
+		ctx.SetParamNames("reviewID")
+		ctx.SetParamValues(reviewID)
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("1", "phone")
+
+		mockService.On("UpdateReview", req).Return(nil)
+
+		assert.NoError(t, h.UpdateReview(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed not customer", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("1", "password")
+
+		util.ErrorHandler(h.UpdateReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed user is not registered", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		httpReq := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, "phone", nil)
+		ctx.SetPath("/api/v1/review/:reviewID")
+		ctx.SetParamNames("reviewID")
+		ctx.SetParamValues("1")
+
+		util.ErrorHandler(h.UpdateReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockService.AssertNotCalled(t, "UpdateReview", mock.Anything)
+	})
+
+	t.Run("failed review id is not number", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("a", "phone")
+
+		util.ErrorHandler(h.UpdateReview(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed review of other user", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("1", "phone")
+
+		mockService.On("UpdateReview", req).Return(errors.Wrap(ErrForbidden, "test error"))
+
+		util.ErrorHandler(h.UpdateReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("1", "phone")
+
+		mockService.On("UpdateReview", req).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.UpdateReview(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_ReplyReview(t *testing.T) {
+	e := echo.New()
+	owner := user.Model{ID: 3, Status: util.StatusBusinessAdmin}
+	req := ReplyRequest{ReviewID: 1, UserID: 3, Reply: "Terima kasih"}
+
+	newRequest := func(providerID string) (echo.Context, *httptest.ResponseRecorder) {
+		httpReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"reply": "Terima kasih"}`))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, providerID, &owner)
+		ctx.SetPath("/api/v1/business-admin/business-profile/review/:reviewID/reply")
+		ctx.SetParamNames("reviewID")
+		ctx.SetParamValues("1")
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("password")
+
+		mockService.On("ReplyReview", req).Return(nil)
+
+		assert.NoError(t, h.ReplyReview(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("failed not business admin", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("phone")
+
+		util.ErrorHandler(h.ReplyReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed already replied", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("password")
+
+		mockService.On("ReplyReview", req).Return(errors.Wrap(ErrInputValidation, "review is already replied"))
+
+		util.ErrorHandler(h.ReplyReview(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_ReportReview(t *testing.T) {
+	e := echo.New()
+
+	newRequest := func(providerID string, userModel *user.Model) (echo.Context, *httptest.ResponseRecorder) {
+		httpReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"reason": "spam"}`))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, providerID, userModel)
+		ctx.SetPath("/api/v1/review/:reviewID/report")
+		ctx.SetParamNames("reviewID")
+		ctx.SetParamValues("1")
+		return ctx, rec
+	}
+
+	t.Run("success customer", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("phone", &user.Model{ID: 2})
+
+		mockService.On("ReportReview", Report{ReviewID: 1, UserID: 2, Reason: "spam"}).Return(nil)
+
+		assert.NoError(t, h.ReportReview(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("success business admin", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("password", &user.Model{ID: 3})
+
+		mockService.On("ReportReview", Report{ReviewID: 1, UserID: 3, Reason: "spam"}).Return(nil)
+
+		assert.NoError(t, h.ReportReview(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("failed user is not registered", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("phone", nil)
+
+		util.ErrorHandler(h.ReportReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("phone", &user.Model{ID: 2})
+
+		mockService.On("ReportReview", mock.Anything).Return(errors.Wrap(ErrInputValidation, "review is already reported"))
+
+		util.ErrorHandler(h.ReportReview(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_GetReportedReviewsWithPagination(t *testing.T) {
+	e := echo.New()
+	moderator := user.Model{ID: 9, Status: util.StatusModerator}
+	params := ModerationListRequest{Limit: 10, Page: 1, Path: "/api/v1/moderation/reviews"}
+
+	newRequest := func(query string, userModel *user.Model) (echo.Context, *httptest.ResponseRecorder) {
+		httpReq := httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, "password", userModel)
+		ctx.SetPath("/api/v1/moderation/reviews")
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("?limit=10&page=1", &moderator)
+
+		list := ModerationList{Reviews: []ReportedReview{{ID: 1, ReportCount: 2}}, TotalCount: 1}
+		pagination := util.GeneratePagination(1, 10, 1, params.Path)
+		mockService.On("GetReportedReviewsWithPagination", params).Return(&list, &pagination, nil)
+
+		assert.NoError(t, h.GetReportedReviewsWithPagination(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		expectedResponseJSON, _ := json.Marshal(util.APIResponse{
+			Status:  http.StatusOK,
+			Message: "success",
+			Data: map[string]interface{}{
+				"reviews":    list.Reviews,
+				"pagination": pagination,
+			},
+		})
+		assert.Equal(t, string(expectedResponseJSON), strings.TrimSuffix(rec.Body.String(), "\n"))
+	})
+
+	t.Run("failed not moderator", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("", &user.Model{ID: 3, Status: util.StatusBusinessAdmin})
+
+		util.ErrorHandler(h.GetReportedReviewsWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("?limit=a", &moderator)
+
+		util.ErrorHandler(h.GetReportedReviewsWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("", &moderator)
+
+		mockService.On("GetReportedReviewsWithPagination", mock.Anything).Return(&ModerationList{}, &util.Pagination{}, errors.Wrap(ErrInternalServer, "test error"))
+
+		util.ErrorHandler(h.GetReportedReviewsWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_HideAndRestoreReview(t *testing.T) {
+	e := echo.New()
+	moderator := user.Model{ID: 9, Status: util.StatusModerator}
+
+	newRequest := func(reviewID string, userModel *user.Model) (echo.Context, *httptest.ResponseRecorder) {
+		httpReq := httptest.NewRequest(http.MethodPatch, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, "password", userModel)
+		ctx.SetPath("/api/v1/moderation/reviews/:reviewID/hide")
+		ctx.SetParamNames("reviewID")
+		ctx.SetParamValues(reviewID)
+		return ctx, rec
+	}
+
+	t.Run("success hide", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("1", &moderator)
+
+		mockService.On("UpdateVisibility", 1, true, 9).Return(nil)
+
+		assert.NoError(t, h.HideReview(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("success restore", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("1", &moderator)
+
+		mockService.On("UpdateVisibility", 1, false, 9).Return(nil)
+
+		assert.NoError(t, h.RestoreReview(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed not moderator", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("1", &user.Model{ID: 3, Status: util.StatusBusinessAdmin})
+
+		util.ErrorHandler(h.HideReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed review id is not number", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("a", &moderator)
+
+		util.ErrorHandler(h.HideReview(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("1", &moderator)
+
+		mockService.On("UpdateVisibility", 1, true, 9).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.HideReview(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_InsertBookingReviewOwnership(t *testing.T) {
+	e := echo.New()
+	body := `{"content": "Tempatnya nyaman", "rating": 5}`
+
+	newRequest := func(bookingID string, userModel *user.Model) (echo.Context, *httptest.ResponseRecorder) {
+		httpReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, httpReq, rec, "phone", userModel)
+		ctx.SetPath("/api/v1/booking/review/:bookingID")
+		ctx.SetParamNames("bookingID")
+		ctx.SetParamValues(bookingID)
+		return ctx, rec
+	}
+
+	t.Run("success booking id is taken from path", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("7", &user.Model{ID: 2})
+
+		mockService.On("InsertBookingReview", BookingReview{UserID: 2, BookingID: 7, Content: "Tempatnya nyaman", Rating: 5}).Return(nil)
+
+		assert.NoError(t, h.InsertBookingReview(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("failed booking of other customer", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("7", &user.Model{ID: 3})
+
+		mockService.On("InsertBookingReview", BookingReview{UserID: 3, BookingID: 7, Content: "Tempatnya nyaman", Rating: 5}).
+			Return(errors.Wrap(ErrForbidden, "Booking bukan milik user."))
+
+		util.ErrorHandler(h.InsertBookingReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed user is not registered", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("7", nil)
+
+		util.ErrorHandler(h.InsertBookingReview(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		mockService.AssertNotCalled(t, "InsertBookingReview", mock.Anything)
+	})
+
+	t.Run("failed booking id is not number", func(t *testing.T) {
+		h := NewHandler(new(MockService))
+		ctx, rec := newRequest("a", &user.Model{ID: 2})
+
+		util.ErrorHandler(h.InsertBookingReview(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed booking is already reviewed", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("7", &user.Model{ID: 2})
+
+		mockService.On("InsertBookingReview", mock.Anything).Return(errors.Wrap(ErrInputValidation, "Booking sudah direview."))
+
+		util.ErrorHandler(h.InsertBookingReview(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed booking not found", func(t *testing.T) {
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+		ctx, rec := newRequest("7", &user.Model{ID: 2})
+
+		mockService.On("InsertBookingReview", mock.Anything).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.InsertBookingReview(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -1,8 +1,14 @@
 package review
 
 import (
+	"database/sql"
+	"fmt"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // NewRepo PostgreSQL for checkup module
@@ -12,6 +18,9 @@ func NewRepo(db *sqlx.DB) Repo {
 	}
 }
 
+// uniqueViolation is the postgres error code when a unique constraint is violated
+const uniqueViolation = "23505"
+
 type repo struct {
 	db *sqlx.DB
 }
@@ -19,18 +28,183 @@ type repo struct {
 // Repo will contain all the function that can be used by repo
 type Repo interface {
 	InsertBookingReview(review BookingReview) error
-	RetrievePlaceID(bookingID int) (*int, error)
-	CheckBookingStatus(bookingID int) (bool, error)
-	UpdateBookingStatus(bookingID int) error
+	GetReviewedBooking(bookingID int) (*ReviewedBooking, error)
+	GetReview(reviewID int) (*Review, error)
+	UpdateReview(review UpdateReviewRequest) error
+	InsertReply(reviewID int, reply string) error
+	InsertReport(report Report) error
+	GetReportedReviewsWithPagination(params ModerationListRequest) (*ModerationList, error)
+	UpdateVisibility(reviewID int, isHidden bool, moderatorID int) error
 }
 
+// InsertBookingReview lock the booking so the ownership check, the status check, the insert of review and its photos, the place stats,
+// the status update and the reviewed event are atomic
 func (r repo) InsertBookingReview(review BookingReview) error {
-	query := `
-    INSERT INTO reviews (user_id, place_id, booking_id, content, rating)
-    VALUES (:user_id, :place_id, :booking_id, :content, :rating);
-	`
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+	defer tx.Rollback()
+
+	var booking ReviewedBooking
+	query := "SELECT user_id, place_id, status FROM bookings WHERE id = $1 FOR UPDATE"
+	err = tx.Get(&booking, query, review.BookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id %d is not found", review.BookingID))
+		}
+
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	err = checkReviewedBooking(booking, review.UserID)
+	if err != nil {
+		return err
+	}
+
+	var reviewID int
+	query = `INSERT INTO reviews (user_id, place_id, booking_id, content, rating, cleanliness_rating, service_rating, value_rating)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`
+	err = tx.Get(&reviewID, query, review.UserID, booking.PlaceID, review.BookingID, review.Content, review.Rating,
+		review.CleanlinessRating, review.ServiceRating, review.ValueRating)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return errors.Wrap(ErrInputValidation, "Booking sudah direview.")
+		}
+
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	if len(review.Photos) > 0 {
+		photos := make([]ReviewPhoto, 0, len(review.Photos))
+		for _, url := range review.Photos {
+			photos = append(photos, ReviewPhoto{ReviewID: reviewID, URL: url})
+		}
+
+		query = "INSERT INTO review_photos (review_id, url) VALUES (:review_id, :url)"
+		_, err = tx.NamedExec(query, photos)
+		if err != nil {
+			return errors.Wrap(ErrInternalServer, err.Error())
+		}
+	}
+
+	err = updatePlaceStats(tx, newPlaceStatsDelta(RatedReview{
+		PlaceID:           booking.PlaceID,
+		Rating:            review.Rating,
+		CleanlinessRating: review.CleanlinessRating,
+		ServiceRating:     review.ServiceRating,
+		ValueRating:       review.ValueRating,
+	}, 1))
+	if err != nil {
+		return err
+	}
+
+	query = "UPDATE bookings SET status = $1, updated_at = NOW() WHERE id = $2"
+	_, err = tx.Exec(query, util.BookingDireview, review.BookingID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	err = notification.InsertEvent(tx, notification.Event{Type: util.NotificationBookingReviewed, BookingID: review.BookingID})
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return nil
+}
+
+// GetReviewedBooking return the booking data to check whether it can be reviewed before the review photos are uploaded
+func (r repo) GetReviewedBooking(bookingID int) (*ReviewedBooking, error) {
+	var booking ReviewedBooking
+
+	query := "SELECT user_id, place_id, status FROM bookings WHERE id = $1"
+	err := r.db.Get(&booking, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id %d is not found", bookingID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &booking, nil
+}
+
+// checkReviewedBooking make sure the booking belongs to the user and is completed
+func checkReviewedBooking(booking ReviewedBooking, userID int) error {
+	if booking.UserID != userID {
+		return errors.Wrap(ErrForbidden, "Booking bukan milik user.")
+	}
+
+	if booking.Status != util.BookingSelesai {
+		return errors.Wrap(ErrInputValidation, "Forbidden entry: Wrong booking status")
+	}
+
+	return nil
+}
+
+func (r repo) GetReview(reviewID int) (*Review, error) {
+	var result Review
+
+	query := `SELECT r.id, r.user_id, r.place_id, p.user_id AS owner_id, r.booking_id, r.content, r.rating, r.reply, r.is_hidden, r.created_at
+			FROM reviews r
+			INNER JOIN places p ON r.place_id = p.id
+			WHERE r.id = $1`
+
+	err := r.db.Get(&result, query, reviewID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("review with id %d is not found", reviewID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &result, nil
+}
+
+// UpdateReview update the review and move the rating difference to the place stats in a single transaction
+func (r repo) UpdateReview(review UpdateReviewRequest) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+	defer tx.Rollback()
+
+	rated, err := getRatedReview(tx, review.ReviewID)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE reviews SET content = $1, rating = $2, cleanliness_rating = $3, service_rating = $4, value_rating = $5, updated_at = NOW()
+			WHERE id = $6 AND user_id = $7`
+	_, err = tx.Exec(query, review.Content, review.Rating, review.CleanlinessRating, review.ServiceRating, review.ValueRating,
+		review.ReviewID, review.UserID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
 
-	_, err := r.db.NamedExec(query, review)
+	delta := sumPlaceStatsDelta(newPlaceStatsDelta(*rated, -1), newPlaceStatsDelta(RatedReview{
+		PlaceID:           rated.PlaceID,
+		Rating:            review.Rating,
+		CleanlinessRating: review.CleanlinessRating,
+		ServiceRating:     review.ServiceRating,
+		ValueRating:       review.ValueRating,
+	}, 1))
+	if !rated.IsHidden && delta != (PlaceStatsDelta{PlaceID: rated.PlaceID}) {
+		err = updatePlaceStats(tx, delta)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return errors.Wrap(ErrInternalServer, err.Error())
 	}
@@ -38,46 +212,193 @@ func (r repo) InsertBookingReview(review BookingReview) error {
 	return nil
 }
 
-func (r repo) RetrievePlaceID(bookingID int) (*int, error) {
-	query := `
-    SELECT place_id FROM bookings WHERE id=$1 LIMIT 1;
-	`
+func (r repo) InsertReply(reviewID int, reply string) error {
+	query := "UPDATE reviews SET reply = $1, replied_at = NOW() WHERE id = $2 AND reply IS NULL"
+
+	result, err := r.db.Exec(query, reply, reviewID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrInputValidation, "review is already replied")
+	}
+
+	return nil
+}
+
+func (r repo) InsertReport(report Report) error {
+	query := `INSERT INTO review_reports (review_id, user_id, reason) VALUES ($1, $2, $3)
+			ON CONFLICT (review_id, user_id) DO NOTHING`
+
+	result, err := r.db.Exec(query, report.ReviewID, report.UserID, report.Reason)
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrInputValidation, "review is already reported")
+	}
+
+	return nil
+}
+
+func (r repo) GetReportedReviewsWithPagination(params ModerationListRequest) (*ModerationList, error) {
+	var list ModerationList
+	list.Reviews = make([]ReportedReview, 0)
+
+	query := `SELECT r.id, r.place_id, r.content, r.rating, r.is_hidden, COUNT(rr.id) AS report_count,
+				string_agg(rr.reason, '; ' ORDER BY rr.created_at) AS reasons, MAX(rr.created_at) AS last_reported_at
+			FROM reviews r
+			INNER JOIN review_reports rr ON rr.review_id = r.id
+			WHERE rr.status = $1
+			GROUP BY r.id
+			ORDER BY report_count DESC, last_reported_at DESC
+			LIMIT $2 OFFSET $3`
+
+	err := r.db.Select(&list.Reviews, query, util.ReviewReportOpen, params.Limit, (params.Page-1)*params.Limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	query = "SELECT COUNT(DISTINCT review_id) FROM review_reports WHERE status = $1"
+	err = r.db.Get(&list.TotalCount, query, util.ReviewReportOpen)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &list, nil
+}
+
+// UpdateVisibility hide or restore the review, update the place stats and resolve its open reports in a single transaction
+func (r repo) UpdateVisibility(reviewID int, isHidden bool, moderatorID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+	defer tx.Rollback()
 
-	var placeID int
-	err := r.db.Get(&placeID, query, bookingID)
+	rated, err := getRatedReview(tx, reviewID)
+	if err != nil {
+		return err
+	}
 
+	query := "UPDATE reviews SET is_hidden = $1, moderated_by = $2, moderated_at = NOW() WHERE id = $3"
+	_, err = tx.Exec(query, isHidden, moderatorID, reviewID)
 	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	if rated.IsHidden != isHidden {
+		sign := 1
+		if isHidden {
+			sign = -1
+		}
+
+		err = updatePlaceStats(tx, newPlaceStatsDelta(*rated, sign))
+		if err != nil {
+			return err
+		}
+	}
+
+	query = "UPDATE review_reports SET status = $1, resolved_at = NOW(), updated_at = NOW() WHERE review_id = $2 AND status = $3"
+	_, err = tx.Exec(query, util.ReviewReportResolved, reviewID, util.ReviewReportOpen)
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return nil
+}
+
+// getRatedReview lock the review so its rating is not changed until the transaction is done
+func getRatedReview(tx *sqlx.Tx, reviewID int) (*RatedReview, error) {
+	var result RatedReview
+
+	query := "SELECT place_id, rating, cleanliness_rating, service_rating, value_rating, is_hidden FROM reviews WHERE id = $1 FOR UPDATE"
+	err := tx.Get(&result, query, reviewID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("review with id %d is not found", reviewID))
+		}
+
 		return nil, errors.Wrap(ErrInternalServer, err.Error())
 	}
-	return &placeID, nil
+
+	return &result, nil
 }
 
-func (r repo) CheckBookingStatus(bookingID int) (bool, error) {
-	query := `
-    SELECT status FROM bookings WHERE id=$1 LIMIT 1;
-	`
-	var bookingStatus int
-	err := r.db.Get(&bookingStatus, query, bookingID)
+// newPlaceStatsDelta return the change of place stats when the review is added, sign is -1 when the review is removed
+func newPlaceStatsDelta(review RatedReview, sign int) PlaceStatsDelta {
+	delta := PlaceStatsDelta{
+		PlaceID:     review.PlaceID,
+		ReviewCount: sign,
+		RatingSum:   sign * review.Rating,
+	}
 
-	if err != nil {		
-		return false, errors.Wrap(ErrInputValidation, "Booking tidak ditemukan")
+	if review.CleanlinessRating != nil {
+		delta.CleanlinessSum = sign * *review.CleanlinessRating
+		delta.CleanlinessCount = sign
 	}
 
-	if bookingStatus != 3 {
-		return false, nil
+	if review.ServiceRating != nil {
+		delta.ServiceSum = sign * *review.ServiceRating
+		delta.ServiceCount = sign
 	}
 
-	return true, nil
+	if review.ValueRating != nil {
+		delta.ValueSum = sign * *review.ValueRating
+		delta.ValueCount = sign
+	}
+
+	return delta
+}
+
+// sumPlaceStatsDelta return the change of place stats of both deltas, both must be of the same place
+func sumPlaceStatsDelta(a, b PlaceStatsDelta) PlaceStatsDelta {
+	return PlaceStatsDelta{
+		PlaceID:          a.PlaceID,
+		ReviewCount:      a.ReviewCount + b.ReviewCount,
+		RatingSum:        a.RatingSum + b.RatingSum,
+		CleanlinessSum:   a.CleanlinessSum + b.CleanlinessSum,
+		CleanlinessCount: a.CleanlinessCount + b.CleanlinessCount,
+		ServiceSum:       a.ServiceSum + b.ServiceSum,
+		ServiceCount:     a.ServiceCount + b.ServiceCount,
+		ValueSum:         a.ValueSum + b.ValueSum,
+		ValueCount:       a.ValueCount + b.ValueCount,
+	}
 }
 
-func (r repo) UpdateBookingStatus(bookingID int) error {
-	query := `
-    UPDATE bookings
-	SET status = 5
-	WHERE id = $1
-	`
+// updatePlaceStats add the delta to the stats of the place, the stats row is created when it is not exist yet
+func updatePlaceStats(tx *sqlx.Tx, delta PlaceStatsDelta) error {
+	query := `INSERT INTO place_stats (place_id, review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count)
+			VALUES (:place_id, :review_count, :rating_sum, :cleanliness_sum, :cleanliness_count, :service_sum, :service_count, :value_sum, :value_count)
+			ON CONFLICT (place_id) DO UPDATE SET
+				review_count = place_stats.review_count + EXCLUDED.review_count,
+				rating_sum = place_stats.rating_sum + EXCLUDED.rating_sum,
+				cleanliness_sum = place_stats.cleanliness_sum + EXCLUDED.cleanliness_sum,
+				cleanliness_count = place_stats.cleanliness_count + EXCLUDED.cleanliness_count,
+				service_sum = place_stats.service_sum + EXCLUDED.service_sum,
+				service_count = place_stats.service_count + EXCLUDED.service_count,
+				value_sum = place_stats.value_sum + EXCLUDED.value_sum,
+				value_count = place_stats.value_count + EXCLUDED.value_count,
+				updated_at = NOW()`
 
-	_, err := r.db.Exec(query, bookingID)
+	_, err := tx.NamedExec(query, delta)
 	if err != nil {
 		return errors.Wrap(ErrInternalServer, err.Error())
 	}
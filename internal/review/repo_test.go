@@ -2,240 +2,843 @@ package review
 
 import (
 	"database/sql"
-	"database/sql/driver"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 func TestRepo_InsertBookingReview(t *testing.T) {
-	mockDB, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer mockDB.Close()
-	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	repoMock := NewRepo(sqlxDB)
-
-	t.Run("Successfully insert review", func(t *testing.T) {
-		userID := 1
-		placeID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
-
-		review := BookingReview{
-			UserID		: userID,
-			PlaceID		: placeID,
-			BookingID	: bookingID,
-			Content		: content,
-			Rating		: rating,
-		}
-
-		query := `
-		INSERT INTO reviews (user_id, place_id, booking_id, content, rating)
-		VALUES (?, ?, ?, ?, ?);
-		`
-
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(
-			review.UserID, review.PlaceID, review.BookingID, review.Content, review.Rating).
-			WillReturnResult(driver.ResultNoRows)
-
-		err := repoMock.InsertBookingReview(review)
+	bookingQuery := "SELECT user_id, place_id, status FROM bookings WHERE id = $1 FOR UPDATE"
+	insertQuery := `INSERT INTO reviews (user_id, place_id, booking_id, content, rating, cleanliness_rating, service_rating, value_rating)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id`
+	photoQuery := "INSERT INTO review_photos (review_id, url) VALUES (?, ?),(?, ?)"
+	updateQuery := "UPDATE bookings SET status = $1, updated_at = NOW() WHERE id = $2"
+	eventQuery := "INSERT INTO notification_events (type, booking_id, user_id, amount)"
+	statsQuery := "INSERT INTO place_stats (place_id, review_count, rating_sum, cleanliness_sum, cleanliness_count, service_sum, service_count, value_sum, value_count)"
+	review := BookingReview{UserID: 1, BookingID: 2, Content: "bagus", Rating: 5}
+	bookingColumns := []string{"user_id", "place_id", "status"}
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WithArgs(1, 3, 2, "bagus", 5, nil, nil, nil).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3, 1, 5, 0, 0, 0, 0, 0, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(util.BookingDireview, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(eventQuery)).WithArgs(util.NotificationBookingReviewed, 2, 0, float64(0)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.InsertBookingReview(review))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success with photos and sub ratings", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		cleanliness := 4
+		reviewWithPhotos := review
+		reviewWithPhotos.CleanlinessRating = &cleanliness
+		reviewWithPhotos.Photos = []string{"https://cdn/1.png", "https://cdn/2.png"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WithArgs(1, 3, 2, "bagus", 5, 4, nil, nil).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta(photoQuery)).WithArgs(10, "https://cdn/1.png", 10, "https://cdn/2.png").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3, 1, 5, 4, 1, 0, 0, 0, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs(util.BookingDireview, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(eventQuery)).WithArgs(util.NotificationBookingReviewed, 2, 0, float64(0)).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.InsertBookingReview(reviewWithPhotos))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed insert photos", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		reviewWithPhotos := review
+		reviewWithPhotos.Photos = []string{"https://cdn/1.png"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO review_photos (review_id, url)")).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(reviewWithPhotos)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed booking not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed booking of other user", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(4, 3, util.BookingSelesai))
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed wrong booking status", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingDireview))
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed booking is already reviewed", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WillReturnError(&pq.Error{Code: uniqueViolation})
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed insert review", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed update place stats", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed update booking status", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed insert reviewed event", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(bookingQuery)).WithArgs(2).
+			WillReturnRows(mock.NewRows(bookingColumns).AddRow(1, 3, util.BookingSelesai))
+		mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WillReturnRows(mock.NewRows([]string{"id"}).AddRow(10))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(eventQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed begin transaction", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+
+		err = repoMock.InsertBookingReview(review)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetReviewedBooking(t *testing.T) {
+	query := "SELECT user_id, place_id, status FROM bookings WHERE id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).
+			WillReturnRows(mock.NewRows([]string{"user_id", "place_id", "status"}).AddRow(2, 3, util.BookingSelesai))
+
+		result, err := repoMock.GetReviewedBooking(1)
 		assert.Nil(t, err)
+		assert.Equal(t, &ReviewedBooking{UserID: 2, PlaceID: 3, Status: util.BookingSelesai}, result)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err = repoMock.GetReviewedBooking(1)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		_, err = repoMock.GetReviewedBooking(1)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetReview(t *testing.T) {
+	query := "SELECT r.id, r.user_id, r.place_id, p.user_id AS owner_id, r.booking_id, r.content, r.rating, r.reply, r.is_hidden, r.created_at FROM reviews r INNER JOIN places p ON r.place_id = p.id WHERE r.id = $1"
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).
+			WillReturnRows(mock.NewRows([]string{"id", "user_id", "place_id", "owner_id", "booking_id", "content", "rating", "reply", "is_hidden", "created_at"}).
+				AddRow(1, 2, 3, 4, 5, "bagus", 5, nil, false, now))
+
+		result, err := repoMock.GetReview(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &Review{ID: 1, UserID: 2, PlaceID: 3, OwnerID: 4, BookingID: 5, Content: "bagus", Rating: 5, CreatedAt: now}, result)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetReview(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetReview(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdateReview(t *testing.T) {
+	ratedQuery := "SELECT place_id, rating, cleanliness_rating, service_rating, value_rating, is_hidden FROM reviews WHERE id = $1 FOR UPDATE"
+	query := "UPDATE reviews SET content = $1, rating = $2, cleanliness_rating = $3, service_rating = $4, value_rating = $5, updated_at = NOW()"
+	statsQuery := "INSERT INTO place_stats (place_id, review_count, rating_sum"
+	ratedColumns := []string{"place_id", "rating", "cleanliness_rating", "service_rating", "value_rating", "is_hidden"}
+	req := UpdateReviewRequest{ReviewID: 1, UserID: 2, Content: "bagus", Rating: 4}
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 2, nil, nil, nil, false))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("bagus", 4, nil, nil, nil, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3, 0, 2, 0, 0, 0, 0, 0, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdateReview(req))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success sub-ratings are replaced", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+		cleanliness, service := 5, 2
+		req := UpdateReviewRequest{ReviewID: 1, UserID: 2, Content: "bagus", Rating: 4, CleanlinessRating: &cleanliness, ServiceRating: &service}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 4, 3, nil, 4, false))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("bagus", 4, &cleanliness, &service, nil, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3, 0, 0, 2, 0, 2, 1, -4, -1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdateReview(req))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success unchanged rating does not update stats", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 4, nil, nil, nil, false))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("bagus", 4, nil, nil, nil, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdateReview(req))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success hidden review is not counted", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 2, nil, nil, nil, true))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("bagus", 4, nil, nil, nil, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdateReview(req))
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("Internal server error while inserting review", func(t *testing.T) {
-		userID := 1
-		placeID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		review := BookingReview{
-			UserID		: userID,
-			PlaceID		: placeID,
-			BookingID	: bookingID,
-			Content		: content,
-			Rating		: rating,
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = repoMock.UpdateReview(req)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
+		defer mockDB.Close()
 
-		query := `
-		INSERT INTO reviews (user_id, place_id, booking_id, content, rating)
-		VALUES (?, ?, ?, ?, ?);
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(
-			review.UserID, review.PlaceID, review.BookingID, review.Content, review.Rating).
-			WillReturnError(sql.ErrTxDone)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 2, nil, nil, nil, false))
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("bagus", 4, nil, nil, nil, 1, 2).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
 
-		err := repoMock.InsertBookingReview(review)
-		assert.NotNil(t, err)
+		err = repoMock.UpdateReview(req)
 		assert.Equal(t, ErrInternalServer, errors.Cause(err))
 	})
 }
 
-func TestRepo_RetrievePlaceID(t *testing.T) {
-	mockDB, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer mockDB.Close()
-	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	repoMock := NewRepo(sqlxDB)
+func TestRepo_InsertReply(t *testing.T) {
+	query := "UPDATE reviews SET reply = $1, replied_at = NOW() WHERE id = $2 AND reply IS NULL"
 
-	t.Run("Successfully retrieve place ID", func(t *testing.T) {
-		bookingID := 1
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		query := `
-		SELECT place_id FROM bookings WHERE id=$1 LIMIT 1;
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.
-			NewRows([]string{"place_id"}).
-			AddRow(5)
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("terima kasih", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 
-		placeID, err := repoMock.RetrievePlaceID(1)
-		assert.NoError(t, err)
-		assert.Equal(t, 5, *placeID)
+		assert.Nil(t, repoMock.InsertReply(1, "terima kasih"))
 	})
 
-	t.Run("No place ID to retrieve", func(t *testing.T) {
-		bookingID := 1
+	t.Run("failed already replied", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		query := `
-		SELECT place_id FROM bookings WHERE id=$1 LIMIT 1;
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.
-			NewRows([]string{"place_id"})
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("terima kasih", 1).WillReturnResult(sqlmock.NewResult(0, 0))
 
-		placeID, err := repoMock.RetrievePlaceID(1)
+		err = repoMock.InsertReply(1, "terima kasih")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("terima kasih", 1).WillReturnError(sql.ErrConnDone)
+
+		err = repoMock.InsertReply(1, "terima kasih")
 		assert.Equal(t, ErrInternalServer, errors.Cause(err))
-		assert.Nil(t, placeID)
 	})
 }
 
-func TestRepo_CheckBookingStatus(t *testing.T) {
-	mockDB, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer mockDB.Close()
-	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	repoMock := NewRepo(sqlxDB)
+func TestRepo_InsertReport(t *testing.T) {
+	query := "INSERT INTO review_reports (review_id, user_id, reason) VALUES ($1, $2, $3) ON CONFLICT (review_id, user_id) DO NOTHING"
+	report := Report{ReviewID: 1, UserID: 2, Reason: "spam"}
 
-	t.Run("Booking is eligible to be reviewed", func(t *testing.T) {
-		bookingID := 1
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		query := `
-		SELECT status FROM bookings WHERE id=$1 LIMIT 1;
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.
-			NewRows([]string{"status"}).
-			AddRow(3)
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, 2, "spam").WillReturnResult(sqlmock.NewResult(1, 1))
 
-		isEligible, err := repoMock.CheckBookingStatus(bookingID)
-		assert.NoError(t, err)
-		assert.Equal(t, true, isEligible)
+		assert.Nil(t, repoMock.InsertReport(report))
 	})
 
-	t.Run("No booking to check", func(t *testing.T) {
-		bookingID := 1
+	t.Run("failed already reported", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		query := `
-		SELECT status FROM bookings WHERE id=$1 LIMIT 1;
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.
-			NewRows([]string{"status"})
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, 2, "spam").WillReturnResult(sqlmock.NewResult(0, 0))
 
-		isEligible, err := repoMock.CheckBookingStatus(bookingID)
+		err = repoMock.InsertReport(report)
 		assert.Equal(t, ErrInputValidation, errors.Cause(err))
-		assert.Equal(t, false, isEligible)
 	})
 
-	t.Run("Booking is not eligible to be reviewed", func(t *testing.T) {
-		bookingID := 1
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		query := `
-		SELECT status FROM bookings WHERE id=$1 LIMIT 1;
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		rows := mock.
-			NewRows([]string{"status"}).
-			AddRow(4)
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnRows(rows)
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(1, 2, "spam").WillReturnError(sql.ErrConnDone)
 
-		isEligible, err := repoMock.CheckBookingStatus(bookingID)
-		assert.NoError(t, errors.Cause(err))
-		assert.Equal(t, false, isEligible)
+		err = repoMock.InsertReport(report)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
 	})
 }
 
-func TestRepo_UpdateBookingStatus(t *testing.T) {
-	mockDB, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer mockDB.Close()
-	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
-	repoMock := NewRepo(sqlxDB)
+func TestRepo_GetReportedReviewsWithPagination(t *testing.T) {
+	query := "FROM reviews r INNER JOIN review_reports rr ON rr.review_id = r.id WHERE rr.status = $1 GROUP BY r.id ORDER BY report_count DESC, last_reported_at DESC LIMIT $2 OFFSET $3"
+	countQuery := "SELECT COUNT(DISTINCT review_id) FROM review_reports WHERE status = $1"
+	params := ModerationListRequest{Limit: 10, Page: 1}
+	now := time.Now()
 
-	t.Run("Booking status is successfully updated", func(t *testing.T) {
-		bookingID := 1
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		query := `
-		UPDATE bookings
-		SET status = 5
-		WHERE id = $1
-		`
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		mock.NewRows([]string{"status"}).AddRow(3)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.ReviewReportOpen, 10, 0).
+			WillReturnRows(mock.NewRows([]string{"id", "place_id", "content", "rating", "is_hidden", "report_count", "reasons", "last_reported_at"}).
+				AddRow(1, 3, "kasar", 1, false, 2, "spam; kasar", now))
+		mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(util.ReviewReportOpen).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnResult(sqlmock.NewResult(int64(bookingID), 1))
+		result, err := repoMock.GetReportedReviewsWithPagination(params)
+		assert.Nil(t, err)
+		assert.Equal(t, &ModerationList{
+			Reviews:    []ReportedReview{{ID: 1, PlaceID: 3, Content: "kasar", Rating: 1, ReportCount: 2, Reasons: "spam; kasar", LastReportedAt: now}},
+			TotalCount: 1,
+		}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		err := repoMock.UpdateBookingStatus(bookingID)
-		assert.NoError(t, err)
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.ReviewReportOpen, 10, 0).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetReportedReviewsWithPagination(params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
 	})
 
-	t.Run("Internal server error", func(t *testing.T) {
-		bookingID := 1
+	t.Run("failed count internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.ReviewReportOpen, 10, 0).
+			WillReturnRows(mock.NewRows([]string{"id"}))
+		mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(util.ReviewReportOpen).WillReturnError(sql.ErrConnDone)
 
-		query := `
-		UPDATE bookings
-		SET status = 5
-		WHERE id = $1
-		`
+		result, err := repoMock.GetReportedReviewsWithPagination(params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdateVisibility(t *testing.T) {
+	ratedQuery := "SELECT place_id, rating, cleanliness_rating, service_rating, value_rating, is_hidden FROM reviews WHERE id = $1 FOR UPDATE"
+	reviewQuery := "UPDATE reviews SET is_hidden = $1, moderated_by = $2, moderated_at = NOW() WHERE id = $3"
+	statsQuery := "INSERT INTO place_stats (place_id, review_count, rating_sum"
+	reportQuery := "UPDATE review_reports SET status = $1, resolved_at = NOW(), updated_at = NOW() WHERE review_id = $2 AND status = $3"
+	ratedColumns := []string{"place_id", "rating", "cleanliness_rating", "service_rating", "value_rating", "is_hidden"}
+
+	t.Run("success hide", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
 
-		mock.NewRows([]string{"status"})
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
 
-		mock.ExpectExec(regexp.QuoteMeta(query)).
-			WithArgs(bookingID).
-			WillReturnError(ErrInternalServer)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 1, 2, nil, nil, false))
+		mock.ExpectExec(regexp.QuoteMeta(reviewQuery)).WithArgs(true, 9, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3, -1, -1, -2, -1, 0, 0, 0, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(reportQuery)).WithArgs(util.ReviewReportResolved, 1, util.ReviewReportOpen).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
 
-		err := repoMock.UpdateBookingStatus(bookingID)
+		assert.Nil(t, repoMock.UpdateVisibility(1, true, 9))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success restore", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 1, nil, nil, 5, true))
+		mock.ExpectExec(regexp.QuoteMeta(reviewQuery)).WithArgs(false, 9, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WithArgs(3, 1, 1, 0, 0, 0, 0, 5, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(reportQuery)).WithArgs(util.ReviewReportResolved, 1, util.ReviewReportOpen).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdateVisibility(1, false, 9))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success already hidden is not counted twice", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 1, nil, nil, nil, true))
+		mock.ExpectExec(regexp.QuoteMeta(reviewQuery)).WithArgs(true, 9, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(reportQuery)).WithArgs(util.ReviewReportResolved, 1, util.ReviewReportOpen).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.Nil(t, repoMock.UpdateVisibility(1, true, 9))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = repoMock.UpdateVisibility(1, false, 9)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed update place stats", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 1, nil, nil, nil, false))
+		mock.ExpectExec(regexp.QuoteMeta(reviewQuery)).WithArgs(true, 9, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(statsQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.UpdateVisibility(1, true, 9)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed resolve report", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(ratedQuery)).WithArgs(1).WillReturnRows(mock.NewRows(ratedColumns).AddRow(3, 1, nil, nil, nil, true))
+		mock.ExpectExec(regexp.QuoteMeta(reviewQuery)).WithArgs(true, 9, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(reportQuery)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		err = repoMock.UpdateVisibility(1, true, 9)
 		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
-}
\ No newline at end of file
+
+	t.Run("failed begin transaction", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+
+		sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+		repoMock := NewRepo(sqlxDB)
+
+		mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+
+		err = repoMock.UpdateVisibility(1, true, 9)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
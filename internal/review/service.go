@@ -1,71 +1,229 @@
 package review
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/cloudinary"
 	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // NewService for initialize service
-func NewService(repo Repo) Service {
+func NewService(repo Repo, notificationService notification.Notifier, cloudinary cloudinary.Repo) Service {
 	return &service{
-		repo: repo,
+		repo:                repo,
+		notificationService: notificationService,
+		cloudinary:          cloudinary,
 	}
 }
 
 // Service will contain all the function that can be used by service
 type Service interface {
 	InsertBookingReview(review BookingReview) error
+	UpdateReview(req UpdateReviewRequest) error
+	ReplyReview(req ReplyRequest) error
+	ReportReview(report Report) error
+	GetReportedReviewsWithPagination(params ModerationListRequest) (*ModerationList, *util.Pagination, error)
+	UpdateVisibility(reviewID int, isHidden bool, moderatorID int) error
 }
 
 type service struct {
-	repo Repo
+	repo                Repo
+	notificationService notification.Notifier
+	cloudinary          cloudinary.Repo
 }
 
 func (s service) InsertBookingReview(review BookingReview) error {
-	isEligible, err := s.repo.CheckBookingStatus(review.BookingID)
+	if review.BookingID <= 0 {
+		return errors.Wrap(ErrInputValidation, "bookingID must be above 0")
+	}
+
+	errorList := validateReview(review.Content, review.Rating)
+	errorList = append(errorList, validateSubRating("Cleanliness", review.CleanlinessRating)...)
+	errorList = append(errorList, validateSubRating("Service", review.ServiceRating)...)
+	errorList = append(errorList, validateSubRating("Value", review.ValueRating)...)
+
+	if len(review.Photos) > util.MaximumReviewPhotos {
+		errorList = append(errorList, fmt.Sprintf("Foto melebihi %d buah.", util.MaximumReviewPhotos))
+	}
+
+	for _, photo := range review.Photos {
+		if photo == "" {
+			errorList = append(errorList, "Foto tidak boleh kosong.")
+			break
+		}
+	}
+
+	if len(errorList) > 0 {
+		return errors.Wrap(ErrInputValidation, strings.Join(errorList, ";"))
+	}
+
+	// the booking is checked before the photos are uploaded, it is checked again when the review is inserted
+	if len(review.Photos) > 0 {
+		booking, err := s.repo.GetReviewedBooking(review.BookingID)
+		if err != nil {
+			return err
+		}
+
+		err = checkReviewedBooking(*booking, review.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the public ID is left to cloudinary so the photo URL can not be guessed from the booking ID
+	for i, photo := range review.Photos {
+		url, err := s.cloudinary.UploadFile(photo, "Review Photo", "")
+		if err != nil {
+			return err
+		}
+		review.Photos[i] = url
+	}
+
+	err := s.repo.InsertBookingReview(review)
 	if err != nil {
 		return err
 	}
-	if !isEligible {
-		return errors.Wrap(ErrInputValidation, "Forbidden entry: Wrong booking status")
-	}
 
+	s.notificationService.Notify(notification.Event{Type: util.NotificationBookingReviewed, BookingID: review.BookingID})
+	return nil
+}
+
+func validateReview(content string, rating int) []string {
 	var errorList []string
 
-	if len([]rune(review.Content)) > 500 {
+	if len([]rune(content)) > util.MaximumReviewLength {
 		errorList = append(errorList, "Review melebihi 500 karakter.")
 	}
 
-	if review.Rating < util.MinimumRatingValue {
+	if rating < util.MinimumRatingValue {
 		errorList = append(errorList, "Rating invalid. Minimum rating adalah 1.")
 	}
 
-	if review.Rating > util.MaximumRatingValue {
+	if rating > util.MaximumRatingValue {
 		errorList = append(errorList, "Rating invalid. Maksimum rating adalah 5.")
 	}
 
+	return errorList
+}
+
+// validateSubRating validate the optional rating of an aspect, nil means the aspect is not rated
+func validateSubRating(aspect string, rating *int) []string {
+	if rating == nil {
+		return nil
+	}
+
+	if *rating < util.MinimumRatingValue || *rating > util.MaximumRatingValue {
+		return []string{fmt.Sprintf("Rating %s invalid. Rating harus 1 - 5.", aspect)}
+	}
+
+	return nil
+}
+
+func (s service) UpdateReview(req UpdateReviewRequest) error {
+	errorList := validateReview(req.Content, req.Rating)
+	errorList = append(errorList, validateSubRating("Cleanliness", req.CleanlinessRating)...)
+	errorList = append(errorList, validateSubRating("Service", req.ServiceRating)...)
+	errorList = append(errorList, validateSubRating("Value", req.ValueRating)...)
 	if len(errorList) > 0 {
 		return errors.Wrap(ErrInputValidation, strings.Join(errorList, ";"))
 	}
 
-	placeID, err := s.repo.RetrievePlaceID(review.BookingID)
+	review, err := s.repo.GetReview(req.ReviewID)
 	if err != nil {
 		return err
 	}
-	review.PlaceID = *placeID	
 
-	err = s.repo.InsertBookingReview(review)
+	if review.UserID != req.UserID {
+		return errors.Wrap(ErrForbidden, "Review bukan milik user.")
+	}
+
+	if time.Since(review.CreatedAt) > util.ReviewEditWindowDays*24*time.Hour {
+		return errors.Wrap(ErrInputValidation, fmt.Sprintf("Review hanya dapat diubah dalam %d hari.", util.ReviewEditWindowDays))
+	}
+
+	return s.repo.UpdateReview(req)
+}
+
+func (s service) ReplyReview(req ReplyRequest) error {
+	req.Reply = strings.TrimSpace(req.Reply)
+	if req.Reply == "" {
+		return errors.Wrap(ErrInputValidation, "Balasan tidak boleh kosong.")
+	}
+
+	if len([]rune(req.Reply)) > util.MaximumReviewLength {
+		return errors.Wrap(ErrInputValidation, "Balasan melebihi 500 karakter.")
+	}
+
+	review, err := s.repo.GetReview(req.ReviewID)
 	if err != nil {
 		return err
 	}
 
-	err = s.repo.UpdateBookingStatus(review.BookingID)
+	if review.OwnerID != req.UserID {
+		return errors.Wrap(ErrForbidden, "Review bukan milik tempat user.")
+	}
+
+	return s.repo.InsertReply(req.ReviewID, req.Reply)
+}
+
+func (s service) ReportReview(report Report) error {
+	report.Reason = strings.TrimSpace(report.Reason)
+	if report.Reason == "" {
+		return errors.Wrap(ErrInputValidation, "Alasan laporan tidak boleh kosong.")
+	}
+
+	if len([]rune(report.Reason)) > util.MaximumReviewLength {
+		return errors.Wrap(ErrInputValidation, "Alasan laporan melebihi 500 karakter.")
+	}
+
+	_, err := s.repo.GetReview(report.ReviewID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.repo.InsertReport(report)
+}
+
+func (s service) GetReportedReviewsWithPagination(params ModerationListRequest) (*ModerationList, *util.Pagination, error) {
+	var errorList []string
+
+	if params.Page <= 0 {
+		params.Page = util.DefaultPage
+	}
 
-}
\ No newline at end of file
+	if params.Limit <= 0 {
+		params.Limit = util.DefaultLimit
+	}
+
+	if params.Limit > util.MaxLimit {
+		errorList = append(errorList, "limit should be 1 - 100")
+	}
+
+	if params.Path == "" {
+		errorList = append(errorList, "path is required for pagination")
+	}
+
+	if len(errorList) > 0 {
+		return nil, nil, errors.Wrap(ErrInputValidation, strings.Join(errorList, ";"))
+	}
+
+	list, err := s.repo.GetReportedReviewsWithPagination(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := util.GeneratePagination(list.TotalCount, params.Limit, params.Page, params.Path)
+	return list, &pagination, nil
+}
+
+func (s service) UpdateVisibility(reviewID int, isHidden bool, moderatorID int) error {
+	if reviewID <= 0 {
+		return errors.Wrap(ErrInputValidation, "reviewID must be above 0")
+	}
+
+	return s.repo.UpdateVisibility(reviewID, isHidden, moderatorID)
+}
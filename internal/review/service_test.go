@@ -3,10 +3,14 @@ package review
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/cloudinary"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 type MockRepository struct {
@@ -18,253 +22,531 @@ func (m *MockRepository) InsertBookingReview(review BookingReview) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) RetrievePlaceID(bookingID int) (*int, error) {
+func (m *MockRepository) GetReviewedBooking(bookingID int) (*ReviewedBooking, error) {
 	args := m.Called(bookingID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*int), args.Error(1)
+	return args.Get(0).(*ReviewedBooking), args.Error(1)
 }
 
-func (m *MockRepository) CheckBookingStatus(bookingID int) (bool, error) {
-	args := m.Called(bookingID)
-	if args.Get(1) != nil {
-		return false, args.Error(1)
-	}
-	return args.Get(0).(bool), args.Error(1)
+func (m *MockRepository) GetReview(reviewID int) (*Review, error) {
+	args := m.Called(reviewID)
+	return args.Get(0).(*Review), args.Error(1)
 }
 
-func (m *MockRepository) UpdateBookingStatus(bookingID int) error {
-	args := m.Called(bookingID)
+func (m *MockRepository) UpdateReview(review UpdateReviewRequest) error {
+	args := m.Called(review)
+	return args.Error(0)
+}
+
+func (m *MockRepository) InsertReply(reviewID int, reply string) error {
+	args := m.Called(reviewID, reply)
 	return args.Error(0)
 }
 
+func (m *MockRepository) InsertReport(report Report) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetReportedReviewsWithPagination(params ModerationListRequest) (*ModerationList, error) {
+	args := m.Called(params)
+	return args.Get(0).(*ModerationList), args.Error(1)
+}
+
+func (m *MockRepository) UpdateVisibility(reviewID int, isHidden bool, moderatorID int) error {
+	args := m.Called(reviewID, isHidden, moderatorID)
+	return args.Error(0)
+}
+
+type MockCloudinary struct {
+	mock.Mock
+}
+
+func (mc *MockCloudinary) UploadFile(fileContent, folderName, fileName string) (string, error) {
+	args := mc.Called(fileContent, folderName, fileName)
+	return args.String(0), args.Error(1)
+}
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+// newMockNotificationService accept every notification, assert on a new MockNotificationService to check the event
+func newMockNotificationService() *MockNotificationService {
+	mockNotification := new(MockNotificationService)
+	mockNotification.On("Notify", mock.Anything).Maybe()
+	return mockNotification
+}
+
+func (m *MockNotificationService) Notify(event notification.Event) {
+	m.Called(event)
+}
+
 func TestService_InsertBookingReview(t *testing.T) {
 	t.Run("Insert booking review done successfully", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
-
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    1,
+			BookingID: 1,
+			Content:   "",
+			Rating:    5,
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
-		mockRepo.On("RetrievePlaceID", review.BookingID).Return(placeID, nil)
+		mockNotification := new(MockNotificationService)
+		mockService := NewService(mockRepo, mockNotification, new(MockCloudinary))
 		mockRepo.On("InsertBookingReview", review).Return(nil)
-		mockRepo.On("UpdateBookingStatus", review.BookingID).Return(nil)
+		mockNotification.On("Notify", notification.Event{Type: util.NotificationBookingReviewed, BookingID: review.BookingID})
 		err := mockService.InsertBookingReview(review)
 
 		mockRepo.AssertExpectations(t)
+		mockNotification.AssertExpectations(t)
 		assert.NoError(t, err)
 	})
 
-	t.Run("Internal server error from CheckBookingStatus", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 100
-		content := ""
-		rating := 5
+	t.Run("Booking ID input validation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+		err := mockService.InsertBookingReview(BookingReview{UserID: 1, Rating: 5})
 
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "InsertBookingReview", mock.Anything)
+	})
+
+	t.Run("Exceeded review content input validation", func(t *testing.T) {
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    1,
+			BookingID: 1,
+			Content:   strings.Repeat("Test Review", 100),
+			Rating:    5,
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(false, ErrInternalServer)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
 		err := mockService.InsertBookingReview(review)
 
-		mockRepo.AssertExpectations(t)
-		assert.Error(t, ErrInternalServer, err)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "InsertBookingReview", mock.Anything)
+	})
+
+	t.Run("Minimum rating value input validation", func(t *testing.T) {
+		review := BookingReview{
+			UserID:    1,
+			BookingID: 1,
+			Content:   "",
+			Rating:    0,
+		}
+
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+		err := mockService.InsertBookingReview(review)
+
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("Maximum rating value input validation", func(t *testing.T) {
+		review := BookingReview{
+			UserID:    1,
+			BookingID: 1,
+			Content:   "",
+			Rating:    6,
+		}
+
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+		err := mockService.InsertBookingReview(review)
+
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("Booking of other user", func(t *testing.T) {
+		review := BookingReview{
+			UserID:    2,
+			BookingID: 1,
+			Content:   "",
+			Rating:    5,
+		}
+
+		mockRepo := new(MockRepository)
+		mockNotification := new(MockNotificationService)
+		mockService := NewService(mockRepo, mockNotification, new(MockCloudinary))
+		mockRepo.On("InsertBookingReview", review).Return(errors.Wrap(ErrForbidden, "Booking bukan milik user."))
+		err := mockService.InsertBookingReview(review)
+
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+		mockNotification.AssertNotCalled(t, "Notify", mock.Anything)
 	})
 
 	t.Run("Booking is not eligible to be reviewed", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
+		review := BookingReview{
+			UserID:    1,
+			BookingID: 1,
+			Content:   "",
+			Rating:    5,
+		}
+
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+		mockRepo.On("InsertBookingReview", review).Return(errors.Wrap(ErrInputValidation, "Forbidden entry: Wrong booking status"))
+		err := mockService.InsertBookingReview(review)
 
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("Insert booking review with photos and sub ratings", func(t *testing.T) {
+		cleanliness, value := 4, 5
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:            1,
+			BookingID:         1,
+			Content:           "",
+			Rating:            5,
+			CleanlinessRating: &cleanliness,
+			ValueRating:       &value,
+			Photos:            []string{"data:image/png;base64,a", "data:image/png;base64,b"},
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(false, nil)
+		mockCloudinary := new(MockCloudinary)
+		mockService := NewService(mockRepo, newMockNotificationService(), mockCloudinary)
+		mockRepo.On("GetReviewedBooking", 1).Return(&ReviewedBooking{UserID: 1, PlaceID: 2, Status: util.BookingSelesai}, nil)
+		mockCloudinary.On("UploadFile", "data:image/png;base64,a", "Review Photo", "").Return("https://cdn/1-1.png", nil)
+		mockCloudinary.On("UploadFile", "data:image/png;base64,b", "Review Photo", "").Return("https://cdn/1-2.png", nil)
+		mockRepo.On("InsertBookingReview", mock.MatchedBy(func(r BookingReview) bool {
+			return assert.ObjectsAreEqual([]string{"https://cdn/1-1.png", "https://cdn/1-2.png"}, r.Photos) &&
+				*r.CleanlinessRating == 4 && r.ServiceRating == nil && *r.ValueRating == 5
+		})).Return(nil)
 		err := mockService.InsertBookingReview(review)
 
+		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
-		assert.Error(t, ErrInputValidation, err)
+		mockCloudinary.AssertExpectations(t)
 	})
 
-	t.Run("Exceeded review content input validation", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := strings.Repeat("Test Review", 100)
-		rating := 5
+	t.Run("Too many photos input validation", func(t *testing.T) {
+		review := BookingReview{
+			UserID:    1,
+			BookingID: 1,
+			Rating:    5,
+			Photos:    make([]string, util.MaximumReviewPhotos+1),
+		}
 
+		mockCloudinary := new(MockCloudinary)
+		mockService := NewService(new(MockRepository), newMockNotificationService(), mockCloudinary)
+		err := mockService.InsertBookingReview(review)
+
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		mockCloudinary.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Empty photo input validation", func(t *testing.T) {
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    1,
+			BookingID: 1,
+			Rating:    5,
+			Photos:    []string{""},
 		}
 
-		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
 		err := mockService.InsertBookingReview(review)
 
-		assert.Equal(t, errors.Cause(err), ErrInputValidation)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
 	})
 
-	t.Run("Minimum rating value input validation", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 0
+	t.Run("Sub rating input validation", func(t *testing.T) {
+		service := 6
+		review := BookingReview{
+			UserID:        1,
+			BookingID:     1,
+			Rating:        5,
+			ServiceRating: &service,
+		}
+
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+		err := mockService.InsertBookingReview(review)
+
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Contains(t, err.Error(), "Rating Service invalid")
+	})
 
+	t.Run("Failed to upload photo", func(t *testing.T) {
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    1,
+			BookingID: 1,
+			Rating:    5,
+			Photos:    []string{"data:image/png;base64,a"},
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
+		mockCloudinary := new(MockCloudinary)
+		mockService := NewService(mockRepo, newMockNotificationService(), mockCloudinary)
+		mockRepo.On("GetReviewedBooking", 1).Return(&ReviewedBooking{UserID: 1, PlaceID: 2, Status: util.BookingSelesai}, nil)
+		mockCloudinary.On("UploadFile", "data:image/png;base64,a", "Review Photo", "").Return("", errors.Wrap(cloudinary.ErrInternalServer, "test error"))
 		err := mockService.InsertBookingReview(review)
 
-		assert.Equal(t, errors.Cause(err), ErrInputValidation)
+		assert.Equal(t, cloudinary.ErrInternalServer, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "InsertBookingReview", mock.Anything)
 	})
 
-	t.Run("Maximum rating value input validation", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 6
-
+	t.Run("Photo is not uploaded for booking of other user", func(t *testing.T) {
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    2,
+			BookingID: 1,
+			Rating:    5,
+			Photos:    []string{"data:image/png;base64,a"},
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
+		mockCloudinary := new(MockCloudinary)
+		mockService := NewService(mockRepo, newMockNotificationService(), mockCloudinary)
+		mockRepo.On("GetReviewedBooking", 1).Return(&ReviewedBooking{UserID: 1, PlaceID: 2, Status: util.BookingSelesai}, nil)
 		err := mockService.InsertBookingReview(review)
 
-		assert.Equal(t, errors.Cause(err), ErrInputValidation)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+		mockCloudinary.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "InsertBookingReview", mock.Anything)
 	})
 
-	t.Run("Internal server error from RetrievePlaceID", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
+	t.Run("Photo is not uploaded for booking that is not completed", func(t *testing.T) {
+		review := BookingReview{
+			UserID:    1,
+			BookingID: 1,
+			Rating:    5,
+			Photos:    []string{"data:image/png;base64,a"},
+		}
 
+		mockRepo := new(MockRepository)
+		mockCloudinary := new(MockCloudinary)
+		mockService := NewService(mockRepo, newMockNotificationService(), mockCloudinary)
+		mockRepo.On("GetReviewedBooking", 1).Return(&ReviewedBooking{UserID: 1, PlaceID: 2, Status: util.BookingBerhasil}, nil)
+		err := mockService.InsertBookingReview(review)
+
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		mockCloudinary.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed to get reviewed booking", func(t *testing.T) {
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    1,
+			BookingID: 1,
+			Rating:    5,
+			Photos:    []string{"data:image/png;base64,a"},
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
-		mockRepo.On("RetrievePlaceID", review.BookingID).Return(nil, ErrInternalServer)
+		mockCloudinary := new(MockCloudinary)
+		mockService := NewService(mockRepo, newMockNotificationService(), mockCloudinary)
+		mockRepo.On("GetReviewedBooking", 1).Return(&ReviewedBooking{}, errors.Wrap(ErrNotFound, "test error"))
 		err := mockService.InsertBookingReview(review)
 
-		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+		mockCloudinary.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything, mock.Anything)
 	})
 
 	t.Run("Internal server error from InsertBookingReview", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
-
 		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
+			UserID:    1,
+			BookingID: 1,
+			Content:   "",
+			Rating:    5,
 		}
 
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
-		mockRepo.On("RetrievePlaceID", review.BookingID).Return(placeID, nil)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
 		mockRepo.On("InsertBookingReview", review).Return(ErrInternalServer)
 		err := mockService.InsertBookingReview(review)
 
 		assert.Equal(t, ErrInternalServer, errors.Cause(err))
 	})
+}
 
-	t.Run("Internal server error from UpdateBookingStatus", func(t *testing.T) {
-		var placeID *int = new(int)
-		*placeID = 1
-		userID := 1
-		bookingID := 1
-		content := ""
-		rating := 5
+func TestService_UpdateReview(t *testing.T) {
+	req := UpdateReviewRequest{ReviewID: 1, UserID: 2, Content: "Tempatnya nyaman", Rating: 4}
 
-		review := BookingReview{
-			UserID:    userID,
-			PlaceID:   *placeID,
-			BookingID: bookingID,
-			Content:   content,
-			Rating:    rating,
-		}
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1, UserID: 2, CreatedAt: time.Now().Add(-time.Hour)}, nil)
+		mockRepo.On("UpdateReview", req).Return(nil)
 
+		assert.Nil(t, mockService.UpdateReview(req))
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+
+		err := mockService.UpdateReview(UpdateReviewRequest{ReviewID: 1, UserID: 2, Rating: 6})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("failed invalid sub-rating", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		mockService := NewService(mockRepo)
-		mockRepo.On("CheckBookingStatus", review.BookingID).Return(true, nil)
-		mockRepo.On("RetrievePlaceID", review.BookingID).Return(placeID, nil)
-		mockRepo.On("InsertBookingReview", review).Return(nil)
-		mockRepo.On("UpdateBookingStatus", review.BookingID).Return(ErrInternalServer)
-		err := mockService.InsertBookingReview(review)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+		invalidRating := 0
+
+		err := mockService.UpdateReview(UpdateReviewRequest{ReviewID: 1, UserID: 2, Content: "Tempatnya nyaman", Rating: 4, ServiceRating: &invalidRating})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "GetReview", mock.Anything)
+	})
+
+	t.Run("failed review of other user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1, UserID: 3, CreatedAt: time.Now()}, nil)
+
+		err := mockService.UpdateReview(req)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+	})
+
+	t.Run("failed edit window is over", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1, UserID: 2, CreatedAt: time.Now().AddDate(0, 0, -util.ReviewEditWindowDays-1)}, nil)
+
+		err := mockService.UpdateReview(req)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		mockRepo.AssertNotCalled(t, "UpdateReview", mock.Anything)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{}, errors.Wrap(ErrNotFound, "test error"))
+
+		err := mockService.UpdateReview(req)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestService_ReplyReview(t *testing.T) {
+	req := ReplyRequest{ReviewID: 1, UserID: 3, Reply: " Terima kasih "}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1, OwnerID: 3}, nil)
+		mockRepo.On("InsertReply", 1, "Terima kasih").Return(nil)
+
+		assert.Nil(t, mockService.ReplyReview(req))
+	})
+
+	t.Run("failed empty reply", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+
+		err := mockService.ReplyReview(ReplyRequest{ReviewID: 1, UserID: 3, Reply: " "})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("failed reply too long", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+
+		err := mockService.ReplyReview(ReplyRequest{ReviewID: 1, UserID: 3, Reply: strings.Repeat("a", util.MaximumReviewLength+1)})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("failed review of other place", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
 
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1, OwnerID: 4}, nil)
+
+		err := mockService.ReplyReview(req)
+		assert.Equal(t, ErrForbidden, errors.Cause(err))
+	})
+
+	t.Run("failed already replied", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1, OwnerID: 3}, nil)
+		mockRepo.On("InsertReply", 1, "Terima kasih").Return(errors.Wrap(ErrInputValidation, "review is already replied"))
+
+		err := mockService.ReplyReview(req)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+}
+
+func TestService_ReportReview(t *testing.T) {
+	report := Report{ReviewID: 1, UserID: 2, Reason: "spam"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{ID: 1}, nil)
+		mockRepo.On("InsertReport", report).Return(nil)
+
+		assert.Nil(t, mockService.ReportReview(report))
+	})
+
+	t.Run("failed empty reason", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+
+		err := mockService.ReportReview(Report{ReviewID: 1, UserID: 2})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReview", 1).Return(&Review{}, errors.Wrap(ErrNotFound, "test error"))
+
+		err := mockService.ReportReview(report)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+}
+
+func TestService_GetReportedReviewsWithPagination(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		list := ModerationList{Reviews: []ReportedReview{{ID: 1, ReportCount: 2}}, TotalCount: 1}
+		mockRepo.On("GetReportedReviewsWithPagination", ModerationListRequest{Limit: util.DefaultLimit, Page: util.DefaultPage, Path: "/api/v1/moderation/reviews"}).Return(&list, nil)
+
+		result, pagination, err := mockService.GetReportedReviewsWithPagination(ModerationListRequest{Path: "/api/v1/moderation/reviews"})
+		assert.Nil(t, err)
+		assert.Equal(t, &list, result)
+		assert.Equal(t, 1, pagination.TotalPage)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+
+		result, pagination, err := mockService.GetReportedReviewsWithPagination(ModerationListRequest{Limit: 101})
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("GetReportedReviewsWithPagination", mock.Anything).Return(&ModerationList{}, errors.Wrap(ErrInternalServer, "test error"))
+
+		result, pagination, err := mockService.GetReportedReviewsWithPagination(ModerationListRequest{Path: "/api/v1/moderation/reviews"})
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
 		assert.Equal(t, ErrInternalServer, errors.Cause(err))
 	})
 }
+
+func TestService_UpdateVisibility(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo, newMockNotificationService(), new(MockCloudinary))
+
+		mockRepo.On("UpdateVisibility", 1, true, 9).Return(nil)
+
+		assert.Nil(t, mockService.UpdateVisibility(1, true, 9))
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository), newMockNotificationService(), new(MockCloudinary))
+
+		err := mockService.UpdateVisibility(0, true, 9)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+}
@@ -13,3 +13,10 @@ type Model struct {
 	CreatedAt       time.Time `db:"created_at"`
 	UpdatedAt       time.Time `db:"updated_at"`
 }
+
+// Membership is the place a business admin or staff belong to, OwnerID is the business admin who own the place
+type Membership struct {
+	PlaceID int    `db:"place_id"`
+	OwnerID int    `db:"owner_id"`
+	Role    string `db:"role"`
+}
@@ -2,8 +2,10 @@ package user
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
 )
 
 // NewRepo PostgreSQL for auth module
@@ -20,6 +22,8 @@ type repo struct {
 // Repo will contain all the function that can be used by repo
 type Repo interface {
 	GetUserIDByLocalID(localID string) (*Model, error)
+	GetUserByID(userID int) (*Model, error)
+	GetMembership(userID int) (*Membership, error)
 }
 
 func (r repo) GetUserIDByLocalID(localID string) (*Model, error) {
@@ -34,3 +38,40 @@ func (r repo) GetUserIDByLocalID(localID string) (*Model, error) {
 
 	return &user, nil
 }
+
+func (r repo) GetUserByID(userID int) (*Model, error) {
+	var user Model
+	err := r.db.Get(&user, "SELECT id, phone_number, name, status, COALESCE(email, '') as email, COALESCE(firebase_local_id, '') as firebase_local_id, created_at, updated_at FROM users WHERE id=$1", userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("user with id %d is not found", userID))
+		}
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &user, nil
+}
+
+// GetMembership return owner role when the user own a place, otherwise the staff role from place_members,
+// a user own or staff at most one place so more than one membership is an error instead of picking one of them
+func (r repo) GetMembership(userID int) (*Membership, error) {
+	var memberships []Membership
+
+	query := `SELECT p.id AS place_id, p.user_id AS owner_id, $2::varchar AS role FROM places p WHERE p.user_id = $1
+			UNION ALL
+			SELECT pm.place_id, p.user_id AS owner_id, pm.role FROM place_members pm INNER JOIN places p ON p.id = pm.place_id WHERE pm.user_id = $1`
+
+	err := r.db.Select(&memberships, query, userID, util.RoleOwner)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	switch len(memberships) {
+	case 0:
+		return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("user with id %d is not member of any place", userID))
+	case 1:
+		return &memberships[0], nil
+	}
+
+	return nil, errors.Wrap(ErrInternalServer, fmt.Sprintf("user with id %d has more than one membership", userID))
+}
@@ -68,3 +68,119 @@ func TestRepo_GetUserIDByLocalID(t *testing.T) {
 		assert.Equal(t, ErrNotFound, errors.Cause(err))
 	})
 }
+
+func TestRepo_GetUserByID(t *testing.T) {
+	query := "SELECT id, phone_number, name, status, COALESCE(email, '') as email, COALESCE(firebase_local_id, '') as firebase_local_id, created_at, updated_at FROM users WHERE id=$1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(1, "Owner"))
+
+		user, err := repo.GetUserByID(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &Model{ID: 1, Name: "Owner"}, user)
+	})
+
+	t.Run("failed sql no rows", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetUserByID(1)
+		assert.Nil(t, user)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		user, err := repo.GetUserByID(1)
+		assert.Nil(t, user)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetMembership(t *testing.T) {
+	query := "SELECT p.id AS place_id, p.user_id AS owner_id, $2::varchar AS role FROM places p WHERE p.user_id = $1"
+
+	t.Run("success", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "owner").
+			WillReturnRows(mock.NewRows([]string{"place_id", "owner_id", "role"}).AddRow(10, 1, "cashier"))
+
+		membership, err := repo.GetMembership(2)
+		assert.Nil(t, err)
+		assert.Equal(t, &Membership{PlaceID: 10, OwnerID: 1, Role: "cashier"}, membership)
+	})
+
+	t.Run("failed not member of any place", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "owner").
+			WillReturnRows(mock.NewRows([]string{"place_id", "owner_id", "role"}))
+
+		membership, err := repo.GetMembership(2)
+		assert.Nil(t, membership)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed more than one membership", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "owner").
+			WillReturnRows(mock.NewRows([]string{"place_id", "owner_id", "role"}).AddRow(10, 2, "owner").AddRow(11, 1, "cashier"))
+
+		membership, err := repo.GetMembership(2)
+		assert.Nil(t, membership)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer mockDB.Close()
+		repo := NewRepo(sqlx.NewDb(mockDB, "sqlmock"))
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, "owner").WillReturnError(sql.ErrConnDone)
+
+		membership, err := repo.GetMembership(2)
+		assert.Nil(t, membership)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import "time"
+
+// Webhook is the endpoint of business admin system that receive the booking event
+type Webhook struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"-" db:"user_id"`
+	URL    string `json:"url"`
+	// Secret is only shown when the webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateRequest for register webhook request body
+type CreateRequest struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// Delivery is a single attempt log of sending event to webhook
+type Delivery struct {
+	ID             int        `json:"id"`
+	WebhookID      int        `json:"webhook_id" db:"webhook_id"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         int        `json:"status"`
+	Attempt        int        `json:"attempt"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status" db:"response_status"`
+	LastError      string     `json:"last_error" db:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at" db:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	URL            string     `json:"-"`
+	Secret         string     `json:"-"`
+}
+
+// BookingData is the booking information sent in the payload
+type BookingData struct {
+	BookingID    int     `json:"booking_id" db:"id"`
+	PlaceID      int     `json:"place_id" db:"place_id"`
+	OwnerID      int     `json:"-" db:"owner_id"`
+	CustomerName string  `json:"customer_name" db:"customer_name"`
+	Date         string  `json:"date"`
+	StartTime    string  `json:"start_time" db:"start_time"`
+	EndTime      string  `json:"end_time" db:"end_time"`
+	TotalPrice   float64 `json:"total_price" db:"total_price"`
+	Status       int     `json:"status"`
+}
+
+// Payload is the signed json body sent to webhook
+type Payload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      BookingData `json:"data"`
+}
+
+// DeliveryListRequest for delivery log request params
+type DeliveryListRequest struct {
+	Limit     int    `json:"limit"`
+	Page      int    `json:"page"`
+	Path      string `json:"path"`
+	UserID    int    `json:"user_id"`
+	WebhookID int    `json:"webhook_id"`
+}
+
+// DeliveryList for delivery log with total count for pagination
+type DeliveryList struct {
+	Deliveries []Delivery `json:"deliveries"`
+	TotalCount int        `json:"total_count"`
+}
+
+// Report is the result of a single delivery run
+type Report struct {
+	Delivered int `json:"delivered"`
+	Retried   int `json:"retried"`
+	Failed    int `json:"failed"`
+}
@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/middleware"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Handler struct for webhook
+type Handler struct {
+	service Service
+}
+
+// NewHandler is used to initialize Handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetWebhooks for handling get webhook list of business admin endpoint
+func (h *Handler) GetWebhooks(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	webhooks, err := h.service.GetWebhooks(user.ID)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    webhooks,
+	})
+}
+
+// CreateWebhook for handling register webhook endpoint
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	var req CreateRequest
+	if err = c.Bind(&req); err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "invalid body")
+	}
+
+	webhook, err := h.service.CreateWebhook(user.ID, req)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, util.APIResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    webhook,
+	})
+}
+
+// DeleteWebhook for handling delete webhook endpoint
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	webhookID, err := strconv.Atoi(c.Param("webhookID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "webhookID must be number")
+	}
+
+	err = h.service.DeleteWebhook(user.ID, webhookID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		default:
+			return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+	})
+}
+
+// GetDeliveriesWithPagination for handling get delivery log of webhook endpoint
+func (h *Handler) GetDeliveriesWithPagination(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	webhookID, err := strconv.Atoi(c.Param("webhookID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "webhookID must be number")
+	}
+
+	page, limit, errorList := util.ValidateParams(c.QueryParam("page"), c.QueryParam("limit"))
+	if len(errorList) > 0 {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, errorList...)
+	}
+
+	params := DeliveryListRequest{
+		Limit:     limit,
+		Page:      page,
+		Path:      fmt.Sprintf("/api/v1/business-admin/webhooks/%d/deliveries", webhookID),
+		UserID:    user.ID,
+		WebhookID: webhookID,
+	}
+
+	list, pagination, err := h.service.GetDeliveriesWithPagination(params)
+	if err != nil {
+		if errors.Cause(err) == ErrInputValidationError {
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		}
+
+		return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data: map[string]interface{}{
+			"deliveries": list.Deliveries,
+			"pagination": pagination,
+		},
+	})
+}
+
+// Redeliver for handling manual redeliver endpoint
+func (h *Handler) Redeliver(c echo.Context) error {
+	_, user, err := middleware.ParseUserData(c, util.StatusBusinessAdmin)
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusForbidden, err)
+	}
+
+	webhookID, err := strconv.Atoi(c.Param("webhookID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "webhookID must be number")
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("deliveryID"))
+	if err != nil {
+		return util.ErrorWrapWithContext(c, http.StatusBadRequest, ErrInputValidationError, "deliveryID must be number")
+	}
+
+	delivery, err := h.service.Redeliver(user.ID, webhookID, deliveryID)
+	if err != nil {
+		switch errors.Cause(err) {
+		case ErrInputValidationError:
+			return util.ErrorWrapWithContext(c, http.StatusBadRequest, err)
+		case ErrNotFound:
+			return util.ErrorWrapWithContext(c, http.StatusNotFound, err)
+		default:
+			return util.ErrorWrapWithContext(c, http.StatusInternalServerError, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, util.APIResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    delivery,
+	})
+}
@@ -0,0 +1,358 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	firebaseauth "gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Notify(event notification.Event) {
+	m.Called(event)
+}
+
+func (m *MockService) ProcessDeliveries() (*Report, error) {
+	args := m.Called()
+	return args.Get(0).(*Report), args.Error(1)
+}
+
+func (m *MockService) CreateWebhook(userID int, req CreateRequest) (*Webhook, error) {
+	args := m.Called(userID, req)
+	return args.Get(0).(*Webhook), args.Error(1)
+}
+
+func (m *MockService) GetWebhooks(userID int) (*[]Webhook, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*[]Webhook), args.Error(1)
+}
+
+func (m *MockService) DeleteWebhook(userID int, webhookID int) error {
+	args := m.Called(userID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockService) GetDeliveriesWithPagination(params DeliveryListRequest) (*DeliveryList, *util.Pagination, error) {
+	args := m.Called(params)
+	return args.Get(0).(*DeliveryList), args.Get(1).(*util.Pagination), args.Error(2)
+}
+
+func (m *MockService) Redeliver(userID int, webhookID int, deliveryID int) (*Delivery, error) {
+	args := m.Called(userID, webhookID, deliveryID)
+	return args.Get(0).(*Delivery), args.Error(1)
+}
+
+func newUserContext(e *echo.Echo, req *http.Request, rec *httptest.ResponseRecorder, providerID string) echo.Context {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID: "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{
+					{
+						ProviderID: providerID,
+					},
+				},
+			},
+		},
+	}
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("userFromDatabase", &user.Model{ID: 1})
+	ctx.Set("userFromFirebase", &userData)
+
+	return ctx
+}
+
+func TestHandler_GetWebhooks(t *testing.T) {
+	url := "/api/v1/business-admin/webhooks"
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetWebhooks", 1).Return(&[]Webhook{{ID: 1, URL: "https://pos.example.com/hook"}}, nil)
+
+		assert.NoError(t, h.GetWebhooks(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"data":[{"id":1,"url":"https://pos.example.com/hook","created_at"`)
+		assert.NotContains(t, rec.Body.String(), "secret")
+	})
+
+	t.Run("failed forbidden", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "phone")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetWebhooks(ctx), ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetWebhooks", 1).Return(&[]Webhook{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetWebhooks(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	url := "/api/v1/business-admin/webhooks"
+	body := `{"url": "https://pos.example.com/hook"}`
+	createRequest := CreateRequest{URL: "https://pos.example.com/hook"}
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateWebhook", 1, createRequest).Return(&Webhook{ID: 1, URL: createRequest.URL, Secret: "generated"}, nil)
+
+		assert.NoError(t, h.CreateWebhook(ctx))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"secret":"generated"`)
+	})
+
+	t.Run("failed invalid body", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader("{"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.CreateWebhook(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateWebhook", 1, createRequest).Return(&Webhook{}, errors.Wrap(ErrInputValidationError, "url must be a valid http or https url"))
+
+		util.ErrorHandler(h.CreateWebhook(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("CreateWebhook", 1, createRequest).Return(&Webhook{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.CreateWebhook(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_DeleteWebhook(t *testing.T) {
+	newContext := func(webhookID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/business-admin/webhooks/2", nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+		ctx.SetParamNames("webhookID")
+		ctx.SetParamValues(webhookID)
+
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("2")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("DeleteWebhook", 1, 2).Return(nil)
+
+		assert.NoError(t, h.DeleteWebhook(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("failed webhookID is not number", func(t *testing.T) {
+		ctx, rec := newContext("a")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.DeleteWebhook(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		ctx, rec := newContext("2")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("DeleteWebhook", 1, 2).Return(errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.DeleteWebhook(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext("2")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("DeleteWebhook", 1, 2).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.DeleteWebhook(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetDeliveriesWithPagination(t *testing.T) {
+	path := "/api/v1/business-admin/webhooks/2/deliveries"
+	params := DeliveryListRequest{Limit: 10, Page: 1, Path: path, UserID: 1, WebhookID: 2}
+
+	newContext := func(webhookID string, query string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, path+query, nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+		ctx.SetParamNames("webhookID")
+		ctx.SetParamValues(webhookID)
+
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("2", "?limit=10&page=1")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		pagination := util.GeneratePagination(1, 10, 1, path)
+		mockService.On("GetDeliveriesWithPagination", params).Return(&DeliveryList{Deliveries: []Delivery{{ID: 3, WebhookID: 2}}, TotalCount: 1}, &pagination, nil)
+
+		assert.NoError(t, h.GetDeliveriesWithPagination(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"deliveries":[{"id":3,"webhook_id":2`)
+		assert.Contains(t, rec.Body.String(), `"pagination":{"limit":10,"page":1`)
+	})
+
+	t.Run("failed webhookID is not number", func(t *testing.T) {
+		ctx, rec := newContext("a", "")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetDeliveriesWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed invalid params", func(t *testing.T) {
+		ctx, rec := newContext("2", "?page=a")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.GetDeliveriesWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext("2", "?limit=10&page=1")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("GetDeliveriesWithPagination", params).Return(&DeliveryList{}, &util.Pagination{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.GetDeliveriesWithPagination(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_Redeliver(t *testing.T) {
+	newContext := func(webhookID string, deliveryID string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/business-admin/webhooks/2/deliveries/3/redeliver", nil)
+		rec := httptest.NewRecorder()
+		ctx := newUserContext(e, req, rec, "password")
+		ctx.SetParamNames("webhookID", "deliveryID")
+		ctx.SetParamValues(webhookID, deliveryID)
+
+		return ctx, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctx, rec := newContext("2", "3")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("Redeliver", 1, 2, 3).Return(&Delivery{ID: 3, WebhookID: 2, Status: util.WebhookDelivered}, nil)
+
+		assert.NoError(t, h.Redeliver(ctx))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":1`)
+	})
+
+	t.Run("failed deliveryID is not number", func(t *testing.T) {
+		ctx, rec := newContext("2", "a")
+		h := NewHandler(new(MockService))
+
+		util.ErrorHandler(h.Redeliver(ctx), ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		ctx, rec := newContext("2", "3")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("Redeliver", 1, 2, 3).Return(&Delivery{}, errors.Wrap(ErrNotFound, "test error"))
+
+		util.ErrorHandler(h.Redeliver(ctx), ctx)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		ctx, rec := newContext("2", "3")
+		mockService := new(MockService)
+		h := NewHandler(mockService)
+
+		mockService.On("Redeliver", 1, 2, 3).Return(&Delivery{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		util.ErrorHandler(h.Redeliver(ctx), ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
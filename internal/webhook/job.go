@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Job send the pending webhook deliveries periodically in background
+type Job struct {
+	*util.Job
+	service Service
+}
+
+// NewJob for initialize webhook job
+func NewJob(service Service, interval time.Duration) *Job {
+	job := &Job{service: service}
+	job.Job = util.NewJob(interval, func() { job.Run() })
+	return job
+}
+
+// Run a single webhook delivery and log the result
+func (j *Job) Run() *Report {
+	report, err := j.service.ProcessDeliveries()
+	if err != nil {
+		logrus.Errorf("[webhook] failed: %v", err)
+		return nil
+	}
+
+	if report.Delivered+report.Retried+report.Failed > 0 {
+		logrus.Infof("[webhook] delivered %d, retried %d and failed %d deliveries", report.Delivered, report.Retried, report.Failed)
+	}
+
+	return report
+}
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// NewRepo used to initialize repo
+func NewRepo(db *sqlx.DB) Repo {
+	return &repo{
+		db: db,
+	}
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+// Repo will contain all the function that can be used by repo
+type Repo interface {
+	GetBookingData(bookingID int) (*BookingData, error)
+	GetWebhooksByUserID(userID int) (*[]Webhook, error)
+	InsertWebhook(webhook Webhook) (*Webhook, error)
+	DeleteWebhook(userID int, webhookID int) error
+	InsertDeliveries(deliveries []Delivery) error
+	GetPendingDeliveries(limit int) (*[]Delivery, error)
+	GetDelivery(userID int, webhookID int, deliveryID int) (*Delivery, error)
+	UpdateDelivery(delivery Delivery) error
+	GetDeliveriesWithPagination(params DeliveryListRequest) (*DeliveryList, error)
+}
+
+func (r repo) GetBookingData(bookingID int) (*BookingData, error) {
+	var result BookingData
+
+	query := `SELECT b.id, b.place_id, p.user_id AS owner_id, u.name AS customer_name, to_char(b.date, 'YYYY-MM-DD') AS date,
+				to_char(b.start_time, 'HH24:MI') AS start_time, to_char(b.end_time, 'HH24:MI') AS end_time,
+				b.total_price + COALESCE(b.booking_price, p.booking_price, 0) AS total_price, b.status
+			FROM bookings b
+			INNER JOIN places p ON b.place_id = p.id
+			INNER JOIN users u ON b.user_id = u.id
+			WHERE b.id = $1`
+
+	err := r.db.Get(&result, query, bookingID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("booking with id %d is not found", bookingID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetWebhooksByUserID(userID int) (*[]Webhook, error) {
+	result := make([]Webhook, 0)
+
+	query := "SELECT id, user_id, url, created_at FROM webhooks WHERE user_id = $1 ORDER BY id"
+	err := r.db.Select(&result, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) InsertWebhook(webhook Webhook) (*Webhook, error) {
+	query := "INSERT INTO webhooks (user_id, url, secret) VALUES ($1, $2, $3) RETURNING id, created_at"
+
+	err := r.db.QueryRowx(query, webhook.UserID, webhook.URL, webhook.Secret).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &webhook, nil
+}
+
+func (r repo) DeleteWebhook(userID int, webhookID int) error {
+	query := "DELETE FROM webhooks WHERE id = $1 AND user_id = $2"
+
+	result, err := r.db.Exec(query, webhookID, userID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	if rowsAffected == 0 {
+		return errors.Wrap(ErrNotFound, fmt.Sprintf("webhook with id %d is not found", webhookID))
+	}
+
+	return nil
+}
+
+func (r repo) InsertDeliveries(deliveries []Delivery) error {
+	query := "INSERT INTO webhook_deliveries (webhook_id, event, payload, status) VALUES (:webhook_id, :event, :payload, :status)"
+
+	_, err := r.db.NamedExec(query, deliveries)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetPendingDeliveries(limit int) (*[]Delivery, error) {
+	result := make([]Delivery, 0)
+
+	query := `SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempt, d.next_attempt_at, d.response_status,
+				d.last_error, d.delivered_at, d.created_at, w.url, w.secret
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON d.webhook_id = w.id
+			WHERE d.status = $1 AND d.next_attempt_at <= NOW()
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT $2`
+
+	err := r.db.Select(&result, query, util.WebhookPending, limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) GetDelivery(userID int, webhookID int, deliveryID int) (*Delivery, error) {
+	var result Delivery
+
+	query := `SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempt, d.next_attempt_at, d.response_status,
+				d.last_error, d.delivered_at, d.created_at, w.url, w.secret
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON d.webhook_id = w.id
+			WHERE d.id = $1 AND d.webhook_id = $2 AND w.user_id = $3`
+
+	err := r.db.Get(&result, query, deliveryID, webhookID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(ErrNotFound, fmt.Sprintf("delivery with id %d is not found", deliveryID))
+		}
+
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &result, nil
+}
+
+func (r repo) UpdateDelivery(delivery Delivery) error {
+	query := `UPDATE webhook_deliveries
+			SET status = $1, attempt = $2, next_attempt_at = $3, response_status = $4, last_error = $5, delivered_at = $6, updated_at = NOW()
+			WHERE id = $7`
+
+	_, err := r.db.Exec(query, delivery.Status, delivery.Attempt, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r repo) GetDeliveriesWithPagination(params DeliveryListRequest) (*DeliveryList, error) {
+	var list DeliveryList
+	list.Deliveries = make([]Delivery, 0)
+
+	query := `SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempt, d.next_attempt_at, d.response_status,
+				d.last_error, d.delivered_at, d.created_at
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON d.webhook_id = w.id
+			WHERE d.webhook_id = $1 AND w.user_id = $2
+			ORDER BY d.created_at DESC, d.id DESC
+			LIMIT $3 OFFSET $4`
+
+	err := r.db.Select(&list.Deliveries, query, params.WebhookID, params.UserID, params.Limit, (params.Page-1)*params.Limit)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	query = `SELECT COUNT(d.id)
+			FROM webhook_deliveries d
+			INNER JOIN webhooks w ON d.webhook_id = w.id
+			WHERE d.webhook_id = $1 AND w.user_id = $2`
+
+	err = r.db.Get(&list.TotalCount, query, params.WebhookID, params.UserID)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func newMockRepo(t *testing.T) (Repo, sqlmock.Sqlmock, func()) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	return NewRepo(sqlxDB), mock, func() { mockDB.Close() }
+}
+
+var deliveryColumns = []string{"id", "webhook_id", "event", "payload", "status", "attempt", "next_attempt_at", "response_status", "last_error", "delivered_at", "created_at", "url", "secret"}
+
+func TestRepo_GetBookingData(t *testing.T) {
+	query := "SELECT b.id, b.place_id, p.user_id AS owner_id, u.name AS customer_name"
+	columns := []string{"id", "place_id", "owner_id", "customer_name", "date", "start_time", "end_time", "total_price", "status"}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).
+			WillReturnRows(mock.NewRows(columns).AddRow(1, 2, 3, "Rafi", "2022-05-01", "10:00", "12:00", 150000.0, util.BookingBerhasil))
+
+		result, err := repoMock.GetBookingData(1)
+		assert.Nil(t, err)
+		assert.Equal(t, &bookingData, result)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetBookingData(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetBookingData(1)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetWebhooksByUserID(t *testing.T) {
+	query := "SELECT id, user_id, url, created_at FROM webhooks WHERE user_id = $1 ORDER BY id"
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3).
+			WillReturnRows(mock.NewRows([]string{"id", "user_id", "url", "created_at"}).AddRow(1, 3, "https://pos.example.com/hook", now))
+
+		result, err := repoMock.GetWebhooksByUserID(3)
+		assert.Nil(t, err)
+		assert.Equal(t, &[]Webhook{{ID: 1, UserID: 3, URL: "https://pos.example.com/hook", CreatedAt: now}}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetWebhooksByUserID(3)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_InsertWebhook(t *testing.T) {
+	query := "INSERT INTO webhooks (user_id, url, secret) VALUES ($1, $2, $3) RETURNING id, created_at"
+	webhook := Webhook{UserID: 3, URL: "https://pos.example.com/hook", Secret: "secret"}
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3, webhook.URL, webhook.Secret).
+			WillReturnRows(mock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+
+		result, err := repoMock.InsertWebhook(webhook)
+		assert.Nil(t, err)
+		assert.Equal(t, &Webhook{ID: 1, UserID: 3, URL: webhook.URL, Secret: webhook.Secret, CreatedAt: now}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3, webhook.URL, webhook.Secret).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.InsertWebhook(webhook)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_DeleteWebhook(t *testing.T) {
+	query := "DELETE FROM webhooks WHERE id = $1 AND user_id = $2"
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.Nil(t, repoMock.DeleteWebhook(1, 2))
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repoMock.DeleteWebhook(1, 2)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(2, 1).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.DeleteWebhook(1, 2)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_InsertDeliveries(t *testing.T) {
+	query := "INSERT INTO webhook_deliveries (webhook_id, event, payload, status) VALUES (?, ?, ?, ?),(?, ?, ?, ?)"
+	deliveries := []Delivery{
+		{WebhookID: 4, Event: util.NotificationBookingPaid, Payload: "{}"},
+		{WebhookID: 5, Event: util.NotificationBookingPaid, Payload: "{}"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs(4, util.NotificationBookingPaid, "{}", util.WebhookPending, 5, util.NotificationBookingPaid, "{}", util.WebhookPending).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		assert.Nil(t, repoMock.InsertDeliveries(deliveries))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.InsertDeliveries(deliveries)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetPendingDeliveries(t *testing.T) {
+	query := "FROM webhook_deliveries d INNER JOIN webhooks w ON d.webhook_id = w.id WHERE d.status = $1 AND d.next_attempt_at <= NOW()"
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.WebhookPending, 100).
+			WillReturnRows(mock.NewRows(deliveryColumns).AddRow(1, 4, util.NotificationBookingPaid, "{}", 0, 0, now, 0, "", nil, now, "https://pos.example.com/hook", "secret"))
+
+		result, err := repoMock.GetPendingDeliveries(100)
+		assert.Nil(t, err)
+		assert.Equal(t, &[]Delivery{{ID: 1, WebhookID: 4, Event: util.NotificationBookingPaid, Payload: "{}", NextAttemptAt: now, CreatedAt: now, URL: "https://pos.example.com/hook", Secret: "secret"}}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(util.WebhookPending, 100).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetPendingDeliveries(100)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetDelivery(t *testing.T) {
+	query := "WHERE d.id = $1 AND d.webhook_id = $2 AND w.user_id = $3"
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3, 2, 1).
+			WillReturnRows(mock.NewRows(deliveryColumns).AddRow(3, 2, util.NotificationBookingPaid, "{}", 2, 6, now, 500, "error", nil, now, "https://pos.example.com/hook", "secret"))
+
+		result, err := repoMock.GetDelivery(1, 2, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.ID)
+		assert.Equal(t, "secret", result.Secret)
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3, 2, 1).WillReturnError(sql.ErrNoRows)
+
+		result, err := repoMock.GetDelivery(1, 2, 3)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3, 2, 1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetDelivery(1, 2, 3)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_UpdateDelivery(t *testing.T) {
+	query := "UPDATE webhook_deliveries SET status = $1, attempt = $2, next_attempt_at = $3, response_status = $4, last_error = $5, delivered_at = $6, updated_at = NOW() WHERE id = $7"
+	now := time.Now()
+	delivery := Delivery{ID: 3, Status: util.WebhookDelivered, Attempt: 1, NextAttemptAt: now, ResponseStatus: 200, DeliveredAt: &now}
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(util.WebhookDelivered, 1, now, 200, "", &now, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.Nil(t, repoMock.UpdateDelivery(delivery))
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectExec(regexp.QuoteMeta(query)).WillReturnError(sql.ErrConnDone)
+
+		err := repoMock.UpdateDelivery(delivery)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRepo_GetDeliveriesWithPagination(t *testing.T) {
+	query := "WHERE d.webhook_id = $1 AND w.user_id = $2 ORDER BY d.created_at DESC, d.id DESC LIMIT $3 OFFSET $4"
+	countQuery := "SELECT COUNT(d.id) FROM webhook_deliveries d"
+	columns := deliveryColumns[:11]
+	params := DeliveryListRequest{UserID: 1, WebhookID: 2, Limit: 10, Page: 1}
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, 1, 10, 0).
+			WillReturnRows(mock.NewRows(columns).AddRow(3, 2, util.NotificationBookingPaid, "{}", 1, 1, now, 200, "", now, now))
+		mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(2, 1).WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
+
+		result, err := repoMock.GetDeliveriesWithPagination(params)
+		assert.Nil(t, err)
+		assert.Equal(t, &DeliveryList{
+			Deliveries: []Delivery{{ID: 3, WebhookID: 2, Event: util.NotificationBookingPaid, Payload: "{}", Status: 1, Attempt: 1, NextAttemptAt: now, ResponseStatus: 200, DeliveredAt: &now, CreatedAt: now}},
+			TotalCount: 1,
+		}, result)
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, 1, 10, 0).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetDeliveriesWithPagination(params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed count internal server error", func(t *testing.T) {
+		repoMock, mock, closeDB := newMockRepo(t)
+		defer closeDB()
+
+		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2, 1, 10, 0).WillReturnRows(mock.NewRows(columns))
+		mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WithArgs(2, 1).WillReturnError(sql.ErrConnDone)
+
+		result, err := repoMock.GetDeliveriesWithPagination(params)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	signatureHeader = "X-Webhook-Signature"
+	timestampHeader = "X-Webhook-Timestamp"
+	eventHeader     = "X-Webhook-Event"
+	deliveryHeader  = "X-Webhook-Delivery"
+)
+
+var (
+	httpClient = newHTTPClient(isPublicIP)
+	lookupIP   = net.DefaultResolver.LookupIPAddr
+)
+
+// newHTTPClient return the client used to deliver webhook, the resolved address is checked again when it is dialed
+// so a hostname can not be pointed to an internal address after the webhook is created, redirect is not followed
+func newHTTPClient(allowed func(ip net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return fmt.Errorf("webhook address %s is not allowed", host)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// deniedNetworks is the special purpose ranges of the IANA registries, prefix which embed an IPv4 address
+// such as NAT64, 6to4 and Teredo is denied as a whole because the embedded address can be internal,
+// IPv4-mapped address is not listed since isPublicIP check it as IPv4 and net.IPNet would match every IPv4 with it
+var deniedNetworks = mustParseCIDRs(
+	// IPv4
+	"0.0.0.0/8",       // this network
+	"10.0.0.0/8",      // private
+	"100.64.0.0/10",   // carrier-grade NAT
+	"127.0.0.0/8",     // loopback
+	"169.254.0.0/16",  // link-local
+	"172.16.0.0/12",   // private
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"192.88.99.0/24",  // 6to4 relay anycast
+	"192.168.0.0/16",  // private
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"224.0.0.0/4",     // multicast
+	"240.0.0.0/4",     // reserved and broadcast
+	// IPv6
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"64:ff9b::/96",   // NAT64
+	"64:ff9b:1::/48", // local-use NAT64
+	"100::/64",       // discard-only
+	"2001::/23",      // IETF protocol assignments, including Teredo
+	"2001:db8::/32",  // documentation
+	"2002::/16",      // 6to4
+	"fc00::/7",       // unique local
+	"fe80::/10",      // link-local
+	"fec0::/10",      // site-local
+	"ff00::/8",       // multicast
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// isPublicIP reject every address in deniedNetworks, IPv4-mapped IPv6 address is checked as its IPv4 address
+func isPublicIP(ip net.IP) bool {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+	}
+
+	for _, network := range deniedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateHost resolve the host of webhook url and make sure every address is public
+func validateHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("address %s is not allowed", host)
+		}
+
+		return nil
+	}
+
+	addresses, err := lookupIP(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range addresses {
+		if !isPublicIP(address.IP) {
+			return fmt.Errorf("address %s is not allowed", address.IP)
+		}
+	}
+
+	return nil
+}
+
+// Sign return the hex HMAC-SHA256 of "timestamp.payload" with the webhook secret, the receiver
+// should compute the same value and compare it with the signature header
+func Sign(secret string, timestamp int64, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d.%s", timestamp, payload)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// send post the signed payload and return the response status code, any non 2xx response is an error
+func send(delivery Delivery, now time.Time) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(timestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(signatureHeader, "sha256="+Sign(delivery.Secret, timestamp, delivery.Payload))
+	req.Header.Set(eventHeader, delivery.Event)
+	req.Header.Set(deliveryHeader, strconv.Itoa(delivery.ID))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(ErrInternalServerError, err.Error())
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, errors.Wrap(ErrInternalServerError, fmt.Sprintf("webhook responded with status %d", res.StatusCode))
+	}
+
+	return res.StatusCode, nil
+}
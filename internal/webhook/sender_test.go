@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "b7a2930bbe4f168efdece97b0d28fb55eb19e6048d56526698d876d9389f9cfe", Sign("secret", 1651363200, `{"event":"booking_paid"}`))
+}
+
+func TestSend(t *testing.T) {
+	allowLocalTarget(t)
+
+	now := time.Unix(1651363200, 0)
+	payload := `{"event":"booking_paid"}`
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, payload, string(body))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "1651363200", r.Header.Get(timestampHeader))
+			assert.Equal(t, "sha256="+Sign("secret", 1651363200, payload), r.Header.Get(signatureHeader))
+			assert.Equal(t, util.NotificationBookingPaid, r.Header.Get(eventHeader))
+			assert.Equal(t, "7", r.Header.Get(deliveryHeader))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		status, err := send(Delivery{ID: 7, Event: util.NotificationBookingPaid, Payload: payload, URL: server.URL, Secret: "secret"}, now)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+
+	t.Run("failed non 2xx response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		status, err := send(Delivery{ID: 7, Payload: payload, URL: server.URL, Secret: "secret"}, now)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed unreachable endpoint", func(t *testing.T) {
+		status, err := send(Delivery{ID: 7, Payload: payload, URL: "http://127.0.0.1:0", Secret: "secret"}, now)
+		assert.Equal(t, 0, status)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestSend_BlockedTarget(t *testing.T) {
+	now := time.Unix(1651363200, 0)
+
+	t.Run("failed private address", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("private address should not be dialed")
+		}))
+		defer server.Close()
+
+		status, err := send(Delivery{ID: 7, Payload: "{}", URL: server.URL, Secret: "secret"}, now)
+		assert.Equal(t, 0, status)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "is not allowed")
+	})
+
+	t.Run("failed redirect is not followed", func(t *testing.T) {
+		allowLocalTarget(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/internal" {
+				t.Error("redirect should not be followed")
+			}
+			http.Redirect(w, r, "/internal", http.StatusFound)
+		}))
+		defer server.Close()
+
+		status, err := send(Delivery{ID: 7, Payload: "{}", URL: server.URL, Secret: "secret"}, now)
+		assert.Equal(t, http.StatusFound, status)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{"0.0.0.0", false},
+		{"10.1.2.3", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"172.16.0.1", false},
+		{"192.0.0.8", false},
+		{"192.0.2.1", false},
+		{"192.168.1.1", false},
+		{"198.18.0.1", false},
+		{"198.19.255.254", false},
+		{"203.0.113.10", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.1.2.3", false},
+		{"::ffff:100.64.0.1", false},
+		{"64:ff9b::7f00:1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"64:ff9b:1::a01:203", false},
+		{"2001::1", false},
+		{"2001:db8::1", false},
+		{"2002:7f00:1::1", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"ff02::1", false},
+		{"8.8.8.8", true},
+		{"93.184.216.34", true},
+		{"100.63.255.255", true},
+		{"100.128.0.1", true},
+		{"198.20.0.1", true},
+		{"::ffff:8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			assert.Equal(t, test.public, isPublicIP(net.ParseIP(test.address)))
+		})
+	}
+}
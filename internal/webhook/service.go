@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// Service will contain all the function that can be used by service
+type Service interface {
+	Notify(event notification.Event)
+	ProcessDeliveries() (*Report, error)
+	CreateWebhook(userID int, req CreateRequest) (*Webhook, error)
+	GetWebhooks(userID int) (*[]Webhook, error)
+	DeleteWebhook(userID int, webhookID int) error
+	GetDeliveriesWithPagination(params DeliveryListRequest) (*DeliveryList, *util.Pagination, error)
+	Redeliver(userID int, webhookID int, deliveryID int) (*Delivery, error)
+}
+
+type service struct {
+	repo Repo
+}
+
+// NewService for initialize service
+func NewService(repo Repo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// deliveredEvents is the booking and payment event that is sent to webhook
+var deliveredEvents = map[string]bool{
+	util.NotificationBookingCreated:   true,
+	util.NotificationBookingConfirmed: true,
+	util.NotificationBookingCancelled: true,
+	util.NotificationBookingExpired:   true,
+	util.NotificationBookingPaid:      true,
+}
+
+// Notify put the event to the delivery queue of every webhook of the place owner, failure is only logged so it never fail the caller
+func (s *service) Notify(event notification.Event) {
+	if !deliveredEvents[event.Type] || event.BookingID <= 0 {
+		return
+	}
+
+	booking, err := s.repo.GetBookingData(event.BookingID)
+	if err != nil {
+		logrus.Errorf("[webhook] failed to queue %s: %v", event.Type, err)
+		return
+	}
+
+	webhooks, err := s.repo.GetWebhooksByUserID(booking.OwnerID)
+	if err != nil {
+		logrus.Errorf("[webhook] failed to queue %s: %v", event.Type, err)
+		return
+	}
+
+	if len(*webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(Payload{Event: event.Type, CreatedAt: time.Now(), Data: *booking})
+	if err != nil {
+		logrus.Errorf("[webhook] failed to queue %s: %v", event.Type, err)
+		return
+	}
+
+	deliveries := make([]Delivery, 0, len(*webhooks))
+	for _, webhook := range *webhooks {
+		deliveries = append(deliveries, Delivery{
+			WebhookID: webhook.ID,
+			Event:     event.Type,
+			Payload:   string(payload),
+			Status:    util.WebhookPending,
+		})
+	}
+
+	err = s.repo.InsertDeliveries(deliveries)
+	if err != nil {
+		logrus.Errorf("[webhook] failed to queue %s: %v", event.Type, err)
+	}
+}
+
+func (s *service) ProcessDeliveries() (*Report, error) {
+	deliveries, err := s.repo.GetPendingDeliveries(util.WebhookBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var report Report
+	for _, delivery := range *deliveries {
+		delivery = s.deliver(delivery)
+		switch delivery.Status {
+		case util.WebhookDelivered:
+			report.Delivered++
+		case util.WebhookFailed:
+			report.Failed++
+		default:
+			report.Retried++
+		}
+
+		err = s.repo.UpdateDelivery(delivery)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &report, nil
+}
+
+// deliver send the delivery once and set the status, a failed delivery is retried until it reach the maximum attempt
+func (s *service) deliver(delivery Delivery) Delivery {
+	now := time.Now()
+	responseStatus, err := send(delivery, now)
+
+	delivery.Attempt++
+	delivery.ResponseStatus = responseStatus
+	switch {
+	case err == nil:
+		delivery.Status = util.WebhookDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempt >= util.MaximumWebhookAttempt:
+		delivery.Status = util.WebhookFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = util.WebhookPending
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempt))
+		delivery.LastError = err.Error()
+	}
+
+	return delivery
+}
+
+// retryDelay is doubled on every attempt, started from WebhookRetryBaseMinutes
+func retryDelay(attempt int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempt-1))*util.WebhookRetryBaseMinutes) * time.Minute
+}
+
+func (s *service) CreateWebhook(userID int, req CreateRequest) (*Webhook, error) {
+	var errorList []string
+
+	endpoint, err := url.ParseRequestURI(req.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Hostname() == "" {
+		errorList = append(errorList, "url must be a valid http or https url")
+	} else if err = validateHost(context.Background(), endpoint.Hostname()); err != nil {
+		errorList = append(errorList, "url must resolve to a public address")
+	}
+
+	if req.Secret != "" && len(req.Secret) < util.MinimumWebhookSecretLength {
+		errorList = append(errorList, fmt.Sprintf("secret must be at least %d characters", util.MinimumWebhookSecretLength))
+	}
+
+	if len(errorList) > 0 {
+		return nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	if req.Secret == "" {
+		req.Secret, err = generateSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.InsertWebhook(Webhook{UserID: userID, URL: req.URL, Secret: req.Secret})
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(ErrInternalServerError, err.Error())
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+func (s *service) GetWebhooks(userID int) (*[]Webhook, error) {
+	return s.repo.GetWebhooksByUserID(userID)
+}
+
+func (s *service) DeleteWebhook(userID int, webhookID int) error {
+	if webhookID <= 0 {
+		return errors.Wrap(ErrInputValidationError, "webhookID must be above 0")
+	}
+
+	return s.repo.DeleteWebhook(userID, webhookID)
+}
+
+func (s *service) GetDeliveriesWithPagination(params DeliveryListRequest) (*DeliveryList, *util.Pagination, error) {
+	var errorList []string
+
+	if params.Page <= 0 {
+		params.Page = util.DefaultPage
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = util.DefaultLimit
+	}
+
+	if params.Limit > util.MaxLimit {
+		errorList = append(errorList, "limit should be 1 - 100")
+	}
+
+	if params.WebhookID <= 0 {
+		errorList = append(errorList, "webhookID must be above 0")
+	}
+
+	if params.Path == "" {
+		errorList = append(errorList, "path is required for pagination")
+	}
+
+	if len(errorList) > 0 {
+		return nil, nil, errors.Wrap(ErrInputValidationError, strings.Join(errorList, ","))
+	}
+
+	list, err := s.repo.GetDeliveriesWithPagination(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := util.GeneratePagination(list.TotalCount, params.Limit, params.Page, params.Path)
+	return list, &pagination, nil
+}
+
+// Redeliver send the delivery immediately regardless of its status and record the result in the delivery log
+func (s *service) Redeliver(userID int, webhookID int, deliveryID int) (*Delivery, error) {
+	if webhookID <= 0 || deliveryID <= 0 {
+		return nil, errors.Wrap(ErrInputValidationError, "webhookID and deliveryID must be above 0")
+	}
+
+	delivery, err := s.repo.GetDelivery(userID, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.deliver(*delivery)
+	err = s.repo.UpdateDelivery(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
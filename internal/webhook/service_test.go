@@ -0,0 +1,391 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/notification"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetBookingData(bookingID int) (*BookingData, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).(*BookingData), args.Error(1)
+}
+
+func (m *MockRepository) GetWebhooksByUserID(userID int) (*[]Webhook, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*[]Webhook), args.Error(1)
+}
+
+func (m *MockRepository) InsertWebhook(webhook Webhook) (*Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(*Webhook), args.Error(1)
+}
+
+func (m *MockRepository) DeleteWebhook(userID int, webhookID int) error {
+	args := m.Called(userID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) InsertDeliveries(deliveries []Delivery) error {
+	args := m.Called(deliveries)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetPendingDeliveries(limit int) (*[]Delivery, error) {
+	args := m.Called(limit)
+	return args.Get(0).(*[]Delivery), args.Error(1)
+}
+
+func (m *MockRepository) GetDelivery(userID int, webhookID int, deliveryID int) (*Delivery, error) {
+	args := m.Called(userID, webhookID, deliveryID)
+	return args.Get(0).(*Delivery), args.Error(1)
+}
+
+func (m *MockRepository) UpdateDelivery(delivery Delivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetDeliveriesWithPagination(params DeliveryListRequest) (*DeliveryList, error) {
+	args := m.Called(params)
+	return args.Get(0).(*DeliveryList), args.Error(1)
+}
+
+var bookingData = BookingData{
+	BookingID:    1,
+	PlaceID:      2,
+	OwnerID:      3,
+	CustomerName: "Rafi",
+	Date:         "2022-05-01",
+	StartTime:    "10:00",
+	EndTime:      "12:00",
+	TotalPrice:   150000,
+	Status:       util.BookingBerhasil,
+}
+
+// allowLocalTarget let the delivery reach httptest server which listen on loopback address
+func allowLocalTarget(t *testing.T) {
+	client := httpClient
+	httpClient = newHTTPClient(func(net.IP) bool { return true })
+	t.Cleanup(func() { httpClient = client })
+}
+
+func newServer(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+}
+
+func TestService_Notify(t *testing.T) {
+	event := notification.Event{Type: util.NotificationBookingPaid, BookingID: 1}
+
+	t.Run("success queue delivery for every webhook of owner", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetBookingData", 1).Return(&bookingData, nil)
+		mockRepo.On("GetWebhooksByUserID", 3).Return(&[]Webhook{{ID: 4}, {ID: 5}}, nil)
+		mockRepo.On("InsertDeliveries", mock.Anything).Return(nil)
+
+		mockService.Notify(event)
+
+		deliveries := mockRepo.Calls[2].Arguments.Get(0).([]Delivery)
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, 4, deliveries[0].WebhookID)
+		assert.Equal(t, 5, deliveries[1].WebhookID)
+		assert.Equal(t, util.NotificationBookingPaid, deliveries[0].Event)
+		assert.Equal(t, util.WebhookPending, deliveries[0].Status)
+
+		var payload Payload
+		assert.Nil(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+		assert.Equal(t, util.NotificationBookingPaid, payload.Event)
+		assert.Equal(t, bookingData.BookingID, payload.Data.BookingID)
+		assert.NotContains(t, deliveries[0].Payload, "owner_id")
+	})
+
+	t.Run("success owner has no webhook", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetBookingData", 1).Return(&bookingData, nil)
+		mockRepo.On("GetWebhooksByUserID", 3).Return(&[]Webhook{}, nil)
+
+		mockService.Notify(event)
+
+		mockRepo.AssertNotCalled(t, "InsertDeliveries", mock.Anything)
+	})
+
+	t.Run("success ignore event that is not delivered", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockService.Notify(notification.Event{Type: util.NotificationDisbursementCompleted, UserID: 3})
+
+		mockRepo.AssertNotCalled(t, "GetBookingData", mock.Anything)
+	})
+
+	t.Run("failed booking is not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetBookingData", 1).Return(&BookingData{}, errors.Wrap(ErrNotFound, "test error"))
+
+		mockService.Notify(event)
+
+		mockRepo.AssertNotCalled(t, "GetWebhooksByUserID", mock.Anything)
+	})
+}
+
+func TestService_ProcessDeliveries(t *testing.T) {
+	allowLocalTarget(t)
+
+	t.Run("success", func(t *testing.T) {
+		ok := newServer(http.StatusOK)
+		defer ok.Close()
+		unavailable := newServer(http.StatusServiceUnavailable)
+		defer unavailable.Close()
+
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		deliveries := []Delivery{
+			{ID: 1, URL: ok.URL, Secret: "secret"},
+			{ID: 2, URL: unavailable.URL, Secret: "secret"},
+			{ID: 3, URL: unavailable.URL, Secret: "secret", Attempt: util.MaximumWebhookAttempt - 1},
+		}
+		mockRepo.On("GetPendingDeliveries", util.WebhookBatchSize).Return(&deliveries, nil)
+		mockRepo.On("UpdateDelivery", mock.Anything).Return(nil)
+
+		report, err := mockService.ProcessDeliveries()
+		assert.Nil(t, err)
+		assert.Equal(t, &Report{Delivered: 1, Retried: 1, Failed: 1}, report)
+
+		delivered := mockRepo.Calls[1].Arguments.Get(0).(Delivery)
+		assert.Equal(t, util.WebhookDelivered, delivered.Status)
+		assert.Equal(t, http.StatusOK, delivered.ResponseStatus)
+		assert.NotNil(t, delivered.DeliveredAt)
+
+		retried := mockRepo.Calls[2].Arguments.Get(0).(Delivery)
+		assert.Equal(t, util.WebhookPending, retried.Status)
+		assert.Equal(t, 1, retried.Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, retried.ResponseStatus)
+		assert.Contains(t, retried.LastError, "status 503")
+
+		failed := mockRepo.Calls[3].Arguments.Get(0).(Delivery)
+		assert.Equal(t, util.WebhookFailed, failed.Status)
+	})
+
+	t.Run("failed get pending deliveries", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPendingDeliveries", util.WebhookBatchSize).Return(&[]Delivery{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		report, err := mockService.ProcessDeliveries()
+		assert.Nil(t, report)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+
+	t.Run("failed update delivery", func(t *testing.T) {
+		server := newServer(http.StatusOK)
+		defer server.Close()
+
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetPendingDeliveries", util.WebhookBatchSize).Return(&[]Delivery{{ID: 1, URL: server.URL}}, nil)
+		mockRepo.On("UpdateDelivery", mock.Anything).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		report, err := mockService.ProcessDeliveries()
+		assert.Nil(t, report)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 1*util.WebhookRetryBaseMinutes*time.Minute, retryDelay(1))
+	assert.Equal(t, 8*util.WebhookRetryBaseMinutes*time.Minute, retryDelay(4))
+}
+
+func TestService_CreateWebhook(t *testing.T) {
+	resolver := lookupIP
+	lookupIP = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if host == "internal.example.com" {
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("192.168.1.10")}}, nil
+		}
+
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	}
+	defer func() { lookupIP = resolver }()
+
+	t.Run("success with given secret", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		webhook := Webhook{UserID: 1, URL: "https://pos.example.com/hook", Secret: "0123456789abcdef"}
+		mockRepo.On("InsertWebhook", webhook).Return(&Webhook{ID: 1, URL: webhook.URL, Secret: webhook.Secret}, nil)
+
+		result, err := mockService.CreateWebhook(1, CreateRequest{URL: webhook.URL, Secret: webhook.Secret})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, result.ID)
+	})
+
+	t.Run("success generate secret", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("InsertWebhook", mock.Anything).Return(&Webhook{ID: 1}, nil)
+
+		_, err := mockService.CreateWebhook(1, CreateRequest{URL: "http://93.184.216.34:8080/hook"})
+		assert.Nil(t, err)
+		assert.Len(t, mockRepo.Calls[0].Arguments.Get(0).(Webhook).Secret, 64)
+	})
+
+	t.Run("failed private address", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		for _, target := range []string{"http://127.0.0.1/hook", "http://10.0.0.2:8080/hook", "http://[::1]/hook", "http://169.254.169.254/latest", "http://internal.example.com/hook"} {
+			result, err := mockService.CreateWebhook(1, CreateRequest{URL: target})
+			assert.Nil(t, result)
+			assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+			assert.Contains(t, err.Error(), "url must resolve to a public address")
+		}
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		result, err := mockService.CreateWebhook(1, CreateRequest{URL: "ftp://pos.example.com", Secret: "short"})
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "url must be a valid http or https url,secret must be at least 16 characters")
+	})
+}
+
+func TestService_DeleteWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("DeleteWebhook", 1, 2).Return(nil)
+
+		assert.Nil(t, mockService.DeleteWebhook(1, 2))
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		err := mockService.DeleteWebhook(1, 0)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+}
+
+func TestService_GetDeliveriesWithPagination(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		list := DeliveryList{Deliveries: []Delivery{{ID: 1}}, TotalCount: 1}
+		params := DeliveryListRequest{UserID: 1, WebhookID: 2, Path: "/api/v1/business-admin/webhooks/2/deliveries"}
+		expected := params
+		expected.Limit = util.DefaultLimit
+		expected.Page = util.DefaultPage
+		mockRepo.On("GetDeliveriesWithPagination", expected).Return(&list, nil)
+
+		result, pagination, err := mockService.GetDeliveriesWithPagination(params)
+		assert.Nil(t, err)
+		assert.Equal(t, &list, result)
+		assert.Equal(t, 1, pagination.TotalPage)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		result, pagination, err := mockService.GetDeliveriesWithPagination(DeliveryListRequest{UserID: 1, Limit: 101})
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+		assert.Contains(t, err.Error(), "limit should be 1 - 100,webhookID must be above 0,path is required for pagination")
+	})
+
+	t.Run("failed internal server error", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetDeliveriesWithPagination", mock.Anything).Return(&DeliveryList{}, errors.Wrap(ErrInternalServerError, "test error"))
+
+		result, pagination, err := mockService.GetDeliveriesWithPagination(DeliveryListRequest{UserID: 1, WebhookID: 2, Path: "/path"})
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
+
+func TestService_Redeliver(t *testing.T) {
+	allowLocalTarget(t)
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(http.StatusOK)
+		defer server.Close()
+
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		delivery := Delivery{ID: 3, WebhookID: 2, URL: server.URL, Status: util.WebhookFailed, Attempt: util.MaximumWebhookAttempt}
+		mockRepo.On("GetDelivery", 1, 2, 3).Return(&delivery, nil)
+		mockRepo.On("UpdateDelivery", mock.Anything).Return(nil)
+
+		result, err := mockService.Redeliver(1, 2, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, util.WebhookDelivered, result.Status)
+		assert.Equal(t, util.MaximumWebhookAttempt+1, result.Attempt)
+	})
+
+	t.Run("failed input validation", func(t *testing.T) {
+		mockService := NewService(new(MockRepository))
+
+		result, err := mockService.Redeliver(1, 0, 3)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInputValidationError, errors.Cause(err))
+	})
+
+	t.Run("failed not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetDelivery", 1, 2, 3).Return(&Delivery{}, errors.Wrap(ErrNotFound, "test error"))
+
+		result, err := mockService.Redeliver(1, 2, 3)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrNotFound, errors.Cause(err))
+	})
+
+	t.Run("failed update delivery", func(t *testing.T) {
+		server := newServer(http.StatusOK)
+		defer server.Close()
+
+		mockRepo := new(MockRepository)
+		mockService := NewService(mockRepo)
+
+		mockRepo.On("GetDelivery", 1, 2, 3).Return(&Delivery{ID: 3, URL: server.URL}, nil)
+		mockRepo.On("UpdateDelivery", mock.Anything).Return(errors.Wrap(ErrInternalServerError, "test error"))
+
+		result, err := mockService.Redeliver(1, 2, 3)
+		assert.Nil(t, result)
+		assert.Equal(t, ErrInternalServerError, errors.Cause(err))
+	})
+}
@@ -35,6 +35,20 @@ func main() {
 	s := api.NewServer(router)
 	s.Init()
 
+	// Admin command to reconcile invoices and disbursements with xendit then exit
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		s.Reconcile()
+		return
+	}
+
+	// Admin command to rebuild place stats from reviews and bookings then exit
+	if len(os.Args) > 1 && os.Args[1] == "backfill-place-stats" {
+		s.BackfillPlaceStats()
+		return
+	}
+
+	s.StartJobs()
+
 	// Running server
 	s.RunServer(os.Getenv("PORT"))
 }
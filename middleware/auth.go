@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
@@ -17,96 +18,171 @@ const (
 	authorizationTypeBearer = "bearer"
 )
 
+// SessionRevocation check whether the token has been revoked by logging out or changing password
+type SessionRevocation interface {
+	IsSessionRevoked(token, localID string, authTime time.Time) (bool, error)
+}
+
 // AuthMiddleware struct for middleware auth
 type AuthMiddleware struct {
-	firebaseAuth firebaseauth.Repo
-	userRepo     user.Repo
+	tokenVerifier     firebaseauth.TokenVerifier
+	sessionRevocation SessionRevocation
+	userRepo          user.Repo
+	userCache         *userCache
 }
 
 // NewAuthMiddleware for creating AuthMiddleware instance
-func NewAuthMiddleware(firebaseAuth firebaseauth.Repo, userRepo user.Repo) AuthMiddleware {
-	return AuthMiddleware{firebaseAuth: firebaseAuth, userRepo: userRepo}
+func NewAuthMiddleware(tokenVerifier firebaseauth.TokenVerifier, sessionRevocation SessionRevocation, userRepo user.Repo) AuthMiddleware {
+	return AuthMiddleware{
+		tokenVerifier:     tokenVerifier,
+		sessionRevocation: sessionRevocation,
+		userRepo:          userRepo,
+		userCache:         newUserCache(util.UserCacheSize, util.UserCacheTTLMinutes*time.Minute),
+	}
 }
 
-// AuthMiddleware function for handling auth middleware
+// AuthMiddleware function for handling auth middleware, request without authorization header is rejected
 func (a AuthMiddleware) AuthMiddleware() echo.MiddlewareFunc {
+	return a.middleware(false)
+}
+
+// OptionalAuthMiddleware attach the user when a valid bearer token is sent and continue anonymously when
+// authorization header is not sent, malformed or invalid token is still rejected
+func (a AuthMiddleware) OptionalAuthMiddleware() echo.MiddlewareFunc {
+	return a.middleware(true)
+}
+
+func (a AuthMiddleware) middleware(optional bool) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
-			//var token string
-			for key, values := range ctx.Request().Header {
-				if key == "Authorization" {
-					if len(values) < 1 || values[0] == "" {
-						err := errors.New("invalid authorization header format")
-						return ctx.JSON(http.StatusUnauthorized, util.APIResponse{
-							Status:  http.StatusUnauthorized,
-							Message: "unauthorized",
-							Errors:  []string{err.Error()},
-						})
-					}
-
-					authHeader := strings.Split(values[0], " ")
-
-					authorizationType := strings.ToLower(authHeader[0])
-					if authorizationType != authorizationTypeBearer {
-						err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-						return ctx.JSON(http.StatusUnauthorized, util.APIResponse{
-							Status:  http.StatusUnauthorized,
-							Message: "unauthorized",
-							Errors:  []string{err.Error()},
-						})
-					}
-
-					userFromFirebase, err := a.firebaseAuth.GetUserDataFromToken(authHeader[1])
-					if err != nil {
-						if errors.Cause(err) == firebaseauth.ErrInputValidation {
-							err, _ := util.ErrorUnwrap(err)
-							return ctx.JSON(http.StatusUnauthorized, util.APIResponse{
-								Status:  http.StatusUnauthorized,
-								Message: "unauthorized",
-								Errors:  err,
-							})
-						}
-						logrus.Error("[failed to get data from firebase repo] ", err.Error())
-						return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
-							Status:  http.StatusInternalServerError,
-							Message: "internal server error",
-						})
-					}
-
-					userFromDatabase, err := a.userRepo.GetUserIDByLocalID(userFromFirebase.Users[0].LocalID)
-					if err != nil && errors.Cause(err) != user.ErrNotFound {
-						logrus.Error("[failed to get data from user repo] ", err.Error())
-						return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
-							Status:  http.StatusInternalServerError,
-							Message: "internal server error",
-						})
-					}
-
-					ctx.Set("userFromFirebase", userFromFirebase)
-					ctx.Set("userFromDatabase", userFromDatabase)
+			values, ok := ctx.Request().Header[echo.HeaderAuthorization]
+			if !ok {
+				if optional {
 					return next(ctx)
 				}
+
+				return unauthorized(ctx, errors.New("authorization header is not provided"))
+			}
+
+			if len(values) < 1 || strings.TrimSpace(values[0]) == "" {
+				return unauthorized(ctx, errors.New("invalid authorization header format"))
+			}
+
+			authHeader := strings.Fields(values[0])
+
+			authorizationType := strings.ToLower(authHeader[0])
+			if authorizationType != authorizationTypeBearer {
+				return unauthorized(ctx, fmt.Errorf("unsupported authorization type %s", authorizationType))
+			}
+
+			if len(authHeader) != 2 {
+				return unauthorized(ctx, errors.New("invalid authorization header format"))
+			}
+
+			userFromFirebase, err := a.tokenVerifier.GetUserDataFromToken(authHeader[1])
+			if err != nil {
+				if errors.Cause(err) == firebaseauth.ErrInputValidation {
+					err, _ := util.ErrorUnwrap(err)
+					return ctx.JSON(http.StatusUnauthorized, util.APIResponse{
+						Status:  http.StatusUnauthorized,
+						Message: "unauthorized",
+						Errors:  err,
+					})
+				}
+				logrus.Error("[failed to get data from firebase repo] ", err.Error())
+				return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
+					Status:  http.StatusInternalServerError,
+					Message: "internal server error",
+				})
+			}
+
+			revoked, err := a.sessionRevocation.IsSessionRevoked(authHeader[1], userFromFirebase.Users[0].LocalID, userFromFirebase.AuthTime)
+			if err != nil {
+				logrus.Error("[failed to check token revocation] ", err.Error())
+				return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
+					Status:  http.StatusInternalServerError,
+					Message: "internal server error",
+				})
+			}
+
+			if revoked {
+				return unauthorized(ctx, errors.New("token has been revoked"))
 			}
 
-			err := errors.New("authorization header is not provided")
-			return ctx.JSON(http.StatusUnauthorized, util.APIResponse{
-				Status:  http.StatusUnauthorized,
-				Message: "unauthorized",
-				Errors:  []string{err.Error()},
-			})
+			userFromDatabase, err := a.getUser(userFromFirebase.Users[0].LocalID)
+			if err != nil {
+				logrus.Error("[failed to get data from user repo] ", err.Error())
+				return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
+					Status:  http.StatusInternalServerError,
+					Message: "internal server error",
+				})
+			}
+
+			ctx.Set("token", authHeader[1])
+			ctx.Set("userFromFirebase", userFromFirebase)
+			ctx.Set("userFromDatabase", userFromDatabase)
+			return next(ctx)
 		}
 	}
 }
 
-// ParseUserData is used to get the user data from firebase from middleware context
-func ParseUserData(ctx echo.Context, status int) (*firebaseauth.UserDataFromToken, *user.Model, error) {
-	userFromFirebase := (ctx.Get("userFromFirebase")).(*firebaseauth.UserDataFromToken)
+// getUser return the registered user of the local ID from cache or database, nil user means not registered yet
+// and it is not cached so the user can be used right after the registration
+func (a AuthMiddleware) getUser(localID string) (*user.Model, error) {
+	if userFromDatabase, ok := a.userCache.get(localID); ok {
+		return userFromDatabase, nil
+	}
+
+	userFromDatabase, err := a.userRepo.GetUserIDByLocalID(localID)
+	if err != nil {
+		if errors.Cause(err) == user.ErrNotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if userFromDatabase != nil {
+		a.userCache.set(localID, userFromDatabase)
+	}
+
+	return userFromDatabase, nil
+}
+
+func unauthorized(ctx echo.Context, err error) error {
+	return ctx.JSON(http.StatusUnauthorized, util.APIResponse{
+		Status:  http.StatusUnauthorized,
+		Message: "unauthorized",
+		Errors:  []string{err.Error()},
+	})
+}
 
-	var userFromDatabase *user.Model
-	if ctx.Get("userFromDatabase") != nil {
-		userFromDatabase = (ctx.Get("userFromDatabase")).(*user.Model)
+// ParseToken return the bearer token of the authenticated request, empty for anonymous request
+func ParseToken(ctx echo.Context) string {
+	token, _ := ctx.Get("token").(string)
+	return token
+}
+
+// ParseLocalID return the local ID of the authenticated user, empty for anonymous request
+func ParseLocalID(ctx echo.Context) string {
+	userFromFirebase, ok := ctx.Get("userFromFirebase").(*firebaseauth.UserDataFromToken)
+	if !ok || userFromFirebase == nil || len(userFromFirebase.Users) == 0 {
+		return ""
 	}
 
+	return userFromFirebase.Users[0].LocalID
+}
+
+// ParseUserData is used to get the user data from firebase from middleware context,
+// anonymous request of OptionalAuthMiddleware return ErrUnauthorized
+func ParseUserData(ctx echo.Context, status int) (*firebaseauth.UserDataFromToken, *user.Model, error) {
+	userFromFirebase, ok := ctx.Get("userFromFirebase").(*firebaseauth.UserDataFromToken)
+	if !ok || userFromFirebase == nil || len(userFromFirebase.Users) == 0 || len(userFromFirebase.Users[0].ProviderUserInfo) == 0 {
+		return nil, nil, errors.Wrap(ErrUnauthorized, "user is not authenticated")
+	}
+
+	userFromDatabase, _ := ctx.Get("userFromDatabase").(*user.Model)
+
 	switch status {
 	case util.StatusCustomer:
 		if userFromFirebase.Users[0].ProviderUserInfo[0].ProviderID == "phone" {
@@ -43,12 +43,31 @@ func (u *UserMockRepository) GetUserIDByLocalID(localID string) (*user.Model, er
 	return args.Get(0).(*user.Model), args.Error(1)
 }
 
+func (u *UserMockRepository) GetUserByID(userID int) (*user.Model, error) {
+	args := u.Called(userID)
+	return args.Get(0).(*user.Model), args.Error(1)
+}
+
+func (u *UserMockRepository) GetMembership(userID int) (*user.Membership, error) {
+	args := u.Called(userID)
+	return args.Get(0).(*user.Membership), args.Error(1)
+}
+
+type revokedTokens struct {
+	tokens map[string]bool
+	err    error
+}
+
+func (r revokedTokens) IsSessionRevoked(token, localID string, authTime time.Time) (bool, error) {
+	return r.tokens[token], r.err
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	e := echo.New()
 
 	firebaseRepo := new(FirebaseMockRepository)
 	userRepo := new(UserMockRepository)
-	authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 	e.GET("/", func(c echo.Context) error {
 		data, _, err := ParseUserData(c, util.StatusCustomer)
@@ -158,7 +177,7 @@ func TestAuthMiddlewareUnknown(t *testing.T) {
 
 	firebaseRepo := new(FirebaseMockRepository)
 	userRepo := new(UserMockRepository)
-	authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 	e.GET("/", func(c echo.Context) error {
 		data, _, err := ParseUserData(c, 3)
@@ -229,7 +248,7 @@ func TestAuthMiddlewareBusinessAdmin(t *testing.T) {
 
 	userRepo := new(UserMockRepository)
 	firebaseRepo := new(FirebaseMockRepository)
-	authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 	e.GET("/", func(c echo.Context) error {
 		data, _, err := ParseUserData(c, util.StatusBusinessAdmin)
@@ -323,7 +342,7 @@ func TestAuthMiddlewareHeaderNotProvided(t *testing.T) {
 
 	firebaseRepo := new(FirebaseMockRepository)
 	userRepo := new(UserMockRepository)
-	authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 	e.GET("/", func(c echo.Context) error {
 		data, _, err := ParseUserData(c, util.StatusCustomer)
@@ -348,7 +367,7 @@ func TestAuthMiddlewareInternalServerError(t *testing.T) {
 
 	firebaseRepo := new(FirebaseMockRepository)
 	userRepo := new(UserMockRepository)
-	authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 	e.GET("/", func(c echo.Context) error {
 		data, _, err := ParseUserData(c, util.StatusCustomer)
@@ -381,7 +400,7 @@ func TestAuthMiddlewareInputValidationError(t *testing.T) {
 
 	firebaseRepo := new(FirebaseMockRepository)
 	userRepo := new(UserMockRepository)
-	authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 	e.GET("/", func(c echo.Context) error {
 		data, _, err := ParseUserData(c, util.StatusCustomer)
@@ -415,7 +434,7 @@ func TestAuthMiddleware_ErrorFromGetUserData(t *testing.T) {
 
 		firebaseRepo := new(FirebaseMockRepository)
 		userRepo := new(UserMockRepository)
-		authMock := NewAuthMiddleware(firebaseRepo, userRepo)
+		authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
 
 		e.GET("/", func(c echo.Context) error {
 			data, _, err := ParseUserData(c, util.StatusCustomer)
@@ -469,3 +488,244 @@ func TestAuthMiddleware_ErrorFromGetUserData(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, res.Code)
 	})
 }
+
+func TestAuthMiddlewareBearerWithoutToken(t *testing.T) {
+	e := echo.New()
+
+	firebaseRepo := new(FirebaseMockRepository)
+	userRepo := new(UserMockRepository)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
+
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, authMock.AuthMiddleware())
+
+	for _, header := range []string{"Bearer", "Bearer ", "Bearer token extra", "   "} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, header)
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusUnauthorized, res.Code, header)
+	}
+	firebaseRepo.AssertNotCalled(t, "GetUserDataFromToken", mock.Anything)
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	e := echo.New()
+
+	firebaseRepo := new(FirebaseMockRepository)
+	userRepo := new(UserMockRepository)
+	authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
+
+	e.GET("/", func(c echo.Context) error {
+		_, userModel, err := ParseUserData(c, util.StatusCustomer)
+		if err != nil {
+			return c.String(http.StatusOK, "anonymous")
+		}
+		return c.JSON(http.StatusOK, userModel)
+	}, authMock.OptionalAuthMiddleware())
+
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID:          "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "phone"}},
+			},
+		},
+	}
+	userModel := user.Model{ID: 1, Name: "Rafi"}
+
+	t.Run("success anonymous", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "anonymous", res.Body.String())
+	})
+
+	t.Run("success with user", func(t *testing.T) {
+		expectedRes, _ := json.Marshal(&userModel)
+		firebaseRepo.On("GetUserDataFromToken", "testtoken").Return(&userData, nil)
+		userRepo.On("GetUserIDByLocalID", "1").Return(&userModel, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer testtoken")
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, string(expectedRes)+"\n", res.Body.String())
+	})
+
+	t.Run("failed malformed token", func(t *testing.T) {
+		for _, header := range []string{"", "Bearer", "Basic dXNlcjpwYXNz"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAuthorization, header)
+			res := httptest.NewRecorder()
+
+			e.ServeHTTP(res, req)
+			assert.Equal(t, http.StatusUnauthorized, res.Code, header)
+		}
+	})
+
+	t.Run("failed invalid token", func(t *testing.T) {
+		var emptyUserData firebaseauth.UserDataFromToken
+		firebaseRepo.On("GetUserDataFromToken", "expired").Return(&emptyUserData, errors.Wrap(firebaseauth.ErrInputValidation, "token expired"))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer expired")
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+}
+
+func TestParseUserDataAnonymous(t *testing.T) {
+	e := echo.New()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	userFromFirebase, userFromDatabase, err := ParseUserData(ctx, util.StatusCustomer)
+	assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+	assert.Nil(t, userFromFirebase)
+	assert.Nil(t, userFromDatabase)
+
+	ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{})
+	_, _, err = ParseUserData(ctx, util.StatusBusinessAdmin)
+	assert.Equal(t, ErrUnauthorized, errors.Cause(err))
+}
+
+func TestParseLocalID(t *testing.T) {
+	e := echo.New()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Equal(t, "", ParseLocalID(ctx))
+
+	ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{Users: []firebaseauth.User{{LocalID: "1"}}})
+	assert.Equal(t, "1", ParseLocalID(ctx))
+}
+
+func TestAuthMiddlewareUserCache(t *testing.T) {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID:          "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "phone"}},
+			},
+		},
+	}
+
+	newServer := func(firebaseRepo *FirebaseMockRepository, userRepo *UserMockRepository) *echo.Echo {
+		e := echo.New()
+		authMock := NewAuthMiddleware(firebaseRepo, revokedTokens{}, userRepo)
+		e.GET("/", func(c echo.Context) error {
+			_, userModel, err := ParseUserData(c, util.StatusCustomer)
+			if err != nil {
+				return c.JSON(http.StatusForbidden, err)
+			}
+			return c.JSON(http.StatusOK, userModel)
+		}, authMock.AuthMiddleware())
+		return e
+	}
+
+	serve := func(e *echo.Echo) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer testtoken")
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("registered user is read once", func(t *testing.T) {
+		firebaseRepo := new(FirebaseMockRepository)
+		userRepo := new(UserMockRepository)
+		e := newServer(firebaseRepo, userRepo)
+
+		firebaseRepo.On("GetUserDataFromToken", "testtoken").Return(&userData, nil)
+		userRepo.On("GetUserIDByLocalID", "1").Return(&user.Model{ID: 1}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, serve(e).Code)
+		res := serve(e)
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"ID":1`)
+		userRepo.AssertNumberOfCalls(t, "GetUserIDByLocalID", 1)
+	})
+
+	t.Run("unregistered user is not cached", func(t *testing.T) {
+		firebaseRepo := new(FirebaseMockRepository)
+		userRepo := new(UserMockRepository)
+		e := newServer(firebaseRepo, userRepo)
+
+		var emptyUser *user.Model
+		firebaseRepo.On("GetUserDataFromToken", "testtoken").Return(&userData, nil)
+		userRepo.On("GetUserIDByLocalID", "1").Return(emptyUser, errors.Wrap(user.ErrNotFound, "test error")).Once()
+		userRepo.On("GetUserIDByLocalID", "1").Return(&user.Model{ID: 1}, nil).Once()
+
+		assert.Equal(t, "null\n", serve(e).Body.String())
+		assert.Contains(t, serve(e).Body.String(), `"ID":1`)
+		userRepo.AssertNumberOfCalls(t, "GetUserIDByLocalID", 2)
+	})
+
+	t.Run("failed to get user is not cached", func(t *testing.T) {
+		firebaseRepo := new(FirebaseMockRepository)
+		userRepo := new(UserMockRepository)
+		e := newServer(firebaseRepo, userRepo)
+
+		var emptyUser *user.Model
+		firebaseRepo.On("GetUserDataFromToken", "testtoken").Return(&userData, nil)
+		userRepo.On("GetUserIDByLocalID", "1").Return(emptyUser, errors.Wrap(user.ErrInternalServer, "test error")).Once()
+		userRepo.On("GetUserIDByLocalID", "1").Return(&user.Model{ID: 1}, nil).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, serve(e).Code)
+		assert.Equal(t, http.StatusOK, serve(e).Code)
+		userRepo.AssertNumberOfCalls(t, "GetUserIDByLocalID", 2)
+	})
+}
+
+func TestAuthMiddlewareRevokedToken(t *testing.T) {
+	userData := firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID:          "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "phone"}},
+			},
+		},
+	}
+
+	serve := func(sessionRevocation SessionRevocation, token string) *httptest.ResponseRecorder {
+		firebaseRepo := new(FirebaseMockRepository)
+		userRepo := new(UserMockRepository)
+		firebaseRepo.On("GetUserDataFromToken", token).Return(&userData, nil)
+		userRepo.On("GetUserIDByLocalID", "1").Return(&user.Model{ID: 1}, nil)
+
+		e := echo.New()
+		authMock := NewAuthMiddleware(firebaseRepo, sessionRevocation, userRepo)
+		e.GET("/", func(c echo.Context) error {
+			return c.String(http.StatusOK, ParseToken(c))
+		}, authMock.AuthMiddleware())
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("token is not revoked", func(t *testing.T) {
+		res := serve(revokedTokens{tokens: map[string]bool{"revokedtoken": true}}, "testtoken")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "testtoken", res.Body.String())
+	})
+
+	t.Run("token is revoked", func(t *testing.T) {
+		res := serve(revokedTokens{tokens: map[string]bool{"revokedtoken": true}}, "revokedtoken")
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+		assert.Contains(t, res.Body.String(), "token has been revoked")
+	})
+
+	t.Run("failed to check revocation", func(t *testing.T) {
+		res := serve(revokedTokens{err: errors.New("test error")}, "testtoken")
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+	})
+}
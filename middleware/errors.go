@@ -6,6 +6,9 @@ var (
 	// ErrForbidden if the role is forbidden to access something
 	ErrForbidden = errors.New("user does not have access to this endpoint")
 
+	// ErrUnauthorized if the request does not carry an authenticated user
+	ErrUnauthorized = errors.New("unauthorized")
+
 	// ErrInputValidationError for the error on input validation
 	ErrInputValidationError = errors.New("input validation error")
 )
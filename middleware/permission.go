@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+// rolePermissions map every role of place to the permissions it is granted
+var rolePermissions = map[string][]string{
+	util.RoleOwner: {
+		util.PermissionReadPlace, util.PermissionManagePlace,
+		util.PermissionReadBooking, util.PermissionManageBooking,
+		util.PermissionReadCatalog, util.PermissionManageCatalog,
+		util.PermissionReplyReview,
+		util.PermissionReadFinance, util.PermissionManageFinance,
+		util.PermissionManageWebhook, util.PermissionManageStaff,
+	},
+	util.RoleManager: {
+		util.PermissionReadPlace, util.PermissionManagePlace,
+		util.PermissionReadBooking, util.PermissionManageBooking,
+		util.PermissionReadCatalog, util.PermissionManageCatalog,
+		util.PermissionReplyReview,
+		util.PermissionReadFinance,
+	},
+	util.RoleCashier: {
+		util.PermissionReadPlace,
+		util.PermissionReadBooking, util.PermissionManageBooking,
+		util.PermissionReadCatalog,
+	},
+}
+
+// HasPermission return true if the role is granted the permission
+func HasPermission(role string, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequirePermission reject business admin or staff whose role at the place is not granted the permission, it must be
+// used after AuthMiddleware. Staff act on behalf of the place owner, so userFromDatabase is replaced by the owner and
+// the staff is kept as actorFromDatabase
+func (a AuthMiddleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			_, userModel, err := ParseUserData(ctx, util.StatusBusinessAdmin)
+			if err != nil {
+				return forbidden(ctx, err)
+			}
+
+			if userModel == nil {
+				return forbidden(ctx, errors.Wrap(ErrForbidden, "user is not registered"))
+			}
+
+			membership, err := a.userRepo.GetMembership(userModel.ID)
+			if err != nil {
+				if errors.Cause(err) == user.ErrNotFound {
+					return forbidden(ctx, errors.Wrap(ErrForbidden, "user is not member of any place"))
+				}
+
+				logrus.Error("[failed to get membership from user repo] ", err.Error())
+				return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
+					Status:  http.StatusInternalServerError,
+					Message: "internal server error",
+				})
+			}
+
+			if !HasPermission(membership.Role, permission) {
+				return forbidden(ctx, errors.Wrapf(ErrForbidden, "role %s does not have %s permission", membership.Role, permission))
+			}
+
+			if membership.OwnerID != userModel.ID {
+				owner, err := a.userRepo.GetUserByID(membership.OwnerID)
+				if err != nil {
+					logrus.Error("[failed to get place owner from user repo] ", err.Error())
+					return ctx.JSON(http.StatusInternalServerError, util.APIResponse{
+						Status:  http.StatusInternalServerError,
+						Message: "internal server error",
+					})
+				}
+
+				ctx.Set("userFromDatabase", owner)
+			}
+
+			ctx.Set("actorFromDatabase", userModel)
+			ctx.Set("membership", membership)
+			return next(ctx)
+		}
+	}
+}
+
+// ParseMembership is used to get the membership resolved by RequirePermission
+func ParseMembership(ctx echo.Context) (*user.Membership, error) {
+	membership, ok := ctx.Get("membership").(*user.Membership)
+	if !ok || membership == nil {
+		return nil, errors.Wrap(ErrForbidden, "membership is not resolved")
+	}
+
+	return membership, nil
+}
+
+func forbidden(ctx echo.Context, err error) error {
+	errorList, _ := util.ErrorUnwrap(err)
+	return ctx.JSON(http.StatusForbidden, util.APIResponse{
+		Status:  http.StatusForbidden,
+		Message: "forbidden",
+		Errors:  errorList,
+	})
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/pkg/firebase_auth"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/util"
+)
+
+func newBusinessAdminContext(e *echo.Echo, userModel *user.Model) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{
+			{
+				LocalID:          "1",
+				ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "password"}},
+			},
+		},
+	})
+	ctx.Set("userFromDatabase", userModel)
+
+	return ctx, rec
+}
+
+func runRequirePermission(t *testing.T, authMiddleware AuthMiddleware, ctx echo.Context, permission string) bool {
+	called := false
+	err := authMiddleware.RequirePermission(permission)(func(c echo.Context) error {
+		called = true
+		return nil
+	})(ctx)
+	assert.NoError(t, err)
+
+	return called
+}
+
+func TestHasPermission(t *testing.T) {
+	assert.True(t, HasPermission(util.RoleOwner, util.PermissionManageFinance))
+	assert.True(t, HasPermission(util.RoleManager, util.PermissionManageCatalog))
+	assert.False(t, HasPermission(util.RoleManager, util.PermissionManageFinance))
+	assert.True(t, HasPermission(util.RoleCashier, util.PermissionManageBooking))
+	assert.False(t, HasPermission(util.RoleCashier, util.PermissionManageFinance))
+	assert.False(t, HasPermission(util.RoleCashier, util.PermissionManageCatalog))
+	assert.False(t, HasPermission("unknown", util.PermissionReadPlace))
+}
+
+func TestRequirePermissionOwner(t *testing.T) {
+	e := echo.New()
+	userRepo := new(UserMockRepository)
+	authMiddleware := NewAuthMiddleware(new(FirebaseMockRepository), revokedTokens{}, userRepo)
+
+	owner := &user.Model{ID: 1, Status: util.StatusBusinessAdmin}
+	membership := &user.Membership{PlaceID: 10, OwnerID: 1, Role: util.RoleOwner}
+	userRepo.On("GetMembership", 1).Return(membership, nil)
+
+	ctx, _ := newBusinessAdminContext(e, owner)
+	assert.True(t, runRequirePermission(t, authMiddleware, ctx, util.PermissionManageFinance))
+	assert.Equal(t, owner, ctx.Get("userFromDatabase"))
+	assert.Equal(t, owner, ctx.Get("actorFromDatabase"))
+
+	parsed, err := ParseMembership(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, membership, parsed)
+	userRepo.AssertNotCalled(t, "GetUserByID", 1)
+}
+
+func TestRequirePermissionCashier(t *testing.T) {
+	e := echo.New()
+	userRepo := new(UserMockRepository)
+	authMiddleware := NewAuthMiddleware(new(FirebaseMockRepository), revokedTokens{}, userRepo)
+
+	owner := &user.Model{ID: 1, Status: util.StatusBusinessAdmin}
+	cashier := &user.Model{ID: 2, Status: util.StatusBusinessAdmin}
+	userRepo.On("GetMembership", 2).Return(&user.Membership{PlaceID: 10, OwnerID: 1, Role: util.RoleCashier}, nil)
+	userRepo.On("GetUserByID", 1).Return(owner, nil)
+
+	t.Run("allowed to manage booking on behalf of owner", func(t *testing.T) {
+		ctx, _ := newBusinessAdminContext(e, cashier)
+		assert.True(t, runRequirePermission(t, authMiddleware, ctx, util.PermissionManageBooking))
+		assert.Equal(t, owner, ctx.Get("userFromDatabase"))
+		assert.Equal(t, cashier, ctx.Get("actorFromDatabase"))
+	})
+
+	for _, permission := range []string{util.PermissionManageFinance, util.PermissionManageCatalog, util.PermissionManageStaff} {
+		t.Run("forbidden "+permission, func(t *testing.T) {
+			ctx, rec := newBusinessAdminContext(e, cashier)
+			assert.False(t, runRequirePermission(t, authMiddleware, ctx, permission))
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Equal(t, cashier, ctx.Get("userFromDatabase"))
+		})
+	}
+}
+
+func TestRequirePermissionNotMember(t *testing.T) {
+	e := echo.New()
+	userRepo := new(UserMockRepository)
+	authMiddleware := NewAuthMiddleware(new(FirebaseMockRepository), revokedTokens{}, userRepo)
+
+	userRepo.On("GetMembership", 3).Return(&user.Membership{}, errors.Wrap(user.ErrNotFound, "not member"))
+
+	ctx, rec := newBusinessAdminContext(e, &user.Model{ID: 3, Status: util.StatusBusinessAdmin})
+	assert.False(t, runRequirePermission(t, authMiddleware, ctx, util.PermissionReadPlace))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRequirePermissionInternalServerError(t *testing.T) {
+	e := echo.New()
+	userRepo := new(UserMockRepository)
+	authMiddleware := NewAuthMiddleware(new(FirebaseMockRepository), revokedTokens{}, userRepo)
+
+	userRepo.On("GetMembership", 4).Return(&user.Membership{}, errors.Wrap(user.ErrInternalServer, "test"))
+
+	ctx, rec := newBusinessAdminContext(e, &user.Model{ID: 4, Status: util.StatusBusinessAdmin})
+	assert.False(t, runRequirePermission(t, authMiddleware, ctx, util.PermissionReadPlace))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestRequirePermissionCustomer(t *testing.T) {
+	e := echo.New()
+	authMiddleware := NewAuthMiddleware(new(FirebaseMockRepository), revokedTokens{}, new(UserMockRepository))
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	ctx.Set("userFromFirebase", &firebaseauth.UserDataFromToken{
+		Users: []firebaseauth.User{{ProviderUserInfo: []firebaseauth.ProviderUserInfo{{ProviderID: "phone"}}}},
+	})
+
+	assert.False(t, runRequirePermission(t, authMiddleware, ctx, util.PermissionReadPlace))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	_, err := ParseMembership(ctx)
+	assert.Equal(t, ErrForbidden, errors.Cause(err))
+}
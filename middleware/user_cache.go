@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+)
+
+type userCacheEntry struct {
+	localID   string
+	user      *user.Model
+	expiresAt time.Time
+}
+
+// userCache is least recently used cache of registered user by firebase local ID,
+// entry is also dropped after ttl so changes of the user is picked up eventually
+type userCache struct {
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newUserCache(size int, ttl time.Duration) *userCache {
+	return &userCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *userCache) get(localID string) (*user.Model, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[localID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*userCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, localID)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.user, true
+}
+
+func (c *userCache) set(localID string, userModel *user.Model) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[localID]; ok {
+		entry := element.Value.(*userCacheEntry)
+		entry.user = userModel
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[localID] = c.order.PushFront(&userCacheEntry{localID: localID, user: userModel, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*userCacheEntry).localID)
+	}
+}
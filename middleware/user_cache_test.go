@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.cs.ui.ac.id/ppl-fasilkom-ui/2022/Kelas-B/OOP/majapahit-service/internal/user"
+)
+
+func TestUserCache(t *testing.T) {
+	t.Run("least recently used is evicted", func(t *testing.T) {
+		cache := newUserCache(2, time.Minute)
+		cache.set("1", &user.Model{ID: 1})
+		cache.set("2", &user.Model{ID: 2})
+
+		_, ok := cache.get("1")
+		assert.True(t, ok)
+
+		cache.set("3", &user.Model{ID: 3})
+
+		_, ok = cache.get("2")
+		assert.False(t, ok)
+
+		cached, ok := cache.get("1")
+		assert.True(t, ok)
+		assert.Equal(t, 1, cached.ID)
+
+		cached, ok = cache.get("3")
+		assert.True(t, ok)
+		assert.Equal(t, 3, cached.ID)
+	})
+
+	t.Run("updated entry is not duplicated", func(t *testing.T) {
+		cache := newUserCache(2, time.Minute)
+		cache.set("1", &user.Model{ID: 1})
+		cache.set("1", &user.Model{ID: 10})
+
+		cached, ok := cache.get("1")
+		assert.True(t, ok)
+		assert.Equal(t, 10, cached.ID)
+		assert.Equal(t, 1, cache.order.Len())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now := time.Now()
+		cache := newUserCache(2, time.Minute)
+		cache.now = func() time.Time { return now }
+		cache.set("1", &user.Model{ID: 1})
+
+		now = now.Add(time.Minute)
+		_, ok := cache.get("1")
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.order.Len())
+	})
+}
@@ -48,10 +48,57 @@ type VerifyOTPResult struct {
 	PhoneNumber  string `json:"phoneNumber"`
 }
 
-// UserDataFromToken is user information after calling get user data from token function
+// SignInWithPasswordParams is parameter for calling sign in with password function
+type SignInWithPasswordParams struct {
+	Email          string `json:"email"`
+	Password       string `json:"password"`
+	RecaptchaToken string `json:"recaptcha_token"`
+}
+
+// SignInWithPasswordResult is result of calling sign in with password function
+type SignInWithPasswordResult struct {
+	IDToken      string `json:"idToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    string `json:"expiresIn"`
+	LocalID      string `json:"localId"`
+	Email        string `json:"email"`
+}
+
+// RefreshTokenResult is result of exchanging refresh token for a new ID token
+type RefreshTokenResult struct {
+	IDToken      string    `json:"id_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    string    `json:"expires_in"`
+	UserID       string    `json:"user_id"`
+	AuthTime     time.Time `json:"-"`
+}
+
+// UpdatePasswordParams is parameter for updating password of the signed in user
+type UpdatePasswordParams struct {
+	IDToken  string `json:"idToken"`
+	LocalID  string `json:"localId"`
+	Password string `json:"password"`
+}
+
+// ResetPasswordParams is parameter for resetting password by the code sent to the email,
+// the code is only verified when NewPassword is empty
+type ResetPasswordParams struct {
+	OOBCode     string `json:"oobCode"`
+	NewPassword string `json:"newPassword,omitempty"`
+}
+
+// ResetPasswordResult is result of calling reset password function
+type ResetPasswordResult struct {
+	Email       string `json:"email"`
+	RequestType string `json:"requestType"`
+}
+
+// UserDataFromToken is user information after calling get user data from token function,
+// AuthTime is when the user signed in and it is kept when the token is refreshed
 type UserDataFromToken struct {
-	Kind  string `json:"kind"`
-	Users []User `json:"users"`
+	Kind     string    `json:"kind"`
+	Users    []User    `json:"users"`
+	AuthTime time.Time `json:"-"`
 }
 
 // User object from firebase
@@ -7,7 +7,9 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/pkg/errors"
 )
 
@@ -15,6 +17,11 @@ import (
 type Repo interface {
 	SendOTP(params SendOTPParams) (*SendOTPResult, error)
 	VerifyOTP(params VerifyOTPParams) (*VerifyOTPResult, error)
+	SignInWithPassword(params SignInWithPasswordParams) (*SignInWithPasswordResult, error)
+	RefreshToken(refreshToken string) (*RefreshTokenResult, error)
+	UpdatePassword(params UpdatePasswordParams) error
+	SendPasswordResetEmail(email string) error
+	ResetPassword(params ResetPasswordParams) (*ResetPasswordResult, error)
 	GetUserDataFromToken(token string) (*UserDataFromToken, error)
 }
 
@@ -130,6 +137,220 @@ func (r repo) SendOTP(params SendOTPParams) (*SendOTPResult, error) {
 	}, nil
 }
 
+func (r repo) SignInWithPassword(params SignInWithPasswordParams) (*SignInWithPasswordResult, error) {
+	URL := fmt.Sprintf("%s/v1/accounts:signInWithPassword?key=%s", r.identityToolkitURL, r.APIKey)
+
+	reqBody := map[string]interface{}{
+		"email":             params.Email,
+		"password":          params.Password,
+		"captchaResponse":   params.RecaptchaToken,
+		"returnSecureToken": true,
+	}
+
+	reqBodyJSON, _ := json.Marshal(reqBody)
+
+	resp, err := http.Post(URL, "application/json", bytes.NewBuffer(reqBodyJSON))
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	respBuffer, _ := io.ReadAll(resp.Body)
+
+	var respSuccess SignInWithPasswordResult
+	err = json.Unmarshal(respBuffer, &respSuccess)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	if respSuccess.IDToken == "" {
+		var errorResponse ErrorFromFirebase
+
+		err = json.Unmarshal(respBuffer, &errorResponse)
+		if err != nil {
+			return nil, errors.Wrap(ErrInternalServer, err.Error())
+		}
+
+		if strings.Contains(errorResponse.Error.Message, "EMAIL_NOT_FOUND") || strings.Contains(errorResponse.Error.Message, "INVALID_PASSWORD") {
+			return nil, errors.Wrap(ErrInputValidation, "wrong email/password")
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, errorResponse.Error.Message)
+	}
+
+	return &respSuccess, nil
+}
+
+func (r repo) RefreshToken(refreshToken string) (*RefreshTokenResult, error) {
+	URL := fmt.Sprintf("%s/v1/token?key=%s", r.secureTokenURL, r.APIKey)
+
+	reqBody := map[string]interface{}{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}
+
+	reqBodyJSON, _ := json.Marshal(reqBody)
+
+	resp, err := http.Post(URL, "application/json", bytes.NewBuffer(reqBodyJSON))
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	respBuffer, _ := io.ReadAll(resp.Body)
+
+	var respSuccess RefreshTokenResult
+	err = json.Unmarshal(respBuffer, &respSuccess)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	if respSuccess.IDToken == "" {
+		var errorResponse ErrorFromFirebase
+
+		err = json.Unmarshal(respBuffer, &errorResponse)
+		if err != nil {
+			return nil, errors.Wrap(ErrInternalServer, err.Error())
+		}
+
+		for _, message := range []string{"TOKEN_EXPIRED", "INVALID_REFRESH_TOKEN", "MISSING_REFRESH_TOKEN", "USER_DISABLED", "USER_NOT_FOUND"} {
+			if strings.Contains(errorResponse.Error.Message, message) {
+				return nil, errors.Wrap(ErrInputValidation, "invalid refresh token")
+			}
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, errorResponse.Error.Message)
+	}
+
+	respSuccess.AuthTime = authTimeOfToken(respSuccess.IDToken)
+	return &respSuccess, nil
+}
+
+// UpdatePassword change the password of the firebase account, firebase only allow it with the ID token of the user
+func (r repo) UpdatePassword(params UpdatePasswordParams) error {
+	if params.IDToken == "" {
+		return errors.Wrap(ErrInputValidation, "password of firebase account can only be updated by the signed in user")
+	}
+
+	URL := fmt.Sprintf("%s/v1/accounts:update?key=%s", r.identityToolkitURL, r.APIKey)
+
+	reqBody := map[string]interface{}{
+		"idToken":           params.IDToken,
+		"password":          params.Password,
+		"returnSecureToken": false,
+	}
+
+	reqBodyJSON, _ := json.Marshal(reqBody)
+
+	resp, err := http.Post(URL, "application/json", bytes.NewBuffer(reqBodyJSON))
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	respBuffer, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse ErrorFromFirebase
+
+		err = json.Unmarshal(respBuffer, &errorResponse)
+		if err != nil {
+			return errors.Wrap(ErrInternalServer, err.Error())
+		}
+
+		if strings.Contains(errorResponse.Error.Message, "WEAK_PASSWORD") {
+			return errors.Wrap(ErrInputValidation, "password is too weak")
+		}
+
+		for _, message := range []string{"INVALID_ID_TOKEN", "TOKEN_EXPIRED", "CREDENTIAL_TOO_OLD_LOGIN_AGAIN"} {
+			if strings.Contains(errorResponse.Error.Message, message) {
+				return errors.Wrap(ErrInputValidation, "session expired, please login again")
+			}
+		}
+
+		return errors.Wrap(ErrInternalServer, errorResponse.Error.Message)
+	}
+
+	return nil
+}
+
+// SendPasswordResetEmail let firebase send the email containing the password reset code
+func (r repo) SendPasswordResetEmail(email string) error {
+	URL := fmt.Sprintf("%s/v1/accounts:sendOobCode?key=%s", r.identityToolkitURL, r.APIKey)
+
+	reqBody := map[string]interface{}{
+		"requestType": "PASSWORD_RESET",
+		"email":       email,
+	}
+
+	reqBodyJSON, _ := json.Marshal(reqBody)
+
+	resp, err := http.Post(URL, "application/json", bytes.NewBuffer(reqBodyJSON))
+	if err != nil {
+		return errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	respBuffer, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse ErrorFromFirebase
+
+		err = json.Unmarshal(respBuffer, &errorResponse)
+		if err != nil {
+			return errors.Wrap(ErrInternalServer, err.Error())
+		}
+
+		if strings.Contains(errorResponse.Error.Message, "EMAIL_NOT_FOUND") {
+			return errors.Wrap(ErrInputValidation, "email not found")
+		}
+
+		return errors.Wrap(ErrInternalServer, errorResponse.Error.Message)
+	}
+
+	return nil
+}
+
+// ResetPassword verify the password reset code and replace the password when NewPassword is given,
+// the code can be verified many times but it can only be used once to replace the password
+func (r repo) ResetPassword(params ResetPasswordParams) (*ResetPasswordResult, error) {
+	URL := fmt.Sprintf("%s/v1/accounts:resetPassword?key=%s", r.identityToolkitURL, r.APIKey)
+
+	reqBodyJSON, _ := json.Marshal(params)
+
+	resp, err := http.Post(URL, "application/json", bytes.NewBuffer(reqBodyJSON))
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	respBuffer, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse ErrorFromFirebase
+
+		err = json.Unmarshal(respBuffer, &errorResponse)
+		if err != nil {
+			return nil, errors.Wrap(ErrInternalServer, err.Error())
+		}
+
+		if strings.Contains(errorResponse.Error.Message, "WEAK_PASSWORD") {
+			return nil, errors.Wrap(ErrInputValidation, "password is too weak")
+		}
+
+		for _, message := range []string{"INVALID_OOB_CODE", "EXPIRED_OOB_CODE", "USER_DISABLED"} {
+			if strings.Contains(errorResponse.Error.Message, message) {
+				return nil, errors.Wrap(ErrInputValidation, "reset code is invalid or expired")
+			}
+		}
+
+		return nil, errors.Wrap(ErrInternalServer, errorResponse.Error.Message)
+	}
+
+	var respSuccess ResetPasswordResult
+	err = json.Unmarshal(respBuffer, &respSuccess)
+	if err != nil {
+		return nil, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	return &respSuccess, nil
+}
+
 func (r repo) GetUserDataFromToken(token string) (*UserDataFromToken, error) {
 	URL := fmt.Sprintf("%s/v1/accounts:lookup?key=%s", r.identityToolkitURL, r.APIKey)
 
@@ -167,9 +388,21 @@ func (r repo) GetUserDataFromToken(token string) (*UserDataFromToken, error) {
 		return nil, errors.Wrap(ErrInternalServer, errorResponse.Error.Message)
 	}
 
+	userData.AuthTime = authTimeOfToken(token)
 	return &userData, nil
 }
 
+// authTimeOfToken read auth_time of the ID token without verifying it, so it must only be used for the token
+// which is verified or issued by firebase
+func authTimeOfToken(token string) time.Time {
+	var claims idTokenClaims
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, &claims); err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.AuthTime, 0)
+}
+
 // NewRepo for initialize repo
 func NewRepo(identityToolkitURL, secureTokenURL, APIKey string) Repo {
 	return &repo{
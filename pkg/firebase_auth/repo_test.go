@@ -4,6 +4,8 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -362,3 +364,224 @@ func TestRepo_GetUserData(t *testing.T) {
 		assert.Nil(t, res)
 	})
 }
+
+func TestRepo_SignInWithPassword(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	params := SignInWithPasswordParams{Email: "test@gmail.com", Password: "password"}
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(`{"idToken": "id token", "refreshToken": "refresh token", "localId": "local-id"}`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").SignInWithPassword(params)
+		assert.Nil(t, err)
+		assert.Equal(t, "id token", res.IDToken)
+		assert.Equal(t, "refresh token", res.RefreshToken)
+	})
+
+	t.Run("wrong email/password", func(t *testing.T) {
+		server := newServer(`{"error": {"code": 400, "message": "INVALID_PASSWORD"}}`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").SignInWithPassword(params)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("other error from firebase", func(t *testing.T) {
+		server := newServer(`{"error": {"code": 400, "message": "TOO_MANY_ATTEMPTS_TRY_LATER"}}`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").SignInWithPassword(params)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed unmarshal", func(t *testing.T) {
+		server := newServer(`not json`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").SignInWithPassword(params)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepo_RefreshToken(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(`{"id_token": "id token", "refresh_token": "refresh token", "expires_in": "3600", "user_id": "local-id"}`)
+		defer server.Close()
+
+		res, err := NewRepo("", server.URL, "key").RefreshToken("refresh token")
+		assert.Nil(t, err)
+		assert.Equal(t, "id token", res.IDToken)
+		assert.Equal(t, "refresh token", res.RefreshToken)
+		assert.Equal(t, "local-id", res.UserID)
+	})
+
+	t.Run("invalid refresh token", func(t *testing.T) {
+		server := newServer(`{"error": {"code": 400, "message": "INVALID_REFRESH_TOKEN"}}`)
+		defer server.Close()
+
+		res, err := NewRepo("", server.URL, "key").RefreshToken("refresh token")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("other error from firebase", func(t *testing.T) {
+		server := newServer(`{"error": {"code": 400, "message": "PROJECT_NUMBER_MISMATCH"}}`)
+		defer server.Close()
+
+		res, err := NewRepo("", server.URL, "key").RefreshToken("refresh token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed in http client post", func(t *testing.T) {
+		res, err := NewRepo("", "localhost:8001", "key").RefreshToken("refresh token")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepo_UpdatePassword(t *testing.T) {
+	newServer := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	params := UpdatePasswordParams{IDToken: "id token", LocalID: "local-id", Password: "Password123"}
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(http.StatusOK, `{"localId": "local-id"}`)
+		defer server.Close()
+
+		assert.Nil(t, NewRepo(server.URL, "", "key").UpdatePassword(params))
+	})
+
+	t.Run("without ID token", func(t *testing.T) {
+		err := NewRepo("", "", "key").UpdatePassword(UpdatePasswordParams{LocalID: "local-id", Password: "Password123"})
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "WEAK_PASSWORD : Password should be at least 6 characters"}}`)
+		defer server.Close()
+
+		err := NewRepo(server.URL, "", "key").UpdatePassword(params)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("credential too old", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "CREDENTIAL_TOO_OLD_LOGIN_AGAIN"}}`)
+		defer server.Close()
+
+		err := NewRepo(server.URL, "", "key").UpdatePassword(params)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("other error from firebase", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "OPERATION_NOT_ALLOWED"}}`)
+		defer server.Close()
+
+		err := NewRepo(server.URL, "", "key").UpdatePassword(params)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+
+	t.Run("failed unmarshal", func(t *testing.T) {
+		server := newServer(http.StatusInternalServerError, `not json`)
+		defer server.Close()
+
+		err := NewRepo(server.URL, "", "key").UpdatePassword(params)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_SendPasswordResetEmail(t *testing.T) {
+	newServer := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(http.StatusOK, `{"email": "test@gmail.com"}`)
+		defer server.Close()
+
+		assert.Nil(t, NewRepo(server.URL, "", "key").SendPasswordResetEmail("test@gmail.com"))
+	})
+
+	t.Run("email not found", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "EMAIL_NOT_FOUND"}}`)
+		defer server.Close()
+
+		err := NewRepo(server.URL, "", "key").SendPasswordResetEmail("test@gmail.com")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+	})
+
+	t.Run("other error from firebase", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "RESET_PASSWORD_EXCEED_LIMIT"}}`)
+		defer server.Close()
+
+		err := NewRepo(server.URL, "", "key").SendPasswordResetEmail("test@gmail.com")
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+	})
+}
+
+func TestRepo_ResetPassword(t *testing.T) {
+	newServer := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	params := ResetPasswordParams{OOBCode: "code", NewPassword: "Password123"}
+
+	t.Run("success", func(t *testing.T) {
+		server := newServer(http.StatusOK, `{"email": "test@gmail.com", "requestType": "PASSWORD_RESET"}`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").ResetPassword(params)
+		assert.Nil(t, err)
+		assert.Equal(t, &ResetPasswordResult{Email: "test@gmail.com", RequestType: "PASSWORD_RESET"}, res)
+	})
+
+	t.Run("invalid or expired code", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "EXPIRED_OOB_CODE"}}`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").ResetPassword(params)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"error": {"code": 400, "message": "WEAK_PASSWORD : Password should be at least 6 characters"}}`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").ResetPassword(params)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed unmarshal", func(t *testing.T) {
+		server := newServer(http.StatusInternalServerError, `not json`)
+		defer server.Close()
+
+		res, err := NewRepo(server.URL, "", "key").ResetPassword(params)
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
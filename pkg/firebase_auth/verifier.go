@@ -0,0 +1,236 @@
+package firebaseauth
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// GoogleCertsURL is the x509 certificates used by firebase to sign the ID token
+	GoogleCertsURL = "https://www.googleapis.com/robot/v1/metadata/x509/securetoken@system.gserviceaccount.com"
+
+	issuerPrefix       = "https://securetoken.google.com/"
+	defaultKeysMaxAge  = time.Hour
+	keysRetryBackoff   = time.Minute
+	getAccountInfoKind = "identitytoolkit#GetAccountInfoResponse"
+)
+
+// TokenVerifier verify ID token and return the user data inside of it
+type TokenVerifier interface {
+	GetUserDataFromToken(token string) (*UserDataFromToken, error)
+}
+
+// KeySource provide the public keys used to sign the ID token by its key ID
+type KeySource interface {
+	Keys() (map[string]*rsa.PublicKey, error)
+}
+
+type idTokenClaims struct {
+	jwt.StandardClaims
+	AuthTime      int64  `json:"auth_time"`
+	PhoneNumber   string `json:"phone_number"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Firebase      struct {
+		SignInProvider string `json:"sign_in_provider"`
+	} `json:"firebase"`
+}
+
+type verifier struct {
+	projectID string
+	keys      KeySource
+}
+
+// NewTokenVerifier for initialize verifier which verify the ID token locally with the public keys from key source
+func NewTokenVerifier(projectID string, keys KeySource) TokenVerifier {
+	return &verifier{
+		projectID: projectID,
+		keys:      keys,
+	}
+}
+
+func (v verifier) GetUserDataFromToken(token string) (*UserDataFromToken, error) {
+	keys, err := v.keys.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var claims idTokenClaims
+	_, err = jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		key, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %s", kid)
+		}
+
+		return key, nil
+	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, errors.Wrap(ErrInputValidation, "token expired")
+		}
+
+		return nil, errors.Wrap(ErrInputValidation, "token invalid")
+	}
+
+	if !claims.VerifyAudience(v.projectID, true) {
+		return nil, errors.Wrap(ErrInputValidation, "token has invalid audience")
+	}
+
+	if !claims.VerifyIssuer(issuerPrefix+v.projectID, true) {
+		return nil, errors.Wrap(ErrInputValidation, "token has invalid issuer")
+	}
+
+	if claims.Subject == "" || claims.AuthTime > jwt.TimeFunc().Unix() {
+		return nil, errors.Wrap(ErrInputValidation, "token invalid")
+	}
+
+	return &UserDataFromToken{
+		Kind:     getAccountInfoKind,
+		AuthTime: time.Unix(claims.AuthTime, 0),
+		Users: []User{
+			{
+				LocalID: claims.Subject,
+				ProviderUserInfo: []ProviderUserInfo{
+					{
+						ProviderID:  claims.Firebase.SignInProvider,
+						RawID:       claims.Subject,
+						PhoneNumber: claims.PhoneNumber,
+						Email:       claims.Email,
+					},
+				},
+				PhoneNumber:   claims.PhoneNumber,
+				Email:         claims.Email,
+				EmailVerified: claims.EmailVerified,
+			},
+		},
+	}, nil
+}
+
+type staticKeySource struct {
+	keys map[string]*rsa.PublicKey
+}
+
+// NewStaticKeySource for initialize key source with fixed public keys
+func NewStaticKeySource(keys map[string]*rsa.PublicKey) KeySource {
+	return &staticKeySource{keys: keys}
+}
+
+func (s staticKeySource) Keys() (map[string]*rsa.PublicKey, error) {
+	return s.keys, nil
+}
+
+type googleKeySource struct {
+	certsURL  string
+	client    *http.Client
+	now       func() time.Time
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	expiresAt time.Time
+}
+
+// NewGoogleKeySource for initialize key source which fetch the certificates from certsURL,
+// the keys are cached until the max-age of the response
+func NewGoogleKeySource(certsURL string, client *http.Client) KeySource {
+	return &googleKeySource{
+		certsURL: certsURL,
+		client:   client,
+		now:      time.Now,
+	}
+}
+
+func (g *googleKeySource) Keys() (map[string]*rsa.PublicKey, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if g.keys != nil && now.Before(g.expiresAt) {
+		return g.keys, nil
+	}
+
+	keys, maxAge, err := g.fetch()
+	if err != nil {
+		// keep using the old keys, google publish the new keys long before the old ones are retired,
+		// the refresh is retried after a backoff so requests are not blocked on fetching while google is down
+		if g.keys != nil {
+			logrus.Warn("[failed to refresh google public keys] ", err.Error())
+			g.expiresAt = now.Add(keysRetryBackoff)
+			return g.keys, nil
+		}
+
+		return nil, err
+	}
+
+	g.keys = keys
+	g.expiresAt = now.Add(maxAge)
+	return g.keys, nil
+}
+
+func (g *googleKeySource) fetch() (map[string]*rsa.PublicKey, time.Duration, error) {
+	resp, err := g.client.Get(g.certsURL)
+	if err != nil {
+		return nil, 0, errors.Wrap(ErrInternalServer, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, errors.Wrap(ErrInternalServer, fmt.Sprintf("unexpected status code %d from %s", resp.StatusCode, g.certsURL))
+	}
+
+	respBuffer, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	var certs map[string]string
+	if err = json.Unmarshal(respBuffer, &certs); err != nil {
+		return nil, 0, errors.Wrap(ErrInternalServer, err.Error())
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(certs))
+	for kid, cert := range certs {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cert))
+		if err != nil {
+			return nil, 0, errors.Wrap(ErrInternalServer, fmt.Sprintf("invalid certificate %s: %s", kid, err.Error()))
+		}
+
+		keys[kid] = key
+	}
+
+	return keys, parseMaxAge(resp.Header.Get("Cache-Control")), nil
+}
+
+// parseMaxAge return max-age of Cache-Control header or the default when it is not found
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+		if err != nil || seconds <= 0 {
+			break
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	return defaultKeysMaxAge
+}
@@ -0,0 +1,285 @@
+package firebaseauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProjectID = "test-project"
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	return key
+}
+
+func newTestClaims() idTokenClaims {
+	now := time.Now()
+	claims := idTokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  testProjectID,
+			Issuer:    issuerPrefix + testProjectID,
+			Subject:   "local-id",
+			IssuedAt:  now.Add(-time.Minute).Unix(),
+			ExpiresAt: now.Add(time.Hour).Unix(),
+		},
+		AuthTime:    now.Add(-time.Minute).Unix(),
+		PhoneNumber: "+6281111111111",
+	}
+	claims.Firebase.SignInProvider = "phone"
+	return claims
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, kid string, claims idTokenClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+type failedKeySource struct{}
+
+func (failedKeySource) Keys() (map[string]*rsa.PublicKey, error) {
+	return nil, errors.Wrap(ErrInternalServer, "test error")
+}
+
+func TestVerifier_GetUserDataFromToken(t *testing.T) {
+	key := newTestKey(t)
+	verifier := NewTokenVerifier(testProjectID, NewStaticKeySource(map[string]*rsa.PublicKey{"kid": &key.PublicKey}))
+
+	t.Run("success", func(t *testing.T) {
+		res, err := verifier.GetUserDataFromToken(signTestToken(t, key, "kid", newTestClaims()))
+		assert.Nil(t, err)
+		assert.Equal(t, "local-id", res.Users[0].LocalID)
+		assert.Equal(t, "phone", res.Users[0].ProviderUserInfo[0].ProviderID)
+		assert.Equal(t, "+6281111111111", res.Users[0].PhoneNumber)
+		assert.Equal(t, newTestClaims().AuthTime, res.AuthTime.Unix())
+	})
+
+	invalidClaims := map[string]func(claims *idTokenClaims){
+		"expired": func(claims *idTokenClaims) {
+			claims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+		},
+		"issued in the future": func(claims *idTokenClaims) {
+			claims.IssuedAt = time.Now().Add(time.Hour).Unix()
+		},
+		"authenticated in the future": func(claims *idTokenClaims) {
+			claims.AuthTime = time.Now().Add(time.Hour).Unix()
+		},
+		"invalid audience": func(claims *idTokenClaims) {
+			claims.Audience = "other-project"
+		},
+		"invalid issuer": func(claims *idTokenClaims) {
+			claims.Issuer = issuerPrefix + "other-project"
+		},
+		"empty subject": func(claims *idTokenClaims) {
+			claims.Subject = ""
+		},
+	}
+
+	for name, invalidate := range invalidClaims {
+		t.Run(name, func(t *testing.T) {
+			claims := newTestClaims()
+			invalidate(&claims)
+
+			res, err := verifier.GetUserDataFromToken(signTestToken(t, key, "kid", claims))
+			assert.Equal(t, ErrInputValidation, errors.Cause(err))
+			assert.Nil(t, res)
+		})
+	}
+
+	t.Run("unknown key id", func(t *testing.T) {
+		res, err := verifier.GetUserDataFromToken(signTestToken(t, key, "other-kid", newTestClaims()))
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("signed by other key", func(t *testing.T) {
+		res, err := verifier.GetUserDataFromToken(signTestToken(t, newTestKey(t), "kid", newTestClaims()))
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("unexpected signing method", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims())
+		token.Header["kid"] = "kid"
+		signed, _ := token.SignedString([]byte("secret"))
+
+		res, err := verifier.GetUserDataFromToken(signed)
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		res, err := verifier.GetUserDataFromToken("test token")
+		assert.Equal(t, ErrInputValidation, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+
+	t.Run("failed to get keys", func(t *testing.T) {
+		verifier := NewTokenVerifier(testProjectID, failedKeySource{})
+
+		res, err := verifier.GetUserDataFromToken(signTestToken(t, key, "kid", newTestClaims()))
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, res)
+	})
+}
+
+func newTestCertificate(t *testing.T, key *rsa.PrivateKey) string {
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "securetoken.system.gserviceaccount.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestGoogleKeySource_Keys(t *testing.T) {
+	key := newTestKey(t)
+	certs, _ := json.Marshal(map[string]string{"kid": newTestCertificate(t, key)})
+
+	newServer := func(status *int, hits *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*hits++
+			w.Header().Set("Cache-Control", "public, max-age=600, must-revalidate, no-transform")
+			w.WriteHeader(*status)
+			_, _ = w.Write(certs)
+		}))
+	}
+
+	t.Run("cached until max-age", func(t *testing.T) {
+		status, hits := http.StatusOK, 0
+		server := newServer(&status, &hits)
+		defer server.Close()
+
+		now := time.Now()
+		source := NewGoogleKeySource(server.URL, server.Client()).(*googleKeySource)
+		source.now = func() time.Time { return now }
+
+		keys, err := source.Keys()
+		assert.Nil(t, err)
+		assert.Equal(t, &key.PublicKey, keys["kid"])
+
+		now = now.Add(9 * time.Minute)
+		_, err = source.Keys()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, hits)
+
+		now = now.Add(time.Minute)
+		_, err = source.Keys()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, hits)
+	})
+
+	t.Run("old keys are used when refresh failed", func(t *testing.T) {
+		status, hits := http.StatusOK, 0
+		server := newServer(&status, &hits)
+		defer server.Close()
+
+		now := time.Now()
+		source := NewGoogleKeySource(server.URL, server.Client()).(*googleKeySource)
+		source.now = func() time.Time { return now }
+
+		_, err := source.Keys()
+		assert.Nil(t, err)
+
+		status = http.StatusInternalServerError
+		now = now.Add(time.Hour)
+		keys, err := source.Keys()
+		assert.Nil(t, err)
+		assert.Equal(t, &key.PublicKey, keys["kid"])
+		assert.Equal(t, 2, hits)
+	})
+
+	t.Run("refresh is retried after backoff when fetch failed", func(t *testing.T) {
+		status, hits := http.StatusOK, 0
+		server := newServer(&status, &hits)
+		defer server.Close()
+
+		now := time.Now()
+		source := NewGoogleKeySource(server.URL, server.Client()).(*googleKeySource)
+		source.now = func() time.Time { return now }
+
+		_, err := source.Keys()
+		assert.Nil(t, err)
+
+		status = http.StatusInternalServerError
+		now = now.Add(time.Hour)
+		for i := 0; i < 3; i++ {
+			keys, err := source.Keys()
+			assert.Nil(t, err)
+			assert.Equal(t, &key.PublicKey, keys["kid"])
+		}
+		assert.Equal(t, 2, hits)
+
+		status = http.StatusOK
+		now = now.Add(keysRetryBackoff)
+		_, err = source.Keys()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, hits)
+
+		now = now.Add(9 * time.Minute)
+		_, err = source.Keys()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, hits)
+	})
+
+	t.Run("failed without old keys", func(t *testing.T) {
+		status, hits := http.StatusInternalServerError, 0
+		server := newServer(&status, &hits)
+		defer server.Close()
+
+		keys, err := NewGoogleKeySource(server.URL, server.Client()).Keys()
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, keys)
+	})
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"kid": "not a certificate"}`))
+		}))
+		defer server.Close()
+
+		keys, err := NewGoogleKeySource(server.URL, server.Client()).Keys()
+		assert.Equal(t, ErrInternalServer, errors.Cause(err))
+		assert.Nil(t, keys)
+	})
+}
+
+func TestParseMaxAge(t *testing.T) {
+	assert.Equal(t, 600*time.Second, parseMaxAge("public, max-age=600, must-revalidate"))
+	assert.Equal(t, defaultKeysMaxAge, parseMaxAge("no-cache"))
+	assert.Equal(t, defaultKeysMaxAge, parseMaxAge("max-age=abc"))
+	assert.Equal(t, defaultKeysMaxAge, parseMaxAge(""))
+}
+
+func TestAuthTimeOfToken(t *testing.T) {
+	claims := newTestClaims()
+	assert.Equal(t, claims.AuthTime, authTimeOfToken(signTestToken(t, newTestKey(t), "kid", claims)).Unix())
+	assert.True(t, authTimeOfToken("test token").IsZero())
+}
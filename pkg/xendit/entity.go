@@ -3,11 +3,15 @@ package xendit
 // CreateDisbursementParams for disbursement params
 type CreateDisbursementParams struct {
 	ID                int      `json:"id"`
+	BankCode          string   `json:"bank_code"`
 	BankAccountName   string   `json:"bank_acount_name"`
 	BankAccountNumber string   `json:"bank_acount_number"`
 	Amount            float64  `json:"amount"`
 	Description       string   `json:"description"`
 	Email             []string `json:"email"`
+	IdempotencyKey    string   `json:"idempotency_key"`
+	// ExternalID is sent back by xendit in the callback, the ID is used when it is empty
+	ExternalID string `json:"external_id"`
 }
 
 // CreateInvoiceParams for create invoices params
@@ -18,6 +22,7 @@ type CreateInvoiceParams struct {
 	CustomerName        string  `json:"customer_name"`
 	CustomerPhoneNumber string  `json:"customer_phone_number"`
 	BookingFee          float64 `json:"booking_fee"`
+	WithoutPlatformFee  bool    `json:"without_platform_fee"`
 }
 
 // Item that will be in invoice
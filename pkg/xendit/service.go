@@ -1,8 +1,8 @@
 package xendit
 
 import (
+	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/pkg/errors"
 	"github.com/xendit/xendit-go"
@@ -22,6 +22,7 @@ type Service interface {
 	CreateDisbursement(params CreateDisbursementParams) (*xendit.Disbursement, error)
 	GetInvoice(ID string) (*xendit.Invoice, error)
 	GetDisbursement(ID string) (*xendit.Disbursement, error)
+	GetDisbursementsByExternalID(externalID string) ([]xendit.Disbursement, error)
 }
 
 // NewXenditClient for initialize xendit service
@@ -39,11 +40,17 @@ func (x service) CreateInvoice(params CreateInvoiceParams) (*xendit.Invoice, err
 		})
 	}
 
-	withBookingFee := util.XenditFeesDefault
-	withBookingFee = append(withBookingFee, xendit.InvoiceFee{
-		Type:  "Booking Fee",
-		Value: params.BookingFee,
-	})
+	var withBookingFee []xendit.InvoiceFee
+	if !params.WithoutPlatformFee {
+		withBookingFee = append(withBookingFee, util.XenditFeesDefault...)
+	}
+
+	if params.BookingFee > 0 {
+		withBookingFee = append(withBookingFee, xendit.InvoiceFee{
+			Type:  "Booking Fee",
+			Value: params.BookingFee,
+		})
+	}
 
 	invoiceParams := &invoice.CreateParams{
 		ExternalID:  strconv.Itoa(params.PlaceID),
@@ -82,10 +89,25 @@ func (x service) CreateInvoice(params CreateInvoiceParams) (*xendit.Invoice, err
 }
 
 func (x service) CreateDisbursement(params CreateDisbursementParams) (*xendit.Disbursement, error) {
+	// the key is required so a retried request never create a second disbursement
+	if params.IdempotencyKey == "" {
+		return nil, errors.Wrap(ErrXenditCreateDisbursement, "idempotency key is required")
+	}
+
+	externalID := params.ExternalID
+	if externalID == "" {
+		externalID = strconv.Itoa(params.ID)
+	}
+
+	bankCode := params.BankCode
+	if bankCode == "" {
+		bankCode = util.BankBCA
+	}
+
 	disbursementParams := disbursement.CreateParams{
-		IdempotencyKey:    time.Now().String(),
-		ExternalID:        strconv.Itoa(params.ID),
-		BankCode:          util.BankBCA,
+		IdempotencyKey:    params.IdempotencyKey,
+		ExternalID:        externalID,
+		BankCode:          bankCode,
 		AccountHolderName: params.BankAccountName,
 		AccountNumber:     params.BankAccountNumber,
 		Description:       params.Description,
@@ -127,3 +149,21 @@ func (x service) GetDisbursement(ID string) (*xendit.Disbursement, error) {
 
 	return resp, nil
 }
+
+func (x service) GetDisbursementsByExternalID(externalID string) ([]xendit.Disbursement, error) {
+	params := disbursement.GetByExternalIDParams{
+		ExternalID: externalID,
+	}
+
+	resp, err := x.Client.Disbursement.GetByExternalID(&params)
+	if err != nil {
+		// xendit respond with not found when no disbursement has the external ID yet
+		if err.GetStatus() == http.StatusNotFound {
+			return []xendit.Disbursement{}, nil
+		}
+
+		return nil, errors.Wrap(ErrXenditGetDisbursement, err.Error())
+	}
+
+	return resp, nil
+}
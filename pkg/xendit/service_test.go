@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestService_CreateInvoiceSuccess(t *testing.T) {
@@ -94,6 +95,7 @@ func TestService_CreateDisbursementSuccess(t *testing.T) {
 		Amount:            10000,
 		Description:       "test description",
 		Email:             []string{"test@email.com"},
+		IdempotencyKey:    strconv.FormatInt(time.Now().UnixNano(), 10),
 	}
 
 	xenCli := client.New(os.Getenv("XENDIT_TOKEN"))
@@ -120,6 +122,7 @@ func TestService_CreateDisbursementFailed(t *testing.T) {
 		Amount:            10000,
 		Description:       "test description",
 		Email:             []string{"test@email.com"},
+		IdempotencyKey:    strconv.FormatInt(time.Now().UnixNano(), 10),
 	}
 
 	xenCli := client.New("wrongToken")
@@ -130,6 +133,21 @@ func TestService_CreateDisbursementFailed(t *testing.T) {
 	assert.Equal(t, ErrXenditCreateDisbursement, errors.Cause(err))
 }
 
+func TestService_CreateDisbursementFailedWithoutIdempotencyKey(t *testing.T) {
+	params := CreateDisbursementParams{
+		ID:                1,
+		BankAccountName:   "test bank account name",
+		BankAccountNumber: "123412",
+		Amount:            10000,
+	}
+
+	testService := NewXenditClient(client.New("wrongToken"))
+	resp, err := testService.CreateDisbursement(params)
+
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrXenditCreateDisbursement, errors.Cause(err))
+}
+
 func TestService_GetInvoiceSuccess(t *testing.T) {
 	_ = godotenv.Load("../../.env")
 
@@ -200,6 +218,7 @@ func TestService_GetDisbursementSuccess(t *testing.T) {
 		Amount:            10000,
 		Description:       "test description",
 		Email:             []string{"test@email.com"},
+		IdempotencyKey:    strconv.FormatInt(time.Now().UnixNano(), 10),
 	}
 
 	xenCli := client.New(os.Getenv("XENDIT_TOKEN"))
@@ -226,3 +245,12 @@ func TestService_GetDisbursementFailed(t *testing.T) {
 	assert.Nil(t, resp)
 	assert.Equal(t, ErrXenditGetDisbursement, errors.Cause(err))
 }
+
+func TestService_GetDisbursementsByExternalIDFailed(t *testing.T) {
+	xenCli := client.New("wrong token")
+	testService := NewXenditClient(xenCli)
+	resp, err := testService.GetDisbursementsByExternalID("1")
+
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrXenditGetDisbursement, errors.Cause(err))
+}
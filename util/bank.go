@@ -0,0 +1,74 @@
+package util
+
+import "strings"
+
+var (
+	// SupportedBanks is the list of bank that can receive disbursement, keyed by xendit bank code
+	SupportedBanks = map[string]string{
+		"BCA":     "Bank Central Asia",
+		"MANDIRI": "Bank Mandiri",
+		"BNI":     "Bank Negara Indonesia",
+		"BRI":     "Bank Rakyat Indonesia",
+		"PERMATA": "Bank Permata",
+		"CIMB":    "Bank CIMB Niaga",
+		"DANAMON": "Bank Danamon",
+		"BSI":     "Bank Syariah Indonesia",
+	}
+
+	// SupportedEWallets is the list of e-wallet that can receive disbursement, keyed by xendit bank code
+	SupportedEWallets = map[string]string{
+		"OVO":       "OVO",
+		"DANA":      "DANA",
+		"GOPAY":     "GoPay",
+		"SHOPEEPAY": "ShopeePay",
+		"LINKAJA":   "LinkAja",
+	}
+)
+
+// IsSupportedBankCode check whether the bank or e-wallet code can receive disbursement
+func IsSupportedBankCode(bankCode string) bool {
+	_, isBank := SupportedBanks[bankCode]
+	return isBank || IsEWallet(bankCode)
+}
+
+// IsEWallet check whether the bank code is an e-wallet
+func IsEWallet(bankCode string) bool {
+	_, ok := SupportedEWallets[bankCode]
+	return ok
+}
+
+// ValidateBankAccountNumber return the reason why the account number is not valid for the bank code,
+// e-wallet account is a phone number while bank account use XXX-YYY...YYY format where XXX is the bank code
+func ValidateBankAccountNumber(bankCode, accountNumber string) string {
+	if IsEWallet(bankCode) {
+		if !strings.HasPrefix(accountNumber, "08") || len(accountNumber) < 10 || len(accountNumber) > 14 {
+			return "e-wallet account number must be a phone number starting with 08"
+		}
+
+		for _, char := range accountNumber {
+			if char < '0' || char > '9' {
+				return "e-wallet account number must be a phone number starting with 08"
+			}
+		}
+
+		return ""
+	}
+
+	if len(accountNumber) < 10 {
+		return "bank account is at least 10 characters"
+	}
+	if len(accountNumber) > 25 {
+		return "bank account is at most 25 characters"
+	}
+	for index, char := range accountNumber {
+		if index == 3 {
+			if char != '-' {
+				return "the valid bank account number format is XXX-YYY...YYY where XXX is the bank code"
+			}
+		} else if char < '0' || char > '9' {
+			return "bank account number is invalid"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupportedBankCode(t *testing.T) {
+	assert.True(t, IsSupportedBankCode(BankBCA))
+	assert.True(t, IsSupportedBankCode("OVO"))
+	assert.False(t, IsSupportedBankCode("UNKNOWN"))
+	assert.False(t, IsSupportedBankCode(""))
+}
+
+func TestValidateBankAccountNumber(t *testing.T) {
+	testCases := []struct {
+		bankCode      string
+		accountNumber string
+		valid         bool
+	}{
+		{"BCA", "008-112492374950", true},
+		{"BNI", "008-11", false},
+		{"BNI", "008-112492374950112492374950112492374950", false},
+		{"BNI", "008112492374950", false},
+		{"BNI", "008-A12B92C37D4950", false},
+		{"OVO", "081234567890", true},
+		{"OVO", "008-112492374950", false},
+		{"DANA", "0812", false},
+		{"GOPAY", "08123456789a", false},
+	}
+
+	for _, testCase := range testCases {
+		reason := ValidateBankAccountNumber(testCase.bankCode, testCase.accountNumber)
+		assert.Equal(t, testCase.valid, reason == "", testCase.bankCode+" "+testCase.accountNumber)
+	}
+}
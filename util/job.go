@@ -0,0 +1,42 @@
+package util
+
+import "time"
+
+// Job run a function periodically in background
+type Job struct {
+	interval time.Duration
+	run      func()
+	stop     chan struct{}
+}
+
+// NewJob for initialize job, run is called every interval
+func NewJob(interval time.Duration, run func()) *Job {
+	return &Job{
+		interval: interval,
+		run:      run,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start the job, the first run is done after the first interval
+func (j *Job) Start() {
+	ticker := time.NewTicker(j.interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				j.run()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop the job
+func (j *Job) Stop() {
+	close(j.stop)
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJob(t *testing.T) {
+	called := make(chan struct{}, 1)
+	job := NewJob(time.Millisecond, func() {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+	})
+
+	job.Start()
+	defer job.Stop()
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		assert.Fail(t, "job is not running")
+	}
+}
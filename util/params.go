@@ -10,6 +10,130 @@ const (
 	// InvoiceDuration expired duration when creating invoice
 	InvoiceDuration = 7200 // 2 hours
 
+	// ReconciliationInterval default interval in minutes between reconciliation run
+	ReconciliationInterval = 30
+
+	// PayoutInterval default interval in minutes between scheduled payout run
+	PayoutInterval = 60
+
+	// PayoutManual for payout that only done by business admin request
+	PayoutManual = 0
+	// PayoutWeekly for automatic payout every week
+	PayoutWeekly = 1
+	// PayoutMonthly for automatic payout every month
+	PayoutMonthly = 2
+
+	// MaximumPayoutAttempt for maximum scheduled payout attempt in a period
+	MaximumPayoutAttempt = 3
+
+	// ReportTransactions for transaction history report
+	ReportTransactions = "transactions"
+	// ReportItemSales for item sales report
+	ReportItemSales = "items"
+	// ReportDisbursements for disbursement history report
+	ReportDisbursements = "disbursements"
+
+	// ReportFormatCSV for report exported as csv
+	ReportFormatCSV = "csv"
+	// ReportFormatXLSX for report exported as xlsx
+	ReportFormatXLSX = "xlsx"
+
+	// MaximumReportRange for maximum days of report date range
+	MaximumReportRange = 366
+
+	// AnalyticsIntervalDay for analytics grouped by day
+	AnalyticsIntervalDay = "day"
+	// AnalyticsIntervalWeek for analytics grouped by week
+	AnalyticsIntervalWeek = "week"
+	// AnalyticsIntervalMonth for analytics grouped by month
+	AnalyticsIntervalMonth = "month"
+
+	// DefaultAnalyticsTopItems for default number of top selling items
+	DefaultAnalyticsTopItems = 10
+	// MaximumAnalyticsTopItems for maximum number of top selling items
+	MaximumAnalyticsTopItems = 100
+
+	// NotificationInterval default interval in minutes between notification outbox run
+	NotificationInterval = 1
+	// NotificationBatchSize for maximum outbox messages sent in a run
+	NotificationBatchSize = 100
+	// MaximumNotificationAttempt for maximum delivery attempt before the message is marked as failed
+	MaximumNotificationAttempt = 5
+	// NotificationRetryBaseMinutes for the first retry delay, doubled on every attempt
+	NotificationRetryBaseMinutes = 1
+
+	// NotificationPending for outbox message waiting to be sent
+	NotificationPending = 0
+	// NotificationSent for outbox message sent successfully
+	NotificationSent = 1
+	// NotificationFailed for outbox message that exceed maximum attempt
+	NotificationFailed = 2
+
+	// NotificationBookingCreated is fired when customer create a booking
+	NotificationBookingCreated = "booking_created"
+	// NotificationBookingConfirmed is fired when business admin confirm a booking
+	NotificationBookingConfirmed = "booking_confirmed"
+	// NotificationBookingPaid is fired when booking is fully paid
+	NotificationBookingPaid = "booking_paid"
+	// NotificationBookingExpired is fired when booking is not confirmed or paid in time
+	NotificationBookingExpired = "booking_expired"
+	// NotificationBookingCancelled is fired when business admin reject a booking
+	NotificationBookingCancelled = "booking_cancelled"
+	// NotificationBookingReviewed is fired when customer review a booking
+	NotificationBookingReviewed = "booking_reviewed"
+	// NotificationDisbursementCompleted is fired when disbursement is completed
+	NotificationDisbursementCompleted = "disbursement_completed"
+	// NotificationDisbursementFailed is fired when disbursement is failed
+	NotificationDisbursementFailed = "disbursement_failed"
+
+	// NotificationChannelEmail for notification sent by email
+	NotificationChannelEmail = "email"
+	// NotificationChannelPush for notification sent by push notification
+	NotificationChannelPush = "push"
+	// NotificationChannelSMS for notification sent by sms
+	NotificationChannelSMS = "sms"
+	// NotificationChannelInApp for notification shown in the application inbox
+	NotificationChannelInApp = "in_app"
+
+	// LocaleIndonesian for indonesian notification
+	LocaleIndonesian = "id"
+	// LocaleEnglish for english notification
+	LocaleEnglish = "en"
+
+	// WebhookInterval default interval in minutes between webhook delivery run
+	WebhookInterval = 1
+	// WebhookBatchSize for maximum webhook deliveries sent in a run
+	WebhookBatchSize = 100
+	// MaximumWebhookAttempt for maximum delivery attempt before the delivery is marked as failed
+	MaximumWebhookAttempt = 6
+	// WebhookRetryBaseMinutes for the first retry delay, doubled on every attempt
+	WebhookRetryBaseMinutes = 1
+	// MinimumWebhookSecretLength for minimum length of secret given by business admin
+	MinimumWebhookSecretLength = 16
+	// MinimumTicketSecretLength for minimum length of secret signing booking e-ticket
+	MinimumTicketSecretLength = 32
+	// MinimumAuthTokenSecretLength for minimum length of secret signing session token of local auth provider
+	MinimumAuthTokenSecretLength = 32
+
+	// WebhookPending for delivery waiting to be sent
+	WebhookPending = 0
+	// WebhookDelivered for delivery accepted by the endpoint
+	WebhookDelivered = 1
+	// WebhookFailed for delivery that exceed maximum attempt
+	WebhookFailed = 2
+
+	// RealtimeKeepAliveSeconds for interval of keep alive comment sent to realtime subscriber
+	RealtimeKeepAliveSeconds = 15
+	// RealtimeBufferSize for maximum event waiting to be written to a subscriber before dropped
+	RealtimeBufferSize = 16
+
+	// RealtimeBookingCreated is pushed when a booking is created
+	RealtimeBookingCreated = "booking_created"
+	// RealtimeBookingStatusChanged is pushed when status of a booking is changed
+	RealtimeBookingStatusChanged = "booking_status_changed"
+	// RealtimePaymentReceived is pushed when payment of a booking is received
+	RealtimePaymentReceived = "payment_received"
+
 	// OOPEmail for Omzet Oriented Programming
 	OOPEmail = "pplb.oop@gmail.com"
 
@@ -27,6 +151,12 @@ const (
 
 	// ApplicationJSON for content-type
 	ApplicationJSON = "application/json"
+	// TextCSV for csv content-type
+	TextCSV = "text/csv"
+	// ApplicationXLSX for xlsx content-type
+	ApplicationXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	// ApplicationPDF for pdf content-type
+	ApplicationPDF = "application/pdf"
 
 	// GenderUndefined mapping for gender undefined
 	GenderUndefined = 0
@@ -46,6 +176,9 @@ const (
 	// StatusBusinessAdmin for mapping status business admin
 	StatusBusinessAdmin = 1
 
+	// StatusModerator for mapping status of platform moderator, a moderator login as business admin
+	StatusModerator = 2
+
 	// TimeLayout for time layout convention
 	TimeLayout = "15:04:05"
 	// DateLayout for date layout convention
@@ -61,6 +194,10 @@ const (
 	BookingSelesai = 3
 	// BookingGagal integer mapping
 	BookingGagal = 4
+	// BookingDireview integer mapping for booking that is already reviewed by customer
+	BookingDireview = 5
+	// BookingTidakHadir integer mapping for customer that did not come to the booking
+	BookingTidakHadir = 6
 
 	// Available booking status
 	Available = 0
@@ -87,6 +224,124 @@ const (
 	MinimumRatingValue = 1
 	// MaximumRatingValue for rating valie validation
 	MaximumRatingValue = 5
+	// MaximumReviewPhotos for number of photos that can be attached to a review
+	MaximumReviewPhotos = 5
+	// ReviewEditWindowDays for number of days a review can be edited after it is created
+	ReviewEditWindowDays = 7
+
+	// ReviewReportOpen for report waiting to be reviewed by moderator
+	ReviewReportOpen = 0
+	// ReviewReportResolved for report that is already handled by moderator
+	ReviewReportResolved = 1
+
+	// PricingRuleWeekday replace booking price on monday until friday
+	PricingRuleWeekday = 0
+	// PricingRuleWeekend replace booking price on saturday and sunday
+	PricingRuleWeekend = 1
+	// PricingRulePeakHour add surcharge when booking overlap the time range
+	PricingRulePeakHour = 2
+	// PricingRulePerPerson add price for every person in the booking
+	PricingRulePerPerson = 3
+	// PricingRuleSpecialDate add surcharge on a specific date
+	PricingRuleSpecialDate = 4
+
+	// MaximumDepositPercentage for deposit percentage validation
+	MaximumDepositPercentage = 100
+
+	// PaymentTypeFull customer pay the whole booking in one invoice
+	PaymentTypeFull = 0
+	// PaymentTypeDeposit customer pay deposit first and settle the rest later
+	PaymentTypeDeposit = 1
+	// PaymentTypeSplit booking is paid by every participant separately
+	PaymentTypeSplit = 2
+
+	// InvoiceTypeFull invoice for the whole booking
+	InvoiceTypeFull = 0
+	// InvoiceTypeDeposit invoice for the deposit portion
+	InvoiceTypeDeposit = 1
+	// InvoiceTypeRemaining invoice for the rest of deposit booking
+	InvoiceTypeRemaining = 2
+	// InvoiceTypeSplit invoice for a participant of split booking
+	InvoiceTypeSplit = 3
+
+	// CheckInEarlyMinutes for how early customer can check in before booking start time
+	CheckInEarlyMinutes = 30
+	// NoShowGracePeriodMinutes for how long after start time a booking can be marked as no-show
+	NoShowGracePeriodMinutes = 15
+
+	// NoShowPolicyNone for place that accept booking from every customer
+	NoShowPolicyNone = 0
+	// NoShowPolicyBlock for place that reject booking from customer above no-show threshold
+	NoShowPolicyBlock = 1
+	// NoShowPolicyPrepayment for place that require full payment from customer above no-show threshold
+	NoShowPolicyPrepayment = 2
+
+	// TicketQRCodeSize for size of e-ticket qr code in pixel
+	TicketQRCodeSize = 256
+
+	// InvoicePending invoice status mapping
+	InvoicePending = 0
+	// InvoicePaid invoice status mapping
+	InvoicePaid = 1
+	// InvoiceExpired invoice status mapping
+	InvoiceExpired = 2
+	// InvoiceRefunded invoice status mapping, the invoice was paid but its booking failed so the amount is owed to the payer
+	InvoiceRefunded = 3
+
+	// RoleOwner for the business admin who own the place
+	RoleOwner = "owner"
+	// RoleManager for staff who run the place except finance and staff management
+	RoleManager = "manager"
+	// RoleCashier for staff who only handle bookings
+	RoleCashier = "cashier"
+
+	// PermissionReadPlace for viewing place profile, review and policy
+	PermissionReadPlace = "place:read"
+	// PermissionManagePlace for editing place profile, pricing and policy
+	PermissionManagePlace = "place:manage"
+	// PermissionReadBooking for viewing bookings of the place
+	PermissionReadBooking = "booking:read"
+	// PermissionManageBooking for confirming, checking in and marking no-show of bookings
+	PermissionManageBooking = "booking:manage"
+	// PermissionReadCatalog for viewing catalog items of the place
+	PermissionReadCatalog = "catalog:read"
+	// PermissionManageCatalog for creating, editing and deleting catalog items
+	PermissionManageCatalog = "catalog:manage"
+	// PermissionReplyReview for replying review of the place
+	PermissionReplyReview = "review:reply"
+	// PermissionReadFinance for viewing balance, reports, transactions and analytics
+	PermissionReadFinance = "finance:read"
+	// PermissionManageFinance for disbursement and payout settings
+	PermissionManageFinance = "finance:manage"
+	// PermissionManageWebhook for managing webhooks of the place
+	PermissionManageWebhook = "webhook:manage"
+	// PermissionManageStaff for inviting and removing staff of the place
+	PermissionManageStaff = "staff:manage"
+
+	// StaffInvitationExpiryHours for how long a staff invitation can be accepted
+	StaffInvitationExpiryHours = 72
+	// MinimumPasswordLength for password chosen by user
+	MinimumPasswordLength = 8
+	// PasswordResetExpiryMinutes for how long a password reset token can be used
+	PasswordResetExpiryMinutes = 30
+
+	// AuthProviderFirebase for authentication through firebase identity toolkit
+	AuthProviderFirebase = "firebase"
+	// AuthProviderLocal for authentication with the self-hosted provider
+	AuthProviderLocal = "local"
+	// OTPExpiryMinutes for how long an OTP code can be verified
+	OTPExpiryMinutes = 5
+	// OTPMaxAttempts for maximum wrong OTP code before the code is discarded
+	OTPMaxAttempts = 5
+	// AccessTokenExpiryMinutes for how long an access token of the self-hosted provider is valid
+	AccessTokenExpiryMinutes = 60
+	// RefreshTokenExpiryDays for how long a refresh token of the self-hosted provider is valid
+	RefreshTokenExpiryDays = 30
+
+	// UserCacheSize for maximum registered users cached by auth middleware
+	UserCacheSize = 1024
+	// UserCacheTTLMinutes for how long a cached user is used before it is read again from database
+	UserCacheTTLMinutes = 5
 )
 
 var (
@@ -113,12 +368,21 @@ var (
 	// XenditStatusExpired for xendit status expired
 	XenditStatusExpired = "EXPIRED"
 
+	// XenditStatusSettled for xendit status paid and already settled to the merchant
+	XenditStatusSettled = "SETTLED"
+
+	// XenditStatusPending for xendit status pending
+	XenditStatusPending = "PENDING"
+
 	// XenditPlatformFee for xendit platform fee
 	XenditPlatformFee = 3000.0
 
 	// XenditDisbursementFee for xendit disbursement fee
 	XenditDisbursementFee = 5000.0
 
+	// FCMSendURL for firebase cloud messaging send endpoint
+	FCMSendURL = "https://fcm.googleapis.com/fcm/send"
+
 	// XenditVATPercentage for xendit vat percentage
 	XenditVATPercentage = .11
 )
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// MaximumPasswordLength is the longest password bcrypt can hash without truncating it
+const MaximumPasswordLength = 72
+
+// ValidatePassword return the reason why the password is too weak, a strong password has at least
+// MinimumPasswordLength characters and mixes upper case letter, lower case letter and digit
+func ValidatePassword(password string) string {
+	if len(password) < MinimumPasswordLength {
+		return fmt.Sprintf("password is at least %d characters", MinimumPasswordLength)
+	}
+	if len(password) > MaximumPasswordLength {
+		return fmt.Sprintf("password is at most %d characters", MaximumPasswordLength)
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		}
+	}
+
+	if !hasUpper || !hasLower || !hasDigit {
+		return "password must contain upper case letter, lower case letter and digit"
+	}
+
+	return ""
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePassword(t *testing.T) {
+	testCases := []struct {
+		password string
+		valid    bool
+	}{
+		{"Password123", true},
+		{"Pass word 1", true},
+		{"Pas1", false},
+		{"password123", false},
+		{"PASSWORD123", false},
+		{"Passwordabc", false},
+		{"Aa1" + strings.Repeat("a", 70), false},
+	}
+
+	for _, testCase := range testCases {
+		reason := ValidatePassword(testCase.password)
+		assert.Equal(t, testCase.valid, reason == "", testCase.password)
+	}
+}
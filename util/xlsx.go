@@ -0,0 +1,130 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Sheet is a worksheet of xlsx file, a cell can be string, int or float64
+type Sheet struct {
+	Name string
+	Rows [][]interface{}
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`%s</Types>`
+	xlsxSheetContentType = `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets>%s</sheets></workbook>`
+	xlsxWorkbookSheet = `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">%s</Relationships>`
+	xlsxWorkbookSheetRel = `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`
+
+	xlsxWorksheet = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>%s</sheetData></worksheet>`
+)
+
+// WriteXLSX write sheets as a minimal xlsx workbook, string cell is written as inline string
+func WriteXLSX(w io.Writer, sheets []Sheet) error {
+	var contentTypes, workbookSheets, workbookRels bytes.Buffer
+	for i, sheet := range sheets {
+		contentTypes.WriteString(fmt.Sprintf(xlsxSheetContentType, i+1))
+		workbookSheets.WriteString(fmt.Sprintf(xlsxWorkbookSheet, escapeXML(sheet.Name), i+1, i+1))
+		workbookRels.WriteString(fmt.Sprintf(xlsxWorkbookSheetRel, i+1, i+1))
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, contentTypes.String())},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, workbookSheets.String())},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxWorkbookRels, workbookRels.String())},
+	}
+
+	for i, sheet := range sheets {
+		sheetData, err := xlsxSheetData(sheet.Rows)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), fmt.Sprintf(xlsxWorksheet, sheetData)})
+	}
+
+	zipWriter := zip.NewWriter(w)
+	for _, file := range files {
+		fileWriter, err := zipWriter.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(fileWriter, file.content); err != nil {
+			return err
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+func xlsxSheetData(rows [][]interface{}) (string, error) {
+	var sheetData bytes.Buffer
+	for i, row := range rows {
+		sheetData.WriteString(fmt.Sprintf(`<row r="%d">`, i+1))
+		for j, cell := range row {
+			ref := xlsxColumnName(j) + strconv.Itoa(i+1)
+
+			switch value := cell.(type) {
+			case string:
+				sheetData.WriteString(fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escapeXML(value)))
+			case int:
+				sheetData.WriteString(fmt.Sprintf(`<c r="%s"><v>%d</v></c>`, ref, value))
+			case float64:
+				sheetData.WriteString(fmt.Sprintf(`<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(value, 'f', -1, 64)))
+			default:
+				return "", fmt.Errorf("unsupported xlsx cell type %T", cell)
+			}
+		}
+		sheetData.WriteString(`</row>`)
+	}
+
+	return sheetData.String(), nil
+}
+
+// xlsxColumnName convert zero based column index to column name, 0 is A and 26 is AA
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+
+	return name
+}
+
+func escapeXML(value string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
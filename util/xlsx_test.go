@@ -0,0 +1,53 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var buffer bytes.Buffer
+		sheets := []Sheet{
+			{Name: "Transactions", Rows: [][]interface{}{{"ID", "Name", "Price"}, {1, "Tom & Jerry", 10000.5}}},
+			{Name: "Items", Rows: [][]interface{}{{"Name"}}},
+		}
+
+		err := WriteXLSX(&buffer, sheets)
+		assert.Nil(t, err)
+
+		reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		assert.Nil(t, err)
+
+		files := map[string]string{}
+		for _, file := range reader.File {
+			fileReader, _ := file.Open()
+			content, _ := io.ReadAll(fileReader)
+			files[file.Name] = string(content)
+		}
+
+		assert.Contains(t, files, "[Content_Types].xml")
+		assert.Contains(t, files, "xl/worksheets/sheet2.xml")
+		assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Transactions" sheetId="1" r:id="rId1"/>`)
+		assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="B2" t="inlineStr"><is><t>Tom &amp; Jerry</t></is></c>`)
+		assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="C2"><v>10000.5</v></c>`)
+	})
+
+	t.Run("failed unsupported cell", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		err := WriteXLSX(&buffer, []Sheet{{Name: "Sheet", Rows: [][]interface{}{{true}}}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumnName(0))
+	assert.Equal(t, "Z", xlsxColumnName(25))
+	assert.Equal(t, "AA", xlsxColumnName(26))
+	assert.Equal(t, "AB", xlsxColumnName(27))
+}